@@ -321,14 +321,13 @@ func LoadConfig() (*GlobalConfig, []string, error) {
 }
 
 func CheckBase58Addr(addr, network string, p *params.Params) bool {
-	_, err := address.DecodeAddress(addr)
+	a, err := address.DecodeAddress(addr)
 	if err != nil {
 		log.Fatalln(network, "qitmeer address error!", err, addr)
 		return false
 	}
-	networkChar := addr[0:1]
-	if p.NetworkAddressPrefix != networkChar {
-		log.Fatalln(network, "qitmeer address not match the network,please check your config network param!", p.NetworkAddressPrefix, networkChar)
+	if !address.IsForNetwork(a, p) {
+		log.Fatalln(network, "qitmeer address not match the network,please check your config network param!", p.NetworkAddressPrefix, addr)
 		return false
 	}
 	return true
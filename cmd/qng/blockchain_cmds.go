@@ -95,6 +95,9 @@ func blockchainCmd() *cli.Command {
 					cfg.InvalidTxIndex = false
 					cfg.VMBlockIndex = false
 					cfg.AddrIndex = false
+					cfg.SpentIndex = false
+					cfg.TokenIndex = false
+					cfg.CrossChainIndex = false
 					cons := consensus.New(cfg, db, interrupt, make(chan struct{}))
 					err = cons.Init()
 					if err != nil {
@@ -158,6 +161,9 @@ func blockchainCmd() *cli.Command {
 					cfg.InvalidTxIndex = false
 					cfg.VMBlockIndex = false
 					cfg.AddrIndex = false
+					cfg.SpentIndex = false
+					cfg.TokenIndex = false
+					cfg.CrossChainIndex = false
 					cons := consensus.New(cfg, db, interrupt, make(chan struct{}))
 					err = cons.Init()
 					if err != nil {
@@ -503,6 +509,9 @@ func upgradeBlockChain(cfg *config.Config, db database.DB, interrupt <-chan stru
 		cfg.InvalidTxIndex = false
 		cfg.VMBlockIndex = false
 		cfg.AddrIndex = false
+		cfg.SpentIndex = false
+		cfg.TokenIndex = false
+		cfg.CrossChainIndex = false
 		cons := consensus.New(cfg, db, interrupt, make(chan struct{}))
 		err := cons.Init()
 		if err != nil {
@@ -613,6 +622,9 @@ func upgradeBlockChain(cfg *config.Config, db database.DB, interrupt <-chan stru
 	cfg.InvalidTxIndex = false
 	cfg.VMBlockIndex = false
 	cfg.AddrIndex = false
+	cfg.SpentIndex = false
+	cfg.TokenIndex = false
+	cfg.CrossChainIndex = false
 	cons := consensus.New(cfg, db, interrupt, make(chan struct{}))
 	err = cons.Init()
 	if err != nil {
@@ -140,6 +140,9 @@ func consensusCmd() *cli.Command {
 					cfg.InvalidTxIndex = false
 					cfg.VMBlockIndex = false
 					cfg.AddrIndex = false
+					cfg.SpentIndex = false
+					cfg.TokenIndex = false
+					cfg.CrossChainIndex = false
 					cons := consensus.New(cfg, db, interrupt, make(chan struct{}))
 					err = cons.Init()
 					if err != nil {
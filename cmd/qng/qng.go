@@ -139,6 +139,30 @@ func qitmeerd(ctx *cli.Context) error {
 
 		return nil
 	}
+	if cfg.DropSpentIndex {
+		if err := index.DropSpentIndex(db, interrupt); err != nil {
+			log.Error(fmt.Sprintf("%v", err))
+			return err
+		}
+
+		return nil
+	}
+	if cfg.DropTokenIndex {
+		if err := index.DropTokenIndex(db, interrupt); err != nil {
+			log.Error(fmt.Sprintf("%v", err))
+			return err
+		}
+
+		return nil
+	}
+	if cfg.DropCrossChainIndex {
+		if err := index.DropCrossChainIndex(db, interrupt); err != nil {
+			log.Error(fmt.Sprintf("%v", err))
+			return err
+		}
+
+		return nil
+	}
 	if cfg.DropTxIndex {
 		if err := index.DropTxIndex(db, interrupt); err != nil {
 			log.Error(fmt.Sprintf("%v", err))
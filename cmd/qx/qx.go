@@ -37,6 +37,7 @@ encode and decode :
     rlp-decode            decode a rlp base16 string to a human-readble representation
     script-encode         encode a tx script token list to a base16 string
     script-decode         decode a base16 string to a human-readable tx script token list
+    script-trace          trace the script execution of a transaction input step by step.
 
 hash :
     blake2b256            calculate Blake2b 256 hash of a base16 data.
@@ -79,9 +80,29 @@ addr & tx & sign :
     ec-to-ethaddr         convert an EC public key to a ethereum address.
     pkaddr-to-public      convert an pkaddress to EC public key (the uncompressed format by default )
     pkaddr-to-ethaddr     convert an pkaddress to ethereum address
+    addr-to-bech32        convert a base58 address to the bech32m encoding of the address.
+    bech32-to-addr        convert a bech32m address to the base58 encoding of the address.
+    descriptor-info       check an output script descriptor and add its checksum.
+    descriptor-derive     derive the addresses of an output script descriptor.
     tx-encode             encode a unsigned transaction.
+    tx-build              build a unsigned transaction with its signing metadata from a json spec.
     tx-decode             decode a transaction in base16 to json format.
     tx-sign               sign a transactions using a private key.
+    psbt-create           create a partially signed transaction from a unsigned transaction.
+    psbt-update           add the previous transactions and outputs, redeem scripts and sighash types to a partially signed transaction.
+    psbt-sign             sign the inputs of a partially signed transaction using private keys.
+    psbt-combine          combine the partially signed transactions of the same transaction.
+    psbt-finalize         finalize the inputs of a partially signed transaction.
+    psbt-extract          extract the signed transaction from a finalized partially signed transaction.
+    psbt-decode           decode a partially signed transaction to json format.
+    htlc-new              create a hash time-locked contract for atomic swaps.
+    htlc-redeem           sign a transaction input which redeems a hash time-locked contract with the secret.
+    htlc-refund           sign a transaction input which refunds a hash time-locked contract after the lock time.
+    musig2-key-agg        aggregate the public keys of the n-of-n signers into a MuSig2 key.
+    musig2-nonce          generate a MuSig2 secret nonce for a transaction input and its public nonce.
+    musig2-nonce-agg      aggregate the public nonces of all the MuSig2 signers.
+    musig2-sign           create the MuSig2 partial signature of a transaction input.
+    musig2-combine        combine the MuSig2 partial signatures into the signature script of a transaction input.
     msg-sign              create a message signature
     msg-verify            validate a message signature
     signature-decode      decode a ECDSA signature
@@ -134,7 +155,34 @@ var txOutputs qx.TxOutputsFlag
 var txVersion qx.TxVersionFlag
 var txLockTime qx.TxLockTimeFlag
 var privateKeys qx.TxPrivateKey
+var psbtPrevTxs qx.PsbtUpdateFlag
+var psbtPrevOuts qx.PsbtUpdateFlag
+var psbtRedeemScripts qx.PsbtUpdateFlag
+var psbtSighashes qx.PsbtUpdateFlag
 var msgSignatureMode string
+var msgSignatureScheme string
+var traceInputIndex int
+var tracePkScript string
+var traceAmount int64
+var htlcRecipient string
+var htlcRefund string
+var htlcSecretHash string
+var htlcSecret string
+var htlcLockTime int64
+var htlcHashType string
+var htlcContract string
+var htlcPrivateKey string
+var htlcInputIndex int
+var htlcP2sh bool
+var musig2PrivateKey string
+var musig2SecNonce string
+var musig2PubKeys string
+var musig2AggNonce string
+var musig2PartialSigs string
+var musig2InputIndex int
+var musig2ScriptType string
+var descriptorBegin uint
+var descriptorEnd uint
 
 func main() {
 
@@ -414,6 +462,16 @@ func main() {
 		cmdUsage(pkaddrToETHAddrCmd, "Usage: qx pkaddr-to-ethaddr [pk address] \n")
 	}
 
+	addrToBech32Cmd := flag.NewFlagSet("addr-to-bech32", flag.ExitOnError)
+	addrToBech32Cmd.Usage = func() {
+		cmdUsage(addrToBech32Cmd, "Usage: qx addr-to-bech32 [address] \n")
+	}
+
+	bech32ToAddrCmd := flag.NewFlagSet("bech32-to-addr", flag.ExitOnError)
+	bech32ToAddrCmd.Usage = func() {
+		cmdUsage(bech32ToAddrCmd, "Usage: qx bech32-to-addr [bech32 address] \n")
+	}
+
 	// Transaction
 	txDecodeCmd := flag.NewFlagSet("tx-decode", flag.ExitOnError)
 	txDecodeCmd.Usage = func() {
@@ -454,6 +512,18 @@ example:
 -o TnTTMZANDBhjeoxbPMAVKb5sM7KuvNpRo2b:9.9999:0:cltvpubkeyhash:1667298670
 `)
 
+	txBuildCmd := flag.NewFlagSet("tx-build", flag.ExitOnError)
+	txBuildCmd.Usage = func() {
+		cmdUsage(txBuildCmd, "Usage: qx tx-build [-n network] [json_spec] \n"+
+			"Types: regular, crossexport, crossimport\n"+
+			"example: \n"+
+			`{"type":"regular","inputs":[{"txid":"5fdad6bb6781416b0361a10eb6183dec45fb31edcf2da10d22893ee7bb6502ca",`+
+			`"vout":0,"address":"TnTTMZANDBhjeoxbPMAVKb5sM7KuvNpRo2b","amount":10,"coinId":0}],`+
+			`"outputs":[{"address":"TnTTMZANDBhjeoxbPMAVKb5sM7KuvNpRo2b","amount":9.9999,"coinId":0},`+
+			`{"opReturn":{"data":"68656c6c6f"}}]}`+"\n")
+	}
+	txBuildCmd.StringVar(&network, "n", "mainnet", "the target network of the transaction. (mainnet, testnet, privnet, mixnet)")
+
 	txSignCmd := flag.NewFlagSet("tx-sign", flag.ExitOnError)
 	txSignCmd.Usage = func() {
 		cmdUsage(txSignCmd, "Usage: qx tx-sign [raw_tx_base16_string] \n")
@@ -461,11 +531,149 @@ example:
 	txSignCmd.Var(&privateKeys, "k", "the ec private key to sign the raw transaction")
 	txSignCmd.StringVar(&network, "n", "mainnet", "decode rawtx for the target network. (mainnet, testnet, privnet)")
 
+	psbtCreateCmd := flag.NewFlagSet("psbt-create", flag.ExitOnError)
+	psbtCreateCmd.Usage = func() {
+		cmdUsage(psbtCreateCmd, "Usage: qx psbt-create [raw_tx_base16_string] \n")
+	}
+
+	psbtUpdateCmd := flag.NewFlagSet("psbt-update", flag.ExitOnError)
+	psbtUpdateCmd.Usage = func() {
+		cmdUsage(psbtUpdateCmd, "Usage: qx psbt-update [-x prevtx] [-i prevout] [-r redeem-script] [-s sighash] [psbt_base64_string] \n")
+	}
+	psbtUpdateCmd.Var(&psbtPrevTxs, "x", `The previous transaction of an input encoded as INDEX:RAWTX.
+RAWTX is the base16 raw transaction, it proves the previous output which the input signers need.`)
+	psbtUpdateCmd.Var(&psbtPrevOuts, "i", `The previous output of an input encoded as INDEX:COINID:AMOUNT:PKSCRIPT.
+PKSCRIPT is the base16 lock script. The input is not signed by psbt-sign without its previous transaction.
+example:
+-i 0:0:100000000:a914f3b5b3c9c1e7e2c4b5a1d7e6c2f1a0b3c4d5e6f787`)
+	psbtUpdateCmd.Var(&psbtRedeemScripts, "r", "The redeem script of a pay-to-script-hash input encoded as INDEX:REDEEMSCRIPT")
+	psbtUpdateCmd.Var(&psbtSighashes, "s", "The sighash type of an input encoded as INDEX:SIGHASH, (ALL, NONE, SINGLE, ALL|ANYONECANPAY, NONE|ANYONECANPAY, SINGLE|ANYONECANPAY)")
+
+	psbtSignCmd := flag.NewFlagSet("psbt-sign", flag.ExitOnError)
+	psbtSignCmd.Usage = func() {
+		cmdUsage(psbtSignCmd, "Usage: qx psbt-sign [-k ec_private_key] [psbt_base64_string] \n")
+	}
+	psbtSignCmd.Var(&privateKeys, "k", "the ec private key to sign the inputs which it owns")
+
+	psbtCombineCmd := flag.NewFlagSet("psbt-combine", flag.ExitOnError)
+	psbtCombineCmd.Usage = func() {
+		cmdUsage(psbtCombineCmd, "Usage: qx psbt-combine [psbt_base64_string] [psbt_base64_string]... \n")
+	}
+
+	psbtFinalizeCmd := flag.NewFlagSet("psbt-finalize", flag.ExitOnError)
+	psbtFinalizeCmd.Usage = func() {
+		cmdUsage(psbtFinalizeCmd, "Usage: qx psbt-finalize [psbt_base64_string] \n")
+	}
+
+	psbtExtractCmd := flag.NewFlagSet("psbt-extract", flag.ExitOnError)
+	psbtExtractCmd.Usage = func() {
+		cmdUsage(psbtExtractCmd, "Usage: qx psbt-extract [psbt_base64_string] \n")
+	}
+
+	psbtDecodeCmd := flag.NewFlagSet("psbt-decode", flag.ExitOnError)
+	psbtDecodeCmd.Usage = func() {
+		cmdUsage(psbtDecodeCmd, "Usage: qx psbt-decode [psbt_base64_string] \n")
+	}
+	psbtDecodeCmd.StringVar(&network, "n", "mainnet", "decode psbt for the target network. (mainnet, testnet, privnet)")
+
+	htlcNewCmd := flag.NewFlagSet("htlc-new", flag.ExitOnError)
+	htlcNewCmd.Usage = func() {
+		cmdUsage(htlcNewCmd, "Usage: qx htlc-new [-r recipient] [-f refund] [-h secret_hash | -s secret] [-l lock_time] [-t hash_type] \n")
+	}
+	htlcNewCmd.StringVar(&network, "n", "mainnet", "the target network of the contract. (mainnet, testnet, privnet, mixnet)")
+	htlcNewCmd.StringVar(&htlcRecipient, "r", "", "the pubkeyhash address which redeems the contract with the secret")
+	htlcNewCmd.StringVar(&htlcRefund, "f", "", "the pubkeyhash address which refunds the contract after the lock time")
+	htlcNewCmd.StringVar(&htlcSecretHash, "h", "", "the base16 hash of the secret")
+	htlcNewCmd.StringVar(&htlcSecret, "s", "", "the base16 32-byte secret, whose hash locks the contract instead of -h")
+	htlcNewCmd.Int64Var(&htlcLockTime, "l", 0, "the lock time of the refund, a block height or a unix time")
+	htlcNewCmd.StringVar(&htlcHashType, "t", "sha256", "the hash of the secret. (sha256, blake256)")
+
+	htlcRedeemCmd := flag.NewFlagSet("htlc-redeem", flag.ExitOnError)
+	htlcRedeemCmd.Usage = func() {
+		cmdUsage(htlcRedeemCmd, "Usage: qx htlc-redeem [-k ec_private_key] [-c contract] [-s secret] [-i input_index] [-p] [raw_tx_base16_string] \n")
+	}
+	htlcRedeemCmd.StringVar(&htlcPrivateKey, "k", "", "the ec private key of the recipient")
+	htlcRedeemCmd.StringVar(&htlcContract, "c", "", "the base16 contract script")
+	htlcRedeemCmd.StringVar(&htlcSecret, "s", "", "the base16 secret")
+	htlcRedeemCmd.IntVar(&htlcInputIndex, "i", 0, "the index of the input which spends the contract")
+	htlcRedeemCmd.BoolVar(&htlcP2sh, "p", false, "the input spends the pay-to-script-hash output of the contract")
+
+	htlcRefundCmd := flag.NewFlagSet("htlc-refund", flag.ExitOnError)
+	htlcRefundCmd.Usage = func() {
+		cmdUsage(htlcRefundCmd, "Usage: qx htlc-refund [-k ec_private_key] [-c contract] [-i input_index] [-p] [raw_tx_base16_string] \n"+
+			"The lock time of the raw transaction must reach the contract, and the sequence of the input must not be 4294967295.\n")
+	}
+	htlcRefundCmd.StringVar(&htlcPrivateKey, "k", "", "the ec private key of the refund")
+	htlcRefundCmd.StringVar(&htlcContract, "c", "", "the base16 contract script")
+	htlcRefundCmd.IntVar(&htlcInputIndex, "i", 0, "the index of the input which spends the contract")
+	htlcRefundCmd.BoolVar(&htlcP2sh, "p", false, "the input spends the pay-to-script-hash output of the contract")
+
+	musig2KeyAggCmd := flag.NewFlagSet("musig2-key-agg", flag.ExitOnError)
+	musig2KeyAggCmd.Usage = func() {
+		cmdUsage(musig2KeyAggCmd, "Usage: qx musig2-key-agg [-n network] [pubkey,pubkey,...] \n")
+	}
+	musig2KeyAggCmd.StringVar(&network, "n", "mainnet", "the target network of the address. (mainnet, testnet, privnet, mixnet)")
+
+	musig2NonceCmd := flag.NewFlagSet("musig2-nonce", flag.ExitOnError)
+	musig2NonceCmd.Usage = func() {
+		cmdUsage(musig2NonceCmd, "Usage: qx musig2-nonce [-k ec_private_key] [-p pubkey,pubkey,...] [-i input_index] [-t script_type] [raw_tx_base16_string] \n"+
+			"The secret nonce is bound to the signature hash of the input and musig2-sign refuses anything else.\n"+
+			"It must be kept private and sign only once, a reused secret nonce reveals the private key.\n")
+	}
+	musig2NonceCmd.StringVar(&musig2PrivateKey, "k", "", "the ec private key of the signer")
+	musig2NonceCmd.StringVar(&musig2PubKeys, "p", "", "the public keys of all the signers")
+	musig2NonceCmd.IntVar(&musig2InputIndex, "i", 0, "the index of the input which spends the aggregated key")
+	musig2NonceCmd.StringVar(&musig2ScriptType, "t", "pubkeyhash", "the script type of the spent output. (pubkey, pubkeyhash)")
+
+	musig2NonceAggCmd := flag.NewFlagSet("musig2-nonce-agg", flag.ExitOnError)
+	musig2NonceAggCmd.Usage = func() {
+		cmdUsage(musig2NonceAggCmd, "Usage: qx musig2-nonce-agg [pub_nonce,pub_nonce,...] \n")
+	}
+
+	musig2SignCmd := flag.NewFlagSet("musig2-sign", flag.ExitOnError)
+	musig2SignCmd.Usage = func() {
+		cmdUsage(musig2SignCmd, "Usage: qx musig2-sign [-k ec_private_key] [-s sec_nonce] [-p pubkey,pubkey,...] [-a agg_nonce] [-i input_index] [-t script_type] [raw_tx_base16_string] \n")
+	}
+	musig2SignCmd.StringVar(&musig2PrivateKey, "k", "", "the ec private key of the signer")
+	musig2SignCmd.StringVar(&musig2SecNonce, "s", "", "the secret nonce of the signer")
+	musig2SignCmd.StringVar(&musig2PubKeys, "p", "", "the public keys of all the signers")
+	musig2SignCmd.StringVar(&musig2AggNonce, "a", "", "the aggregated nonce")
+	musig2SignCmd.IntVar(&musig2InputIndex, "i", 0, "the index of the input which spends the aggregated key")
+	musig2SignCmd.StringVar(&musig2ScriptType, "t", "pubkeyhash", "the script type of the spent output. (pubkey, pubkeyhash)")
+
+	musig2CombineCmd := flag.NewFlagSet("musig2-combine", flag.ExitOnError)
+	musig2CombineCmd.Usage = func() {
+		cmdUsage(musig2CombineCmd, "Usage: qx musig2-combine [-p pubkey,pubkey,...] [-a agg_nonce] [-s partial_sig,partial_sig,...] [-i input_index] [-t script_type] [raw_tx_base16_string] \n")
+	}
+	musig2CombineCmd.StringVar(&musig2PubKeys, "p", "", "the public keys of all the signers")
+	musig2CombineCmd.StringVar(&musig2AggNonce, "a", "", "the aggregated nonce")
+	musig2CombineCmd.StringVar(&musig2PartialSigs, "s", "", "the partial signatures of all the signers")
+	musig2CombineCmd.IntVar(&musig2InputIndex, "i", 0, "the index of the input which spends the aggregated key")
+	musig2CombineCmd.StringVar(&musig2ScriptType, "t", "pubkeyhash", "the script type of the spent output. (pubkey, pubkeyhash)")
+
+	descriptorInfoCmd := flag.NewFlagSet("descriptor-info", flag.ExitOnError)
+	descriptorInfoCmd.Usage = func() {
+		cmdUsage(descriptorInfoCmd, "Usage: qx descriptor-info [-n network] [descriptor] \n"+
+			"Descriptors: pkh(KEY), pkhalt(KEY), multi(K,KEY,...), sh(SCRIPT), cltv(LOCKTIME,KEY),\n"+
+			"tokenpkh(COINID,UPLIMIT,NAME,FEECFG,KEY), addr(ADDRESS), raw(HEX). KEY is a public key or a HD key with\n"+
+			"a derivation path, which ends with /* for a range.\n")
+	}
+	descriptorInfoCmd.StringVar(&network, "n", "mainnet", "the target network of the descriptor. (mainnet, testnet, privnet, mixnet)")
+
+	descriptorDeriveCmd := flag.NewFlagSet("descriptor-derive", flag.ExitOnError)
+	descriptorDeriveCmd.Usage = func() {
+		cmdUsage(descriptorDeriveCmd, "Usage: qx descriptor-derive [-n network] [-b begin] [-e end] [descriptor] \n")
+	}
+	descriptorDeriveCmd.StringVar(&network, "n", "mainnet", "the target network of the addresses. (mainnet, testnet, privnet, mixnet)")
+	descriptorDeriveCmd.UintVar(&descriptorBegin, "b", 0, "the begin index of the range of a ranged descriptor")
+	descriptorDeriveCmd.UintVar(&descriptorEnd, "e", 0, "the end index of the range of a ranged descriptor")
+
 	msgSignCmd := flag.NewFlagSet("msg-sign", flag.ExitOnError)
 	msgSignCmd.Usage = func() {
 		cmdUsage(msgSignCmd, "Usage: msg-sign [wif] [message] \n")
 	}
 	msgSignCmd.StringVar(&msgSignatureMode, "m", "qx", "the msg signature mode")
+	msgSignCmd.StringVar(&msgSignatureScheme, "s", "secp256k1", "the signature scheme of the key. (secp256k1, ed25519, schnorr)")
 	msgSignCmd.BoolVar(&showDetails, "d", false, "show signature details")
 
 	msgVerifyCmd := flag.NewFlagSet("msg-verify", flag.ExitOnError)
@@ -485,6 +693,14 @@ example:
 		cmdUsage(scriptEncodeCmd, "Usage: qx script-encode [ops] \n")
 	}
 
+	scriptTraceCmd := flag.NewFlagSet("script-trace", flag.ExitOnError)
+	scriptTraceCmd.Usage = func() {
+		cmdUsage(scriptTraceCmd, "Usage: qx script-trace [-i input_index] [-s prev_pkscript] [-a amount] [raw_tx_base16_string] \n")
+	}
+	scriptTraceCmd.IntVar(&traceInputIndex, "i", 0, "the index of the input to trace")
+	scriptTraceCmd.StringVar(&tracePkScript, "s", "", "the base16 lock script of the previous output")
+	scriptTraceCmd.Int64Var(&traceAmount, "a", 0, "the amount of the previous output")
+
 	flagSet := []*flag.FlagSet{
 		base58CheckEncodeCommand,
 		base58CheckDecodeCommand,
@@ -528,13 +744,34 @@ example:
 		ecToETHAddrCmd,
 		pkaddrToPubCmd,
 		pkaddrToETHAddrCmd,
+		addrToBech32Cmd,
+		bech32ToAddrCmd,
 		txEncodeCmd,
+		txBuildCmd,
 		txDecodeCmd,
 		txSignCmd,
+		psbtCreateCmd,
+		psbtUpdateCmd,
+		psbtSignCmd,
+		psbtCombineCmd,
+		psbtFinalizeCmd,
+		psbtExtractCmd,
+		psbtDecodeCmd,
+		htlcNewCmd,
+		htlcRedeemCmd,
+		htlcRefundCmd,
+		musig2KeyAggCmd,
+		musig2NonceCmd,
+		musig2NonceAggCmd,
+		musig2SignCmd,
+		musig2CombineCmd,
+		descriptorInfoCmd,
+		descriptorDeriveCmd,
 		msgSignCmd,
 		msgVerifyCmd,
 		scriptDecodeCmd,
 		scriptEncodeCmd,
+		scriptTraceCmd,
 	}
 
 	if len(os.Args) == 1 {
@@ -1393,6 +1630,58 @@ example:
 		}
 	}
 
+	if addrToBech32Cmd.Parsed() {
+		stat, _ := os.Stdin.Stat()
+		if (stat.Mode() & os.ModeNamedPipe) == 0 {
+			if len(os.Args) == 2 || os.Args[2] == "help" || os.Args[2] == "--help" {
+				addrToBech32Cmd.Usage()
+			} else {
+				addr, err := qx.AddrToBech32(os.Args[len(os.Args)-1])
+				if err != nil {
+					qx.ErrExit(err)
+				}
+				fmt.Printf("%s\n", addr)
+			}
+		} else { //try from STDIN
+			src, err := ioutil.ReadAll(os.Stdin)
+			if err != nil {
+				errExit(err)
+			}
+			str := strings.TrimSpace(string(src))
+			addr, err := qx.AddrToBech32(str)
+			if err != nil {
+				qx.ErrExit(err)
+			}
+			fmt.Printf("%s\n", addr)
+		}
+	}
+
+	if bech32ToAddrCmd.Parsed() {
+		stat, _ := os.Stdin.Stat()
+		if (stat.Mode() & os.ModeNamedPipe) == 0 {
+			if len(os.Args) == 2 || os.Args[2] == "help" || os.Args[2] == "--help" {
+				bech32ToAddrCmd.Usage()
+			} else {
+				addr, err := qx.Bech32ToAddr(os.Args[len(os.Args)-1])
+				if err != nil {
+					qx.ErrExit(err)
+				}
+				fmt.Printf("%s\n", addr)
+			}
+		} else { //try from STDIN
+			src, err := ioutil.ReadAll(os.Stdin)
+			if err != nil {
+				errExit(err)
+			}
+			str := strings.TrimSpace(string(src))
+			addr, err := qx.Bech32ToAddr(str)
+			if err != nil {
+				qx.ErrExit(err)
+			}
+			fmt.Printf("%s\n", addr)
+		}
+	}
+
 	if txDecodeCmd.Parsed() {
 		stat, _ := os.Stdin.Stat()
 		if (stat.Mode() & os.ModeNamedPipe) == 0 {
@@ -1422,6 +1711,24 @@ example:
 		}
 	}
 
+	if txBuildCmd.Parsed() {
+		stat, _ := os.Stdin.Stat()
+		if (stat.Mode() & os.ModeNamedPipe) == 0 {
+			if len(os.Args) == 2 || os.Args[2] == "help" || os.Args[2] == "--help" {
+				txBuildCmd.Usage()
+			} else {
+				qx.TxBuildSTDO(network, os.Args[len(os.Args)-1])
+			}
+		} else { //try from STDIN
+			src, err := ioutil.ReadAll(os.Stdin)
+			if err != nil {
+				errExit(err)
+			}
+			str := strings.TrimSpace(string(src))
+			qx.TxBuildSTDO(network, str)
+		}
+	}
+
 	if txSignCmd.Parsed() {
 		stat, _ := os.Stdin.Stat()
 		if (stat.Mode() & os.ModeNamedPipe) == 0 {
@@ -1440,13 +1747,307 @@ example:
 		}
 	}
 
+	if psbtCreateCmd.Parsed() {
+		stat, _ := os.Stdin.Stat()
+		if (stat.Mode() & os.ModeNamedPipe) == 0 {
+			if len(os.Args) == 2 || os.Args[2] == "help" || os.Args[2] == "--help" {
+				psbtCreateCmd.Usage()
+			} else {
+				qx.PsbtCreateSTDO(os.Args[len(os.Args)-1])
+			}
+		} else { //try from STDIN
+			src, err := ioutil.ReadAll(os.Stdin)
+			if err != nil {
+				errExit(err)
+			}
+			str := strings.TrimSpace(string(src))
+			qx.PsbtCreateSTDO(str)
+		}
+	}
+
+	if psbtUpdateCmd.Parsed() {
+		stat, _ := os.Stdin.Stat()
+		if (stat.Mode() & os.ModeNamedPipe) == 0 {
+			if len(os.Args) == 2 || os.Args[2] == "help" || os.Args[2] == "--help" {
+				psbtUpdateCmd.Usage()
+			} else {
+				qx.PsbtUpdateSTDO(os.Args[len(os.Args)-1], psbtPrevTxs, psbtPrevOuts, psbtRedeemScripts, psbtSighashes)
+			}
+		} else { //try from STDIN
+			src, err := ioutil.ReadAll(os.Stdin)
+			if err != nil {
+				errExit(err)
+			}
+			str := strings.TrimSpace(string(src))
+			qx.PsbtUpdateSTDO(str, psbtPrevTxs, psbtPrevOuts, psbtRedeemScripts, psbtSighashes)
+		}
+	}
+
+	if psbtSignCmd.Parsed() {
+		stat, _ := os.Stdin.Stat()
+		if (stat.Mode() & os.ModeNamedPipe) == 0 {
+			if len(os.Args) == 2 || os.Args[2] == "help" || os.Args[2] == "--help" {
+				psbtSignCmd.Usage()
+			} else {
+				qx.PsbtSignSTDO(privateKeys, os.Args[len(os.Args)-1])
+			}
+		} else { //try from STDIN
+			src, err := ioutil.ReadAll(os.Stdin)
+			if err != nil {
+				errExit(err)
+			}
+			str := strings.TrimSpace(string(src))
+			qx.PsbtSignSTDO(privateKeys, str)
+		}
+	}
+
+	if psbtCombineCmd.Parsed() {
+		if psbtCombineCmd.NArg() < 2 {
+			psbtCombineCmd.Usage()
+		} else {
+			qx.PsbtCombineSTDO(psbtCombineCmd.Args())
+		}
+	}
+
+	if psbtFinalizeCmd.Parsed() {
+		stat, _ := os.Stdin.Stat()
+		if (stat.Mode() & os.ModeNamedPipe) == 0 {
+			if len(os.Args) == 2 || os.Args[2] == "help" || os.Args[2] == "--help" {
+				psbtFinalizeCmd.Usage()
+			} else {
+				qx.PsbtFinalizeSTDO(os.Args[len(os.Args)-1])
+			}
+		} else { //try from STDIN
+			src, err := ioutil.ReadAll(os.Stdin)
+			if err != nil {
+				errExit(err)
+			}
+			str := strings.TrimSpace(string(src))
+			qx.PsbtFinalizeSTDO(str)
+		}
+	}
+
+	if psbtExtractCmd.Parsed() {
+		stat, _ := os.Stdin.Stat()
+		if (stat.Mode() & os.ModeNamedPipe) == 0 {
+			if len(os.Args) == 2 || os.Args[2] == "help" || os.Args[2] == "--help" {
+				psbtExtractCmd.Usage()
+			} else {
+				qx.PsbtExtractSTDO(os.Args[len(os.Args)-1])
+			}
+		} else { //try from STDIN
+			src, err := ioutil.ReadAll(os.Stdin)
+			if err != nil {
+				errExit(err)
+			}
+			str := strings.TrimSpace(string(src))
+			qx.PsbtExtractSTDO(str)
+		}
+	}
+
+	if psbtDecodeCmd.Parsed() {
+		stat, _ := os.Stdin.Stat()
+		if (stat.Mode() & os.ModeNamedPipe) == 0 {
+			if len(os.Args) == 2 || os.Args[2] == "help" || os.Args[2] == "--help" {
+				psbtDecodeCmd.Usage()
+			} else {
+				qx.PsbtDecode(network, os.Args[len(os.Args)-1])
+			}
+		} else { //try from STDIN
+			src, err := ioutil.ReadAll(os.Stdin)
+			if err != nil {
+				errExit(err)
+			}
+			str := strings.TrimSpace(string(src))
+			qx.PsbtDecode(network, str)
+		}
+	}
+
+	if htlcNewCmd.Parsed() {
+		if htlcRecipient == "" || htlcRefund == "" || (htlcSecretHash == "" && htlcSecret == "") {
+			htlcNewCmd.Usage()
+		} else {
+			qx.HTLCNewSTDO(network, htlcRecipient, htlcRefund, htlcSecretHash, htlcSecret, htlcLockTime, htlcHashType)
+		}
+	}
+
+	if htlcRedeemCmd.Parsed() {
+		stat, _ := os.Stdin.Stat()
+		if (stat.Mode() & os.ModeNamedPipe) == 0 {
+			if len(os.Args) == 2 || os.Args[2] == "help" || os.Args[2] == "--help" {
+				htlcRedeemCmd.Usage()
+			} else {
+				qx.HTLCRedeemSTDO(htlcPrivateKey, os.Args[len(os.Args)-1], htlcInputIndex, htlcContract, htlcSecret, htlcP2sh)
+			}
+		} else { //try from STDIN
+			src, err := ioutil.ReadAll(os.Stdin)
+			if err != nil {
+				errExit(err)
+			}
+			str := strings.TrimSpace(string(src))
+			qx.HTLCRedeemSTDO(htlcPrivateKey, str, htlcInputIndex, htlcContract, htlcSecret, htlcP2sh)
+		}
+	}
+
+	if htlcRefundCmd.Parsed() {
+		stat, _ := os.Stdin.Stat()
+		if (stat.Mode() & os.ModeNamedPipe) == 0 {
+			if len(os.Args) == 2 || os.Args[2] == "help" || os.Args[2] == "--help" {
+				htlcRefundCmd.Usage()
+			} else {
+				qx.HTLCRefundSTDO(htlcPrivateKey, os.Args[len(os.Args)-1], htlcInputIndex, htlcContract, htlcP2sh)
+			}
+		} else { //try from STDIN
+			src, err := ioutil.ReadAll(os.Stdin)
+			if err != nil {
+				errExit(err)
+			}
+			str := strings.TrimSpace(string(src))
+			qx.HTLCRefundSTDO(htlcPrivateKey, str, htlcInputIndex, htlcContract, htlcP2sh)
+		}
+	}
+
+	if musig2KeyAggCmd.Parsed() {
+		stat, _ := os.Stdin.Stat()
+		if (stat.Mode() & os.ModeNamedPipe) == 0 {
+			if len(os.Args) == 2 || os.Args[2] == "help" || os.Args[2] == "--help" {
+				musig2KeyAggCmd.Usage()
+			} else {
+				qx.MuSig2KeyAggSTDO(network, os.Args[len(os.Args)-1])
+			}
+		} else { //try from STDIN
+			src, err := ioutil.ReadAll(os.Stdin)
+			if err != nil {
+				errExit(err)
+			}
+			str := strings.TrimSpace(string(src))
+			qx.MuSig2KeyAggSTDO(network, str)
+		}
+	}
+
+	if musig2NonceCmd.Parsed() {
+		stat, _ := os.Stdin.Stat()
+		if (stat.Mode() & os.ModeNamedPipe) == 0 {
+			if len(os.Args) == 2 || os.Args[2] == "help" || os.Args[2] == "--help" {
+				musig2NonceCmd.Usage()
+			} else {
+				qx.MuSig2NonceSTDO(musig2PrivateKey, musig2PubKeys, os.Args[len(os.Args)-1], musig2InputIndex, musig2ScriptType)
+			}
+		} else { //try from STDIN
+			src, err := ioutil.ReadAll(os.Stdin)
+			if err != nil {
+				errExit(err)
+			}
+			str := strings.TrimSpace(string(src))
+			qx.MuSig2NonceSTDO(musig2PrivateKey, musig2PubKeys, str, musig2InputIndex, musig2ScriptType)
+		}
+	}
+
+	if musig2NonceAggCmd.Parsed() {
+		stat, _ := os.Stdin.Stat()
+		if (stat.Mode() & os.ModeNamedPipe) == 0 {
+			if len(os.Args) == 2 || os.Args[2] == "help" || os.Args[2] == "--help" {
+				musig2NonceAggCmd.Usage()
+			} else {
+				qx.MuSig2NonceAggSTDO(os.Args[len(os.Args)-1])
+			}
+		} else { //try from STDIN
+			src, err := ioutil.ReadAll(os.Stdin)
+			if err != nil {
+				errExit(err)
+			}
+			str := strings.TrimSpace(string(src))
+			qx.MuSig2NonceAggSTDO(str)
+		}
+	}
+
+	if musig2SignCmd.Parsed() {
+		stat, _ := os.Stdin.Stat()
+		if (stat.Mode() & os.ModeNamedPipe) == 0 {
+			if len(os.Args) == 2 || os.Args[2] == "help" || os.Args[2] == "--help" {
+				musig2SignCmd.Usage()
+			} else {
+				qx.MuSig2SignSTDO(musig2PrivateKey, musig2SecNonce, musig2PubKeys, musig2AggNonce, os.Args[len(os.Args)-1], musig2InputIndex, musig2ScriptType)
+			}
+		} else { //try from STDIN
+			src, err := ioutil.ReadAll(os.Stdin)
+			if err != nil {
+				errExit(err)
+			}
+			str := strings.TrimSpace(string(src))
+			qx.MuSig2SignSTDO(musig2PrivateKey, musig2SecNonce, musig2PubKeys, musig2AggNonce, str, musig2InputIndex, musig2ScriptType)
+		}
+	}
+
+	if musig2CombineCmd.Parsed() {
+		stat, _ := os.Stdin.Stat()
+		if (stat.Mode() & os.ModeNamedPipe) == 0 {
+			if len(os.Args) == 2 || os.Args[2] == "help" || os.Args[2] == "--help" {
+				musig2CombineCmd.Usage()
+			} else {
+				qx.MuSig2CombineSTDO(musig2PubKeys, musig2AggNonce, musig2PartialSigs, os.Args[len(os.Args)-1], musig2InputIndex, musig2ScriptType)
+			}
+		} else { //try from STDIN
+			src, err := ioutil.ReadAll(os.Stdin)
+			if err != nil {
+				errExit(err)
+			}
+			str := strings.TrimSpace(string(src))
+			qx.MuSig2CombineSTDO(musig2PubKeys, musig2AggNonce, musig2PartialSigs, str, musig2InputIndex, musig2ScriptType)
+		}
+	}
+
+	if descriptorInfoCmd.Parsed() {
+		stat, _ := os.Stdin.Stat()
+		if (stat.Mode() & os.ModeNamedPipe) == 0 {
+			if len(os.Args) == 2 || os.Args[2] == "help" || os.Args[2] == "--help" {
+				descriptorInfoCmd.Usage()
+			} else {
+				qx.DescriptorInfoSTDO(network, os.Args[len(os.Args)-1])
+			}
+		} else { //try from STDIN
+			src, err := ioutil.ReadAll(os.Stdin)
+			if err != nil {
+				errExit(err)
+			}
+			str := strings.TrimSpace(string(src))
+			qx.DescriptorInfoSTDO(network, str)
+		}
+	}
+
+	if descriptorDeriveCmd.Parsed() {
+		stat, _ := os.Stdin.Stat()
+		if (stat.Mode() & os.ModeNamedPipe) == 0 {
+			if len(os.Args) == 2 || os.Args[2] == "help" || os.Args[2] == "--help" {
+				descriptorDeriveCmd.Usage()
+			} else {
+				qx.DescriptorDeriveSTDO(network, os.Args[len(os.Args)-1], uint32(descriptorBegin), uint32(descriptorEnd))
+			}
+		} else { //try from STDIN
+			src, err := ioutil.ReadAll(os.Stdin)
+			if err != nil {
+				errExit(err)
+			}
+			str := strings.TrimSpace(string(src))
+			qx.DescriptorDeriveSTDO(network, str, uint32(descriptorBegin), uint32(descriptorEnd))
+		}
+	}
+
 	if msgSignCmd.Parsed() {
 		stat, _ := os.Stdin.Stat()
 		if (stat.Mode() & os.ModeNamedPipe) == 0 {
 			if len(os.Args) == 2 || os.Args[2] == "help" || os.Args[2] == "--help" {
 				msgSignCmd.Usage()
 			} else {
-				qx.MsgSign(msgSignatureMode, showDetails, os.Args[len(os.Args)-2], os.Args[len(os.Args)-1], showDetails)
+				if msgSignatureScheme != "secp256k1" {
+					sig, err := qx.MsgSignWithPubKey(msgSignatureScheme, os.Args[len(os.Args)-2], os.Args[len(os.Args)-1])
+					if err != nil {
+						qx.ErrExit(err)
+					}
+					fmt.Printf("%s\n", sig)
+				} else {
+					qx.MsgSign(msgSignatureMode, showDetails, os.Args[len(os.Args)-2], os.Args[len(os.Args)-1], showDetails)
+				}
 			}
 		}
 	}
@@ -1497,4 +2098,22 @@ example:
 			qx.ScriptEncode(str)
 		}
 	}
+
+	if scriptTraceCmd.Parsed() {
+		stat, _ := os.Stdin.Stat()
+		if (stat.Mode() & os.ModeNamedPipe) == 0 {
+			if len(os.Args) == 2 || os.Args[2] == "help" || os.Args[2] == "--help" || tracePkScript == "" {
+				scriptTraceCmd.Usage()
+			} else {
+				qx.ScriptTraceSTDO(os.Args[len(os.Args)-1], traceInputIndex, tracePkScript, traceAmount)
+			}
+		} else { //try from STDIN
+			src, err := ioutil.ReadAll(os.Stdin)
+			if err != nil {
+				errExit(err)
+			}
+			str := strings.TrimSpace(string(src))
+			qx.ScriptTraceSTDO(str, traceInputIndex, tracePkScript, traceAmount)
+		}
+	}
 }
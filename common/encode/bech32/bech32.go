@@ -227,9 +227,23 @@ const bech32_charset = "qpzry9x8gf2tvdw0s3jn54khce6mua7l"
 
 var gen = []int{0x3b6a57b2, 0x26508e6d, 0x1ea119fa, 0x3d4233dd, 0x2a1462b3}
 
+// bech32mConst is the constant the bech32m checksum is xored with instead
+// of 1, please refer to BIP 350.
+const bech32mConst = 0x2bc830a3
+
 // Decode decodes a bech32 encoded string, returning the human-readable
 // part and the data part excluding the checksum.
 func DecodeBech32(bech string) (string, []byte, error) {
+	return decodeBech32(bech, 1)
+}
+
+// DecodeBech32m decodes a bech32m encoded string, returning the
+// human-readable part and the data part excluding the checksum.
+func DecodeBech32m(bech string) (string, []byte, error) {
+	return decodeBech32(bech, bech32mConst)
+}
+
+func decodeBech32(bech string, checksumConst int) (string, []byte, error) {
 	// The maximum allowed length for a bech32 string is 90. It must also
 	// be at least 8 characters, since it needs a non-empty HRP, a
 	// separator, and a 6 character checksum.
@@ -277,11 +291,11 @@ func DecodeBech32(bech string) (string, []byte, error) {
 			"%v", err)
 	}
 
-	if !bech32VerifyChecksum(hrp, decoded) {
+	if !bech32VerifyChecksum(hrp, decoded, checksumConst) {
 		moreInfo := ""
 		checksum := bech[len(bech)-6:]
 		expected, err := toChars(bech32Checksum(hrp,
-			decoded[:len(decoded)-6]))
+			decoded[:len(decoded)-6], checksumConst))
 		if err == nil {
 			moreInfo = fmt.Sprintf("Expected %v, got %v.",
 				expected, checksum)
@@ -297,8 +311,26 @@ func DecodeBech32(bech string) (string, []byte, error) {
 // human-readable part hrb. Note that the bytes must each encode 5 bits
 // (base32).
 func EncodeBech32(hrp string, data []byte) (string, error) {
+	return encodeBech32(hrp, data, 1)
+}
+
+// EncodeBech32m encodes a byte slice into a bech32m string with the
+// human-readable part hrp.  Note that the bytes must each encode 5 bits
+// (base32).
+func EncodeBech32m(hrp string, data []byte) (string, error) {
+	// The encoding must be decodable by DecodeBech32m, so it is limited to
+	// the same 90 characters, which are the hrp, the separator, data and
+	// the 6 character checksum.
+	if len(hrp)+1+len(data)+6 > 90 {
+		return "", fmt.Errorf("invalid bech32m string length %d",
+			len(hrp)+1+len(data)+6)
+	}
+	return encodeBech32(hrp, data, bech32mConst)
+}
+
+func encodeBech32(hrp string, data []byte, checksumConst int) (string, error) {
 	// Calculate the checksum of the data and append it at the end.
-	checksum := bech32Checksum(hrp, data)
+	checksum := bech32Checksum(hrp, data, checksumConst)
 	combined := append(data, checksum...)
 
 	// The resulting bech32 string is the concatenation of the hrp, the
@@ -409,8 +441,9 @@ func ConvertBits(data []byte, fromBits, toBits uint8, pad bool) ([]byte, error)
 	return regrouped, nil
 }
 
-// For more details on the checksum calculation, please refer to BIP 173.
-func bech32Checksum(hrp string, data []byte) []byte {
+// For more details on the checksum calculation, please refer to BIP 173 and
+// BIP 350 for the checksum constant.
+func bech32Checksum(hrp string, data []byte, checksumConst int) []byte {
 	// Convert the bytes to list of integers, as this is needed for the
 	// checksum calculation.
 	integers := make([]int, len(data))
@@ -419,7 +452,7 @@ func bech32Checksum(hrp string, data []byte) []byte {
 	}
 	values := append(bech32HrpExpand(hrp), integers...)
 	values = append(values, []int{0, 0, 0, 0, 0, 0}...)
-	polymod := bech32Polymod(values) ^ 1
+	polymod := bech32Polymod(values) ^ checksumConst
 	var res []byte
 	for i := 0; i < 6; i++ {
 		res = append(res, byte((polymod>>uint(5*(5-i)))&31))
@@ -456,11 +489,11 @@ func bech32HrpExpand(hrp string) []int {
 }
 
 // For more details on the checksum verification, please refer to BIP 173.
-func bech32VerifyChecksum(hrp string, data []byte) bool {
+func bech32VerifyChecksum(hrp string, data []byte, checksumConst int) bool {
 	integers := make([]int, len(data))
 	for i, b := range data {
 		integers[i] = int(b)
 	}
 	concat := append(bech32HrpExpand(hrp), integers...)
-	return bech32Polymod(concat) == 1
+	return bech32Polymod(concat) == checksumConst
 }
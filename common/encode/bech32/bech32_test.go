@@ -464,3 +464,68 @@ func TestBech32(t *testing.T) {
 		}
 	}
 }
+
+var validBech32m = []string{
+	"A1LQFN3A",
+	"a1lqfn3a",
+	"an83characterlonghumanreadablepartthatcontainsthetheexcludedcharactersbioandnumber11sg7hg6",
+	"abcdef1l7aum6echk45nj3s0wdvt2fg8x9yrzpqzd3ryx",
+	"split1checkupstagehandshakeupstreamerranterredcaperredlc445v",
+	"?1v759aa",
+}
+
+var invalidBech32m = []string{
+	"\x7F" + "1g6xzxy",
+	"an84characterslonghumanreadablepartthatcontainsthetheexcludedcharactersbioandnumber11d6pts4",
+	"qyrz8wqd2c9m",
+	"1qyrz8wqd2c9m",
+	"y1b0jsk6g",
+	"lt1igcx5c0",
+	"in1muywd",
+	"mm1crxm3i",
+	"au1s5cgom",
+	"M1VUXWEZ",
+	"16plkw9",
+	"1p2gdwpf",
+	// valid bech32 strings fail the bech32m checksum
+	"A12UEL5L",
+	"abcdef1qpzry9x8gf2tvdw0s3jn54khce6mua7lmqqqxw",
+}
+
+func TestBech32m(t *testing.T) {
+	for _, test := range validBech32m {
+		hrp, data, err := bech32.DecodeBech32m(test)
+		if err != nil {
+			t.Errorf("Valid bech32m for %s : FAIL / error %+v\n", test, err)
+			continue
+		}
+		encoded, err := bech32.EncodeBech32m(hrp, data)
+		if err != nil {
+			t.Errorf("Encode bech32m for %s : FAIL / error %+v\n", test, err)
+			continue
+		}
+		if encoded != strings.ToLower(test) {
+			t.Errorf("Encode bech32m for %s : FAIL / got %s\n", test, encoded)
+		}
+		if _, _, err := bech32.DecodeBech32(test); err == nil {
+			t.Errorf("Valid bech32m %s passes the bech32 checksum\n", test)
+		}
+	}
+	for _, test := range invalidBech32m {
+		if _, _, err := bech32.DecodeBech32m(test); err == nil {
+			t.Errorf("Invalid bech32m for %s : FAIL\n", test)
+		}
+	}
+
+	// The encoding over 90 characters can't be decoded.
+	if _, err := bech32.EncodeBech32m("meer", make([]byte, 79)); err != nil {
+		t.Errorf("Encode bech32m of 90 characters : FAIL / error %+v\n", err)
+	}
+	if _, err := bech32.EncodeBech32m("meer", make([]byte, 80)); err == nil {
+		t.Errorf("Encode bech32m of 91 characters : FAIL\n")
+	}
+	// The bech32 encoding keeps no limit.
+	if _, err := bech32.EncodeBech32("meer", make([]byte, 80)); err != nil {
+		t.Errorf("Encode bech32 of 91 characters : FAIL / error %+v\n", err)
+	}
+}
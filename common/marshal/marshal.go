@@ -2,15 +2,20 @@ package marshal
 
 import (
 	"bytes"
+	"encoding/binary"
 	"encoding/hex"
 	"errors"
 	"fmt"
 	"github.com/Qitmeer/qng/common/hash"
 	"github.com/Qitmeer/qng/consensus/vm"
+	"github.com/Qitmeer/qng/core/address"
 	"github.com/Qitmeer/qng/core/blockchain/opreturn"
 	"github.com/Qitmeer/qng/core/json"
 	"github.com/Qitmeer/qng/core/protocol"
+	"github.com/Qitmeer/qng/core/psbt"
 	"github.com/Qitmeer/qng/core/types"
+	"github.com/Qitmeer/qng/crypto/bip32"
+	"github.com/Qitmeer/qng/crypto/ecc"
 	"github.com/Qitmeer/qng/engine/txscript"
 	"github.com/Qitmeer/qng/meerdag"
 	"github.com/Qitmeer/qng/meerevm/common"
@@ -385,3 +390,186 @@ func GetGraphStateResult(gs *meerdag.GraphState) *json.GetGraphStateResult {
 	}
 	return nil
 }
+
+// MarshalJsonPsbt returns the json result of the partially signed
+// transaction.
+func MarshalJsonPsbt(p *psbt.Packet, params *params.Params) *json.DecodePsbtResult {
+	tx := p.UnsignedTx
+	result := &json.DecodePsbtResult{
+		Tx: &json.OrderedResult{
+			{Key: "txid", Val: tx.TxHash().String()},
+			{Key: "version", Val: int32(tx.Version)},
+			{Key: "locktime", Val: tx.LockTime},
+			{Key: "timestamp", Val: tx.Timestamp.Format(time.RFC3339)},
+			{Key: "vin", Val: MarshJsonVin(tx)},
+			{Key: "vout", Val: MarshJsonVout(tx, nil, params)},
+		},
+		Unknown:  marshalPsbtUnknowns(p.Unknowns),
+		Inputs:   make([]json.PsbtInputResult, len(p.Inputs)),
+		Outputs:  make([]json.PsbtOutputResult, len(p.Outputs)),
+		Complete: p.IsComplete(),
+	}
+	for i, pi := range p.Inputs {
+		ir := &result.Inputs[i]
+		if pi.PrevOut != nil {
+			prevTx := types.NewTransaction()
+			prevTx.AddTxOut(pi.PrevOut)
+			ir.PrevOut = &MarshJsonVout(prevTx, nil, params)[0]
+		}
+		if len(pi.PartialSigs) > 0 {
+			ir.PartialSignatures = map[string]string{}
+			for _, ps := range pi.PartialSigs {
+				ir.PartialSignatures[hex.EncodeToString(ps.PubKey)] = hex.EncodeToString(ps.Signature)
+			}
+		}
+		if pi.SighashType != 0 {
+			ir.SighashType = psbt.SighashTypeString(pi.SighashType)
+		}
+		ir.RedeemScript = marshalPsbtScript(pi.RedeemScript)
+		ir.Bip32Derivs = marshalPsbtBip32Derivs(pi.Bip32Derivation)
+		ir.FinalScriptSig = marshalPsbtScript(pi.FinalScriptSig)
+		ir.Unknown = marshalPsbtUnknowns(pi.Unknowns)
+	}
+	for i, po := range p.Outputs {
+		or := &result.Outputs[i]
+		or.RedeemScript = marshalPsbtScript(po.RedeemScript)
+		or.Bip32Derivs = marshalPsbtBip32Derivs(po.Bip32Derivation)
+		or.Unknown = marshalPsbtUnknowns(po.Unknowns)
+	}
+	fees, err := p.Fee()
+	if err == nil {
+		result.Fee = map[string]int64{}
+		for coin, fee := range fees {
+			result.Fee[coin.Name()] = fee
+		}
+	}
+	return result
+}
+
+func marshalPsbtScript(script []byte) *json.ScriptSig {
+	if len(script) <= 0 {
+		return nil
+	}
+	disbuf, _ := txscript.DisasmString(script)
+	return &json.ScriptSig{Asm: disbuf, Hex: hex.EncodeToString(script)}
+}
+
+func marshalPsbtBip32Derivs(derivs []*psbt.Bip32Derivation) []json.PsbtBip32DerivResult {
+	if len(derivs) <= 0 {
+		return nil
+	}
+	result := make([]json.PsbtBip32DerivResult, len(derivs))
+	for i, d := range derivs {
+		path := "m"
+		for _, idx := range d.Path {
+			if idx >= bip32.FirstHardenedChild {
+				path += fmt.Sprintf("/%d'", idx-bip32.FirstHardenedChild)
+			} else {
+				path += fmt.Sprintf("/%d", idx)
+			}
+		}
+		fingerprint := make([]byte, 4)
+		binary.LittleEndian.PutUint32(fingerprint, d.MasterKeyFingerprint)
+		result[i] = json.PsbtBip32DerivResult{
+			PubKey:            hex.EncodeToString(d.PubKey),
+			MasterFingerprint: hex.EncodeToString(fingerprint),
+			Path:              path,
+		}
+	}
+	return result
+}
+
+func marshalPsbtUnknowns(unknowns []*psbt.Unknown) map[string]string {
+	if len(unknowns) <= 0 {
+		return nil
+	}
+	result := map[string]string{}
+	for _, u := range unknowns {
+		result[hex.EncodeToString(u.Key)] = hex.EncodeToString(u.Value)
+	}
+	return result
+}
+
+// traceScriptNames are the names of the scripts executed for an input.
+var traceScriptNames = []string{"signScript", "pkScript", "redeemScript"}
+
+// MarshalJsonTrace returns the json of the steps traced by the script engine
+// for the input, and err is the result of the execution.
+func MarshalJsonTrace(tx *types.Transaction, txIdx int, amount int64, pkScript []byte, steps []*txscript.TraceStep, err error) *json.TraceTxInputResult {
+	asm, _ := txscript.DisasmString(pkScript)
+	result := &json.TraceTxInputResult{
+		TxId:        tx.TxHash().String(),
+		Input:       txIdx,
+		Amount:      amount,
+		PkScriptAsm: asm,
+		Steps:       make([]json.TraceStepResult, len(steps)),
+		Valid:       err == nil,
+	}
+	if err != nil {
+		result.Error = err.Error()
+	}
+	for i, step := range steps {
+		script := fmt.Sprintf("%d", step.ScriptIdx)
+		if step.ScriptIdx < len(traceScriptNames) {
+			script = traceScriptNames[step.ScriptIdx]
+		}
+		result.Steps[i] = json.TraceStepResult{
+			Script:         script,
+			Offset:         step.ScriptOff,
+			Opcode:         step.Opcode,
+			Executed:       step.Executed,
+			StackBefore:    marshalTraceStack(step.StackBefore),
+			AltStackBefore: marshalTraceStack(step.AltStackBefore),
+			BranchBefore:   step.CondStackBefore,
+			StackAfter:     marshalTraceStack(step.StackAfter),
+			AltStackAfter:  marshalTraceStack(step.AltStackAfter),
+			BranchAfter:    step.CondStackAfter,
+		}
+		if step.Err != nil {
+			result.Steps[i].Error = step.Err.Error()
+		}
+	}
+	return result
+}
+
+func marshalTraceStack(stack [][]byte) []string {
+	result := make([]string, len(stack))
+	for i, item := range stack {
+		result[i] = hex.EncodeToString(item)
+	}
+	return result
+}
+
+// MarshalJsonHTLC returns the json of the hash time-locked contract.
+func MarshalJsonHTLC(contract []byte, params *params.Params) (*json.HTLCResult, error) {
+	pushes, err := txscript.ExtractHTLCDataPushes(contract)
+	if err != nil {
+		return nil, err
+	}
+	asm, err := txscript.DisasmString(contract)
+	if err != nil {
+		return nil, err
+	}
+	p2sh, err := address.NewScriptHashAddress(contract, params)
+	if err != nil {
+		return nil, err
+	}
+	recipient, err := address.NewPubKeyHashAddress(pushes.RecipientHash160[:], params, ecc.ECDSA_Secp256k1)
+	if err != nil {
+		return nil, err
+	}
+	refund, err := address.NewPubKeyHashAddress(pushes.RefundHash160[:], params, ecc.ECDSA_Secp256k1)
+	if err != nil {
+		return nil, err
+	}
+	return &json.HTLCResult{
+		Contract:    hex.EncodeToString(contract),
+		Asm:         asm,
+		P2shAddress: p2sh.String(),
+		Recipient:   recipient.String(),
+		Refund:      refund.String(),
+		HashType:    txscript.HTLCHashOpName(pushes.HashOp),
+		SecretHash:  hex.EncodeToString(pushes.SecretHash[:]),
+		LockTime:    pushes.LockTime,
+	}, nil
+}
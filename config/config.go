@@ -24,6 +24,8 @@ type Config struct {
 	DisableRPC         bool     `long:"norpc" description:"Disable built-in RPC server -- NOTE: The RPC server is disabled by default if no rpcuser/rpcpass or rpclimituser/rpclimitpass is specified"`
 	DisableTLS         bool     `long:"notls" description:"Disable TLS for the RPC server -- NOTE: This is only allowed if the RPC server is bound to localhost"`
 	Modules            []string `long:"modules" description:"Modules is a list of API modules(See GetNodeInfo) to expose via the HTTP RPC interface. If the module list is empty, all RPC API endpoints designated public will be exposed."`
+	REST               bool     `long:"rest" description:"Enable the read-only REST interface (/rest/...) on the RPC listeners"`
+	GraphQL            bool     `long:"graphql" description:"Enable the read-only GraphQL endpoint (/graphql) on the RPC listeners"`
 	DisableCheckpoints bool     `long:"nocheckpoints" description:"Disable built-in checkpoints.  Don't do this unless you know what you're doing."`
 	LightNode          bool     `long:"light" description:"start as a qitmeer light node"`
 	SigCacheMaxSize    uint     `long:"sigcachemaxsize" description:"The maximum number of entries in the signature verification cache"`
@@ -80,12 +82,20 @@ type Config struct {
 	Zmqpubhashtx string `long:"zmqpubhashtx" description:"Enable publish hash transaction in <address>"`
 	Zmqpubrawtx  string `long:"zmqpubrawtx" description:"Enable publish raw transaction in <address>"`
 
+	Zmqpubreorg string `long:"zmqpubreorg" description:"Enable publish reorganization diff in <address>"`
+
 	// index
-	AddrIndex      bool `long:"addrindex" description:"Maintain a full address-based transaction index which makes the getrawtransactions RPC available"`
-	VMBlockIndex   bool `long:"vmblockindex" description:"Maintain a full vm block index which makes the GetTxIDByMeerEVMTxHash RPC available"`
-	InvalidTxIndex bool `long:"invalidtxindex" description:"Cache invalid transactions."`
-	DropAddrIndex  bool `long:"dropaddrindex" description:"Deletes the address-based transaction index from the database on start up and then exits."`
-	DropTxIndex    bool `long:"droptxindex" description:"Deletes the hash-based transaction index from the database on start up and then exits."`
+	AddrIndex           bool `long:"addrindex" description:"Maintain a full address-based transaction index which makes the getrawtransactions RPC available"`
+	VMBlockIndex        bool `long:"vmblockindex" description:"Maintain a full vm block index which makes the GetTxIDByMeerEVMTxHash RPC available"`
+	InvalidTxIndex      bool `long:"invalidtxindex" description:"Cache invalid transactions."`
+	SpentIndex          bool `long:"spentindex" description:"Maintain a full spent outpoint index which makes the getSpendingTx RPC available"`
+	TokenIndex          bool `long:"tokenindex" description:"Maintain a token activity and holder index which makes the getTokenHolders, getTokenHistory and getTokenBalances RPCs available"`
+	CrossChainIndex     bool `long:"crosschainindex" description:"Maintain a cross chain transfer index which makes the getCrossChainTransfer and listCrossChainTransfers RPCs available"`
+	DropAddrIndex       bool `long:"dropaddrindex" description:"Deletes the address-based transaction index from the database on start up and then exits."`
+	DropSpentIndex      bool `long:"dropspentindex" description:"Deletes the spent outpoint index from the database on start up and then exits."`
+	DropTokenIndex      bool `long:"droptokenindex" description:"Deletes the token index from the database on start up and then exits."`
+	DropCrossChainIndex bool `long:"dropcrosschainindex" description:"Deletes the cross chain transfer index from the database on start up and then exits."`
+	DropTxIndex         bool `long:"droptxindex" description:"Deletes the hash-based transaction index from the database on start up and then exits."`
 
 	NTP bool `long:"ntp" description:"Auto sync time."`
 
@@ -0,0 +1,26 @@
+package forks
+
+import (
+	"github.com/Qitmeer/qng/core/protocol"
+	"github.com/Qitmeer/qng/params"
+)
+
+// The main heights from which the consensus rules enforce the token opcodes,
+// the blocks before them keep the token opcodes as NOPs.  A network without
+// a height doesn't enforce them in the consensus rules, which is the case
+// until an activation height is scheduled, since the existing token scripts
+// of its chain must not be validated again under the new rules.  The private
+// network has no chain to keep, so it enforces them from the genesis.
+var tokenOpcodesForkMainHeights = map[protocol.Network]int64{
+	protocol.PrivNet: 0,
+}
+
+// IsTokenOpcodesForkHeight returns whether the blocks of the main height
+// enforce the token opcodes against the token state.
+func IsTokenOpcodesForkHeight(mainHeight int64) bool {
+	forkHeight, ok := tokenOpcodesForkMainHeights[params.ActiveNetParams.Net]
+	if !ok {
+		return false
+	}
+	return mainHeight >= forkHeight
+}
@@ -1,10 +0,0 @@
-package model
-
-import (
-	"github.com/Qitmeer/qng/core/types"
-)
-
-type Acct interface {
-	Apply(add bool, op *types.TxOutPoint, entry interface{}) error
-	Commit() error
-}
@@ -14,4 +14,6 @@ type BlockChain interface {
 	BlockByOrder(blockOrder uint64) (*types.SerializedBlock, error)
 	Rebuild() error
 	GetMiningTips(expectPriority int) []*hash.Hash
+	ChainLock()
+	ChainUnlock()
 }
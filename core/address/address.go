@@ -25,6 +25,18 @@ func encodeAddress(hash160 []byte, netID [2]byte) string {
 // encodePKAddress returns a human-readable payment address to a public key
 // given a serialized public key, a netID, and a signature suite.
 func encodePKAddress(serializedPK []byte, netID [2]byte, algo ecc.EcType) string {
+	pubKeyBytes := pkAddressPayload(serializedPK, algo)
+	if pubKeyBytes == nil {
+		return ""
+	}
+	res, _ := base58.QitmeerCheckEncode(pubKeyBytes, netID[:])
+	return string(res)
+}
+
+// pkAddressPayload returns the 33 bytes encoded in a pay-to-pubkey address
+// given a serialized public key and a signature suite, or nil if the public
+// key is invalid.
+func pkAddressPayload(serializedPK []byte, algo ecc.EcType) []byte {
 	pubKeyBytes := []byte{0x00}
 
 	switch algo {
@@ -41,7 +53,7 @@ func encodePKAddress(serializedPK []byte, netID [2]byte, algo ecc.EcType) string
 	if algo == ecc.ECDSA_Secp256k1 || algo == ecc.ECDSA_SecpSchnorr {
 		pub, err := ecc.Secp256k1.ParsePubKey(serializedPK)
 		if err != nil {
-			return ""
+			return nil
 		}
 		pubSerComp := pub.SerializeCompressed()
 
@@ -53,9 +65,7 @@ func encodePKAddress(serializedPK []byte, netID [2]byte, algo ecc.EcType) string
 		compressed = pubSerComp[1:]
 	}
 
-	pubKeyBytes = append(pubKeyBytes, compressed...)
-	res, _ := base58.QitmeerCheckEncode(pubKeyBytes, netID[:])
-	return string(res)
+	return append(pubKeyBytes, compressed...)
 }
 
 // PubKeyFormat describes what format to use for a pay-to-pubkey address.
@@ -85,6 +95,24 @@ type ContractAddress struct {
 // DecodeAddress decodes the string encoding of an address and returns
 // the Address if addr is a valid encoding for a known address type
 func DecodeAddress(addr string) (types.Address, error) {
+	// The bech32m encoding starts with the human-readable part of a network.
+	// A base58 address can look like it too, so it is decoded as base58
+	// before the bech32m error is returned.
+	if net := detectNetworkForBech32Address(addr); net != nil {
+		a, err := decodeBech32Address(addr, net)
+		if err == nil {
+			return a, nil
+		}
+		if a, berr := decodeBase58Address(addr); berr == nil {
+			return a, nil
+		}
+		return nil, err
+	}
+	return decodeBase58Address(addr)
+}
+
+// decodeBase58Address decodes the base58 encoding of an address.
+func decodeBase58Address(addr string) (types.Address, error) {
 	// Switch on decoded length to determine the type.
 	decoded, netID, err := base58.QitmeerCheckDecode(addr)
 	if err != nil {
@@ -0,0 +1,195 @@
+// Copyright (c) 2017-2018 The qitmeer developers
+
+package address
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/Qitmeer/qng/common/encode/bech32"
+	"github.com/Qitmeer/qng/core/types"
+	"github.com/Qitmeer/qng/crypto/ecc"
+	"github.com/Qitmeer/qng/params"
+)
+
+// The kinds of the bech32m encoded addresses.  The network is given by the
+// human-readable part, so the first 5 bit group of the data part only tells
+// the address kind, which is followed by the same payload as the base58
+// encoding of the address.  Token outputs pay to these same kinds.
+const (
+	// bech32PKHKind is a secp256k1 pay-to-pubkey-hash address.
+	bech32PKHKind = 0
+
+	// bech32PKHEdwardsKind is an Ed25519 pay-to-pubkey-hash address.
+	bech32PKHEdwardsKind = 1
+
+	// bech32PKHSchnorrKind is a secp256k1 Schnorr pay-to-pubkey-hash
+	// address.
+	bech32PKHSchnorrKind = 2
+
+	// bech32ScriptHashKind is a pay-to-script-hash address.
+	bech32ScriptHashKind = 8
+
+	// bech32PubKeyKind is a pay-to-pubkey address, the payload tells the
+	// signature suite of the public key.
+	bech32PubKeyKind = 16
+)
+
+// bech32Networks are the networks whose human-readable part is detected
+// when decoding a bech32m address.
+var bech32Networks = []*params.Params{
+	&params.MainNetParams,
+	&params.TestNetParams,
+	&params.PrivNetParams,
+	&params.MixNetParams,
+}
+
+// EncodeBech32 returns the bech32m encoding of the address, which is the same
+// address as its String form in base58.  The encoding is lowercase, the
+// uppercase of it is valid too, e.g. for QR codes.
+func EncodeBech32(addr types.Address) (string, error) {
+	var (
+		net     *params.Params
+		kind    byte
+		payload []byte
+	)
+	switch a := addr.(type) {
+	case *PubKeyHashAddress:
+		net = a.net
+		switch a.EcType() {
+		case ecc.ECDSA_Secp256k1:
+			kind = bech32PKHKind
+		case ecc.EdDSA_Ed25519:
+			kind = bech32PKHEdwardsKind
+		case ecc.ECDSA_SecpSchnorr:
+			kind = bech32PKHSchnorrKind
+		default:
+			return "", ErrUnknownAddressType
+		}
+		payload = a.hash[:]
+	case *ScriptHashAddress:
+		net = a.net
+		kind = bech32ScriptHashKind
+		payload = a.hash[:]
+	case *SecpPubKeyAddress:
+		net = a.net
+		kind = bech32PubKeyKind
+		payload = pkAddressPayload(a.serialize(), ecc.ECDSA_Secp256k1)
+	case *EdwardsPubKeyAddress:
+		net = a.net
+		kind = bech32PubKeyKind
+		payload = pkAddressPayload(a.serialize(), ecc.EdDSA_Ed25519)
+	case *SecSchnorrPubKeyAddress:
+		net = a.net
+		kind = bech32PubKeyKind
+		payload = pkAddressPayload(a.serialize(), ecc.ECDSA_SecpSchnorr)
+	default:
+		return "", ErrUnknownAddressType
+	}
+	if net == nil || len(net.Bech32HRP) == 0 {
+		return "", fmt.Errorf("no bech32 human-readable part for the network of the address")
+	}
+	if payload == nil {
+		return "", ErrInvalidPubKeyFormat
+	}
+	data, err := bech32.ConvertBits(payload, 8, 5, true)
+	if err != nil {
+		return "", err
+	}
+	return bech32.EncodeBech32m(net.Bech32HRP, append([]byte{kind}, data...))
+}
+
+// IsBech32Address returns whether the address string is in the bech32m
+// encoding of a known network, regardless of whether it is valid.
+func IsBech32Address(addr string) bool {
+	return detectNetworkForBech32Address(addr) != nil
+}
+
+// NormalizeAddress returns the base58 encoding of a bech32m address, which is
+// how the addresses are keyed by the indexes and the watchers.  The other
+// strings are returned as they are.
+func NormalizeAddress(addr string) string {
+	if !IsBech32Address(addr) {
+		return addr
+	}
+	a, err := DecodeAddress(addr)
+	if err != nil {
+		return addr
+	}
+	return a.String()
+}
+
+// bech32Charset is the charset of the data part of the bech32 encoding.
+const bech32Charset = "qpzry9x8gf2tvdw0s3jn54khce6mua7l"
+
+// detectNetworkForBech32Address returns the network whose human-readable part
+// is before the last separator of the address, or nil for none.  The address
+// must have the length, the single case and the data charset of the bech32
+// encoding, so that a base58 address with the same beginning isn't taken for
+// it.
+func detectNetworkForBech32Address(addr string) *params.Params {
+	if len(addr) < 8 || len(addr) > 90 {
+		return nil
+	}
+	lower := strings.ToLower(addr)
+	if addr != lower && addr != strings.ToUpper(addr) {
+		return nil
+	}
+	one := strings.LastIndexByte(lower, '1')
+	if one < 1 || len(lower)-one-1 < 6 {
+		return nil
+	}
+	for _, c := range lower[one+1:] {
+		if !strings.ContainsRune(bech32Charset, c) {
+			return nil
+		}
+	}
+	hrp := lower[:one]
+	for _, net := range bech32Networks {
+		if len(net.Bech32HRP) > 0 && net.Bech32HRP == hrp {
+			return net
+		}
+	}
+	return nil
+}
+
+// decodeBech32Address decodes the bech32m encoding of an address for the
+// network.
+func decodeBech32Address(addr string, net *params.Params) (types.Address, error) {
+	_, data, err := bech32.DecodeBech32m(addr)
+	if err != nil {
+		if strings.HasPrefix(err.Error(), "checksum failed") {
+			return nil, ErrChecksumMismatch
+		}
+		return nil, fmt.Errorf("decoded address is of unknown format: %v",
+			err.Error())
+	}
+	if len(data) < 1 {
+		return nil, ErrUnknownAddressType
+	}
+	decoded, err := bech32.ConvertBits(data[1:], 5, 8, false)
+	if err != nil {
+		return nil, fmt.Errorf("decoded address is of unknown format: %v",
+			err.Error())
+	}
+
+	switch data[0] {
+	case bech32PubKeyKind:
+		return NewPubKeyAddress(decoded, net)
+
+	case bech32PKHKind:
+		return NewPubKeyHashAddress(decoded, net, ecc.ECDSA_Secp256k1)
+
+	case bech32PKHEdwardsKind:
+		return NewPubKeyHashAddress(decoded, net, ecc.EdDSA_Ed25519)
+
+	case bech32PKHSchnorrKind:
+		return NewPubKeyHashAddress(decoded, net, ecc.ECDSA_SecpSchnorr)
+
+	case bech32ScriptHashKind:
+		return NewScriptHashAddressFromHash(decoded, net)
+
+	default:
+		return nil, ErrUnknownAddressType
+	}
+}
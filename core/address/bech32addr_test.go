@@ -0,0 +1,146 @@
+// Copyright (c) 2017-2018 The qitmeer developers
+
+package address
+
+import (
+	"encoding/hex"
+	"strings"
+	"testing"
+
+	"github.com/Qitmeer/qng/core/types"
+	"github.com/Qitmeer/qng/crypto/ecc"
+	"github.com/Qitmeer/qng/params"
+)
+
+func TestBech32Address(t *testing.T) {
+	pkHash, _ := hex.DecodeString("8dc268a8e2876b941b95f3bd3b71050f003c5383")
+	pubKey, _ := hex.DecodeString("0354455a60d86273d322eebb913d87f428988ce97922a366f0a0867a426df78bc9")
+
+	tests := []struct {
+		name   string
+		prefix string
+		f      func() (types.Address, error)
+	}{
+		{
+			name:   "mainnet p2pkh",
+			prefix: "meer1q",
+			f: func() (types.Address, error) {
+				return NewPubKeyHashAddress(pkHash, &params.MainNetParams, ecc.ECDSA_Secp256k1)
+			},
+		},
+		{
+			name:   "testnet edwards p2pkh",
+			prefix: "tmeer1p",
+			f: func() (types.Address, error) {
+				return NewPubKeyHashAddress(pkHash, &params.TestNetParams, ecc.EdDSA_Ed25519)
+			},
+		},
+		{
+			name:   "privnet schnorr p2pkh",
+			prefix: "rmeer1z",
+			f: func() (types.Address, error) {
+				return NewPubKeyHashAddress(pkHash, &params.PrivNetParams, ecc.ECDSA_SecpSchnorr)
+			},
+		},
+		{
+			name:   "mixnet p2sh",
+			prefix: "xmeer1g",
+			f: func() (types.Address, error) {
+				return NewScriptHashAddressFromHash(pkHash, &params.MixNetParams)
+			},
+		},
+		{
+			name:   "mainnet secp256k1 p2pk",
+			prefix: "meer1s",
+			f: func() (types.Address, error) {
+				return NewSecpPubKeyAddress(pubKey, &params.MainNetParams)
+			},
+		},
+		{
+			name:   "testnet schnorr p2pk",
+			prefix: "tmeer1s",
+			f: func() (types.Address, error) {
+				return NewSecSchnorrPubKeyAddress(pubKey, &params.TestNetParams)
+			},
+		},
+	}
+
+	for _, test := range tests {
+		addr, err := test.f()
+		if err != nil {
+			t.Fatalf("%s: %v", test.name, err)
+		}
+		encoded, err := EncodeBech32(addr)
+		if err != nil {
+			t.Fatalf("%s: encode: %v", test.name, err)
+		}
+		if !strings.HasPrefix(encoded, test.prefix) {
+			t.Errorf("%s: got %s, want prefix %s", test.name, encoded, test.prefix)
+		}
+		if !IsBech32Address(encoded) {
+			t.Errorf("%s: %s is not detected as bech32", test.name, encoded)
+		}
+		if IsBech32Address(addr.String()) {
+			t.Errorf("%s: %s is detected as bech32", test.name, addr.String())
+		}
+		for _, s := range []string{encoded, strings.ToUpper(encoded)} {
+			decoded, err := DecodeAddress(s)
+			if err != nil {
+				t.Fatalf("%s: decode %s: %v", test.name, s, err)
+			}
+			if decoded.String() != addr.String() {
+				t.Errorf("%s: decode %s: got %s, want %s", test.name, s,
+					decoded.String(), addr.String())
+			}
+			if decoded.Encode() != addr.Encode() {
+				t.Errorf("%s: decode %s: got %s, want %s", test.name, s,
+					decoded.Encode(), addr.Encode())
+			}
+		}
+
+		// A single typo is detected by the checksum.
+		typo := []byte(encoded)
+		if typo[len(typo)-1] == 'q' {
+			typo[len(typo)-1] = 'p'
+		} else {
+			typo[len(typo)-1] = 'q'
+		}
+		if _, err := DecodeAddress(string(typo)); err != ErrChecksumMismatch {
+			t.Errorf("%s: decode %s: got %v, want %v", test.name, typo, err,
+				ErrChecksumMismatch)
+		}
+	}
+
+	// Mixed case is invalid.
+	addr, _ := NewPubKeyHashAddress(pkHash, &params.MainNetParams, ecc.ECDSA_Secp256k1)
+	encoded, _ := EncodeBech32(addr)
+	if _, err := DecodeAddress("MEER" + encoded[4:]); err == nil {
+		t.Errorf("mixed case %s is decoded", "MEER"+encoded[4:])
+	}
+}
+
+func TestBech32AddressDetection(t *testing.T) {
+	// The base58 addresses which begin like a bech32m address of a network
+	// are still decoded.
+	for _, addr := range []string{
+		"MeEr1k6NrPr4ES9bCLSQqVTw7uAHy3EJcnM",
+	} {
+		if IsBech32Address(addr) {
+			t.Errorf("%s is detected as bech32", addr)
+		}
+		decoded, err := DecodeAddress(addr)
+		if err != nil {
+			t.Fatalf("decode %s: %v", addr, err)
+		}
+		if decoded.String() != addr {
+			t.Errorf("decode %s: got %s", addr, decoded.String())
+		}
+	}
+
+	// The strings which aren't in the bech32 charset aren't bech32.
+	for _, addr := range []string{"meer1", "meer1qqqqqb", "meer1qqqqqqqqi", "Meer1qqqqqqqqq"} {
+		if IsBech32Address(addr) {
+			t.Errorf("%s is detected as bech32", addr)
+		}
+	}
+}
@@ -0,0 +1,130 @@
+// Copyright (c) 2017-2018 The qitmeer developers
+
+package address
+
+import (
+	"bytes"
+	"errors"
+	"github.com/Qitmeer/qng/common/hash"
+	"github.com/Qitmeer/qng/core/serialization"
+	"github.com/Qitmeer/qng/core/types"
+	"github.com/Qitmeer/qng/crypto/ecc"
+	"github.com/Qitmeer/qng/crypto/ecc/secp256k1"
+)
+
+// MessageSignaturePrefixMagic is the prefix of the signed message, which
+// distinguishes it from a transaction.
+const MessageSignaturePrefixMagic = "Qitmeer Signed Message:\n"
+
+const (
+	// compactSignatureLen is the length of the compact signature of a
+	// secp256k1 key.
+	compactSignatureLen = 65
+
+	// messageSignatureLen is the length of an ed25519 or schnorr signature,
+	// which follows the public key in the message signature.
+	messageSignatureLen = 64
+)
+
+var (
+	// ErrMalformedMessageSignature describes an error where the message
+	// signature isn't in the format of the address.
+	ErrMalformedMessageSignature = errors.New("malformed message signature")
+
+	// ErrMessageAddressType describes an error where the message is
+	// verified against an address which has no message signatures, like a
+	// script hash address.
+	ErrMessageAddressType = errors.New("address type has no message signatures")
+)
+
+// MessageHash returns the hash of the message which is signed.
+func MessageHash(msg string) []byte {
+	var buf bytes.Buffer
+	serialization.WriteVarString(&buf, 0, MessageSignaturePrefixMagic)
+	serialization.WriteVarString(&buf, 0, msg)
+	return hash.HashB(buf.Bytes())
+}
+
+// SignMessage returns the compact signature of the message by the secp256k1
+// private key, from which the public key is recovered.
+func SignMessage(key ecc.PrivateKey, compressed bool, msg string) ([]byte, error) {
+	return secp256k1.SignCompact(secp256k1.NewPrivateKey(key.GetD()), MessageHash(msg), compressed)
+}
+
+// SignMessageWithPubKey returns the message signature by the ed25519 or
+// schnorr private key, which is the compressed public key followed by the 64
+// byte signature, since the public key can't be recovered from it.
+func SignMessageWithPubKey(dsa ecc.DSA, key ecc.PrivateKey, msg string) ([]byte, error) {
+	r, s, err := dsa.Sign(key, MessageHash(msg))
+	if err != nil {
+		return nil, err
+	}
+	sig := dsa.NewSignature(r, s).Serialize()
+	if len(sig) != messageSignatureLen {
+		return nil, ErrMalformedMessageSignature
+	}
+	pub := dsa.NewPublicKey(key.Public()).SerializeCompressed()
+	return append(pub, sig...), nil
+}
+
+// VerifyMessage returns whether the message is signed by the key of the
+// address.  The signature of a secp256k1 address is the compact signature,
+// and that of an ed25519 or schnorr pubkey hash address is the public key
+// followed by the signature, since the public key can't be recovered.
+func VerifyMessage(addr types.Address, sig []byte, msg string) (bool, error) {
+	msgHash := MessageHash(msg)
+	switch a := addr.(type) {
+	case *SecpPubKeyAddress:
+		if len(sig) != compactSignatureLen {
+			return false, ErrMalformedMessageSignature
+		}
+		pubKey, _, err := ecc.Secp256k1.RecoverCompact(sig, msgHash)
+		if err != nil {
+			return false, err
+		}
+		return bytes.Equal(pubKey.SerializeCompressed(), a.PubKey().SerializeCompressed()), nil
+
+	case *PubKeyHashAddress:
+		var dsa ecc.DSA
+		switch a.EcType() {
+		case ecc.ECDSA_Secp256k1:
+			if len(sig) != compactSignatureLen {
+				return false, ErrMalformedMessageSignature
+			}
+			pubKey, compressed, err := ecc.Secp256k1.RecoverCompact(sig, msgHash)
+			if err != nil {
+				return false, err
+			}
+			serialized := pubKey.SerializeUncompressed()
+			if compressed {
+				serialized = pubKey.SerializeCompressed()
+			}
+			return bytes.Equal(hash.Hash160(serialized), a.Hash160()[:]), nil
+		case ecc.EdDSA_Ed25519:
+			dsa = ecc.Ed25519
+		case ecc.ECDSA_SecpSchnorr:
+			dsa = ecc.SecSchnorr
+		default:
+			return false, ErrMessageAddressType
+		}
+		if len(sig) <= messageSignatureLen {
+			return false, ErrMalformedMessageSignature
+		}
+		pkBytes := sig[:len(sig)-messageSignatureLen]
+		if !bytes.Equal(hash.Hash160(pkBytes), a.Hash160()[:]) {
+			return false, nil
+		}
+		pubKey, err := dsa.ParsePubKey(pkBytes)
+		if err != nil {
+			return false, ErrMalformedMessageSignature
+		}
+		signature, err := dsa.ParseSignature(sig[len(pkBytes):])
+		if err != nil {
+			return false, ErrMalformedMessageSignature
+		}
+		return dsa.Verify(pubKey, msgHash, signature.GetR(), signature.GetS()), nil
+
+	default:
+		return false, ErrMessageAddressType
+	}
+}
@@ -0,0 +1,91 @@
+// Copyright (c) 2017-2018 The qitmeer developers
+
+package address
+
+import (
+	"github.com/Qitmeer/qng/common/hash"
+	"github.com/Qitmeer/qng/core/types"
+	"github.com/Qitmeer/qng/crypto/ecc"
+	"github.com/Qitmeer/qng/params"
+	"testing"
+)
+
+func TestVerifyMessage(t *testing.T) {
+	net := &params.TestNetParams
+	msg := "Qitmeer message"
+	seed := hash.HashB([]byte("message"))
+
+	key, pub := ecc.Secp256k1.PrivKeyFromBytes(seed)
+	for _, compressed := range []bool{true, false} {
+		sig, err := SignMessage(key, compressed, msg)
+		if err != nil {
+			t.Fatal(err)
+		}
+		serialized := pub.SerializeUncompressed()
+		if compressed {
+			serialized = pub.SerializeCompressed()
+		}
+		pkh, _ := NewPubKeyHashAddress(hash.Hash160(serialized), net, ecc.ECDSA_Secp256k1)
+		pk, _ := NewSecpPubKeyAddress(serialized, net)
+		for _, addr := range []types.Address{pkh, pk} {
+			ok, err := VerifyMessage(addr, sig, msg)
+			if err != nil || !ok {
+				t.Fatalf("%s: failed to verify the message: %v", addr, err)
+			}
+			if ok, _ := VerifyMessage(addr, sig, msg+"."); ok {
+				t.Fatalf("%s: expected another message to fail", addr)
+			}
+		}
+	}
+	// The uncompressed key has another pubkey hash address.
+	sig, _ := SignMessage(key, false, msg)
+	pkh, _ := NewPubKeyHashAddress(hash.Hash160(pub.SerializeCompressed()), net, ecc.ECDSA_Secp256k1)
+	if ok, _ := VerifyMessage(pkh, sig, msg); ok {
+		t.Fatalf("expected the uncompressed signature to fail")
+	}
+
+	if _, err := VerifyMessage(pkh, sig[1:], msg); err != ErrMalformedMessageSignature {
+		t.Fatalf("got %v, want %v", err, ErrMalformedMessageSignature)
+	}
+
+	// The alternative signatures follow the public key.
+	tests := []struct {
+		dsa    ecc.DSA
+		ecType ecc.EcType
+	}{
+		{ecc.Ed25519, ecc.EdDSA_Ed25519},
+		{ecc.SecSchnorr, ecc.ECDSA_SecpSchnorr},
+	}
+	// The scalar is less than the order of both curves.
+	scalar := append([]byte{}, seed...)
+	scalar[0] &= 0x0f
+	for _, test := range tests {
+		key, pub := test.dsa.PrivKeyFromScalar(scalar)
+		pkBytes := pub.SerializeCompressed()
+		sig, err := SignMessageWithPubKey(test.dsa, key, msg)
+		if err != nil {
+			t.Fatal(err)
+		}
+		addr, _ := NewPubKeyHashAddress(hash.Hash160(pkBytes), net, test.ecType)
+		ok, err := VerifyMessage(addr, sig, msg)
+		if err != nil || !ok {
+			t.Fatalf("%s: failed to verify the message: %v", addr, err)
+		}
+		if ok, _ := VerifyMessage(addr, sig, msg+"."); ok {
+			t.Fatalf("%s: expected another message to fail", addr)
+		}
+		other, _ := NewPubKeyHashAddress(hash.Hash160(pkBytes[1:]), net, test.ecType)
+		if ok, _ := VerifyMessage(other, sig, msg); ok {
+			t.Fatalf("%s: expected another address to fail", other)
+		}
+		if _, err := VerifyMessage(addr, sig[len(pkBytes):], msg); err != ErrMalformedMessageSignature {
+			t.Fatalf("%s: got %v, want %v", addr, err, ErrMalformedMessageSignature)
+		}
+	}
+
+	// The script hash addresses have no keys.
+	sh, _ := NewScriptHashAddressFromHash(hash.Hash160(pub.SerializeCompressed()), net)
+	if _, err := VerifyMessage(sh, sig, msg); err != ErrMessageAddressType {
+		t.Fatalf("got %v, want %v", err, ErrMessageAddressType)
+	}
+}
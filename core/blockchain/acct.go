@@ -0,0 +1,31 @@
+package blockchain
+
+import (
+	"github.com/Qitmeer/qng/core/blockchain/utxo"
+	"github.com/Qitmeer/qng/core/types"
+)
+
+// Acct is the account manager which follows the utxo set and the blocks of
+// the chain.  Its errors don't fail the chain, it is marked for a rebuild
+// with MarkRebuild instead.
+type Acct interface {
+	Apply(add bool, op *types.TxOutPoint, entry *utxo.UtxoEntry) error
+	Commit() error
+	// ConnectBlock is called with the view of the block before it is
+	// committed and with the outputs spent by the block.
+	ConnectBlock(block *types.SerializedBlock, order uint, view *utxo.UtxoViewpoint, stxos []utxo.SpentTxOut) error
+	DisconnectBlock(block *types.SerializedBlock) error
+	// MarkRebuild stops updating the account manager after it failed to
+	// follow the chain, so it is rebuilt the next time it is started.
+	MarkRebuild(err error)
+}
+
+// acctError marks the account manager for a rebuild when it failed to follow
+// the chain.
+func (b *BlockChain) acctError(err error) {
+	if err == nil {
+		return
+	}
+	log.Error(err.Error())
+	b.Acct.MarkRebuild(err)
+}
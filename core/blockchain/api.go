@@ -468,6 +468,92 @@ func (api *PublicBlockAPI) GetTokenInfo() (interface{}, error) {
 	return tbs, nil
 }
 
+// GetBlockStats returns the statistics of a block
+// 1. hashOrOrder    (string, required)                The block hash or order, -1 is the main tip
+// 2. fields         (array of string, optional)       Only return these fields of the result
+//
+// The fees are computed from the spend journal, so they are only known for
+// the blocks in the main order. The fee rate percentiles are the 10th, 25th,
+// 50th, 75th and 90th.
+func (api *PublicBlockAPI) GetBlockStats(hashOrOrder string, fields *[]string) (interface{}, error) {
+	var blockHash *hash.Hash
+	if len(hashOrOrder) == hash.MaxHashStringSize {
+		h, err := hash.NewHashFromStr(hashOrOrder)
+		if err != nil {
+			return nil, err
+		}
+		blockHash = h
+	} else {
+		order, err := strconv.ParseInt(hashOrOrder, 10, 64)
+		if err != nil {
+			return nil, fmt.Errorf("Invalid block hash or order: %s", hashOrOrder)
+		}
+		if order == LatestBlockOrder {
+			order = int64(api.chain.BestSnapshot().GraphState.GetMainOrder())
+		}
+		blockHash, err = api.chain.BlockHashByOrder(uint64(order))
+		if err != nil {
+			return nil, err
+		}
+	}
+	block, err := api.chain.FetchBlockByHash(blockHash)
+	if err != nil {
+		return nil, err
+	}
+	stats, err := api.chain.calcBlockStats(block)
+	if err != nil {
+		return nil, internalError(err.Error(), "Failed to compute the block stats")
+	}
+	if fields == nil || len(*fields) == 0 {
+		return stats, nil
+	}
+	return filterBlockStats(stats, *fields)
+}
+
+// GetTxOutSetInfo returns the statistics of the utxo set at the main tip
+//
+//Result:
+//{
+// "bestblock": "value",        (string)          The hash of the main tip
+// "order": n,                  (numeric)         The order of the main tip
+// "totalsubsidy": n,           (numeric)         The total subsidy of the chain in atoms
+// "txouts": n,                 (numeric)         The number of unspent outputs
+// "bytes_serialized": n,       (numeric)         The serialized size of the utxo set
+// "hash_serialized": "value",  (string)          The hash of the serialized utxo set
+// "coins": [{                  (array of object) The unspent outputs per coin
+//   "coinid": n,               (numeric)         The coin id
+//   "coinname": "value",       (string)          The coin name
+//   "txouts": n,               (numeric)         The number of unspent outputs
+//   "amount": n,               (numeric)         The total amount in atoms
+//   "bytes_serialized": n,     (numeric)         The serialized size of the outputs
+// },...]
+//}
+func (api *PublicBlockAPI) GetTxOutSetInfo() (interface{}, error) {
+	stats, err := api.chain.FetchUtxoSetStats()
+	if err != nil {
+		return nil, internalError(err.Error(), "Failed to fetch the utxo set")
+	}
+	coins := make([]json.TxOutSetCoinInfo, 0, len(stats.Coins))
+	for _, coin := range stats.Coins {
+		coins = append(coins, json.TxOutSetCoinInfo{
+			CoinId:          uint16(coin.CoinId),
+			CoinName:        coin.CoinId.Name(),
+			Txouts:          coin.Txouts,
+			Amount:          coin.Amount,
+			BytesSerialized: coin.BytesSerialized,
+		})
+	}
+	return &json.TxOutSetInfoResult{
+		BestBlock:       stats.BestHash.String(),
+		Order:           stats.Order,
+		TotalSubsidy:    stats.TotalSubsidy,
+		Txouts:          stats.Txouts,
+		BytesSerialized: stats.BytesSerialized,
+		HashSerialized:  stats.Hash.String(),
+		Coins:           coins,
+	}, nil
+}
+
 func internalError(err, context string) error {
 	return fmt.Errorf("%s : %s", context, err)
 }
\ No newline at end of file
@@ -130,7 +130,7 @@ type BlockChain struct {
 	unknownRulesWarned bool
 	deploymentMux      sync.RWMutex
 
-	Acct model.Acct
+	Acct Acct
 
 	shutdownTracker *shutdown.Tracker
 
@@ -631,18 +631,28 @@ func (b *BlockChain) FetchSubsidyCache() *SubsidyCache {
 // This function MUST be called with the chain state lock held (for writes).
 
 func (b *BlockChain) reorganizeChain(ib meerdag.IBlock, detachNodes *list.List, attachNodes *list.List, newBlock *types.SerializedBlock, connectedBlocks *list.List) error {
-	oldBlocks := []*hash.Hash{}
+	rnd := &ReorganizationNotifyData{
+		NewBlock: newBlock.Hash(),
+		NewOrder: uint64(ib.GetOrder()),
+	}
+	// The blue/red and validity status of the old blocks before the
+	// reorganization, used to notify what the reorganization changed.
+	type oldState struct {
+		blue    bool
+		invalid bool
+		block   *types.SerializedBlock
+	}
+	oldStates := map[uint]*oldState{}
 	for e := detachNodes.Front(); e != nil; e = e.Next() {
 		ob := e.Value.(*meerdag.BlockOrderHelp)
-		oldBlocks = append(oldBlocks, ob.Block.GetHash())
+		rnd.OldBlocks = append(rnd.OldBlocks, ob.Block.GetHash())
+		rnd.OldOrders = append(rnd.OldOrders, uint64(ob.OldOrder))
+		oldStates[ob.Block.GetID()] = &oldState{
+			blue:    ob.OldBlue,
+			invalid: ob.Block.GetStatus().KnownInvalid(),
+		}
 	}
 
-	b.sendNotification(Reorganization, &ReorganizationNotifyData{
-		OldBlocks: oldBlocks,
-		NewBlock:  newBlock.Hash(),
-		NewOrder:  uint64(ib.GetOrder()),
-	})
-
 	// Why the old order is the order that was removed by the new block, because the new block
 	// must be one of the tip of the dag.This is very important for the following understanding.
 	// In the two case, the perspective is the same.In the other words, the future can not
@@ -663,6 +673,7 @@ func (b *BlockChain) reorganizeChain(ib meerdag.IBlock, detachNodes *list.List,
 		}
 
 		block.SetOrder(uint64(n.OldOrder))
+		oldStates[n.Block.GetID()].block = block
 		// Load all of the utxos referenced by the block that aren't
 		// already in the view.
 		var stxos []utxo.SpentTxOut
@@ -745,6 +756,38 @@ func (b *BlockChain) reorganizeChain(ib meerdag.IBlock, detachNodes *list.List,
 	// heads.
 	log.Debug(fmt.Sprintf("End DAG REORGANIZE: Old Len= %d;New Len= %d", attachNodes.Len(), detachNodes.Len()))
 
+	// The notification is sent once the blocks were reordered, so that it
+	// carries the blue/red and validity changes of the reorganization.
+	for e := attachNodes.Front(); e != nil; e = e.Next() {
+		nb := e.Value.(meerdag.IBlock)
+		if !nb.IsOrdered() {
+			continue
+		}
+		rnd.NewBlocks = append(rnd.NewBlocks, nb.GetHash())
+		rnd.NewOrders = append(rnd.NewOrders, uint64(nb.GetOrder()))
+
+		old, ok := oldStates[nb.GetID()]
+		if !ok {
+			continue
+		}
+		blue := b.bd.IsBlue(nb.GetID())
+		if blue != old.blue {
+			rnd.BlueFlips = append(rnd.BlueFlips, &BlueFlip{Hash: nb.GetHash(), Blue: blue})
+		}
+		invalid := nb.GetStatus().KnownInvalid()
+		if invalid == old.invalid || old.block == nil {
+			continue
+		}
+		for _, tx := range old.block.Transactions() {
+			if invalid {
+				rnd.InvalidatedTxs = append(rnd.InvalidatedTxs, tx.Hash())
+			} else {
+				rnd.ValidatedTxs = append(rnd.ValidatedTxs, tx.Hash())
+			}
+		}
+	}
+	b.sendNotification(Reorganization, rnd)
+
 	return nil
 }
 
@@ -0,0 +1,193 @@
+// Copyright (c) 2017-2018 The qitmeer developers
+package blockchain
+
+import (
+	ejson "encoding/json"
+	"fmt"
+	"github.com/Qitmeer/qng/core/blockchain/utxo"
+	"github.com/Qitmeer/qng/core/json"
+	"github.com/Qitmeer/qng/core/types"
+	"sort"
+)
+
+// blockStatsPercentiles are the percentiles of the fee rates reported by
+// the getBlockStats command.
+var blockStatsPercentiles = []int{10, 25, 50, 75, 90}
+
+// stxoKey identifies the input of a transaction in the spend journal.
+type stxoKey struct {
+	txIndex   uint32
+	txInIndex uint32
+}
+
+// stxoValue returns the value spent by the stxo, the coinbase outputs also
+// spend the fees of their block the same as CheckTransactionInputs.
+func stxoValue(stxo *utxo.SpentTxOut) types.Amount {
+	amount := stxo.Amount
+	if stxo.IsCoinBase && amount.Id != types.MEERA && stxo.Fees.Value > 0 {
+		amount.Value = stxo.Fees.Value
+		return amount
+	}
+	if stxo.Fees.Id == amount.Id {
+		amount.Value += stxo.Fees.Value
+	}
+	return amount
+}
+
+// calcBlockStats computes the statistics of the block. The values of the
+// inputs are read from the spend journal, so the fees are only known for the
+// blocks in the main order.  The fees and fee rates are in atoms of MEER, the
+// fee rates in atoms per byte.
+func (b *BlockChain) calcBlockStats(block *types.SerializedBlock) (*json.BlockStatsResult, error) {
+	ib := b.BlockDAG().GetBlock(block.Hash())
+	if ib == nil {
+		return nil, fmt.Errorf("Block not found: %s", block.Hash())
+	}
+	stxos, err := b.FetchSpendJournal(block)
+	if err != nil {
+		return nil, err
+	}
+	spent := make(map[stxoKey]*utxo.SpentTxOut, len(stxos))
+	for i := range stxos {
+		spent[stxoKey{stxos[i].TxIndex, stxos[i].TxInIndex}] = &stxos[i]
+	}
+
+	stats := &json.BlockStatsResult{
+		BlockHash:          block.Hash().String(),
+		Order:              uint64(ib.GetOrder()),
+		Height:             uint64(ib.GetHeight()),
+		Time:               block.Block().Header.Timestamp.Unix(),
+		Blue:               b.BlockDAG().IsBlue(ib.GetID()),
+		Valid:              !ib.GetStatus().KnownInvalid(),
+		Txs:                len(block.Transactions()),
+		TotalSize:          block.Block().SerializeSize(),
+		TotalOut:           map[string]int64{},
+		TotalFees:          map[string]int64{},
+		FeeRatePercentiles: make([]int64, len(blockStatsPercentiles)),
+	}
+	for coinId, fee := range b.GetFees(block.Hash()) {
+		if fee > 0 {
+			stats.TotalFees[coinId.Name()] = fee
+		}
+	}
+
+	var fees, feeRates []int64
+	for txIdx, tx := range block.Transactions() {
+		msgTx := tx.Tx
+		size := msgTx.SerializeSize()
+		if txIdx != 0 {
+			if size < stats.MinTxSize || stats.MinTxSize == 0 {
+				stats.MinTxSize = size
+			}
+			if size > stats.MaxTxSize {
+				stats.MaxTxSize = size
+			}
+			stats.TotalTxSize += size
+		}
+		for _, txOut := range msgTx.TxOut {
+			stats.TotalOut[txOut.Amount.Id.Name()] += txOut.Amount.Value
+		}
+		stats.Ins += len(msgTx.TxIn)
+		stats.Outs += len(msgTx.TxOut)
+
+		switch {
+		case msgTx.IsCoinBase():
+			stats.Ins--
+			continue
+		case types.IsTokenTx(msgTx):
+			stats.TokenTxs++
+			continue
+		case types.IsCrossChainImportTx(msgTx):
+			stats.ImportTxs++
+			continue
+		case types.IsCrossChainVMTx(msgTx):
+			stats.VMTxs++
+			continue
+		case types.IsCrossChainExportTx(msgTx):
+			stats.ExportTxs++
+		}
+		if tx.IsDuplicate {
+			continue
+		}
+
+		// The fee of the transaction in MEER.
+		var in, out int64
+		known := true
+		for txInIndex := range msgTx.TxIn {
+			stxo, ok := spent[stxoKey{uint32(txIdx), uint32(txInIndex)}]
+			if !ok {
+				known = false
+				break
+			}
+			amount := stxoValue(stxo)
+			if amount.Id == types.MEERA {
+				in += amount.Value
+			}
+		}
+		if !known {
+			continue
+		}
+		for idx, txOut := range msgTx.TxOut {
+			if txOut.Amount.Id == types.MEERA ||
+				(idx == 0 && types.IsCrossChainExportTx(msgTx)) {
+				out += txOut.Amount.Value
+			}
+		}
+		fee := in - out
+		fees = append(fees, fee)
+		if size > 0 {
+			feeRates = append(feeRates, fee/int64(size))
+		}
+	}
+
+	if len(fees) > 0 {
+		stats.TotalFee = sumInt64(fees)
+		stats.AvgFee = stats.TotalFee / int64(len(fees))
+		sort.Slice(fees, func(i, j int) bool { return fees[i] < fees[j] })
+		stats.MinFee = fees[0]
+		stats.MaxFee = fees[len(fees)-1]
+		stats.MedianFee = fees[len(fees)/2]
+	}
+	if len(feeRates) > 0 {
+		stats.AvgFeeRate = sumInt64(feeRates) / int64(len(feeRates))
+		sort.Slice(feeRates, func(i, j int) bool { return feeRates[i] < feeRates[j] })
+		stats.MinFeeRate = feeRates[0]
+		stats.MaxFeeRate = feeRates[len(feeRates)-1]
+		for i, p := range blockStatsPercentiles {
+			stats.FeeRatePercentiles[i] = feeRates[(len(feeRates)-1)*p/100]
+		}
+	}
+	if stats.Txs > 1 {
+		stats.AvgTxSize = stats.TotalTxSize / (stats.Txs - 1)
+	}
+	return stats, nil
+}
+
+func sumInt64(values []int64) int64 {
+	var sum int64
+	for _, v := range values {
+		sum += v
+	}
+	return sum
+}
+
+// filterBlockStats returns only the requested fields of the statistics.
+func filterBlockStats(stats *json.BlockStatsResult, fields []string) (map[string]interface{}, error) {
+	serialized, err := ejson.Marshal(stats)
+	if err != nil {
+		return nil, err
+	}
+	all := map[string]interface{}{}
+	if err := ejson.Unmarshal(serialized, &all); err != nil {
+		return nil, err
+	}
+	result := make(map[string]interface{}, len(fields))
+	for _, field := range fields {
+		value, ok := all[field]
+		if !ok {
+			return nil, fmt.Errorf("Invalid block stats field: %s", field)
+		}
+		result[field] = value
+	}
+	return result, nil
+}
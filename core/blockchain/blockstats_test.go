@@ -0,0 +1,77 @@
+// Copyright (c) 2017-2020 The qitmeer developers
+
+package blockchain
+
+import (
+	"github.com/Qitmeer/qng/core/blockchain/utxo"
+	"github.com/Qitmeer/qng/core/json"
+	"github.com/Qitmeer/qng/core/types"
+	"testing"
+)
+
+// TestStxoValue tests the values spent by the stxos of the spend journal.
+func TestStxoValue(t *testing.T) {
+	tests := []struct {
+		name string
+		stxo utxo.SpentTxOut
+		want types.Amount
+	}{
+		{
+			name: "regular output",
+			stxo: utxo.SpentTxOut{
+				Amount: types.Amount{Value: 100, Id: types.MEERA},
+				Fees:   types.Amount{Value: 0, Id: types.MEERA},
+			},
+			want: types.Amount{Value: 100, Id: types.MEERA},
+		},
+		{
+			name: "coinbase subsidy output",
+			stxo: utxo.SpentTxOut{
+				Amount:     types.Amount{Value: 100, Id: types.MEERA},
+				Fees:       types.Amount{Value: 7, Id: types.MEERA},
+				IsCoinBase: true,
+			},
+			want: types.Amount{Value: 107, Id: types.MEERA},
+		},
+		{
+			name: "coinbase token fees output",
+			stxo: utxo.SpentTxOut{
+				Amount:     types.Amount{Value: 0, Id: types.MEERB},
+				Fees:       types.Amount{Value: 5, Id: types.MEERB},
+				IsCoinBase: true,
+			},
+			want: types.Amount{Value: 5, Id: types.MEERB},
+		},
+	}
+	for _, test := range tests {
+		got := stxoValue(&test.stxo)
+		if got != test.want {
+			t.Errorf("%s: got %v, want %v", test.name, got, test.want)
+		}
+	}
+}
+
+// TestFilterBlockStats tests the selection of the fields of the block stats.
+func TestFilterBlockStats(t *testing.T) {
+	stats := &json.BlockStatsResult{
+		BlockHash: "00",
+		Txs:       3,
+		TotalOut:  map[string]int64{"MEER": 10},
+	}
+	result, err := filterBlockStats(stats, []string{"txs", "total_out"})
+	if err != nil {
+		t.Fatalf("filterBlockStats: %v", err)
+	}
+	if len(result) != 2 {
+		t.Fatalf("got %d fields, want 2", len(result))
+	}
+	if result["txs"] != float64(3) {
+		t.Errorf("txs: got %v, want 3", result["txs"])
+	}
+	if _, ok := result["blockhash"]; ok {
+		t.Errorf("blockhash should not be returned")
+	}
+	if _, err := filterBlockStats(stats, []string{"unknown"}); err == nil {
+		t.Errorf("expected an error for an unknown field")
+	}
+}
@@ -10,6 +10,7 @@ import (
 	"fmt"
 	"github.com/Qitmeer/qng/common/hash"
 	"github.com/Qitmeer/qng/core/event"
+	"github.com/Qitmeer/qng/core/json"
 	"github.com/Qitmeer/qng/core/types"
 )
 
@@ -76,6 +77,79 @@ type ReorganizationNotifyData struct {
 	OldBlocks []*hash.Hash
 	NewBlock  *hash.Hash
 	NewOrder  uint64
+
+	// OldOrders are the orders the old blocks had before the
+	// reorganization, in the same sequence as OldBlocks.
+	OldOrders []uint64
+
+	// NewBlocks and NewOrders are the blocks that are ordered by the
+	// reorganization and their new orders.
+	NewBlocks []*hash.Hash
+	NewOrders []uint64
+
+	// BlueFlips are the reordered blocks whose blue/red status changed.
+	BlueFlips []*BlueFlip
+
+	// ValidatedTxs and InvalidatedTxs are the transactions of the
+	// reordered blocks which became valid or invalid.
+	ValidatedTxs   []*hash.Hash
+	InvalidatedTxs []*hash.Hash
+}
+
+// BlueFlip is a block whose blue/red status was changed by a
+// reorganization, Blue is the new status.
+type BlueFlip struct {
+	Hash *hash.Hash
+	Blue bool
+}
+
+// Diff returns the reorganization as the result of the reorganization
+// notifications.
+func (r *ReorganizationNotifyData) Diff() *json.ReorganizationDiff {
+	diff := &json.ReorganizationDiff{
+		Hash:           r.NewBlock.String(),
+		Order:          r.NewOrder,
+		OldBlocks:      hashStrings(r.OldBlocks),
+		OldOrders:      r.OldOrders,
+		OldRange:       orderRange(r.OldOrders),
+		NewBlocks:      hashStrings(r.NewBlocks),
+		NewOrders:      r.NewOrders,
+		NewRange:       orderRange(r.NewOrders),
+		BlueFlips:      make([]json.BlueFlip, 0, len(r.BlueFlips)),
+		ValidatedTxs:   hashStrings(r.ValidatedTxs),
+		InvalidatedTxs: hashStrings(r.InvalidatedTxs),
+	}
+	for _, flip := range r.BlueFlips {
+		diff.BlueFlips = append(diff.BlueFlips, json.BlueFlip{
+			Hash: flip.Hash.String(),
+			Blue: flip.Blue,
+		})
+	}
+	return diff
+}
+
+func hashStrings(hs []*hash.Hash) []string {
+	result := make([]string, 0, len(hs))
+	for _, h := range hs {
+		result = append(result, h.String())
+	}
+	return result
+}
+
+func orderRange(orders []uint64) *json.OrderRange {
+	if len(orders) == 0 {
+		return nil
+	}
+	r := &json.OrderRange{Start: orders[0], End: orders[0]}
+	for _, order := range orders[1:] {
+		if order < r.Start {
+			r.Start = order
+		}
+		if order > r.End {
+			r.End = order
+		}
+	}
+	return r
 }
 
 // Notification defines notification that is sent to the caller via the callback
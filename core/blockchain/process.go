@@ -276,10 +276,7 @@ func (b *BlockChain) maybeAcceptBlock(block *types.SerializedBlock, flags Behavi
 		Flags:                flags,
 	})
 	if b.Acct != nil {
-		err = b.Acct.Commit()
-		if err != nil {
-			log.Error(err.Error())
-		}
+		b.acctError(b.Acct.Commit())
 	}
 	return nil
 }
@@ -427,6 +424,10 @@ func (b *BlockChain) connectBlock(node meerdag.IBlock, block *types.SerializedBl
 			}
 		}
 
+		if b.Acct != nil {
+			b.acctError(b.Acct.ConnectBlock(block, node.GetOrder(), view, stxos))
+		}
+
 		// Prune fully spent entries and mark all entries in the view unmodified
 		// now that the modifications have been committed to the database.
 		view.Commit()
@@ -490,6 +491,9 @@ func (b *BlockChain) disconnectBlock(ib meerdag.IBlock, block *types.SerializedB
 			return fmt.Errorf("%v. (Attempt to execute --droptxindex)", err)
 		}
 	}
+	if b.Acct != nil {
+		b.acctError(b.Acct.DisconnectBlock(block))
+	}
 	// Prune fully spent entries and mark all entries in the view unmodified
 	// now that the modifications have been committed to the database.
 	view.Commit()
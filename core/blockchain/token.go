@@ -195,6 +195,31 @@ func (b *BlockChain) GetTokenState(bid uint32) *token.TokenState {
 	return state
 }
 
+// tokenStateBefore returns the token state which the block is connected on,
+// which is the latest token state of the blocks ordered before it.  The blocks
+// are connected in order, so it is the token tip unless the block is validated
+// again after the blocks ordered behind it.
+func (b *BlockChain) tokenStateBefore(ib meerdag.IBlock) (*token.TokenState, error) {
+	id := b.TokenTipID
+	for {
+		state := b.GetTokenState(id)
+		if state == nil {
+			return nil, fmt.Errorf("No token sate:%d\n", id)
+		}
+		if uint(state.PrevStateID) == meerdag.MaxId {
+			return state, nil
+		}
+		sib := b.bd.GetBlockById(uint(id))
+		if sib == nil {
+			return nil, fmt.Errorf("No token state block:%d\n", id)
+		}
+		if sib.GetID() != ib.GetID() && sib.GetOrder() < ib.GetOrder() {
+			return state, nil
+		}
+		id = state.PrevStateID
+	}
+}
+
 func (b *BlockChain) GetCurTokenState() *token.TokenState {
 	b.ChainRLock()
 	defer b.ChainRUnlock()
@@ -10,7 +10,9 @@ import (
 	"fmt"
 	"github.com/Qitmeer/qng/consensus/forks"
 	"github.com/Qitmeer/qng/consensus/vm"
+	"github.com/Qitmeer/qng/core/blockchain/token"
 	"github.com/Qitmeer/qng/core/blockchain/utxo"
+	"github.com/Qitmeer/qng/meerdag"
 	"math"
 	"runtime"
 
@@ -20,9 +22,10 @@ import (
 
 // txValidateItem holds a transaction along with which input to validate.
 type txValidateItem struct {
-	txInIndex int
-	txIn      *types.TxInput
-	tx        *types.Tx
+	txInIndex  int
+	txIn       *types.TxInput
+	tx         *types.Tx
+	tokenState *token.TokenState
 }
 
 // txValidator provides a type which asynchronously validates transaction
@@ -33,6 +36,7 @@ type txValidator struct {
 	quitChan     chan struct{}
 	resultChan   chan error
 	utxoView     *utxo.UtxoViewpoint
+	tokenState   *token.TokenState
 	flags        txscript.ScriptFlags
 	sigCache     *txscript.SigCache
 }
@@ -88,6 +92,13 @@ out:
 				v.sendResult(err)
 				break out
 			}
+			tokenState := v.tokenState
+			if txVI.tokenState != nil {
+				tokenState = txVI.tokenState
+			}
+			if tokenState != nil {
+				vm.SetTokenView(&tokenScriptView{state: tokenState, utxoView: v.utxoView})
+			}
 
 			// Execute the script pair.
 			if err := vm.Execute(); err != nil {
@@ -172,20 +183,22 @@ func (v *txValidator) Validate(items []*txValidateItem) error {
 
 // newTxValidator returns a new instance of txValidator to be used for
 // validating transaction scripts asynchronously.
-func newTxValidator(utxoView *utxo.UtxoViewpoint, flags txscript.ScriptFlags, sigCache *txscript.SigCache) *txValidator {
+func newTxValidator(utxoView *utxo.UtxoViewpoint, tokenState *token.TokenState, flags txscript.ScriptFlags, sigCache *txscript.SigCache) *txValidator {
 	return &txValidator{
 		validateChan: make(chan *txValidateItem),
 		quitChan:     make(chan struct{}),
 		resultChan:   make(chan error),
 		utxoView:     utxoView,
+		tokenState:   tokenState,
 		sigCache:     sigCache,
 		flags:        flags,
 	}
 }
 
 // ValidateTransactionScripts validates the scripts for the passed transaction
-// using multiple goroutines.
-func ValidateTransactionScripts(tx *types.Tx, utxoView *utxo.UtxoViewpoint, flags txscript.ScriptFlags, sigCache *txscript.SigCache, height int64) error {
+// using multiple goroutines.  The token state is required by the token opcodes
+// when the flags enforce them.
+func ValidateTransactionScripts(tx *types.Tx, utxoView *utxo.UtxoViewpoint, tokenState *token.TokenState, flags txscript.ScriptFlags, sigCache *txscript.SigCache, height int64) error {
 	// Collect all of the transaction inputs and required information for
 	// validation.
 	txIns := tx.Transaction().TxIn
@@ -207,15 +220,85 @@ func ValidateTransactionScripts(tx *types.Tx, utxoView *utxo.UtxoViewpoint, flag
 	}
 
 	// Validate all of the inputs.
-	return newTxValidator(utxoView, flags, sigCache).Validate(txValItems)
+	return newTxValidator(utxoView, tokenState, flags, sigCache).Validate(txValItems)
+
+}
+
+// tokenScriptView provides the token state and the previous outputs to the
+// token opcodes of the script engine.
+type tokenScriptView struct {
+	state    *token.TokenState
+	utxoView *utxo.UtxoViewpoint
+}
+
+// NewTokenScriptView returns the token view of the script engine from the
+// token state and the utxo view of the previous outputs.
+func NewTokenScriptView(state *token.TokenState, utxoView *utxo.UtxoViewpoint) txscript.TokenView {
+	return &tokenScriptView{state: state, utxoView: utxoView}
+}
+
+// LookupToken returns the state of the token, or nil when the token is not
+// registered.
+func (tv *tokenScriptView) LookupToken(id types.CoinID) *txscript.TokenInfo {
+	tt, ok := tv.state.Types[id]
+	if !ok {
+		return nil
+	}
+	return &txscript.TokenInfo{
+		UpLimit: tt.UpLimit,
+		Enable:  tt.Enable,
+		Balance: tv.state.Balances[id].Balance,
+	}
+}
+
+// LookupOutput returns the amount and the public key script of the previous
+// output in the utxo view.
+func (tv *tokenScriptView) LookupOutput(outpoint types.TxOutPoint) (types.Amount, []byte, bool) {
+	entry := tv.utxoView.LookupEntry(outpoint)
+	if entry == nil {
+		return types.Amount{}, nil, false
+	}
+	return entry.Amount(), entry.PkScript(), true
+}
 
+// nextTokenState returns a copy of the token state with the update of the
+// token transaction applied.
+func nextTokenState(state *token.TokenState, tx *types.Transaction) (*token.TokenState, error) {
+	update, err := token.NewUpdateFromTx(tx)
+	if err != nil {
+		return nil, err
+	}
+	next := &token.TokenState{
+		PrevStateID: state.PrevStateID,
+		Types:       token.TokenTypesMap{},
+		Balances:    token.TokenBalancesMap{},
+		Updates:     []token.ITokenUpdate{update},
+	}
+	for id, tt := range state.Types {
+		next.Types[id] = tt
+	}
+	for id, tb := range state.Balances {
+		next.Balances[id] = tb
+	}
+	err = next.Update()
+	if err != nil {
+		return nil, err
+	}
+	return next, nil
 }
 
 // checkBlockScripts executes and validates the scripts for all transactions in
-// the passed block using multiple goroutines.
+// the passed block using multiple goroutines.  The token opcodes of each
+// transaction are checked against the token state which the block is
+// connected on, with the token transactions before it in the block applied.
 // txTree = true is TxTreeRegular, txTree = false is TxTreeStake.
-func (b *BlockChain) checkBlockScripts(block *types.SerializedBlock, utxoView *utxo.UtxoViewpoint,
+func (b *BlockChain) checkBlockScripts(ib meerdag.IBlock, block *types.SerializedBlock, utxoView *utxo.UtxoViewpoint,
 	scriptFlags txscript.ScriptFlags, sigCache *txscript.SigCache) error {
+	tokenState, err := b.tokenStateBefore(ib)
+	if err != nil {
+		return err
+	}
+	checkTokenOpcodes := scriptFlags&txscript.ScriptVerifyTokenOpcodes == txscript.ScriptVerifyTokenOpcodes
 
 	// Collect all of the transaction inputs and required information for
 	// validation for all transactions in the block into a single slice.
@@ -251,6 +334,9 @@ func (b *BlockChain) checkBlockScripts(block *types.SerializedBlock, utxoView *u
 				txIn:      vtsTx.TxIn[0],
 				tx:        types.NewTx(vtsTx),
 			}
+			if checkTokenOpcodes {
+				txVI.tokenState = tokenState
+			}
 			txValItems = append(txValItems, txVI)
 			continue
 		}
@@ -267,16 +353,15 @@ func (b *BlockChain) checkBlockScripts(block *types.SerializedBlock, utxoView *u
 				txIn:      txIn,
 				tx:        tx,
 			}
+			if checkTokenOpcodes {
+				txVI.tokenState = tokenState
+			}
 			txValItems = append(txValItems, txVI)
 		}
 
 		if types.IsTokenTx(tx.Tx) {
 			if types.IsTokenMintTx(tx.Tx) {
-				state := b.GetTokenState(b.TokenTipID)
-				if state == nil {
-					return fmt.Errorf("Token state error\n")
-				}
-				tt, ok := state.Types[tx.Tx.TxOut[0].Amount.Id]
+				tt, ok := tokenState.Types[tx.Tx.TxOut[0].Amount.Id]
 				if !ok {
 					return fmt.Errorf("It doesn't exist: Coin id (%d)\n", tx.Tx.TxOut[0].Amount.Id)
 				}
@@ -284,9 +369,13 @@ func (b *BlockChain) checkBlockScripts(block *types.SerializedBlock, utxoView *u
 			} else {
 				utxoView.AddTokenTxOut(tx.Tx.TxIn[0].PreviousOut, nil)
 			}
+			tokenState, err = nextTokenState(tokenState, tx.Tx)
+			if err != nil {
+				return err
+			}
 		}
 	}
 
 	// Validate all of the inputs.
-	return newTxValidator(utxoView, scriptFlags, sigCache).Validate(txValItems)
+	return newTxValidator(utxoView, nil, scriptFlags, sigCache).Validate(txValItems)
 }
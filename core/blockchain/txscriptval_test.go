@@ -0,0 +1,60 @@
+package blockchain
+
+import (
+	"testing"
+
+	"github.com/Qitmeer/qng/common/hash"
+	"github.com/Qitmeer/qng/core/blockchain/token"
+	"github.com/Qitmeer/qng/core/types"
+	"github.com/Qitmeer/qng/engine/txscript"
+)
+
+const testTokenID types.CoinID = 100
+
+func tokenTypeTx(t *testing.T, txType types.TxType, upLimit uint64, name string) *types.Transaction {
+	pkScript, err := txscript.PayToTokenPubKeyHashScript(make([]byte, 20), testTokenID, upLimit, name, 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+	tx := types.NewTransaction()
+	tx.AddTxIn(&types.TxInput{
+		PreviousOut: *types.NewOutPoint(&hash.ZeroHash, types.SupperPrevOutIndex),
+		Sequence:    uint32(txType),
+	})
+	tx.AddTxOut(&types.TxOutput{PkScript: pkScript})
+	return tx
+}
+
+func TestNextTokenState(t *testing.T) {
+	state := token.BuildGenesisTokenState()
+
+	// The token issued by a transaction is seen by the later ones.
+	issued, err := nextTokenState(state, tokenTypeTx(t, types.TxTypeTokenNew, 1000, "QIT"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	tt, ok := issued.Types[testTokenID]
+	if !ok || tt.Enable || tt.UpLimit != 1000 {
+		t.Fatalf("got %v %v, want a disabled token of up limit 1000", tt, ok)
+	}
+	enabled, err := nextTokenState(issued, tokenTypeTx(t, types.TxTypeTokenValidate, 0, ""))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !enabled.Types[testTokenID].Enable {
+		t.Fatalf("the token isn't enabled")
+	}
+
+	// The states before are unchanged.
+	if _, ok := state.Types[testTokenID]; ok {
+		t.Fatalf("the token is issued in the state before")
+	}
+	if issued.Types[testTokenID].Enable {
+		t.Fatalf("the token is enabled in the state before")
+	}
+
+	// An update which the state doesn't allow fails.
+	if _, err := nextTokenState(issued, tokenTypeTx(t, types.TxTypeTokenNew, 1000, "QIT")); err == nil {
+		t.Fatalf("the token is issued twice")
+	}
+}
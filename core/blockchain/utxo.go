@@ -204,10 +204,7 @@ func (b *BlockChain) dbPutUtxoView(dbTx database.Tx, view *utxo.UtxoViewpoint) e
 				return err
 			}
 			if b.Acct != nil {
-				err = b.Acct.Apply(false, &outpoint, entry)
-				if err != nil {
-					log.Error(err.Error())
-				}
+				b.acctError(b.Acct.Apply(false, &outpoint, entry))
 			}
 			continue
 		}
@@ -228,10 +225,7 @@ func (b *BlockChain) dbPutUtxoView(dbTx database.Tx, view *utxo.UtxoViewpoint) e
 		}
 
 		if b.Acct != nil {
-			err = b.Acct.Apply(true, &outpoint, entry)
-			if err != nil {
-				log.Error(err.Error())
-			}
+			b.acctError(b.Acct.Apply(true, &outpoint, entry))
 		}
 	}
 
@@ -0,0 +1,131 @@
+// Copyright (c) 2017-2018 The qitmeer developers
+package blockchain
+
+import (
+	"github.com/Qitmeer/qng/common/hash"
+	"github.com/Qitmeer/qng/core/blockchain/utxo"
+	"github.com/Qitmeer/qng/core/dbnamespace"
+	"github.com/Qitmeer/qng/core/types"
+	"github.com/Qitmeer/qng/database"
+	"golang.org/x/crypto/blake2b"
+	"sort"
+)
+
+// CoinUtxoStats describes the unspent outputs of one coin.
+type CoinUtxoStats struct {
+	CoinId          types.CoinID
+	Txouts          uint64
+	Amount          uint64
+	BytesSerialized uint64
+}
+
+// UtxoSetStats describes the utxo set at the main tip.
+type UtxoSetStats struct {
+	BestHash        hash.Hash
+	Order           uint64
+	TotalSubsidy    uint64
+	Txouts          uint64
+	BytesSerialized uint64
+	// Hash commits to every unspent output of the set, it is computed over
+	// the serialized outputs in the key order of the utxo bucket, so it is
+	// the same for all the nodes with the same utxo set.
+	Hash hash.Hash
+	// Coins are sorted by coin id.
+	Coins []*CoinUtxoStats
+}
+
+// FetchUtxoSetStats walks the whole utxo set at a consistent snapshot and
+// returns its statistics.  The outputs of the blocks known to be invalid are
+// not counted, the same as FetchUtxoEntry.
+//
+// The chain lock is only held while the database snapshot of the main tip is
+// taken, the walk reads the snapshot while the chain goes on.  The validity of
+// the blocks of the outputs is read from the DAG as the walk goes, so the rare
+// reorganization during the walk which changes it may be seen.
+//
+// The statistics are always computed by a full walk, there is no incremental
+// mode: the hash is computed over the outputs in the key order, which a
+// utxo view commit can't update without walking the set again.
+//
+// NOTE: The amounts of the coinbase outputs don't include the fees of their
+// blocks, since those are only added when the outputs are spent.
+func (b *BlockChain) FetchUtxoSetStats() (*UtxoSetStats, error) {
+	b.ChainRLock()
+	locked := true
+	defer func() {
+		if locked {
+			b.ChainRUnlock()
+		}
+	}()
+
+	var stats *UtxoSetStats
+	err := b.db.View(func(dbTx database.Tx) error {
+		// The snapshot of the transaction is taken with the chain lock
+		// held, so it has the utxo set of the best state.
+		best := b.BestSnapshot()
+		b.ChainRUnlock()
+		locked = false
+
+		var err error
+		stats, err = utxoSetStats(dbTx, b.IsInvalidOut)
+		if err != nil {
+			return err
+		}
+		stats.BestHash = best.Hash
+		stats.Order = uint64(best.GraphState.GetMainOrder())
+		stats.TotalSubsidy = best.TotalSubsidy
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return stats, nil
+}
+
+// utxoSetStats walks the utxo set of the database transaction and returns
+// its statistics, without the outputs which are invalid.
+func utxoSetStats(dbTx database.Tx, isInvalid func(entry *utxo.UtxoEntry) bool) (*UtxoSetStats, error) {
+	stats := &UtxoSetStats{}
+	hasher, err := blake2b.New256(nil)
+	if err != nil {
+		return nil, err
+	}
+	coins := map[types.CoinID]*CoinUtxoStats{}
+	utxoBucket := dbTx.Metadata().Bucket(dbnamespace.UtxoSetBucketName)
+	cursor := utxoBucket.Cursor()
+	for ok := cursor.First(); ok; ok = cursor.Next() {
+		serializedUtxo := cursor.Value()
+		entry, err := utxo.DeserializeUtxoEntry(serializedUtxo)
+		if err != nil {
+			return nil, err
+		}
+		if entry.IsSpent() || isInvalid(entry) {
+			continue
+		}
+		key := cursor.Key()
+		hasher.Write(key)
+		hasher.Write(serializedUtxo)
+
+		size := uint64(len(key) + len(serializedUtxo))
+		amount := entry.Amount()
+		coin, ok := coins[amount.Id]
+		if !ok {
+			coin = &CoinUtxoStats{CoinId: amount.Id}
+			coins[amount.Id] = coin
+		}
+		coin.Txouts++
+		coin.Amount += uint64(amount.Value)
+		coin.BytesSerialized += size
+		stats.Txouts++
+		stats.BytesSerialized += size
+	}
+	copy(stats.Hash[:], hasher.Sum(nil))
+
+	for _, coin := range coins {
+		stats.Coins = append(stats.Coins, coin)
+	}
+	sort.Slice(stats.Coins, func(i, j int) bool {
+		return stats.Coins[i].CoinId < stats.Coins[j].CoinId
+	})
+	return stats, nil
+}
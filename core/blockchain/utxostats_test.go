@@ -0,0 +1,98 @@
+package blockchain
+
+import (
+	"testing"
+
+	"github.com/Qitmeer/qng/common/hash"
+	"github.com/Qitmeer/qng/core/blockchain/utxo"
+	"github.com/Qitmeer/qng/core/dbnamespace"
+	"github.com/Qitmeer/qng/core/types"
+	"github.com/Qitmeer/qng/database"
+	_ "github.com/Qitmeer/qng/database/ffldb"
+	"github.com/Qitmeer/qng/params"
+)
+
+// putTestUtxos puts the entries into the utxo set of a new database.
+func putTestUtxos(t *testing.T, entries map[types.TxOutPoint]*utxo.UtxoEntry) database.DB {
+	db, err := database.Create("ffldb", t.TempDir(), params.PrivNetParam.Net)
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { db.Close() })
+	err = db.Update(func(dbTx database.Tx) error {
+		bucket, err := dbTx.Metadata().CreateBucketIfNotExists(dbnamespace.UtxoSetBucketName)
+		if err != nil {
+			return err
+		}
+		for op, entry := range entries {
+			serialized, err := utxo.SerializeUtxoEntry(entry)
+			if err != nil {
+				return err
+			}
+			if err := bucket.Put(*utxo.OutpointKey(op), serialized); err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	return db
+}
+
+func testUtxoSetStats(t *testing.T, db database.DB, invalid *hash.Hash) *UtxoSetStats {
+	var stats *UtxoSetStats
+	err := db.View(func(dbTx database.Tx) error {
+		var err error
+		stats, err = utxoSetStats(dbTx, func(entry *utxo.UtxoEntry) bool {
+			return entry.BlockHash().IsEqual(invalid)
+		})
+		return err
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	return stats
+}
+
+func TestUtxoSetStats(t *testing.T) {
+	valid := hash.Hash{0x01}
+	invalid := hash.Hash{0x02}
+	pkScript := []byte{0x51}
+	entries := map[types.TxOutPoint]*utxo.UtxoEntry{
+		{Hash: hash.Hash{0x10}, OutIndex: 0}: utxo.NewUtxoEntry(types.Amount{Id: types.MEERA, Value: 100}, pkScript, &valid, true),
+		{Hash: hash.Hash{0x10}, OutIndex: 1}: utxo.NewUtxoEntry(types.Amount{Id: types.MEERA, Value: 200}, pkScript, &valid, false),
+		{Hash: hash.Hash{0x11}, OutIndex: 0}: utxo.NewUtxoEntry(types.Amount{Id: testTokenID, Value: 300}, pkScript, &valid, false),
+		{Hash: hash.Hash{0x12}, OutIndex: 0}: utxo.NewUtxoEntry(types.Amount{Id: types.MEERA, Value: 400}, pkScript, &invalid, false),
+	}
+	stats := testUtxoSetStats(t, putTestUtxos(t, entries), &invalid)
+
+	if stats.Txouts != 3 {
+		t.Fatalf("got %d outputs, want 3", stats.Txouts)
+	}
+	if len(stats.Coins) != 2 || stats.Coins[0].CoinId != types.MEERA || stats.Coins[1].CoinId != testTokenID {
+		t.Fatalf("got the coins %v, want MEER and the token", stats.Coins)
+	}
+	if stats.Coins[0].Txouts != 2 || stats.Coins[0].Amount != 300 {
+		t.Fatalf("got %d MEER outputs of %d, want 2 of 300", stats.Coins[0].Txouts, stats.Coins[0].Amount)
+	}
+	if stats.Coins[1].Txouts != 1 || stats.Coins[1].Amount != 300 {
+		t.Fatalf("got %d token outputs of %d, want 1 of 300", stats.Coins[1].Txouts, stats.Coins[1].Amount)
+	}
+	if stats.BytesSerialized != stats.Coins[0].BytesSerialized+stats.Coins[1].BytesSerialized {
+		t.Fatalf("the serialized size %d isn't the sum of the coins", stats.BytesSerialized)
+	}
+
+	// The hash only depends on the valid outputs.
+	delete(entries, types.TxOutPoint{Hash: hash.Hash{0x12}, OutIndex: 0})
+	same := testUtxoSetStats(t, putTestUtxos(t, entries), &invalid)
+	if same.Hash != stats.Hash {
+		t.Fatalf("the hash of the same utxo set is different")
+	}
+	entries[types.TxOutPoint{Hash: hash.Hash{0x10}, OutIndex: 1}].SetAmount(types.Amount{Id: types.MEERA, Value: 201})
+	changed := testUtxoSetStats(t, putTestUtxos(t, entries), &invalid)
+	if changed.Hash == stats.Hash {
+		t.Fatalf("the hash of a changed utxo set is the same")
+	}
+}
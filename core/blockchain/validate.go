@@ -862,7 +862,7 @@ func (b *BlockChain) checkConnectBlock(ib meerdag.IBlock, block *types.Serialize
 	var scriptFlags txscript.ScriptFlags
 	var err error
 	if runScripts {
-		scriptFlags, err = b.consensusScriptVerifyFlags()
+		scriptFlags, err = b.consensusScriptVerifyFlags(int64(ib.GetHeight()))
 		if err != nil {
 			return err
 		}
@@ -926,7 +926,7 @@ func (b *BlockChain) checkConnectBlock(ib meerdag.IBlock, block *types.Serialize
 	}
 
 	if runScripts {
-		err = b.checkBlockScripts(block, utxoView,
+		err = b.checkBlockScripts(ib, block, utxoView,
 			scriptFlags, b.sigCache)
 		if err != nil {
 			log.Trace("checkBlockScripts failed; error returned "+
@@ -945,8 +945,8 @@ func (b *BlockChain) checkConnectBlock(ib meerdag.IBlock, block *types.Serialize
 // consensusScriptVerifyFlags returns the script flags that must be used when
 // executing transaction scripts to enforce the consensus rules. This includes
 // any flags required as the result of any agendas that have passed and become
-// active.
-func (b *BlockChain) consensusScriptVerifyFlags() (txscript.ScriptFlags, error) {
+// active, and the forks active at the main height of the block.
+func (b *BlockChain) consensusScriptVerifyFlags(mainHeight int64) (txscript.ScriptFlags, error) {
 	//TODO, refactor the txvm flag, the flag should decided by node.parent
 	scriptFlags := txscript.ScriptBip16 |
 		txscript.ScriptVerifyDERSignatures |
@@ -957,6 +957,9 @@ func (b *BlockChain) consensusScriptVerifyFlags() (txscript.ScriptFlags, error)
 
 	scriptFlags |= txscript.ScriptVerifyCheckSequenceVerify
 	scriptFlags |= txscript.ScriptVerifySHA256
+	if forks.IsTokenOpcodesForkHeight(mainHeight) {
+		scriptFlags |= txscript.ScriptVerifyTokenOpcodes
+	}
 	return scriptFlags, nil
 }
 
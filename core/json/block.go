@@ -86,3 +86,99 @@ type TokenState struct {
 	Balance    int64  `json:"balance,omitempty"`
 	LockedMeer int64  `json:"lockedMEER,omitempty"`
 }
+
+// TokenHoldersResult models the data of the getTokenHolders command. Holders
+// is the total count of the holders and Held the sum of their balances.
+type TokenHoldersResult struct {
+	CoinId   uint16              `json:"coinid"`
+	CoinName string              `json:"coinname"`
+	Holders  uint32              `json:"holders"`
+	Held     int64               `json:"held"`
+	List     []TokenHolderResult `json:"list"`
+}
+
+type TokenHolderResult struct {
+	Address string `json:"address"`
+	Balance int64  `json:"balance"`
+}
+
+// TokenEventResult models an event of the getTokenHistory command.
+type TokenEventResult struct {
+	Type    string `json:"type"`
+	TxId    string `json:"txid"`
+	Order   uint32 `json:"order"`
+	TxIndex uint32 `json:"txindex"`
+	Amount  int64  `json:"amount"`
+}
+
+// TokenBalanceResult models a token balance of the getTokenBalances command.
+type TokenBalanceResult struct {
+	CoinId   uint16 `json:"coinid"`
+	CoinName string `json:"coinname"`
+	Balance  int64  `json:"balance"`
+}
+
+// BlockStatsResult models the data of the getBlockStats command. The fees
+// and fee rates are in atoms of MEER, the fee rates in atoms per byte, and
+// the sizes in bytes. TotalOut and TotalFees are keyed by the coin name.
+type BlockStatsResult struct {
+	BlockHash          string           `json:"blockhash"`
+	Order              uint64           `json:"order"`
+	Height             uint64           `json:"height"`
+	Time               int64            `json:"time"`
+	Blue               bool             `json:"blue"`
+	Valid              bool             `json:"valid"`
+	Txs                int              `json:"txs"`
+	Ins                int              `json:"ins"`
+	Outs               int              `json:"outs"`
+	TotalSize          int              `json:"total_size"`
+	TotalTxSize        int              `json:"total_tx_size"`
+	MinTxSize          int              `json:"mintxsize"`
+	MaxTxSize          int              `json:"maxtxsize"`
+	AvgTxSize          int              `json:"avgtxsize"`
+	TotalOut           map[string]int64 `json:"total_out"`
+	TotalFees          map[string]int64 `json:"totalfees"`
+	TotalFee           int64            `json:"totalfee"`
+	MinFee             int64            `json:"minfee"`
+	MaxFee             int64            `json:"maxfee"`
+	AvgFee             int64            `json:"avgfee"`
+	MedianFee          int64            `json:"medianfee"`
+	MinFeeRate         int64            `json:"minfeerate"`
+	MaxFeeRate         int64            `json:"maxfeerate"`
+	AvgFeeRate         int64            `json:"avgfeerate"`
+	FeeRatePercentiles []int64          `json:"feerate_percentiles"`
+	TokenTxs           int              `json:"token_txs"`
+	ImportTxs          int              `json:"import_txs"`
+	ExportTxs          int              `json:"export_txs"`
+	VMTxs              int              `json:"vm_txs"`
+}
+
+// ReorganizationDiff models the data of the reorganization notifications.
+// The old blocks are listed with the orders they had before the
+// reorganization, the new blocks with the orders they have after it.
+type ReorganizationDiff struct {
+	Hash           string      `json:"hash"`
+	Order          uint64      `json:"order"`
+	OldBlocks      []string    `json:"oldblocks"`
+	OldOrders      []uint64    `json:"oldorders"`
+	OldRange       *OrderRange `json:"oldrange,omitempty"`
+	NewBlocks      []string    `json:"newblocks"`
+	NewOrders      []uint64    `json:"neworders"`
+	NewRange       *OrderRange `json:"newrange,omitempty"`
+	BlueFlips      []BlueFlip  `json:"blueflips"`
+	ValidatedTxs   []string    `json:"validatedtxs"`
+	InvalidatedTxs []string    `json:"invalidatedtxs"`
+}
+
+// OrderRange is an inclusive range of block orders.
+type OrderRange struct {
+	Start uint64 `json:"start"`
+	End   uint64 `json:"end"`
+}
+
+// BlueFlip is a block whose blue/red status was changed by a
+// reorganization, blue is the new status.
+type BlueFlip struct {
+	Hash string `json:"hash"`
+	Blue bool   `json:"blue"`
+}
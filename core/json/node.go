@@ -111,6 +111,112 @@ type AcctInfo struct {
 	Total   uint32   `json:"total"`
 	Watcher uint32   `json:"watcher"`
 	Addrs   []string `json:"addrs,omitempty"`
+	Xpubs   []string `json:"xpubs,omitempty"`
+}
+
+type AddrEventResult struct {
+	Order  uint32 `json:"order"`
+	TxId   string `json:"txid"`
+	Type   string `json:"type"`
+	Index  uint32 `json:"index"`
+	CoinId string `json:"coinid"`
+	Amount int64  `json:"amount"`
+	Locked bool   `json:"locked,omitempty"`
+}
+
+type BalanceAtResult struct {
+	Address  string           `json:"address"`
+	Order    uint32           `json:"order"`
+	Balances map[string]int64 `json:"balances"`
+}
+
+type LockedBalanceScheduleResult struct {
+	Address       string             `json:"address"`
+	CoinId        string             `json:"coinid"`
+	Locked        uint64             `json:"locked"`
+	MainHeight    uint64             `json:"mainheight"`
+	MainLayer     uint64             `json:"mainlayer"`
+	BlockInterval float64            `json:"blockinterval"`
+	UTXOs         []LockedUTXOResult `json:"utxos"`
+}
+
+// LockedUTXOResult is a locked output with its unlock condition, the
+// remaining main chain blocks and the expected unlock time.
+type LockedUTXOResult struct {
+	TxId         string `json:"txid"`
+	Index        uint32 `json:"idx"`
+	Type         string `json:"type"`
+	Amount       uint64 `json:"amount"`
+	Condition    string `json:"condition"`
+	LockTime     int64  `json:"locktime,omitempty"`
+	UnlockLayer  uint64 `json:"unlocklayer,omitempty"`
+	UnlockHeight uint64 `json:"unlockheight,omitempty"`
+	Remaining    int64  `json:"remaining"`
+	UnlockTime   int64  `json:"unlocktime"`
+}
+
+type XpubBalanceResult struct {
+	Name     string                  `json:"name"`
+	Xpub     string                  `json:"xpub"`
+	Path     string                  `json:"path"`
+	GapLimit uint32                  `json:"gaplimit"`
+	Used     uint32                  `json:"used"`
+	Balance  uint64                  `json:"balance"`
+	Locked   uint64                  `json:"locked"`
+	Addrs    []XpubAddrBalanceResult `json:"addrs"`
+}
+
+type XpubAddrBalanceResult struct {
+	Index   uint32 `json:"index"`
+	Path    string `json:"path"`
+	Address string `json:"address"`
+	Balance uint64 `json:"balance"`
+	Locked  uint64 `json:"locked"`
+	UTXOs   uint32 `json:"utxos"`
+}
+
+type BalanceInfoResult struct {
+	CoinId  string       `json:"coinid"`
+	Balance int64        `json:"balance"`
+	UTXOs   []UTXOResult `json:"utxos,omitempty"`
+}
+
+type UTXOResult struct {
+	Type      string `json:"type"`
+	Amount    uint64 `json:"amount"`
+	PreTxHash string `json:"txid"`
+	PreOutIdx uint32 `json:"idx"`
+	Status    string `json:"status"`
+}
+
+type WalletInfoResult struct {
+	Version       int                   `json:"version"`
+	Unlocked      bool                  `json:"unlocked"`
+	UnlockedUntil int64                 `json:"unlockeduntil,omitempty"`
+	Accounts      []WalletAccountResult `json:"accounts"`
+}
+
+type WalletAccountResult struct {
+	Name     string `json:"name"`
+	Index    uint32 `json:"index"`
+	Xpub     string `json:"xpub"`
+	External uint32 `json:"external"`
+	Internal uint32 `json:"internal"`
+}
+
+type WalletUnspentResult struct {
+	TxId    string `json:"txid"`
+	Vout    uint32 `json:"vout"`
+	Address string `json:"address"`
+	CoinId  uint16 `json:"coinid"`
+	Coin    string `json:"coin"`
+	Amount  int64  `json:"amount"`
+	Type    string `json:"type"`
+}
+
+type SignRawTransactionResult struct {
+	Hex      string `json:"hex"`
+	Complete bool   `json:"complete"`
 }
 
 type MeerDAGInfoResult struct {
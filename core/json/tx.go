@@ -137,6 +137,79 @@ type GetUtxoResult struct {
 	Coinbase      bool               `json:"coinbase"`
 }
 
+// IndexInfoResult models an index of the getIndexInfo command. The order is
+// -1 when nothing is indexed yet.
+type IndexInfoResult struct {
+	Name   string `json:"name"`
+	Order  int64  `json:"order"`
+	Synced bool   `json:"synced"`
+}
+
+// TxOutSetInfoResult models the data of the getTxOutSetInfo command.
+type TxOutSetInfoResult struct {
+	BestBlock       string             `json:"bestblock"`
+	Order           uint64             `json:"order"`
+	TotalSubsidy    uint64             `json:"totalsubsidy"`
+	Txouts          uint64             `json:"txouts"`
+	BytesSerialized uint64             `json:"bytes_serialized"`
+	HashSerialized  string             `json:"hash_serialized"`
+	Coins           []TxOutSetCoinInfo `json:"coins"`
+}
+
+// TxOutSetCoinInfo models the unspent outputs of one coin in the
+// getTxOutSetInfo command. The amount is in atoms.
+type TxOutSetCoinInfo struct {
+	CoinId          uint16 `json:"coinid"`
+	CoinName        string `json:"coinname"`
+	Txouts          uint64 `json:"txouts"`
+	Amount          uint64 `json:"amount"`
+	BytesSerialized uint64 `json:"bytes_serialized"`
+}
+
+// GetSpendingTxResult models the data of the getSpendingTx command.
+// The block is empty when the spending transaction is in the mempool.
+type GetSpendingTxResult struct {
+	Txid      string `json:"txid"`
+	Vin       uint32 `json:"vin"`
+	BlockHash string `json:"blockhash,omitempty"`
+	Order     uint64 `json:"order,omitempty"`
+	InMempool bool   `json:"inmempool"`
+}
+
+// CrossChainTransferResult models the data of the getCrossChainTransfer and
+// listCrossChainTransfers commands. The status is pending, confirmed or
+// invalidated, the block is empty for the pending transfers.
+type CrossChainTransferResult struct {
+	Txid          string `json:"txid"`
+	Type          string `json:"type"`
+	Status        string `json:"status"`
+	Amount        int64  `json:"amount"`
+	CoinId        uint16 `json:"coinid"`
+	Address       string `json:"address,omitempty"`
+	EVMAddress    string `json:"evmaddress"`
+	EVMTxHash     string `json:"evmtxhash"`
+	BlockHash     string `json:"blockhash,omitempty"`
+	Order         uint64 `json:"order,omitempty"`
+	Confirmations uint64 `json:"confirmations"`
+}
+
+// GetUtxosResult models a single outpoint of the REST getutxos request.
+// Utxo is nil when the output is spent or unknown.
+type GetUtxosResult struct {
+	Txid string         `json:"txid"`
+	Vout uint32         `json:"vout"`
+	Utxo *GetUtxoResult `json:"utxo"`
+}
+
+// MempoolInfoResult models the data from the getMempoolInfo command.
+type MempoolInfoResult struct {
+	Size        int   `json:"size"`
+	Bytes       int64 `json:"bytes"`
+	Fees        int64 `json:"fees"`
+	Orphans     int   `json:"orphans"`
+	LastUpdated int64 `json:"lastupdated"`
+}
+
 // GetRawTransactionsResult models the data from the getrawtransactions
 // command.
 type GetRawTransactionsResult struct {
@@ -207,3 +280,125 @@ type Amout struct {
 }
 
 type AdreesAmount map[string]Amout
+
+// PsbtResult models the partially signed transaction returned by the psbt
+// commands, with whether all its inputs are finalized.
+type PsbtResult struct {
+	Psbt     string `json:"psbt"`
+	Complete bool   `json:"complete"`
+}
+
+// FinalizePsbtResult models the data from the finalizePsbt command. Hex is
+// the signed transaction when it is complete and extracted.
+type FinalizePsbtResult struct {
+	Psbt     string `json:"psbt,omitempty"`
+	Hex      string `json:"hex,omitempty"`
+	Complete bool   `json:"complete"`
+}
+
+// PsbtBip32DerivResult models the derivation hint of a key.
+type PsbtBip32DerivResult struct {
+	PubKey            string `json:"pubkey"`
+	MasterFingerprint string `json:"master_fingerprint"`
+	Path              string `json:"path"`
+}
+
+// PsbtInputResult models the signing data of an input.
+type PsbtInputResult struct {
+	PrevOut           *Vout                  `json:"prevout,omitempty"`
+	PartialSignatures map[string]string      `json:"partial_signatures,omitempty"`
+	SighashType       string                 `json:"sighash,omitempty"`
+	RedeemScript      *ScriptSig             `json:"redeem_script,omitempty"`
+	Bip32Derivs       []PsbtBip32DerivResult `json:"bip32_derivs,omitempty"`
+	FinalScriptSig    *ScriptSig             `json:"final_scriptSig,omitempty"`
+	Unknown           map[string]string      `json:"unknown,omitempty"`
+}
+
+// PsbtOutputResult models the data of an output.
+type PsbtOutputResult struct {
+	RedeemScript *ScriptSig             `json:"redeem_script,omitempty"`
+	Bip32Derivs  []PsbtBip32DerivResult `json:"bip32_derivs,omitempty"`
+	Unknown      map[string]string      `json:"unknown,omitempty"`
+}
+
+// DecodePsbtResult models the data from the decodePsbt command. Fee is only
+// known when the previous outputs of all the inputs are known.
+type DecodePsbtResult struct {
+	Tx       interface{}        `json:"tx"`
+	Unknown  map[string]string  `json:"unknown,omitempty"`
+	Inputs   []PsbtInputResult  `json:"inputs"`
+	Outputs  []PsbtOutputResult `json:"outputs"`
+	Fee      map[string]int64   `json:"fee,omitempty"`
+	Complete bool               `json:"complete"`
+}
+
+// FundRawTransactionOptions models the options of the fundRawTransaction
+// command.  The inputs are selected from the outputs of the addresses, the
+// change goes to the change address or the first address.  The fee rate is
+// in atoms per kilobyte, it is estimated for the confirmation target in
+// blocks when it is not given.
+type FundRawTransactionOptions struct {
+	Addresses     []string `json:"addresses"`
+	ChangeAddress string   `json:"changeAddress,omitempty"`
+	FeeRate       int64    `json:"feeRate,omitempty"`
+	ConfTarget    uint32   `json:"confTarget,omitempty"`
+}
+
+// FundRawTransactionResult models the data from the fundRawTransaction
+// command.  ChangePos is the index of the MEER change output, -1 when there
+// is none.
+type FundRawTransactionResult struct {
+	Hex       string `json:"hex"`
+	Fee       int64  `json:"fee"`
+	ChangePos int    `json:"changepos"`
+}
+
+// TraceStepResult models an executed opcode of the traceTxInput command.  The
+// stacks are in hex with the top item last, the branches are the states of
+// the conditional stack with the innermost last.
+type TraceStepResult struct {
+	Script         string   `json:"script"`
+	Offset         int      `json:"offset"`
+	Opcode         string   `json:"opcode"`
+	Executed       bool     `json:"executed"`
+	StackBefore    []string `json:"stackBefore"`
+	AltStackBefore []string `json:"altStackBefore"`
+	BranchBefore   []string `json:"branchBefore"`
+	StackAfter     []string `json:"stackAfter"`
+	AltStackAfter  []string `json:"altStackAfter"`
+	BranchAfter    []string `json:"branchAfter"`
+	Error          string   `json:"error,omitempty"`
+}
+
+// TraceTxInputResult models the data from the traceTxInput command.
+type TraceTxInputResult struct {
+	TxId        string            `json:"txid"`
+	Input       int               `json:"input"`
+	Amount      int64             `json:"amount"`
+	PkScriptAsm string            `json:"pkScriptAsm"`
+	Steps       []TraceStepResult `json:"steps"`
+	Valid       bool              `json:"valid"`
+	Error       string            `json:"error,omitempty"`
+}
+
+// HTLCResult models the data of a hash time-locked contract from the
+// createHTLC command.  The contract is paid either directly or by its
+// pay-to-script-hash address.
+type HTLCResult struct {
+	Contract    string `json:"contract"`
+	Asm         string `json:"asm"`
+	P2shAddress string `json:"p2shAddress"`
+	Recipient   string `json:"recipient"`
+	Refund      string `json:"refund"`
+	HashType    string `json:"hashType"`
+	SecretHash  string `json:"secretHash"`
+	LockTime    int64  `json:"lockTime"`
+}
+
+// DescriptorInfoResult models the data from the getDescriptorInfo command.
+type DescriptorInfoResult struct {
+	Descriptor     string `json:"descriptor"`
+	Checksum       string `json:"checksum"`
+	IsRange        bool   `json:"isrange"`
+	HasPrivateKeys bool   `json:"hasprivatekeys"`
+}
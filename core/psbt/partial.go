@@ -0,0 +1,418 @@
+package psbt
+
+import (
+	"bytes"
+	"encoding/binary"
+	"errors"
+	"github.com/Qitmeer/qng/common/hash"
+	s "github.com/Qitmeer/qng/core/serialization"
+	"github.com/Qitmeer/qng/core/types"
+	"github.com/Qitmeer/qng/engine/txscript"
+	"io"
+	"sort"
+)
+
+// The key types of the global map.
+const (
+	GlobalUnsignedTxType = 0x00
+	GlobalVersionType    = 0xfb
+)
+
+// The key types of the input maps.
+const (
+	InPrevTxType          = 0x00
+	InPrevOutType         = 0x01
+	InPartialSigType      = 0x02
+	InSighashType         = 0x03
+	InRedeemScriptType    = 0x04
+	InBip32DerivationType = 0x06
+	InFinalScriptSigType  = 0x07
+)
+
+// The key types of the output maps.
+const (
+	OutRedeemScriptType    = 0x00
+	OutBip32DerivationType = 0x02
+)
+
+// PartialSig is the signature of an input by a public key.
+type PartialSig struct {
+	PubKey    []byte
+	Signature []byte
+}
+
+// Bip32Derivation is the hint of the HD key of a public key, the fingerprint
+// of its master key and its derivation path.
+type Bip32Derivation struct {
+	PubKey               []byte
+	MasterKeyFingerprint uint32
+	Path                 []uint32
+}
+
+func (d *Bip32Derivation) encode() []byte {
+	value := make([]byte, 4*(len(d.Path)+1))
+	binary.LittleEndian.PutUint32(value, d.MasterKeyFingerprint)
+	for i, idx := range d.Path {
+		binary.LittleEndian.PutUint32(value[4*(i+1):], idx)
+	}
+	return value
+}
+
+func decodeBip32Derivation(pubKey []byte, value []byte) (*Bip32Derivation, error) {
+	if len(value) < 4 || len(value)%4 != 0 {
+		return nil, ErrInvalidPsbtFormat
+	}
+	d := &Bip32Derivation{
+		PubKey:               pubKey,
+		MasterKeyFingerprint: binary.LittleEndian.Uint32(value),
+	}
+	for i := 4; i < len(value); i += 4 {
+		d.Path = append(d.Path, binary.LittleEndian.Uint32(value[i:]))
+	}
+	return d, nil
+}
+
+// PInput is the signing data of an input.
+type PInput struct {
+	// PrevTx is the transaction of the output spent by the input, which
+	// proves the previous output since its hash is the one of the outpoint.
+	PrevTx *types.Transaction
+	// PrevOut is the output spent by the input, with its coin and amount.
+	PrevOut         *types.TxOutput
+	PartialSigs     []*PartialSig
+	SighashType     txscript.SigHashType
+	RedeemScript    []byte
+	Bip32Derivation []*Bip32Derivation
+	FinalScriptSig  []byte
+	Unknowns        []*Unknown
+
+	// prevOutChecked is set when the previous output is checked against
+	// the UTXO set by VerifyInPrevOut.
+	prevOutChecked bool
+}
+
+// IsFinalized returns whether the sign script of the input is final.
+func (pi *PInput) IsFinalized() bool {
+	return len(pi.FinalScriptSig) > 0
+}
+
+// IsPrevOutVerified returns whether the previous output of the input is
+// proven by its previous transaction or checked against the UTXO set, the
+// signatures don't commit to the amounts so the other ones can't be trusted.
+func (pi *PInput) IsPrevOutVerified() bool {
+	return pi.PrevOut != nil && (pi.PrevTx != nil || pi.prevOutChecked)
+}
+
+// samePrevOut returns whether the outputs have the same coin, amount and
+// script.
+func samePrevOut(a *types.TxOutput, b *types.TxOutput) bool {
+	return a.Amount == b.Amount && bytes.Equal(a.PkScript, b.PkScript)
+}
+
+// subScript returns the script which the input signatures commit to.
+func (pi *PInput) subScript() ([]byte, error) {
+	if pi.PrevOut == nil {
+		return nil, ErrNoPrevOut
+	}
+	if txscript.GetScriptClass(txscript.DefaultScriptVersion, pi.PrevOut.PkScript) != txscript.ScriptHashTy {
+		return pi.PrevOut.PkScript, nil
+	}
+	if len(pi.RedeemScript) <= 0 {
+		return nil, ErrNoRedeemScript
+	}
+	return pi.RedeemScript, nil
+}
+
+// checkRedeemScript checks the redeem script matches the pay-to-script-hash
+// previous output.
+func (pi *PInput) checkRedeemScript() error {
+	if pi.PrevOut == nil || len(pi.RedeemScript) <= 0 {
+		return nil
+	}
+	pops, err := txscript.ParseScript(pi.PrevOut.PkScript)
+	if err != nil {
+		return err
+	}
+	if !txscript.IsPayToScriptHash(pi.PrevOut.PkScript) || len(pops) != 3 {
+		return ErrInvalidRedeemScript
+	}
+	if !bytes.Equal(pops[1].GetData(), hash.Hash160(pi.RedeemScript)) {
+		return ErrInvalidRedeemScript
+	}
+	return nil
+}
+
+func (pi *PInput) addPartialSig(ps *PartialSig) {
+	for _, old := range pi.PartialSigs {
+		if bytes.Equal(old.PubKey, ps.PubKey) {
+			return
+		}
+	}
+	pi.PartialSigs = append(pi.PartialSigs, ps)
+	sort.Slice(pi.PartialSigs, func(i, j int) bool {
+		return bytes.Compare(pi.PartialSigs[i].PubKey, pi.PartialSigs[j].PubKey) < 0
+	})
+}
+
+func (pi *PInput) addBip32Derivation(d *Bip32Derivation) {
+	for _, old := range pi.Bip32Derivation {
+		if bytes.Equal(old.PubKey, d.PubKey) {
+			return
+		}
+	}
+	pi.Bip32Derivation = append(pi.Bip32Derivation, d)
+}
+
+// merge merges the data of the other packet of the input.
+func (pi *PInput) merge(o *PInput) error {
+	if pi.PrevTx == nil {
+		pi.PrevTx = o.PrevTx
+	} else if o.PrevTx != nil && pi.PrevTx.TxHash() != o.PrevTx.TxHash() {
+		return errors.New("Different previous transactions")
+	}
+	if pi.PrevOut == nil {
+		pi.PrevOut = o.PrevOut
+	} else if o.PrevOut != nil && !samePrevOut(pi.PrevOut, o.PrevOut) {
+		return errors.New("Different previous outputs")
+	}
+	if len(pi.RedeemScript) <= 0 {
+		pi.RedeemScript = o.RedeemScript
+	}
+	if pi.SighashType == 0 {
+		pi.SighashType = o.SighashType
+	}
+	if len(pi.FinalScriptSig) <= 0 {
+		pi.FinalScriptSig = o.FinalScriptSig
+	}
+	for _, ps := range o.PartialSigs {
+		pi.addPartialSig(ps)
+	}
+	for _, d := range o.Bip32Derivation {
+		pi.addBip32Derivation(d)
+	}
+	pi.Unknowns = mergeUnknowns(pi.Unknowns, o.Unknowns)
+	return pi.checkRedeemScript()
+}
+
+func (pi *PInput) serialize(w io.Writer) error {
+	if pi.PrevTx != nil {
+		txBytes, err := pi.PrevTx.Serialize()
+		if err != nil {
+			return err
+		}
+		err = writeEntry(w, InPrevTxType, nil, txBytes)
+		if err != nil {
+			return err
+		}
+	}
+	if pi.PrevOut != nil {
+		var buf bytes.Buffer
+		err := s.WriteElements(&buf, uint16(pi.PrevOut.Amount.Id), pi.PrevOut.Amount.Value)
+		if err != nil {
+			return err
+		}
+		err = s.WriteVarBytes(&buf, 0, pi.PrevOut.PkScript)
+		if err != nil {
+			return err
+		}
+		err = writeEntry(w, InPrevOutType, nil, buf.Bytes())
+		if err != nil {
+			return err
+		}
+	}
+	if !pi.IsFinalized() {
+		for _, ps := range pi.PartialSigs {
+			err := writeEntry(w, InPartialSigType, ps.PubKey, ps.Signature)
+			if err != nil {
+				return err
+			}
+		}
+		if pi.SighashType != 0 {
+			err := writeEntry(w, InSighashType, nil, uint32Bytes(uint32(pi.SighashType)))
+			if err != nil {
+				return err
+			}
+		}
+		if len(pi.RedeemScript) > 0 {
+			err := writeEntry(w, InRedeemScriptType, nil, pi.RedeemScript)
+			if err != nil {
+				return err
+			}
+		}
+		for _, d := range pi.Bip32Derivation {
+			err := writeEntry(w, InBip32DerivationType, d.PubKey, d.encode())
+			if err != nil {
+				return err
+			}
+		}
+	} else {
+		err := writeEntry(w, InFinalScriptSigType, nil, pi.FinalScriptSig)
+		if err != nil {
+			return err
+		}
+	}
+	err := writeUnknowns(w, pi.Unknowns)
+	if err != nil {
+		return err
+	}
+	return writeSeparator(w)
+}
+
+func (pi *PInput) deserialize(r io.Reader) error {
+	return readMap(r, func(kt byte, keyData []byte, value []byte) error {
+		switch kt {
+		case InPrevTxType:
+			if len(keyData) > 0 || pi.PrevTx != nil {
+				return ErrDuplicateKey
+			}
+			tx := &types.Transaction{}
+			err := tx.Deserialize(bytes.NewReader(value))
+			if err != nil {
+				return err
+			}
+			pi.PrevTx = tx
+		case InPrevOutType:
+			if len(keyData) > 0 || pi.PrevOut != nil {
+				return ErrDuplicateKey
+			}
+			vr := bytes.NewReader(value)
+			coin := uint16(0)
+			amount := int64(0)
+			err := s.ReadElements(vr, &coin, &amount)
+			if err != nil {
+				return err
+			}
+			pkScript, err := s.ReadVarBytes(vr, 0, MaxPsbtValueLength, "pkScript")
+			if err != nil {
+				return err
+			}
+			pi.PrevOut = types.NewTxOutput(types.Amount{Id: types.CoinID(coin), Value: amount}, pkScript)
+		case InPartialSigType:
+			for _, ps := range pi.PartialSigs {
+				if bytes.Equal(ps.PubKey, keyData) {
+					return ErrDuplicateKey
+				}
+			}
+			pi.addPartialSig(&PartialSig{PubKey: keyData, Signature: value})
+		case InSighashType:
+			if len(keyData) > 0 || pi.SighashType != 0 {
+				return ErrDuplicateKey
+			}
+			ht, err := readUint32(value)
+			if err != nil {
+				return err
+			}
+			pi.SighashType = txscript.SigHashType(ht)
+		case InRedeemScriptType:
+			if len(keyData) > 0 || len(pi.RedeemScript) > 0 {
+				return ErrDuplicateKey
+			}
+			pi.RedeemScript = value
+		case InBip32DerivationType:
+			for _, d := range pi.Bip32Derivation {
+				if bytes.Equal(d.PubKey, keyData) {
+					return ErrDuplicateKey
+				}
+			}
+			d, err := decodeBip32Derivation(keyData, value)
+			if err != nil {
+				return err
+			}
+			pi.Bip32Derivation = append(pi.Bip32Derivation, d)
+		case InFinalScriptSigType:
+			if len(keyData) > 0 || len(pi.FinalScriptSig) > 0 {
+				return ErrDuplicateKey
+			}
+			pi.FinalScriptSig = value
+		default:
+			pi.Unknowns = append(pi.Unknowns, &Unknown{Key: append([]byte{kt}, keyData...), Value: value})
+		}
+		return nil
+	})
+}
+
+// POutput is the data of an output which lets the signers verify it, like
+// the change outputs.
+type POutput struct {
+	RedeemScript    []byte
+	Bip32Derivation []*Bip32Derivation
+	Unknowns        []*Unknown
+}
+
+func (po *POutput) merge(o *POutput) {
+	if len(po.RedeemScript) <= 0 {
+		po.RedeemScript = o.RedeemScript
+	}
+	for _, d := range o.Bip32Derivation {
+		has := false
+		for _, old := range po.Bip32Derivation {
+			if bytes.Equal(old.PubKey, d.PubKey) {
+				has = true
+				break
+			}
+		}
+		if !has {
+			po.Bip32Derivation = append(po.Bip32Derivation, d)
+		}
+	}
+	po.Unknowns = mergeUnknowns(po.Unknowns, o.Unknowns)
+}
+
+func (po *POutput) serialize(w io.Writer) error {
+	if len(po.RedeemScript) > 0 {
+		err := writeEntry(w, OutRedeemScriptType, nil, po.RedeemScript)
+		if err != nil {
+			return err
+		}
+	}
+	for _, d := range po.Bip32Derivation {
+		err := writeEntry(w, OutBip32DerivationType, d.PubKey, d.encode())
+		if err != nil {
+			return err
+		}
+	}
+	err := writeUnknowns(w, po.Unknowns)
+	if err != nil {
+		return err
+	}
+	return writeSeparator(w)
+}
+
+func (po *POutput) deserialize(r io.Reader) error {
+	return readMap(r, func(kt byte, keyData []byte, value []byte) error {
+		switch kt {
+		case OutRedeemScriptType:
+			if len(keyData) > 0 || len(po.RedeemScript) > 0 {
+				return ErrDuplicateKey
+			}
+			po.RedeemScript = value
+		case OutBip32DerivationType:
+			for _, d := range po.Bip32Derivation {
+				if bytes.Equal(d.PubKey, keyData) {
+					return ErrDuplicateKey
+				}
+			}
+			d, err := decodeBip32Derivation(keyData, value)
+			if err != nil {
+				return err
+			}
+			po.Bip32Derivation = append(po.Bip32Derivation, d)
+		default:
+			po.Unknowns = append(po.Unknowns, &Unknown{Key: append([]byte{kt}, keyData...), Value: value})
+		}
+		return nil
+	})
+}
+
+func uint32Bytes(v uint32) []byte {
+	b := make([]byte, 4)
+	binary.LittleEndian.PutUint32(b, v)
+	return b
+}
+
+func readUint32(b []byte) (uint32, error) {
+	if len(b) != 4 {
+		return 0, ErrInvalidPsbtFormat
+	}
+	return binary.LittleEndian.Uint32(b), nil
+}
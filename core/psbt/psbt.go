@@ -0,0 +1,430 @@
+// Copyright (c) 2017-2018 The qitmeer developers
+
+// Package psbt implements the partially signed transactions of qng, a
+// container modeled on BIP174 which carries an unsigned transaction with the
+// data its signers need: the previous transactions which prove the previous
+// outputs with their coins, the redeem scripts, the sighash types, the BIP32
+// derivation hints of the keys and the partial signatures.  It is passed between the parties which update, sign
+// and combine it until the inputs can be finalized and the signed transaction
+// extracted.
+package psbt
+
+import (
+	"bytes"
+	"encoding/base64"
+	"errors"
+	"fmt"
+	s "github.com/Qitmeer/qng/core/serialization"
+	"github.com/Qitmeer/qng/core/types"
+	"io"
+	"io/ioutil"
+)
+
+// Magic is the prefix of the serialized packets, "qpst" with the 0xff
+// separator.
+var Magic = []byte{0x71, 0x70, 0x73, 0x74, 0xff}
+
+const (
+	// Version is the version of the packet format.
+	Version = 0
+
+	// MaxPsbtValueLength is the maximum length of the values of the packet.
+	MaxPsbtValueLength = 4000000
+
+	// MaxPsbtKeyLength is the maximum length of the keys of the packet.
+	MaxPsbtKeyLength = 10000
+)
+
+var (
+	ErrInvalidMagic         = errors.New("Invalid magic of the partially signed transaction")
+	ErrInvalidPsbtFormat    = errors.New("Invalid format of the partially signed transaction")
+	ErrDuplicateKey         = errors.New("Duplicate key in the partially signed transaction")
+	ErrInvalidSignScript    = errors.New("The unsigned transaction has sign scripts")
+	ErrIndexOutOfRange      = errors.New("The index is out of range")
+	ErrNoPrevOut            = errors.New("The previous output of the input is unknown")
+	ErrInvalidPrevTx        = errors.New("The previous transaction does not match the outpoint of the input")
+	ErrInvalidPrevOut       = errors.New("The previous output does not match the one of the input")
+	ErrUnverifiedPrevOut    = errors.New("The previous output of the input is not verified")
+	ErrInvalidPartialSig    = errors.New("Invalid partial signature")
+	ErrNoRedeemScript       = errors.New("The redeem script of the input is unknown")
+	ErrInvalidRedeemScript  = errors.New("The redeem script does not match the previous output")
+	ErrNotFinalized         = errors.New("The inputs are not all finalized")
+	ErrUnsupportedScript    = errors.New("The script of the input is not supported")
+	ErrMismatchedUnsignedTx = errors.New("The partially signed transactions have different unsigned transactions")
+)
+
+// Unknown is a key value pair of the packet whose type is unknown, it is
+// kept so the packet can be passed through older versions.
+type Unknown struct {
+	Key   []byte
+	Value []byte
+}
+
+// Packet is a partially signed transaction.
+type Packet struct {
+	UnsignedTx *types.Transaction
+	Inputs     []PInput
+	Outputs    []POutput
+	Unknowns   []*Unknown
+}
+
+// New returns the packet of the unsigned transaction.
+func New(tx *types.Transaction) (*Packet, error) {
+	for _, txIn := range tx.TxIn {
+		if len(txIn.SignScript) > 0 {
+			return nil, ErrInvalidSignScript
+		}
+	}
+	return &Packet{
+		UnsignedTx: tx,
+		Inputs:     make([]PInput, len(tx.TxIn)),
+		Outputs:    make([]POutput, len(tx.TxOut)),
+		Unknowns:   []*Unknown{},
+	}, nil
+}
+
+// NewFromRawBytes decodes the packet, which is encoded by base64 when b64 is
+// true.
+func NewFromRawBytes(r io.Reader, b64 bool) (*Packet, error) {
+	if b64 {
+		data, err := ioutil.ReadAll(r)
+		if err != nil {
+			return nil, err
+		}
+		decoded, err := base64.StdEncoding.DecodeString(string(bytes.TrimSpace(data)))
+		if err != nil {
+			return nil, err
+		}
+		r = bytes.NewReader(decoded)
+	}
+	magic := make([]byte, len(Magic))
+	_, err := io.ReadFull(r, magic)
+	if err != nil {
+		return nil, err
+	}
+	if !bytes.Equal(magic, Magic) {
+		return nil, ErrInvalidMagic
+	}
+
+	p := &Packet{Unknowns: []*Unknown{}}
+	err = readMap(r, func(kt byte, keyData []byte, value []byte) error {
+		switch kt {
+		case GlobalUnsignedTxType:
+			if len(keyData) > 0 || p.UnsignedTx != nil {
+				return ErrDuplicateKey
+			}
+			tx := &types.Transaction{}
+			err := tx.Deserialize(bytes.NewReader(value))
+			if err != nil {
+				return err
+			}
+			p.UnsignedTx = tx
+		case GlobalVersionType:
+			version, err := readUint32(value)
+			if err != nil {
+				return err
+			}
+			if version > Version {
+				return fmt.Errorf("Unsupported version of the partially signed transaction:%d", version)
+			}
+		default:
+			p.Unknowns = append(p.Unknowns, &Unknown{Key: append([]byte{kt}, keyData...), Value: value})
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	if p.UnsignedTx == nil {
+		return nil, ErrInvalidPsbtFormat
+	}
+	for _, txIn := range p.UnsignedTx.TxIn {
+		if len(txIn.SignScript) > 0 {
+			return nil, ErrInvalidSignScript
+		}
+	}
+
+	p.Inputs = make([]PInput, len(p.UnsignedTx.TxIn))
+	for i := range p.Inputs {
+		err = p.Inputs[i].deserialize(r)
+		if err != nil {
+			return nil, err
+		}
+	}
+	p.Outputs = make([]POutput, len(p.UnsignedTx.TxOut))
+	for i := range p.Outputs {
+		err = p.Outputs[i].deserialize(r)
+		if err != nil {
+			return nil, err
+		}
+	}
+	err = p.SanityCheck()
+	if err != nil {
+		return nil, err
+	}
+	return p, nil
+}
+
+// NewFromBase64 decodes the base64 packet.
+func NewFromBase64(str string) (*Packet, error) {
+	return NewFromRawBytes(bytes.NewReader([]byte(str)), true)
+}
+
+// Serialize encodes the packet.
+func (p *Packet) Serialize(w io.Writer) error {
+	_, err := w.Write(Magic)
+	if err != nil {
+		return err
+	}
+	txBytes, err := p.UnsignedTx.Serialize()
+	if err != nil {
+		return err
+	}
+	err = writeEntry(w, GlobalUnsignedTxType, nil, txBytes)
+	if err != nil {
+		return err
+	}
+	if Version > 0 {
+		err = writeEntry(w, GlobalVersionType, nil, uint32Bytes(Version))
+		if err != nil {
+			return err
+		}
+	}
+	err = writeUnknowns(w, p.Unknowns)
+	if err != nil {
+		return err
+	}
+	err = writeSeparator(w)
+	if err != nil {
+		return err
+	}
+	for i := range p.Inputs {
+		err = p.Inputs[i].serialize(w)
+		if err != nil {
+			return err
+		}
+	}
+	for i := range p.Outputs {
+		err = p.Outputs[i].serialize(w)
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// B64Encode returns the base64 encoding of the packet.
+func (p *Packet) B64Encode() (string, error) {
+	var buf bytes.Buffer
+	err := p.Serialize(&buf)
+	if err != nil {
+		return "", err
+	}
+	return base64.StdEncoding.EncodeToString(buf.Bytes()), nil
+}
+
+// SanityCheck checks the packet matches its unsigned transaction.
+func (p *Packet) SanityCheck() error {
+	if p.UnsignedTx == nil {
+		return ErrInvalidPsbtFormat
+	}
+	if len(p.Inputs) != len(p.UnsignedTx.TxIn) ||
+		len(p.Outputs) != len(p.UnsignedTx.TxOut) {
+		return ErrInvalidPsbtFormat
+	}
+	for i := range p.Inputs {
+		err := p.checkPrevTx(i)
+		if err != nil {
+			return fmt.Errorf("input %d: %v", i, err)
+		}
+		err = p.Inputs[i].checkRedeemScript()
+		if err != nil {
+			return fmt.Errorf("input %d: %v", i, err)
+		}
+	}
+	return nil
+}
+
+// checkPrevTx checks the previous transaction of the input is the one of its
+// outpoint and its output is the previous output of the input.
+func (p *Packet) checkPrevTx(index int) error {
+	pi := &p.Inputs[index]
+	if pi.PrevTx == nil {
+		return nil
+	}
+	op := &p.UnsignedTx.TxIn[index].PreviousOut
+	if pi.PrevTx.TxHash() != op.Hash || op.OutIndex >= uint32(len(pi.PrevTx.TxOut)) {
+		return ErrInvalidPrevTx
+	}
+	if pi.PrevOut == nil || !samePrevOut(pi.PrevOut, pi.PrevTx.TxOut[op.OutIndex]) {
+		return ErrInvalidPrevOut
+	}
+	return nil
+}
+
+// IsComplete returns whether all the inputs are finalized.
+func (p *Packet) IsComplete() bool {
+	for i := range p.Inputs {
+		if !p.Inputs[i].IsFinalized() {
+			return false
+		}
+	}
+	return true
+}
+
+// Fee returns the fees of the coins, which are only known when the previous
+// outputs of all the inputs are known and verified.
+func (p *Packet) Fee() (map[types.CoinID]int64, error) {
+	fees := map[types.CoinID]int64{}
+	for i := range p.Inputs {
+		if p.Inputs[i].PrevOut == nil {
+			return nil, ErrNoPrevOut
+		}
+		if !p.Inputs[i].IsPrevOutVerified() {
+			return nil, ErrUnverifiedPrevOut
+		}
+		fees[p.Inputs[i].PrevOut.Amount.Id] += p.Inputs[i].PrevOut.Amount.Value
+	}
+	for _, txOut := range p.UnsignedTx.TxOut {
+		fees[txOut.Amount.Id] -= txOut.Amount.Value
+	}
+	return fees, nil
+}
+
+// Extract returns the signed transaction of the finalized packet.
+func (p *Packet) Extract() (*types.Transaction, error) {
+	if !p.IsComplete() {
+		return nil, ErrNotFinalized
+	}
+	txBytes, err := p.UnsignedTx.Serialize()
+	if err != nil {
+		return nil, err
+	}
+	tx := &types.Transaction{}
+	err = tx.Deserialize(bytes.NewReader(txBytes))
+	if err != nil {
+		return nil, err
+	}
+	for i, txIn := range tx.TxIn {
+		txIn.SignScript = p.Inputs[i].FinalScriptSig
+	}
+	return tx, nil
+}
+
+// Combine merges the data of the packets of the same unsigned transaction.
+func Combine(packets ...*Packet) (*Packet, error) {
+	if len(packets) <= 0 {
+		return nil, ErrInvalidPsbtFormat
+	}
+	result, err := packets[0].copy()
+	if err != nil {
+		return nil, err
+	}
+	txHash := result.UnsignedTx.TxHash()
+	for _, p := range packets[1:] {
+		if p.UnsignedTx.TxHash() != txHash ||
+			len(p.Inputs) != len(result.Inputs) ||
+			len(p.Outputs) != len(result.Outputs) {
+			return nil, ErrMismatchedUnsignedTx
+		}
+		for i := range p.Inputs {
+			err = result.Inputs[i].merge(&p.Inputs[i])
+			if err != nil {
+				return nil, fmt.Errorf("input %d: %v", i, err)
+			}
+		}
+		for i := range p.Outputs {
+			result.Outputs[i].merge(&p.Outputs[i])
+		}
+		result.Unknowns = mergeUnknowns(result.Unknowns, p.Unknowns)
+	}
+	return result, nil
+}
+
+// copy returns a deep copy of the packet.
+func (p *Packet) copy() (*Packet, error) {
+	var buf bytes.Buffer
+	err := p.Serialize(&buf)
+	if err != nil {
+		return nil, err
+	}
+	return NewFromRawBytes(&buf, false)
+}
+
+func (p *Packet) checkInput(index int) error {
+	if index < 0 || index >= len(p.Inputs) {
+		return ErrIndexOutOfRange
+	}
+	return nil
+}
+
+func (p *Packet) checkOutput(index int) error {
+	if index < 0 || index >= len(p.Outputs) {
+		return ErrIndexOutOfRange
+	}
+	return nil
+}
+
+// readMap reads the key value pairs up to the separator.
+func readMap(r io.Reader, handle func(kt byte, keyData []byte, value []byte) error) error {
+	for {
+		key, err := s.ReadVarBytes(r, 0, MaxPsbtKeyLength, "psbt key")
+		if err != nil {
+			return err
+		}
+		if len(key) == 0 {
+			return nil
+		}
+		value, err := s.ReadVarBytes(r, 0, MaxPsbtValueLength, "psbt value")
+		if err != nil {
+			return err
+		}
+		err = handle(key[0], key[1:], value)
+		if err != nil {
+			return err
+		}
+	}
+}
+
+func writeEntry(w io.Writer, kt byte, keyData []byte, value []byte) error {
+	err := s.WriteVarBytes(w, 0, append([]byte{kt}, keyData...))
+	if err != nil {
+		return err
+	}
+	return s.WriteVarBytes(w, 0, value)
+}
+
+func writeUnknowns(w io.Writer, unknowns []*Unknown) error {
+	for _, u := range unknowns {
+		err := s.WriteVarBytes(w, 0, u.Key)
+		if err != nil {
+			return err
+		}
+		err = s.WriteVarBytes(w, 0, u.Value)
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func writeSeparator(w io.Writer) error {
+	_, err := w.Write([]byte{0x00})
+	return err
+}
+
+func mergeUnknowns(a []*Unknown, b []*Unknown) []*Unknown {
+	for _, ub := range b {
+		has := false
+		for _, ua := range a {
+			if bytes.Equal(ua.Key, ub.Key) {
+				has = true
+				break
+			}
+		}
+		if !has {
+			a = append(a, ub)
+		}
+	}
+	return a
+}
@@ -0,0 +1,308 @@
+package psbt
+
+import (
+	"bytes"
+	"github.com/Qitmeer/qng/common/hash"
+	"github.com/Qitmeer/qng/core/address"
+	"github.com/Qitmeer/qng/core/types"
+	"github.com/Qitmeer/qng/crypto/ecc"
+	"github.com/Qitmeer/qng/engine/txscript"
+	"github.com/Qitmeer/qng/params"
+	"strings"
+	"testing"
+)
+
+func testKeys(t *testing.T, n int) []ecc.PrivateKey {
+	keys := make([]ecc.PrivateKey, n)
+	for i := range keys {
+		secret := bytes.Repeat([]byte{byte(i + 1)}, 32)
+		keys[i], _ = ecc.Secp256k1.PrivKeyFromBytes(secret)
+	}
+	return keys
+}
+
+// createMultiSigPacket returns the packet of a transaction which spends a
+// 2-of-3 pay-to-script-hash multisig output.
+func createMultiSigPacket(t *testing.T, keys []ecc.PrivateKey) (*Packet, []byte) {
+	pubKeys := make([]*address.SecpPubKeyAddress, len(keys))
+	for i, key := range keys {
+		_, pub := ecc.Secp256k1.PrivKeyFromBytes(key.Serialize())
+		addr, err := address.NewSecpPubKeyAddress(pub.SerializeCompressed(), &params.TestNetParams)
+		if err != nil {
+			t.Fatal(err)
+		}
+		pubKeys[i] = addr
+	}
+	redeemScript, err := txscript.MultiSigScript(pubKeys, 2)
+	if err != nil {
+		t.Fatal(err)
+	}
+	p2sh, err := address.NewScriptHashAddress(redeemScript, &params.TestNetParams)
+	if err != nil {
+		t.Fatal(err)
+	}
+	pkScript, err := txscript.PayToAddrScript(p2sh)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	prevTx := testPrevTx(pkScript)
+	prevHash := prevTx.TxHash()
+	tx := types.NewTransaction()
+	tx.AddTxIn(types.NewTxInput(types.NewOutPoint(&prevHash, 1), nil))
+	tx.AddTxOut(types.NewTxOutput(types.Amount{Value: 90000000, Id: types.MEERA}, pkScript))
+	p, err := New(tx)
+	if err != nil {
+		t.Fatal(err)
+	}
+	err = p.AddInPrevTx(0, prevTx)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return p, redeemScript
+}
+
+// testPrevTx returns the previous transaction whose second output of
+// 100000000 pays to the script.
+func testPrevTx(pkScript []byte) *types.Transaction {
+	prevTx := types.NewTransaction()
+	prevTx.AddTxIn(types.NewTxInput(types.NewOutPoint(&hash.Hash{0x01}, 0), nil))
+	prevTx.AddTxOut(types.NewTxOutput(types.Amount{Value: 5, Id: types.MEERA}, pkScript))
+	prevTx.AddTxOut(types.NewTxOutput(types.Amount{Value: 100000000, Id: types.MEERA}, pkScript))
+	return prevTx
+}
+
+func TestSerialize(t *testing.T) {
+	keys := testKeys(t, 3)
+	p, redeemScript := createMultiSigPacket(t, keys)
+	err := p.AddInRedeemScript(0, redeemScript)
+	if err != nil {
+		t.Fatal(err)
+	}
+	err = p.AddInBip32Derivation(0, &Bip32Derivation{PubKey: []byte{0x02, 0x01}, MasterKeyFingerprint: 7, Path: []uint32{0x8000002c, 0, 5}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	p.Unknowns = append(p.Unknowns, &Unknown{Key: []byte{0xf0, 0x01}, Value: []byte{0x02}})
+
+	b64, err := p.B64Encode()
+	if err != nil {
+		t.Fatal(err)
+	}
+	decoded, err := NewFromBase64(b64)
+	if err != nil {
+		t.Fatal(err)
+	}
+	b64Again, err := decoded.B64Encode()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if b64 != b64Again {
+		t.Fatalf("round trip mismatch:\n%s\n%s", b64, b64Again)
+	}
+	if !bytes.Equal(decoded.Inputs[0].RedeemScript, redeemScript) ||
+		decoded.Inputs[0].PrevOut.Amount.Value != 100000000 ||
+		len(decoded.Inputs[0].Bip32Derivation[0].Path) != 3 ||
+		len(decoded.Unknowns) != 1 {
+		t.Fatalf("decoded packet mismatch")
+	}
+
+	_, err = NewFromBase64("cXBzdA==")
+	if err == nil {
+		t.Fatalf("expected error of the invalid magic")
+	}
+}
+
+func TestInvalidRedeemScript(t *testing.T) {
+	keys := testKeys(t, 3)
+	p, redeemScript := createMultiSigPacket(t, keys)
+	err := p.AddInRedeemScript(0, redeemScript[1:])
+	if err != ErrInvalidRedeemScript {
+		t.Fatalf("expected %v, got %v", ErrInvalidRedeemScript, err)
+	}
+}
+
+func TestMultiSig(t *testing.T) {
+	keys := testKeys(t, 4)
+	p, redeemScript := createMultiSigPacket(t, keys[:3])
+	err := p.AddInRedeemScript(0, redeemScript)
+	if err != nil {
+		t.Fatal(err)
+	}
+	b64, err := p.B64Encode()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// The signers get their own copies of the packet.
+	signed := []*Packet{}
+	for _, key := range []ecc.PrivateKey{keys[2], keys[0]} {
+		sp, err := NewFromBase64(b64)
+		if err != nil {
+			t.Fatal(err)
+		}
+		ok, err := sp.Sign(0, key)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if !ok {
+			t.Fatalf("expected the key to sign")
+		}
+		if sp.MaybeFinalizeAll() {
+			t.Fatalf("expected one signature to be not enough")
+		}
+		signed = append(signed, sp)
+	}
+	ok, err := p.Sign(0, keys[3])
+	if err != nil || ok {
+		t.Fatalf("expected the foreign key not to sign:%v", err)
+	}
+
+	_, err = signed[0].Extract()
+	if err != ErrNotFinalized {
+		t.Fatalf("expected %v, got %v", ErrNotFinalized, err)
+	}
+	combined, err := Combine(signed...)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(combined.Inputs[0].PartialSigs) != 2 {
+		t.Fatalf("expected 2 signatures, got %d", len(combined.Inputs[0].PartialSigs))
+	}
+	if !combined.MaybeFinalizeAll() {
+		t.Fatalf("expected the packet to be complete")
+	}
+	fees, err := combined.Fee()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if fees[types.MEERA] != 10000000 {
+		t.Fatalf("expected fee 10000000, got %d", fees[types.MEERA])
+	}
+	tx, err := combined.Extract()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if tx.TxHash() != p.UnsignedTx.TxHash() {
+		t.Fatalf("the extracted transaction is different")
+	}
+
+	flags := txscript.ScriptBip16 | txscript.ScriptVerifyDERSignatures |
+		txscript.ScriptVerifyStrictEncoding | txscript.ScriptVerifyMinimalData
+	vm, err := txscript.NewEngine(combined.Inputs[0].PrevOut.PkScript, tx, 0, flags, 0, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	err = vm.Execute()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	other := types.NewTransaction()
+	other.AddTxIn(types.NewTxInput(types.NewOutPoint(&hash.Hash{0x02}, 0), nil))
+	op, err := New(other)
+	if err != nil {
+		t.Fatal(err)
+	}
+	_, err = Combine(signed[0], op)
+	if err != ErrMismatchedUnsignedTx {
+		t.Fatalf("expected %v, got %v", ErrMismatchedUnsignedTx, err)
+	}
+}
+
+func TestPrevOut(t *testing.T) {
+	keys := testKeys(t, 3)
+	p, redeemScript := createMultiSigPacket(t, keys)
+	err := p.AddInRedeemScript(0, redeemScript)
+	if err != nil {
+		t.Fatal(err)
+	}
+	prevTx := p.Inputs[0].PrevTx
+	pkScript := p.Inputs[0].PrevOut.PkScript
+
+	// The previous transaction must be the one of the outpoint and agree
+	// with the previous output.
+	other := testPrevTx(pkScript)
+	other.TxOut[1].Amount.Value++
+	if err := p.AddInPrevTx(0, other); err != ErrInvalidPrevTx {
+		t.Fatalf("expected %v, got %v", ErrInvalidPrevTx, err)
+	}
+	overstated := types.NewTxOutput(types.Amount{Value: 200000000, Id: types.MEERA}, pkScript)
+	if err := p.AddInPrevOut(0, overstated); err != ErrInvalidPrevOut {
+		t.Fatalf("expected %v, got %v", ErrInvalidPrevOut, err)
+	}
+
+	// The previous transaction is checked when the packet is decoded.
+	p.Inputs[0].PrevOut = overstated
+	b64, err := p.B64Encode()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := NewFromBase64(b64); err == nil {
+		t.Fatalf("expected error of the overstated previous output")
+	}
+
+	// A previous output without its transaction isn't signed nor counted
+	// by the fee until it is verified.
+	p.Inputs[0].PrevTx = nil
+	if _, err := p.Sign(0, keys[0]); err != ErrUnverifiedPrevOut {
+		t.Fatalf("expected %v, got %v", ErrUnverifiedPrevOut, err)
+	}
+	if _, err := p.Fee(); err != ErrUnverifiedPrevOut {
+		t.Fatalf("expected %v, got %v", ErrUnverifiedPrevOut, err)
+	}
+	if err := p.VerifyInPrevOut(0, prevTx.TxOut[1]); err != ErrInvalidPrevOut {
+		t.Fatalf("expected %v, got %v", ErrInvalidPrevOut, err)
+	}
+	p.Inputs[0].PrevOut = prevTx.TxOut[1]
+	if err := p.VerifyInPrevOut(0, prevTx.TxOut[1]); err != nil {
+		t.Fatal(err)
+	}
+	if ok, err := p.Sign(0, keys[0]); err != nil || !ok {
+		t.Fatalf("expected the verified input to be signed:%v", err)
+	}
+	fees, err := p.Fee()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if fees[types.MEERA] != 10000000 {
+		t.Fatalf("expected fee 10000000, got %d", fees[types.MEERA])
+	}
+}
+
+func TestFinalizeInvalidSig(t *testing.T) {
+	keys := testKeys(t, 3)
+	p, redeemScript := createMultiSigPacket(t, keys)
+	err := p.AddInRedeemScript(0, redeemScript)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, key := range keys[:2] {
+		if ok, err := p.Sign(0, key); err != nil || !ok {
+			t.Fatalf("expected the key to sign:%v", err)
+		}
+	}
+
+	// A signature by another key under the public key of a signer.
+	forged := *p.Inputs[0].PartialSigs[1]
+	p.Inputs[0].PartialSigs[1] = &PartialSig{PubKey: forged.PubKey, Signature: p.Inputs[0].PartialSigs[0].Signature}
+	err = p.Finalize(0)
+	if err == nil || !strings.Contains(err.Error(), ErrInvalidPartialSig.Error()) {
+		t.Fatalf("expected %v, got %v", ErrInvalidPartialSig, err)
+	}
+	if p.Inputs[0].IsFinalized() {
+		t.Fatalf("expected the input not to be finalized")
+	}
+
+	// A signature of another sighash type than the one of the input.
+	p.Inputs[0].PartialSigs[1] = &forged
+	p.Inputs[0].SighashType = txscript.SigHashSingle
+	err = p.Finalize(0)
+	if err == nil || !strings.Contains(err.Error(), ErrInvalidPartialSig.Error()) {
+		t.Fatalf("expected %v, got %v", ErrInvalidPartialSig, err)
+	}
+	p.Inputs[0].SighashType = 0
+	if err := p.Finalize(0); err != nil {
+		t.Fatal(err)
+	}
+}
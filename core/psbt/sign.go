@@ -0,0 +1,333 @@
+// Copyright (c) 2017-2018 The qitmeer developers
+
+package psbt
+
+import (
+	"bytes"
+	"fmt"
+	"github.com/Qitmeer/qng/common/hash"
+	"github.com/Qitmeer/qng/core/types"
+	"github.com/Qitmeer/qng/crypto/ecc"
+	"github.com/Qitmeer/qng/engine/txscript"
+)
+
+// AddInPrevOut sets the output spent by the input. It must be verified by
+// VerifyInPrevOut before the input is signed, unless the previous transaction
+// is added too.
+func (p *Packet) AddInPrevOut(index int, prevOut *types.TxOutput) error {
+	err := p.checkInput(index)
+	if err != nil {
+		return err
+	}
+	pi := &p.Inputs[index]
+	if pi.PrevOut != nil && !samePrevOut(pi.PrevOut, prevOut) {
+		if pi.PrevTx != nil {
+			return ErrInvalidPrevOut
+		}
+		pi.prevOutChecked = false
+	}
+	pi.PrevOut = prevOut
+	return pi.checkRedeemScript()
+}
+
+// AddInPrevTx sets the transaction of the output spent by the input, which
+// sets and proves the previous output of the input.
+func (p *Packet) AddInPrevTx(index int, prevTx *types.Transaction) error {
+	err := p.checkInput(index)
+	if err != nil {
+		return err
+	}
+	op := &p.UnsignedTx.TxIn[index].PreviousOut
+	if prevTx.TxHash() != op.Hash || op.OutIndex >= uint32(len(prevTx.TxOut)) {
+		return ErrInvalidPrevTx
+	}
+	pi := &p.Inputs[index]
+	prevOut := prevTx.TxOut[op.OutIndex]
+	if pi.PrevOut != nil && !samePrevOut(pi.PrevOut, prevOut) {
+		return ErrInvalidPrevOut
+	}
+	pi.PrevTx = prevTx
+	pi.PrevOut = prevOut
+	return pi.checkRedeemScript()
+}
+
+// VerifyInPrevOut checks the previous output of the input against the output
+// of the UTXO set, which lets the input be signed without its previous
+// transaction.
+func (p *Packet) VerifyInPrevOut(index int, utxo *types.TxOutput) error {
+	err := p.checkInput(index)
+	if err != nil {
+		return err
+	}
+	pi := &p.Inputs[index]
+	if pi.PrevOut == nil {
+		return ErrNoPrevOut
+	}
+	if !samePrevOut(pi.PrevOut, utxo) {
+		return ErrInvalidPrevOut
+	}
+	pi.prevOutChecked = true
+	return nil
+}
+
+// AddInRedeemScript sets the redeem script of the pay-to-script-hash input.
+func (p *Packet) AddInRedeemScript(index int, redeemScript []byte) error {
+	err := p.checkInput(index)
+	if err != nil {
+		return err
+	}
+	p.Inputs[index].RedeemScript = redeemScript
+	err = p.Inputs[index].checkRedeemScript()
+	if err != nil {
+		p.Inputs[index].RedeemScript = nil
+		return err
+	}
+	return nil
+}
+
+// AddInSighashType sets the sighash type which the signers of the input use.
+func (p *Packet) AddInSighashType(index int, hashType txscript.SigHashType) error {
+	err := p.checkInput(index)
+	if err != nil {
+		return err
+	}
+	p.Inputs[index].SighashType = hashType
+	return nil
+}
+
+// AddInBip32Derivation adds the derivation hint of a key of the input.
+func (p *Packet) AddInBip32Derivation(index int, d *Bip32Derivation) error {
+	err := p.checkInput(index)
+	if err != nil {
+		return err
+	}
+	p.Inputs[index].addBip32Derivation(d)
+	return nil
+}
+
+// AddOutRedeemScript sets the redeem script of the pay-to-script-hash output.
+func (p *Packet) AddOutRedeemScript(index int, redeemScript []byte) error {
+	err := p.checkOutput(index)
+	if err != nil {
+		return err
+	}
+	p.Outputs[index].RedeemScript = redeemScript
+	return nil
+}
+
+// AddOutBip32Derivation adds the derivation hint of a key of the output.
+func (p *Packet) AddOutBip32Derivation(index int, d *Bip32Derivation) error {
+	err := p.checkOutput(index)
+	if err != nil {
+		return err
+	}
+	for _, old := range p.Outputs[index].Bip32Derivation {
+		if bytes.Equal(old.PubKey, d.PubKey) {
+			return nil
+		}
+	}
+	p.Outputs[index].Bip32Derivation = append(p.Outputs[index].Bip32Derivation, d)
+	return nil
+}
+
+// Sign adds the partial signature of the key to the input. It returns false
+// when the key does not belong to the script of the input or the input is
+// already finalized, and an error when the previous output of the input
+// isn't verified.
+func (p *Packet) Sign(index int, key ecc.PrivateKey) (bool, error) {
+	err := p.checkInput(index)
+	if err != nil {
+		return false, err
+	}
+	pi := &p.Inputs[index]
+	if pi.IsFinalized() {
+		return false, nil
+	}
+	subScript, err := pi.subScript()
+	if err != nil {
+		return false, err
+	}
+	pubKey := matchKey(subScript, key)
+	if pubKey == nil {
+		return false, nil
+	}
+	if !pi.IsPrevOutVerified() {
+		return false, ErrUnverifiedPrevOut
+	}
+	hashType := pi.SighashType
+	if hashType == 0 {
+		hashType = txscript.SigHashAll
+	}
+	sig, err := txscript.RawTxInSignature(p.UnsignedTx, index, subScript, hashType, key)
+	if err != nil {
+		return false, err
+	}
+	pi.addPartialSig(&PartialSig{PubKey: pubKey, Signature: sig})
+	return true, nil
+}
+
+// matchKey returns the serialized public key of the private key which the
+// script pays to, by the key itself or by its hash.
+func matchKey(script []byte, key ecc.PrivateKey) []byte {
+	pushes, err := txscript.PushedData(script)
+	if err != nil {
+		return nil
+	}
+	_, pub := ecc.Secp256k1.PrivKeyFromBytes(key.Serialize())
+	for _, pubKey := range [][]byte{pub.SerializeCompressed(), pub.SerializeUncompressed()} {
+		pkh := hash.Hash160(pubKey)
+		for _, data := range pushes {
+			if bytes.Equal(data, pubKey) || bytes.Equal(data, pkh) {
+				return pubKey
+			}
+		}
+	}
+	return nil
+}
+
+// verifyPartialSig checks the partial signature of the input is a valid
+// signature of the unsigned transaction by its public key.
+func (p *Packet) verifyPartialSig(index int, subScript []byte, ps *PartialSig) error {
+	pi := &p.Inputs[index]
+	if len(ps.Signature) < 1 {
+		return ErrInvalidPartialSig
+	}
+	hashType := txscript.SigHashType(ps.Signature[len(ps.Signature)-1])
+	if pi.SighashType != 0 && hashType != pi.SighashType {
+		return fmt.Errorf("%v: sighash type %s, want %s", ErrInvalidPartialSig,
+			SighashTypeString(hashType), SighashTypeString(pi.SighashType))
+	}
+	sig, err := ecc.Secp256k1.ParseDERSignature(ps.Signature[:len(ps.Signature)-1])
+	if err != nil {
+		return fmt.Errorf("%v: %v", ErrInvalidPartialSig, err)
+	}
+	pubKey, err := ecc.Secp256k1.ParsePubKey(ps.PubKey)
+	if err != nil {
+		return fmt.Errorf("%v: %v", ErrInvalidPartialSig, err)
+	}
+	h, err := txscript.CalcSignatureHash(subScript, hashType, p.UnsignedTx, index, nil)
+	if err != nil {
+		return err
+	}
+	if !ecc.Secp256k1.Verify(pubKey, h, sig.GetR(), sig.GetS()) {
+		return fmt.Errorf("%v: public key %x", ErrInvalidPartialSig, ps.PubKey)
+	}
+	return nil
+}
+
+// Finalize checks the partial signatures of the input and builds its sign
+// script from them, then drops the signing data which is no longer needed.
+func (p *Packet) Finalize(index int) error {
+	err := p.checkInput(index)
+	if err != nil {
+		return err
+	}
+	pi := &p.Inputs[index]
+	if pi.IsFinalized() {
+		return nil
+	}
+	subScript, err := pi.subScript()
+	if err != nil {
+		return err
+	}
+	for _, ps := range pi.PartialSigs {
+		err = p.verifyPartialSig(index, subScript, ps)
+		if err != nil {
+			return fmt.Errorf("input %d: %v", index, err)
+		}
+	}
+	builder := txscript.NewScriptBuilder()
+	class := txscript.GetScriptClass(txscript.DefaultScriptVersion, subScript)
+	switch class {
+	case txscript.PubKeyTy:
+		if len(pi.PartialSigs) != 1 {
+			return fmt.Errorf("input %d needs one signature", index)
+		}
+		builder.AddData(pi.PartialSigs[0].Signature)
+	case txscript.PubKeyHashTy, txscript.CLTVPubKeyHashTy, txscript.TokenPubKeyHashTy:
+		if len(pi.PartialSigs) != 1 {
+			return fmt.Errorf("input %d needs one signature", index)
+		}
+		builder.AddData(pi.PartialSigs[0].Signature).AddData(pi.PartialSigs[0].PubKey)
+	case txscript.MultiSigTy:
+		_, nRequired, err := txscript.CalcMultiSigStats(subScript)
+		if err != nil {
+			return err
+		}
+		pushes, err := txscript.PushedData(subScript)
+		if err != nil {
+			return err
+		}
+		// The signatures must be in the order of the public keys of the
+		// script.
+		sigs := 0
+		for _, pubKey := range pushes {
+			if sigs >= nRequired {
+				break
+			}
+			for _, ps := range pi.PartialSigs {
+				if bytes.Equal(ps.PubKey, pubKey) {
+					builder.AddData(ps.Signature)
+					sigs++
+					break
+				}
+			}
+		}
+		if sigs < nRequired {
+			return fmt.Errorf("input %d needs %d signatures, has %d", index, nRequired, sigs)
+		}
+	default:
+		return fmt.Errorf("input %d: %v:%s", index, ErrUnsupportedScript, class)
+	}
+	if txscript.IsPayToScriptHash(pi.PrevOut.PkScript) {
+		builder.AddData(pi.RedeemScript)
+	}
+	script, err := builder.Script()
+	if err != nil {
+		return err
+	}
+	pi.FinalScriptSig = script
+	pi.PartialSigs = nil
+	pi.SighashType = 0
+	pi.RedeemScript = nil
+	pi.Bip32Derivation = nil
+	return nil
+}
+
+// MaybeFinalizeAll finalizes the inputs which have enough signatures and
+// returns whether the packet is complete.
+func (p *Packet) MaybeFinalizeAll() bool {
+	for i := range p.Inputs {
+		p.Finalize(i)
+	}
+	return p.IsComplete()
+}
+
+var sighashNames = map[txscript.SigHashType]string{
+	txscript.SigHashAll:                                   "ALL",
+	txscript.SigHashNone:                                  "NONE",
+	txscript.SigHashSingle:                                "SINGLE",
+	txscript.SigHashAll | txscript.SigHashAnyOneCanPay:    "ALL|ANYONECANPAY",
+	txscript.SigHashNone | txscript.SigHashAnyOneCanPay:   "NONE|ANYONECANPAY",
+	txscript.SigHashSingle | txscript.SigHashAnyOneCanPay: "SINGLE|ANYONECANPAY",
+}
+
+// SighashTypeString returns the name of the sighash type.
+func SighashTypeString(hashType txscript.SigHashType) string {
+	name, ok := sighashNames[hashType]
+	if !ok {
+		return fmt.Sprintf("0x%x", byte(hashType))
+	}
+	return name
+}
+
+// ParseSighashType returns the sighash type of the name, like
+// "ALL|ANYONECANPAY".
+func ParseSighashType(name string) (txscript.SigHashType, error) {
+	for hashType, n := range sighashNames {
+		if n == name {
+			return hashType, nil
+		}
+	}
+	return 0, fmt.Errorf("Invalid sighash type:%s", name)
+}
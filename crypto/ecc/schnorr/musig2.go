@@ -0,0 +1,412 @@
+// Copyright (c) 2017-2020 The qitmeer developers
+// Use of this source code is governed by an ISC
+// license that can be found in the LICENSE file.
+
+package schnorr
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+	"math/big"
+	"sort"
+
+	chainhash "github.com/Qitmeer/qng/common/hash"
+	"github.com/Qitmeer/qng/crypto/ecc/secp256k1"
+)
+
+// MuSig2 is the two-round Schnorr multi-signature scheme of Nick, Ruffing and
+// Seurin, adapted to the Schnorr signatures of this package, which verify
+// s*G + h*Q == R with h = BLAKE256(R.x || m) and an even R.y.  The n-of-n
+// signers aggregate their keys into a single key Q, exchange two public
+// nonces each, and combine their partial signatures into a signature which is
+// indistinguishable from a single signer's one.
+//
+// The secret nonces must never be reused, a secret nonce which signs two
+// different messages leaks the private key.  MuSig2PartialSign zeroes the
+// secret nonce after it signs.
+
+const (
+	// MuSig2SecNonceSize is the size of a secret nonce, two scalars.
+	MuSig2SecNonceSize = 2 * scalarSize
+
+	// MuSig2PubNonceSize is the size of a public nonce, two compressed
+	// points.
+	MuSig2PubNonceSize = 2 * PubKeyBytesLen
+
+	// MuSig2PartialSigSize is the size of a partial signature.
+	MuSig2PartialSigSize = scalarSize
+)
+
+var (
+	muSig2KeyAggListTag  = []byte("MuSig2/KeyAgg list")
+	muSig2KeyAggCoeffTag = []byte("MuSig2/KeyAgg coefficient")
+	muSig2NonceTag       = []byte("MuSig2/nonce")
+	muSig2NonceCoeffTag  = []byte("MuSig2/noncecoef")
+)
+
+// taggedHash returns the BLAKE256 hash of the data, which is domain separated
+// by the tag.
+func taggedHash(tag []byte, data ...[]byte) []byte {
+	tagHash := chainhash.HashB(tag)
+	buf := make([]byte, 0, 2*len(tagHash))
+	buf = append(buf, tagHash...)
+	buf = append(buf, tagHash...)
+	for _, d := range data {
+		buf = append(buf, d...)
+	}
+	return chainhash.HashB(buf)
+}
+
+// hashToScalar returns the hash reduced by the order of the curve.
+func hashToScalar(h []byte) *big.Int {
+	n := new(big.Int).SetBytes(h)
+	return n.Mod(n, secp256k1.S256().N)
+}
+
+// SortMuSig2PubKeys sorts the public keys by their compressed serialization,
+// which is the order the keys are aggregated in.
+func SortMuSig2PubKeys(pks []*secp256k1.PublicKey) []*secp256k1.PublicKey {
+	sorted := make([]*secp256k1.PublicKey, len(pks))
+	copy(sorted, pks)
+	sort.Slice(sorted, func(i, j int) bool {
+		return bytes.Compare(sorted[i].SerializeCompressed(),
+			sorted[j].SerializeCompressed()) < 0
+	})
+	return sorted
+}
+
+// muSig2KeyAggCoeffs returns the sorted public keys and their coefficients,
+// a_i = H(L || P_i), where L commits to all the keys.
+func muSig2KeyAggCoeffs(pks []*secp256k1.PublicKey) ([]*secp256k1.PublicKey,
+	[]*big.Int, error) {
+	if len(pks) < 1 {
+		return nil, nil, schnorrError(ErrInputValue, "no public keys to aggregate")
+	}
+	curve := secp256k1.S256()
+	sorted := SortMuSig2PubKeys(pks)
+	list := make([]byte, 0, len(sorted)*PubKeyBytesLen)
+	for i, pk := range sorted {
+		if pk == nil || !curve.IsOnCurve(pk.GetX(), pk.GetY()) {
+			str := fmt.Sprintf("public key %v is off curve", i)
+			return nil, nil, schnorrError(ErrPointNotOnCurve, str)
+		}
+		list = append(list, pk.SerializeCompressed()...)
+	}
+	l := taggedHash(muSig2KeyAggListTag, list)
+
+	coeffs := make([]*big.Int, len(sorted))
+	for i, pk := range sorted {
+		coeffs[i] = hashToScalar(taggedHash(muSig2KeyAggCoeffTag, l,
+			pk.SerializeCompressed()))
+	}
+	return sorted, coeffs, nil
+}
+
+// MuSig2AggregatePubKeys aggregates the public keys of the signers into the
+// single public key Q = sum(a_i*P_i) which their combined signature verifies
+// for.  The keys are aggregated in the sorted order, so the order they are
+// given in doesn't matter.
+func MuSig2AggregatePubKeys(pks []*secp256k1.PublicKey) (*secp256k1.PublicKey,
+	error) {
+	sorted, coeffs, err := muSig2KeyAggCoeffs(pks)
+	if err != nil {
+		return nil, err
+	}
+	curve := secp256k1.S256()
+	var qx, qy *big.Int
+	for i, pk := range sorted {
+		x, y := curve.ScalarMult(pk.GetX(), pk.GetY(), coeffs[i].Bytes())
+		if qx == nil {
+			qx, qy = x, y
+			continue
+		}
+		qx, qy = curve.Add(qx, qy, x, y)
+	}
+	if qx.Sign() == 0 && qy.Sign() == 0 {
+		return nil, schnorrError(ErrInputValue, "aggregated public key is infinity")
+	}
+	return secp256k1.NewPublicKey(qx, qy), nil
+}
+
+// muSig2Coeff returns the coefficient of the public key of a signer.
+func muSig2Coeff(pks []*secp256k1.PublicKey,
+	pk *secp256k1.PublicKey) (*big.Int, error) {
+	sorted, coeffs, err := muSig2KeyAggCoeffs(pks)
+	if err != nil {
+		return nil, err
+	}
+	for i, p := range sorted {
+		if p.IsEqual(pk) {
+			return coeffs[i], nil
+		}
+	}
+	return nil, schnorrError(ErrInputValue, "public key is not one of the signers")
+}
+
+// MuSig2GenerateNonce generates the secret nonce and the public nonce of a
+// signer for one signing session.  The nonces are derived from the randomness
+// of rand, the private key and the message, so a faulty random source alone
+// doesn't repeat them.  The message may be nil when it isn't known yet.
+func MuSig2GenerateNonce(rand io.Reader, priv *secp256k1.PrivateKey,
+	msg []byte) ([]byte, []byte, error) {
+	rnd := make([]byte, scalarSize)
+	if _, err := io.ReadFull(rand, rnd); err != nil {
+		return nil, nil, err
+	}
+	privBytes := priv.Serialize()
+	defer zeroSlice(privBytes)
+
+	curve := secp256k1.S256()
+	secNonce := make([]byte, 0, MuSig2SecNonceSize)
+	pubNonce := make([]byte, 0, MuSig2PubNonceSize)
+	for i := byte(0); i < 2; i++ {
+		k := hashToScalar(taggedHash(muSig2NonceTag, rnd, privBytes, msg, []byte{i}))
+		if k.Sign() == 0 {
+			return nil, nil, schnorrError(ErrBadNonce, "k scalar is zero")
+		}
+		kB := BigIntToEncodedBytes(k)
+		k.SetInt64(0)
+		rx, ry := curve.ScalarBaseMult(kB[:])
+		secNonce = append(secNonce, kB[:]...)
+		zeroArray(kB)
+		pubNonce = append(pubNonce, secp256k1.NewPublicKey(rx, ry).SerializeCompressed()...)
+	}
+	return secNonce, pubNonce, nil
+}
+
+// parseMuSig2Nonce parses the two points of a public or aggregated nonce.
+func parseMuSig2Nonce(nonce []byte) (*secp256k1.PublicKey, *secp256k1.PublicKey,
+	error) {
+	if len(nonce) != MuSig2PubNonceSize {
+		str := fmt.Sprintf("wrong size for nonce (got %v, want %v)",
+			len(nonce), MuSig2PubNonceSize)
+		return nil, nil, schnorrError(ErrBadInputSize, str)
+	}
+	curve := secp256k1.S256()
+	r1, err := ParsePubKey(curve, nonce[:PubKeyBytesLen])
+	if err != nil {
+		return nil, nil, schnorrError(ErrBadNonce, err.Error())
+	}
+	r2, err := ParsePubKey(curve, nonce[PubKeyBytesLen:])
+	if err != nil {
+		return nil, nil, schnorrError(ErrBadNonce, err.Error())
+	}
+	return r1, r2, nil
+}
+
+// MuSig2AggregateNonces aggregates the public nonces of all the signers into
+// the nonce of the signing session, which every signer signs with.
+func MuSig2AggregateNonces(pubNonces [][]byte) ([]byte, error) {
+	if len(pubNonces) < 1 {
+		return nil, schnorrError(ErrInputValue, "no nonces to aggregate")
+	}
+	curve := secp256k1.S256()
+	var r1x, r1y, r2x, r2y *big.Int
+	for i, pubNonce := range pubNonces {
+		r1, r2, err := parseMuSig2Nonce(pubNonce)
+		if err != nil {
+			return nil, fmt.Errorf("nonce %v: %v", i, err)
+		}
+		if i == 0 {
+			r1x, r1y, r2x, r2y = r1.GetX(), r1.GetY(), r2.GetX(), r2.GetY()
+			continue
+		}
+		r1x, r1y = curve.Add(r1x, r1y, r1.GetX(), r1.GetY())
+		r2x, r2y = curve.Add(r2x, r2y, r2.GetX(), r2.GetY())
+	}
+	if (r1x.Sign() == 0 && r1y.Sign() == 0) || (r2x.Sign() == 0 && r2y.Sign() == 0) {
+		return nil, schnorrError(ErrBadNonce, "aggregated nonce is infinity")
+	}
+	aggNonce := make([]byte, 0, MuSig2PubNonceSize)
+	aggNonce = append(aggNonce, secp256k1.NewPublicKey(r1x, r1y).SerializeCompressed()...)
+	aggNonce = append(aggNonce, secp256k1.NewPublicKey(r2x, r2y).SerializeCompressed()...)
+	return aggNonce, nil
+}
+
+// muSig2Session holds the values of a signing session which every signer
+// derives from the public keys, the aggregated nonce and the message.
+type muSig2Session struct {
+	pks    []*secp256k1.PublicKey
+	aggKey *secp256k1.PublicKey
+
+	// b is the coefficient of the second nonces.
+	b *big.Int
+
+	// r is the x coordinate of the final nonce R = R1 + b*R2.
+	r *big.Int
+
+	// negate is whether R has an odd y, so the nonces are negated.
+	negate bool
+
+	// h is the challenge H(R.x || m).
+	h *big.Int
+}
+
+func newMuSig2Session(pks []*secp256k1.PublicKey, aggNonce []byte,
+	msg []byte) (*muSig2Session, error) {
+	if len(msg) != scalarSize {
+		str := fmt.Sprintf("wrong size for message (got %v, want %v)",
+			len(msg), scalarSize)
+		return nil, schnorrError(ErrBadInputSize, str)
+	}
+	aggKey, err := MuSig2AggregatePubKeys(pks)
+	if err != nil {
+		return nil, err
+	}
+	r1, r2, err := parseMuSig2Nonce(aggNonce)
+	if err != nil {
+		return nil, err
+	}
+	curve := secp256k1.S256()
+	b := hashToScalar(taggedHash(muSig2NonceCoeffTag, aggNonce,
+		aggKey.SerializeCompressed(), msg))
+	bx, by := curve.ScalarMult(r2.GetX(), r2.GetY(), b.Bytes())
+	rx, ry := curve.Add(r1.GetX(), r1.GetY(), bx, by)
+	if rx.Sign() == 0 && ry.Sign() == 0 {
+		return nil, schnorrError(ErrBadNonce, "final nonce is infinity")
+	}
+
+	// h = Hash(r || m), the same challenge as a single signer's.
+	rB := BigIntToEncodedBytes(rx)
+	h := new(big.Int).SetBytes(chainhash.HashB(append(rB[:], msg...)))
+	if h.Cmp(curve.N) >= 0 || h.Sign() == 0 {
+		return nil, schnorrError(ErrSchnorrHashValue, "hash of (R || m) out of range")
+	}
+	return &muSig2Session{
+		pks:    pks,
+		aggKey: aggKey,
+		b:      b,
+		r:      rx,
+		negate: ry.Bit(0) == 1,
+		h:      h,
+	}, nil
+}
+
+// MuSig2PartialSign creates the partial signature of a signer of the message
+// with the aggregated nonce, s_i = k_i - h*a_i*x_i.  The secret nonce is
+// zeroed, so it can never sign again.
+func MuSig2PartialSign(secNonce []byte, priv *secp256k1.PrivateKey,
+	pks []*secp256k1.PublicKey, aggNonce []byte, msg []byte) ([]byte, error) {
+	if len(secNonce) != MuSig2SecNonceSize {
+		str := fmt.Sprintf("wrong size for secret nonce (got %v, want %v)",
+			len(secNonce), MuSig2SecNonceSize)
+		return nil, schnorrError(ErrBadInputSize, str)
+	}
+	curve := secp256k1.S256()
+	k1 := new(big.Int).SetBytes(secNonce[:scalarSize])
+	k2 := new(big.Int).SetBytes(secNonce[scalarSize:])
+	zeroSlice(secNonce[:scalarSize])
+	zeroSlice(secNonce[scalarSize:])
+	defer k1.SetInt64(0)
+	defer k2.SetInt64(0)
+	if k1.Sign() == 0 || k2.Sign() == 0 {
+		return nil, schnorrError(ErrBadNonce, "secret nonce is zero or was used")
+	}
+	if k1.Cmp(curve.N) >= 0 || k2.Cmp(curve.N) >= 0 {
+		return nil, schnorrError(ErrBadNonce, "secret nonce is out of bounds")
+	}
+
+	session, err := newMuSig2Session(pks, aggNonce, msg)
+	if err != nil {
+		return nil, err
+	}
+	pub := priv.PubKey()
+	a, err := muSig2Coeff(pks, pub)
+	if err != nil {
+		return nil, err
+	}
+
+	// k = k1 + b*k2, negated when R has an odd y.
+	k := new(big.Int).Mul(session.b, k2)
+	k.Add(k, k1)
+	k.Mod(k, curve.N)
+	defer k.SetInt64(0)
+	if session.negate {
+		k.Sub(curve.N, k)
+	}
+
+	// s = k - h*a*x
+	s := new(big.Int).Mul(session.h, a)
+	s.Mul(s, priv.GetD())
+	s.Sub(k, s)
+	s.Mod(s, curve.N)
+	sB := BigIntToEncodedBytes(s)
+	return sB[:], nil
+}
+
+// MuSig2PartialVerify verifies the partial signature of the signer of the
+// public key and the public nonce, s_i*G + h*a_i*P_i == R_i.
+func MuSig2PartialVerify(partialSig []byte, pubNonce []byte,
+	pk *secp256k1.PublicKey, pks []*secp256k1.PublicKey, aggNonce []byte,
+	msg []byte) error {
+	if len(partialSig) != MuSig2PartialSigSize {
+		str := fmt.Sprintf("wrong size for partial signature (got %v, want %v)",
+			len(partialSig), MuSig2PartialSigSize)
+		return schnorrError(ErrBadInputSize, str)
+	}
+	curve := secp256k1.S256()
+	s := new(big.Int).SetBytes(partialSig)
+	if s.Cmp(curve.N) >= 0 {
+		return schnorrError(ErrInputValue, "partial signature is out of bounds")
+	}
+	session, err := newMuSig2Session(pks, aggNonce, msg)
+	if err != nil {
+		return err
+	}
+	a, err := muSig2Coeff(pks, pk)
+	if err != nil {
+		return err
+	}
+	r1, r2, err := parseMuSig2Nonce(pubNonce)
+	if err != nil {
+		return err
+	}
+
+	// R_i = R1_i + b*R2_i, negated when R has an odd y.
+	bx, by := curve.ScalarMult(r2.GetX(), r2.GetY(), session.b.Bytes())
+	rx, ry := curve.Add(r1.GetX(), r1.GetY(), bx, by)
+	if session.negate {
+		ry = new(big.Int).Sub(curve.P, ry)
+	}
+
+	ha := new(big.Int).Mul(session.h, a)
+	ha.Mod(ha, curve.N)
+	lx, ly := curve.ScalarMult(pk.GetX(), pk.GetY(), ha.Bytes())
+	sx, sy := curve.ScalarBaseMult(partialSig)
+	vx, vy := curve.Add(lx, ly, sx, sy)
+	if vx.Cmp(rx) != 0 || vy.Cmp(ry) != 0 {
+		return schnorrError(ErrUnequalRValues, "partial signature is invalid")
+	}
+	return nil
+}
+
+// MuSig2CombineSigs combines the partial signatures of all the signers into
+// the signature of the aggregated public key, which is verified before it is
+// returned.
+func MuSig2CombineSigs(partialSigs [][]byte, pks []*secp256k1.PublicKey,
+	aggNonce []byte, msg []byte) (*Signature, error) {
+	session, err := newMuSig2Session(pks, aggNonce, msg)
+	if err != nil {
+		return nil, err
+	}
+	sigss := make([][]byte, len(partialSigs))
+	for i, partialSig := range partialSigs {
+		if len(partialSig) != MuSig2PartialSigSize {
+			str := fmt.Sprintf("wrong size for partial signature %v "+
+				"(got %v, want %v)", i, len(partialSig), MuSig2PartialSigSize)
+			return nil, schnorrError(ErrBadInputSize, str)
+		}
+		sigss[i] = partialSig
+	}
+	s, err := schnorrCombineSigs(secp256k1.S256(), sigss)
+	if err != nil {
+		return nil, err
+	}
+	sig := NewSignature(session.r, s)
+	if !Verify(session.aggKey, msg, sig.R, sig.S) {
+		return nil, schnorrError(ErrUnequalRValues,
+			"combined signature is invalid for the aggregated public key")
+	}
+	return sig, nil
+}
@@ -0,0 +1,111 @@
+// Copyright (c) 2017-2020 The qitmeer developers
+// Use of this source code is governed by an ISC
+// license that can be found in the LICENSE file.
+
+package schnorr
+
+import (
+	"crypto/rand"
+	"testing"
+
+	chainhash "github.com/Qitmeer/qng/common/hash"
+	"github.com/Qitmeer/qng/crypto/ecc/secp256k1"
+)
+
+func TestMuSig2(t *testing.T) {
+	for _, n := range []int{1, 2, 3, 5} {
+		privs := make([]*secp256k1.PrivateKey, n)
+		pks := make([]*secp256k1.PublicKey, n)
+		for i := range privs {
+			priv, err := secp256k1.GeneratePrivateKey()
+			if err != nil {
+				t.Fatal(err)
+			}
+			privs[i] = priv
+			pks[i] = priv.PubKey()
+		}
+		aggKey, err := MuSig2AggregatePubKeys(pks)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		// The order of the keys doesn't matter.
+		reversed := make([]*secp256k1.PublicKey, n)
+		for i := range pks {
+			reversed[n-1-i] = pks[i]
+		}
+		aggKey2, err := MuSig2AggregatePubKeys(reversed)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if !aggKey.IsEqual(aggKey2) {
+			t.Fatalf("n=%d: the aggregated key depends on the order of the keys", n)
+		}
+
+		for round := 0; round < 8; round++ {
+			msg := chainhash.HashB([]byte{byte(n), byte(round)})
+			secNonces := make([][]byte, n)
+			pubNonces := make([][]byte, n)
+			for i, priv := range privs {
+				secNonces[i], pubNonces[i], err = MuSig2GenerateNonce(rand.Reader, priv, msg)
+				if err != nil {
+					t.Fatal(err)
+				}
+			}
+			aggNonce, err := MuSig2AggregateNonces(pubNonces)
+			if err != nil {
+				t.Fatal(err)
+			}
+			partialSigs := make([][]byte, n)
+			for i, priv := range privs {
+				partialSigs[i], err = MuSig2PartialSign(secNonces[i], priv, pks, aggNonce, msg)
+				if err != nil {
+					t.Fatal(err)
+				}
+				err = MuSig2PartialVerify(partialSigs[i], pubNonces[i], pks[i], pks, aggNonce, msg)
+				if err != nil {
+					t.Fatalf("n=%d: partial signature %d: %v", n, i, err)
+				}
+			}
+
+			// A used secret nonce can't sign again.
+			_, err = MuSig2PartialSign(secNonces[0], privs[0], pks, aggNonce, msg)
+			if err == nil {
+				t.Fatalf("n=%d: expected a used secret nonce to fail", n)
+			}
+
+			sig, err := MuSig2CombineSigs(partialSigs, pks, aggNonce, msg)
+			if err != nil {
+				t.Fatalf("n=%d: %v", n, err)
+			}
+			if !Verify(aggKey, msg, sig.R, sig.S) {
+				t.Fatalf("n=%d: the combined signature doesn't verify", n)
+			}
+
+			// A tampered partial signature is detected.
+			bad := append([]byte{}, partialSigs[n-1]...)
+			bad[31] ^= 0x01
+			err = MuSig2PartialVerify(bad, pubNonces[n-1], pks[n-1], pks, aggNonce, msg)
+			if err == nil {
+				t.Fatalf("n=%d: expected the tampered partial signature to fail", n)
+			}
+			partialSigs[n-1] = bad
+			if _, err := MuSig2CombineSigs(partialSigs, pks, aggNonce, msg); err == nil {
+				t.Fatalf("n=%d: expected the combined signature to fail", n)
+			}
+		}
+	}
+
+	// The public nonces are two compressed points.
+	priv, _ := secp256k1.GeneratePrivateKey()
+	_, pubNonce, err := MuSig2GenerateNonce(rand.Reader, priv, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(pubNonce) != MuSig2PubNonceSize {
+		t.Fatalf("unexpected size of the public nonce %d", len(pubNonce))
+	}
+	if _, err := MuSig2AggregateNonces([][]byte{pubNonce[:PubKeyBytesLen]}); err == nil {
+		t.Fatalf("expected an invalid nonce to fail")
+	}
+}
@@ -0,0 +1,522 @@
+// Copyright (c) 2017-2020 The qitmeer developers
+// Use of this source code is governed by an ISC
+// license that can be found in the LICENSE file.
+package txscript
+
+import (
+	"encoding/hex"
+	"fmt"
+	"github.com/Qitmeer/qng/common/hash"
+	"github.com/Qitmeer/qng/core/address"
+	"github.com/Qitmeer/qng/core/types"
+	"github.com/Qitmeer/qng/crypto/bip32"
+	"github.com/Qitmeer/qng/crypto/ecc"
+	"github.com/Qitmeer/qng/params"
+	"strconv"
+	"strings"
+)
+
+// An output script descriptor describes the public key scripts of the
+// standard script classes, optionally a range of them derived from an
+// extended key:
+//
+//	pkh(KEY)                                   PubKeyHashTy
+//	pkhalt(KEY)                                PubkeyHashAltTy, schnorr for a secp256k1 key or ed25519 for a 32 bytes key
+//	multi(K,KEY,...)                           MultiSigTy
+//	sh(SCRIPT)                                 ScriptHashTy of any script above
+//	cltv(LOCKTIME,KEY)                         CLTVPubKeyHashTy
+//	tokenpkh(COINID,UPLIMIT,NAME,FEECFG,KEY)   TokenPubKeyHashTy
+//	addr(ADDRESS)                              the script of the address
+//	raw(HEX)                                   the script as is
+//
+// KEY is a hex public key or an extended key with a derivation path, which
+// ends with /* (or /*' for hardened) for a range, and may be prefixed by its
+// origin [fingerprint/path].  The descriptor may end with #checksum, which is
+// the checksum of BIP-0380.
+
+// MaxDescriptorRange is the maximum number of scripts derived at once from a
+// ranged descriptor.
+const MaxDescriptorRange = 10000
+
+const (
+	descInputCharset    = "0123456789()[],'/*abcdefgh@:$%{}IJKLMNOPQRSTUVWXYZ&+-.;<=>?!^_|~ijklmnopqrstuvwxyzABCDEFGH`#\"\\ "
+	descChecksumCharset = "qpzry9x8gf2tvdw0s3jn54khce6mua7l"
+	descChecksumLen     = 8
+)
+
+// descriptorPolyMod computes the checksum of the symbols.
+func descriptorPolyMod(symbols []uint64) uint64 {
+	generator := []uint64{0xf5dee51989, 0xa9fdca3312, 0x1bab10e32d, 0x3706b1677a, 0x644d626ffd}
+	chk := uint64(1)
+	for _, value := range symbols {
+		top := chk >> 35
+		chk = (chk&0x7ffffffff)<<5 ^ value
+		for i := 0; i < 5; i++ {
+			if (top>>uint(i))&1 == 1 {
+				chk ^= generator[i]
+			}
+		}
+	}
+	return chk
+}
+
+// DescriptorChecksum returns the checksum of the descriptor without its
+// checksum.
+func DescriptorChecksum(desc string) (string, error) {
+	symbols := []uint64{}
+	groups := []uint64{}
+	for _, c := range desc {
+		v := strings.IndexRune(descInputCharset, c)
+		if v < 0 {
+			return "", fmt.Errorf("invalid character %q in descriptor", c)
+		}
+		symbols = append(symbols, uint64(v&31))
+		groups = append(groups, uint64(v>>5))
+		if len(groups) == 3 {
+			symbols = append(symbols, groups[0]*9+groups[1]*3+groups[2])
+			groups = groups[:0]
+		}
+	}
+	switch len(groups) {
+	case 1:
+		symbols = append(symbols, groups[0])
+	case 2:
+		symbols = append(symbols, groups[0]*3+groups[1])
+	}
+	symbols = append(symbols, make([]uint64, descChecksumLen)...)
+	chk := descriptorPolyMod(symbols) ^ 1
+	checksum := make([]byte, descChecksumLen)
+	for i := range checksum {
+		checksum[i] = descChecksumCharset[(chk>>uint(5*(descChecksumLen-1-i)))&31]
+	}
+	return string(checksum), nil
+}
+
+// descKey is a key expression of a descriptor.
+type descKey struct {
+	pubKey   []byte     // the public key, nil for an extended key
+	ext      *bip32.Key // the extended key derived by the path
+	ranged   bool       // the key is derived by the index of the range
+	hardened bool       // the range is hardened
+	private  bool
+}
+
+// parseDescPath parses a step of a derivation path.
+func parseDescPath(step string) (uint32, error) {
+	hardened := strings.HasSuffix(step, "'") || strings.HasSuffix(step, "h")
+	if hardened {
+		step = step[:len(step)-1]
+	}
+	idx, err := strconv.ParseUint(step, 10, 32)
+	if err != nil || uint32(idx) >= bip32.FirstHardenedChild {
+		return 0, fmt.Errorf("invalid derivation path step %s", step)
+	}
+	if hardened {
+		return uint32(idx) + bip32.FirstHardenedChild, nil
+	}
+	return uint32(idx), nil
+}
+
+// parseDescKey parses a key expression, ed25519 tells whether a 32 bytes
+// ed25519 public key is allowed.
+func parseDescKey(expr string, ed25519 bool, net *params.Params) (*descKey, error) {
+	if strings.HasPrefix(expr, "[") {
+		end := strings.Index(expr, "]")
+		if end < 0 {
+			return nil, fmt.Errorf("invalid key origin %s", expr)
+		}
+		expr = expr[end+1:]
+	}
+	if data, err := hex.DecodeString(expr); err == nil {
+		switch {
+		case len(data) == 32 && ed25519:
+			_, err = ecc.Ed25519.ParsePubKey(data)
+		case len(data) == 33:
+			_, err = ecc.Secp256k1.ParsePubKey(data)
+		default:
+			err = fmt.Errorf("invalid public key length %d", len(data))
+		}
+		if err != nil {
+			return nil, err
+		}
+		return &descKey{pubKey: data}, nil
+	}
+
+	steps := strings.Split(expr, "/")
+	versions := []bip32.Bip32Version{
+		{PrivKeyVersion: net.HDPrivateKeyID[:], PubKeyVersion: net.HDPublicKeyID[:]},
+		bip32.DefaultBip32Version,
+	}
+	var ext *bip32.Key
+	var err error
+	for _, version := range versions {
+		ext, err = bip32.B58Deserialize(steps[0], version)
+		if err == nil {
+			break
+		}
+	}
+	if err != nil {
+		return nil, fmt.Errorf("invalid key %s: %v", steps[0], err)
+	}
+	key := &descKey{private: ext.IsPrivate}
+	for i, step := range steps[1:] {
+		if i == len(steps)-2 {
+			switch step {
+			case "*":
+				key.ranged = true
+			case "*'", "*h":
+				key.ranged, key.hardened = true, true
+			}
+			if key.ranged {
+				break
+			}
+		}
+		idx, err := parseDescPath(step)
+		if err != nil {
+			return nil, err
+		}
+		ext, err = ext.NewChildKey(idx)
+		if err != nil {
+			return nil, err
+		}
+	}
+	if key.hardened && !ext.IsPrivate {
+		return nil, bip32.ErrHardnedChildPublicKey
+	}
+	key.ext = ext
+	return key, nil
+}
+
+// derive returns the public key at the index of the range.
+func (k *descKey) derive(index uint32) ([]byte, error) {
+	if k.ext == nil {
+		return k.pubKey, nil
+	}
+	ext := k.ext
+	if k.ranged {
+		if index >= bip32.FirstHardenedChild {
+			return nil, fmt.Errorf("index %d out of range", index)
+		}
+		if k.hardened {
+			index += bip32.FirstHardenedChild
+		}
+		var err error
+		ext, err = ext.NewChildKey(index)
+		if err != nil {
+			return nil, err
+		}
+	}
+	return ext.PublicKey().Key, nil
+}
+
+// descNode is a script expression of a descriptor.
+type descNode struct {
+	fn       string
+	keys     []*descKey
+	sub      *descNode
+	required int
+	lockTime int64
+	coinId   types.CoinID
+	upLimit  uint64
+	name     string
+	feeCfg   int64
+	script   []byte
+}
+
+// splitDescArgs splits the arguments of a script expression by the commas
+// outside of any parentheses or brackets.
+func splitDescArgs(args string) []string {
+	parts := []string{}
+	depth, start := 0, 0
+	for i, c := range args {
+		switch c {
+		case '(', '[':
+			depth++
+		case ')', ']':
+			depth--
+		case ',':
+			if depth == 0 {
+				parts = append(parts, args[start:i])
+				start = i + 1
+			}
+		}
+	}
+	return append(parts, args[start:])
+}
+
+// parseDescNode parses a script expression, ctx is the enclosing function.
+func parseDescNode(expr string, ctx string, net *params.Params) (*descNode, error) {
+	open := strings.Index(expr, "(")
+	if open <= 0 || !strings.HasSuffix(expr, ")") {
+		return nil, fmt.Errorf("invalid script expression %s", expr)
+	}
+	node := &descNode{fn: expr[:open]}
+	args := splitDescArgs(expr[open+1 : len(expr)-1])
+	nargs := map[string]int{"pkh": 1, "pkhalt": 1, "sh": 1, "cltv": 2, "tokenpkh": 5, "addr": 1, "raw": 1}
+	if n, ok := nargs[node.fn]; ok && len(args) != n {
+		return nil, fmt.Errorf("%s() takes %d arguments", node.fn, n)
+	}
+	if ctx != "" && (node.fn == "sh" || node.fn == "addr" || node.fn == "raw") {
+		return nil, fmt.Errorf("%s() can't be inside %s()", node.fn, ctx)
+	}
+
+	var err error
+	key := args[len(args)-1]
+	switch node.fn {
+	case "pkh", "pkhalt", "cltv", "tokenpkh":
+		k, err := parseDescKey(key, node.fn == "pkhalt", net)
+		if err != nil {
+			return nil, err
+		}
+		node.keys = append(node.keys, k)
+	}
+	switch node.fn {
+	case "pkh", "pkhalt":
+	case "multi":
+		if len(args) < 2 {
+			return nil, fmt.Errorf("multi() takes at least 2 arguments")
+		}
+		node.required, err = strconv.Atoi(args[0])
+		if err != nil {
+			return nil, fmt.Errorf("invalid required signatures %s", args[0])
+		}
+		for _, arg := range args[1:] {
+			k, err := parseDescKey(arg, false, net)
+			if err != nil {
+				return nil, err
+			}
+			node.keys = append(node.keys, k)
+		}
+		if node.required < 1 || node.required > len(node.keys) ||
+			len(node.keys) > MaxPubKeysPerMultiSig {
+			return nil, ErrBadNumRequired
+		}
+	case "sh":
+		node.sub, err = parseDescNode(args[0], node.fn, net)
+		if err != nil {
+			return nil, err
+		}
+	case "cltv":
+		node.lockTime, err = strconv.ParseInt(args[0], 10, 64)
+		if err != nil || node.lockTime < 1 || node.lockTime > int64(types.MaxTxInSequenceNum) {
+			return nil, fmt.Errorf("invalid lock time %s", args[0])
+		}
+	case "tokenpkh":
+		coinId, err := strconv.ParseUint(args[0], 10, 16)
+		if err != nil {
+			return nil, fmt.Errorf("invalid coin id %s", args[0])
+		}
+		node.coinId = types.CoinID(coinId)
+		node.upLimit, err = strconv.ParseUint(args[1], 10, 64)
+		if err != nil {
+			return nil, fmt.Errorf("invalid up limit %s", args[1])
+		}
+		node.name = args[2]
+		node.feeCfg, err = strconv.ParseInt(args[3], 10, 64)
+		if err != nil {
+			return nil, fmt.Errorf("invalid fee config %s", args[3])
+		}
+	case "addr":
+		addr, err := address.DecodeAddress(args[0])
+		if err != nil {
+			return nil, err
+		}
+		if !address.IsForNetwork(addr, net) {
+			return nil, fmt.Errorf("the address %s isn't for the network %s", args[0], net.Name)
+		}
+		node.script, err = PayToAddrScript(addr)
+		if err != nil {
+			return nil, err
+		}
+	case "raw":
+		node.script, err = hex.DecodeString(args[0])
+		if err != nil {
+			return nil, err
+		}
+		if _, err := parseScript(node.script); err != nil {
+			return nil, err
+		}
+	default:
+		return nil, fmt.Errorf("unknown script expression %s()", node.fn)
+	}
+	return node, nil
+}
+
+// secpKey returns the derived key, which must be a secp256k1 public key.
+func (n *descNode) secpKey(k *descKey, index uint32) ([]byte, error) {
+	pubKey, err := k.derive(index)
+	if err != nil {
+		return nil, err
+	}
+	if len(pubKey) != 33 {
+		return nil, fmt.Errorf("%s() requires a secp256k1 public key", n.fn)
+	}
+	return pubKey, nil
+}
+
+// pkScript returns the public key script at the index of the range.
+func (n *descNode) pkScript(index uint32) ([]byte, error) {
+	switch n.fn {
+	case "pkh", "cltv", "tokenpkh":
+		pubKey, err := n.secpKey(n.keys[0], index)
+		if err != nil {
+			return nil, err
+		}
+		switch n.fn {
+		case "cltv":
+			return PayToCLTVPubKeyHashScript(hash.Hash160(pubKey), n.lockTime)
+		case "tokenpkh":
+			return PayToTokenPubKeyHashScript(hash.Hash160(pubKey), n.coinId,
+				n.upLimit, n.name, n.feeCfg)
+		}
+		return payToPubKeyHashScript(hash.Hash160(pubKey))
+	case "pkhalt":
+		pubKey, err := n.keys[0].derive(index)
+		if err != nil {
+			return nil, err
+		}
+		if len(pubKey) == 32 {
+			return payToPubKeyHashEdwardsScript(hash.Hash160(pubKey))
+		}
+		return payToPubKeyHashSchnorrScript(hash.Hash160(pubKey))
+	case "multi":
+		builder := NewScriptBuilder().AddInt64(int64(n.required))
+		for _, k := range n.keys {
+			pubKey, err := n.secpKey(k, index)
+			if err != nil {
+				return nil, err
+			}
+			builder.AddData(pubKey)
+		}
+		return builder.AddInt64(int64(len(n.keys))).AddOp(OP_CHECKMULTISIG).Script()
+	case "sh":
+		script, err := n.sub.pkScript(index)
+		if err != nil {
+			return nil, err
+		}
+		if len(script) > MaxScriptElementSize {
+			return nil, fmt.Errorf("the script size %d exceeds %d", len(script), MaxScriptElementSize)
+		}
+		return payToScriptHashScript(hash.Hash160(script))
+	}
+	return n.script, nil
+}
+
+// isRange returns whether any key of the expression is ranged.
+func (n *descNode) isRange() bool {
+	for _, k := range n.keys {
+		if k.ranged {
+			return true
+		}
+	}
+	return n.sub != nil && n.sub.isRange()
+}
+
+// hasPrivateKeys returns whether any key of the expression is private.
+func (n *descNode) hasPrivateKeys() bool {
+	for _, k := range n.keys {
+		if k.private {
+			return true
+		}
+	}
+	return n.sub != nil && n.sub.hasPrivateKeys()
+}
+
+// Descriptor is a parsed output script descriptor.
+type Descriptor struct {
+	desc string
+	node *descNode
+	net  *params.Params
+}
+
+// ParseDescriptor parses the descriptor of the network.  The checksum is
+// verified when present, and required when requireChecksum is set.
+func ParseDescriptor(desc string, requireChecksum bool, net *params.Params) (*Descriptor, error) {
+	desc = strings.TrimSpace(desc)
+	checksum := ""
+	if i := strings.LastIndex(desc, "#"); i >= 0 {
+		desc, checksum = desc[:i], desc[i+1:]
+	}
+	expected, err := DescriptorChecksum(desc)
+	if err != nil {
+		return nil, err
+	}
+	if checksum == "" && requireChecksum {
+		return nil, fmt.Errorf("missing checksum, the descriptor is %s#%s", desc, expected)
+	}
+	if checksum != "" && checksum != expected {
+		return nil, fmt.Errorf("invalid checksum %s, expected %s", checksum, expected)
+	}
+	node, err := parseDescNode(desc, "", net)
+	if err != nil {
+		return nil, err
+	}
+	return &Descriptor{desc: desc, node: node, net: net}, nil
+}
+
+// String returns the descriptor with its checksum.
+func (d *Descriptor) String() string {
+	return d.desc + "#" + d.Checksum()
+}
+
+// Checksum returns the checksum of the descriptor.
+func (d *Descriptor) Checksum() string {
+	checksum, _ := DescriptorChecksum(d.desc)
+	return checksum
+}
+
+// IsRange returns whether the descriptor describes a range of scripts.
+func (d *Descriptor) IsRange() bool {
+	return d.node.isRange()
+}
+
+// HasPrivateKeys returns whether the descriptor contains any private key.
+func (d *Descriptor) HasPrivateKeys() bool {
+	return d.node.hasPrivateKeys()
+}
+
+// PkScript returns the public key script at the index of the range, which is
+// ignored unless the descriptor is ranged.
+func (d *Descriptor) PkScript(index uint32) ([]byte, error) {
+	return d.node.pkScript(index)
+}
+
+// Address returns the address of the public key script at the index of the
+// range.  Bare multi-signature scripts have no address.
+func (d *Descriptor) Address(index uint32) (types.Address, error) {
+	script, err := d.PkScript(index)
+	if err != nil {
+		return nil, err
+	}
+	class, addrs, _, err := ExtractPkScriptAddrs(script, d.net)
+	if err != nil {
+		return nil, err
+	}
+	if class == MultiSigTy || len(addrs) != 1 {
+		return nil, fmt.Errorf("the %s script of the descriptor has no address", class)
+	}
+	return addrs[0], nil
+}
+
+// Addresses returns the addresses of the range [begin, end] of the ranged
+// descriptor, or the address of the descriptor which isn't ranged.
+func (d *Descriptor) Addresses(begin uint32, end uint32) ([]types.Address, error) {
+	if !d.IsRange() {
+		begin, end = 0, 0
+	}
+	if end < begin || end-begin >= MaxDescriptorRange {
+		return nil, fmt.Errorf("invalid range [%d, %d]", begin, end)
+	}
+	addrs := make([]types.Address, 0, end-begin+1)
+	for index := begin; ; index++ {
+		addr, err := d.Address(index)
+		if err != nil {
+			return nil, err
+		}
+		addrs = append(addrs, addr)
+		if index == end {
+			break
+		}
+	}
+	return addrs, nil
+}
@@ -0,0 +1,162 @@
+// Copyright (c) 2017-2020 The qitmeer developers
+// Use of this source code is governed by an ISC
+// license that can be found in the LICENSE file.
+package txscript
+
+import (
+	"encoding/hex"
+	"github.com/Qitmeer/qng/common/hash"
+	"github.com/Qitmeer/qng/core/address"
+	"github.com/Qitmeer/qng/crypto/bip32"
+	"github.com/Qitmeer/qng/crypto/ecc"
+	"github.com/Qitmeer/qng/params"
+	"testing"
+)
+
+func TestDescriptorChecksum(t *testing.T) {
+	// The test vector of BIP-0380.
+	checksum, err := DescriptorChecksum("raw(deadbeef)")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if checksum != "89f8spxm" {
+		t.Fatalf("unexpected checksum %s", checksum)
+	}
+	net := &params.TestNetParams
+	if _, err := ParseDescriptor("raw(deadbeef)#89f8spxm", true, net); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := ParseDescriptor("raw(deadbeef)#89f8spxn", false, net); err == nil {
+		t.Fatalf("expected an invalid checksum to fail")
+	}
+	if _, err := ParseDescriptor("raw(deadbeef)", true, net); err == nil {
+		t.Fatalf("expected a missing checksum to fail")
+	}
+}
+
+func TestDescriptor(t *testing.T) {
+	net := &params.TestNetParams
+	_, pub1 := ecc.Secp256k1.PrivKeyFromBytes(hash.HashB([]byte{1}))
+	_, pub2 := ecc.Secp256k1.PrivKeyFromBytes(hash.HashB([]byte{2}))
+	pk1 := pub1.SerializeCompressed()
+	pk2 := pub2.SerializeCompressed()
+	hex1 := hex.EncodeToString(pk1)
+	hex2 := hex.EncodeToString(pk2)
+
+	multi, err := NewScriptBuilder().AddInt64(2).AddData(pk1).AddData(pk2).
+		AddInt64(2).AddOp(OP_CHECKMULTISIG).Script()
+	if err != nil {
+		t.Fatal(err)
+	}
+	pkh, _ := address.NewPubKeyHashAddress(hash.Hash160(pk1), net, ecc.ECDSA_Secp256k1)
+	pkhAlt, _ := address.NewPubKeyHashAddress(hash.Hash160(pk1), net, ecc.ECDSA_SecpSchnorr)
+	sh, _ := address.NewScriptHashAddress(multi, net)
+
+	tests := []struct {
+		desc string
+		addr string
+	}{
+		{"pkh(" + hex1 + ")", pkh.String()},
+		{"pkhalt(" + hex1 + ")", pkhAlt.String()},
+		{"sh(multi(2," + hex1 + "," + hex2 + "))", sh.String()},
+		{"cltv(1000," + hex1 + ")", pkh.String()},
+		{"tokenpkh(1,1000000,TKN,0," + hex1 + ")", pkh.String()},
+		{"addr(" + pkh.String() + ")", pkh.String()},
+	}
+	for _, test := range tests {
+		d, err := ParseDescriptor(test.desc, false, net)
+		if err != nil {
+			t.Fatalf("%s: %v", test.desc, err)
+		}
+		if d.IsRange() {
+			t.Fatalf("%s: unexpected range", test.desc)
+		}
+		addr, err := d.Address(0)
+		if err != nil {
+			t.Fatalf("%s: %v", test.desc, err)
+		}
+		if addr.String() != test.addr {
+			t.Fatalf("%s: unexpected address %s, expected %s", test.desc, addr, test.addr)
+		}
+	}
+
+	invalid := []string{
+		"pkh()",
+		"pkh(" + hex1 + "," + hex2 + ")",
+		"multi(3," + hex1 + "," + hex2 + ")",
+		"sh(sh(pkh(" + hex1 + ")))",
+		"pkh(" + hex1[:64] + ")",
+		"cltv(0," + hex1 + ")",
+		"wpkh(" + hex1 + ")",
+	}
+	for _, desc := range invalid {
+		if _, err := ParseDescriptor(desc, false, net); err == nil {
+			t.Fatalf("%s: expected to fail", desc)
+		}
+	}
+
+	// A bare multi-signature script has no address.
+	d, err := ParseDescriptor("multi(1,"+hex1+")", false, net)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := d.Address(0); err == nil {
+		t.Fatalf("expected a bare multi-signature script to have no address")
+	}
+}
+
+func TestDescriptorRange(t *testing.T) {
+	net := &params.TestNetParams
+	version := bip32.Bip32Version{PrivKeyVersion: net.HDPrivateKeyID[:], PubKeyVersion: net.HDPublicKeyID[:]}
+	master, err := bip32.NewMasterKey2(hash.HashB([]byte("descriptor")), version)
+	if err != nil {
+		t.Fatal(err)
+	}
+	account, err := master.NewChildKey(bip32.FirstHardenedChild)
+	if err != nil {
+		t.Fatal(err)
+	}
+	xpub := account.PublicKey().String()
+
+	d, err := ParseDescriptor("pkh([deadbeef/0']"+xpub+"/0/*)", false, net)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !d.IsRange() || d.HasPrivateKeys() {
+		t.Fatalf("unexpected range or private keys")
+	}
+	addrs, err := d.Addresses(2, 4)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(addrs) != 3 {
+		t.Fatalf("unexpected %d addresses", len(addrs))
+	}
+	external, _ := account.NewChildKey(0)
+	for i, addr := range addrs {
+		child, err := external.NewChildKey(uint32(2 + i))
+		if err != nil {
+			t.Fatal(err)
+		}
+		expected, _ := address.NewPubKeyHashAddress(hash.Hash160(child.PublicKey().Key),
+			net, ecc.ECDSA_Secp256k1)
+		if addr.String() != expected.String() {
+			t.Fatalf("index %d: unexpected address %s, expected %s", 2+i, addr, expected)
+		}
+	}
+
+	// A hardened range requires the private key.
+	if _, err := ParseDescriptor("pkh("+xpub+"/0/*')", false, net); err == nil {
+		t.Fatalf("expected a hardened range of a public key to fail")
+	}
+	d, err = ParseDescriptor("pkh("+account.String()+"/0/*')", false, net)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !d.HasPrivateKeys() {
+		t.Fatalf("expected private keys")
+	}
+	if _, err := d.Addresses(0, MaxDescriptorRange); err == nil {
+		t.Fatalf("expected a too large range to fail")
+	}
+}
@@ -71,8 +71,29 @@ const (
 	// OP_UNKNOWN192) as the OP_SHA256 opcode which consumes the top item of
 	// the data stack and replaces it with the sha256 of it.
 	ScriptVerifySHA256
+
+	// ScriptVerifyTokenOpcodes defines whether to check the transaction
+	// against the token state by the token opcodes (OP_TOKEN,
+	// OP_TOKEN_MINT, OP_TOKEN_UNMINT, OP_TOKEN_DESTORY, OP_TOKEN_RELEASE
+	// and OP_TOKEN_CHANGE) instead of treating them as NOPs.
+	ScriptVerifyTokenOpcodes
 )
 
+// StandardVerifyFlags are the script flags which the scripts of a standard
+// transaction must pass in addition to the consensus rules, they are used by
+// the mempool policy and the script trace.
+const StandardVerifyFlags = ScriptBip16 |
+	ScriptVerifyDERSignatures |
+	ScriptVerifyStrictEncoding |
+	ScriptVerifyMinimalData |
+	ScriptDiscourageUpgradableNops |
+	ScriptVerifyCleanStack |
+	ScriptVerifyCheckLockTimeVerify |
+	ScriptVerifyCheckSequenceVerify |
+	ScriptVerifySHA256 |
+	ScriptVerifyTokenOpcodes |
+	ScriptVerifyLowS
+
 const (
 	// maxStackSize is the maximum combined height of stack and alt stack
 	// during execution.
@@ -109,6 +130,9 @@ type Engine struct {
 	flags       ScriptFlags
 	version     uint16
 	bip16       bool // treat execution as pay-to-script-hash
+	tracing     bool // record the executed opcodes
+	trace       []*TraceStep
+	tokenView   TokenView // token state checked by the token opcodes
 }
 
 // hasFlag returns whether the script engine instance has the passed flag set.
@@ -280,7 +304,14 @@ func (vm *Engine) Step() (done bool, err error) {
 	// Execute the opcode while taking into account several things such as
 	// disabled opcodes, illegal opcodes, maximum allowed operations per
 	// script, maximum script element sizes, and conditionals.
+	var step *TraceStep
+	if vm.tracing {
+		step = vm.beginTraceStep(opcode)
+	}
 	err = vm.executeOpcode(opcode)
+	if step != nil {
+		vm.endTraceStep(step, err)
+	}
 	if err != nil {
 		return true, err
 	}
@@ -288,57 +319,71 @@ func (vm *Engine) Step() (done bool, err error) {
 	// The number of elements in the combination of the data and alt stacks
 	// must not exceed the maximum number of stack elements allowed.
 	if vm.dstack.Depth()+vm.astack.Depth() > maxStackSize {
+		if step != nil {
+			step.Err = ErrStackOverflow
+		}
 		return false, ErrStackOverflow
 	}
 
 	// Prepare for next instruction.
 	vm.scriptOff++
 	if vm.scriptOff >= len(vm.scripts[vm.scriptIdx]) {
-		// Illegal to have an `if' that straddles two scripts.
-		if len(vm.condStack) != 0 {
-			return false, ErrStackMissingEndif
-		}
+		end := vm.beginTraceEnd(vm.scriptIdx)
+		done, err = vm.endScript()
+		vm.endTraceEnd(end, err)
+		return done, err
+	}
+	return false, nil
+}
 
-		// Alt stack doesn't persist.
-		_ = vm.astack.DropN(vm.astack.Depth())
-
-		vm.numOps = 0 // number of ops is per script.
-		vm.scriptOff = 0
-		if vm.scriptIdx == 0 && vm.bip16 {
-			vm.scriptIdx++
-			vm.savedFirstStack = vm.GetStack()
-		} else if vm.scriptIdx == 1 && vm.bip16 {
-			// Put us past the end for CheckErrorCondition()
-			vm.scriptIdx++
-			// Check script ran successfully and pull the script
-			// out of the first stack and execute that.
-			err := vm.CheckErrorCondition(false)
-			if err != nil {
-				return false, err
-			}
+// endScript moves the program counter to the next script after the current
+// one has ended.  The pay-to-script-hash redeem script is pulled out of the
+// stack of the signature script once the public key script succeeds.
+func (vm *Engine) endScript() (done bool, err error) {
+	// Illegal to have an `if' that straddles two scripts.
+	if len(vm.condStack) != 0 {
+		return false, ErrStackMissingEndif
+	}
 
-			script := vm.savedFirstStack[len(vm.savedFirstStack)-1]
-			pops, err := parseScript(script)
-			if err != nil {
-				return false, err
-			}
-			vm.scripts = append(vm.scripts, pops)
+	// Alt stack doesn't persist.
+	_ = vm.astack.DropN(vm.astack.Depth())
 
-			// Set stack to be the stack from first script minus the
-			// script itself
-			vm.SetStack(vm.savedFirstStack[:len(vm.savedFirstStack)-1])
-		} else {
-			vm.scriptIdx++
-		}
-		// there are zero length scripts in the wild
-		if vm.scriptIdx < len(vm.scripts) &&
-			vm.scriptOff >= len(vm.scripts[vm.scriptIdx]) {
-			vm.scriptIdx++
+	vm.numOps = 0 // number of ops is per script.
+	vm.scriptOff = 0
+	if vm.scriptIdx == 0 && vm.bip16 {
+		vm.scriptIdx++
+		vm.savedFirstStack = vm.GetStack()
+	} else if vm.scriptIdx == 1 && vm.bip16 {
+		// Put us past the end for CheckErrorCondition()
+		vm.scriptIdx++
+		// Check script ran successfully and pull the script
+		// out of the first stack and execute that.
+		err = vm.CheckErrorCondition(false)
+		if err != nil {
+			return false, err
 		}
-		vm.lastCodeSep = 0
-		if vm.scriptIdx >= len(vm.scripts) {
-			return true, nil
+
+		script := vm.savedFirstStack[len(vm.savedFirstStack)-1]
+		pops, err := parseScript(script)
+		if err != nil {
+			return false, err
 		}
+		vm.scripts = append(vm.scripts, pops)
+
+		// Set stack to be the stack from first script minus the
+		// script itself
+		vm.SetStack(vm.savedFirstStack[:len(vm.savedFirstStack)-1])
+	} else {
+		vm.scriptIdx++
+	}
+	// there are zero length scripts in the wild
+	if vm.scriptIdx < len(vm.scripts) &&
+		vm.scriptOff >= len(vm.scripts[vm.scriptIdx]) {
+		vm.scriptIdx++
+	}
+	vm.lastCodeSep = 0
+	if vm.scriptIdx >= len(vm.scripts) {
+		return true, nil
 	}
 	return false, nil
 }
@@ -382,7 +427,10 @@ func (vm *Engine) Execute() (err error) {
 		})))
 	}
 
-	return vm.CheckErrorCondition(true)
+	end := vm.beginTraceEnd(len(vm.scripts) - 1)
+	err = vm.CheckErrorCondition(true)
+	vm.endTraceEnd(end, err)
+	return err
 }
 
 // subScript returns the script since the last OP_CODESEPARATOR.
@@ -0,0 +1,235 @@
+// Copyright (c) 2017-2020 The qitmeer developers
+// Use of this source code is governed by an ISC
+// license that can be found in the LICENSE file.
+package txscript
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"errors"
+	"fmt"
+	"github.com/Qitmeer/qng/common/hash"
+	"github.com/Qitmeer/qng/core/types"
+	"github.com/Qitmeer/qng/crypto/ecc"
+)
+
+// HTLCSecretSize is the size of the secret of a standard hash time-locked
+// contract.
+const HTLCSecretSize = 32
+
+var (
+	// ErrNotHTLC is returned when the script is not a standard hash
+	// time-locked contract.
+	ErrNotHTLC = errors.New("not a hash time-locked contract")
+
+	// ErrHTLCKeyMismatch is returned when the key doesn't own the branch of
+	// the hash time-locked contract which it signs.
+	ErrHTLCKeyMismatch = errors.New("the key doesn't match the hash time-locked contract")
+)
+
+// HTLCDataPushes houses the data pushes of a hash time-locked contract.
+type HTLCDataPushes struct {
+	// HashOp is the opcode which hashes the secret, OP_SHA256 or
+	// OP_BLAKE256.
+	HashOp           byte
+	SecretHash       [32]byte
+	RecipientHash160 [20]byte
+	RefundHash160    [20]byte
+	LockTime         int64
+}
+
+// isHTLC returns true if the script passed is a hash time-locked contract of
+// the form:
+//
+//	OP_IF
+//	  OP_SIZE 32 OP_EQUALVERIFY <OP_SHA256|OP_BLAKE256> <secret hash> OP_EQUALVERIFY
+//	  OP_DUP OP_HASH160 <recipient hash>
+//	OP_ELSE
+//	  <lockTime> OP_CHECKLOCKTIMEVERIFY OP_DROP
+//	  OP_DUP OP_HASH160 <refund hash>
+//	OP_ENDIF
+//	OP_EQUALVERIFY OP_CHECKSIG
+func isHTLC(pops []ParsedOpcode) bool {
+	return len(pops) == 20 &&
+		pops[0].opcode.value == OP_IF &&
+		pops[1].opcode.value == OP_SIZE &&
+		pops[2].opcode.value == OP_DATA_1 &&
+		pops[2].data[0] == HTLCSecretSize &&
+		pops[3].opcode.value == OP_EQUALVERIFY &&
+		(pops[4].opcode.value == OP_SHA256 || pops[4].opcode.value == OP_BLAKE256) &&
+		pops[5].opcode.value == OP_DATA_32 &&
+		pops[6].opcode.value == OP_EQUALVERIFY &&
+		pops[7].opcode.value == OP_DUP &&
+		pops[8].opcode.value == OP_HASH160 &&
+		pops[9].opcode.value == OP_DATA_20 &&
+		pops[10].opcode.value == OP_ELSE &&
+		pops[11].opcode.value <= OP_16 && canonicalPush(pops[11]) &&
+		pops[12].opcode.value == OP_CHECKLOCKTIMEVERIFY &&
+		pops[13].opcode.value == OP_DROP &&
+		pops[14].opcode.value == OP_DUP &&
+		pops[15].opcode.value == OP_HASH160 &&
+		pops[16].opcode.value == OP_DATA_20 &&
+		pops[17].opcode.value == OP_ENDIF &&
+		pops[18].opcode.value == OP_EQUALVERIFY &&
+		pops[19].opcode.value == OP_CHECKSIG
+}
+
+// HTLCSecretHash returns the hash of the secret by the hash opcode of a hash
+// time-locked contract.
+func HTLCSecretHash(hashOp byte, secret []byte) ([]byte, error) {
+	switch hashOp {
+	case OP_SHA256:
+		h := sha256.Sum256(secret)
+		return h[:], nil
+	case OP_BLAKE256:
+		return hash.DoubleHashB(secret), nil
+	}
+	return nil, fmt.Errorf("unsupported hash opcode of the secret: %s", opcodeArray[hashOp].name)
+}
+
+// htlcHashOps are the hash opcodes of the secret by their names.
+var htlcHashOps = map[string]byte{
+	"sha256":   OP_SHA256,
+	"blake256": OP_BLAKE256,
+}
+
+// ParseHTLCHashOp returns the hash opcode of the secret by its name, sha256 or
+// blake256.
+func ParseHTLCHashOp(name string) (byte, error) {
+	hashOp, ok := htlcHashOps[name]
+	if !ok {
+		return 0, fmt.Errorf("unsupported hash of the secret: %s (sha256, blake256)", name)
+	}
+	return hashOp, nil
+}
+
+// HTLCHashOpName returns the name of the hash opcode of the secret.
+func HTLCHashOpName(hashOp byte) string {
+	for name, op := range htlcHashOps {
+		if op == hashOp {
+			return name
+		}
+	}
+	return opcodeArray[hashOp].name
+}
+
+// PayToHTLCScript creates a new hash time-locked contract, which pays to the
+// recipient with the secret of the secret hash, or refunds after the lock
+// time.
+func PayToHTLCScript(hashOp byte, secretHash []byte, recipientHash160 []byte,
+	refundHash160 []byte, lockTime int64) ([]byte, error) {
+	if hashOp != OP_SHA256 && hashOp != OP_BLAKE256 {
+		return nil, fmt.Errorf("unsupported hash opcode of the secret: %s", opcodeArray[hashOp].name)
+	}
+	if len(secretHash) != 32 {
+		return nil, fmt.Errorf("invalid secret hash length %d", len(secretHash))
+	}
+	if len(recipientHash160) != 20 || len(refundHash160) != 20 {
+		return nil, ErrUnsupportedAddress
+	}
+	if lockTime < 1 || lockTime > int64(types.MaxTxInSequenceNum) {
+		return nil, fmt.Errorf("Locktime out of range:%d", lockTime)
+	}
+	return NewScriptBuilder().
+		AddOp(OP_IF).
+		AddOp(OP_SIZE).AddInt64(HTLCSecretSize).AddOp(OP_EQUALVERIFY).
+		AddOp(hashOp).AddData(secretHash).AddOp(OP_EQUALVERIFY).
+		AddOp(OP_DUP).AddOp(OP_HASH160).AddData(recipientHash160).
+		AddOp(OP_ELSE).
+		AddInt64(lockTime).AddOp(OP_CHECKLOCKTIMEVERIFY).AddOp(OP_DROP).
+		AddOp(OP_DUP).AddOp(OP_HASH160).AddData(refundHash160).
+		AddOp(OP_ENDIF).
+		AddOp(OP_EQUALVERIFY).AddOp(OP_CHECKSIG).
+		Script()
+}
+
+// ExtractHTLCDataPushes returns the data pushes of a hash time-locked
+// contract, ErrNotHTLC is returned when the script is not one.
+func ExtractHTLCDataPushes(script []byte) (*HTLCDataPushes, error) {
+	pops, err := parseScript(script)
+	if err != nil {
+		return nil, err
+	}
+	if !isHTLC(pops) {
+		return nil, ErrNotHTLC
+	}
+	pushes := &HTLCDataPushes{HashOp: pops[4].opcode.value}
+	copy(pushes.SecretHash[:], pops[5].data)
+	copy(pushes.RecipientHash160[:], pops[9].data)
+	copy(pushes.RefundHash160[:], pops[16].data)
+	if op := pops[11].opcode; isSmallInt(op) {
+		pushes.LockTime = int64(asSmallInt(op))
+	} else {
+		lockTime, err := makeScriptNum(pops[11].data, true, 5)
+		if err != nil {
+			return nil, err
+		}
+		pushes.LockTime = int64(lockTime)
+	}
+	return pushes, nil
+}
+
+// SignHTLC creates the signature script of the idx'th input of tx which spends
+// the hash time-locked contract.  With the secret it redeems the contract by
+// the key of the recipient, otherwise it refunds the contract by the key of
+// the refund, which requires the lock time of tx to reach the contract and the
+// sequence of the input to be not final.  When p2sh is set, the input spends a
+// pay-to-script-hash output of the contract, and the contract is pushed last.
+func SignHTLC(tx *types.Transaction, idx int, contract []byte, hashType SigHashType,
+	key ecc.PrivateKey, secret []byte, p2sh bool) ([]byte, error) {
+	if idx < 0 || idx >= len(tx.TxIn) {
+		return nil, ErrInvalidIndex
+	}
+	pushes, err := ExtractHTLCDataPushes(contract)
+	if err != nil {
+		return nil, err
+	}
+	_, pub := ecc.Secp256k1.PrivKeyFromBytes(key.Serialize())
+	pubKey := pub.SerializeCompressed()
+	pkh := hash.Hash160(pubKey)
+
+	redeem := secret != nil
+	if redeem {
+		if len(secret) != HTLCSecretSize {
+			return nil, fmt.Errorf("invalid secret length %d", len(secret))
+		}
+		secretHash, err := HTLCSecretHash(pushes.HashOp, secret)
+		if err != nil {
+			return nil, err
+		}
+		if !bytes.Equal(secretHash, pushes.SecretHash[:]) {
+			return nil, fmt.Errorf("the secret doesn't match the secret hash %x", pushes.SecretHash)
+		}
+		if !bytes.Equal(pkh, pushes.RecipientHash160[:]) {
+			return nil, ErrHTLCKeyMismatch
+		}
+	} else {
+		if !bytes.Equal(pkh, pushes.RefundHash160[:]) {
+			return nil, ErrHTLCKeyMismatch
+		}
+		lockTime := int64(tx.LockTime)
+		if (lockTime < LockTimeThreshold) != (pushes.LockTime < LockTimeThreshold) ||
+			lockTime < pushes.LockTime {
+			return nil, fmt.Errorf("the lock time %d of the transaction doesn't reach the contract %d",
+				lockTime, pushes.LockTime)
+		}
+		if tx.TxIn[idx].Sequence == types.MaxTxInSequenceNum {
+			return nil, fmt.Errorf("the sequence of the input %d is final", idx)
+		}
+	}
+
+	sig, err := RawTxInSignature(tx, idx, contract, hashType, key)
+	if err != nil {
+		return nil, err
+	}
+	builder := NewScriptBuilder().AddData(sig).AddData(pubKey)
+	if redeem {
+		builder.AddData(secret).AddOp(OP_TRUE)
+	} else {
+		builder.AddOp(OP_FALSE)
+	}
+	if p2sh {
+		builder.AddData(contract)
+	}
+	return builder.Script()
+}
@@ -0,0 +1,107 @@
+// Copyright (c) 2017-2020 The qitmeer developers
+// Use of this source code is governed by an ISC
+// license that can be found in the LICENSE file.
+package txscript
+
+import (
+	"bytes"
+	"github.com/Qitmeer/qng/common/hash"
+	"github.com/Qitmeer/qng/core/types"
+	"github.com/Qitmeer/qng/crypto/ecc"
+	"github.com/Qitmeer/qng/params"
+	"testing"
+)
+
+func TestHTLC(t *testing.T) {
+	recipientKey, _ := ecc.Secp256k1.PrivKeyFromBytes(bytes.Repeat([]byte{0x01}, 32))
+	refundKey, _ := ecc.Secp256k1.PrivKeyFromBytes(bytes.Repeat([]byte{0x02}, 32))
+	pkh := func(key ecc.PrivateKey) []byte {
+		_, pub := ecc.Secp256k1.PrivKeyFromBytes(key.Serialize())
+		return hash.Hash160(pub.SerializeCompressed())
+	}
+	secret := bytes.Repeat([]byte{0x03}, HTLCSecretSize)
+	lockTime := int64(1000)
+	flags := ScriptBip16 | ScriptVerifyDERSignatures | ScriptVerifyStrictEncoding |
+		ScriptVerifyMinimalData | ScriptVerifyCleanStack |
+		ScriptVerifyCheckLockTimeVerify | ScriptVerifySHA256
+
+	for _, hashOp := range []byte{OP_SHA256, OP_BLAKE256} {
+		secretHash, err := HTLCSecretHash(hashOp, secret)
+		if err != nil {
+			t.Fatal(err)
+		}
+		contract, err := PayToHTLCScript(hashOp, secretHash, pkh(recipientKey), pkh(refundKey), lockTime)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if class := GetScriptClass(DefaultScriptVersion, contract); class != HTLCTy {
+			t.Fatalf("expected %v, got %v", HTLCTy, class)
+		}
+		class, addrs, requiredSigs, err := ExtractPkScriptAddrs(contract, &params.TestNetParams)
+		if err != nil || class != HTLCTy || len(addrs) != 2 || requiredSigs != 1 {
+			t.Fatalf("unexpected addresses of the contract: %v %v %d %v", class, addrs, requiredSigs, err)
+		}
+		pushes, err := ExtractHTLCDataPushes(contract)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if pushes.HashOp != hashOp || pushes.LockTime != lockTime ||
+			!bytes.Equal(pushes.SecretHash[:], secretHash) {
+			t.Fatalf("unexpected data pushes: %+v", pushes)
+		}
+
+		for _, p2sh := range []bool{false, true} {
+			pkScript := contract
+			if p2sh {
+				pkScript, err = PayToScriptHashScript(hash.Hash160(contract))
+				if err != nil {
+					t.Fatal(err)
+				}
+			}
+			tx := types.NewTransaction()
+			tx.AddTxIn(types.NewTxInput(types.NewOutPoint(&hash.Hash{0x01}, 0), nil))
+			tx.AddTxOut(types.NewTxOutput(types.Amount{Value: 1000, Id: types.MEERA}, pkh(recipientKey)))
+
+			// The recipient redeems with the secret.
+			tx.TxIn[0].SignScript, err = SignHTLC(tx, 0, contract, SigHashAll, recipientKey, secret, p2sh)
+			if err != nil {
+				t.Fatal(err)
+			}
+			vm, err := NewEngine(pkScript, tx, 0, flags, 0, nil)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if err := vm.Execute(); err != nil {
+				t.Fatalf("redeem failed (p2sh %v): %v", p2sh, err)
+			}
+			_, err = SignHTLC(tx, 0, contract, SigHashAll, refundKey, secret, p2sh)
+			if err != ErrHTLCKeyMismatch {
+				t.Fatalf("expected %v, got %v", ErrHTLCKeyMismatch, err)
+			}
+
+			// The refund needs the lock time.
+			_, err = SignHTLC(tx, 0, contract, SigHashAll, refundKey, nil, p2sh)
+			if err == nil {
+				t.Fatalf("expected the refund before the lock time to fail")
+			}
+			tx.LockTime = uint32(lockTime)
+			tx.TxIn[0].Sequence = types.MaxTxInSequenceNum - 1
+			tx.TxIn[0].SignScript, err = SignHTLC(tx, 0, contract, SigHashAll, refundKey, nil, p2sh)
+			if err != nil {
+				t.Fatal(err)
+			}
+			vm, err = NewEngine(pkScript, tx, 0, flags, 0, nil)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if err := vm.Execute(); err != nil {
+				t.Fatalf("refund failed (p2sh %v): %v", p2sh, err)
+			}
+		}
+	}
+
+	_, err := ExtractHTLCDataPushes([]byte{OP_TRUE})
+	if err != ErrNotHTLC {
+		t.Fatalf("expected %v, got %v", ErrNotHTLC, err)
+	}
+}
@@ -0,0 +1,96 @@
+// Copyright (c) 2017-2020 The qitmeer developers
+// Use of this source code is governed by an ISC
+// license that can be found in the LICENSE file.
+package txscript
+
+import (
+	"bytes"
+	"fmt"
+	"github.com/Qitmeer/qng/common/hash"
+	"github.com/Qitmeer/qng/core/address"
+	"github.com/Qitmeer/qng/core/types"
+	"github.com/Qitmeer/qng/crypto/ecc"
+	"github.com/Qitmeer/qng/crypto/ecc/schnorr"
+	"github.com/Qitmeer/qng/crypto/ecc/secp256k1"
+	"github.com/Qitmeer/qng/params"
+)
+
+// An n-of-n MuSig2 key is a single secp256k1 Schnorr key, so it's paid to by
+// the standard pay-to-pubkey and pay-to-pubkey-hash scripts of the Schnorr
+// signature suite, and on chain it can't be told apart from a single key:
+//
+//	<aggregated pubkey> <secSchnorr> OP_CHECKSIGALT
+//	OP_DUP OP_HASH160 <aggregated pubkey hash> OP_EQUALVERIFY <secSchnorr> OP_CHECKSIGALT
+
+// MuSig2PubKeyHashAddress returns the Schnorr pay-to-pubkey-hash address of
+// the aggregated key of the public keys.
+func MuSig2PubKeyHashAddress(pubKeys []*secp256k1.PublicKey,
+	net *params.Params) (*address.PubKeyHashAddress, error) {
+	aggKey, err := schnorr.MuSig2AggregatePubKeys(pubKeys)
+	if err != nil {
+		return nil, err
+	}
+	return address.NewPubKeyHashAddress(hash.Hash160(aggKey.SerializeCompressed()),
+		net, ecc.ECDSA_SecpSchnorr)
+}
+
+// checkMuSig2PkScript returns an error unless the public key script pays to
+// the aggregated key by a Schnorr pay-to-pubkey or pay-to-pubkey-hash script.
+func checkMuSig2PkScript(pkScript []byte, aggKey *secp256k1.PublicKey) (ScriptClass, error) {
+	pops, err := parseScript(pkScript)
+	if err != nil {
+		return NonStandardTy, err
+	}
+	class := typeOfScript(pops)
+	if class != PubkeyAltTy && class != PubkeyHashAltTy {
+		return class, fmt.Errorf("the script %s is not a schnorr pubkey or pubkey hash script", class)
+	}
+	suite, err := ExtractPkScriptAltSigType(pkScript)
+	if err != nil {
+		return class, err
+	}
+	if suite != ecc.ECDSA_SecpSchnorr {
+		return class, fmt.Errorf("the signature suite of the script is not schnorr")
+	}
+	pubKey := aggKey.SerializeCompressed()
+	switch class {
+	case PubkeyAltTy:
+		if !bytes.Equal(pops[0].data, pubKey) {
+			return class, fmt.Errorf("the script doesn't pay to the aggregated key")
+		}
+	case PubkeyHashAltTy:
+		if !bytes.Equal(pops[2].data, hash.Hash160(pubKey)) {
+			return class, fmt.Errorf("the script doesn't pay to the aggregated key")
+		}
+	}
+	return class, nil
+}
+
+// MuSig2SigHash returns the signature hash of the idx'th input of tx, which
+// spends the public key script paying to the aggregated key.  It is the
+// message which every signer signs.
+func MuSig2SigHash(tx *types.Transaction, idx int, pkScript []byte,
+	hashType SigHashType, aggKey *secp256k1.PublicKey) ([]byte, error) {
+	if idx < 0 || idx >= len(tx.TxIn) {
+		return nil, ErrInvalidIndex
+	}
+	if _, err := checkMuSig2PkScript(pkScript, aggKey); err != nil {
+		return nil, err
+	}
+	return CalcSignatureHash(pkScript, hashType, tx, idx, nil)
+}
+
+// MuSig2SignatureScript creates the signature script which spends the public
+// key script paying to the aggregated key with the combined signature.
+func MuSig2SignatureScript(pkScript []byte, sig *schnorr.Signature,
+	hashType SigHashType, aggKey *secp256k1.PublicKey) ([]byte, error) {
+	class, err := checkMuSig2PkScript(pkScript, aggKey)
+	if err != nil {
+		return nil, err
+	}
+	builder := NewScriptBuilder().AddData(append(sig.Serialize(), byte(hashType)))
+	if class == PubkeyHashAltTy {
+		builder.AddData(aggKey.SerializeCompressed())
+	}
+	return builder.Script()
+}
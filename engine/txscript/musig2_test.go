@@ -0,0 +1,92 @@
+// Copyright (c) 2017-2020 The qitmeer developers
+// Use of this source code is governed by an ISC
+// license that can be found in the LICENSE file.
+package txscript
+
+import (
+	"crypto/rand"
+	"github.com/Qitmeer/qng/common/hash"
+	"github.com/Qitmeer/qng/core/types"
+	"github.com/Qitmeer/qng/crypto/ecc/schnorr"
+	"github.com/Qitmeer/qng/crypto/ecc/secp256k1"
+	"github.com/Qitmeer/qng/params"
+	"testing"
+)
+
+func TestMuSig2Spend(t *testing.T) {
+	privs := make([]*secp256k1.PrivateKey, 3)
+	pks := make([]*secp256k1.PublicKey, 3)
+	for i := range privs {
+		privs[i], _ = secp256k1.GeneratePrivateKey()
+		pks[i] = privs[i].PubKey()
+	}
+	aggKey, err := schnorr.MuSig2AggregatePubKeys(pks)
+	if err != nil {
+		t.Fatal(err)
+	}
+	addr, err := MuSig2PubKeyHashAddress(pks, &params.TestNetParams)
+	if err != nil {
+		t.Fatal(err)
+	}
+	pkhScript, err := PayToAddrScript(addr)
+	if err != nil {
+		t.Fatal(err)
+	}
+	pkScript, err := payToSchnorrPubKeyScript(aggKey.SerializeCompressed())
+	if err != nil {
+		t.Fatal(err)
+	}
+	flags := ScriptBip16 | ScriptVerifyDERSignatures | ScriptVerifyStrictEncoding |
+		ScriptVerifyMinimalData | ScriptVerifyCleanStack
+
+	for _, script := range [][]byte{pkhScript, pkScript} {
+		tx := types.NewTransaction()
+		tx.AddTxIn(types.NewTxInput(types.NewOutPoint(&hash.Hash{0x01}, 0), nil))
+		tx.AddTxOut(types.NewTxOutput(types.Amount{Value: 1000, Id: types.MEERA}, script))
+
+		msg, err := MuSig2SigHash(tx, 0, script, SigHashAll, aggKey)
+		if err != nil {
+			t.Fatal(err)
+		}
+		secNonces := make([][]byte, len(privs))
+		pubNonces := make([][]byte, len(privs))
+		for i, priv := range privs {
+			secNonces[i], pubNonces[i], err = schnorr.MuSig2GenerateNonce(rand.Reader, priv, msg)
+			if err != nil {
+				t.Fatal(err)
+			}
+		}
+		aggNonce, err := schnorr.MuSig2AggregateNonces(pubNonces)
+		if err != nil {
+			t.Fatal(err)
+		}
+		partialSigs := make([][]byte, len(privs))
+		for i, priv := range privs {
+			partialSigs[i], err = schnorr.MuSig2PartialSign(secNonces[i], priv, pks, aggNonce, msg)
+			if err != nil {
+				t.Fatal(err)
+			}
+		}
+		sig, err := schnorr.MuSig2CombineSigs(partialSigs, pks, aggNonce, msg)
+		if err != nil {
+			t.Fatal(err)
+		}
+		tx.TxIn[0].SignScript, err = MuSig2SignatureScript(script, sig, SigHashAll, aggKey)
+		if err != nil {
+			t.Fatal(err)
+		}
+		vm, err := NewEngine(script, tx, 0, flags, 0, nil)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if err := vm.Execute(); err != nil {
+			t.Fatalf("spend of %v failed: %v", GetScriptClass(DefaultScriptVersion, script), err)
+		}
+	}
+
+	// A script of another key is refused.
+	other, _ := payToSchnorrPubKeyScript(pks[0].SerializeCompressed())
+	if _, err := MuSig2SignatureScript(other, &schnorr.Signature{}, SigHashAll, aggKey); err == nil {
+		t.Fatalf("expected the script of another key to fail")
+	}
+}
@@ -534,14 +534,14 @@ var opcodeArray = [256]Opcode{
 	OP_CHECKSIGALTVERIFY: {OP_CHECKSIGALTVERIFY, "OP_CHECKSIGALTVERIFY", 1, opcodeCheckSigAltVerify},
 
 	// Qitmeer Token opcode.
-	OP_TOKEN_MINT:    {OP_TOKEN_MINT, "OP_TOKEN_MINT", 1, opcodeNop},
-	OP_TOKEN_UNMINT:  {OP_TOKEN_UNMINT, "OP_TOKEN_UNMINT", 1, opcodeNop},
+	OP_TOKEN_MINT:    {OP_TOKEN_MINT, "OP_TOKEN_MINT", 1, opcodeCheckTokenVerify},
+	OP_TOKEN_UNMINT:  {OP_TOKEN_UNMINT, "OP_TOKEN_UNMINT", 1, opcodeCheckTokenVerify},
 	OP_MEER_LOCK:     {OP_MEER_LOCK, "OP_MEER_LOCK", 1, opcodeNop},
 	OP_MEER_RELEASE:  {OP_MEER_RELEASE, "OP_MEER_RELEASE", 1, opcodeNop},
-	OP_TOKEN_DESTORY: {OP_TOKEN_DESTORY, "OP_TOKEN_DESTORY", 1, opcodeNop},
-	OP_TOKEN_RELEASE: {OP_TOKEN_RELEASE, "OP_TOKEN_RELEASE", 1, opcodeNop},
+	OP_TOKEN_DESTORY: {OP_TOKEN_DESTORY, "OP_TOKEN_DESTORY", 1, opcodeCheckTokenVerify},
+	OP_TOKEN_RELEASE: {OP_TOKEN_RELEASE, "OP_TOKEN_RELEASE", 1, opcodeCheckTokenVerify},
 	OP_MEER_CHANGE:   {OP_MEER_CHANGE, "OP_MEER_CHANGE", 1, opcodeNop},
-	OP_TOKEN_CHANGE:  {OP_TOKEN_CHANGE, "OP_TOKEN_CHANGE", 1, opcodeNop},
+	OP_TOKEN_CHANGE:  {OP_TOKEN_CHANGE, "OP_TOKEN_CHANGE", 1, opcodeCheckTokenVerify},
 	OP_TOKEN:         {OP_TOKEN, "OP_TOKEN", 1, opcodeCheckTokenVerify},
 	// Undefined opcodes.
 
@@ -3052,8 +3052,14 @@ func opcodeCheckSigAltVerify(op *ParsedOpcode, vm *Engine) error {
 	return err
 }
 
+// opcodeCheckTokenVerify checks the transaction against the token state by
+// the token opcodes when the ScriptVerifyTokenOpcodes flag is set, otherwise
+// they are treated as NOPs.  See checkTokenOp for the details.
 func opcodeCheckTokenVerify(op *ParsedOpcode, vm *Engine) error {
-	return nil
+	if !vm.hasFlag(ScriptVerifyTokenOpcodes) {
+		return nil
+	}
+	return vm.checkTokenOp(op.opcode.value)
 }
 
 // OpcodeByName is a map that can be used to lookup an opcode by its
@@ -373,6 +373,10 @@ func sign(chainParams *params.Params, tx *types.Transaction, idx int,
 		}
 
 		return script, class, addresses, nrequired, nil
+
+	case HTLCTy:
+		return nil, class, nil, 0,
+			errors.New("can't sign HTLC transactions without the branch, use SignHTLC")
 	default:
 		return nil, class, nil, 0,
 			errors.New("can't sign unknown transactions")
@@ -41,6 +41,7 @@ const (
 	PubkeyHashAltTy                      // Alternative signature pubkey hash.
 	CLTVPubKeyHashTy                     // Check Lock Time Verify Pay pubkey hash.
 	TokenPubKeyHashTy                    // Token Pay pubkey hash.
+	HTLCTy                               // Hash time-locked contract.
 )
 
 // Script Interface provide a abstract layer to support new Script parsing from opcode
@@ -130,6 +131,7 @@ var scriptClassToName = []string{
 	StakeSubChangeTy:  "sstxchange",
 	CLTVPubKeyHashTy:  "cltvpubkeyhash",
 	TokenPubKeyHashTy: "tokenpubkeyhash",
+	HTLCTy:            "htlc",
 }
 
 // String implements the Stringer interface by returning the name of
@@ -466,6 +468,8 @@ func typeOfScript(pops []ParsedOpcode) ScriptClass {
 		return CLTVPubKeyHashTy
 	} else if isTokenPubkeyHash(pops) {
 		return TokenPubKeyHashTy
+	} else if isHTLC(pops) {
+		return HTLCTy
 	}
 
 	return NonStandardTy
@@ -1413,6 +1417,19 @@ func ExtractPkScriptAddrs(pkScript []byte,
 		if err == nil {
 			addrs = append(addrs, addr)
 		}
+
+	case HTLCTy:
+		// A hash time-locked contract is spent by either the recipient
+		// or the refund pubkey hash, the recipient is the 10th item and
+		// the refund is the 17th item of the script.
+		requiredSigs = 1
+		for _, i := range []int{9, 16} {
+			addr, err := address.NewPubKeyHashAddress(pops[i].data,
+				chainParams, ecc.ECDSA_Secp256k1)
+			if err == nil {
+				addrs = append(addrs, addr)
+			}
+		}
 	}
 
 	return scriptClass, addrs, requiredSigs, nil
@@ -1497,9 +1514,8 @@ type AtomicSwapDataPushes struct {
 // ExtractAtomicSwapDataPushes returns (nil, nil).  Non-nil errors are returned
 // for unparsable scripts.
 //
-// NOTE: Atomic swaps are not considered standard script types by the mempool policy
-// and should be used with P2SH.  The atomic swap format is also
-// expected to change to use a more secure hash function in the future.
+// NOTE: The atomic swaps with 32-byte secrets are the standard HTLCTy scripts,
+// see ExtractHTLCDataPushes.
 //
 // This function is only defined in the txscript package due to API limitations
 // which prevent callers using txscript to parse nonstandard scripts.
@@ -0,0 +1,280 @@
+// Copyright (c) 2017-2020 The qitmeer developers
+// Use of this source code is governed by an ISC
+// license that can be found in the LICENSE file.
+package txscript
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"github.com/Qitmeer/qng/core/types"
+)
+
+// The token opcodes check the transaction against the token state when the
+// ScriptVerifyTokenOpcodes flag is set, otherwise they are NOPs.  The
+// consensus rules set the flag from the fork height of the network, see
+// forks.IsTokenOpcodesForkHeight.  Like
+// OP_CHECKLOCKTIMEVERIFY they only peek their arguments and leave the stack
+// unchanged, so a script usually drops them afterwards:
+//
+//	<coinId> <upLimit> <name> <feeCfg> OP_TOKEN
+//	    the token is registered, and a mint transaction mints the token.
+//	<coinId> <amount> OP_TOKEN_MINT
+//	    the transaction mints at most amount of the enabled token, within
+//	    its upper limit.
+//	<coinId> <amount> OP_TOKEN_UNMINT
+//	    the transaction unmints at most amount of the token, within its
+//	    balance.
+//	<coinId> <amount> OP_TOKEN_DESTORY
+//	    the transaction unmints at least amount of the token.
+//	<coinId> <amount> OP_TOKEN_RELEASE
+//	    the inputs which spend the script release at most amount of the token
+//	    to other scripts than the script.
+//	<coinId> <amount> OP_TOKEN_CHANGE
+//	    the transaction pays at least amount of the token back to the script
+//	    of the input.
+//
+// For example, a vesting output which releases at most 100 of token 1 after
+// the lock time:
+//
+//	1 100 OP_TOKEN_RELEASE OP_2DROP <lockTime> OP_CHECKLOCKTIMEVERIFY OP_DROP
+//	OP_DUP OP_HASH160 <pubkey hash> OP_EQUALVERIFY OP_CHECKSIG
+//
+// or an output of token 1 which can only be burned by both keys:
+//
+//	1 0 OP_TOKEN_DESTORY OP_2DROP 2 <pubkey 1> <pubkey 2> 2 OP_CHECKMULTISIG
+
+// ErrTokenViewMissing is returned when the token opcodes are enforced
+// without the token state.
+var ErrTokenViewMissing = errors.New("the token opcodes require the token state")
+
+// TokenInfo houses the state of a token which the token opcodes check.
+type TokenInfo struct {
+	UpLimit uint64
+	Enable  bool
+	Balance int64
+}
+
+// TokenView provides the token state and the previous outputs to the token
+// opcodes.
+type TokenView interface {
+	// LookupToken returns the state of the token, or nil when the token is
+	// not registered.
+	LookupToken(id types.CoinID) *TokenInfo
+
+	// LookupOutput returns the amount and the public key script of the
+	// previous output, ok is false when the output is unknown.
+	LookupOutput(outpoint types.TxOutPoint) (amount types.Amount, pkScript []byte, ok bool)
+}
+
+// SetTokenView sets the token state which the token opcodes check.
+func (vm *Engine) SetTokenView(view TokenView) {
+	vm.tokenView = view
+}
+
+// peekTokenId returns the coin id at the depth of the data stack.
+func (vm *Engine) peekTokenId(idx int32) (types.CoinID, error) {
+	so, err := vm.dstack.PeekByteArray(idx)
+	if err != nil {
+		return 0, err
+	}
+	id, err := makeScriptNum(so, vm.dstack.verifyMinimalData, 3)
+	if err != nil {
+		return 0, err
+	}
+	if id < 0 || id > 0xffff {
+		return 0, fmt.Errorf("invalid coin id: %d", id)
+	}
+	return types.CoinID(id), nil
+}
+
+// peekTokenAmount returns the token amount at the depth of the data stack.
+func (vm *Engine) peekTokenAmount(idx int32) (int64, error) {
+	so, err := vm.dstack.PeekByteArray(idx)
+	if err != nil {
+		return 0, err
+	}
+	amount, err := makeScriptNum(so, vm.dstack.verifyMinimalData, 8)
+	if err != nil {
+		return 0, err
+	}
+	if amount < 0 || amount > types.MaxAmount {
+		return 0, fmt.Errorf("token amount out of range: %d", amount)
+	}
+	return int64(amount), nil
+}
+
+// lookupToken returns the state of the registered token.
+func (vm *Engine) lookupToken(id types.CoinID) (*TokenInfo, error) {
+	if vm.tokenView == nil {
+		return nil, ErrTokenViewMissing
+	}
+	info := vm.tokenView.LookupToken(id)
+	if info == nil {
+		return nil, fmt.Errorf("token %v is not registered", id)
+	}
+	return info, nil
+}
+
+// tokenInputAmount returns the amount of the token spent by the input, only
+// from the script when it is not nil.
+func (vm *Engine) tokenInputAmount(idx int, id types.CoinID, pkScript []byte) (int64, error) {
+	if vm.tokenView == nil {
+		return 0, ErrTokenViewMissing
+	}
+	txIn := vm.tx.TxIn[idx]
+	if types.IsMeerEVMForkInput(txIn) {
+		return 0, nil
+	}
+	amount, prevScript, ok := vm.tokenView.LookupOutput(txIn.PreviousOut)
+	if !ok {
+		return 0, fmt.Errorf("unable to find the previous output of input %d", idx)
+	}
+	if amount.Id != id {
+		return 0, nil
+	}
+	if pkScript != nil && !bytes.Equal(prevScript, pkScript) {
+		return 0, nil
+	}
+	return amount.Value, nil
+}
+
+// tokenInputsAmount returns the amount of the token spent by the transaction,
+// only from the script when it is not nil.  The first input of a mint or
+// unmint transaction is the token owners, which doesn't spend any output.
+func (vm *Engine) tokenInputsAmount(id types.CoinID, pkScript []byte) (int64, error) {
+	start := 0
+	if types.IsTokenMintTx(&vm.tx) || types.IsTokenUnmintTx(&vm.tx) {
+		start = 1
+	}
+	total := int64(0)
+	for idx := start; idx < len(vm.tx.TxIn); idx++ {
+		amount, err := vm.tokenInputAmount(idx, id, pkScript)
+		if err != nil {
+			return 0, err
+		}
+		total += amount
+	}
+	return total, nil
+}
+
+// tokenOutputsAmount returns the amount of the token paid by the transaction,
+// only to the script when it is not nil.
+func (vm *Engine) tokenOutputsAmount(id types.CoinID, pkScript []byte) int64 {
+	total := int64(0)
+	for _, txOut := range vm.tx.TxOut {
+		if txOut.Amount.Id != id {
+			continue
+		}
+		if pkScript != nil && !bytes.Equal(txOut.PkScript, pkScript) {
+			continue
+		}
+		total += txOut.Amount.Value
+	}
+	return total
+}
+
+// checkTokenOwner checks the token of the owners script.
+func (vm *Engine) checkTokenOwner() error {
+	id, err := vm.peekTokenId(3)
+	if err != nil {
+		return err
+	}
+	if _, err := vm.lookupToken(id); err != nil {
+		return err
+	}
+	if types.IsTokenMintTx(&vm.tx) && vm.tx.TxOut[0].Amount.Id != id {
+		return fmt.Errorf("the owners of token %v can't mint token %v",
+			id, vm.tx.TxOut[0].Amount.Id)
+	}
+	return nil
+}
+
+// checkTokenOp checks the transaction against the token opcode with the
+// coin id and the amount on the data stack.
+func (vm *Engine) checkTokenOp(opcode byte) error {
+	if opcode == OP_TOKEN {
+		return vm.checkTokenOwner()
+	}
+	amount, err := vm.peekTokenAmount(0)
+	if err != nil {
+		return err
+	}
+	id, err := vm.peekTokenId(1)
+	if err != nil {
+		return err
+	}
+	info, err := vm.lookupToken(id)
+	if err != nil {
+		return err
+	}
+
+	switch opcode {
+	case OP_TOKEN_MINT:
+		if !types.IsTokenMintTx(&vm.tx) || vm.tx.TxOut[0].Amount.Id != id {
+			return fmt.Errorf("the transaction doesn't mint token %v", id)
+		}
+		if !info.Enable {
+			return fmt.Errorf("token %v is disabled", id)
+		}
+		minted := vm.tokenOutputsAmount(id, nil)
+		if minted > amount {
+			return fmt.Errorf("the mint %d of token %v exceeds %d", minted, id, amount)
+		}
+		if uint64(info.Balance+minted) > info.UpLimit {
+			return fmt.Errorf("the mint %d of token %v exceeds the upper limit %d",
+				minted, id, info.UpLimit)
+		}
+
+	case OP_TOKEN_UNMINT, OP_TOKEN_DESTORY:
+		if !types.IsTokenUnmintTx(&vm.tx) {
+			return fmt.Errorf("the transaction doesn't unmint token %v", id)
+		}
+		in, err := vm.tokenInputsAmount(id, nil)
+		if err != nil {
+			return err
+		}
+		burned := in - vm.tokenOutputsAmount(id, nil)
+		if burned <= 0 {
+			return fmt.Errorf("the transaction doesn't unmint token %v", id)
+		}
+		if opcode == OP_TOKEN_DESTORY {
+			if burned < amount {
+				return fmt.Errorf("the unmint %d of token %v is less than %d",
+					burned, id, amount)
+			}
+			break
+		}
+		if burned > amount {
+			return fmt.Errorf("the unmint %d of token %v exceeds %d", burned, id, amount)
+		}
+		if burned > info.Balance {
+			return fmt.Errorf("the unmint %d of token %v exceeds the balance %d",
+				burned, id, info.Balance)
+		}
+
+	case OP_TOKEN_RELEASE, OP_TOKEN_CHANGE:
+		pkScript, err := unparseScript(vm.scripts[1])
+		if err != nil {
+			return err
+		}
+		change := vm.tokenOutputsAmount(id, pkScript)
+		if opcode == OP_TOKEN_CHANGE {
+			if change < amount {
+				return fmt.Errorf("the change %d of token %v is less than %d",
+					change, id, amount)
+			}
+			break
+		}
+		// Every input which spends the script counts the same change,
+		// so the release is of all of them together.
+		in, err := vm.tokenInputsAmount(id, pkScript)
+		if err != nil {
+			return err
+		}
+		if in-change > amount {
+			return fmt.Errorf("the release %d of token %v exceeds %d", in-change, id, amount)
+		}
+	}
+	return nil
+}
@@ -0,0 +1,168 @@
+// Copyright (c) 2017-2020 The qitmeer developers
+// Use of this source code is governed by an ISC
+// license that can be found in the LICENSE file.
+package txscript
+
+import (
+	"github.com/Qitmeer/qng/common/hash"
+	"github.com/Qitmeer/qng/core/types"
+	"testing"
+)
+
+type testTokenView struct {
+	tokens  map[types.CoinID]*TokenInfo
+	amounts map[types.TxOutPoint]types.Amount
+	scripts map[types.TxOutPoint][]byte
+}
+
+func (tv *testTokenView) LookupToken(id types.CoinID) *TokenInfo {
+	return tv.tokens[id]
+}
+
+func (tv *testTokenView) LookupOutput(outpoint types.TxOutPoint) (types.Amount, []byte, bool) {
+	amount, ok := tv.amounts[outpoint]
+	return amount, tv.scripts[outpoint], ok
+}
+
+func mustScript(t *testing.T, builder *ScriptBuilder) []byte {
+	script, err := builder.Script()
+	if err != nil {
+		t.Fatal(err)
+	}
+	return script
+}
+
+func TestTokenOpcodes(t *testing.T) {
+	const tokenId = types.CoinID(1)
+	view := &testTokenView{
+		tokens: map[types.CoinID]*TokenInfo{
+			tokenId: {UpLimit: 1000, Enable: true, Balance: 800},
+		},
+		amounts: map[types.TxOutPoint]types.Amount{},
+		scripts: map[types.TxOutPoint][]byte{},
+	}
+	other := mustScript(t, NewScriptBuilder().AddOp(OP_TRUE))
+	flags := ScriptVerifyMinimalData | ScriptVerifyTokenOpcodes
+
+	newTx := func(txType types.TxType) *types.Transaction {
+		tx := types.NewTransaction()
+		if txType != types.TxTypeRegular {
+			in := types.NewTxInput(types.NewOutPoint(&hash.Hash{}, types.SupperPrevOutIndex), nil)
+			in.Sequence = uint32(txType)
+			tx.AddTxIn(in)
+		}
+		return tx
+	}
+	spend := func(tx *types.Transaction, amount types.Amount, pkScript []byte) int {
+		outpoint := types.NewOutPoint(&hash.Hash{byte(len(tx.TxIn) + 1)}, 0)
+		view.amounts[*outpoint] = amount
+		view.scripts[*outpoint] = pkScript
+		tx.AddTxIn(types.NewTxInput(outpoint, nil))
+		return len(tx.TxIn) - 1
+	}
+	execute := func(pkScript []byte, tx *types.Transaction, idx int,
+		flags ScriptFlags, view TokenView) error {
+		vm, err := NewEngine(pkScript, tx, idx, flags, 0, nil)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if view != nil {
+			vm.SetTokenView(view)
+		}
+		return vm.Execute()
+	}
+
+	// Vesting: release at most 100 of the token, and pay the rest back.
+	release := mustScript(t, NewScriptBuilder().AddInt64(int64(tokenId)).AddInt64(100).
+		AddOp(OP_TOKEN_RELEASE).AddOp(OP_2DROP).AddOp(OP_TRUE))
+	tx := newTx(types.TxTypeRegular)
+	idx := spend(tx, types.Amount{Id: tokenId, Value: 150}, release)
+	tx.AddTxOut(types.NewTxOutput(types.Amount{Id: tokenId, Value: 100}, other))
+	tx.AddTxOut(types.NewTxOutput(types.Amount{Id: tokenId, Value: 50}, release))
+	if err := execute(release, tx, idx, flags, view); err != nil {
+		t.Fatalf("release: %v", err)
+	}
+	tx.TxOut[0].Amount.Value, tx.TxOut[1].Amount.Value = 101, 49
+	if err := execute(release, tx, idx, flags, view); err == nil {
+		t.Fatalf("expected the release over the limit to fail")
+	}
+	if err := execute(release, tx, idx, flags&^ScriptVerifyTokenOpcodes, view); err != nil {
+		t.Fatalf("expected the opcode to be a NOP without the flag: %v", err)
+	}
+	if err := execute(release, tx, idx, flags, nil); err != ErrTokenViewMissing {
+		t.Fatalf("unexpected error without the token state: %v", err)
+	}
+
+	// Several inputs of the script share the limit and the change: two
+	// inputs of 100 with a change of 100 release 100, and 150 is over it.
+	multi := newTx(types.TxTypeRegular)
+	spend(multi, types.Amount{Id: tokenId, Value: 100}, release)
+	spend(multi, types.Amount{Id: tokenId, Value: 100}, release)
+	multi.AddTxOut(types.NewTxOutput(types.Amount{Id: tokenId, Value: 100}, other))
+	multi.AddTxOut(types.NewTxOutput(types.Amount{Id: tokenId, Value: 100}, release))
+	for i := range multi.TxIn {
+		if err := execute(release, multi, i, flags, view); err != nil {
+			t.Fatalf("release input %d: %v", i, err)
+		}
+	}
+	multi.TxOut[0].Amount.Value, multi.TxOut[1].Amount.Value = 150, 50
+	for i := range multi.TxIn {
+		if err := execute(release, multi, i, flags, view); err == nil {
+			t.Fatalf("expected the release of input %d over the limit to fail", i)
+		}
+	}
+
+	// Change: pay at least 50 of the token back.
+	change := mustScript(t, NewScriptBuilder().AddInt64(int64(tokenId)).AddInt64(50).
+		AddOp(OP_TOKEN_CHANGE).AddOp(OP_2DROP).AddOp(OP_TRUE))
+	tx.TxOut[1].PkScript = change
+	if err := execute(change, tx, idx, flags, view); err == nil {
+		t.Fatalf("expected the change under the limit to fail")
+	}
+	tx.TxOut[0].Amount.Value, tx.TxOut[1].Amount.Value = 100, 50
+	if err := execute(change, tx, idx, flags, view); err != nil {
+		t.Fatalf("change: %v", err)
+	}
+
+	// Mint: the owners mint at most 100 of their token.
+	owners := mustScript(t, NewScriptBuilder().AddInt64(int64(tokenId)).AddInt64(1000).
+		AddData([]byte("TKN")).AddInt64(0).AddOp(OP_TOKEN).AddOp(OP_2DROP).AddOp(OP_2DROP).
+		AddInt64(int64(tokenId)).AddInt64(100).AddOp(OP_TOKEN_MINT).AddOp(OP_2DROP).AddOp(OP_TRUE))
+	tx = newTx(types.TxTypeTokenMint)
+	spend(tx, types.Amount{Id: types.MEERA, Value: 1000}, other)
+	tx.AddTxOut(types.NewTxOutput(types.Amount{Id: tokenId, Value: 100}, other))
+	if err := execute(owners, tx, 0, flags, view); err != nil {
+		t.Fatalf("mint: %v", err)
+	}
+	tx.TxOut[0].Amount.Value = 101
+	if err := execute(owners, tx, 0, flags, view); err == nil {
+		t.Fatalf("expected the mint over the limit to fail")
+	}
+	tx.TxOut[0].Amount.Value = 100
+	view.tokens[tokenId].Balance = 950
+	if err := execute(owners, tx, 0, flags, view); err == nil {
+		t.Fatalf("expected the mint over the upper limit to fail")
+	}
+	view.tokens[tokenId].Balance = 800
+	view.tokens[tokenId+1] = &TokenInfo{UpLimit: 1000, Enable: true}
+	tx.TxOut[0].Amount.Id = tokenId + 1
+	if err := execute(owners, tx, 0, flags, view); err == nil {
+		t.Fatalf("expected the owners to fail to mint another token")
+	}
+
+	// Burn: the output can only be unminted.
+	burn := mustScript(t, NewScriptBuilder().AddInt64(int64(tokenId)).AddInt64(50).
+		AddOp(OP_TOKEN_DESTORY).AddOp(OP_2DROP).AddOp(OP_TRUE))
+	tx = newTx(types.TxTypeTokenUnmint)
+	idx = spend(tx, types.Amount{Id: tokenId, Value: 80}, burn)
+	tx.AddTxOut(types.NewTxOutput(types.Amount{Id: types.MEERA, Value: 1000}, other))
+	if err := execute(burn, tx, idx, flags, view); err != nil {
+		t.Fatalf("burn: %v", err)
+	}
+	tx = newTx(types.TxTypeRegular)
+	idx = spend(tx, types.Amount{Id: tokenId, Value: 80}, burn)
+	tx.AddTxOut(types.NewTxOutput(types.Amount{Id: tokenId, Value: 80}, other))
+	if err := execute(burn, tx, idx, flags, view); err == nil {
+		t.Fatalf("expected the transfer of the burn output to fail")
+	}
+}
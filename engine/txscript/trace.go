@@ -0,0 +1,111 @@
+// Copyright (c) 2017-2020 The qitmeer developers
+// Use of this source code is governed by an ISC
+// license that can be found in the LICENSE file.
+package txscript
+
+// TraceStep is the execution of an opcode which the engine records in the
+// trace mode.  The stacks list the top item last, the conditional stacks
+// list the state of the innermost branch last.
+type TraceStep struct {
+	// ScriptIdx is the index of the script, 0 is the signature script, 1
+	// is the public key script and 2 is the redeem script of a
+	// pay-to-script-hash input.
+	ScriptIdx int
+	ScriptOff int
+	Opcode    string
+
+	// Executed is whether the opcode is in an executing branch.
+	Executed bool
+
+	StackBefore     [][]byte
+	AltStackBefore  [][]byte
+	CondStackBefore []string
+	StackAfter      [][]byte
+	AltStackAfter   [][]byte
+	CondStackAfter  []string
+
+	// Err is the error of the opcode, which stops the execution.
+	Err error
+}
+
+// TraceEnd is the opcode of the step which records the checks at the end of a
+// script, such as a missing OP_ENDIF, a clean stack or a true top of the stack.
+// The step is only recorded when a check fails.
+const TraceEnd = "END"
+
+// condNames are the names of the conditional execution states.
+var condNames = map[int]string{
+	OpCondFalse: "false",
+	OpCondTrue:  "true",
+	OpCondSkip:  "skip",
+}
+
+func (vm *Engine) condStackNames() []string {
+	names := make([]string, len(vm.condStack))
+	for i, cond := range vm.condStack {
+		names[i] = condNames[cond]
+	}
+	return names
+}
+
+// EnableTrace turns on the trace mode, in which every executed opcode is
+// recorded with the stacks before and after it.
+func (vm *Engine) EnableTrace() {
+	vm.tracing = true
+	vm.trace = []*TraceStep{}
+}
+
+// Trace returns the steps recorded in the trace mode.
+func (vm *Engine) Trace() []*TraceStep {
+	return vm.trace
+}
+
+// beginTraceStep records the state of the engine before the opcode executes.
+func (vm *Engine) beginTraceStep(pop *ParsedOpcode) *TraceStep {
+	step := &TraceStep{
+		ScriptIdx:       vm.scriptIdx,
+		ScriptOff:       vm.scriptOff,
+		Opcode:          pop.print(false),
+		Executed:        vm.isBranchExecuting(),
+		StackBefore:     vm.GetStack(),
+		AltStackBefore:  vm.GetAltStack(),
+		CondStackBefore: vm.condStackNames(),
+	}
+	vm.trace = append(vm.trace, step)
+	return step
+}
+
+// endTraceStep records the state of the engine after the opcode executes.
+func (vm *Engine) endTraceStep(step *TraceStep, err error) {
+	step.StackAfter = vm.GetStack()
+	step.AltStackAfter = vm.GetAltStack()
+	step.CondStackAfter = vm.condStackNames()
+	step.Err = err
+}
+
+// beginTraceEnd records the state of the engine before the checks at the end of
+// the script, or returns nil out of the trace mode.
+func (vm *Engine) beginTraceEnd(scriptIdx int) *TraceStep {
+	if !vm.tracing {
+		return nil
+	}
+	return &TraceStep{
+		ScriptIdx:       scriptIdx,
+		ScriptOff:       len(vm.scripts[scriptIdx]),
+		Opcode:          TraceEnd,
+		Executed:        true,
+		StackBefore:     vm.GetStack(),
+		AltStackBefore:  vm.GetAltStack(),
+		CondStackBefore: vm.condStackNames(),
+	}
+}
+
+// endTraceEnd records the failed checks at the end of the script as the last
+// step.
+func (vm *Engine) endTraceEnd(step *TraceStep, err error) {
+	if step == nil || err == nil {
+		return
+	}
+	vm.endTraceStep(step, err)
+	vm.trace = append(vm.trace, step)
+}
@@ -0,0 +1,120 @@
+// Copyright (c) 2017-2020 The qitmeer developers
+// Use of this source code is governed by an ISC
+// license that can be found in the LICENSE file.
+package txscript
+
+import (
+	"github.com/Qitmeer/qng/common/hash"
+	"github.com/Qitmeer/qng/core/types"
+	"testing"
+)
+
+func TestTrace(t *testing.T) {
+	tx := types.NewTransaction()
+	tx.AddTxIn(types.NewTxInput(types.NewOutPoint(&hash.Hash{0x01}, 0), []byte{OP_0}))
+	pkScript := []byte{OP_IF, OP_2, OP_ELSE, OP_3, OP_TOALTSTACK, OP_FROMALTSTACK, OP_ENDIF, OP_3, OP_EQUAL}
+
+	vm, err := NewEngine(pkScript, tx, 0, ScriptVerifyMinimalData, 0, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	vm.EnableTrace()
+	err = vm.Execute()
+	if err != nil {
+		t.Fatal(err)
+	}
+	steps := vm.Trace()
+	if len(steps) != 10 {
+		t.Fatalf("expected 10 steps, got %d", len(steps))
+	}
+	if steps[0].ScriptIdx != 0 || steps[0].Opcode != "OP_0" ||
+		len(steps[0].StackBefore) != 0 || len(steps[0].StackAfter) != 1 {
+		t.Fatalf("unexpected step of the signature script: %+v", steps[0])
+	}
+	// OP_IF pops the false and skips its branch.
+	if steps[1].ScriptIdx != 1 || steps[1].Opcode != "OP_IF" ||
+		len(steps[1].CondStackAfter) != 1 || steps[1].CondStackAfter[0] != "false" {
+		t.Fatalf("unexpected step of OP_IF: %+v", steps[1])
+	}
+	if steps[2].Executed || len(steps[2].StackAfter) != 0 {
+		t.Fatalf("expected OP_2 not to execute: %+v", steps[2])
+	}
+	if !steps[4].Executed || steps[4].CondStackBefore[0] != "true" {
+		t.Fatalf("expected OP_3 to execute: %+v", steps[4])
+	}
+	if len(steps[5].StackAfter) != 0 || len(steps[5].AltStackAfter) != 1 {
+		t.Fatalf("unexpected stacks of OP_TOALTSTACK: %+v", steps[5])
+	}
+	if len(steps[7].CondStackAfter) != 0 {
+		t.Fatalf("expected OP_ENDIF to close the branch: %+v", steps[7])
+	}
+
+	// The failed opcode is the last step.
+	pkScript = []byte{OP_DROP, OP_RETURN, OP_TRUE}
+	vm, err = NewEngine(pkScript, tx, 0, 0, 0, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	vm.EnableTrace()
+	err = vm.Execute()
+	if err != ErrStackEarlyReturn {
+		t.Fatalf("expected %v, got %v", ErrStackEarlyReturn, err)
+	}
+	steps = vm.Trace()
+	last := steps[len(steps)-1]
+	if last.Opcode != "OP_RETURN" || last.Err != ErrStackEarlyReturn {
+		t.Fatalf("unexpected last step: %+v", last)
+	}
+}
+
+func TestTraceEnd(t *testing.T) {
+	redeemScript := []byte{OP_TRUE, OP_NOT}
+	p2sh, err := payToScriptHashScript(hash.Hash160(redeemScript))
+	if err != nil {
+		t.Fatal(err)
+	}
+	pushRedeem, err := NewScriptBuilder().AddData(redeemScript).Script()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		name       string
+		sigScript  []byte
+		pkScript   []byte
+		flags      ScriptFlags
+		err        error
+		scriptIdx  int
+		stackAfter int
+	}{
+		{"false", []byte{OP_0}, []byte{OP_DROP, OP_FALSE}, 0, ErrStackScriptFailed, 1, 0},
+		{"clean stack", []byte{OP_0}, []byte{OP_TRUE}, ScriptBip16 | ScriptVerifyCleanStack, ErrStackCleanStack, 1, 2},
+		{"missing endif", []byte{OP_0}, []byte{OP_TRUE, OP_IF}, 0, ErrStackMissingEndif, 1, 1},
+		{"p2sh hash", []byte{OP_DATA_1, OP_TRUE}, p2sh, ScriptBip16, ErrStackScriptFailed, 1, 0},
+		{"redeem script", pushRedeem, p2sh, ScriptBip16, ErrStackScriptFailed, 2, 0},
+	}
+	for _, test := range tests {
+		tx := types.NewTransaction()
+		tx.AddTxIn(types.NewTxInput(types.NewOutPoint(&hash.Hash{0x01}, 0), test.sigScript))
+		vm, err := NewEngine(test.pkScript, tx, 0, test.flags, 0, nil)
+		if err != nil {
+			t.Fatalf("%s: %v", test.name, err)
+		}
+		vm.EnableTrace()
+		err = vm.Execute()
+		if err != test.err {
+			t.Fatalf("%s: expected %v, got %v", test.name, test.err, err)
+		}
+		steps := vm.Trace()
+		last := steps[len(steps)-1]
+		if last.Opcode != TraceEnd || last.Err != test.err ||
+			last.ScriptIdx != test.scriptIdx || len(last.StackAfter) != test.stackAfter {
+			t.Fatalf("%s: unexpected last step: %+v", test.name, last)
+		}
+		for _, step := range steps[:len(steps)-1] {
+			if step.Err != nil || step.Opcode == TraceEnd {
+				t.Fatalf("%s: unexpected step before the end: %+v", test.name, step)
+			}
+		}
+	}
+}
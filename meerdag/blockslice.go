@@ -68,4 +68,6 @@ func (bs BlockHeightSlice) Swap(i, j int) {
 type BlockOrderHelp struct {
 	OldOrder uint
 	Block    IBlock
+	// OldBlue is whether the block was blue before the reorganization.
+	OldBlue bool
 }
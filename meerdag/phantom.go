@@ -301,6 +301,17 @@ func (ph *Phantom) updateMainChain(buestTip *PhantomBlock, pb *PhantomBlock) (*P
 		panic("DAG can't find intersection!")
 	}
 
+	// The blocks of the old orders are blue if they are on the main chain
+	// after the intersection or in the blue diff anticone of one of them.
+	oldBlues := NewIdSet()
+	for cur := ph.getBlock(ph.mainChain.tip); cur != nil && cur.GetID() != intersection; cur = ph.getBlock(cur.mainParent) {
+		oldBlues.Add(cur.GetID())
+		oldBlues.AddSet(cur.GetBlueDiffAnticone())
+		if cur.mainParent == MaxId {
+			break
+		}
+	}
+
 	// old orders
 	oldOrders := list.New()
 	for i := intersectionBlock.GetOrder() + 1; i <= ph.GetMainChainTip().GetOrder(); i++ {
@@ -308,7 +319,7 @@ func (ph *Phantom) updateMainChain(buestTip *PhantomBlock, pb *PhantomBlock) (*P
 		if ib == nil {
 			panic(fmt.Errorf("DAG can't find block in order(%d)\n", i))
 		}
-		oldOrders.PushBack(&BlockOrderHelp{OldOrder: i, Block: ib})
+		oldOrders.PushBack(&BlockOrderHelp{OldOrder: i, Block: ib, OldBlue: oldBlues.Has(ib.GetID())})
 	}
 
 	//
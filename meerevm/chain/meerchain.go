@@ -157,24 +157,10 @@ func (b *MeerChain) fillBlock(qtxs []model.Tx, header *types.Header, statedb *st
 
 	for _, tx := range qtxs {
 		if tx.GetTxType() == qtypes.TxTypeCrossChainExport {
-			pubkBytes, err := hex.DecodeString(tx.GetTo())
-			if err != nil {
-				return nil, nil, err
-			}
-			publicKey, err := crypto.UnmarshalPubkey(pubkBytes)
+			etx, err := qcommon.NewCrossChainTx(tx.GetTxType(), tx.GetTo(), tx.GetValue())
 			if err != nil {
 				return nil, nil, err
 			}
-
-			value := big.NewInt(int64(tx.GetValue()))
-			value = value.Mul(value, qcommon.Precision)
-			toAddr := crypto.PubkeyToAddress(*publicKey)
-			txData := &types.AccessListTx{
-				To:    &toAddr,
-				Value: value,
-				Nonce: uint64(tx.GetTxType()),
-			}
-			etx := types.NewTx(txData)
 			txmb, err := etx.MarshalBinary()
 			if err != nil {
 				return nil, nil, err
@@ -184,25 +170,10 @@ func (b *MeerChain) fillBlock(qtxs []model.Tx, header *types.Header, statedb *st
 			}
 			header.Extra = txmb
 		} else if tx.GetTxType() == qtypes.TxTypeCrossChainImport {
-			pubkBytes, err := hex.DecodeString(tx.GetFrom())
+			etx, err := qcommon.NewCrossChainTx(tx.GetTxType(), tx.GetFrom(), tx.GetValue())
 			if err != nil {
 				return nil, nil, err
 			}
-			publicKey, err := crypto.UnmarshalPubkey(pubkBytes)
-			if err != nil {
-				return nil, nil, err
-			}
-
-			toAddr := crypto.PubkeyToAddress(*publicKey)
-
-			value := big.NewInt(int64(tx.GetValue()))
-			value = value.Mul(value, qcommon.Precision)
-			txData := &types.AccessListTx{
-				To:    &toAddr,
-				Value: value,
-				Nonce: uint64(tx.GetTxType()),
-			}
-			etx := types.NewTx(txData)
 			txmb, err := etx.MarshalBinary()
 			if err != nil {
 				return nil, nil, err
@@ -49,6 +49,29 @@ var (
 	Precision = big.NewInt(params.Ether).Div(big.NewInt(params.Ether), big.NewInt(qtypes.AtomsPerCoin))
 )
 
+// NewCrossChainTx returns the MeerEVM transaction which applies an export or
+// an import transaction of the DAG to the account of the public key. It is
+// carried by the extra data of the header of the MeerEVM block.
+func NewCrossChainTx(txType qtypes.TxType, pubkeyHex string, value uint64) (*types.Transaction, error) {
+	pubkBytes, err := hex.DecodeString(pubkeyHex)
+	if err != nil {
+		return nil, err
+	}
+	publicKey, err := crypto.UnmarshalPubkey(pubkBytes)
+	if err != nil {
+		return nil, err
+	}
+	toAddr := crypto.PubkeyToAddress(*publicKey)
+	v := big.NewInt(int64(value))
+	v = v.Mul(v, Precision)
+	txData := &types.AccessListTx{
+		To:    &toAddr,
+		Value: v,
+		Nonce: uint64(txType),
+	}
+	return types.NewTx(txData), nil
+}
+
 func CopyReceipts(receipts []*types.Receipt) []*types.Receipt {
 	result := make([]*types.Receipt, len(receipts))
 	for i, l := range receipts {
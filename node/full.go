@@ -16,11 +16,13 @@ import (
 	"github.com/Qitmeer/qng/services/acct"
 	"github.com/Qitmeer/qng/services/address"
 	"github.com/Qitmeer/qng/services/common"
+	"github.com/Qitmeer/qng/services/graphql"
 	"github.com/Qitmeer/qng/services/mempool"
 	"github.com/Qitmeer/qng/services/miner"
 	"github.com/Qitmeer/qng/services/mining"
 	"github.com/Qitmeer/qng/services/notifymgr"
 	"github.com/Qitmeer/qng/services/tx"
+	"github.com/Qitmeer/qng/services/wallet"
 	"github.com/Qitmeer/qng/vm"
 	"github.com/Qitmeer/qng/vm/consensus"
 	"reflect"
@@ -64,6 +66,14 @@ func (qm *QitmeerFull) RegisterRpcService() error {
 	}
 	qm.Services().RegisterService(rpcServer)
 
+	if qm.node.Config.GraphQL {
+		handler, err := graphql.New(qm.node.consensus, qm.GetTxManager().MemPool().(*mempool.TxPool))
+		if err != nil {
+			return err
+		}
+		rpcServer.RegisterHandler(graphql.PathPrefix, handler)
+	}
+
 	go func() {
 		<-rpcServer.RequestedProcessShutdown()
 		system.ShutdownRequestChannel <- struct{}{}
@@ -139,10 +149,23 @@ func (qm *QitmeerFull) RegisterAccountService(cfg *config.Config) error {
 		return err
 	}
 	qm.GetBlockChain().Acct = acctmgr
+	if nfManager, ok := qm.nfManager.(*notifymgr.NotifyMgr); ok {
+		acctmgr.LockMaturedNotify = nfManager.LockMatured
+	}
 	qm.Services().RegisterService(acctmgr)
 	return nil
 }
 
+func (qm *QitmeerFull) RegisterWalletService(cfg *config.Config) error {
+	// wallet
+	walletmgr, err := wallet.New(cfg, qm.GetBlockChain(), qm.GetAccountManager(), qm.GetTxManager().MemPool().(*mempool.TxPool), qm.nfManager)
+	if err != nil {
+		return err
+	}
+	qm.Services().RegisterService(walletmgr)
+	return nil
+}
+
 func (qm *QitmeerFull) RegisterVMService(vmService *vm.Service) error {
 	return qm.Services().RegisterService(vmService)
 }
@@ -190,6 +213,15 @@ func (qm *QitmeerFull) GetVMService() *vm.Service {
 	return service
 }
 
+func (qm *QitmeerFull) GetAccountManager() *acct.AccountManager {
+	var service *acct.AccountManager
+	if err := qm.Services().FetchService(&service); err != nil {
+		log.Error(err.Error())
+		return nil
+	}
+	return service
+}
+
 func (qm *QitmeerFull) GetMiner() *miner.Miner {
 	var service *miner.Miner
 	if err := qm.Services().FetchService(&service); err != nil {
@@ -257,6 +289,9 @@ func newQitmeerFullNode(node *Node) (*QitmeerFull, error) {
 	if err := qm.RegisterAccountService(cfg); err != nil {
 		return nil, err
 	}
+	if err := qm.RegisterWalletService(cfg); err != nil {
+		return nil, err
+	}
 
 	if err := qm.RegisterRpcService(); err != nil {
 		return nil, err
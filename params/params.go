@@ -261,6 +261,10 @@ type Params struct {
 	// for any given address encoded as a string.
 	NetworkAddressPrefix string
 
+	// Bech32HRP is the human-readable part of the bech32m encoded
+	// addresses of the network.
+	Bech32HRP string
+
 	// Address encoding magics
 	PubKeyAddrID     [2]byte // First 2 bytes of a P2PK address
 	PubKeyHashAddrID [2]byte // First 2 bytes of P2PKH address
@@ -123,6 +123,7 @@ var MainNetParams = Params{
 
 	// Address encoding magics
 	NetworkAddressPrefix: "M",
+	Bech32HRP:            "meer",
 	PubKeyAddrID:         [2]byte{0x1f, 0xc5}, // starts with Mk
 	PubKeyHashAddrID:     [2]byte{0x0b, 0xb1}, // starts with Mm
 	PKHEdwardsAddrID:     [2]byte{0x0b, 0x9f}, // starts with Me
@@ -159,3 +159,26 @@ func PKAddressToETHAddressSTDO(pkaddr string) {
 	}
 	EcPubKeyToETHAddressSTDO(hex.EncodeToString(pka.PubKey().SerializeUncompressed()))
 }
+
+// AddrToBech32 converts a base58 address to the bech32m encoding of the same
+// address.
+func AddrToBech32(addr string) (string, error) {
+	a, err := address.DecodeAddress(addr)
+	if err != nil {
+		return "", err
+	}
+	return address.EncodeBech32(a)
+}
+
+// Bech32ToAddr converts a bech32m address to the base58 encoding of the same
+// address.
+func Bech32ToAddr(addr string) (string, error) {
+	if !address.IsBech32Address(addr) {
+		return "", fmt.Errorf("%s is not bech32 address", addr)
+	}
+	a, err := address.DecodeAddress(addr)
+	if err != nil {
+		return "", err
+	}
+	return a.String(), nil
+}
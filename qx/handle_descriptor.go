@@ -0,0 +1,79 @@
+package qx
+
+import (
+	"encoding/json"
+	"fmt"
+	"github.com/Qitmeer/qng/engine/txscript"
+	"github.com/Qitmeer/qng/params"
+	"strings"
+)
+
+func parseDescriptor(network string, descriptor string) (*txscript.Descriptor, error) {
+	var param *params.Params
+	switch network {
+	case "mainnet":
+		param = &params.MainNetParams
+	case "testnet":
+		param = &params.TestNetParams
+	case "privnet":
+		param = &params.PrivNetParams
+	case "mixnet":
+		param = &params.MixNetParams
+	default:
+		return nil, fmt.Errorf("invalid network (mainnet|testnet|privnet|mixnet)")
+	}
+	return txscript.ParseDescriptor(descriptor, false, param)
+}
+
+// DescriptorInfo returns the json of the descriptor with its checksum and
+// whether it is ranged.
+func DescriptorInfo(network string, descriptor string) (string, error) {
+	d, err := parseDescriptor(network, descriptor)
+	if err != nil {
+		return "", err
+	}
+	marshaled, err := json.Marshal(map[string]interface{}{
+		"descriptor":     d.String(),
+		"checksum":       d.Checksum(),
+		"isrange":        d.IsRange(),
+		"hasprivatekeys": d.HasPrivateKeys(),
+	})
+	if err != nil {
+		return "", err
+	}
+	return string(marshaled), nil
+}
+
+// DescriptorDerive returns the addresses of the descriptor, one per line, from
+// the begin to the end of the range for a ranged descriptor.
+func DescriptorDerive(network string, descriptor string, begin uint32, end uint32) (string, error) {
+	d, err := parseDescriptor(network, descriptor)
+	if err != nil {
+		return "", err
+	}
+	addrs, err := d.Addresses(begin, end)
+	if err != nil {
+		return "", err
+	}
+	result := make([]string, 0, len(addrs))
+	for _, addr := range addrs {
+		result = append(result, addr.String())
+	}
+	return strings.Join(result, "\n"), nil
+}
+
+func DescriptorInfoSTDO(network string, descriptor string) {
+	result, err := DescriptorInfo(network, descriptor)
+	if err != nil {
+		ErrExit(err)
+	}
+	fmt.Printf("%s\n", result)
+}
+
+func DescriptorDeriveSTDO(network string, descriptor string, begin uint32, end uint32) {
+	result, err := DescriptorDerive(network, descriptor, begin, end)
+	if err != nil {
+		ErrExit(err)
+	}
+	fmt.Printf("%s\n", result)
+}
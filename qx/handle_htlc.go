@@ -0,0 +1,158 @@
+package qx
+
+import (
+	"bytes"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"github.com/Qitmeer/qng/common/marshal"
+	"github.com/Qitmeer/qng/core/address"
+	"github.com/Qitmeer/qng/core/types"
+	"github.com/Qitmeer/qng/crypto/ecc"
+	"github.com/Qitmeer/qng/engine/txscript"
+	"github.com/Qitmeer/qng/params"
+	"strings"
+)
+
+func decodeHTLCAddress(addrStr string) ([]byte, error) {
+	addr, err := address.DecodeAddress(addrStr)
+	if err != nil {
+		return nil, err
+	}
+	if _, ok := addr.(*address.PubKeyHashAddress); !ok {
+		return nil, fmt.Errorf("not a pay-to-pubkey-hash address: %s", addrStr)
+	}
+	return addr.Hash160()[:], nil
+}
+
+// HTLCNew returns the json of a new hash time-locked contract, which is locked
+// by the secret hash, or by the hash of the secret when it is given.
+func HTLCNew(network string, recipient string, refund string, secretHashStr string, secretStr string, lockTime int64, hashType string) (string, error) {
+	var param *params.Params
+	switch network {
+	case "mainnet":
+		param = &params.MainNetParams
+	case "testnet":
+		param = &params.TestNetParams
+	case "privnet":
+		param = &params.PrivNetParams
+	case "mixnet":
+		param = &params.MixNetParams
+	default:
+		ErrExit(fmt.Errorf("invalid network (mainnet|testnet|privnet|mixnet)"))
+	}
+	recipientHash, err := decodeHTLCAddress(recipient)
+	if err != nil {
+		return "", err
+	}
+	refundHash, err := decodeHTLCAddress(refund)
+	if err != nil {
+		return "", err
+	}
+	hashOp, err := txscript.ParseHTLCHashOp(hashType)
+	if err != nil {
+		return "", err
+	}
+	var secretHash []byte
+	if len(secretStr) > 0 {
+		secret, err := hex.DecodeString(secretStr)
+		if err != nil {
+			return "", err
+		}
+		if len(secret) != txscript.HTLCSecretSize {
+			return "", fmt.Errorf("invalid secret length %d", len(secret))
+		}
+		secretHash, err = txscript.HTLCSecretHash(hashOp, secret)
+		if err != nil {
+			return "", err
+		}
+	} else {
+		secretHash, err = hex.DecodeString(secretHashStr)
+		if err != nil {
+			return "", err
+		}
+	}
+	contract, err := txscript.PayToHTLCScript(hashOp, secretHash, recipientHash, refundHash, lockTime)
+	if err != nil {
+		return "", err
+	}
+	result, err := marshal.MarshalJsonHTLC(contract, param)
+	if err != nil {
+		return "", err
+	}
+	marshaled, err := json.Marshal(result)
+	if err != nil {
+		return "", err
+	}
+	return string(marshaled), nil
+}
+
+// htlcSign signs the input of the raw transaction which spends the hash
+// time-locked contract, it redeems the contract with the secret, otherwise
+// refunds it.
+func htlcSign(privkeyStr string, rawTxStr string, index int, contractStr string, secret []byte, p2sh bool) (string, error) {
+	privkeyByte, err := hex.DecodeString(privkeyStr)
+	if err != nil {
+		return "", err
+	}
+	if len(privkeyByte) != 32 {
+		return "", fmt.Errorf("invaid ec private key bytes: %d", len(privkeyByte))
+	}
+	privateKey, _ := ecc.Secp256k1.PrivKeyFromBytes(privkeyByte)
+	contract, err := hex.DecodeString(contractStr)
+	if err != nil {
+		return "", err
+	}
+	rawTxStr = strings.Split(rawTxStr, MTX_STR_SEPERATE)[0]
+	serializedTx, err := hex.DecodeString(rawTxStr)
+	if err != nil {
+		return "", err
+	}
+	var tx types.Transaction
+	err = tx.Deserialize(bytes.NewReader(serializedTx))
+	if err != nil {
+		return "", err
+	}
+	sigScript, err := txscript.SignHTLC(&tx, index, contract, txscript.SigHashAll, privateKey, secret, p2sh)
+	if err != nil {
+		return "", err
+	}
+	tx.TxIn[index].SignScript = sigScript
+	return marshal.MessageToHex(&tx)
+}
+
+func HTLCRedeem(privkeyStr string, rawTxStr string, index int, contractStr string, secretStr string, p2sh bool) (string, error) {
+	secret, err := hex.DecodeString(secretStr)
+	if err != nil {
+		return "", err
+	}
+	return htlcSign(privkeyStr, rawTxStr, index, contractStr, secret, p2sh)
+}
+
+func HTLCRefund(privkeyStr string, rawTxStr string, index int, contractStr string, p2sh bool) (string, error) {
+	return htlcSign(privkeyStr, rawTxStr, index, contractStr, nil, p2sh)
+}
+
+func HTLCNewSTDO(network string, recipient string, refund string, secretHashStr string, secretStr string, lockTime int64, hashType string) {
+	result, err := HTLCNew(network, recipient, refund, secretHashStr, secretStr, lockTime, hashType)
+	if err != nil {
+		ErrExit(err)
+	}
+	fmt.Printf("%s\n", result)
+}
+
+func HTLCRedeemSTDO(privkeyStr string, rawTxStr string, index int, contractStr string, secretStr string, p2sh bool) {
+	result, err := HTLCRedeem(privkeyStr, rawTxStr, index, contractStr, secretStr, p2sh)
+	if err != nil {
+		ErrExit(err)
+	}
+	fmt.Printf("%s\n", result)
+}
+
+func HTLCRefundSTDO(privkeyStr string, rawTxStr string, index int, contractStr string, p2sh bool) {
+	result, err := HTLCRefund(privkeyStr, rawTxStr, index, contractStr, p2sh)
+	if err != nil {
+		ErrExit(err)
+	}
+	fmt.Printf("%s\n", result)
+}
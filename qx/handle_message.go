@@ -7,6 +7,7 @@ import (
 	"github.com/Qitmeer/qng/common/encode/base58"
 	"github.com/Qitmeer/qng/common/hash"
 	"github.com/Qitmeer/qng/common/hash/btc"
+	"github.com/Qitmeer/qng/core/address"
 	"github.com/Qitmeer/qng/core/serialization"
 	"github.com/Qitmeer/qng/crypto/ecc"
 	"github.com/Qitmeer/qng/crypto/ecc/secp256k1"
@@ -15,7 +16,7 @@ import (
 
 const (
 	BTCMsgSignaturePrefixMagic     = "Bitcoin Signed Message:\n"
-	QitmeerMsgSignaturePrefixMagic = "Qitmeer Signed Message:\n"
+	QitmeerMsgSignaturePrefixMagic = address.MessageSignaturePrefixMagic
 )
 
 func DecodeSignature(signatureType string, signStr string) {
@@ -41,6 +42,22 @@ func DecodeAddr(mode string, addrStr string) ([]byte, error) {
 	}
 }
 func VerifyMsgSignature(mode string, addrStr string, signStr string, msgStr string) {
+	if mode != "btc" {
+		addr, err := address.DecodeAddress(addrStr)
+		if err != nil {
+			ErrExit(err)
+		}
+		sign, err := base64.StdEncoding.DecodeString(signStr)
+		if err != nil {
+			ErrExit(err)
+		}
+		ok, err := address.VerifyMessage(addr, sign, msgStr)
+		if err != nil {
+			ErrExit(err)
+		}
+		fmt.Printf("%v\n", ok)
+		return
+	}
 
 	msgHash := BuildMsgHash(mode, msgStr)
 
@@ -95,10 +112,7 @@ func BuildMsgHash(mode string, msg string) []byte {
 		msgHash = btc.DoubleHashB(buf.Bytes())
 
 	default:
-		var buf bytes.Buffer
-		serialization.WriteVarString(&buf, 0, QitmeerMsgSignaturePrefixMagic)
-		serialization.WriteVarString(&buf, 0, msg)
-		msgHash = hash.HashB(buf.Bytes())
+		msgHash = address.MessageHash(msg)
 	}
 	return msgHash
 }
@@ -140,3 +154,40 @@ func MsgSign(mode string, showSignDetail bool, wif string, msg string, showDetai
 	}
 
 }
+
+// messageSignDSA returns the signature suite of the scheme of the ed25519 or
+// schnorr message signatures.
+func messageSignDSA(scheme string) (ecc.DSA, ecc.EcType, error) {
+	switch scheme {
+	case "ed25519":
+		return ecc.Ed25519, ecc.EdDSA_Ed25519, nil
+	case "schnorr":
+		return ecc.SecSchnorr, ecc.ECDSA_SecpSchnorr, nil
+	default:
+		return nil, 0, fmt.Errorf("unknown signature scheme %s", scheme)
+	}
+}
+
+// MsgSignWithPubKey returns the message signature in base64 by the ed25519 or
+// schnorr private scalar in the wif, which is the compressed public key
+// followed by the 64 byte signature, since the public key can't be recovered
+// from it.  It is verified against the pubkey hash address of the scheme.
+func MsgSignWithPubKey(scheme string, wif string, msg string) (string, error) {
+	dsa, _, err := messageSignDSA(scheme)
+	if err != nil {
+		return "", err
+	}
+	decoded, _, err := DecodeWIF(wif)
+	if err != nil {
+		return "", err
+	}
+	key, pub := dsa.PrivKeyFromScalar(decoded)
+	if key == nil || pub == nil {
+		return "", fmt.Errorf("invalid %s private key", scheme)
+	}
+	sig, err := address.SignMessageWithPubKey(dsa, key, msg)
+	if err != nil {
+		return "", err
+	}
+	return base64.StdEncoding.EncodeToString(sig), nil
+}
@@ -0,0 +1,304 @@
+package qx
+
+import (
+	"bytes"
+	"crypto/rand"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"github.com/Qitmeer/qng/common/hash"
+	"github.com/Qitmeer/qng/common/marshal"
+	"github.com/Qitmeer/qng/core/address"
+	"github.com/Qitmeer/qng/core/types"
+	"github.com/Qitmeer/qng/crypto/ecc"
+	"github.com/Qitmeer/qng/crypto/ecc/schnorr"
+	"github.com/Qitmeer/qng/crypto/ecc/secp256k1"
+	"github.com/Qitmeer/qng/engine/txscript"
+	"github.com/Qitmeer/qng/params"
+	"os"
+	"strings"
+)
+
+// The secret nonce of MuSig2Nonce is followed by the signature hash which it
+// is bound to, MuSig2Sign refuses to sign any other hash with it.
+const muSig2BoundSecNonceSize = schnorr.MuSig2SecNonceSize + hash.HashSize
+
+// muSig2NonceWarning is printed with every secret nonce.
+const muSig2NonceWarning = "WARNING: the secret nonce is as sensitive as the private key. Sign with it only once, and never " +
+	"again after another aggregated nonce: two partial signatures of the same nonce reveal the private key."
+
+func decodeMuSig2PubKeys(pubkeysStr string) ([]*secp256k1.PublicKey, error) {
+	pks := []*secp256k1.PublicKey{}
+	for _, pkStr := range strings.Split(pubkeysStr, ",") {
+		data, err := hex.DecodeString(strings.TrimSpace(pkStr))
+		if err != nil {
+			return nil, err
+		}
+		pk, err := schnorr.ParsePubKey(secp256k1.S256(), data)
+		if err != nil {
+			return nil, err
+		}
+		pks = append(pks, pk)
+	}
+	return pks, nil
+}
+
+func decodeMuSig2List(listStr string) ([][]byte, error) {
+	list := [][]byte{}
+	for _, s := range strings.Split(listStr, ",") {
+		data, err := hex.DecodeString(strings.TrimSpace(s))
+		if err != nil {
+			return nil, err
+		}
+		list = append(list, data)
+	}
+	return list, nil
+}
+
+// muSig2PkScript returns the schnorr pay-to-pubkey or pay-to-pubkey-hash
+// script of the aggregated key, the network of the address doesn't matter to
+// the script.
+func muSig2PkScript(aggKey *secp256k1.PublicKey, scriptType string) ([]byte, error) {
+	var addr types.Address
+	var err error
+	switch scriptType {
+	case "pubkey":
+		addr, err = address.NewSecSchnorrPubKeyAddress(aggKey.SerializeCompressed(), params.ActiveNetParams.Params)
+	case "pubkeyhash":
+		addr, err = address.NewPubKeyHashAddress(hash.Hash160(aggKey.SerializeCompressed()), params.ActiveNetParams.Params, ecc.ECDSA_SecpSchnorr)
+	default:
+		return nil, fmt.Errorf("invalid script type %s (pubkey|pubkeyhash)", scriptType)
+	}
+	if err != nil {
+		return nil, err
+	}
+	return txscript.PayToAddrScript(addr)
+}
+
+// muSig2Session decodes the raw transaction, and returns the signature hash
+// of its input which spends the output of the aggregated key.
+func muSig2Session(pks []*secp256k1.PublicKey, rawTxStr string, index int, scriptType string) (*types.Transaction, []byte, []byte, *secp256k1.PublicKey, error) {
+	aggKey, err := schnorr.MuSig2AggregatePubKeys(pks)
+	if err != nil {
+		return nil, nil, nil, nil, err
+	}
+	pkScript, err := muSig2PkScript(aggKey, scriptType)
+	if err != nil {
+		return nil, nil, nil, nil, err
+	}
+	rawTxStr = strings.Split(rawTxStr, MTX_STR_SEPERATE)[0]
+	serializedTx, err := hex.DecodeString(rawTxStr)
+	if err != nil {
+		return nil, nil, nil, nil, err
+	}
+	var tx types.Transaction
+	err = tx.Deserialize(bytes.NewReader(serializedTx))
+	if err != nil {
+		return nil, nil, nil, nil, err
+	}
+	msg, err := txscript.MuSig2SigHash(&tx, index, pkScript, txscript.SigHashAll, aggKey)
+	if err != nil {
+		return nil, nil, nil, nil, err
+	}
+	return &tx, pkScript, msg, aggKey, nil
+}
+
+// MuSig2KeyAgg returns the json of the aggregated key of the public keys and
+// its schnorr pay-to-pubkey-hash address.
+func MuSig2KeyAgg(network string, pubkeysStr string) (string, error) {
+	var param *params.Params
+	switch network {
+	case "mainnet":
+		param = &params.MainNetParams
+	case "testnet":
+		param = &params.TestNetParams
+	case "privnet":
+		param = &params.PrivNetParams
+	case "mixnet":
+		param = &params.MixNetParams
+	default:
+		ErrExit(fmt.Errorf("invalid network (mainnet|testnet|privnet|mixnet)"))
+	}
+	pks, err := decodeMuSig2PubKeys(pubkeysStr)
+	if err != nil {
+		return "", err
+	}
+	aggKey, err := schnorr.MuSig2AggregatePubKeys(pks)
+	if err != nil {
+		return "", err
+	}
+	addr, err := txscript.MuSig2PubKeyHashAddress(pks, param)
+	if err != nil {
+		return "", err
+	}
+	marshaled, err := json.Marshal(map[string]string{
+		"pubKey":  hex.EncodeToString(aggKey.SerializeCompressed()),
+		"address": addr.String(),
+	})
+	if err != nil {
+		return "", err
+	}
+	return string(marshaled), nil
+}
+
+// MuSig2Nonce returns the json of a new secret nonce, bound to the signature
+// hash of the input of the raw transaction which spends the output of the
+// aggregated key, and its public nonce.  The secret nonce must be kept
+// private and sign only once.
+func MuSig2Nonce(privkeyStr string, pubkeysStr string, rawTxStr string, index int, scriptType string) (string, error) {
+	privkeyByte, err := hex.DecodeString(privkeyStr)
+	if err != nil {
+		return "", err
+	}
+	if len(privkeyByte) != 32 {
+		return "", fmt.Errorf("invaid ec private key bytes: %d", len(privkeyByte))
+	}
+	priv, _ := secp256k1.PrivKeyFromBytes(privkeyByte)
+	pks, err := decodeMuSig2PubKeys(pubkeysStr)
+	if err != nil {
+		return "", err
+	}
+	_, _, msg, _, err := muSig2Session(pks, rawTxStr, index, scriptType)
+	if err != nil {
+		return "", err
+	}
+	secNonce, pubNonce, err := schnorr.MuSig2GenerateNonce(rand.Reader, priv, msg)
+	if err != nil {
+		return "", err
+	}
+	marshaled, err := json.Marshal(map[string]string{
+		"secNonce": hex.EncodeToString(append(secNonce, msg...)),
+		"pubNonce": hex.EncodeToString(pubNonce),
+		"sigHash":  hex.EncodeToString(msg),
+	})
+	if err != nil {
+		return "", err
+	}
+	return string(marshaled), nil
+}
+
+func MuSig2NonceAgg(pubNoncesStr string) (string, error) {
+	pubNonces, err := decodeMuSig2List(pubNoncesStr)
+	if err != nil {
+		return "", err
+	}
+	aggNonce, err := schnorr.MuSig2AggregateNonces(pubNonces)
+	if err != nil {
+		return "", err
+	}
+	return hex.EncodeToString(aggNonce), nil
+}
+
+// MuSig2Sign returns the partial signature of the signer for the input of the
+// raw transaction which spends the output of the aggregated key.
+func MuSig2Sign(privkeyStr string, secNonceStr string, pubkeysStr string, aggNonceStr string, rawTxStr string, index int, scriptType string) (string, error) {
+	privkeyByte, err := hex.DecodeString(privkeyStr)
+	if err != nil {
+		return "", err
+	}
+	if len(privkeyByte) != 32 {
+		return "", fmt.Errorf("invaid ec private key bytes: %d", len(privkeyByte))
+	}
+	priv, _ := secp256k1.PrivKeyFromBytes(privkeyByte)
+	secNonce, err := hex.DecodeString(secNonceStr)
+	if err != nil {
+		return "", err
+	}
+	if len(secNonce) != muSig2BoundSecNonceSize {
+		return "", fmt.Errorf("invalid secret nonce bytes: %d, it must be created by musig2-nonce for the transaction", len(secNonce))
+	}
+	aggNonce, err := hex.DecodeString(aggNonceStr)
+	if err != nil {
+		return "", err
+	}
+	pks, err := decodeMuSig2PubKeys(pubkeysStr)
+	if err != nil {
+		return "", err
+	}
+	_, _, msg, _, err := muSig2Session(pks, rawTxStr, index, scriptType)
+	if err != nil {
+		return "", err
+	}
+	if !bytes.Equal(secNonce[schnorr.MuSig2SecNonceSize:], msg) {
+		return "", fmt.Errorf("the secret nonce is bound to the signature hash %x, not %x, it must never sign anything else",
+			secNonce[schnorr.MuSig2SecNonceSize:], msg)
+	}
+	partialSig, err := schnorr.MuSig2PartialSign(secNonce[:schnorr.MuSig2SecNonceSize], priv, pks, aggNonce, msg)
+	if err != nil {
+		return "", err
+	}
+	return hex.EncodeToString(partialSig), nil
+}
+
+// MuSig2Combine combines the partial signatures of all the signers into the
+// signature script of the input, and returns the signed raw transaction.
+func MuSig2Combine(pubkeysStr string, aggNonceStr string, partialSigsStr string, rawTxStr string, index int, scriptType string) (string, error) {
+	aggNonce, err := hex.DecodeString(aggNonceStr)
+	if err != nil {
+		return "", err
+	}
+	partialSigs, err := decodeMuSig2List(partialSigsStr)
+	if err != nil {
+		return "", err
+	}
+	pks, err := decodeMuSig2PubKeys(pubkeysStr)
+	if err != nil {
+		return "", err
+	}
+	tx, pkScript, msg, aggKey, err := muSig2Session(pks, rawTxStr, index, scriptType)
+	if err != nil {
+		return "", err
+	}
+	sig, err := schnorr.MuSig2CombineSigs(partialSigs, pks, aggNonce, msg)
+	if err != nil {
+		return "", err
+	}
+	sigScript, err := txscript.MuSig2SignatureScript(pkScript, sig, txscript.SigHashAll, aggKey)
+	if err != nil {
+		return "", err
+	}
+	tx.TxIn[index].SignScript = sigScript
+	return marshal.MessageToHex(tx)
+}
+
+func MuSig2KeyAggSTDO(network string, pubkeysStr string) {
+	result, err := MuSig2KeyAgg(network, pubkeysStr)
+	if err != nil {
+		ErrExit(err)
+	}
+	fmt.Printf("%s\n", result)
+}
+
+func MuSig2NonceSTDO(privkeyStr string, pubkeysStr string, rawTxStr string, index int, scriptType string) {
+	result, err := MuSig2Nonce(privkeyStr, pubkeysStr, rawTxStr, index, scriptType)
+	if err != nil {
+		ErrExit(err)
+	}
+	fmt.Fprintln(os.Stderr, muSig2NonceWarning)
+	fmt.Printf("%s\n", result)
+}
+
+func MuSig2NonceAggSTDO(pubNoncesStr string) {
+	result, err := MuSig2NonceAgg(pubNoncesStr)
+	if err != nil {
+		ErrExit(err)
+	}
+	fmt.Printf("%s\n", result)
+}
+
+func MuSig2SignSTDO(privkeyStr string, secNonceStr string, pubkeysStr string, aggNonceStr string, rawTxStr string, index int, scriptType string) {
+	result, err := MuSig2Sign(privkeyStr, secNonceStr, pubkeysStr, aggNonceStr, rawTxStr, index, scriptType)
+	if err != nil {
+		ErrExit(err)
+	}
+	fmt.Fprintln(os.Stderr, "WARNING: the secret nonce is used, delete it and never sign with it again.")
+	fmt.Printf("%s\n", result)
+}
+
+func MuSig2CombineSTDO(pubkeysStr string, aggNonceStr string, partialSigsStr string, rawTxStr string, index int, scriptType string) {
+	result, err := MuSig2Combine(pubkeysStr, aggNonceStr, partialSigsStr, rawTxStr, index, scriptType)
+	if err != nil {
+		ErrExit(err)
+	}
+	fmt.Printf("%s\n", result)
+}
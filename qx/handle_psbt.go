@@ -0,0 +1,281 @@
+package qx
+
+import (
+	"bytes"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"github.com/Qitmeer/qng/common/marshal"
+	"github.com/Qitmeer/qng/core/psbt"
+	"github.com/Qitmeer/qng/core/types"
+	"github.com/Qitmeer/qng/crypto/ecc"
+	"github.com/Qitmeer/qng/params"
+	"strconv"
+	"strings"
+)
+
+// PsbtUpdateFlag is the set of INDEX:DATA values of the psbt-update options.
+type PsbtUpdateFlag []string
+
+func (v *PsbtUpdateFlag) Set(s string) error {
+	*v = append(*v, s)
+	return nil
+}
+
+func (v PsbtUpdateFlag) String() string {
+	return strings.Join(v, " ")
+}
+
+func splitIndex(s string) (int, []string, error) {
+	args := strings.Split(s, ":")
+	if len(args) < 2 {
+		return 0, nil, fmt.Errorf("invalid argument: %s", s)
+	}
+	index, err := strconv.Atoi(args[0])
+	if err != nil {
+		return 0, nil, err
+	}
+	return index, args[1:], nil
+}
+
+func PsbtCreate(rawTxStr string) (string, error) {
+	rawTxStr = strings.Split(rawTxStr, MTX_STR_SEPERATE)[0]
+	serializedTx, err := hex.DecodeString(rawTxStr)
+	if err != nil {
+		return "", err
+	}
+	var tx types.Transaction
+	err = tx.Deserialize(bytes.NewReader(serializedTx))
+	if err != nil {
+		return "", err
+	}
+	p, err := psbt.New(&tx)
+	if err != nil {
+		return "", err
+	}
+	return p.B64Encode()
+}
+
+func PsbtUpdate(psbtStr string, prevTxs []string, prevOuts []string, redeemScripts []string, sighashes []string) (string, error) {
+	p, err := psbt.NewFromBase64(psbtStr)
+	if err != nil {
+		return "", err
+	}
+	for _, s := range prevTxs {
+		index, args, err := splitIndex(s)
+		if err != nil {
+			return "", err
+		}
+		serializedTx, err := hex.DecodeString(args[0])
+		if err != nil {
+			return "", err
+		}
+		var prevTx types.Transaction
+		err = prevTx.Deserialize(bytes.NewReader(serializedTx))
+		if err != nil {
+			return "", err
+		}
+		err = p.AddInPrevTx(index, &prevTx)
+		if err != nil {
+			return "", err
+		}
+	}
+	for _, s := range prevOuts {
+		index, args, err := splitIndex(s)
+		if err != nil {
+			return "", err
+		}
+		if len(args) != 3 {
+			return "", fmt.Errorf("invalid previous output: %s", s)
+		}
+		coinId, err := strconv.ParseUint(args[0], 10, 16)
+		if err != nil {
+			return "", err
+		}
+		amount, err := strconv.ParseInt(args[1], 10, 64)
+		if err != nil {
+			return "", err
+		}
+		pkScript, err := hex.DecodeString(args[2])
+		if err != nil {
+			return "", err
+		}
+		err = p.AddInPrevOut(index, types.NewTxOutput(types.Amount{Value: amount, Id: types.CoinID(coinId)}, pkScript))
+		if err != nil {
+			return "", err
+		}
+	}
+	for _, s := range redeemScripts {
+		index, args, err := splitIndex(s)
+		if err != nil {
+			return "", err
+		}
+		script, err := hex.DecodeString(args[0])
+		if err != nil {
+			return "", err
+		}
+		err = p.AddInRedeemScript(index, script)
+		if err != nil {
+			return "", err
+		}
+	}
+	for _, s := range sighashes {
+		index, args, err := splitIndex(s)
+		if err != nil {
+			return "", err
+		}
+		hashType, err := psbt.ParseSighashType(args[0])
+		if err != nil {
+			return "", err
+		}
+		err = p.AddInSighashType(index, hashType)
+		if err != nil {
+			return "", err
+		}
+	}
+	return p.B64Encode()
+}
+
+func PsbtSign(privkeyStrs []string, psbtStr string) (string, error) {
+	p, err := psbt.NewFromBase64(psbtStr)
+	if err != nil {
+		return "", err
+	}
+	for _, privkeyStr := range privkeyStrs {
+		privkeyByte, err := hex.DecodeString(privkeyStr)
+		if err != nil {
+			return "", err
+		}
+		if len(privkeyByte) != 32 {
+			return "", fmt.Errorf("invaid ec private key bytes: %d", len(privkeyByte))
+		}
+		privateKey, _ := ecc.Secp256k1.PrivKeyFromBytes(privkeyByte)
+		signed := false
+		for i := range p.Inputs {
+			ok, err := p.Sign(i, privateKey)
+			if err != nil {
+				return "", fmt.Errorf("input %d: %v", i, err)
+			}
+			signed = signed || ok
+		}
+		if !signed {
+			return "", fmt.Errorf("the private key %s signs no input", privkeyStr)
+		}
+	}
+	return p.B64Encode()
+}
+
+func PsbtCombine(psbtStrs []string) (string, error) {
+	packets := make([]*psbt.Packet, len(psbtStrs))
+	for i, psbtStr := range psbtStrs {
+		p, err := psbt.NewFromBase64(psbtStr)
+		if err != nil {
+			return "", err
+		}
+		packets[i] = p
+	}
+	p, err := psbt.Combine(packets...)
+	if err != nil {
+		return "", err
+	}
+	return p.B64Encode()
+}
+
+func PsbtFinalize(psbtStr string) (string, error) {
+	p, err := psbt.NewFromBase64(psbtStr)
+	if err != nil {
+		return "", err
+	}
+	for i := range p.Inputs {
+		err = p.Finalize(i)
+		if err != nil {
+			return "", err
+		}
+	}
+	return p.B64Encode()
+}
+
+func PsbtExtract(psbtStr string) (string, error) {
+	p, err := psbt.NewFromBase64(psbtStr)
+	if err != nil {
+		return "", err
+	}
+	tx, err := p.Extract()
+	if err != nil {
+		return "", err
+	}
+	return marshal.MessageToHex(tx)
+}
+
+func PsbtDecode(network string, psbtStr string) {
+	var param *params.Params
+	switch network {
+	case "mainnet":
+		param = &params.MainNetParams
+	case "testnet":
+		param = &params.TestNetParams
+	case "privnet":
+		param = &params.PrivNetParams
+	case "mixnet":
+		param = &params.MixNetParams
+	default:
+		ErrExit(fmt.Errorf("invalid network (mainnet|testnet|privnet|mixnet)"))
+	}
+	p, err := psbt.NewFromBase64(psbtStr)
+	if err != nil {
+		ErrExit(err)
+	}
+	marshaled, err := json.Marshal(marshal.MarshalJsonPsbt(p, param))
+	if err != nil {
+		ErrExit(err)
+	}
+	fmt.Printf("%s\n", marshaled)
+}
+
+func PsbtCreateSTDO(rawTxStr string) {
+	result, err := PsbtCreate(rawTxStr)
+	if err != nil {
+		ErrExit(err)
+	}
+	fmt.Printf("%s\n", result)
+}
+
+func PsbtUpdateSTDO(psbtStr string, prevTxs []string, prevOuts []string, redeemScripts []string, sighashes []string) {
+	result, err := PsbtUpdate(psbtStr, prevTxs, prevOuts, redeemScripts, sighashes)
+	if err != nil {
+		ErrExit(err)
+	}
+	fmt.Printf("%s\n", result)
+}
+
+func PsbtSignSTDO(privkeyStrs []string, psbtStr string) {
+	result, err := PsbtSign(privkeyStrs, psbtStr)
+	if err != nil {
+		ErrExit(err)
+	}
+	fmt.Printf("%s\n", result)
+}
+
+func PsbtCombineSTDO(psbtStrs []string) {
+	result, err := PsbtCombine(psbtStrs)
+	if err != nil {
+		ErrExit(err)
+	}
+	fmt.Printf("%s\n", result)
+}
+
+func PsbtFinalizeSTDO(psbtStr string) {
+	result, err := PsbtFinalize(psbtStr)
+	if err != nil {
+		ErrExit(err)
+	}
+	fmt.Printf("%s\n", result)
+}
+
+func PsbtExtractSTDO(psbtStr string) {
+	result, err := PsbtExtract(psbtStr)
+	if err != nil {
+		ErrExit(err)
+	}
+	fmt.Printf("%s\n", result)
+}
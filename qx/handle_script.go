@@ -1,9 +1,14 @@
 package qx
 
 import (
+	"bytes"
 	"encoding/hex"
+	"encoding/json"
 	"fmt"
+	"github.com/Qitmeer/qng/common/marshal"
+	"github.com/Qitmeer/qng/core/types"
 	"github.com/Qitmeer/qng/engine/txscript"
+	"strings"
 )
 
 func ScriptDecode(rawScriptStr string) {
@@ -25,3 +30,43 @@ func ScriptEncode(rawOps string) {
 	}
 	fmt.Printf("%x\n", bytes)
 }
+
+// traceVerifyFlags are the standard script flags of the mempool, without the
+// token opcodes which need the token state of a node.
+const traceVerifyFlags = txscript.StandardVerifyFlags &^ txscript.ScriptVerifyTokenOpcodes
+
+func ScriptTrace(rawTxStr string, index int, pkScriptStr string, amount int64) (string, error) {
+	rawTxStr = strings.Split(rawTxStr, MTX_STR_SEPERATE)[0]
+	serializedTx, err := hex.DecodeString(rawTxStr)
+	if err != nil {
+		return "", err
+	}
+	var tx types.Transaction
+	err = tx.Deserialize(bytes.NewReader(serializedTx))
+	if err != nil {
+		return "", err
+	}
+	pkScript, err := hex.DecodeString(pkScriptStr)
+	if err != nil {
+		return "", err
+	}
+	vm, err := txscript.NewEngine(pkScript, &tx, index, traceVerifyFlags, txscript.DefaultScriptVersion, nil)
+	if err != nil {
+		return "", err
+	}
+	vm.EnableTrace()
+	err = vm.Execute()
+	marshaled, err := json.Marshal(marshal.MarshalJsonTrace(&tx, index, amount, pkScript, vm.Trace(), err))
+	if err != nil {
+		return "", err
+	}
+	return string(marshaled), nil
+}
+
+func ScriptTraceSTDO(rawTxStr string, index int, pkScriptStr string, amount int64) {
+	result, err := ScriptTrace(rawTxStr, index, pkScriptStr, amount)
+	if err != nil {
+		ErrExit(err)
+	}
+	fmt.Printf("%s\n", result)
+}
@@ -0,0 +1,403 @@
+package qx
+
+import (
+	"bytes"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"github.com/Qitmeer/qng/common/hash"
+	"github.com/Qitmeer/qng/core/address"
+	"github.com/Qitmeer/qng/core/blockchain/opreturn"
+	"github.com/Qitmeer/qng/core/types"
+	"github.com/Qitmeer/qng/crypto/ecc"
+	"github.com/Qitmeer/qng/engine/txscript"
+	"github.com/Qitmeer/qng/params"
+	"github.com/Qitmeer/qng/qx/scriptbasetypes"
+	"time"
+)
+
+// The transaction types of the tx-build spec.
+const (
+	TX_BUILD_REGULAR      = "regular"
+	TX_BUILD_CROSS_EXPORT = "crossexport"
+	TX_BUILD_CROSS_IMPORT = "crossimport"
+)
+
+// TxBuildSpec is the json spec of the transaction built by tx-build, the
+// amounts are in coins like tx-encode.
+type TxBuildSpec struct {
+	Type      string          `json:"type"`
+	Version   uint32          `json:"version"`
+	LockTime  uint32          `json:"lockTime"`
+	Timestamp int64           `json:"timestamp"`
+	Inputs    []TxBuildInput  `json:"inputs"`
+	Outputs   []TxBuildOutput `json:"outputs"`
+}
+
+// TxBuildInput is an input of the spec, the previous output is given by its
+// script or address, and the redeem script when it is a pay-to-script-hash.
+type TxBuildInput struct {
+	TxID         string  `json:"txid"`
+	Vout         uint32  `json:"vout"`
+	Sequence     *uint32 `json:"sequence"`
+	PkScript     string  `json:"pkScript"`
+	Address      string  `json:"address"`
+	RedeemScript string  `json:"redeemScript"`
+	Amount       float64 `json:"amount"`
+	CoinID       uint16  `json:"coinId"`
+}
+
+// TxBuildOutput is an output of the spec, which pays to the address, locked
+// to the lock time when it is set, to the script, or to the op return.
+type TxBuildOutput struct {
+	Address  string           `json:"address"`
+	PkScript string           `json:"pkScript"`
+	Amount   float64          `json:"amount"`
+	CoinID   uint16           `json:"coinId"`
+	LockTime int64            `json:"lockTime"`
+	OpReturn *TxBuildOpReturn `json:"opReturn"`
+}
+
+// TxBuildOpReturn is the op return payload of an output, either the locked
+// amount in coins or the data.
+type TxBuildOpReturn struct {
+	LockAmount *float64 `json:"lockAmount"`
+	Data       string   `json:"data"`
+}
+
+// TxBuildInputInfo is the signing metadata of an input.
+type TxBuildInputInfo struct {
+	Index        int      `json:"index"`
+	PkScript     string   `json:"pkScript,omitempty"`
+	RedeemScript string   `json:"redeemScript,omitempty"`
+	ScriptClass  string   `json:"scriptClass"`
+	SignType     string   `json:"signType"`
+	Addresses    []string `json:"addresses,omitempty"`
+	RequiredSigs int      `json:"requiredSigs"`
+	Amount       int64    `json:"amount"`
+	CoinID       uint16   `json:"coinId"`
+	SigHash      string   `json:"sigHash,omitempty"`
+}
+
+// TxBuildResult is the unsigned transaction with its signing metadata, the
+// raw is the input of tx-sign.
+type TxBuildResult struct {
+	Raw    string             `json:"raw"`
+	Tx     string             `json:"tx"`
+	TxID   string             `json:"txid"`
+	Type   string             `json:"type"`
+	Fees   map[string]int64   `json:"fees"`
+	Inputs []TxBuildInputInfo `json:"inputs"`
+}
+
+func signTypeName(ecType ecc.EcType) string {
+	switch ecType {
+	case ecc.EdDSA_Ed25519:
+		return "ed25519"
+	case ecc.ECDSA_SecpSchnorr:
+		return "schnorr"
+	default:
+		return "secp256k1"
+	}
+}
+
+func buildTxInputScript(in *TxBuildInput, param *params.Params) ([]byte, error) {
+	if len(in.PkScript) > 0 {
+		return hex.DecodeString(in.PkScript)
+	}
+	if len(in.Address) == 0 {
+		return nil, fmt.Errorf("input %s:%d requires its pkScript or address", in.TxID, in.Vout)
+	}
+	addr, err := address.DecodeAddress(in.Address)
+	if err != nil {
+		return nil, err
+	}
+	if !address.IsForNetwork(addr, param) {
+		return nil, fmt.Errorf("address %s is not for %s", in.Address, param.Name)
+	}
+	return txscript.PayToAddrScript(addr)
+}
+
+func buildTxOutputScript(out *TxBuildOutput, param *params.Params) ([]byte, error) {
+	if out.OpReturn != nil {
+		if out.OpReturn.LockAmount != nil {
+			lockAmount, err := types.NewAmount(*out.OpReturn.LockAmount)
+			if err != nil {
+				return nil, err
+			}
+			return opreturn.NewLockAmount(lockAmount.Value).PKScript(), nil
+		}
+		data, err := hex.DecodeString(out.OpReturn.Data)
+		if err != nil {
+			return nil, err
+		}
+		return txscript.GenerateProvablyPruneableOut(data)
+	}
+	if len(out.PkScript) > 0 {
+		return hex.DecodeString(out.PkScript)
+	}
+	addr, err := address.DecodeAddress(out.Address)
+	if err != nil {
+		return nil, fmt.Errorf("could not decode address: %v", err)
+	}
+	if !address.IsForNetwork(addr, param) {
+		return nil, fmt.Errorf("address %s is not for %s", out.Address, param.Name)
+	}
+	if out.LockTime > 0 {
+		pkhAddr, ok := addr.(*address.PubKeyHashAddress)
+		if !ok || pkhAddr.EcType() != ecc.ECDSA_Secp256k1 {
+			return nil, fmt.Errorf("the lock time requires a pubkeyhash address: %s", out.Address)
+		}
+		return txscript.PayToCLTVPubKeyHashScript(addr.Script(), out.LockTime)
+	}
+	return txscript.PayToAddrScript(addr)
+}
+
+// TxBuild returns the json of the unsigned transaction of the spec, with the
+// signing metadata of its inputs for an offline signer.
+func TxBuild(network string, specStr string) (string, error) {
+	var param *params.Params
+	switch network {
+	case "mainnet":
+		param = &params.MainNetParams
+	case "testnet":
+		param = &params.TestNetParams
+	case "privnet":
+		param = &params.PrivNetParams
+	case "mixnet":
+		param = &params.MixNetParams
+	default:
+		return "", fmt.Errorf("invalid network (mainnet|testnet|privnet|mixnet)")
+	}
+	var spec TxBuildSpec
+	decoder := json.NewDecoder(bytes.NewReader([]byte(specStr)))
+	decoder.DisallowUnknownFields()
+	if err := decoder.Decode(&spec); err != nil {
+		return "", fmt.Errorf("invalid tx spec: %v", err)
+	}
+	var txType types.TxType
+	switch spec.Type {
+	case "", TX_BUILD_REGULAR:
+		txType = types.TxTypeRegular
+	case TX_BUILD_CROSS_EXPORT:
+		txType = types.TxTypeCrossChainExport
+	case TX_BUILD_CROSS_IMPORT:
+		txType = types.TxTypeCrossChainImport
+	default:
+		return "", fmt.Errorf("invalid tx type %s (%s|%s|%s)", spec.Type,
+			TX_BUILD_REGULAR, TX_BUILD_CROSS_EXPORT, TX_BUILD_CROSS_IMPORT)
+	}
+	if len(spec.Outputs) == 0 {
+		return "", fmt.Errorf("the transaction has no outputs")
+	}
+
+	mtx := types.NewTransaction()
+	if spec.Version != 0 {
+		mtx.Version = spec.Version
+	}
+	mtx.LockTime = spec.LockTime
+	if spec.Timestamp != 0 {
+		mtx.Timestamp = time.Unix(spec.Timestamp, 0)
+	}
+
+	txtypes := &ScriptTypeIndex{}
+	infos := make([]TxBuildInputInfo, 0, len(spec.Inputs))
+	pkScripts := make([][]byte, 0, len(spec.Inputs))
+	totalIn := map[types.CoinID]int64{}
+	if txType == types.TxTypeCrossChainImport {
+		// The import input spends the balance of the signer in meer evm.
+		if len(spec.Inputs) != 0 {
+			return "", fmt.Errorf("%s transaction has no inputs in the spec", spec.Type)
+		}
+		txIn := types.NewTxInput(types.NewOutPoint(&hash.ZeroHash, types.SupperPrevOutIndex), []byte{})
+		txIn.Sequence = uint32(types.TxTypeCrossChainImport)
+		mtx.AddTxIn(txIn)
+		txtypes.InputTypeSet(0, scriptbasetypes.SPECIAL_CROSS_VAL, 0)
+		infos = append(infos, TxBuildInputInfo{
+			ScriptClass:  scriptbasetypes.SPECIAL_CROSS_TYPE,
+			SignType:     signTypeName(ecc.ECDSA_Secp256k1),
+			RequiredSigs: 1,
+		})
+	}
+	for i := range spec.Inputs {
+		in := &spec.Inputs[i]
+		txHash, err := hash.NewHashFromStr(in.TxID)
+		if err != nil {
+			return "", err
+		}
+		pkScript, err := buildTxInputScript(in, param)
+		if err != nil {
+			return "", err
+		}
+		amount, err := types.NewAmount(in.Amount)
+		if err != nil {
+			return "", err
+		}
+		amount.Id = types.CoinID(in.CoinID)
+		if err := types.CheckCoinID(amount.Id); err != nil {
+			return "", err
+		}
+		if txType == types.TxTypeCrossChainExport && amount.Id != types.MEERA {
+			return "", fmt.Errorf("%s transaction only spends %v", spec.Type, types.MEERA.Name())
+		}
+		totalIn[amount.Id] += amount.Value
+
+		txIn := types.NewTxInput(types.NewOutPoint(txHash, in.Vout), []byte{})
+		class, addrs, requiredSigs, err := txscript.ExtractPkScriptAddrs(pkScript, param)
+		if err != nil {
+			return "", err
+		}
+		info := TxBuildInputInfo{
+			Index:        len(mtx.TxIn),
+			PkScript:     hex.EncodeToString(pkScript),
+			ScriptClass:  class.String(),
+			SignType:     signTypeName(ecc.ECDSA_Secp256k1),
+			RequiredSigs: requiredSigs,
+			Amount:       amount.Value,
+			CoinID:       in.CoinID,
+		}
+		for _, addr := range addrs {
+			info.Addresses = append(info.Addresses, addr.String())
+		}
+		signClass, signScript := class, pkScript
+		if class == txscript.ScriptHashTy {
+			if len(in.RedeemScript) == 0 {
+				return "", fmt.Errorf("input %s:%d requires its redeem script", in.TxID, in.Vout)
+			}
+			signScript, err = hex.DecodeString(in.RedeemScript)
+			if err != nil {
+				return "", err
+			}
+			if !bytes.Equal(hash.Hash160(signScript), addrs[0].Hash160()[:]) {
+				return "", fmt.Errorf("the redeem script of input %s:%d doesn't match its script", in.TxID, in.Vout)
+			}
+			info.RedeemScript = in.RedeemScript
+			signClass = txscript.GetScriptClass(txscript.DefaultScriptVersion, signScript)
+		}
+		switch signClass {
+		case txscript.PubkeyAltTy, txscript.PubkeyHashAltTy:
+			ecType, err := txscript.ExtractPkScriptAltSigType(signScript)
+			if err != nil {
+				return "", err
+			}
+			info.SignType = signTypeName(ecType)
+		case txscript.NonStandardTy:
+			return "", fmt.Errorf("input %s:%d has a non-standard script", in.TxID, in.Vout)
+		}
+
+		lockTime := int64(0)
+		if signClass == txscript.CLTVPubKeyHashTy {
+			// see https://github.com/bitcoin/bips/blob/master/bip-0065.mediawiki
+			pops, err := txscript.ParseScript(signScript)
+			if err != nil {
+				return "", err
+			}
+			lockTime = txscript.GetInt64FromOpcode(pops[0])
+			if lockTime > int64(mtx.LockTime) ||
+				(lockTime < txscript.LockTimeThreshold) != (int64(mtx.LockTime) < txscript.LockTimeThreshold) {
+				return "", fmt.Errorf("the lock time of input %s:%d is %d, but the transaction lock time is %d",
+					in.TxID, in.Vout, lockTime, mtx.LockTime)
+			}
+			txIn.Sequence = types.MaxTxInSequenceNum - 1
+		}
+		if in.Sequence != nil {
+			txIn.Sequence = *in.Sequence
+		}
+		txtypes.InputTypeSet(info.Index, signClass, lockTime)
+		mtx.AddTxIn(txIn)
+		infos = append(infos, info)
+		pkScripts = append(pkScripts, signScript)
+	}
+
+	totalOut := map[types.CoinID]int64{}
+	for i := range spec.Outputs {
+		out := &spec.Outputs[i]
+		pkScript, err := buildTxOutputScript(out, param)
+		if err != nil {
+			return "", fmt.Errorf("output %d: %v", i, err)
+		}
+		amount, err := types.NewAmount(out.Amount)
+		if err != nil {
+			return "", err
+		}
+		amount.Id = types.CoinID(out.CoinID)
+		if err := types.CheckCoinID(amount.Id); err != nil {
+			return "", err
+		}
+		if i == 0 && txType == types.TxTypeCrossChainExport {
+			// The export output pays to the public key of the account in
+			// meer evm, and is paid by the inputs of meer.
+			if txscript.GetScriptClass(txscript.DefaultScriptVersion, pkScript) != txscript.PubKeyTy {
+				return "", fmt.Errorf("%s transaction pays to a public key address", spec.Type)
+			}
+			totalOut[types.MEERA] += amount.Value
+		} else {
+			totalOut[amount.Id] += amount.Value
+		}
+		txtypes.OutputTypeSet(i, txscript.GetScriptClass(txscript.DefaultScriptVersion, pkScript))
+		mtx.AddTxOut(types.NewTxOutput(*amount, pkScript))
+	}
+
+	if types.DetermineTxType(mtx) != txType {
+		return "", fmt.Errorf("the spec of %s transaction builds %v", spec.Type, types.DetermineTxType(mtx))
+	}
+	fees := map[string]int64{}
+	if txType != types.TxTypeCrossChainImport {
+		for id, out := range totalOut {
+			if _, ok := totalIn[id]; !ok {
+				return "", fmt.Errorf("the outputs of %v have no inputs", id.Name())
+			}
+			if totalIn[id] < out {
+				return "", fmt.Errorf("the outputs %d of %v are more than the inputs %d",
+					out, id.Name(), totalIn[id])
+			}
+		}
+		for id, in := range totalIn {
+			fees[id.Name()] = in - totalOut[id]
+		}
+		// The signature hashes of the inputs, which are only valid for the
+		// default hash type.
+		offset := len(mtx.TxIn) - len(pkScripts)
+		for i, script := range pkScripts {
+			sigHash, err := txscript.CalcSignatureHash(script, txscript.SigHashAll, mtx, offset+i, nil)
+			if err != nil {
+				return "", err
+			}
+			infos[offset+i].SigHash = hex.EncodeToString(sigHash)
+		}
+	}
+
+	mtxBytes, err := mtx.Serialize()
+	if err != nil {
+		return "", err
+	}
+	typeIndex, err := txtypes.Encode()
+	if err != nil {
+		return "", err
+	}
+	mtxHex := hex.EncodeToString(mtxBytes)
+	if len(spec.Type) == 0 {
+		spec.Type = TX_BUILD_REGULAR
+	}
+	result := &TxBuildResult{
+		Raw:    mtxHex + MTX_STR_SEPERATE + typeIndex,
+		Tx:     mtxHex,
+		TxID:   mtx.TxHash().String(),
+		Type:   spec.Type,
+		Fees:   fees,
+		Inputs: infos,
+	}
+	marshaled, err := json.MarshalIndent(result, "", "  ")
+	if err != nil {
+		return "", err
+	}
+	return string(marshaled), nil
+}
+
+func TxBuildSTDO(network string, specStr string) {
+	result, err := TxBuild(network, specStr)
+	if err != nil {
+		ErrExit(err)
+	}
+	fmt.Printf("%s\n", result)
+}
@@ -1,10 +1,19 @@
 package qx
 
 import (
+	"bytes"
+	"encoding/base64"
+	"encoding/hex"
+	"encoding/json"
 	"fmt"
+	"github.com/Qitmeer/qng/common/hash"
+	"github.com/Qitmeer/qng/common/marshal"
+	"github.com/Qitmeer/qng/core/address"
 	"github.com/Qitmeer/qng/core/types"
 	"github.com/Qitmeer/qng/engine/txscript"
+	"github.com/Qitmeer/qng/params"
 	"github.com/stretchr/testify/assert"
+	"strings"
 	"testing"
 	"time"
 )
@@ -152,3 +161,205 @@ func TestGPSToCompact(t *testing.T) {
 	// output :
 	// 36284416
 }
+
+func TestTxBuild(t *testing.T) {
+	k := "c39fb9103419af8be42385f3d6390b4c0c8f2cb67cf24dd43a059c4045d1a409"
+	addr := "TnPsPHp9pYkaCGbMNkbgfRKF8gMpRUsvL7B"
+	txid := "5fdad6bb6781416b0361a10eb6183dec45fb31edcf2da10d22893ee7bb6502ca"
+	spec := `{"lockTime":100,"inputs":[{"txid":"` + txid + `","vout":0,"address":"` + addr + `","amount":10,"coinId":0}],` +
+		`"outputs":[{"address":"` + addr + `","amount":9.9999,"coinId":0,"lockTime":50},{"opReturn":{"lockAmount":1}}]}`
+	rs, err := TxBuild("testnet", spec)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var result TxBuildResult
+	if err := json.Unmarshal([]byte(rs), &result); err != nil {
+		t.Fatal(err)
+	}
+	assert.Equal(t, result.Fees["MEER Asset"], int64(10000))
+	assert.Equal(t, len(result.Inputs), 1)
+	assert.Equal(t, result.Inputs[0].ScriptClass, txscript.PubKeyHashTy.String())
+	assert.Equal(t, result.Inputs[0].SignType, "secp256k1")
+
+	// The raw transaction is signed by tx-sign, and the signature is valid.
+	signed, err := TxSign([]string{k}, result.Raw, "testnet")
+	if err != nil {
+		t.Fatal(err)
+	}
+	serializedTx, _ := hex.DecodeString(signed)
+	var tx types.Transaction
+	if err := tx.Deserialize(bytes.NewReader(serializedTx)); err != nil {
+		t.Fatal(err)
+	}
+	assert.Equal(t, tx.TxHash().String(), result.TxID)
+	pkScript, _ := hex.DecodeString(result.Inputs[0].PkScript)
+	vm, err := txscript.NewEngine(pkScript, &tx, 0, txscript.ScriptBip16, 0, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := vm.Execute(); err != nil {
+		t.Fatal(err)
+	}
+
+	invalid := []string{
+		// The outputs spend more than the inputs.
+		`{"inputs":[{"txid":"` + txid + `","address":"` + addr + `","amount":1}],"outputs":[{"address":"` + addr + `","amount":2}]}`,
+		// The coin id is unknown.
+		`{"inputs":[{"txid":"` + txid + `","address":"` + addr + `","amount":1}],"outputs":[{"address":"` + addr + `","amount":1,"coinId":2}]}`,
+		// The export pays to a public key address.
+		`{"type":"crossexport","inputs":[{"txid":"` + txid + `","address":"` + addr + `","amount":1}],"outputs":[{"address":"` + addr + `","amount":1,"coinId":1}]}`,
+		// The address is for another network.
+		`{"inputs":[{"txid":"` + txid + `","address":"` + addr + `","amount":1}],"outputs":[{"address":"` + addr + `","amount":1}]}`,
+	}
+	for i, spec := range invalid {
+		network := "testnet"
+		if i == len(invalid)-1 {
+			network = "mainnet"
+		}
+		if _, err := TxBuild(network, spec); err == nil {
+			t.Fatalf("expected spec %d to fail", i)
+		}
+	}
+}
+
+func TestAddrToBech32(t *testing.T) {
+	b, err := AddrToBech32("TnV2vWDJoKceiyUHFCqFKaTLLkyDK6cY5ka")
+	assert.NoError(t, err)
+	assert.Contains(t, b, "tmeer1q")
+	a, err := Bech32ToAddr(strings.ToUpper(b))
+	assert.NoError(t, err)
+	assert.Equal(t, a, "TnV2vWDJoKceiyUHFCqFKaTLLkyDK6cY5ka")
+	_, err = Bech32ToAddr(a)
+	assert.Error(t, err)
+}
+
+func TestMsgSignWithPubKey(t *testing.T) {
+	// The scalar is less than the order of both curves.
+	k := "039fb9103419af8be42385f3d6390b4c0c8f2cb67cf24dd43a059c4045d1a409"
+	wif, err := EncodeWIF(false, k)
+	if err != nil {
+		t.Fatal(err)
+	}
+	scalar, _ := hex.DecodeString(k)
+	for _, scheme := range []string{"ed25519", "schnorr"} {
+		sig, err := MsgSignWithPubKey(scheme, wif, "hello")
+		if err != nil {
+			t.Fatalf("%s: %v", scheme, err)
+		}
+		dsa, ecType, _ := messageSignDSA(scheme)
+		_, pub := dsa.PrivKeyFromScalar(scalar)
+		addr, _ := address.NewPubKeyHashAddress(hash.Hash160(pub.SerializeCompressed()), &params.MixNetParams, ecType)
+		decoded, _ := base64.StdEncoding.DecodeString(sig)
+		ok, err := address.VerifyMessage(addr, decoded, "hello")
+		if err != nil || !ok {
+			t.Fatalf("%s: failed to verify the message: %v", scheme, err)
+		}
+	}
+	if _, err := MsgSignWithPubKey("rsa", wif, "hello"); err == nil {
+		t.Fatalf("unknown scheme is signed")
+	}
+}
+
+func TestMuSig2(t *testing.T) {
+	privs := []string{
+		"c39fb9103419af8be42385f3d6390b4c0c8f2cb67cf24dd43a059c4045d1a409",
+		"8c0e2bfa6d7a1e5a0f4c9f3a5b1de8a3c7f92e0b6d4a1c3e5f7a9b2d4c6e8f0a",
+	}
+	pubkeys := []string{}
+	for _, priv := range privs {
+		pub, err := EcPrivateKeyToEcPublicKey(false, priv)
+		if err != nil {
+			t.Fatal(err)
+		}
+		pubkeys = append(pubkeys, pub)
+	}
+	pubkeysStr := strings.Join(pubkeys, ",")
+	aggKey, err := MuSig2KeyAgg("testnet", pubkeysStr)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var agg map[string]string
+	if err := json.Unmarshal([]byte(aggKey), &agg); err != nil {
+		t.Fatal(err)
+	}
+	addr, err := address.DecodeAddress(agg["address"])
+	if err != nil {
+		t.Fatal(err)
+	}
+	pkScript, err := txscript.PayToAddrScript(addr)
+	if err != nil {
+		t.Fatal(err)
+	}
+	tx := types.NewTransaction()
+	tx.AddTxIn(types.NewTxInput(types.NewOutPoint(&hash.Hash{0x01}, 0), nil))
+	tx.AddTxOut(types.NewTxOutput(types.Amount{Value: 1000, Id: types.MEERA}, pkScript))
+	rawTx, err := marshal.MessageToHex(tx)
+	if err != nil {
+		t.Fatal(err)
+	}
+	other := types.NewTransaction()
+	other.AddTxIn(types.NewTxInput(types.NewOutPoint(&hash.Hash{0x01}, 0), nil))
+	other.AddTxOut(types.NewTxOutput(types.Amount{Value: 999, Id: types.MEERA}, pkScript))
+	otherTx, err := marshal.MessageToHex(other)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	secNonces := []string{}
+	pubNonces := []string{}
+	for _, priv := range privs {
+		result, err := MuSig2Nonce(priv, pubkeysStr, rawTx, 0, "pubkeyhash")
+		if err != nil {
+			t.Fatal(err)
+		}
+		var nonce map[string]string
+		if err := json.Unmarshal([]byte(result), &nonce); err != nil {
+			t.Fatal(err)
+		}
+		if !strings.HasSuffix(nonce["secNonce"], nonce["sigHash"]) {
+			t.Fatalf("the secret nonce isn't bound to the signature hash")
+		}
+		secNonces = append(secNonces, nonce["secNonce"])
+		pubNonces = append(pubNonces, nonce["pubNonce"])
+	}
+	aggNonce, err := MuSig2NonceAgg(strings.Join(pubNonces, ","))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// A secret nonce never signs another transaction, or without its hash.
+	if _, err := MuSig2Sign(privs[0], secNonces[0], pubkeysStr, aggNonce, otherTx, 0, "pubkeyhash"); err == nil {
+		t.Fatalf("the secret nonce signed another transaction")
+	}
+	if _, err := MuSig2Sign(privs[0], secNonces[0][:128], pubkeysStr, aggNonce, rawTx, 0, "pubkeyhash"); err == nil {
+		t.Fatalf("signed by a secret nonce without its signature hash")
+	}
+
+	partialSigs := []string{}
+	for i, priv := range privs {
+		partialSig, err := MuSig2Sign(priv, secNonces[i], pubkeysStr, aggNonce, rawTx, 0, "pubkeyhash")
+		if err != nil {
+			t.Fatal(err)
+		}
+		partialSigs = append(partialSigs, partialSig)
+	}
+	signedTx, err := MuSig2Combine(pubkeysStr, aggNonce, strings.Join(partialSigs, ","), rawTx, 0, "pubkeyhash")
+	if err != nil {
+		t.Fatal(err)
+	}
+	serializedTx, err := hex.DecodeString(signedTx)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var signed types.Transaction
+	if err := signed.Deserialize(bytes.NewReader(serializedTx)); err != nil {
+		t.Fatal(err)
+	}
+	vm, err := txscript.NewEngine(pkScript, &signed, 0, txscript.StandardVerifyFlags, 0, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := vm.Execute(); err != nil {
+		t.Fatal(err)
+	}
+}
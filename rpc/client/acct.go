@@ -0,0 +1,226 @@
+/*
+ * Copyright (c) 2017-2020 The qitmeer developers
+ */
+
+package client
+
+import (
+	"encoding/json"
+	j "github.com/Qitmeer/qng/core/json"
+	"github.com/Qitmeer/qng/core/types"
+	"github.com/Qitmeer/qng/rpc/client/cmds"
+)
+
+type FutureGetBalanceResult chan *response
+
+func (r FutureGetBalanceResult) Receive() (int64, error) {
+	res, err := receiveFuture(r)
+	if err != nil {
+		return 0, err
+	}
+
+	var result int64
+	err = json.Unmarshal(res, &result)
+	if err != nil {
+		return 0, err
+	}
+
+	return result, nil
+}
+
+func (c *Client) GetBalanceAsync(addr string, coinID types.CoinID) FutureGetBalanceResult {
+	cmd := cmds.NewGetBalanceCmd(addr, coinID)
+	return c.sendCmd(cmd)
+}
+
+func (c *Client) GetBalance(addr string, coinID types.CoinID) (int64, error) {
+	return c.GetBalanceAsync(addr, coinID).Receive()
+}
+
+type FutureGetAcctInfoResult chan *response
+
+func (r FutureGetAcctInfoResult) Receive() (*j.AcctInfo, error) {
+	res, err := receiveFuture(r)
+	if err != nil {
+		return nil, err
+	}
+
+	var result j.AcctInfo
+	err = json.Unmarshal(res, &result)
+	if err != nil {
+		return nil, err
+	}
+
+	return &result, nil
+}
+
+func (c *Client) GetAcctInfoAsync() FutureGetAcctInfoResult {
+	cmd := cmds.NewGetAcctInfoCmd()
+	return c.sendCmd(cmd)
+}
+
+func (c *Client) GetAcctInfo() (*j.AcctInfo, error) {
+	return c.GetAcctInfoAsync().Receive()
+}
+
+type FutureGetBalanceInfoResult chan *response
+
+func (r FutureGetBalanceInfoResult) Receive() (*j.BalanceInfoResult, error) {
+	res, err := receiveFuture(r)
+	if err != nil {
+		return nil, err
+	}
+
+	var result j.BalanceInfoResult
+	err = json.Unmarshal(res, &result)
+	if err != nil {
+		return nil, err
+	}
+
+	return &result, nil
+}
+
+func (c *Client) GetBalanceInfoAsync(addr string, coinID types.CoinID) FutureGetBalanceInfoResult {
+	cmd := cmds.NewGetBalanceInfoCmd(addr, coinID)
+	return c.sendCmd(cmd)
+}
+
+func (c *Client) GetBalanceInfo(addr string, coinID types.CoinID) (*j.BalanceInfoResult, error) {
+	return c.GetBalanceInfoAsync(addr, coinID).Receive()
+}
+
+type FutureAddBalanceResult chan *response
+
+func (r FutureAddBalanceResult) Receive() error {
+	_, err := receiveFuture(r)
+	return err
+}
+
+func (c *Client) AddBalanceAsync(addr string) FutureAddBalanceResult {
+	cmd := cmds.NewAddBalanceCmd(addr)
+	return c.sendCmd(cmd)
+}
+
+func (c *Client) AddBalance(addr string) error {
+	return c.AddBalanceAsync(addr).Receive()
+}
+
+type FutureAddXpubResult chan *response
+
+func (r FutureAddXpubResult) Receive() error {
+	_, err := receiveFuture(r)
+	return err
+}
+
+func (c *Client) AddXpubAsync(name string, xpub string, path string, gapLimit uint32) FutureAddXpubResult {
+	cmd := cmds.NewAddXpubCmd(name, xpub, &path, &gapLimit)
+	return c.sendCmd(cmd)
+}
+
+func (c *Client) AddXpub(name string, xpub string, path string, gapLimit uint32) error {
+	return c.AddXpubAsync(name, xpub, path, gapLimit).Receive()
+}
+
+type FutureGetXpubBalanceResult chan *response
+
+func (r FutureGetXpubBalanceResult) Receive() (*j.XpubBalanceResult, error) {
+	res, err := receiveFuture(r)
+	if err != nil {
+		return nil, err
+	}
+
+	var result j.XpubBalanceResult
+	err = json.Unmarshal(res, &result)
+	if err != nil {
+		return nil, err
+	}
+
+	return &result, nil
+}
+
+func (c *Client) GetXpubBalanceAsync(name string) FutureGetXpubBalanceResult {
+	cmd := cmds.NewGetXpubBalanceCmd(name)
+	return c.sendCmd(cmd)
+}
+
+func (c *Client) GetXpubBalance(name string) (*j.XpubBalanceResult, error) {
+	return c.GetXpubBalanceAsync(name).Receive()
+}
+
+type FutureGetAddressHistoryResult chan *response
+
+func (r FutureGetAddressHistoryResult) Receive() ([]j.AddrEventResult, error) {
+	res, err := receiveFuture(r)
+	if err != nil {
+		return nil, err
+	}
+
+	var result []j.AddrEventResult
+	err = json.Unmarshal(res, &result)
+	if err != nil {
+		return nil, err
+	}
+
+	return result, nil
+}
+
+func (c *Client) GetAddressHistoryAsync(addr string, fromOrder uint32, count uint32) FutureGetAddressHistoryResult {
+	cmd := cmds.NewGetAddressHistoryCmd(addr, &fromOrder, &count)
+	return c.sendCmd(cmd)
+}
+
+func (c *Client) GetAddressHistory(addr string, fromOrder uint32, count uint32) ([]j.AddrEventResult, error) {
+	return c.GetAddressHistoryAsync(addr, fromOrder, count).Receive()
+}
+
+type FutureGetBalanceAtResult chan *response
+
+func (r FutureGetBalanceAtResult) Receive() (*j.BalanceAtResult, error) {
+	res, err := receiveFuture(r)
+	if err != nil {
+		return nil, err
+	}
+
+	var result j.BalanceAtResult
+	err = json.Unmarshal(res, &result)
+	if err != nil {
+		return nil, err
+	}
+
+	return &result, nil
+}
+
+func (c *Client) GetBalanceAtAsync(addr string, order uint32) FutureGetBalanceAtResult {
+	cmd := cmds.NewGetBalanceAtCmd(addr, order)
+	return c.sendCmd(cmd)
+}
+
+func (c *Client) GetBalanceAt(addr string, order uint32) (*j.BalanceAtResult, error) {
+	return c.GetBalanceAtAsync(addr, order).Receive()
+}
+
+type FutureGetLockedBalanceScheduleResult chan *response
+
+func (r FutureGetLockedBalanceScheduleResult) Receive() (*j.LockedBalanceScheduleResult, error) {
+	res, err := receiveFuture(r)
+	if err != nil {
+		return nil, err
+	}
+
+	var result j.LockedBalanceScheduleResult
+	err = json.Unmarshal(res, &result)
+	if err != nil {
+		return nil, err
+	}
+
+	return &result, nil
+}
+
+func (c *Client) GetLockedBalanceScheduleAsync(addr string, coinID types.CoinID) FutureGetLockedBalanceScheduleResult {
+	cmd := cmds.NewGetLockedBalanceScheduleCmd(addr, coinID)
+	return c.sendCmd(cmd)
+}
+
+func (c *Client) GetLockedBalanceSchedule(addr string, coinID types.CoinID) (*j.LockedBalanceScheduleResult, error) {
+	return c.GetLockedBalanceScheduleAsync(addr, coinID).Receive()
+}
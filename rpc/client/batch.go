@@ -0,0 +1,214 @@
+/*
+ * Copyright (c) 2017-2020 The qitmeer developers
+ */
+
+package client
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"github.com/Qitmeer/qng/rpc/client/cmds"
+	"io/ioutil"
+	"math"
+)
+
+var (
+	// ErrNotBatchClient is returned by Send when the client was not created
+	// by NewBatch.
+	ErrNotBatchClient = errors.New("the client is not a batch client")
+
+	// ErrBatchNoResponse is delivered to a queued request when the batch
+	// response of the server does not contain its reply.
+	ErrBatchNoResponse = errors.New("no response for the request in the batch reply")
+)
+
+// batchResponse is an element of the reply to a JSON-RPC batch request.
+type batchResponse struct {
+	ID *float64 `json:"id"`
+	rawResponse
+}
+
+// NewBatch returns a client which queues the commands issued through the
+// *Async methods instead of sending them, the queued commands are sent to
+// the server in a single JSON-RPC batch request when Send is called. The
+// futures returned by the *Async methods receive their results once Send has
+// returned.
+//
+// Batch requests are issued with HTTP POST, so the HTTPPostMode of the
+// config is forced.
+//
+//	batch, _ := client.NewBatch(cfg)
+//	count := batch.GetBlockCountAsync()
+//	tips := batch.TipsAsync()
+//	if err := batch.Send(); err != nil {
+//		...
+//	}
+//	n, err := count.Receive()
+func NewBatch(config *ConnConfig) (*Client, error) {
+	cfg := *config
+	cfg.HTTPPostMode = true
+	client, err := New(&cfg, nil)
+	if err != nil {
+		return nil, err
+	}
+	client.batch = true
+	return client, nil
+}
+
+func (c *Client) addBatchRequest(jReq *jsonRequest) {
+	c.batchLock.Lock()
+	c.batchList = append(c.batchList, jReq)
+	c.batchLock.Unlock()
+}
+
+// BatchLen returns the number of commands queued by a batch client.
+func (c *Client) BatchLen() int {
+	c.batchLock.Lock()
+	defer c.batchLock.Unlock()
+	return len(c.batchList)
+}
+
+// Send sends the queued commands of a batch client in a single batch request
+// and delivers the replies to their futures.
+func (c *Client) Send() error {
+	return c.SendContext(context.Background())
+}
+
+// SendContext is the same as Send, but the request is abandoned when the
+// context is done.
+func (c *Client) SendContext(ctx context.Context) error {
+	if !c.batch {
+		return ErrNotBatchClient
+	}
+	c.batchLock.Lock()
+	reqs := c.batchList
+	c.batchList = nil
+	c.batchLock.Unlock()
+	if len(reqs) == 0 {
+		return nil
+	}
+
+	err := c.sendBatch(ctx, reqs)
+	if err != nil {
+		for _, jReq := range reqs {
+			jReq.responseChan <- &response{err: err}
+		}
+	}
+	return err
+}
+
+func (c *Client) sendBatch(ctx context.Context, reqs []*jsonRequest) error {
+	select {
+	case <-c.shutdown:
+		return ErrClientShutdown
+	default:
+	}
+
+	marshalledReqs := make([]json.RawMessage, 0, len(reqs))
+	reqMap := make(map[uint64]*jsonRequest, len(reqs))
+	for _, jReq := range reqs {
+		marshalledReqs = append(marshalledReqs, jReq.marshalledJSON)
+		reqMap[jReq.id] = jReq
+	}
+	marshalledJSON, err := json.Marshal(marshalledReqs)
+	if err != nil {
+		return err
+	}
+	httpReq, err := c.newHTTPRequest(ctx, marshalledJSON)
+	if err != nil {
+		return err
+	}
+
+	log.Trace(fmt.Sprintf("Sending batch of %d commands", len(reqs)))
+	httpResponse, err := c.httpClient.Do(httpReq)
+	if err != nil {
+		return err
+	}
+
+	// Read the raw bytes and close the response.
+	respBytes, err := ioutil.ReadAll(httpResponse.Body)
+	httpResponse.Body.Close()
+	if err != nil {
+		return fmt.Errorf("error reading json reply: %v", err)
+	}
+
+	var resps []batchResponse
+	err = json.Unmarshal(respBytes, &resps)
+	if err != nil {
+		// The server replies with a single error object when the whole
+		// batch is rejected.
+		var resp rawResponse
+		if json.Unmarshal(respBytes, &resp) == nil && resp.Error != nil {
+			return resp.Error
+		}
+		return fmt.Errorf("status code: %d, response: %q",
+			httpResponse.StatusCode, string(respBytes))
+	}
+
+	for _, resp := range resps {
+		if resp.ID == nil || *resp.ID < 0 || *resp.ID != math.Trunc(*resp.ID) {
+			log.Warn("Malformed batch response: invalid identifier")
+			continue
+		}
+		jReq, ok := reqMap[uint64(*resp.ID)]
+		if !ok {
+			log.Warn(fmt.Sprintf("Received unexpected batch reply (id %v)", *resp.ID))
+			continue
+		}
+		delete(reqMap, jReq.id)
+		res, err := resp.result()
+		jReq.responseChan <- &response{result: res, err: err}
+	}
+	for _, jReq := range reqMap {
+		jReq.responseChan <- &response{err: ErrBatchNoResponse}
+	}
+	return nil
+}
+
+// RawRequestAsync returns the future of a command which is not known by the cmds
+// package. The params are marshalled as the positional parameters of the
+// method, which must include the namespace prefix, e.g. "qitmeer_getBlockCount".
+func (c *Client) RawRequestAsync(method string, params []interface{}) FutureRawResult {
+	if method == "" {
+		return newFutureError(errors.New("no method"))
+	}
+	if params == nil {
+		params = []interface{}{}
+	}
+	id := c.NextID()
+	rawRequest, err := cmds.NewRequest(id, method, params)
+	if err != nil {
+		return newFutureError(err)
+	}
+	marshalledJSON, err := json.Marshal(rawRequest)
+	if err != nil {
+		return newFutureError(err)
+	}
+	jReq := &jsonRequest{
+		id:             id,
+		method:         method,
+		cmd:            nil,
+		marshalledJSON: marshalledJSON,
+		responseChan:   make(chan *response, 1),
+	}
+	if c.batch {
+		c.addBatchRequest(jReq)
+	} else {
+		c.sendRequest(jReq)
+	}
+	return jReq.responseChan
+}
+
+// RawRequest sends a command which is not known by the cmds package and
+// returns its raw result.
+func (c *Client) RawRequest(method string, params []interface{}) (json.RawMessage, error) {
+	return c.RawRequestAsync(method, params).Receive()
+}
+
+type FutureRawResult chan *response
+
+func (r FutureRawResult) Receive() (json.RawMessage, error) {
+	return receiveFuture(r)
+}
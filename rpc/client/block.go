@@ -723,3 +723,86 @@ func (c *Client) GetFeesAsync(h string) FutureGetFeesResult {
 func (c *Client) GetFees(h string) (int64, error) {
 	return c.GetFeesAsync(h).Receive()
 }
+
+type FutureGetTokenInfoResult chan *response
+
+func (r FutureGetTokenInfoResult) Receive() ([]j.TokenState, error) {
+	res, err := receiveFuture(r)
+	if err != nil {
+		return nil, err
+	}
+	var tokens []j.TokenState
+	err = json.Unmarshal(res, &tokens)
+	if err != nil {
+		return nil, err
+	}
+	return tokens, nil
+}
+
+func (c *Client) GetTokenInfoAsync() FutureGetTokenInfoResult {
+	cmd := cmds.NewGetTokenInfoCmd()
+	return c.sendCmd(cmd)
+}
+
+func (c *Client) GetTokenInfo() ([]j.TokenState, error) {
+	return c.GetTokenInfoAsync().Receive()
+}
+
+type FutureGetBlockStatsResult chan *response
+
+// Receive waits for the response promised by the future and returns the
+// statistics of the block. Only the requested fields are set when the
+// command was sent with fields.
+func (r FutureGetBlockStatsResult) Receive() (*j.BlockStatsResult, error) {
+	res, err := receiveFuture(r)
+	if err != nil {
+		return nil, err
+	}
+	var stats j.BlockStatsResult
+	err = json.Unmarshal(res, &stats)
+	if err != nil {
+		return nil, err
+	}
+	return &stats, nil
+}
+
+func (c *Client) GetBlockStatsAsync(hashOrOrder string, fields []string) FutureGetBlockStatsResult {
+	var fs *[]string
+	if len(fields) > 0 {
+		fs = &fields
+	}
+	cmd := cmds.NewGetBlockStatsCmd(hashOrOrder, fs)
+	return c.sendCmd(cmd)
+}
+
+// GetBlockStats returns the statistics of the block identified by its hash
+// or order, optionally only the passed fields.
+func (c *Client) GetBlockStats(hashOrOrder string, fields []string) (*j.BlockStatsResult, error) {
+	return c.GetBlockStatsAsync(hashOrOrder, fields).Receive()
+}
+
+type FutureGetTxOutSetInfoResult chan *response
+
+func (r FutureGetTxOutSetInfoResult) Receive() (*j.TxOutSetInfoResult, error) {
+	res, err := receiveFuture(r)
+	if err != nil {
+		return nil, err
+	}
+	var info j.TxOutSetInfoResult
+	err = json.Unmarshal(res, &info)
+	if err != nil {
+		return nil, err
+	}
+	return &info, nil
+}
+
+func (c *Client) GetTxOutSetInfoAsync() FutureGetTxOutSetInfoResult {
+	cmd := cmds.NewGetTxOutSetInfoCmd()
+	return c.sendCmd(cmd)
+}
+
+// GetTxOutSetInfo returns the statistics and the hash of the utxo set at the
+// main tip.
+func (c *Client) GetTxOutSetInfo() (*j.TxOutSetInfoResult, error) {
+	return c.GetTxOutSetInfoAsync().Receive()
+}
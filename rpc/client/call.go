@@ -0,0 +1,119 @@
+/*
+ * Copyright (c) 2017-2020 The qitmeer developers
+ */
+
+package client
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net"
+	"time"
+)
+
+const (
+	// defaultRetryInterval is the amount of time to wait before the first
+	// retry of a call when the config does not specify one.
+	defaultRetryInterval = time.Second
+
+	// maxRetryInterval is the longest amount of time to wait between the
+	// retries of a call.
+	maxRetryInterval = time.Minute
+)
+
+// CallContext sends the command and unmarshals its result into result, which
+// is typically a pointer to a result type of core/json. The result is
+// ignored when it is nil.
+//
+// The call is abandoned when the context is done. It is retried up to
+// ConnConfig.MaxRetries times, with an exponential backoff, only when the
+// request never reached the server, so that a command such as sendRawTransaction
+// is not run twice. A call which fails once the request is sent, e.g. on a lost
+// connection or a timeout, and the errors returned by the server are never
+// retried.
+//
+//	var info json.InfoNodeResult
+//	err := c.CallContext(ctx, &info, cmds.NewGetNodeInfoCmd())
+func (c *Client) CallContext(ctx context.Context, result interface{}, cmd interface{}) error {
+	interval := c.config.RetryInterval
+	if interval <= 0 {
+		interval = defaultRetryInterval
+	}
+	for retry := 0; ; retry++ {
+		res, err := c.sendCmdContext(ctx, cmd)
+		if err == nil {
+			if result == nil || res == nil {
+				return nil
+			}
+			return json.Unmarshal(res, result)
+		}
+		if retry >= c.config.MaxRetries || !isUnsentError(err) || ctx.Err() != nil {
+			return err
+		}
+
+		log.Debug(fmt.Sprintf("Retrying call in %s (%d/%d): %v", interval,
+			retry+1, c.config.MaxRetries, err))
+		select {
+		case <-time.After(interval):
+		case <-ctx.Done():
+			return ctx.Err()
+		}
+		interval *= 2
+		if interval > maxRetryInterval {
+			interval = maxRetryInterval
+		}
+	}
+}
+
+// Call is the same as CallContext with a background context.
+func (c *Client) Call(result interface{}, cmd interface{}) error {
+	return c.CallContext(context.Background(), result, cmd)
+}
+
+func (c *Client) sendCmdContext(ctx context.Context, cmd interface{}) ([]byte, error) {
+	if c.batch {
+		return nil, errors.New("a batch client can not issue calls with a context")
+	}
+	jReq, err := c.newJsonRequest(cmd)
+	if err != nil {
+		return nil, err
+	}
+	jReq.ctx = ctx
+	c.sendRequest(jReq)
+
+	select {
+	case r := <-jReq.responseChan:
+		return r.result, r.err
+	case <-ctx.Done():
+		// Stop tracking the request, a late reply is dropped.
+		c.removeRequest(jReq.id)
+		return nil, ctx.Err()
+	}
+}
+
+// ReceiveContext waits for the raw result of the future until the context
+// is done, the result can be unmarshalled into a result type of core/json.
+// It allows any future returned by the *Async methods to be received with a
+// context, e.g. ReceiveContext(ctx, c.GetBlockCountAsync()).
+func ReceiveContext(ctx context.Context, f chan *response) (json.RawMessage, error) {
+	select {
+	case r := <-f:
+		return r.result, r.err
+	case <-ctx.Done():
+		return nil, ctx.Err()
+	}
+}
+
+// isUnsentError returns whether the error of a call means the request was not
+// sent to the server, either because the websocket connection was never
+// established or because the HTTP connection could not be dialed, so that the
+// call can be tried again.
+func isUnsentError(err error) bool {
+	if err == ErrClientNotConnected {
+		return true
+	}
+	var opErr *net.OpError
+	return errors.As(err, &opErr) && opErr.Op == "dial"
+}
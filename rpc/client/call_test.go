@@ -0,0 +1,94 @@
+/*
+ * Copyright (c) 2017-2020 The qitmeer developers
+ */
+
+package client
+
+import (
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/Qitmeer/qng/rpc/client/cmds"
+)
+
+func newTestPostClient(t *testing.T, host string) *Client {
+	c, err := New(&ConnConfig{
+		Host:          host,
+		User:          "user",
+		Pass:          "pass",
+		DisableTLS:    true,
+		HTTPPostMode:  true,
+		MaxRetries:    2,
+		RetryInterval: 10 * time.Millisecond,
+	}, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(c.Shutdown)
+	return c
+}
+
+func TestCallContextSentNotRetried(t *testing.T) {
+	var requests int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		atomic.AddInt32(&requests, 1)
+		// The connection is lost once the request is received.
+		conn, _, err := w.(http.Hijacker).Hijack()
+		if err != nil {
+			t.Error(err)
+			return
+		}
+		conn.Close()
+	}))
+	defer server.Close()
+
+	c := newTestPostClient(t, server.Listener.Addr().String())
+	if err := c.Call(nil, cmds.NewGetNodeInfoCmd()); err == nil {
+		t.Fatalf("the call succeeded on a lost connection")
+	}
+	if n := atomic.LoadInt32(&requests); n != 1 {
+		t.Fatalf("the sent request is tried %d times", n)
+	}
+}
+
+func TestCallContextUnsentRetried(t *testing.T) {
+	// Nothing listens on the address of a closed listener.
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	host := listener.Addr().String()
+	listener.Close()
+
+	c := newTestPostClient(t, host)
+	start := time.Now()
+	err = c.Call(nil, cmds.NewGetNodeInfoCmd())
+	if !isUnsentError(err) {
+		t.Fatalf("got %v, want a dial error", err)
+	}
+	// Both retries wait for their interval.
+	if elapsed := time.Since(start); elapsed < 30*time.Millisecond {
+		t.Fatalf("the call is not retried, it returned after %s", elapsed)
+	}
+}
+
+func TestIsUnsentError(t *testing.T) {
+	for _, test := range []struct {
+		err    error
+		unsent bool
+	}{
+		{ErrClientNotConnected, true},
+		{&net.OpError{Op: "dial", Err: ErrInvalidEndpoint}, true},
+		{ErrClientDisconnect, false},
+		{&net.OpError{Op: "read", Err: ErrInvalidEndpoint}, false},
+		{ErrInvalidAuth, false},
+	} {
+		if isUnsentError(test.err) != test.unsent {
+			t.Errorf("%v: got unsent %v, want %v", test.err, !test.unsent, test.unsent)
+		}
+	}
+}
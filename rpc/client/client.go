@@ -7,6 +7,7 @@ package client
 import (
 	"bytes"
 	"container/list"
+	"context"
 	"encoding/json"
 	"fmt"
 	"github.com/Qitmeer/qng/params"
@@ -60,6 +61,12 @@ type Client struct {
 	ntfnStateLock sync.Mutex
 	ntfnState     *notificationState
 
+	// batch indicates that the client queues the requests until Send is
+	// called, see NewBatch.
+	batch     bool
+	batchLock sync.Mutex
+	batchList []*jsonRequest
+
 	// Networking infrastructure.
 	sendChan        chan []byte
 	sendPostChan    chan *sendPostDetails
@@ -255,6 +262,9 @@ func (c *Client) handleMessage(msg []byte) {
 
 	// Deliver the response.
 	result, err := in.rawResponse.result()
+	if err == nil {
+		c.trackSubscription(request.cmd, result)
+	}
 	request.responseChan <- &response{result: result, err: err}
 }
 
@@ -314,6 +324,16 @@ func (c *Client) handleNotification(ntfn *rawNotification) {
 		c.ntfnHandlers.OnBlockAccepted(blockHash, height, blockOrder, blockTime, txs)
 
 	case cmds.ReorganizationNtfnMethod:
+		if c.ntfnHandlers.OnReorganizationDiff != nil {
+			diff, err := parseReorganizationDiffNtfnParams(ntfn.Params)
+			if err != nil {
+				log.Warn(fmt.Sprintf("Received invalid block reorganization "+
+					"notification: %v", err))
+				return
+			}
+			c.ntfnHandlers.OnReorganizationDiff(diff)
+		}
+
 		// Ignore the notification if the client is not interested in
 		// it.
 		if c.ntfnHandlers.OnReorganization == nil {
@@ -436,6 +456,26 @@ func (c *Client) handleNotification(ntfn *rawNotification) {
 		}
 		c.ntfnHandlers.OnBlockTemplate(bt)
 
+	// OnSubscription
+	case cmds.SubscriptionNtfnMethod:
+		// Ignore the notification if the client is not interested in
+		// it.
+		if c.ntfnHandlers.OnSubscription == nil {
+			return
+		}
+
+		serverID, result, err := parseSubscriptionNtfnParams(ntfn.Params)
+		if err != nil {
+			log.Warn(fmt.Sprintf("Received invalid subscription "+
+				"notification: %v", err))
+			return
+		}
+		id, topic := serverID, ""
+		if sub := c.subscriptionByServerID(serverID); sub != nil {
+			id, topic = sub.id, sub.cmd.Topic
+		}
+		c.ntfnHandlers.OnSubscription(id, topic, result)
+
 	// OnUnknownNotification
 	default:
 		if c.ntfnHandlers.OnUnknownNotification == nil {
@@ -499,32 +539,86 @@ func (c *Client) trackRegisteredNtfns(cmd interface{}) {
 	}
 }
 
+// trackSubscription records the subscription made by a successful subscribe
+// command, so that it can be re-established on reconnect. The server id of
+// a re-established subscription replaces the previous one.
+func (c *Client) trackSubscription(cmd interface{}, result json.RawMessage) {
+	scmd, ok := cmd.(*cmds.SubscribeCmd)
+	if !ok || c.ntfnHandlers == nil {
+		return
+	}
+	var serverID string
+	if err := json.Unmarshal(result, &serverID); err != nil {
+		return
+	}
+
+	c.ntfnStateLock.Lock()
+	defer c.ntfnStateLock.Unlock()
+
+	for _, sub := range c.ntfnState.subscriptions {
+		if sub.cmd == scmd {
+			sub.serverID = serverID
+			return
+		}
+	}
+	c.ntfnState.subscriptions[serverID] = &subscriptionState{
+		id:       serverID,
+		serverID: serverID,
+		cmd:      scmd,
+	}
+}
+
+func (c *Client) subscriptionByServerID(serverID string) *subscriptionState {
+	c.ntfnStateLock.Lock()
+	defer c.ntfnStateLock.Unlock()
+
+	for _, sub := range c.ntfnState.subscriptions {
+		if sub.serverID == serverID {
+			return sub
+		}
+	}
+	return nil
+}
+
 func (c *Client) sendCmd(cmd interface{}) chan *response {
+	jReq, err := c.newJsonRequest(cmd)
+	if err != nil {
+		return newFutureError(err)
+	}
+
+	// Queue the request when the client is a batch client, it is sent
+	// along with the other queued requests by Send.
+	if c.batch {
+		c.addBatchRequest(jReq)
+		return jReq.responseChan
+	}
+	c.sendRequest(jReq)
+
+	return jReq.responseChan
+}
+
+func (c *Client) newJsonRequest(cmd interface{}) (*jsonRequest, error) {
 	// Get the method associated with the command.
 	method, err := cmds.CmdMethod(cmd)
 	if err != nil {
-		return newFutureError(err)
+		return nil, err
 	}
 
 	// Marshal the command.
 	id := c.NextID()
 	marshalledJSON, err := cmds.MarshalCmd(id, cmd)
 	if err != nil {
-		return newFutureError(err)
+		return nil, err
 	}
 
-	// Generate the request and send it along with a channel to respond on.
-	responseChan := make(chan *response, 1)
-	jReq := &jsonRequest{
+	// Generate the request along with a channel to respond on.
+	return &jsonRequest{
 		id:             id,
 		method:         method,
 		cmd:            cmd,
 		marshalledJSON: marshalledJSON,
-		responseChan:   responseChan,
-	}
-	c.sendRequest(jReq)
-
-	return responseChan
+		responseChan:   make(chan *response, 1),
+	}, nil
 }
 
 func (c *Client) NextID() uint64 {
@@ -562,18 +656,20 @@ func (c *Client) sendRequest(jReq *jsonRequest) {
 	c.sendMessage(jReq.marshalledJSON)
 }
 
-func (c *Client) sendPost(jReq *jsonRequest) {
-	// Generate a request to the configured RPC server.
+// newHTTPRequest generates a HTTP POST request of the marshalled JSON to the
+// configured RPC server.
+func (c *Client) newHTTPRequest(ctx context.Context, marshalledJSON []byte) (*http.Request, error) {
 	protocol := "http"
 	if !c.config.DisableTLS {
 		protocol = "https"
 	}
 	url := protocol + "://" + c.config.Host
-	bodyReader := bytes.NewReader(jReq.marshalledJSON)
-	httpReq, err := http.NewRequest("POST", url, bodyReader)
+	if ctx == nil {
+		ctx = context.Background()
+	}
+	httpReq, err := http.NewRequestWithContext(ctx, "POST", url, bytes.NewReader(marshalledJSON))
 	if err != nil {
-		jReq.responseChan <- &response{result: nil, err: err}
-		return
+		return nil, err
 	}
 	httpReq.Close = true
 	httpReq.Header.Set("Content-Type", "application/json")
@@ -583,11 +679,20 @@ func (c *Client) sendPost(jReq *jsonRequest) {
 
 	// Configure basic access authorization.
 	user, pass, err := c.config.getAuth()
+	if err != nil {
+		return nil, err
+	}
+	httpReq.SetBasicAuth(user, pass)
+	return httpReq, nil
+}
+
+func (c *Client) sendPost(jReq *jsonRequest) {
+	// Generate a request to the configured RPC server.
+	httpReq, err := c.newHTTPRequest(jReq.ctx, jReq.marshalledJSON)
 	if err != nil {
 		jReq.responseChan <- &response{result: nil, err: err}
 		return
 	}
-	httpReq.SetBasicAuth(user, pass)
 
 	log.Trace(fmt.Sprintf("Sending command [%s] with id %d", jReq.method, jReq.id))
 	c.sendPostRequest(httpReq, jReq)
@@ -948,6 +1053,14 @@ func (c *Client) reregisterNtfns() error {
 			return err
 		}
 	}
+	// Resubscribe, the new server ids are mapped to the ids returned by
+	// the first subscribe.
+	for _, sub := range stateCopy.subscriptions {
+		log.Debug(fmt.Sprintf("Resubscribing [%s] (%s)", sub.cmd.Topic, sub.id))
+		if _, err := receiveFuture(c.sendCmd(sub.cmd)); err != nil {
+			return err
+		}
+	}
 
 	return nil
 }
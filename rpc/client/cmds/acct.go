@@ -0,0 +1,127 @@
+/*
+ * Copyright (c) 2017-2020 The qitmeer developers
+ */
+
+package cmds
+
+import (
+	"github.com/Qitmeer/qng/core/types"
+)
+
+type GetBalanceCmd struct {
+	Addr   string
+	CoinID types.CoinID
+}
+
+func NewGetBalanceCmd(addr string, coinID types.CoinID) *GetBalanceCmd {
+	return &GetBalanceCmd{
+		Addr:   addr,
+		CoinID: coinID,
+	}
+}
+
+type GetAcctInfoCmd struct{}
+
+func NewGetAcctInfoCmd() *GetAcctInfoCmd {
+	return &GetAcctInfoCmd{}
+}
+
+type GetBalanceInfoCmd struct {
+	Addr   string
+	CoinID types.CoinID
+}
+
+func NewGetBalanceInfoCmd(addr string, coinID types.CoinID) *GetBalanceInfoCmd {
+	return &GetBalanceInfoCmd{
+		Addr:   addr,
+		CoinID: coinID,
+	}
+}
+
+type AddBalanceCmd struct {
+	Addr string
+}
+
+func NewAddBalanceCmd(addr string) *AddBalanceCmd {
+	return &AddBalanceCmd{
+		Addr: addr,
+	}
+}
+
+type AddXpubCmd struct {
+	Name     string
+	Xpub     string
+	Path     *string
+	GapLimit *uint32
+}
+
+func NewAddXpubCmd(name string, xpub string, path *string, gapLimit *uint32) *AddXpubCmd {
+	return &AddXpubCmd{
+		Name:     name,
+		Xpub:     xpub,
+		Path:     path,
+		GapLimit: gapLimit,
+	}
+}
+
+type GetXpubBalanceCmd struct {
+	Name string
+}
+
+func NewGetXpubBalanceCmd(name string) *GetXpubBalanceCmd {
+	return &GetXpubBalanceCmd{
+		Name: name,
+	}
+}
+
+type GetAddressHistoryCmd struct {
+	Addr      string
+	FromOrder *uint32
+	Count     *uint32
+}
+
+func NewGetAddressHistoryCmd(addr string, fromOrder *uint32, count *uint32) *GetAddressHistoryCmd {
+	return &GetAddressHistoryCmd{
+		Addr:      addr,
+		FromOrder: fromOrder,
+		Count:     count,
+	}
+}
+
+type GetBalanceAtCmd struct {
+	Addr  string
+	Order uint32
+}
+
+func NewGetBalanceAtCmd(addr string, order uint32) *GetBalanceAtCmd {
+	return &GetBalanceAtCmd{
+		Addr:  addr,
+		Order: order,
+	}
+}
+
+type GetLockedBalanceScheduleCmd struct {
+	Addr   string
+	CoinID types.CoinID
+}
+
+func NewGetLockedBalanceScheduleCmd(addr string, coinID types.CoinID) *GetLockedBalanceScheduleCmd {
+	return &GetLockedBalanceScheduleCmd{
+		Addr:   addr,
+		CoinID: coinID,
+	}
+}
+
+func init() {
+	flags := UsageFlag(0)
+
+	MustRegisterCmd("getBalance", (*GetBalanceCmd)(nil), flags, DefaultServiceNameSpace)
+	MustRegisterCmd("getAcctInfo", (*GetAcctInfoCmd)(nil), flags, DefaultServiceNameSpace)
+	MustRegisterCmd("getBalanceInfo", (*GetBalanceInfoCmd)(nil), flags, DefaultServiceNameSpace)
+	MustRegisterCmd("addBalance", (*AddBalanceCmd)(nil), flags, DefaultServiceNameSpace)
+	MustRegisterCmd("addXpub", (*AddXpubCmd)(nil), flags, DefaultServiceNameSpace)
+	MustRegisterCmd("getXpubBalance", (*GetXpubBalanceCmd)(nil), flags, DefaultServiceNameSpace)
+	MustRegisterCmd("getAddressHistory", (*GetAddressHistoryCmd)(nil), flags, DefaultServiceNameSpace)
+	MustRegisterCmd("getBalanceAt", (*GetBalanceAtCmd)(nil), flags, DefaultServiceNameSpace)
+	MustRegisterCmd("getLockedBalanceSchedule", (*GetLockedBalanceScheduleCmd)(nil), flags, DefaultServiceNameSpace)
+}
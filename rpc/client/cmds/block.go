@@ -244,6 +244,30 @@ func NewGetFeesCmd(h string) *GetFeesCmd {
 	}
 }
 
+type GetTokenInfoCmd struct{}
+
+func NewGetTokenInfoCmd() *GetTokenInfoCmd {
+	return &GetTokenInfoCmd{}
+}
+
+type GetBlockStatsCmd struct {
+	HashOrOrder string
+	Fields      *[]string
+}
+
+func NewGetBlockStatsCmd(hashOrOrder string, fields *[]string) *GetBlockStatsCmd {
+	return &GetBlockStatsCmd{
+		HashOrOrder: hashOrOrder,
+		Fields:      fields,
+	}
+}
+
+type GetTxOutSetInfoCmd struct{}
+
+func NewGetTxOutSetInfoCmd() *GetTxOutSetInfoCmd {
+	return &GetTxOutSetInfoCmd{}
+}
+
 func init() {
 	flags := UsageFlag(0)
 
@@ -266,4 +290,7 @@ func init() {
 	MustRegisterCmd("tips", (*TipsCmd)(nil), flags, DefaultServiceNameSpace)
 	MustRegisterCmd("getCoinbase", (*GetCoinbaseCmd)(nil), flags, DefaultServiceNameSpace)
 	MustRegisterCmd("getFees", (*GetFeesCmd)(nil), flags, DefaultServiceNameSpace)
+	MustRegisterCmd("getTokenInfo", (*GetTokenInfoCmd)(nil), flags, DefaultServiceNameSpace)
+	MustRegisterCmd("getBlockStats", (*GetBlockStatsCmd)(nil), flags, DefaultServiceNameSpace)
+	MustRegisterCmd("getTxOutSetInfo", (*GetTxOutSetInfoCmd)(nil), flags, DefaultServiceNameSpace)
 }
@@ -0,0 +1,61 @@
+/*
+ * Copyright (c) 2017-2020 The qitmeer developers
+ */
+
+package cmds
+
+type CreateHTLCCmd struct {
+	Recipient  string
+	Refund     string
+	SecretHash string
+	LockTime   int64
+	HashType   *string
+}
+
+func NewCreateHTLCCmd(recipient string, refund string, secretHash string, lockTime int64, hashType *string) *CreateHTLCCmd {
+	return &CreateHTLCCmd{
+		Recipient:  recipient,
+		Refund:     refund,
+		SecretHash: secretHash,
+		LockTime:   lockTime,
+		HashType:   hashType,
+	}
+}
+
+type RedeemHTLCCmd struct {
+	RawTx      string
+	InputIndex int
+	Contract   string
+	Secret     string
+}
+
+func NewRedeemHTLCCmd(rawTx string, inputIndex int, contract string, secret string) *RedeemHTLCCmd {
+	return &RedeemHTLCCmd{
+		RawTx:      rawTx,
+		InputIndex: inputIndex,
+		Contract:   contract,
+		Secret:     secret,
+	}
+}
+
+type RefundHTLCCmd struct {
+	RawTx      string
+	InputIndex int
+	Contract   string
+}
+
+func NewRefundHTLCCmd(rawTx string, inputIndex int, contract string) *RefundHTLCCmd {
+	return &RefundHTLCCmd{
+		RawTx:      rawTx,
+		InputIndex: inputIndex,
+		Contract:   contract,
+	}
+}
+
+func init() {
+	flags := UsageFlag(0)
+
+	MustRegisterCmd("createHTLC", (*CreateHTLCCmd)(nil), flags, DefaultServiceNameSpace)
+	MustRegisterCmd("redeemHTLC", (*RedeemHTLCCmd)(nil), flags, WalletNameSpace)
+	MustRegisterCmd("refundHTLC", (*RefundHTLCCmd)(nil), flags, WalletNameSpace)
+}
@@ -17,6 +17,7 @@ const (
 	LogNameSpace            = "log"
 	NotifyNameSpace         = ""
 	P2PNameSpace            = "p2p"
+	WalletNameSpace         = "wallet"
 )
 
 type RPCErrorCode int
@@ -144,6 +145,12 @@ var (
 		Code:    -32003,
 		Message: "Invalid Node",
 	}
+	// ErrRPCIndexSyncing indicates that a required index is still catching
+	// up with the main chain.
+	ErrRPCIndexSyncing = &RPCError{
+		Code:    -32004,
+		Message: "Index syncing",
+	}
 	ErrRPCParse = &RPCError{
 		Code:    -32700,
 		Message: "Parse error",
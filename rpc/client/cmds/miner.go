@@ -71,6 +71,12 @@ func NewSubmitBlockHeaderCmd(blockHeader *types.BlockHeader) *SubmitBlockHeaderC
 	}
 }
 
+type GetMinerInfoCmd struct{}
+
+func NewGetMinerInfoCmd() *GetMinerInfoCmd {
+	return &GetMinerInfoCmd{}
+}
+
 func init() {
 	flags := UsageFlag(0)
 
@@ -78,5 +84,6 @@ func init() {
 	MustRegisterCmd("submitBlock", (*SubmitBlockCmd)(nil), flags, DefaultServiceNameSpace)
 	MustRegisterCmd("getRemoteGBT", (*GetRemoteGBTCmd)(nil), flags, DefaultServiceNameSpace)
 	MustRegisterCmd("submitBlockHeader", (*SubmitBlockHeaderCmd)(nil), flags, DefaultServiceNameSpace)
+	MustRegisterCmd("getMinerInfo", (*GetMinerInfoCmd)(nil), flags, DefaultServiceNameSpace)
 	MustRegisterCmd("generate", (*GenerateCmd)(nil), flags, MinerNameSpace)
 }
@@ -6,10 +6,46 @@ func NewGetNodeInfoCmd() *GetNodeInfoCmd {
 	return &GetNodeInfoCmd{}
 }
 
-type GetPeerInfoCmd struct{}
+type GetPeerInfoCmd struct {
+	Verbose *bool
+	Network *string
+}
+
+func NewGetPeerInfoCmd(verbose *bool, network *string) *GetPeerInfoCmd {
+	return &GetPeerInfoCmd{
+		Verbose: verbose,
+		Network: network,
+	}
+}
 
-func NewGetPeerInfoCmd() *GetPeerInfoCmd {
-	return &GetPeerInfoCmd{}
+type GetNetworkInfoCmd struct{}
+
+func NewGetNetworkInfoCmd() *GetNetworkInfoCmd {
+	return &GetNetworkInfoCmd{}
+}
+
+type GetSubsidyCmd struct{}
+
+func NewGetSubsidyCmd() *GetSubsidyCmd {
+	return &GetSubsidyCmd{}
+}
+
+type GetRpcModulesCmd struct{}
+
+func NewGetRpcModulesCmd() *GetRpcModulesCmd {
+	return &GetRpcModulesCmd{}
+}
+
+type GetMeerDAGInfoCmd struct{}
+
+func NewGetMeerDAGInfoCmd() *GetMeerDAGInfoCmd {
+	return &GetMeerDAGInfoCmd{}
+}
+
+type GetVMsInfoCmd struct{}
+
+func NewGetVMsInfoCmd() *GetVMsInfoCmd {
+	return &GetVMsInfoCmd{}
 }
 
 type GetRpcInfoCmd struct{}
@@ -68,6 +104,44 @@ func NewCheckAddressCmd(address string, network string) *CheckAddressCmd {
 	}
 }
 
+type VerifyMessageCmd struct {
+	Address   string
+	Signature string
+	Message   string
+}
+
+func NewVerifyMessageCmd(address string, signature string, message string) *VerifyMessageCmd {
+	return &VerifyMessageCmd{
+		Address:   address,
+		Signature: signature,
+		Message:   message,
+	}
+}
+
+type GetDescriptorInfoCmd struct {
+	Descriptor string
+}
+
+func NewGetDescriptorInfoCmd(descriptor string) *GetDescriptorInfoCmd {
+	return &GetDescriptorInfoCmd{
+		Descriptor: descriptor,
+	}
+}
+
+type DeriveAddressesCmd struct {
+	Descriptor string
+	Begin      *uint32
+	End        *uint32
+}
+
+func NewDeriveAddressesCmd(descriptor string, begin *uint32, end *uint32) *DeriveAddressesCmd {
+	return &DeriveAddressesCmd{
+		Descriptor: descriptor,
+		Begin:      begin,
+		End:        end,
+	}
+}
+
 type SetLogLevelCmd struct {
 	Level string
 }
@@ -78,6 +152,16 @@ func NewSetLogLevelCmd(level string) *SetLogLevelCmd {
 	}
 }
 
+type GetAddressesCmd struct {
+	PrivateKeyHex string
+}
+
+func NewGetAddressesCmd(privateKeyHex string) *GetAddressesCmd {
+	return &GetAddressesCmd{
+		PrivateKeyHex: privateKeyHex,
+	}
+}
+
 func init() {
 	flags := UsageFlag(0)
 
@@ -85,12 +169,21 @@ func init() {
 	MustRegisterCmd("getPeerInfo", (*GetPeerInfoCmd)(nil), flags, DefaultServiceNameSpace)
 	MustRegisterCmd("getRpcInfo", (*GetRpcInfoCmd)(nil), flags, DefaultServiceNameSpace)
 	MustRegisterCmd("getTimeInfo", (*GetTimeInfoCmd)(nil), flags, DefaultServiceNameSpace)
+	MustRegisterCmd("getNetworkInfo", (*GetNetworkInfoCmd)(nil), flags, DefaultServiceNameSpace)
+	MustRegisterCmd("getSubsidy", (*GetSubsidyCmd)(nil), flags, DefaultServiceNameSpace)
+	MustRegisterCmd("getRpcModules", (*GetRpcModulesCmd)(nil), flags, DefaultServiceNameSpace)
+	MustRegisterCmd("getMeerDAGInfo", (*GetMeerDAGInfoCmd)(nil), flags, DefaultServiceNameSpace)
+	MustRegisterCmd("getVMsInfo", (*GetVMsInfoCmd)(nil), flags, DefaultServiceNameSpace)
 	MustRegisterCmd("stop", (*StopCmd)(nil), flags, TestNameSpace)
 	MustRegisterCmd("banlist", (*BanlistCmd)(nil), flags, TestNameSpace)
 	MustRegisterCmd("removeBan", (*RemoveBanCmd)(nil), flags, TestNameSpace)
 	MustRegisterCmd("setRpcMaxClients", (*SetRpcMaxClientsCmd)(nil), flags, TestNameSpace)
 
 	MustRegisterCmd("checkAddress", (*CheckAddressCmd)(nil), flags, DefaultServiceNameSpace)
+	MustRegisterCmd("verifyMessage", (*VerifyMessageCmd)(nil), flags, DefaultServiceNameSpace)
+	MustRegisterCmd("getDescriptorInfo", (*GetDescriptorInfoCmd)(nil), flags, DefaultServiceNameSpace)
+	MustRegisterCmd("deriveAddresses", (*DeriveAddressesCmd)(nil), flags, DefaultServiceNameSpace)
+	MustRegisterCmd("getAddresses", (*GetAddressesCmd)(nil), flags, TestNameSpace)
 
 	MustRegisterCmd("setLogLevel", (*SetLogLevelCmd)(nil), flags, LogNameSpace)
 }
@@ -68,6 +68,7 @@ type ReorganizationNtfn struct {
 	Height int64
 	Order  int64
 	Olds   []string
+	Diff   *json.ReorganizationDiff
 }
 
 func NewReorganizationNtfn(hash string, order int64, olds []string) *ReorganizationNtfn {
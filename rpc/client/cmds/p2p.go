@@ -0,0 +1,61 @@
+/*
+ * Copyright (c) 2017-2020 The qitmeer developers
+ */
+
+package cmds
+
+type AddPeerCmd struct {
+	QMAddr string
+}
+
+func NewAddPeerCmd(qmaddr string) *AddPeerCmd {
+	return &AddPeerCmd{
+		QMAddr: qmaddr,
+	}
+}
+
+type DelPeerCmd struct {
+	PID string
+}
+
+func NewDelPeerCmd(pid string) *DelPeerCmd {
+	return &DelPeerCmd{
+		PID: pid,
+	}
+}
+
+type PingCmd struct {
+	Addr     string
+	Port     uint
+	Protocol string
+}
+
+func NewPingCmd(addr string, port uint, protocol string) *PingCmd {
+	return &PingCmd{
+		Addr:     addr,
+		Port:     port,
+		Protocol: protocol,
+	}
+}
+
+type PauseCmd struct{}
+
+func NewPauseCmd() *PauseCmd {
+	return &PauseCmd{}
+}
+
+type ResetPeersCmd struct{}
+
+func NewResetPeersCmd() *ResetPeersCmd {
+	return &ResetPeersCmd{}
+}
+
+func init() {
+	flags := UsageFlag(0)
+
+	MustRegisterCmd("addPeer", (*AddPeerCmd)(nil), flags, P2PNameSpace)
+	MustRegisterCmd("delPeer", (*DelPeerCmd)(nil), flags, P2PNameSpace)
+	MustRegisterCmd("ping", (*PingCmd)(nil), flags, P2PNameSpace)
+	MustRegisterCmd("pause", (*PauseCmd)(nil), flags, P2PNameSpace)
+	MustRegisterCmd("resetPeers", (*ResetPeersCmd)(nil), flags, P2PNameSpace)
+}
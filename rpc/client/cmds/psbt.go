@@ -0,0 +1,82 @@
+/*
+ * Copyright (c) 2017-2020 The qitmeer developers
+ */
+
+package cmds
+
+type CreatePsbtCmd struct {
+	RawTx string
+}
+
+func NewCreatePsbtCmd(rawTx string) *CreatePsbtCmd {
+	return &CreatePsbtCmd{
+		RawTx: rawTx,
+	}
+}
+
+type UpdatePsbtCmd struct {
+	Psbt          string
+	RedeemScripts *[]string
+}
+
+func NewUpdatePsbtCmd(psbt string, redeemScripts *[]string) *UpdatePsbtCmd {
+	return &UpdatePsbtCmd{
+		Psbt:          psbt,
+		RedeemScripts: redeemScripts,
+	}
+}
+
+type CombinePsbtCmd struct {
+	Psbts []string
+}
+
+func NewCombinePsbtCmd(psbts []string) *CombinePsbtCmd {
+	return &CombinePsbtCmd{
+		Psbts: psbts,
+	}
+}
+
+type FinalizePsbtCmd struct {
+	Psbt    string
+	Extract *bool
+}
+
+func NewFinalizePsbtCmd(psbt string, extract *bool) *FinalizePsbtCmd {
+	return &FinalizePsbtCmd{
+		Psbt:    psbt,
+		Extract: extract,
+	}
+}
+
+type DecodePsbtCmd struct {
+	Psbt string
+}
+
+func NewDecodePsbtCmd(psbt string) *DecodePsbtCmd {
+	return &DecodePsbtCmd{
+		Psbt: psbt,
+	}
+}
+
+type SignPsbtCmd struct {
+	Psbt    string
+	Sighash *string
+}
+
+func NewSignPsbtCmd(psbt string, sighash *string) *SignPsbtCmd {
+	return &SignPsbtCmd{
+		Psbt:    psbt,
+		Sighash: sighash,
+	}
+}
+
+func init() {
+	flags := UsageFlag(0)
+
+	MustRegisterCmd("createPsbt", (*CreatePsbtCmd)(nil), flags, DefaultServiceNameSpace)
+	MustRegisterCmd("updatePsbt", (*UpdatePsbtCmd)(nil), flags, DefaultServiceNameSpace)
+	MustRegisterCmd("combinePsbt", (*CombinePsbtCmd)(nil), flags, DefaultServiceNameSpace)
+	MustRegisterCmd("finalizePsbt", (*FinalizePsbtCmd)(nil), flags, DefaultServiceNameSpace)
+	MustRegisterCmd("decodePsbt", (*DecodePsbtCmd)(nil), flags, DefaultServiceNameSpace)
+	MustRegisterCmd("signPsbt", (*SignPsbtCmd)(nil), flags, WalletNameSpace)
+}
@@ -0,0 +1,142 @@
+/*
+ * Copyright (c) 2017-2020 The qitmeer developers
+ */
+
+package cmds
+
+// The topics of the subscribe command.
+const (
+	// SubscribeTopicNewDAGBlocks notifies every block accepted into the
+	// DAG, the result is a SubscribedBlock.
+	SubscribeTopicNewDAGBlocks = "newDAGBlocks"
+
+	// SubscribeTopicBlockConnected notifies every block connected to the
+	// DAG in order, the result is a SubscribedBlock.
+	SubscribeTopicBlockConnected = "blockConnected"
+
+	// SubscribeTopicBlockDisconnected notifies every block disconnected
+	// by a reorganization, the result is a SubscribedBlock.
+	SubscribeTopicBlockDisconnected = "blockDisconnected"
+
+	// SubscribeTopicReorganization notifies the reorganizations of the
+	// block order, the result is a json.ReorganizationDiff.
+	SubscribeTopicReorganization = "reorganization"
+
+	// SubscribeTopicMempool notifies the transactions added to or removed
+	// from the mempool, the result is a SubscribedMempoolTx.
+	SubscribeTopicMempool = "mempool"
+
+	// SubscribeTopicAddressActivity notifies the transactions which pay to
+	// or spend from the addresses of the filter, the result is a
+	// SubscribedAddressActivity.
+	SubscribeTopicAddressActivity = "addressActivity"
+
+	// SubscribeTopicTokenEvents notifies the token transactions of the
+	// connected blocks, optionally only of the coin ids of the filter. The
+	// result is a SubscribedTokenEvent.
+	SubscribeTopicTokenEvents = "tokenEvents"
+
+	// SubscribeTopicLockMatured notifies the locked outputs of the
+	// addresses watched by the account manager (--acctmode) which mature,
+	// optionally only of the addresses of the filter. The result is a
+	// SubscribedLockMatured.
+	SubscribeTopicLockMatured = "lockMatured"
+)
+
+// The actions of a SubscribedMempoolTx.
+const (
+	MempoolActionAdd    = "add"
+	MempoolActionRemove = "remove"
+)
+
+// SubscriptionNtfnMethod is the method of the notifications sent for the
+// subscriptions. Their params are an object with the subscription id and
+// the result.
+const SubscriptionNtfnMethod = "subscription"
+
+// SubscribeFilter restricts the notifications of a subscription.
+type SubscribeFilter struct {
+	// Addresses are the watched addresses of an addressActivity or a
+	// lockMatured subscription.
+	Addresses []string `json:"addresses,omitempty"`
+
+	// CoinIds are the token coin ids of a tokenEvents subscription, all
+	// the tokens are notified when it is empty.
+	CoinIds []uint16 `json:"coinids,omitempty"`
+}
+
+type SubscribeCmd struct {
+	Topic  string
+	Filter *SubscribeFilter
+}
+
+func NewSubscribeCmd(topic string, filter *SubscribeFilter) *SubscribeCmd {
+	return &SubscribeCmd{
+		Topic:  topic,
+		Filter: filter,
+	}
+}
+
+type UnsubscribeCmd struct {
+	ID string
+}
+
+func NewUnsubscribeCmd(id string) *UnsubscribeCmd {
+	return &UnsubscribeCmd{
+		ID: id,
+	}
+}
+
+// SubscribedBlock is the result of the newDAGBlocks, blockConnected and
+// blockDisconnected subscriptions.
+type SubscribedBlock struct {
+	Hash   string   `json:"hash"`
+	Height uint64   `json:"height"`
+	Order  uint64   `json:"order"`
+	Time   int64    `json:"time"`
+	Txs    []string `json:"txs"`
+}
+
+// SubscribedMempoolTx is the result of the mempool subscription.
+type SubscribedMempoolTx struct {
+	Action string `json:"action"`
+	TxID   string `json:"txid"`
+}
+
+// SubscribedAddressActivity is the result of the addressActivity
+// subscription. The block is empty for a transaction of the mempool.
+type SubscribedAddressActivity struct {
+	Address string `json:"address"`
+	TxID    string `json:"txid"`
+	Spent   bool   `json:"spent"`
+	Block   string `json:"block,omitempty"`
+	Order   uint64 `json:"order,omitempty"`
+}
+
+// SubscribedTokenEvent is the result of the tokenEvents subscription.
+type SubscribedTokenEvent struct {
+	Type    string   `json:"type"`
+	TxID    string   `json:"txid"`
+	CoinIds []uint16 `json:"coinids"`
+	Block   string   `json:"block"`
+	Order   uint64   `json:"order"`
+}
+
+// SubscribedLockMatured is the result of the lockMatured subscription, the
+// order and the height are of the main tip when the output matured.
+type SubscribedLockMatured struct {
+	Address string `json:"address"`
+	TxID    string `json:"txid"`
+	Index   uint32 `json:"idx"`
+	Type    string `json:"type"`
+	Amount  uint64 `json:"amount"`
+	Order   uint64 `json:"order"`
+	Height  uint64 `json:"height"`
+}
+
+func init() {
+	flags := UFWebsocketOnly
+
+	MustRegisterCmd("subscribe", (*SubscribeCmd)(nil), flags, NotifyNameSpace)
+	MustRegisterCmd("unsubscribe", (*UnsubscribeCmd)(nil), flags, NotifyNameSpace)
+}
@@ -18,6 +18,117 @@ func NewCreateRawTransactionCmd(inputs []json.TransactionInput, amounts json.Amo
 	}
 }
 
+type CreateRawTransactionV2Cmd struct {
+	Inputs   []json.TransactionInput
+	Amounts  json.AdreesAmount
+	LockTime int64
+}
+
+func NewCreateRawTransactionV2Cmd(inputs []json.TransactionInput, amounts json.AdreesAmount, lockTime int64) *CreateRawTransactionV2Cmd {
+	return &CreateRawTransactionV2Cmd{
+		Inputs:   inputs,
+		Amounts:  amounts,
+		LockTime: lockTime,
+	}
+}
+
+type FundRawTransactionCmd struct {
+	HexTx   string
+	Options json.FundRawTransactionOptions
+}
+
+func NewFundRawTransactionCmd(hexTx string, options json.FundRawTransactionOptions) *FundRawTransactionCmd {
+	return &FundRawTransactionCmd{
+		HexTx:   hexTx,
+		Options: options,
+	}
+}
+
+type TraceTxInputCmd struct {
+	HexTx        string
+	InputIndex   int
+	PrevPkScript string
+	Amount       int64
+}
+
+func NewTraceTxInputCmd(hexTx string, inputIndex int, prevPkScript string, amount int64) *TraceTxInputCmd {
+	return &TraceTxInputCmd{
+		HexTx:        hexTx,
+		InputIndex:   inputIndex,
+		PrevPkScript: prevPkScript,
+		Amount:       amount,
+	}
+}
+
+type CreateTokenRawTransactionCmd struct {
+	TxType   string
+	CoinId   uint16
+	CoinName string
+	Owners   string
+	UpLimit  uint64
+	Inputs   []json.TransactionInput
+	Amounts  json.Amounts
+	FeeType  uint16
+	FeeValue int64
+}
+
+func NewCreateTokenRawTransactionCmd(txType string, coinId uint16, coinName string, owners string, upLimit uint64,
+	inputs []json.TransactionInput, amounts json.Amounts, feeType uint16, feeValue int64) *CreateTokenRawTransactionCmd {
+	return &CreateTokenRawTransactionCmd{
+		TxType:   txType,
+		CoinId:   coinId,
+		CoinName: coinName,
+		Owners:   owners,
+		UpLimit:  upLimit,
+		Inputs:   inputs,
+		Amounts:  amounts,
+		FeeType:  feeType,
+		FeeValue: feeValue,
+	}
+}
+
+type CreateImportRawTransactionCmd struct {
+	PKAddress string
+	Amount    int64
+}
+
+func NewCreateImportRawTransactionCmd(pkAddress string, amount int64) *CreateImportRawTransactionCmd {
+	return &CreateImportRawTransactionCmd{
+		PKAddress: pkAddress,
+		Amount:    amount,
+	}
+}
+
+type CreateExportRawTransactionCmd struct {
+	Txid      string
+	Vout      uint32
+	PKAddress string
+	Amount    int64
+}
+
+func NewCreateExportRawTransactionCmd(txid string, vout uint32, pkAddress string, amount int64) *CreateExportRawTransactionCmd {
+	return &CreateExportRawTransactionCmd{
+		Txid:      txid,
+		Vout:      vout,
+		PKAddress: pkAddress,
+		Amount:    amount,
+	}
+}
+
+type CreateExportRawTransactionV2Cmd struct {
+	Inputs   []json.TransactionInput
+	Outputs  []json.TransactionOutput
+	LockTime int64
+}
+
+func NewCreateExportRawTransactionV2Cmd(inputs []json.TransactionInput, outputs []json.TransactionOutput, lockTime int64) *CreateExportRawTransactionV2Cmd {
+	return &CreateExportRawTransactionV2Cmd{
+		Inputs:   inputs,
+		Outputs:  outputs,
+		LockTime: lockTime,
+	}
+}
+
 type DecodeRawTransactionCmd struct {
 	HexTx string
 }
@@ -52,6 +163,38 @@ func NewGetRawTransactionCmd(txHash string, verbose bool) *GetRawTransactionCmd
 	}
 }
 
+type GetRawTransactionByHashCmd struct {
+	TxHash  string
+	Verbose bool
+}
+
+func NewGetRawTransactionByHashCmd(txHash string, verbose bool) *GetRawTransactionByHashCmd {
+	return &GetRawTransactionByHashCmd{
+		TxHash:  txHash,
+		Verbose: verbose,
+	}
+}
+
+type GetMeerEVMTxHashByIDCmd struct {
+	TxID string
+}
+
+func NewGetMeerEVMTxHashByIDCmd(txid string) *GetMeerEVMTxHashByIDCmd {
+	return &GetMeerEVMTxHashByIDCmd{
+		TxID: txid,
+	}
+}
+
+type GetTxIDByMeerEVMTxHashCmd struct {
+	ETxHash string
+}
+
+func NewGetTxIDByMeerEVMTxHashCmd(etxh string) *GetTxIDByMeerEVMTxHashCmd {
+	return &GetTxIDByMeerEVMTxHashCmd{
+		ETxHash: etxh,
+	}
+}
+
 type GetUtxoCmd struct {
 	TxHash         string
 	Vout           uint32
@@ -66,6 +209,18 @@ func NewGetUtxoCmd(txHash string, vout uint32, includeMempool bool) *GetUtxoCmd
 	}
 }
 
+type GetSpendingTxCmd struct {
+	TxHash string
+	Vout   uint32
+}
+
+func NewGetSpendingTxCmd(txHash string, vout uint32) *GetSpendingTxCmd {
+	return &GetSpendingTxCmd{
+		TxHash: txHash,
+		Vout:   vout,
+	}
+}
+
 type GetRawTransactionsCmd struct {
 	Addre       string
 	Vinext      bool
@@ -112,6 +267,34 @@ func NewGetMempoolCmd(txType string, verbose bool) *GetMempoolCmd {
 	}
 }
 
+type GetMempoolInfoCmd struct{}
+
+func NewGetMempoolInfoCmd() *GetMempoolInfoCmd {
+	return &GetMempoolInfoCmd{}
+}
+
+type GetMempoolCountCmd struct{}
+
+func NewGetMempoolCountCmd() *GetMempoolCountCmd {
+	return &GetMempoolCountCmd{}
+}
+
+type SaveMempoolCmd struct{}
+
+func NewSaveMempoolCmd() *SaveMempoolCmd {
+	return &SaveMempoolCmd{}
+}
+
+type EstimateFeeCmd struct {
+	NumBlocks int64
+}
+
+func NewEstimateFeeCmd(numBlocks int64) *EstimateFeeCmd {
+	return &EstimateFeeCmd{
+		NumBlocks: numBlocks,
+	}
+}
+
 // ws
 type NotifyNewTransactionsCmd struct {
 	Verbose bool
@@ -198,18 +381,111 @@ func StopNotifyTxsByAddrCmd(addr []string) *UnNotifyTxsByAddrCmd {
 	}
 }
 
+type GetCrossChainTransferCmd struct {
+	Id string
+}
+
+func NewGetCrossChainTransferCmd(id string) *GetCrossChainTransferCmd {
+	return &GetCrossChainTransferCmd{
+		Id: id,
+	}
+}
+
+type ListCrossChainTransfersCmd struct {
+	Address string
+	Count   *uint
+	Skip    *uint
+	Reverse *bool
+}
+
+func NewListCrossChainTransfersCmd(address string, count *uint, skip *uint, reverse *bool) *ListCrossChainTransfersCmd {
+	return &ListCrossChainTransfersCmd{
+		Address: address,
+		Count:   count,
+		Skip:    skip,
+		Reverse: reverse,
+	}
+}
+
+type GetIndexInfoCmd struct{}
+
+func NewGetIndexInfoCmd() *GetIndexInfoCmd {
+	return &GetIndexInfoCmd{}
+}
+
+type GetTokenHoldersCmd struct {
+	CoinId uint16
+	Count  *uint
+	Skip   *uint
+}
+
+func NewGetTokenHoldersCmd(coinId uint16, count *uint, skip *uint) *GetTokenHoldersCmd {
+	return &GetTokenHoldersCmd{
+		CoinId: coinId,
+		Count:  count,
+		Skip:   skip,
+	}
+}
+
+type GetTokenHistoryCmd struct {
+	CoinId  uint16
+	Count   *uint
+	Skip    *uint
+	Reverse *bool
+}
+
+func NewGetTokenHistoryCmd(coinId uint16, count *uint, skip *uint, reverse *bool) *GetTokenHistoryCmd {
+	return &GetTokenHistoryCmd{
+		CoinId:  coinId,
+		Count:   count,
+		Skip:    skip,
+		Reverse: reverse,
+	}
+}
+
+type GetTokenBalancesCmd struct {
+	Address string
+}
+
+func NewGetTokenBalancesCmd(address string) *GetTokenBalancesCmd {
+	return &GetTokenBalancesCmd{
+		Address: address,
+	}
+}
+
 func init() {
 	flags := UsageFlag(0)
 
 	MustRegisterCmd("createRawTransaction", (*CreateRawTransactionCmd)(nil), flags, DefaultServiceNameSpace)
+	MustRegisterCmd("createRawTransactionV2", (*CreateRawTransactionV2Cmd)(nil), flags, DefaultServiceNameSpace)
+	MustRegisterCmd("fundRawTransaction", (*FundRawTransactionCmd)(nil), flags, DefaultServiceNameSpace)
+	MustRegisterCmd("traceTxInput", (*TraceTxInputCmd)(nil), flags, DefaultServiceNameSpace)
+	MustRegisterCmd("createTokenRawTransaction", (*CreateTokenRawTransactionCmd)(nil), flags, DefaultServiceNameSpace)
+	MustRegisterCmd("createImportRawTransaction", (*CreateImportRawTransactionCmd)(nil), flags, DefaultServiceNameSpace)
+	MustRegisterCmd("createExportRawTransaction", (*CreateExportRawTransactionCmd)(nil), flags, DefaultServiceNameSpace)
+	MustRegisterCmd("createExportRawTransactionV2", (*CreateExportRawTransactionV2Cmd)(nil), flags, DefaultServiceNameSpace)
 	MustRegisterCmd("decodeRawTransaction", (*DecodeRawTransactionCmd)(nil), flags, DefaultServiceNameSpace)
 	MustRegisterCmd("sendRawTransaction", (*SendRawTransactionCmd)(nil), flags, DefaultServiceNameSpace)
 	MustRegisterCmd("getRawTransaction", (*GetRawTransactionCmd)(nil), flags, DefaultServiceNameSpace)
+	MustRegisterCmd("getRawTransactionByHash", (*GetRawTransactionByHashCmd)(nil), flags, DefaultServiceNameSpace)
+	MustRegisterCmd("getMeerEVMTxHashByID", (*GetMeerEVMTxHashByIDCmd)(nil), flags, DefaultServiceNameSpace)
+	MustRegisterCmd("getTxIDByMeerEVMTxHash", (*GetTxIDByMeerEVMTxHashCmd)(nil), flags, DefaultServiceNameSpace)
 	MustRegisterCmd("getUtxo", (*GetUtxoCmd)(nil), flags, DefaultServiceNameSpace)
+	MustRegisterCmd("getSpendingTx", (*GetSpendingTxCmd)(nil), flags, DefaultServiceNameSpace)
 	MustRegisterCmd("getRawTransactions", (*GetRawTransactionsCmd)(nil), flags, DefaultServiceNameSpace)
+	MustRegisterCmd("getCrossChainTransfer", (*GetCrossChainTransferCmd)(nil), flags, DefaultServiceNameSpace)
+	MustRegisterCmd("listCrossChainTransfers", (*ListCrossChainTransfersCmd)(nil), flags, DefaultServiceNameSpace)
+	MustRegisterCmd("getIndexInfo", (*GetIndexInfoCmd)(nil), flags, DefaultServiceNameSpace)
+	MustRegisterCmd("getTokenHolders", (*GetTokenHoldersCmd)(nil), flags, DefaultServiceNameSpace)
+	MustRegisterCmd("getTokenHistory", (*GetTokenHistoryCmd)(nil), flags, DefaultServiceNameSpace)
+	MustRegisterCmd("getTokenBalances", (*GetTokenBalancesCmd)(nil), flags, DefaultServiceNameSpace)
 	MustRegisterCmd("txSign", (*TxSignCmd)(nil), flags, TestNameSpace)
 
 	MustRegisterCmd("getMempool", (*GetMempoolCmd)(nil), flags, DefaultServiceNameSpace)
+	MustRegisterCmd("getMempoolInfo", (*GetMempoolInfoCmd)(nil), flags, DefaultServiceNameSpace)
+	MustRegisterCmd("getMempoolCount", (*GetMempoolCountCmd)(nil), flags, DefaultServiceNameSpace)
+	MustRegisterCmd("saveMempool", (*SaveMempoolCmd)(nil), flags, DefaultServiceNameSpace)
+	MustRegisterCmd("estimateFee", (*EstimateFeeCmd)(nil), flags, DefaultServiceNameSpace)
 
 	// ws
 	MustRegisterCmd("notifynewtransactions", (*NotifyNewTransactionsCmd)(nil), UFWebsocketOnly, NotifyNameSpace)
@@ -0,0 +1,128 @@
+/*
+ * Copyright (c) 2017-2020 The qitmeer developers
+ */
+
+package cmds
+
+import (
+	"github.com/Qitmeer/qng/core/json"
+)
+
+type CreateWalletCmd struct {
+	Passphrase string
+	Seed       *string
+}
+
+func NewCreateWalletCmd(passphrase string, seed *string) *CreateWalletCmd {
+	return &CreateWalletCmd{
+		Passphrase: passphrase,
+		Seed:       seed,
+	}
+}
+
+type UnlockCmd struct {
+	Passphrase string
+	Timeout    uint32
+}
+
+func NewUnlockCmd(passphrase string, timeout uint32) *UnlockCmd {
+	return &UnlockCmd{
+		Passphrase: passphrase,
+		Timeout:    timeout,
+	}
+}
+
+type LockCmd struct{}
+
+func NewLockCmd() *LockCmd {
+	return &LockCmd{}
+}
+
+type GetWalletInfoCmd struct{}
+
+func NewGetWalletInfoCmd() *GetWalletInfoCmd {
+	return &GetWalletInfoCmd{}
+}
+
+type GetNewAddressCmd struct {
+	Account *string
+}
+
+func NewGetNewAddressCmd(account *string) *GetNewAddressCmd {
+	return &GetNewAddressCmd{
+		Account: account,
+	}
+}
+
+type ListUnspentCmd struct {
+	CoinID *uint16
+	Addrs  *[]string
+}
+
+func NewListUnspentCmd(coinID *uint16, addrs *[]string) *ListUnspentCmd {
+	return &ListUnspentCmd{
+		CoinID: coinID,
+		Addrs:  addrs,
+	}
+}
+
+type SendToAddressCmd struct {
+	Addr   string
+	Amount int64
+	CoinID *uint16
+}
+
+func NewSendToAddressCmd(addr string, amount int64, coinID *uint16) *SendToAddressCmd {
+	return &SendToAddressCmd{
+		Addr:   addr,
+		Amount: amount,
+		CoinID: coinID,
+	}
+}
+
+type SendManyCmd struct {
+	Amounts json.AdreesAmount
+}
+
+func NewSendManyCmd(amounts json.AdreesAmount) *SendManyCmd {
+	return &SendManyCmd{
+		Amounts: amounts,
+	}
+}
+
+type SignRawTransactionWithWalletCmd struct {
+	RawTx string
+}
+
+func NewSignRawTransactionWithWalletCmd(rawTx string) *SignRawTransactionWithWalletCmd {
+	return &SignRawTransactionWithWalletCmd{
+		RawTx: rawTx,
+	}
+}
+
+type SignMessageCmd struct {
+	Address string
+	Message string
+}
+
+func NewSignMessageCmd(address string, message string) *SignMessageCmd {
+	return &SignMessageCmd{
+		Address: address,
+		Message: message,
+	}
+}
+
+func init() {
+	flags := UsageFlag(0)
+
+	MustRegisterCmd("createWallet", (*CreateWalletCmd)(nil), flags, WalletNameSpace)
+	MustRegisterCmd("unlock", (*UnlockCmd)(nil), flags, WalletNameSpace)
+	MustRegisterCmd("lock", (*LockCmd)(nil), flags, WalletNameSpace)
+	MustRegisterCmd("getWalletInfo", (*GetWalletInfoCmd)(nil), flags, WalletNameSpace)
+	MustRegisterCmd("getNewAddress", (*GetNewAddressCmd)(nil), flags, WalletNameSpace)
+	MustRegisterCmd("listUnspent", (*ListUnspentCmd)(nil), flags, WalletNameSpace)
+	MustRegisterCmd("sendToAddress", (*SendToAddressCmd)(nil), flags, WalletNameSpace)
+	MustRegisterCmd("sendMany", (*SendManyCmd)(nil), flags, WalletNameSpace)
+	MustRegisterCmd("signRawTransactionWithWallet", (*SignRawTransactionWithWalletCmd)(nil), flags, WalletNameSpace)
+	MustRegisterCmd("signMessage", (*SignMessageCmd)(nil), flags, WalletNameSpace)
+}
@@ -73,6 +73,16 @@ type ConnConfig struct {
 	// ExtraHeaders specifies the extra headers when perform request. It's
 	// useful when RPC provider need customized headers.
 	ExtraHeaders map[string]string
+
+	// MaxRetries is the number of times a call issued with CallContext is
+	// retried when its request could not be sent to the server. The calls
+	// are not retried when it is zero.
+	MaxRetries int
+
+	// RetryInterval is the amount of time to wait before the first retry,
+	// the interval is doubled on each following retry. It defaults to
+	// one second.
+	RetryInterval time.Duration
 }
 
 func (config *ConnConfig) getAuth() (username, passphrase string, err error) {
@@ -0,0 +1,55 @@
+/*
+ * Copyright (c) 2017-2020 The qitmeer developers
+ */
+
+package client
+
+import (
+	"encoding/json"
+	j "github.com/Qitmeer/qng/core/json"
+	"github.com/Qitmeer/qng/rpc/client/cmds"
+)
+
+type FutureCreateHTLCResult chan *response
+
+func (r FutureCreateHTLCResult) Receive() (*j.HTLCResult, error) {
+	res, err := receiveFuture(r)
+	if err != nil {
+		return nil, err
+	}
+
+	var result j.HTLCResult
+	err = json.Unmarshal(res, &result)
+	if err != nil {
+		return nil, err
+	}
+
+	return &result, nil
+}
+
+func (c *Client) CreateHTLCAsync(recipient string, refund string, secretHash string, lockTime int64, hashType *string) FutureCreateHTLCResult {
+	cmd := cmds.NewCreateHTLCCmd(recipient, refund, secretHash, lockTime, hashType)
+	return c.sendCmd(cmd)
+}
+
+func (c *Client) CreateHTLC(recipient string, refund string, secretHash string, lockTime int64, hashType *string) (*j.HTLCResult, error) {
+	return c.CreateHTLCAsync(recipient, refund, secretHash, lockTime, hashType).Receive()
+}
+
+func (c *Client) RedeemHTLCAsync(rawTx string, inputIndex int, contract string, secret string) FutureCreateRawTransactionResult {
+	cmd := cmds.NewRedeemHTLCCmd(rawTx, inputIndex, contract, secret)
+	return c.sendCmd(cmd)
+}
+
+func (c *Client) RedeemHTLC(rawTx string, inputIndex int, contract string, secret string) (string, error) {
+	return c.RedeemHTLCAsync(rawTx, inputIndex, contract, secret).Receive()
+}
+
+func (c *Client) RefundHTLCAsync(rawTx string, inputIndex int, contract string) FutureCreateRawTransactionResult {
+	cmd := cmds.NewRefundHTLCCmd(rawTx, inputIndex, contract)
+	return c.sendCmd(cmd)
+}
+
+func (c *Client) RefundHTLC(rawTx string, inputIndex int, contract string) (string, error) {
+	return c.RefundHTLCAsync(rawTx, inputIndex, contract).Receive()
+}
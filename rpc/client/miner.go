@@ -136,3 +136,27 @@ func (c *Client) SubmitBlockHeaderAsync(header *types.BlockHeader) FutureSubmitB
 func (c *Client) SubmitBlockHeader(header *types.BlockHeader) (*j.SubmitBlockResult, error) {
 	return c.SubmitBlockHeaderAsync(header).Receive()
 }
+
+type FutureGetMinerInfoResult chan *response
+
+func (r FutureGetMinerInfoResult) Receive() (*j.MinerInfoResult, error) {
+	res, err := receiveFuture(r)
+	if err != nil {
+		return nil, err
+	}
+	var info j.MinerInfoResult
+	err = json.Unmarshal(res, &info)
+	if err != nil {
+		return nil, err
+	}
+	return &info, nil
+}
+
+func (c *Client) GetMinerInfoAsync() FutureGetMinerInfoResult {
+	cmd := cmds.NewGetMinerInfoCmd()
+	return c.sendCmd(cmd)
+}
+
+func (c *Client) GetMinerInfo() (*j.MinerInfoResult, error) {
+	return c.GetMinerInfoAsync().Receive()
+}
@@ -52,7 +52,7 @@ func (r FutureGetPeerInfoResult) Receive() ([]j.GetPeerInfoResult, error) {
 }
 
 func (c *Client) GetPeerInfoAsync() FutureGetPeerInfoResult {
-	cmd := cmds.NewGetPeerInfoCmd()
+	cmd := cmds.NewGetPeerInfoCmd(nil, nil)
 	return c.sendCmd(cmd)
 }
 
@@ -60,6 +60,150 @@ func (c *Client) GetPeerInfo() ([]j.GetPeerInfoResult, error) {
 	return c.GetPeerInfoAsync().Receive()
 }
 
+func (c *Client) GetPeerInfoByNetworkAsync(verbose bool, network string) FutureGetPeerInfoResult {
+	cmd := cmds.NewGetPeerInfoCmd(&verbose, &network)
+	return c.sendCmd(cmd)
+}
+
+// GetPeerInfoByNetwork returns the peers of the network, "all" returns the
+// peers of every network. The disconnected peers are included when verbose
+// is set.
+func (c *Client) GetPeerInfoByNetwork(verbose bool, network string) ([]j.GetPeerInfoResult, error) {
+	return c.GetPeerInfoByNetworkAsync(verbose, network).Receive()
+}
+
+type FutureGetNetworkInfoResult chan *response
+
+func (r FutureGetNetworkInfoResult) Receive() (*j.NetworkStat, error) {
+	res, err := receiveFuture(r)
+	if err != nil {
+		return nil, err
+	}
+
+	var result j.NetworkStat
+	err = json.Unmarshal(res, &result)
+	if err != nil {
+		return nil, err
+	}
+
+	return &result, nil
+}
+
+func (c *Client) GetNetworkInfoAsync() FutureGetNetworkInfoResult {
+	cmd := cmds.NewGetNetworkInfoCmd()
+	return c.sendCmd(cmd)
+}
+
+func (c *Client) GetNetworkInfo() (*j.NetworkStat, error) {
+	return c.GetNetworkInfoAsync().Receive()
+}
+
+type FutureGetSubsidyResult chan *response
+
+func (r FutureGetSubsidyResult) Receive() (*j.SubsidyInfo, error) {
+	res, err := receiveFuture(r)
+	if err != nil {
+		return nil, err
+	}
+
+	var result j.SubsidyInfo
+	err = json.Unmarshal(res, &result)
+	if err != nil {
+		return nil, err
+	}
+
+	return &result, nil
+}
+
+func (c *Client) GetSubsidyAsync() FutureGetSubsidyResult {
+	cmd := cmds.NewGetSubsidyCmd()
+	return c.sendCmd(cmd)
+}
+
+func (c *Client) GetSubsidy() (*j.SubsidyInfo, error) {
+	return c.GetSubsidyAsync().Receive()
+}
+
+type FutureGetRpcModulesResult chan *response
+
+// Receive returns the rpc modules of the node and whether they are enabled.
+func (r FutureGetRpcModulesResult) Receive() (map[string]bool, error) {
+	res, err := receiveFuture(r)
+	if err != nil {
+		return nil, err
+	}
+
+	result := map[string]bool{}
+	err = json.Unmarshal(res, &result)
+	if err != nil {
+		return nil, err
+	}
+
+	return result, nil
+}
+
+func (c *Client) GetRpcModulesAsync() FutureGetRpcModulesResult {
+	cmd := cmds.NewGetRpcModulesCmd()
+	return c.sendCmd(cmd)
+}
+
+func (c *Client) GetRpcModules() (map[string]bool, error) {
+	return c.GetRpcModulesAsync().Receive()
+}
+
+type FutureGetMeerDAGInfoResult chan *response
+
+func (r FutureGetMeerDAGInfoResult) Receive() (*j.MeerDAGInfoResult, error) {
+	res, err := receiveFuture(r)
+	if err != nil {
+		return nil, err
+	}
+
+	var result j.MeerDAGInfoResult
+	err = json.Unmarshal(res, &result)
+	if err != nil {
+		return nil, err
+	}
+
+	return &result, nil
+}
+
+func (c *Client) GetMeerDAGInfoAsync() FutureGetMeerDAGInfoResult {
+	cmd := cmds.NewGetMeerDAGInfoCmd()
+	return c.sendCmd(cmd)
+}
+
+func (c *Client) GetMeerDAGInfo() (*j.MeerDAGInfoResult, error) {
+	return c.GetMeerDAGInfoAsync().Receive()
+}
+
+type FutureGetVMsInfoResult chan *response
+
+// Receive returns the version information of each VM keyed by the VM id.
+func (r FutureGetVMsInfoResult) Receive() (map[string]map[string]string, error) {
+	res, err := receiveFuture(r)
+	if err != nil {
+		return nil, err
+	}
+
+	result := map[string]map[string]string{}
+	err = json.Unmarshal(res, &result)
+	if err != nil {
+		return nil, err
+	}
+
+	return result, nil
+}
+
+func (c *Client) GetVMsInfoAsync() FutureGetVMsInfoResult {
+	cmd := cmds.NewGetVMsInfoCmd()
+	return c.sendCmd(cmd)
+}
+
+func (c *Client) GetVMsInfo() (map[string]map[string]string, error) {
+	return c.GetVMsInfoAsync().Receive()
+}
+
 type FutureCheckAddressResult chan *response
 
 func (r FutureCheckAddressResult) Receive() (bool, error) {
@@ -85,6 +229,109 @@ func (c *Client) CheckAddress(address string, network string) (bool, error) {
 	return c.CheckAddressAsync(address, network).Receive()
 }
 
+type FutureVerifyMessageResult chan *response
+
+func (r FutureVerifyMessageResult) Receive() (bool, error) {
+	res, err := receiveFuture(r)
+	if err != nil {
+		return false, err
+	}
+	var result bool
+	err = json.Unmarshal(res, &result)
+	if err != nil {
+		return false, err
+	}
+
+	return result, nil
+}
+
+func (c *Client) VerifyMessageAsync(address string, signature string, message string) FutureVerifyMessageResult {
+	cmd := cmds.NewVerifyMessageCmd(address, signature, message)
+	return c.sendCmd(cmd)
+}
+
+func (c *Client) VerifyMessage(address string, signature string, message string) (bool, error) {
+	return c.VerifyMessageAsync(address, signature, message).Receive()
+}
+
+type FutureGetDescriptorInfoResult chan *response
+
+func (r FutureGetDescriptorInfoResult) Receive() (*j.DescriptorInfoResult, error) {
+	res, err := receiveFuture(r)
+	if err != nil {
+		return nil, err
+	}
+	var result j.DescriptorInfoResult
+	err = json.Unmarshal(res, &result)
+	if err != nil {
+		return nil, err
+	}
+
+	return &result, nil
+}
+
+func (c *Client) GetDescriptorInfoAsync(descriptor string) FutureGetDescriptorInfoResult {
+	cmd := cmds.NewGetDescriptorInfoCmd(descriptor)
+	return c.sendCmd(cmd)
+}
+
+func (c *Client) GetDescriptorInfo(descriptor string) (*j.DescriptorInfoResult, error) {
+	return c.GetDescriptorInfoAsync(descriptor).Receive()
+}
+
+type FutureDeriveAddressesResult chan *response
+
+func (r FutureDeriveAddressesResult) Receive() ([]string, error) {
+	res, err := receiveFuture(r)
+	if err != nil {
+		return nil, err
+	}
+	var result []string
+	err = json.Unmarshal(res, &result)
+	if err != nil {
+		return nil, err
+	}
+
+	return result, nil
+}
+
+func (c *Client) DeriveAddressesAsync(descriptor string, begin *uint32, end *uint32) FutureDeriveAddressesResult {
+	cmd := cmds.NewDeriveAddressesCmd(descriptor, begin, end)
+	return c.sendCmd(cmd)
+}
+
+func (c *Client) DeriveAddresses(descriptor string, begin *uint32, end *uint32) ([]string, error) {
+	return c.DeriveAddressesAsync(descriptor, begin, end).Receive()
+}
+
+type FutureGetAddressesResult chan *response
+
+// Receive returns the private key and the addresses derived from it, keyed
+// by the address kind.
+func (r FutureGetAddressesResult) Receive() (map[string]string, error) {
+	res, err := receiveFuture(r)
+	if err != nil {
+		return nil, err
+	}
+
+	result := map[string]string{}
+	err = json.Unmarshal(res, &result)
+	if err != nil {
+		return nil, err
+	}
+
+	return result, nil
+}
+
+func (c *Client) GetAddressesAsync(privateKeyHex string) FutureGetAddressesResult {
+	cmd := cmds.NewGetAddressesCmd(privateKeyHex)
+	return c.sendCmd(cmd)
+}
+
+func (c *Client) GetAddresses(privateKeyHex string) (map[string]string, error) {
+	return c.GetAddressesAsync(privateKeyHex).Receive()
+}
+
 type FutureGetRpcInfoResult chan *response
 
 func (r FutureGetRpcInfoResult) Receive() (*cmds.JsonRequestStatus, error) {
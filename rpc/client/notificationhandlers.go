@@ -21,6 +21,12 @@ type NotificationHandlers struct {
 	OnBlockDisconnected func(hash *hash.Hash, height, order int64, t time.Time, txs []*types.Transaction)
 	OnBlockAccepted     func(hash *hash.Hash, height, order int64, t time.Time, txs []*types.Transaction)
 	OnReorganization    func(hash *hash.Hash, order int64, olds []*hash.Hash)
+
+	// OnReorganizationDiff is invoked with the full diff of a
+	// reorganization: the old and new orders, the blue/red flips and the
+	// transactions whose validity changed.
+	OnReorganizationDiff func(diff *j.ReorganizationDiff)
+
 	OnTxAccepted        func(hash *hash.Hash, amounts types.AmountGroup)
 	OnTxAcceptedVerbose func(c *Client, tx *j.DecodeRawTransactionResult)
 	OnTxConfirm         func(txConfirm *cmds.TxConfirmResult)
@@ -29,6 +35,12 @@ type NotificationHandlers struct {
 	OnNodeExit          func(nodeExit *cmds.NodeExitNtfn)
 	OnBlockTemplate     func(bt *j.RemoteGBTResult)
 
+	// OnSubscription is invoked for the notifications of the
+	// subscriptions made by Subscribe. The id is the one returned by
+	// Subscribe, it is kept across reconnects. The result can be
+	// unmarshalled into the cmds.Subscribed* type of the topic.
+	OnSubscription func(id string, topic string, result json.RawMessage)
+
 	OnUnknownNotification func(method string, params []json.RawMessage)
 }
 
@@ -97,7 +109,8 @@ func parseChainNtfnParams(params []json.RawMessage) (*hash.Hash, int64, int64, t
 }
 
 func parseReorganizationNtfnParams(params []json.RawMessage) (*hash.Hash, int64, []*hash.Hash, error) {
-	if len(params) != 4 {
+	// The diff of the reorganization is an optional fifth parameter.
+	if len(params) != 4 && len(params) != 5 {
 		return nil, 0, nil, wrongNumParams(len(params))
 	}
 
@@ -108,9 +121,9 @@ func parseReorganizationNtfnParams(params []json.RawMessage) (*hash.Hash, int64,
 		return nil, 0, nil, err
 	}
 
-	// Unmarshal second parameter as an integer.
+	// Unmarshal third parameter as an integer.
 	var blockOrder int64
-	err = json.Unmarshal(params[1], &blockOrder)
+	err = json.Unmarshal(params[2], &blockOrder)
 	if err != nil {
 		return nil, 0, nil, err
 	}
@@ -138,6 +151,18 @@ func parseReorganizationNtfnParams(params []json.RawMessage) (*hash.Hash, int64,
 	return blockHash, blockOrder, olds, nil
 }
 
+func parseReorganizationDiffNtfnParams(params []json.RawMessage) (*j.ReorganizationDiff, error) {
+	if len(params) != 5 {
+		return nil, wrongNumParams(len(params))
+	}
+	var diff j.ReorganizationDiff
+	err := json.Unmarshal(params[4], &diff)
+	if err != nil {
+		return nil, err
+	}
+	return &diff, nil
+}
+
 func parseTxAcceptedNtfnParams(params []json.RawMessage) (*hash.Hash,
 	types.AmountGroup, error) {
 
@@ -277,3 +302,18 @@ func parseBlockTemplateNtfnParams(params []json.RawMessage) (*j.RemoteGBTResult,
 	}
 	return &rawTx, nil
 }
+
+func parseSubscriptionNtfnParams(params []json.RawMessage) (string, json.RawMessage, error) {
+	if len(params) != 1 {
+		return "", nil, wrongNumParams(len(params))
+	}
+	var sub struct {
+		Subscription string          `json:"subscription"`
+		Result       json.RawMessage `json:"result"`
+	}
+	err := json.Unmarshal(params[0], &sub)
+	if err != nil {
+		return "", nil, err
+	}
+	return sub.Subscription, sub.Result, nil
+}
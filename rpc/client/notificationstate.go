@@ -4,11 +4,23 @@
 
 package client
 
+import "github.com/Qitmeer/qng/rpc/client/cmds"
+
+// subscriptionState is a subscription made by the subscribe command. The id
+// is the server id of the first subscribe, serverID is the id of the last
+// one, they differ once the subscription was re-established on reconnect.
+type subscriptionState struct {
+	id       string
+	serverID string
+	cmd      *cmds.SubscribeCmd
+}
+
 type notificationState struct {
 	notifyBlocks       bool
 	notifyNewTx        bool
 	notifyNewTxVerbose bool
 	notifyReceived     map[string]struct{}
+	subscriptions      map[string]*subscriptionState
 }
 
 func (s *notificationState) Copy() *notificationState {
@@ -20,11 +32,16 @@ func (s *notificationState) Copy() *notificationState {
 	for addr := range s.notifyReceived {
 		stateCopy.notifyReceived[addr] = struct{}{}
 	}
+	stateCopy.subscriptions = make(map[string]*subscriptionState)
+	for id, sub := range s.subscriptions {
+		stateCopy.subscriptions[id] = sub
+	}
 	return &stateCopy
 }
 
 func newNotificationState() *notificationState {
 	return &notificationState{
 		notifyReceived: make(map[string]struct{}),
+		subscriptions:  make(map[string]*subscriptionState),
 	}
 }
@@ -1,6 +1,7 @@
 package client
 
 import (
+	"encoding/json"
 	"errors"
 	"github.com/Qitmeer/qng/core/types"
 	"github.com/Qitmeer/qng/rpc/client/cmds"
@@ -13,6 +14,12 @@ var (
 	// configured to run in HTTP POST mode.
 	ErrWebsocketsRequired = errors.New("a websocket connection is required " +
 		"to use this feature")
+
+	// ErrNoNotificationHandlers is an error to describe the condition where
+	// the caller subscribes to notifications on a client which was created
+	// without notification handlers.
+	ErrNoNotificationHandlers = errors.New("the client has no notification " +
+		"handlers")
 )
 
 type FutureNotifyBlocksResult chan *response
@@ -264,3 +271,96 @@ func (c *Client) StopNotifyNewTransactionsAsync() FutureStopNotifyNewTransaction
 func (c *Client) StopNotifyNewTransactions() error {
 	return c.StopNotifyNewTransactionsAsync().Receive()
 }
+
+type FutureSubscribeResult chan *response
+
+// Receive waits for the response promised by the future and returns the id
+// of the subscription.
+func (r FutureSubscribeResult) Receive() (string, error) {
+	res, err := receiveFuture(r)
+	if err != nil {
+		return "", err
+	}
+	var id string
+	err = json.Unmarshal(res, &id)
+	if err != nil {
+		return "", err
+	}
+	return id, nil
+}
+
+// SubscribeAsync returns an instance of a type that can be used to get the
+// result of the RPC at some future time by invoking the Receive function on
+// the returned instance.
+//
+// See Subscribe for the blocking version and more details.
+func (c *Client) SubscribeAsync(topic string, filter *cmds.SubscribeFilter) FutureSubscribeResult {
+	// Not supported in HTTP POST mode.
+	if c.config.HTTPPostMode {
+		return newFutureError(ErrWebsocketsRequired)
+	}
+
+	// The notifications of the subscription can not be delivered when the
+	// client is not interested in notifications.
+	if c.ntfnHandlers == nil {
+		return newFutureError(ErrNoNotificationHandlers)
+	}
+
+	cmd := cmds.NewSubscribeCmd(topic, filter)
+	return c.sendCmd(cmd)
+}
+
+// Subscribe subscribes to one of the cmds.SubscribeTopic* topics and returns
+// the id of the subscription. Its notifications are delivered to the
+// OnSubscription handler with this id. Several subscriptions can be made on
+// the same connection, they are re-established when the client reconnects.
+func (c *Client) Subscribe(topic string, filter *cmds.SubscribeFilter) (string, error) {
+	return c.SubscribeAsync(topic, filter).Receive()
+}
+
+type FutureUnsubscribeResult chan *response
+
+// Receive waits for the response promised by the future and returns
+// whether the subscription existed on the server.
+func (r FutureUnsubscribeResult) Receive() (bool, error) {
+	res, err := receiveFuture(r)
+	if err != nil {
+		return false, err
+	}
+	var exists bool
+	err = json.Unmarshal(res, &exists)
+	if err != nil {
+		return false, err
+	}
+	return exists, nil
+}
+
+// UnsubscribeAsync returns an instance of a type that can be used to get the
+// result of the RPC at some future time by invoking the Receive function on
+// the returned instance.
+//
+// See Unsubscribe for the blocking version and more details.
+func (c *Client) UnsubscribeAsync(id string) FutureUnsubscribeResult {
+	// Not supported in HTTP POST mode.
+	if c.config.HTTPPostMode {
+		return newFutureError(ErrWebsocketsRequired)
+	}
+
+	serverID := id
+	if c.ntfnHandlers != nil {
+		c.ntfnStateLock.Lock()
+		if sub, ok := c.ntfnState.subscriptions[id]; ok {
+			serverID = sub.serverID
+			delete(c.ntfnState.subscriptions, id)
+		}
+		c.ntfnStateLock.Unlock()
+	}
+
+	cmd := cmds.NewUnsubscribeCmd(serverID)
+	return c.sendCmd(cmd)
+}
+
+// Unsubscribe cancels the subscription with the id returned by Subscribe.
+func (c *Client) Unsubscribe(id string) (bool, error) {
+	return c.UnsubscribeAsync(id).Receive()
+}
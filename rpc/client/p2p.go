@@ -0,0 +1,140 @@
+/*
+ * Copyright (c) 2017-2020 The qitmeer developers
+ */
+
+package client
+
+import (
+	"encoding/json"
+	"github.com/Qitmeer/qng/rpc/client/cmds"
+)
+
+type FutureAddPeerResult chan *response
+
+func (r FutureAddPeerResult) Receive() (bool, error) {
+	res, err := receiveFuture(r)
+	if err != nil {
+		return false, err
+	}
+
+	var result bool
+	err = json.Unmarshal(res, &result)
+	if err != nil {
+		return false, err
+	}
+
+	return result, nil
+}
+
+func (c *Client) AddPeerAsync(qmaddr string) FutureAddPeerResult {
+	cmd := cmds.NewAddPeerCmd(qmaddr)
+	return c.sendCmd(cmd)
+}
+
+func (c *Client) AddPeer(qmaddr string) (bool, error) {
+	return c.AddPeerAsync(qmaddr).Receive()
+}
+
+type FutureDelPeerResult chan *response
+
+func (r FutureDelPeerResult) Receive() (bool, error) {
+	res, err := receiveFuture(r)
+	if err != nil {
+		return false, err
+	}
+
+	var result bool
+	err = json.Unmarshal(res, &result)
+	if err != nil {
+		return false, err
+	}
+
+	return result, nil
+}
+
+func (c *Client) DelPeerAsync(pid string) FutureDelPeerResult {
+	cmd := cmds.NewDelPeerCmd(pid)
+	return c.sendCmd(cmd)
+}
+
+func (c *Client) DelPeer(pid string) (bool, error) {
+	return c.DelPeerAsync(pid).Receive()
+}
+
+type FuturePingResult chan *response
+
+func (r FuturePingResult) Receive() (string, error) {
+	res, err := receiveFuture(r)
+	if err != nil {
+		return "", err
+	}
+
+	var result string
+	err = json.Unmarshal(res, &result)
+	if err != nil {
+		return "", err
+	}
+
+	return result, nil
+}
+
+func (c *Client) PingAsync(addr string, port uint, protocol string) FuturePingResult {
+	cmd := cmds.NewPingCmd(addr, port, protocol)
+	return c.sendCmd(cmd)
+}
+
+func (c *Client) Ping(addr string, port uint, protocol string) (string, error) {
+	return c.PingAsync(addr, port, protocol).Receive()
+}
+
+type FuturePauseResult chan *response
+
+func (r FuturePauseResult) Receive() (bool, error) {
+	res, err := receiveFuture(r)
+	if err != nil {
+		return false, err
+	}
+
+	var result bool
+	err = json.Unmarshal(res, &result)
+	if err != nil {
+		return false, err
+	}
+
+	return result, nil
+}
+
+func (c *Client) PauseAsync() FuturePauseResult {
+	cmd := cmds.NewPauseCmd()
+	return c.sendCmd(cmd)
+}
+
+func (c *Client) Pause() (bool, error) {
+	return c.PauseAsync().Receive()
+}
+
+type FutureResetPeersResult chan *response
+
+func (r FutureResetPeersResult) Receive() (int, error) {
+	res, err := receiveFuture(r)
+	if err != nil {
+		return 0, err
+	}
+
+	var result int
+	err = json.Unmarshal(res, &result)
+	if err != nil {
+		return 0, err
+	}
+
+	return result, nil
+}
+
+func (c *Client) ResetPeersAsync() FutureResetPeersResult {
+	cmd := cmds.NewResetPeersCmd()
+	return c.sendCmd(cmd)
+}
+
+func (c *Client) ResetPeers() (int, error) {
+	return c.ResetPeersAsync().Receive()
+}
@@ -0,0 +1,131 @@
+/*
+ * Copyright (c) 2017-2020 The qitmeer developers
+ */
+
+package client
+
+import (
+	"encoding/json"
+	j "github.com/Qitmeer/qng/core/json"
+	"github.com/Qitmeer/qng/rpc/client/cmds"
+)
+
+type FuturePsbtResult chan *response
+
+func (r FuturePsbtResult) Receive() (string, error) {
+	res, err := receiveFuture(r)
+	if err != nil {
+		return "", err
+	}
+	var psbt string
+	err = json.Unmarshal(res, &psbt)
+	if err != nil {
+		return "", err
+	}
+	return psbt, nil
+}
+
+func (c *Client) CreatePsbtAsync(rawTx string) FuturePsbtResult {
+	cmd := cmds.NewCreatePsbtCmd(rawTx)
+	return c.sendCmd(cmd)
+}
+
+func (c *Client) CreatePsbt(rawTx string) (string, error) {
+	return c.CreatePsbtAsync(rawTx).Receive()
+}
+
+func (c *Client) UpdatePsbtAsync(psbt string, redeemScripts *[]string) FuturePsbtResult {
+	cmd := cmds.NewUpdatePsbtCmd(psbt, redeemScripts)
+	return c.sendCmd(cmd)
+}
+
+func (c *Client) UpdatePsbt(psbt string, redeemScripts *[]string) (string, error) {
+	return c.UpdatePsbtAsync(psbt, redeemScripts).Receive()
+}
+
+func (c *Client) CombinePsbtAsync(psbts []string) FuturePsbtResult {
+	cmd := cmds.NewCombinePsbtCmd(psbts)
+	return c.sendCmd(cmd)
+}
+
+func (c *Client) CombinePsbt(psbts []string) (string, error) {
+	return c.CombinePsbtAsync(psbts).Receive()
+}
+
+type FutureFinalizePsbtResult chan *response
+
+func (r FutureFinalizePsbtResult) Receive() (*j.FinalizePsbtResult, error) {
+	res, err := receiveFuture(r)
+	if err != nil {
+		return nil, err
+	}
+
+	var result j.FinalizePsbtResult
+	err = json.Unmarshal(res, &result)
+	if err != nil {
+		return nil, err
+	}
+
+	return &result, nil
+}
+
+func (c *Client) FinalizePsbtAsync(psbt string, extract *bool) FutureFinalizePsbtResult {
+	cmd := cmds.NewFinalizePsbtCmd(psbt, extract)
+	return c.sendCmd(cmd)
+}
+
+func (c *Client) FinalizePsbt(psbt string, extract *bool) (*j.FinalizePsbtResult, error) {
+	return c.FinalizePsbtAsync(psbt, extract).Receive()
+}
+
+type FutureDecodePsbtResult chan *response
+
+func (r FutureDecodePsbtResult) Receive() (*j.DecodePsbtResult, error) {
+	res, err := receiveFuture(r)
+	if err != nil {
+		return nil, err
+	}
+
+	var result j.DecodePsbtResult
+	err = json.Unmarshal(res, &result)
+	if err != nil {
+		return nil, err
+	}
+
+	return &result, nil
+}
+
+func (c *Client) DecodePsbtAsync(psbt string) FutureDecodePsbtResult {
+	cmd := cmds.NewDecodePsbtCmd(psbt)
+	return c.sendCmd(cmd)
+}
+
+func (c *Client) DecodePsbt(psbt string) (*j.DecodePsbtResult, error) {
+	return c.DecodePsbtAsync(psbt).Receive()
+}
+
+type FutureSignPsbtResult chan *response
+
+func (r FutureSignPsbtResult) Receive() (*j.PsbtResult, error) {
+	res, err := receiveFuture(r)
+	if err != nil {
+		return nil, err
+	}
+
+	var result j.PsbtResult
+	err = json.Unmarshal(res, &result)
+	if err != nil {
+		return nil, err
+	}
+
+	return &result, nil
+}
+
+func (c *Client) SignPsbtAsync(psbt string, sighash *string) FutureSignPsbtResult {
+	cmd := cmds.NewSignPsbtCmd(psbt, sighash)
+	return c.sendCmd(cmd)
+}
+
+func (c *Client) SignPsbt(psbt string, sighash *string) (*j.PsbtResult, error) {
+	return c.SignPsbtAsync(psbt, sighash).Receive()
+}
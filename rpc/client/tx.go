@@ -6,6 +6,7 @@ import (
 	"github.com/Qitmeer/qng/common/hash"
 	j "github.com/Qitmeer/qng/core/json"
 	"github.com/Qitmeer/qng/rpc/client/cmds"
+	"strconv"
 )
 
 type FutureCreateRawTransactionResult chan *response
@@ -156,6 +157,34 @@ func (c *Client) GetUtxo(txHash string, vout uint32, includeMempool bool) (*j.Ge
 	return c.GetUtxoAsync(txHash, vout, includeMempool).Receive()
 }
 
+type FutureGetSpendingTxResult chan *response
+
+// Receive waits for the response promised by the future and returns the
+// transaction input spending the output, or nil when it is unspent.
+func (r FutureGetSpendingTxResult) Receive() (*j.GetSpendingTxResult, error) {
+	res, err := receiveFuture(r)
+	if err != nil {
+		return nil, err
+	}
+	var spending *j.GetSpendingTxResult
+	err = json.Unmarshal(res, &spending)
+	if err != nil {
+		return nil, err
+	}
+	return spending, nil
+}
+
+func (c *Client) GetSpendingTxAsync(txHash string, vout uint32) FutureGetSpendingTxResult {
+	cmd := cmds.NewGetSpendingTxCmd(txHash, vout)
+	return c.sendCmd(cmd)
+}
+
+// GetSpendingTx returns the transaction input which spent the output, it
+// requires the spent index of the node (--spentindex).
+func (c *Client) GetSpendingTx(txHash string, vout uint32) (*j.GetSpendingTxResult, error) {
+	return c.GetSpendingTxAsync(txHash, vout).Receive()
+}
+
 type FutureGetRawTransactionsResult chan *response
 
 func (r FutureGetRawTransactionsResult) Receive(verbose bool) (interface{}, error) {
@@ -259,3 +288,404 @@ func (c *Client) GetMempoolAsync(txType string, verbose bool) FutureGetMempoolRe
 func (c *Client) GetMempool(txType string, verbose bool) ([]string, error) {
 	return c.GetMempoolAsync(txType, verbose).Receive()
 }
+
+type FutureGetMempoolInfoResult chan *response
+
+func (r FutureGetMempoolInfoResult) Receive() (*j.MempoolInfoResult, error) {
+	res, err := receiveFuture(r)
+	if err != nil {
+		return nil, err
+	}
+	var info j.MempoolInfoResult
+	err = json.Unmarshal(res, &info)
+	if err != nil {
+		return nil, err
+	}
+	return &info, nil
+}
+
+func (c *Client) GetMempoolInfoAsync() FutureGetMempoolInfoResult {
+	cmd := cmds.NewGetMempoolInfoCmd()
+	return c.sendCmd(cmd)
+}
+
+func (c *Client) GetMempoolInfo() (*j.MempoolInfoResult, error) {
+	return c.GetMempoolInfoAsync().Receive()
+}
+
+type FutureGetMempoolCountResult chan *response
+
+func (r FutureGetMempoolCountResult) Receive() (int, error) {
+	res, err := receiveFuture(r)
+	if err != nil {
+		return 0, err
+	}
+	// The count is returned as a decimal string.
+	var count string
+	err = json.Unmarshal(res, &count)
+	if err != nil {
+		return 0, err
+	}
+	return strconv.Atoi(count)
+}
+
+func (c *Client) GetMempoolCountAsync() FutureGetMempoolCountResult {
+	cmd := cmds.NewGetMempoolCountCmd()
+	return c.sendCmd(cmd)
+}
+
+func (c *Client) GetMempoolCount() (int, error) {
+	return c.GetMempoolCountAsync().Receive()
+}
+
+type FutureSaveMempoolResult chan *response
+
+func (r FutureSaveMempoolResult) Receive() (string, error) {
+	res, err := receiveFuture(r)
+	if err != nil {
+		return "", err
+	}
+	var result string
+	err = json.Unmarshal(res, &result)
+	if err != nil {
+		return "", err
+	}
+	return result, nil
+}
+
+func (c *Client) SaveMempoolAsync() FutureSaveMempoolResult {
+	cmd := cmds.NewSaveMempoolCmd()
+	return c.sendCmd(cmd)
+}
+
+func (c *Client) SaveMempool() (string, error) {
+	return c.SaveMempoolAsync().Receive()
+}
+
+type FutureEstimateFeeResult chan *response
+
+func (r FutureEstimateFeeResult) Receive() (float64, error) {
+	res, err := receiveFuture(r)
+	if err != nil {
+		return -1, err
+	}
+	var feeRate float64
+	err = json.Unmarshal(res, &feeRate)
+	if err != nil {
+		return -1, err
+	}
+	return feeRate, nil
+}
+
+func (c *Client) EstimateFeeAsync(numBlocks int64) FutureEstimateFeeResult {
+	cmd := cmds.NewEstimateFeeCmd(numBlocks)
+	return c.sendCmd(cmd)
+}
+
+func (c *Client) EstimateFee(numBlocks int64) (float64, error) {
+	return c.EstimateFeeAsync(numBlocks).Receive()
+}
+
+func (c *Client) CreateRawTransactionV2Async(inputs []j.TransactionInput, amounts j.AdreesAmount, lockTime int64) FutureCreateRawTransactionResult {
+	cmd := cmds.NewCreateRawTransactionV2Cmd(inputs, amounts, lockTime)
+	return c.sendCmd(cmd)
+}
+
+func (c *Client) CreateRawTransactionV2(inputs []j.TransactionInput, amounts j.AdreesAmount, lockTime int64) (string, error) {
+	return c.CreateRawTransactionV2Async(inputs, amounts, lockTime).Receive()
+}
+
+type FutureFundRawTransactionResult chan *response
+
+func (r FutureFundRawTransactionResult) Receive() (*j.FundRawTransactionResult, error) {
+	res, err := receiveFuture(r)
+	if err != nil {
+		return nil, err
+	}
+
+	var result j.FundRawTransactionResult
+	err = json.Unmarshal(res, &result)
+	if err != nil {
+		return nil, err
+	}
+
+	return &result, nil
+}
+
+func (c *Client) FundRawTransactionAsync(hexTx string, options j.FundRawTransactionOptions) FutureFundRawTransactionResult {
+	cmd := cmds.NewFundRawTransactionCmd(hexTx, options)
+	return c.sendCmd(cmd)
+}
+
+func (c *Client) FundRawTransaction(hexTx string, options j.FundRawTransactionOptions) (*j.FundRawTransactionResult, error) {
+	return c.FundRawTransactionAsync(hexTx, options).Receive()
+}
+
+type FutureTraceTxInputResult chan *response
+
+func (r FutureTraceTxInputResult) Receive() (*j.TraceTxInputResult, error) {
+	res, err := receiveFuture(r)
+	if err != nil {
+		return nil, err
+	}
+
+	var result j.TraceTxInputResult
+	err = json.Unmarshal(res, &result)
+	if err != nil {
+		return nil, err
+	}
+
+	return &result, nil
+}
+
+func (c *Client) TraceTxInputAsync(hexTx string, inputIndex int, prevPkScript string, amount int64) FutureTraceTxInputResult {
+	cmd := cmds.NewTraceTxInputCmd(hexTx, inputIndex, prevPkScript, amount)
+	return c.sendCmd(cmd)
+}
+
+func (c *Client) TraceTxInput(hexTx string, inputIndex int, prevPkScript string, amount int64) (*j.TraceTxInputResult, error) {
+	return c.TraceTxInputAsync(hexTx, inputIndex, prevPkScript, amount).Receive()
+}
+
+func (c *Client) CreateTokenRawTransactionAsync(txType string, coinId uint16, coinName string, owners string, upLimit uint64,
+	inputs []j.TransactionInput, amounts j.Amounts, feeType uint16, feeValue int64) FutureCreateRawTransactionResult {
+	cmd := cmds.NewCreateTokenRawTransactionCmd(txType, coinId, coinName, owners, upLimit, inputs, amounts, feeType, feeValue)
+	return c.sendCmd(cmd)
+}
+
+func (c *Client) CreateTokenRawTransaction(txType string, coinId uint16, coinName string, owners string, upLimit uint64,
+	inputs []j.TransactionInput, amounts j.Amounts, feeType uint16, feeValue int64) (string, error) {
+	return c.CreateTokenRawTransactionAsync(txType, coinId, coinName, owners, upLimit, inputs, amounts, feeType, feeValue).Receive()
+}
+
+func (c *Client) CreateImportRawTransactionAsync(pkAddress string, amount int64) FutureCreateRawTransactionResult {
+	cmd := cmds.NewCreateImportRawTransactionCmd(pkAddress, amount)
+	return c.sendCmd(cmd)
+}
+
+func (c *Client) CreateImportRawTransaction(pkAddress string, amount int64) (string, error) {
+	return c.CreateImportRawTransactionAsync(pkAddress, amount).Receive()
+}
+
+func (c *Client) CreateExportRawTransactionAsync(txid string, vout uint32, pkAddress string, amount int64) FutureCreateRawTransactionResult {
+	cmd := cmds.NewCreateExportRawTransactionCmd(txid, vout, pkAddress, amount)
+	return c.sendCmd(cmd)
+}
+
+func (c *Client) CreateExportRawTransaction(txid string, vout uint32, pkAddress string, amount int64) (string, error) {
+	return c.CreateExportRawTransactionAsync(txid, vout, pkAddress, amount).Receive()
+}
+
+func (c *Client) CreateExportRawTransactionV2Async(inputs []j.TransactionInput, outputs []j.TransactionOutput, lockTime int64) FutureCreateRawTransactionResult {
+	cmd := cmds.NewCreateExportRawTransactionV2Cmd(inputs, outputs, lockTime)
+	return c.sendCmd(cmd)
+}
+
+func (c *Client) CreateExportRawTransactionV2(inputs []j.TransactionInput, outputs []j.TransactionOutput, lockTime int64) (string, error) {
+	return c.CreateExportRawTransactionV2Async(inputs, outputs, lockTime).Receive()
+}
+
+func (c *Client) GetRawTransactionByHashAsync(txHash string, verbose bool) FutureGetRawTransactionResult {
+	cmd := cmds.NewGetRawTransactionByHashCmd(txHash, verbose)
+	return c.sendCmd(cmd)
+}
+
+// GetRawTransactionByHash returns the transaction by its full hash, the
+// result is the same as GetRawTransaction.
+func (c *Client) GetRawTransactionByHash(txHash string, verbose bool) (interface{}, error) {
+	return c.GetRawTransactionByHashAsync(txHash, verbose).Receive(verbose)
+}
+
+type FutureTxHashResult chan *response
+
+func (r FutureTxHashResult) Receive() (string, error) {
+	res, err := receiveFuture(r)
+	if err != nil {
+		return "", err
+	}
+	var txHash string
+	err = json.Unmarshal(res, &txHash)
+	if err != nil {
+		return "", err
+	}
+	return txHash, nil
+}
+
+func (c *Client) GetMeerEVMTxHashByIDAsync(txid string) FutureTxHashResult {
+	cmd := cmds.NewGetMeerEVMTxHashByIDCmd(txid)
+	return c.sendCmd(cmd)
+}
+
+// GetMeerEVMTxHashByID returns the MeerEVM transaction hash of a cross chain
+// transaction.
+func (c *Client) GetMeerEVMTxHashByID(txid string) (string, error) {
+	return c.GetMeerEVMTxHashByIDAsync(txid).Receive()
+}
+
+func (c *Client) GetTxIDByMeerEVMTxHashAsync(etxh string) FutureTxHashResult {
+	cmd := cmds.NewGetTxIDByMeerEVMTxHashCmd(etxh)
+	return c.sendCmd(cmd)
+}
+
+// GetTxIDByMeerEVMTxHash returns the id of the cross chain transaction which
+// carries the MeerEVM transaction.
+func (c *Client) GetTxIDByMeerEVMTxHash(etxh string) (string, error) {
+	return c.GetTxIDByMeerEVMTxHashAsync(etxh).Receive()
+}
+
+type FutureGetCrossChainTransferResult chan *response
+
+func (r FutureGetCrossChainTransferResult) Receive() (*j.CrossChainTransferResult, error) {
+	res, err := receiveFuture(r)
+	if err != nil {
+		return nil, err
+	}
+	var transfer *j.CrossChainTransferResult
+	err = json.Unmarshal(res, &transfer)
+	if err != nil {
+		return nil, err
+	}
+	return transfer, nil
+}
+
+func (c *Client) GetCrossChainTransferAsync(id string) FutureGetCrossChainTransferResult {
+	cmd := cmds.NewGetCrossChainTransferCmd(id)
+	return c.sendCmd(cmd)
+}
+
+// GetCrossChainTransfer returns the export or import transaction by its hash
+// or the 0x prefixed hash of its MeerEVM transaction, it requires the cross
+// chain index of the node (--crosschainindex).
+func (c *Client) GetCrossChainTransfer(id string) (*j.CrossChainTransferResult, error) {
+	return c.GetCrossChainTransferAsync(id).Receive()
+}
+
+type FutureListCrossChainTransfersResult chan *response
+
+func (r FutureListCrossChainTransfersResult) Receive() ([]j.CrossChainTransferResult, error) {
+	res, err := receiveFuture(r)
+	if err != nil {
+		return nil, err
+	}
+	var transfers []j.CrossChainTransferResult
+	err = json.Unmarshal(res, &transfers)
+	if err != nil {
+		return nil, err
+	}
+	return transfers, nil
+}
+
+func (c *Client) ListCrossChainTransfersAsync(address string, count uint, skip uint, reverse bool) FutureListCrossChainTransfersResult {
+	cmd := cmds.NewListCrossChainTransfersCmd(address, &count, &skip, &reverse)
+	return c.sendCmd(cmd)
+}
+
+// ListCrossChainTransfers returns the export and import transactions of the
+// address or the 0x prefixed MeerEVM account, it requires the cross chain
+// index of the node (--crosschainindex).
+func (c *Client) ListCrossChainTransfers(address string, count uint, skip uint, reverse bool) ([]j.CrossChainTransferResult, error) {
+	return c.ListCrossChainTransfersAsync(address, count, skip, reverse).Receive()
+}
+
+type FutureGetIndexInfoResult chan *response
+
+func (r FutureGetIndexInfoResult) Receive() ([]j.IndexInfoResult, error) {
+	res, err := receiveFuture(r)
+	if err != nil {
+		return nil, err
+	}
+	var infos []j.IndexInfoResult
+	err = json.Unmarshal(res, &infos)
+	if err != nil {
+		return nil, err
+	}
+	return infos, nil
+}
+
+func (c *Client) GetIndexInfoAsync() FutureGetIndexInfoResult {
+	cmd := cmds.NewGetIndexInfoCmd()
+	return c.sendCmd(cmd)
+}
+
+// GetIndexInfo returns the sync state of the enabled indexes of the node.
+func (c *Client) GetIndexInfo() ([]j.IndexInfoResult, error) {
+	return c.GetIndexInfoAsync().Receive()
+}
+
+type FutureGetTokenHoldersResult chan *response
+
+func (r FutureGetTokenHoldersResult) Receive() (*j.TokenHoldersResult, error) {
+	res, err := receiveFuture(r)
+	if err != nil {
+		return nil, err
+	}
+	var holders j.TokenHoldersResult
+	err = json.Unmarshal(res, &holders)
+	if err != nil {
+		return nil, err
+	}
+	return &holders, nil
+}
+
+func (c *Client) GetTokenHoldersAsync(coinId uint16, count uint, skip uint) FutureGetTokenHoldersResult {
+	cmd := cmds.NewGetTokenHoldersCmd(coinId, &count, &skip)
+	return c.sendCmd(cmd)
+}
+
+// GetTokenHolders returns the addresses holding the token, it requires the
+// token index of the node (--tokenindex).
+func (c *Client) GetTokenHolders(coinId uint16, count uint, skip uint) (*j.TokenHoldersResult, error) {
+	return c.GetTokenHoldersAsync(coinId, count, skip).Receive()
+}
+
+type FutureGetTokenHistoryResult chan *response
+
+func (r FutureGetTokenHistoryResult) Receive() ([]j.TokenEventResult, error) {
+	res, err := receiveFuture(r)
+	if err != nil {
+		return nil, err
+	}
+	var events []j.TokenEventResult
+	err = json.Unmarshal(res, &events)
+	if err != nil {
+		return nil, err
+	}
+	return events, nil
+}
+
+func (c *Client) GetTokenHistoryAsync(coinId uint16, count uint, skip uint, reverse bool) FutureGetTokenHistoryResult {
+	cmd := cmds.NewGetTokenHistoryCmd(coinId, &count, &skip, &reverse)
+	return c.sendCmd(cmd)
+}
+
+// GetTokenHistory returns the events of the token, it requires the token
+// index of the node (--tokenindex).
+func (c *Client) GetTokenHistory(coinId uint16, count uint, skip uint, reverse bool) ([]j.TokenEventResult, error) {
+	return c.GetTokenHistoryAsync(coinId, count, skip, reverse).Receive()
+}
+
+type FutureGetTokenBalancesResult chan *response
+
+func (r FutureGetTokenBalancesResult) Receive() ([]j.TokenBalanceResult, error) {
+	res, err := receiveFuture(r)
+	if err != nil {
+		return nil, err
+	}
+	var balances []j.TokenBalanceResult
+	err = json.Unmarshal(res, &balances)
+	if err != nil {
+		return nil, err
+	}
+	return balances, nil
+}
+
+func (c *Client) GetTokenBalancesAsync(address string) FutureGetTokenBalancesResult {
+	cmd := cmds.NewGetTokenBalancesCmd(address)
+	return c.sendCmd(cmd)
+}
+
+// GetTokenBalances returns the balances of the tokens held by the address, it
+// requires the token index of the node (--tokenindex).
+func (c *Client) GetTokenBalances(address string) ([]j.TokenBalanceResult, error) {
+	return c.GetTokenBalancesAsync(address).Receive()
+}
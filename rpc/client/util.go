@@ -6,6 +6,7 @@ package client
 
 import (
 	"bufio"
+	"context"
 	"crypto/tls"
 	"crypto/x509"
 	"encoding/base64"
@@ -55,6 +56,9 @@ type jsonRequest struct {
 	cmd            interface{}
 	marshalledJSON []byte
 	responseChan   chan *response
+
+	// ctx is the context of the HTTP POST request, it may be nil.
+	ctx context.Context
 }
 
 type inMessage struct {
@@ -64,8 +68,27 @@ type inMessage struct {
 }
 
 type rawNotification struct {
-	Method string            `json:"method"`
-	Params []json.RawMessage `json:"params"`
+	Method string             `json:"method"`
+	Params notificationParams `json:"params"`
+}
+
+// notificationParams are the positional params of a notification. The
+// object params of a subscription notification are kept as the single
+// element of the params.
+type notificationParams []json.RawMessage
+
+func (p *notificationParams) UnmarshalJSON(data []byte) error {
+	trimmed := strings.TrimSpace(string(data))
+	if strings.HasPrefix(trimmed, "{") {
+		*p = notificationParams{json.RawMessage(trimmed)}
+		return nil
+	}
+	var params []json.RawMessage
+	if err := json.Unmarshal(data, &params); err != nil {
+		return err
+	}
+	*p = params
+	return nil
 }
 
 func newHTTPClient(config *ConnConfig) (*http.Client, error) {
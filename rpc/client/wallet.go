@@ -0,0 +1,218 @@
+/*
+ * Copyright (c) 2017-2020 The qitmeer developers
+ */
+
+package client
+
+import (
+	"encoding/json"
+	j "github.com/Qitmeer/qng/core/json"
+	"github.com/Qitmeer/qng/rpc/client/cmds"
+)
+
+type FutureCreateWalletResult chan *response
+
+func (r FutureCreateWalletResult) Receive() error {
+	_, err := receiveFuture(r)
+	return err
+}
+
+func (c *Client) CreateWalletAsync(passphrase string, seed *string) FutureCreateWalletResult {
+	cmd := cmds.NewCreateWalletCmd(passphrase, seed)
+	return c.sendCmd(cmd)
+}
+
+func (c *Client) CreateWallet(passphrase string, seed *string) error {
+	return c.CreateWalletAsync(passphrase, seed).Receive()
+}
+
+type FutureUnlockResult chan *response
+
+func (r FutureUnlockResult) Receive() error {
+	_, err := receiveFuture(r)
+	return err
+}
+
+func (c *Client) UnlockAsync(passphrase string, timeout uint32) FutureUnlockResult {
+	cmd := cmds.NewUnlockCmd(passphrase, timeout)
+	return c.sendCmd(cmd)
+}
+
+func (c *Client) Unlock(passphrase string, timeout uint32) error {
+	return c.UnlockAsync(passphrase, timeout).Receive()
+}
+
+type FutureLockResult chan *response
+
+func (r FutureLockResult) Receive() error {
+	_, err := receiveFuture(r)
+	return err
+}
+
+func (c *Client) LockAsync() FutureLockResult {
+	cmd := cmds.NewLockCmd()
+	return c.sendCmd(cmd)
+}
+
+func (c *Client) Lock() error {
+	return c.LockAsync().Receive()
+}
+
+type FutureGetWalletInfoResult chan *response
+
+func (r FutureGetWalletInfoResult) Receive() (*j.WalletInfoResult, error) {
+	res, err := receiveFuture(r)
+	if err != nil {
+		return nil, err
+	}
+
+	var result j.WalletInfoResult
+	err = json.Unmarshal(res, &result)
+	if err != nil {
+		return nil, err
+	}
+
+	return &result, nil
+}
+
+func (c *Client) GetWalletInfoAsync() FutureGetWalletInfoResult {
+	cmd := cmds.NewGetWalletInfoCmd()
+	return c.sendCmd(cmd)
+}
+
+func (c *Client) GetWalletInfo() (*j.WalletInfoResult, error) {
+	return c.GetWalletInfoAsync().Receive()
+}
+
+type FutureGetNewAddressResult chan *response
+
+func (r FutureGetNewAddressResult) Receive() (string, error) {
+	res, err := receiveFuture(r)
+	if err != nil {
+		return "", err
+	}
+	var addr string
+	err = json.Unmarshal(res, &addr)
+	if err != nil {
+		return "", err
+	}
+	return addr, nil
+}
+
+func (c *Client) GetNewAddressAsync(account *string) FutureGetNewAddressResult {
+	cmd := cmds.NewGetNewAddressCmd(account)
+	return c.sendCmd(cmd)
+}
+
+func (c *Client) GetNewAddress(account *string) (string, error) {
+	return c.GetNewAddressAsync(account).Receive()
+}
+
+type FutureListUnspentResult chan *response
+
+func (r FutureListUnspentResult) Receive() ([]j.WalletUnspentResult, error) {
+	res, err := receiveFuture(r)
+	if err != nil {
+		return nil, err
+	}
+
+	var result []j.WalletUnspentResult
+	err = json.Unmarshal(res, &result)
+	if err != nil {
+		return nil, err
+	}
+
+	return result, nil
+}
+
+func (c *Client) ListUnspentAsync(coinID *uint16, addrs *[]string) FutureListUnspentResult {
+	cmd := cmds.NewListUnspentCmd(coinID, addrs)
+	return c.sendCmd(cmd)
+}
+
+func (c *Client) ListUnspent(coinID *uint16, addrs *[]string) ([]j.WalletUnspentResult, error) {
+	return c.ListUnspentAsync(coinID, addrs).Receive()
+}
+
+type FutureSendResult chan *response
+
+func (r FutureSendResult) Receive() (string, error) {
+	res, err := receiveFuture(r)
+	if err != nil {
+		return "", err
+	}
+	var txid string
+	err = json.Unmarshal(res, &txid)
+	if err != nil {
+		return "", err
+	}
+	return txid, nil
+}
+
+func (c *Client) SendToAddressAsync(addr string, amount int64, coinID *uint16) FutureSendResult {
+	cmd := cmds.NewSendToAddressCmd(addr, amount, coinID)
+	return c.sendCmd(cmd)
+}
+
+func (c *Client) SendToAddress(addr string, amount int64, coinID *uint16) (string, error) {
+	return c.SendToAddressAsync(addr, amount, coinID).Receive()
+}
+
+func (c *Client) SendManyAsync(amounts j.AdreesAmount) FutureSendResult {
+	cmd := cmds.NewSendManyCmd(amounts)
+	return c.sendCmd(cmd)
+}
+
+func (c *Client) SendMany(amounts j.AdreesAmount) (string, error) {
+	return c.SendManyAsync(amounts).Receive()
+}
+
+type FutureSignRawTransactionWithWalletResult chan *response
+
+func (r FutureSignRawTransactionWithWalletResult) Receive() (*j.SignRawTransactionResult, error) {
+	res, err := receiveFuture(r)
+	if err != nil {
+		return nil, err
+	}
+
+	var result j.SignRawTransactionResult
+	err = json.Unmarshal(res, &result)
+	if err != nil {
+		return nil, err
+	}
+
+	return &result, nil
+}
+
+func (c *Client) SignRawTransactionWithWalletAsync(rawTx string) FutureSignRawTransactionWithWalletResult {
+	cmd := cmds.NewSignRawTransactionWithWalletCmd(rawTx)
+	return c.sendCmd(cmd)
+}
+
+func (c *Client) SignRawTransactionWithWallet(rawTx string) (*j.SignRawTransactionResult, error) {
+	return c.SignRawTransactionWithWalletAsync(rawTx).Receive()
+}
+
+type FutureSignMessageResult chan *response
+
+func (r FutureSignMessageResult) Receive() (string, error) {
+	res, err := receiveFuture(r)
+	if err != nil {
+		return "", err
+	}
+	var sig string
+	err = json.Unmarshal(res, &sig)
+	if err != nil {
+		return "", err
+	}
+	return sig, nil
+}
+
+func (c *Client) SignMessageAsync(address string, message string) FutureSignMessageResult {
+	cmd := cmds.NewSignMessageCmd(address, message)
+	return c.sendCmd(cmd)
+}
+
+func (c *Client) SignMessage(address string, message string) (string, error) {
+	return c.SignMessageAsync(address, message).Receive()
+}
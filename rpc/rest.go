@@ -0,0 +1,358 @@
+// Copyright (c) 2017-2018 The qitmeer developers
+
+package rpc
+
+import (
+	"context"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"github.com/Qitmeer/qng/common/hash"
+	qjson "github.com/Qitmeer/qng/core/json"
+	"github.com/Qitmeer/qng/rpc/client/cmds"
+	"net/http"
+	"reflect"
+	"strconv"
+	"strings"
+)
+
+const (
+	// restPathPrefix is the http path under which all of the REST
+	// endpoints are served.
+	restPathPrefix = "/rest/"
+
+	// restMaxGetUtxosOutpoints is the maximum number of outpoints that can
+	// be queried by a single getutxos request.
+	restMaxGetUtxosOutpoints = 15
+)
+
+// restFormat is the response encoding requested by a REST call through the
+// suffix of the last path element (e.g. /rest/tx/<txid>.hex).
+type restFormat int
+
+const (
+	restFormatJSON restFormat = iota
+	restFormatBinary
+	restFormatHex
+)
+
+var restFormatStrings = map[string]restFormat{
+	"json": restFormatJSON,
+	"bin":  restFormatBinary,
+	"hex":  restFormatHex,
+}
+
+// parseRestFormat splits the format suffix from the last element of a REST
+// path.  A path element without a suffix defaults to json.
+func parseRestFormat(param string) (string, restFormat, error) {
+	pos := strings.LastIndex(param, ".")
+	if pos < 0 {
+		return param, restFormatJSON, nil
+	}
+	format, ok := restFormatStrings[param[pos+1:]]
+	if !ok {
+		return "", restFormatJSON, fmt.Errorf("output format not found (available: json, bin, hex)")
+	}
+	return param[:pos], format, nil
+}
+
+// restHandler serves the read-only REST interface.  Every endpoint is mapped to
+// a registered RPC method, so the results are the same as the JSON-RPC ones and
+// only the modules exposed by the RPC server are available.
+func (s *RpcServer) restHandler(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		restError(w, http.StatusMethodNotAllowed, "method not allowed")
+		return
+	}
+	if s.IsShutdown() {
+		restError(w, http.StatusServiceUnavailable, (&shutdownError{}).Error())
+		return
+	}
+
+	path := strings.Split(strings.TrimPrefix(r.URL.Path, restPathPrefix), "/")
+	switch path[0] {
+	case "block":
+		s.restBlock(w, path[1:])
+	case "blockbyorder":
+		s.restBlockByOrder(w, path[1:])
+	case "tx":
+		s.restTx(w, path[1:])
+	case "getutxos":
+		s.restGetUtxos(w, path[1:])
+	case "tips", "tips.json":
+		s.restJSON(w, path, "tips")
+	case "mempool":
+		if len(path) != 2 {
+			restError(w, http.StatusBadRequest, "usage: /rest/mempool/<info|contents>.json")
+			return
+		}
+		switch path[1] {
+		case "info", "info.json":
+			s.restJSON(w, path[1:], "getMempoolInfo")
+		case "contents", "contents.json":
+			s.restJSON(w, path[1:], "getMempool", nil, false)
+		default:
+			restError(w, http.StatusNotFound, "usage: /rest/mempool/<info|contents>.json")
+		}
+	case "dag":
+		if len(path) != 2 || (path[1] != "info" && path[1] != "info.json") {
+			restError(w, http.StatusNotFound, "usage: /rest/dag/info.json")
+			return
+		}
+		s.restJSON(w, path[1:], "getMeerDAGInfo")
+	default:
+		restError(w, http.StatusNotFound, fmt.Sprintf("unknown REST endpoint: %s", r.URL.Path))
+	}
+}
+
+// restBlock implements /rest/block/[notxdetails/]<hash>.<json|bin|hex>
+func (s *RpcServer) restBlock(w http.ResponseWriter, path []string) {
+	fullTx := true
+	if len(path) == 2 && path[0] == "notxdetails" {
+		fullTx = false
+		path = path[1:]
+	}
+	if len(path) != 1 {
+		restError(w, http.StatusBadRequest, "usage: /rest/block/[notxdetails/]<hash>.<json|bin|hex>")
+		return
+	}
+	hashStr, format, err := parseRestFormat(path[0])
+	if err != nil {
+		restError(w, http.StatusNotFound, err.Error())
+		return
+	}
+	h, err := hash.NewHashFromStr(hashStr)
+	if err != nil {
+		restError(w, http.StatusBadRequest, fmt.Sprintf("invalid hash: %s", hashStr))
+		return
+	}
+	s.writeRestBlock(w, h, format, fullTx)
+}
+
+// restBlockByOrder implements /rest/blockbyorder/<order>.<json|bin|hex>
+func (s *RpcServer) restBlockByOrder(w http.ResponseWriter, path []string) {
+	if len(path) != 1 {
+		restError(w, http.StatusBadRequest, "usage: /rest/blockbyorder/<order>.<json|bin|hex>")
+		return
+	}
+	orderStr, format, err := parseRestFormat(path[0])
+	if err != nil {
+		restError(w, http.StatusNotFound, err.Error())
+		return
+	}
+	order, err := strconv.ParseInt(orderStr, 10, 64)
+	if err != nil || order < 0 {
+		restError(w, http.StatusBadRequest, fmt.Sprintf("invalid order: %s", orderStr))
+		return
+	}
+	ret, err := s.callAPI(cmds.DefaultServiceNameSpace, "getBlockhash", order)
+	if err != nil {
+		restError(w, http.StatusNotFound, err.Error())
+		return
+	}
+	h, err := hash.NewHashFromStr(ret.(string))
+	if err != nil {
+		restError(w, http.StatusInternalServerError, err.Error())
+		return
+	}
+	s.writeRestBlock(w, h, format, true)
+}
+
+func (s *RpcServer) writeRestBlock(w http.ResponseWriter, h *hash.Hash, format restFormat, fullTx bool) {
+	ret, err := s.callAPI(cmds.DefaultServiceNameSpace, "getBlock", *h, format == restFormatJSON, true, fullTx)
+	if err != nil {
+		restError(w, http.StatusNotFound, err.Error())
+		return
+	}
+	writeRestResult(w, format, ret)
+}
+
+// restTx implements /rest/tx/<txid>.<json|bin|hex>
+func (s *RpcServer) restTx(w http.ResponseWriter, path []string) {
+	if len(path) != 1 {
+		restError(w, http.StatusBadRequest, "usage: /rest/tx/<txid>.<json|bin|hex>")
+		return
+	}
+	hashStr, format, err := parseRestFormat(path[0])
+	if err != nil {
+		restError(w, http.StatusNotFound, err.Error())
+		return
+	}
+	h, err := hash.NewHashFromStr(hashStr)
+	if err != nil {
+		restError(w, http.StatusBadRequest, fmt.Sprintf("invalid hash: %s", hashStr))
+		return
+	}
+	ret, err := s.callAPI(cmds.DefaultServiceNameSpace, "getRawTransaction", *h, format == restFormatJSON)
+	if err != nil {
+		restError(w, http.StatusNotFound, err.Error())
+		return
+	}
+	writeRestResult(w, format, ret)
+}
+
+// restGetUtxos implements /rest/getutxos/[checkmempool/]<txid>-<n>/<txid>-<n>/.../<txid>-<n>.json
+func (s *RpcServer) restGetUtxos(w http.ResponseWriter, path []string) {
+	includeMempool := false
+	if len(path) > 0 && path[0] == "checkmempool" {
+		includeMempool = true
+		path = path[1:]
+	}
+	if len(path) == 0 {
+		restError(w, http.StatusBadRequest, "usage: /rest/getutxos/[checkmempool/]<txid>-<n>/.../<txid>-<n>.json")
+		return
+	}
+	if len(path) > restMaxGetUtxosOutpoints {
+		restError(w, http.StatusBadRequest, fmt.Sprintf("error: max outpoints exceeded (max: %d, tried: %d)",
+			restMaxGetUtxosOutpoints, len(path)))
+		return
+	}
+	last, format, err := parseRestFormat(path[len(path)-1])
+	if err != nil {
+		restError(w, http.StatusNotFound, err.Error())
+		return
+	}
+	if format != restFormatJSON {
+		restError(w, http.StatusNotFound, "output format not found (available: json)")
+		return
+	}
+	path[len(path)-1] = last
+
+	result := make([]qjson.GetUtxosResult, 0, len(path))
+	for _, op := range path {
+		pos := strings.LastIndex(op, "-")
+		if pos < 0 {
+			restError(w, http.StatusBadRequest, fmt.Sprintf("invalid outpoint: %s", op))
+			return
+		}
+		h, err := hash.NewHashFromStr(op[:pos])
+		if err != nil {
+			restError(w, http.StatusBadRequest, fmt.Sprintf("invalid outpoint: %s", op))
+			return
+		}
+		vout, err := strconv.ParseUint(op[pos+1:], 10, 32)
+		if err != nil {
+			restError(w, http.StatusBadRequest, fmt.Sprintf("invalid outpoint: %s", op))
+			return
+		}
+		ret, err := s.callAPI(cmds.DefaultServiceNameSpace, "getUtxo", *h, uint32(vout), includeMempool)
+		if err != nil {
+			restError(w, http.StatusNotFound, err.Error())
+			return
+		}
+		item := qjson.GetUtxosResult{Txid: h.String(), Vout: uint32(vout)}
+		if utxo, ok := ret.(*qjson.GetUtxoResult); ok {
+			item.Utxo = utxo
+		}
+		result = append(result, item)
+	}
+	writeRestResult(w, restFormatJSON, result)
+}
+
+// restJSON serves an endpoint which only has a json representation.
+func (s *RpcServer) restJSON(w http.ResponseWriter, path []string, method string, args ...interface{}) {
+	if len(path) != 1 {
+		restError(w, http.StatusBadRequest, "unexpected path elements")
+		return
+	}
+	_, format, err := parseRestFormat(path[0])
+	if err != nil || format != restFormatJSON {
+		restError(w, http.StatusNotFound, "output format not found (available: json)")
+		return
+	}
+	ret, err := s.callAPI(cmds.DefaultServiceNameSpace, method, args...)
+	if err != nil {
+		restError(w, http.StatusNotFound, err.Error())
+		return
+	}
+	writeRestResult(w, format, ret)
+}
+
+// callAPI invokes a registered RPC method directly. Missing trailing arguments
+// are passed as zero values, which is how optional parameters are handled by
+// the JSON-RPC codec.
+func (s *RpcServer) callAPI(namespace string, method string, args ...interface{}) (interface{}, error) {
+	svc, ok := s.rpcSvcRegistry[namespace]
+	if !ok {
+		return nil, &methodNotFoundError{namespace, method}
+	}
+	callb, ok := svc.callbacks[method]
+	if !ok || callb.isSubscribe {
+		return nil, &methodNotFoundError{namespace, method}
+	}
+	if len(args) > len(callb.argTypes) {
+		return nil, &invalidParamsError{fmt.Sprintf("%s expects %d parameters, got %d",
+			method, len(callb.argTypes), len(args))}
+	}
+	arguments := []reflect.Value{callb.receiver}
+	if callb.hasCtx {
+		arguments = append(arguments, reflect.ValueOf(context.Background()))
+	}
+	for i, argType := range callb.argTypes {
+		if i >= len(args) || args[i] == nil {
+			arguments = append(arguments, reflect.Zero(argType))
+			continue
+		}
+		v := reflect.ValueOf(args[i])
+		if argType.Kind() == reflect.Ptr && v.Type().AssignableTo(argType.Elem()) {
+			// optional parameter
+			pv := reflect.New(argType.Elem())
+			pv.Elem().Set(v)
+			v = pv
+		} else if !v.Type().AssignableTo(argType) {
+			if !v.Type().ConvertibleTo(argType) {
+				return nil, &invalidParamsError{fmt.Sprintf("invalid argument %d: %s is not %s", i, v.Type(), argType)}
+			}
+			v = v.Convert(argType)
+		}
+		arguments = append(arguments, v)
+	}
+	reply := callb.method.Func.Call(arguments)
+	if len(reply) == 0 {
+		return nil, nil
+	}
+	if callb.errPos >= 0 && !reply[callb.errPos].IsNil() {
+		return nil, reply[callb.errPos].Interface().(error)
+	}
+	return reply[0].Interface(), nil
+}
+
+// writeRestResult writes a method result using the requested format. Binary
+// and hex formats are only supported by the methods which return the
+// serialized data as a hex string.
+func writeRestResult(w http.ResponseWriter, format restFormat, result interface{}) {
+	switch format {
+	case restFormatBinary, restFormatHex:
+		hexStr, ok := result.(string)
+		if !ok {
+			restError(w, http.StatusInternalServerError, "result has no serialized form")
+			return
+		}
+		if format == restFormatHex {
+			w.Header().Set("Content-Type", "text/plain")
+			w.Write([]byte(hexStr + "\n"))
+			return
+		}
+		data, err := hex.DecodeString(hexStr)
+		if err != nil {
+			restError(w, http.StatusInternalServerError, err.Error())
+			return
+		}
+		w.Header().Set("Content-Type", "application/octet-stream")
+		w.Write(data)
+	default:
+		data, err := json.Marshal(result)
+		if err != nil {
+			restError(w, http.StatusInternalServerError, err.Error())
+			return
+		}
+		w.Header().Set("Content-Type", contentType)
+		w.Write(append(data, '\n'))
+	}
+}
+
+func restError(w http.ResponseWriter, status int, message string) {
+	w.Header().Set("Content-Type", "text/plain")
+	w.WriteHeader(status)
+	w.Write([]byte(message + "\r\n"))
+}
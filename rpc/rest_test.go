@@ -0,0 +1,166 @@
+// Copyright (c) 2017-2018 The qitmeer developers
+
+package rpc
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/Qitmeer/qng/common/hash"
+	"github.com/Qitmeer/qng/config"
+	qjson "github.com/Qitmeer/qng/core/json"
+	"github.com/Qitmeer/qng/rpc/client/cmds"
+)
+
+var testRestBlockHash = hash.HashH([]byte("block"))
+
+// testRestAPI has the signatures of the methods the REST interface calls.
+type testRestAPI struct {
+	includeMempool bool
+}
+
+func (api *testRestAPI) GetBlockhash(order int64) (string, error) {
+	if order != 1 {
+		return "", fmt.Errorf("no block at order %d", order)
+	}
+	return testRestBlockHash.String(), nil
+}
+
+func (api *testRestAPI) GetBlock(h hash.Hash, verbose *bool, inclTx *bool, fullTx *bool) (interface{}, error) {
+	if h != testRestBlockHash {
+		return nil, fmt.Errorf("block %s not found", h)
+	}
+	if verbose == nil || !*verbose {
+		return "0102", nil
+	}
+	return map[string]interface{}{"hash": h.String(), "fullTx": fullTx != nil && *fullTx}, nil
+}
+
+func (api *testRestAPI) GetUtxo(txHash hash.Hash, vout uint32, includeMempool *bool) (interface{}, error) {
+	api.includeMempool = includeMempool != nil && *includeMempool
+	if vout != 0 {
+		return nil, nil
+	}
+	return &qjson.GetUtxoResult{CoinId: 0, Amount: 1}, nil
+}
+
+func (api *testRestAPI) GetMempoolInfo() (interface{}, error) {
+	return &qjson.MempoolInfoResult{Size: 2}, nil
+}
+
+func newTestRestServer(t *testing.T) (*RpcServer, *testRestAPI) {
+	s, err := NewRPCServer(&config.Config{}, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	api := &testRestAPI{}
+	if err := s.RegisterService(cmds.DefaultServiceNameSpace, api); err != nil {
+		t.Fatal(err)
+	}
+	return s, api
+}
+
+func testRestGet(s *RpcServer, path string) *httptest.ResponseRecorder {
+	w := httptest.NewRecorder()
+	s.restHandler(w, httptest.NewRequest(http.MethodGet, path, nil))
+	return w
+}
+
+func TestRestBlock(t *testing.T) {
+	s, _ := newTestRestServer(t)
+
+	tests := []struct {
+		path   string
+		status int
+		body   string
+	}{
+		{"/rest/block/" + testRestBlockHash.String() + ".hex", http.StatusOK, "0102\n"},
+		{"/rest/block/" + testRestBlockHash.String() + ".bin", http.StatusOK, "\x01\x02"},
+		{"/rest/block/" + testRestBlockHash.String() + ".json", http.StatusOK,
+			`{"fullTx":true,"hash":"` + testRestBlockHash.String() + `"}` + "\n"},
+		{"/rest/block/notxdetails/" + testRestBlockHash.String(), http.StatusOK,
+			`{"fullTx":false,"hash":"` + testRestBlockHash.String() + `"}` + "\n"},
+		{"/rest/blockbyorder/1.hex", http.StatusOK, "0102\n"},
+		{"/rest/blockbyorder/2.hex", http.StatusNotFound, ""},
+		{"/rest/blockbyorder/-1.hex", http.StatusBadRequest, ""},
+		{"/rest/block/" + testRestBlockHash.String() + ".xml", http.StatusNotFound, ""},
+		{"/rest/block/zz.json", http.StatusBadRequest, ""},
+		{"/rest/block/" + hash.HashH([]byte("other")).String() + ".json", http.StatusNotFound, ""},
+		{"/rest/unknown", http.StatusNotFound, ""},
+	}
+	for _, test := range tests {
+		w := testRestGet(s, test.path)
+		if w.Code != test.status {
+			t.Errorf("%s: got the status %d, want %d: %s", test.path, w.Code, test.status, w.Body.String())
+			continue
+		}
+		if test.body != "" && w.Body.String() != test.body {
+			t.Errorf("%s: got %q, want %q", test.path, w.Body.String(), test.body)
+		}
+	}
+
+	w := httptest.NewRecorder()
+	s.restHandler(w, httptest.NewRequest(http.MethodPost, "/rest/tips.json", nil))
+	if w.Code != http.StatusMethodNotAllowed {
+		t.Fatalf("got the status %d for a POST request", w.Code)
+	}
+}
+
+func TestRestGetUtxos(t *testing.T) {
+	s, api := newTestRestServer(t)
+	txHash := hash.HashH([]byte("tx"))
+
+	w := testRestGet(s, fmt.Sprintf("/rest/getutxos/checkmempool/%s-0/%s-1.json", txHash, txHash))
+	if w.Code != http.StatusOK {
+		t.Fatalf("got the status %d: %s", w.Code, w.Body.String())
+	}
+	if !api.includeMempool {
+		t.Fatalf("the mempool isn't checked")
+	}
+	var result []qjson.GetUtxosResult
+	if err := json.Unmarshal(w.Body.Bytes(), &result); err != nil {
+		t.Fatal(err)
+	}
+	if len(result) != 2 || result[0].Txid != txHash.String() || result[0].Utxo == nil ||
+		result[1].Vout != 1 || result[1].Utxo != nil {
+		t.Fatalf("got %+v", result)
+	}
+
+	testRestGet(s, fmt.Sprintf("/rest/getutxos/%s-0.json", txHash))
+	if api.includeMempool {
+		t.Fatalf("the mempool is checked")
+	}
+
+	tooMany := strings.Repeat(txHash.String()+"-0/", restMaxGetUtxosOutpoints+1)
+	for _, path := range []string{
+		"/rest/getutxos/" + tooMany[:len(tooMany)-1] + ".json",
+		"/rest/getutxos/" + txHash.String() + ".json",
+		"/rest/getutxos/" + txHash.String() + "-x.json",
+	} {
+		if w := testRestGet(s, path); w.Code != http.StatusBadRequest {
+			t.Errorf("%s: got the status %d, want %d", path, w.Code, http.StatusBadRequest)
+		}
+	}
+	if w := testRestGet(s, "/rest/getutxos/"+txHash.String()+"-0.hex"); w.Code != http.StatusNotFound {
+		t.Errorf("got the status %d for the hex format", w.Code)
+	}
+}
+
+func TestRestJSON(t *testing.T) {
+	s, _ := newTestRestServer(t)
+	w := testRestGet(s, "/rest/mempool/info.json")
+	if w.Code != http.StatusOK || !strings.HasPrefix(w.Body.String(), `{"size":2,`) {
+		t.Fatalf("got the status %d: %s", w.Code, w.Body.String())
+	}
+	// The methods which aren't registered aren't found.
+	if w := testRestGet(s, "/rest/dag/info.json"); w.Code != http.StatusNotFound {
+		t.Fatalf("got the status %d for an unregistered method", w.Code)
+	}
+	if w := testRestGet(s, "/rest/mempool/info.hex"); w.Code != http.StatusNotFound {
+		t.Fatalf("got the status %d for the hex format", w.Code)
+	}
+}
@@ -53,6 +53,9 @@ type RpcServer struct {
 	ChainParams *params.Params
 	listeners   []net.Listener
 	consensus model.Consensus
+
+	// handlers are the additional http handlers served by the listeners
+	handlers map[string]http.Handler
 }
 
 // service represents a registered object
@@ -115,6 +118,7 @@ func NewRPCServer(cfg *config.Config, consensus model.Consensus) (*RpcServer, er
 		requestProcessShutdown: make(chan struct{}),
 		quit:                   make(chan int),
 		ReqStatus:              map[string]*RequestStatus{},
+		handlers:               map[string]http.Handler{},
 	}
 
 	if cfg.RPCUser != "" && cfg.RPCPass != "" {
@@ -233,6 +237,15 @@ func (s *RpcServer) startHTTP(listenAddrs []string) error {
 		s.WebsocketHandler(ws, r.RemoteAddr, isAdmin)
 	})
 
+	// REST endpoint.
+	if s.config.REST {
+		rpcServeMux.Handle(restPathPrefix, s.authHandler(http.HandlerFunc(s.restHandler)))
+	}
+
+	for pattern, handler := range s.handlers {
+		rpcServeMux.Handle(pattern, s.authHandler(handler))
+	}
+
 	listeners, err := parseListeners(s.config, listenAddrs)
 	if err != nil {
 		return err
@@ -250,6 +263,34 @@ func (s *RpcServer) startHTTP(listenAddrs []string) error {
 	return nil
 }
 
+// RegisterHandler adds a http handler which is served by the RPC listeners
+// with the same connection limit and authentication as the JSON-RPC requests.
+// It must be called before the server is started.
+func (s *RpcServer) RegisterHandler(pattern string, handler http.Handler) {
+	s.handlers[pattern] = handler
+}
+
+// authHandler wraps a http handler with the connection limit and the http
+// basic authentication of the RPC server.
+func (s *RpcServer) authHandler(handler http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		// Limit the number of connections to max allowed.
+		if s.limitConnections(w, r.RemoteAddr) {
+			return
+		}
+
+		// Keep track of the number of connected clients.
+		s.incrementClients()
+		defer s.decrementClients()
+		_, err := s.checkAuth(r, true)
+		if err != nil {
+			jsonAuthFail(w)
+			return
+		}
+		handler.ServeHTTP(w, r)
+	})
+}
+
 // limitConnections responds with a 503 service unavailable and returns true if
 // adding another client would exceed the maximum allow RPC clients.
 //
@@ -656,6 +697,10 @@ func (s *RpcServer) handle(ctx context.Context, codec ServerCodec, req *serverRe
 	if req.callb.errPos >= 0 { // test if method returned an error
 		if !reply[req.callb.errPos].IsNil() {
 			e := reply[req.callb.errPos].Interface().(error)
+			// Keep the code of the errors which carry one.
+			if rpcErr, ok := e.(Error); ok {
+				return codec.CreateErrorResponse(&req.id, rpcErr), nil
+			}
 			res := codec.CreateErrorResponse(&req.id, &callbackError{e.Error()})
 			return res, nil
 		}
@@ -145,6 +145,8 @@ var wsHandlersBeforeInit = map[string]wsCommandHandler{
 	"rescan":                    handleRescan,
 	"notifyTxsConfirmed":        handleNotifyTxsConfirmed,
 	"removeTxsConfirmed":        handleRemoveTxsConfirmed,
+	"subscribe":                 handleSubscribe,
+	"unsubscribe":               handleUnsubscribe,
 }
 
 func handleNotifyBlocks(wsc *wsClient, icmd interface{}) (interface{}, error) {
@@ -62,6 +62,10 @@ type wsClient struct {
 	// `rescanblocks` methods.
 	filterData *wsClientFilter
 
+	// subscriptions are the subscriptions made by the subscribe command,
+	// keyed by their ids.
+	subscriptions map[string]*wsSubscription
+
 	// Networking infrastructure.
 	serviceRequestSem semaphore
 	ntfnChan          chan []byte
@@ -24,6 +24,7 @@ type notificationReorganization struct {
 	OldBlocks []*hash.Hash
 	NewBlock  *hash.Hash
 	NewOrder  uint64
+	Diff      *json.ReorganizationDiff
 }
 
 type notificationTxAcceptedByMempool struct {
@@ -95,6 +96,11 @@ out:
 					m.notifyBlockConnected(blockNotifications,
 						block)
 				}
+				m.notifySubscribedBlock(clients, cmds.SubscribeTopicBlockConnected, block)
+				for _, tx := range block.Transactions() {
+					m.notifyAddressActivity(clients, tx, block)
+				}
+				m.notifyTokenEvents(clients, block)
 
 			case *notificationBlockDisconnected:
 				block := (*types.SerializedBlock)(n)
@@ -102,6 +108,7 @@ out:
 					m.notifyBlockDisconnected(blockNotifications,
 						block)
 				}
+				m.notifySubscribedBlock(clients, cmds.SubscribeTopicBlockDisconnected, block)
 
 			case *notificationBlockAccepted:
 				band := (*blockchain.BlockAcceptedNotifyData)(n)
@@ -110,6 +117,7 @@ out:
 					m.notifyBlockAccepted(blockNotifications,
 						block)
 				}
+				m.notifySubscribedBlock(clients, cmds.SubscribeTopicNewDAGBlocks, block)
 				if band.IsMainChainTipChange {
 					// do something
 					if len(txConfirms) != 0 {
@@ -129,12 +137,23 @@ out:
 				if len(blockNotifications) != 0 {
 					m.notifyReorganization(blockNotifications, n)
 				}
+				m.notifySubscribedReorganization(clients, n)
 
 			case *notificationTxAcceptedByMempool:
 
 				if n.isNew && len(txNotifications) != 0 {
 					m.notifyForNewTx(txNotifications, n.tx)
 				}
+				if n.isNew {
+					m.notifySubscribedMempoolTx(clients, n.tx, cmds.MempoolActionAdd)
+					m.notifyAddressActivity(clients, n.tx, nil)
+				}
+
+			case *notificationTxRemovedFromMempool:
+				m.notifySubscribedMempoolTx(clients, (*types.Tx)(n), cmds.MempoolActionRemove)
+
+			case *notificationLockMatured:
+				m.notifyLockMatured(clients, (*cmds.SubscribedLockMatured)(n))
 			case *notificationBlockTemplate:
 				bt := (*json.RemoteGBTResult)(n)
 				if len(blockNotifications) != 0 {
@@ -265,6 +284,7 @@ func (m *wsNotificationManager) NotifyReorganization(rnd *blockchain.Reorganizat
 		OldBlocks: rnd.OldBlocks,
 		NewBlock:  rnd.NewBlock,
 		NewOrder:  rnd.NewOrder,
+		Diff:      rnd.Diff(),
 	}
 	select {
 	case m.queueNotification <- nr:
@@ -279,6 +299,7 @@ func (m *wsNotificationManager) notifyReorganization(clients map[chan struct{}]*
 		olds = append(olds, old.String())
 	}
 	ntfn := cmds.NewReorganizationNtfn(nr.NewBlock.String(), int64(nr.NewOrder), olds)
+	ntfn.Diff = nr.Diff
 	marshalledJSON, err := cmds.MarshalCmd(nil, ntfn)
 	if err != nil {
 		log.Error(fmt.Sprintf("Failed to marshal block accepted notification: "+
@@ -0,0 +1,344 @@
+/*
+ * Copyright (c) 2017-2020 The qitmeer developers
+ */
+
+package rpc
+
+import (
+	"encoding/json"
+	"fmt"
+	"github.com/Qitmeer/qng/core/address"
+	"github.com/Qitmeer/qng/core/types"
+	"github.com/Qitmeer/qng/engine/txscript"
+	"github.com/Qitmeer/qng/rpc/client/cmds"
+)
+
+// wsSubscription is a subscription of a websocket client made by the
+// subscribe command.
+type wsSubscription struct {
+	id    string
+	topic string
+
+	// filter holds the watched addresses of an addressActivity
+	// subscription.
+	filter *wsClientFilter
+
+	// coinIds holds the coin ids of a tokenEvents subscription, every
+	// token is notified when it is empty.
+	coinIds map[types.CoinID]struct{}
+}
+
+var subscribeTopics = map[string]struct{}{
+	cmds.SubscribeTopicNewDAGBlocks:      {},
+	cmds.SubscribeTopicBlockConnected:    {},
+	cmds.SubscribeTopicBlockDisconnected: {},
+	cmds.SubscribeTopicReorganization:    {},
+	cmds.SubscribeTopicMempool:           {},
+	cmds.SubscribeTopicAddressActivity:   {},
+	cmds.SubscribeTopicTokenEvents:       {},
+	cmds.SubscribeTopicLockMatured:       {},
+}
+
+// handleSubscribe implements the subscribe command extension for websocket
+// connections. It returns the id of the new subscription, which is carried
+// by all of its notifications.
+func handleSubscribe(wsc *wsClient, icmd interface{}) (interface{}, error) {
+	cmd, ok := icmd.(*cmds.SubscribeCmd)
+	if !ok {
+		return nil, cmds.ErrRPCInternal
+	}
+	if _, ok := subscribeTopics[cmd.Topic]; !ok {
+		return nil, cmds.NewRPCError(cmds.ErrRPCInvalidParams.Code,
+			fmt.Sprintf("unknown subscription topic %q", cmd.Topic))
+	}
+	sub := &wsSubscription{
+		id:    string(NewID()),
+		topic: cmd.Topic,
+	}
+	switch cmd.Topic {
+	case cmds.SubscribeTopicAddressActivity:
+		if cmd.Filter == nil || len(cmd.Filter.Addresses) == 0 {
+			return nil, cmds.NewRPCError(cmds.ErrRPCInvalidParams.Code,
+				"the addressActivity topic requires the addresses of the filter")
+		}
+		sub.filter = newWSClientFilter(cmd.Filter.Addresses, nil)
+
+	case cmds.SubscribeTopicLockMatured:
+		if cmd.Filter != nil && len(cmd.Filter.Addresses) > 0 {
+			sub.filter = newWSClientFilter(cmd.Filter.Addresses, nil)
+		}
+
+	case cmds.SubscribeTopicTokenEvents:
+		sub.coinIds = map[types.CoinID]struct{}{}
+		if cmd.Filter != nil {
+			for _, id := range cmd.Filter.CoinIds {
+				sub.coinIds[types.CoinID(id)] = struct{}{}
+			}
+		}
+	}
+
+	wsc.Lock()
+	if wsc.subscriptions == nil {
+		wsc.subscriptions = map[string]*wsSubscription{}
+	}
+	wsc.subscriptions[sub.id] = sub
+	wsc.Unlock()
+
+	log.Debug(fmt.Sprintf("Websocket client %s subscribed to %s (%s)", wsc.addr, sub.topic, sub.id))
+	return sub.id, nil
+}
+
+// handleUnsubscribe implements the unsubscribe command extension for
+// websocket connections. It returns whether the subscription existed.
+func handleUnsubscribe(wsc *wsClient, icmd interface{}) (interface{}, error) {
+	cmd, ok := icmd.(*cmds.UnsubscribeCmd)
+	if !ok {
+		return nil, cmds.ErrRPCInternal
+	}
+	wsc.Lock()
+	_, exists := wsc.subscriptions[cmd.ID]
+	delete(wsc.subscriptions, cmd.ID)
+	wsc.Unlock()
+	return exists, nil
+}
+
+// topicSubscriptions returns the subscriptions of the client to the topic.
+func (c *wsClient) topicSubscriptions(topic string) []*wsSubscription {
+	c.Lock()
+	defer c.Unlock()
+
+	var subs []*wsSubscription
+	for _, sub := range c.subscriptions {
+		if sub.topic == topic {
+			subs = append(subs, sub)
+		}
+	}
+	return subs
+}
+
+// queueSubscription queues the notification of a result for the
+// subscription.
+func (c *wsClient) queueSubscription(sub *wsSubscription, result interface{}) {
+	marshalledJSON, err := json.Marshal(&jsonNotification{
+		Version: jsonrpcVersion,
+		Method:  cmds.SubscriptionNtfnMethod,
+		Params: jsonSubscription{
+			Subscription: sub.id,
+			Result:       result,
+		},
+	})
+	if err != nil {
+		log.Error(fmt.Sprintf("Failed to marshal %s subscription notification: %v", sub.topic, err))
+		return
+	}
+	c.QueueNotification(marshalledJSON)
+}
+
+// notifySubscriptions notifies the result to every subscription of the
+// clients to the topic.
+func (m *wsNotificationManager) notifySubscriptions(clients map[chan struct{}]*wsClient, topic string, result interface{}) {
+	for _, wsc := range clients {
+		for _, sub := range wsc.topicSubscriptions(topic) {
+			wsc.queueSubscription(sub, result)
+		}
+	}
+}
+
+func subscribedBlock(block *types.SerializedBlock) *cmds.SubscribedBlock {
+	txs := make([]string, 0, len(block.Transactions()))
+	for _, tx := range block.Transactions() {
+		txs = append(txs, tx.Hash().String())
+	}
+	return &cmds.SubscribedBlock{
+		Hash:   block.Hash().String(),
+		Height: uint64(block.Height()),
+		Order:  block.Order(),
+		Time:   block.Block().Header.Timestamp.Unix(),
+		Txs:    txs,
+	}
+}
+
+func (m *wsNotificationManager) notifySubscribedBlock(clients map[chan struct{}]*wsClient, topic string, block *types.SerializedBlock) {
+	m.notifySubscriptions(clients, topic, subscribedBlock(block))
+}
+
+func (m *wsNotificationManager) notifySubscribedReorganization(clients map[chan struct{}]*wsClient, nr *notificationReorganization) {
+	m.notifySubscriptions(clients, cmds.SubscribeTopicReorganization, nr.Diff)
+}
+
+func (m *wsNotificationManager) notifySubscribedMempoolTx(clients map[chan struct{}]*wsClient, tx *types.Tx, action string) {
+	m.notifySubscriptions(clients, cmds.SubscribeTopicMempool, &cmds.SubscribedMempoolTx{
+		Action: action,
+		TxID:   tx.Hash().String(),
+	})
+}
+
+// notifyAddressActivity notifies the addressActivity subscriptions of the
+// transaction, the block is nil for a transaction of the mempool.
+func (m *wsNotificationManager) notifyAddressActivity(clients map[chan struct{}]*wsClient, tx *types.Tx, block *types.SerializedBlock) {
+	type activity struct {
+		addr  types.Address
+		spent bool
+	}
+	var acts []activity
+	if !tx.Tx.IsCoinBase() {
+		for _, input := range tx.Tx.TxIn {
+			pkScript, err := txscript.ComputePkScript(input.SignScript)
+			if err != nil {
+				continue
+			}
+			addr, err := pkScript.Address(m.server.ChainParams)
+			if err != nil {
+				continue
+			}
+			acts = append(acts, activity{addr: addr, spent: true})
+		}
+	}
+	for _, output := range tx.Tx.TxOut {
+		_, addrs, _, err := txscript.ExtractPkScriptAddrs(output.PkScript, m.server.ChainParams)
+		if err != nil {
+			continue
+		}
+		for _, addr := range addrs {
+			acts = append(acts, activity{addr: addr})
+		}
+	}
+	if len(acts) == 0 {
+		return
+	}
+
+	for _, wsc := range clients {
+		for _, sub := range wsc.topicSubscriptions(cmds.SubscribeTopicAddressActivity) {
+			// Notify every address once per transaction and direction.
+			notified := map[string]struct{}{}
+			for _, act := range acts {
+				sub.filter.mu.Lock()
+				exists := sub.filter.existsAddress(act.addr)
+				sub.filter.mu.Unlock()
+				if !exists {
+					continue
+				}
+				key := fmt.Sprintf("%s:%v", act.addr.String(), act.spent)
+				if _, ok := notified[key]; ok {
+					continue
+				}
+				notified[key] = struct{}{}
+				result := &cmds.SubscribedAddressActivity{
+					Address: act.addr.String(),
+					TxID:    tx.Hash().String(),
+					Spent:   act.spent,
+				}
+				if block != nil {
+					result.Block = block.Hash().String()
+					result.Order = block.Order()
+				}
+				wsc.queueSubscription(sub, result)
+			}
+		}
+	}
+}
+
+// notifyTokenEvents notifies the tokenEvents subscriptions of the token
+// transactions of the connected block.
+func (m *wsNotificationManager) notifyTokenEvents(clients map[chan struct{}]*wsClient, block *types.SerializedBlock) {
+	for _, tx := range block.Transactions() {
+		if !types.IsTokenTx(tx.Tx) {
+			continue
+		}
+		ids := map[types.CoinID]struct{}{}
+		coinIds := []uint16{}
+		for _, output := range tx.Tx.TxOut {
+			if output.Amount.Id == types.MEERA {
+				continue
+			}
+			if _, ok := ids[output.Amount.Id]; ok {
+				continue
+			}
+			ids[output.Amount.Id] = struct{}{}
+			coinIds = append(coinIds, uint16(output.Amount.Id))
+		}
+		result := &cmds.SubscribedTokenEvent{
+			Type:    types.DetermineTxType(tx.Tx).String(),
+			TxID:    tx.Hash().String(),
+			CoinIds: coinIds,
+			Block:   block.Hash().String(),
+			Order:   block.Order(),
+		}
+		for _, wsc := range clients {
+			for _, sub := range wsc.topicSubscriptions(cmds.SubscribeTopicTokenEvents) {
+				if !sub.matchCoinIds(ids) {
+					continue
+				}
+				wsc.queueSubscription(sub, result)
+			}
+		}
+	}
+}
+
+func (s *wsSubscription) matchCoinIds(ids map[types.CoinID]struct{}) bool {
+	if len(s.coinIds) == 0 {
+		return true
+	}
+	for id := range ids {
+		if _, ok := s.coinIds[id]; ok {
+			return true
+		}
+	}
+	return false
+}
+
+// NotifyRemovedTransaction notifies the websocket clients of a transaction
+// that was removed from the mempool.
+func (s *RpcServer) NotifyRemovedTransaction(tx *types.Tx) {
+	s.ntfnMgr.NotifyMempoolTxRemoved(tx)
+}
+
+func (m *wsNotificationManager) NotifyMempoolTxRemoved(tx *types.Tx) {
+	select {
+	case m.queueNotification <- (*notificationTxRemovedFromMempool)(tx):
+	case <-m.quit:
+	}
+}
+
+// notificationTxRemovedFromMempool is the notification of a transaction
+// removed from the mempool.
+type notificationTxRemovedFromMempool types.Tx
+
+// NotifyLockMatured notifies the websocket clients of a watched locked output
+// which matured.
+func (s *RpcServer) NotifyLockMatured(lm *cmds.SubscribedLockMatured) {
+	s.ntfnMgr.NotifyLockMatured(lm)
+}
+
+func (m *wsNotificationManager) NotifyLockMatured(lm *cmds.SubscribedLockMatured) {
+	select {
+	case m.queueNotification <- (*notificationLockMatured)(lm):
+	case <-m.quit:
+	}
+}
+
+// notificationLockMatured is the notification of a watched locked output
+// which matured.
+type notificationLockMatured cmds.SubscribedLockMatured
+
+// notifyLockMatured notifies the lockMatured subscriptions which watch the
+// address of the output.
+func (m *wsNotificationManager) notifyLockMatured(clients map[chan struct{}]*wsClient, lm *cmds.SubscribedLockMatured) {
+	addr, err := address.DecodeAddress(lm.Address)
+	if err != nil {
+		return
+	}
+	for _, wsc := range clients {
+		for _, sub := range wsc.topicSubscriptions(cmds.SubscribeTopicLockMatured) {
+			if sub.filter != nil {
+				sub.filter.mu.Lock()
+				exists := sub.filter.existsAddress(addr)
+				sub.filter.mu.Unlock()
+				if !exists {
+					continue
+				}
+			}
+			wsc.queueSubscription(sub, lm)
+		}
+	}
+}
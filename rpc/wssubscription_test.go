@@ -0,0 +1,189 @@
+/*
+ * Copyright (c) 2017-2020 The qitmeer developers
+ */
+
+package rpc
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/Qitmeer/qng/common/hash"
+	"github.com/Qitmeer/qng/config"
+	"github.com/Qitmeer/qng/core/address"
+	"github.com/Qitmeer/qng/core/types"
+	"github.com/Qitmeer/qng/crypto/ecc"
+	"github.com/Qitmeer/qng/engine/txscript"
+	"github.com/Qitmeer/qng/params"
+	"github.com/Qitmeer/qng/rpc/client/cmds"
+)
+
+// testSubscriptionNtfn is a received subscription notification.
+type testSubscriptionNtfn struct {
+	Method string `json:"method"`
+	Params struct {
+		Subscription string          `json:"subscription"`
+		Result       json.RawMessage `json:"result"`
+	} `json:"params"`
+}
+
+func newTestWsClient(t *testing.T) *wsClient {
+	s, err := NewRPCServer(&config.Config{RPCMaxConcurrentReqs: 1}, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	s.ChainParams = &params.TestNetParams
+	wsc, err := newWebsocketClient(s, nil, "test", true)
+	if err != nil {
+		t.Fatal(err)
+	}
+	wsc.ntfnChan = make(chan []byte, 16)
+	return wsc
+}
+
+func testSubscribe(t *testing.T, wsc *wsClient, topic string, filter *cmds.SubscribeFilter) string {
+	id, err := handleSubscribe(wsc, cmds.NewSubscribeCmd(topic, filter))
+	if err != nil {
+		t.Fatal(err)
+	}
+	return id.(string)
+}
+
+// receivedNtfns returns the queued subscription notifications.
+func receivedNtfns(t *testing.T, wsc *wsClient) []*testSubscriptionNtfn {
+	var ntfns []*testSubscriptionNtfn
+	for {
+		select {
+		case marshalled := <-wsc.ntfnChan:
+			ntfn := &testSubscriptionNtfn{}
+			if err := json.Unmarshal(marshalled, ntfn); err != nil {
+				t.Fatal(err)
+			}
+			if ntfn.Method != cmds.SubscriptionNtfnMethod {
+				t.Fatalf("got the notification method %s", ntfn.Method)
+			}
+			ntfns = append(ntfns, ntfn)
+		default:
+			return ntfns
+		}
+	}
+}
+
+func TestSubscribe(t *testing.T) {
+	wsc := newTestWsClient(t)
+	clients := map[chan struct{}]*wsClient{wsc.quit: wsc}
+	m := wsc.server.ntfnMgr
+
+	for _, cmd := range []*cmds.SubscribeCmd{
+		cmds.NewSubscribeCmd("unknown", nil),
+		cmds.NewSubscribeCmd(cmds.SubscribeTopicAddressActivity, nil),
+		cmds.NewSubscribeCmd(cmds.SubscribeTopicAddressActivity, &cmds.SubscribeFilter{}),
+	} {
+		if _, err := handleSubscribe(wsc, cmd); err == nil {
+			t.Fatalf("the subscription to %s is made", cmd.Topic)
+		}
+	}
+
+	mempool1 := testSubscribe(t, wsc, cmds.SubscribeTopicMempool, nil)
+	mempool2 := testSubscribe(t, wsc, cmds.SubscribeTopicMempool, nil)
+	testSubscribe(t, wsc, cmds.SubscribeTopicNewDAGBlocks, nil)
+	if mempool1 == mempool2 {
+		t.Fatalf("the subscriptions have the same id %s", mempool1)
+	}
+
+	tx := types.NewTx(types.NewTransaction())
+	m.notifySubscribedMempoolTx(clients, tx, cmds.MempoolActionAdd)
+	ntfns := receivedNtfns(t, wsc)
+	if len(ntfns) != 2 {
+		t.Fatalf("got %d notifications, want one per mempool subscription", len(ntfns))
+	}
+	ids := map[string]bool{}
+	for _, ntfn := range ntfns {
+		ids[ntfn.Params.Subscription] = true
+		var result cmds.SubscribedMempoolTx
+		if err := json.Unmarshal(ntfn.Params.Result, &result); err != nil {
+			t.Fatal(err)
+		}
+		if result.Action != cmds.MempoolActionAdd || result.TxID != tx.Hash().String() {
+			t.Fatalf("got the result %+v", result)
+		}
+	}
+	if !ids[mempool1] || !ids[mempool2] {
+		t.Fatalf("got the subscriptions %v, want %s and %s", ids, mempool1, mempool2)
+	}
+
+	exists, err := handleUnsubscribe(wsc, &cmds.UnsubscribeCmd{ID: mempool1})
+	if err != nil || !exists.(bool) {
+		t.Fatalf("the subscription %s isn't removed: %v", mempool1, err)
+	}
+	exists, err = handleUnsubscribe(wsc, &cmds.UnsubscribeCmd{ID: mempool1})
+	if err != nil || exists.(bool) {
+		t.Fatalf("the subscription %s is removed twice: %v", mempool1, err)
+	}
+	m.notifySubscribedMempoolTx(clients, tx, cmds.MempoolActionRemove)
+	ntfns = receivedNtfns(t, wsc)
+	if len(ntfns) != 1 || ntfns[0].Params.Subscription != mempool2 {
+		t.Fatalf("got %d notifications after unsubscribing", len(ntfns))
+	}
+}
+
+func TestSubscribeAddressActivity(t *testing.T) {
+	wsc := newTestWsClient(t)
+	clients := map[chan struct{}]*wsClient{wsc.quit: wsc}
+
+	var addrs []types.Address
+	for _, seed := range []string{"a", "b"} {
+		addr, err := address.NewPubKeyHashAddress(hash.Hash160([]byte(seed)), &params.TestNetParams, ecc.ECDSA_Secp256k1)
+		if err != nil {
+			t.Fatal(err)
+		}
+		addrs = append(addrs, addr)
+	}
+	id := testSubscribe(t, wsc, cmds.SubscribeTopicAddressActivity,
+		&cmds.SubscribeFilter{Addresses: []string{addrs[0].String()}})
+
+	mtx := types.NewTransaction()
+	mtx.AddTxIn(types.NewTxInput(types.NewOutPoint(&hash.Hash{0x01}, 0), []byte{}))
+	for _, addr := range []types.Address{addrs[0], addrs[0], addrs[1]} {
+		pkScript, err := txscript.PayToAddrScript(addr)
+		if err != nil {
+			t.Fatal(err)
+		}
+		mtx.AddTxOut(types.NewTxOutput(types.Amount{Id: types.MEERA, Value: 1e8}, pkScript))
+	}
+	tx := types.NewTx(mtx)
+	wsc.server.ntfnMgr.notifyAddressActivity(clients, tx, nil)
+
+	// The watched address is notified once for the transaction.
+	ntfns := receivedNtfns(t, wsc)
+	if len(ntfns) != 1 || ntfns[0].Params.Subscription != id {
+		t.Fatalf("got %d notifications, want 1", len(ntfns))
+	}
+	var result cmds.SubscribedAddressActivity
+	if err := json.Unmarshal(ntfns[0].Params.Result, &result); err != nil {
+		t.Fatal(err)
+	}
+	if result.Address != addrs[0].String() || result.TxID != tx.Hash().String() ||
+		result.Spent || result.Block != "" {
+		t.Fatalf("got the result %+v", result)
+	}
+}
+
+func TestSubscriptionMatchCoinIds(t *testing.T) {
+	wsc := newTestWsClient(t)
+	testSubscribe(t, wsc, cmds.SubscribeTopicTokenEvents, nil)
+	testSubscribe(t, wsc, cmds.SubscribeTopicTokenEvents, &cmds.SubscribeFilter{CoinIds: []uint16{100}})
+	subs := wsc.topicSubscriptions(cmds.SubscribeTopicTokenEvents)
+	if len(subs) != 2 {
+		t.Fatalf("got %d tokenEvents subscriptions, want 2", len(subs))
+	}
+	for _, sub := range subs {
+		all := len(sub.coinIds) == 0
+		if !sub.matchCoinIds(map[types.CoinID]struct{}{100: {}, 101: {}}) {
+			t.Fatalf("the subscription %s doesn't match its coin id", sub.id)
+		}
+		if sub.matchCoinIds(map[types.CoinID]struct{}{101: {}}) != all {
+			t.Fatalf("the subscription %s matches %v", sub.id, !all)
+		}
+	}
+}
@@ -225,6 +225,81 @@ function get_utxo() {
   get_result "$data"
 }
 
+# return the transaction input which spent an output
+function get_cross_chain_transfer() {
+  local id=$1
+  local data='{"jsonrpc":"2.0","method":"getCrossChainTransfer","params":["'$id'"],"id":1}'
+  get_result "$data"
+}
+
+function list_cross_chain_transfers() {
+  local address=$1
+  local count=$2
+  local skip=$3
+  local reverse=$4
+  if [ "$count" == "" ]; then
+    count=100
+  fi
+  if [ "$skip" == "" ]; then
+    skip=0
+  fi
+  if [ "$reverse" == "" ]; then
+    reverse=false
+  fi
+  local data='{"jsonrpc":"2.0","method":"listCrossChainTransfers","params":["'$address'",'$count','$skip','$reverse'],"id":1}'
+  get_result "$data"
+}
+
+function get_index_info() {
+  local data='{"jsonrpc":"2.0","method":"getIndexInfo","params":[],"id":1}'
+  get_result "$data"
+}
+
+function get_token_holders() {
+  local coin_id=$1
+  local count=$2
+  local skip=$3
+  if [ "$count" == "" ]; then
+    count=100
+  fi
+  if [ "$skip" == "" ]; then
+    skip=0
+  fi
+  local data='{"jsonrpc":"2.0","method":"getTokenHolders","params":['$coin_id','$count','$skip'],"id":1}'
+  get_result "$data"
+}
+
+function get_token_history() {
+  local coin_id=$1
+  local count=$2
+  local skip=$3
+  local reverse=$4
+  if [ "$count" == "" ]; then
+    count=100
+  fi
+  if [ "$skip" == "" ]; then
+    skip=0
+  fi
+  if [ "$reverse" == "" ]; then
+    reverse=false
+  fi
+  local data='{"jsonrpc":"2.0","method":"getTokenHistory","params":['$coin_id','$count','$skip','$reverse'],"id":1}'
+  get_result "$data"
+}
+
+function get_token_balances() {
+  local address=$1
+  local data='{"jsonrpc":"2.0","method":"getTokenBalances","params":["'$address'"],"id":1}'
+  get_result "$data"
+}
+
+function get_spending_tx() {
+  local tx_hash=$1
+  local vout=$2
+  local data='{"jsonrpc":"2.0","method":"getSpendingTx","params":["'$tx_hash'",'$vout'],"id":1}'
+  get_result "$data"
+}
+
 function tx_sign(){
    local private_key=$1
    local raw_tx=$2
@@ -318,6 +393,73 @@ function decode_raw_tx(){
   get_result "$data"
 }
 
+function fund_raw_tx(){
+  local raw_tx=$1
+  local options=$2
+  local data='{"jsonrpc":"2.0","method":"fundRawTransaction","params":["'$raw_tx'",'$options'],"id":1}'
+  get_result "$data"
+}
+
+function trace_tx_input(){
+  local raw_tx=$1
+  local index=$2
+  local pk_script=$3
+  local amount=$4
+  if [ "$amount" == "" ]; then
+    amount=0
+  fi
+  local data='{"jsonrpc":"2.0","method":"traceTxInput","params":["'$raw_tx'",'$index',"'$pk_script'",'$amount'],"id":1}'
+  get_result "$data"
+}
+
+function create_htlc(){
+  local recipient=$1
+  local refund=$2
+  local secret_hash=$3
+  local lock_time=$4
+  local hash_type=$5
+  local data='{"jsonrpc":"2.0","method":"createHTLC","params":["'$recipient'","'$refund'","'$secret_hash'",'$lock_time',"'$hash_type'"],"id":1}'
+  get_result "$data"
+}
+
+function create_psbt(){
+  local raw_tx=$1
+  local data='{"jsonrpc":"2.0","method":"createPsbt","params":["'$raw_tx'"],"id":1}'
+  get_result "$data"
+}
+
+function update_psbt(){
+  local psbt=$1
+  local redeem_scripts=$2
+  if [ "$redeem_scripts" == "" ]; then
+    redeem_scripts="[]"
+  fi
+  local data='{"jsonrpc":"2.0","method":"updatePsbt","params":["'$psbt'",'$redeem_scripts'],"id":1}'
+  get_result "$data"
+}
+
+function combine_psbt(){
+  local psbts=$1
+  local data='{"jsonrpc":"2.0","method":"combinePsbt","params":['$psbts'],"id":1}'
+  get_result "$data"
+}
+
+function finalize_psbt(){
+  local psbt=$1
+  local extract=$2
+  if [ "$extract" == "" ]; then
+    extract="true"
+  fi
+  local data='{"jsonrpc":"2.0","method":"finalizePsbt","params":["'$psbt'",'$extract'],"id":1}'
+  get_result "$data"
+}
+
+function decode_psbt(){
+  local psbt=$1
+  local data='{"jsonrpc":"2.0","method":"decodePsbt","params":["'$psbt'"],"id":1}'
+  get_result "$data"
+}
+
 function send_raw_tx(){
   local input=$1
   local allow_high_fee=$2
@@ -468,6 +610,155 @@ function add_balance() {
   get_result "$data"
 }
 
+function add_xpub() {
+  local name=$1
+  local xpub=$2
+  local path=$3
+  local gaplimit=$4
+  if [ "$path" == "" ]; then
+    path="0/*"
+  fi
+  if [ "$gaplimit" == "" ]; then
+    gaplimit=20
+  fi
+  local data='{"jsonrpc":"2.0","method":"addXpub","params":["'$name'","'$xpub'","'$path'",'$gaplimit'],"id":null}'
+  get_result "$data"
+}
+
+function get_xpub_balance() {
+  local name=$1
+  local data='{"jsonrpc":"2.0","method":"getXpubBalance","params":["'$name'"],"id":null}'
+  get_result "$data"
+}
+
+function get_address_history() {
+  local address=$1
+  local from=$2
+  local count=$3
+  if [ "$from" == "" ]; then
+    from=0
+  fi
+  if [ "$count" == "" ]; then
+    count=100
+  fi
+  local data='{"jsonrpc":"2.0","method":"getAddressHistory","params":["'$address'",'$from','$count'],"id":null}'
+  get_result "$data"
+}
+
+function get_balance_at() {
+  local address=$1
+  local order=$2
+  local data='{"jsonrpc":"2.0","method":"getBalanceAt","params":["'$address'",'$order'],"id":null}'
+  get_result "$data"
+}
+
+function get_locked_balance_schedule() {
+  local address=$1
+  local coinID=$2
+  if [ "$coinID" == "" ]; then
+    coinID=0
+  fi
+  local data='{"jsonrpc":"2.0","method":"getLockedBalanceSchedule","params":["'$address'",'$coinID'],"id":null}'
+  get_result "$data"
+}
+
+function create_wallet() {
+  local passphrase=$1
+  local seed=$2
+  local data='{"jsonrpc":"2.0","method":"wallet_createWallet","params":["'$passphrase'","'$seed'"],"id":null}'
+  get_result "$data"
+}
+
+function wallet_unlock() {
+  local passphrase=$1
+  local timeout=$2
+  if [ "$timeout" == "" ]; then
+    timeout=60
+  fi
+  local data='{"jsonrpc":"2.0","method":"wallet_unlock","params":["'$passphrase'",'$timeout'],"id":null}'
+  get_result "$data"
+}
+
+function wallet_lock() {
+  local data='{"jsonrpc":"2.0","method":"wallet_lock","params":[],"id":null}'
+  get_result "$data"
+}
+
+function get_wallet_info() {
+  local data='{"jsonrpc":"2.0","method":"wallet_getWalletInfo","params":[],"id":null}'
+  get_result "$data"
+}
+
+function get_new_address() {
+  local account=$1
+  local data='{"jsonrpc":"2.0","method":"wallet_getNewAddress","params":["'$account'"],"id":null}'
+  get_result "$data"
+}
+
+function list_unspent() {
+  local coinID=$1
+  if [ "$coinID" == "" ]; then
+    local data='{"jsonrpc":"2.0","method":"wallet_listUnspent","params":[],"id":null}'
+  else
+    local data='{"jsonrpc":"2.0","method":"wallet_listUnspent","params":['$coinID'],"id":null}'
+  fi
+  get_result "$data"
+}
+
+function send_to_address() {
+  local address=$1
+  local amount=$2
+  local coinID=$3
+  if [ "$coinID" == "" ]; then
+    coinID=0
+  fi
+  local data='{"jsonrpc":"2.0","method":"wallet_sendToAddress","params":["'$address'",'$amount','$coinID'],"id":null}'
+  get_result "$data"
+}
+
+function send_many() {
+  local amounts=$1
+  local data='{"jsonrpc":"2.0","method":"wallet_sendMany","params":['$amounts'],"id":null}'
+  get_result "$data"
+}
+
+function sign_raw_tx_with_wallet() {
+  local raw_tx=$1
+  local data='{"jsonrpc":"2.0","method":"wallet_signRawTransactionWithWallet","params":["'$raw_tx'"],"id":null}'
+  get_result "$data"
+}
+
+function sign_psbt() {
+  local psbt=$1
+  local sighash=$2
+  local data='{"jsonrpc":"2.0","method":"wallet_signPsbt","params":["'$psbt'","'$sighash'"],"id":null}'
+  get_result "$data"
+}
+
+function sign_message() {
+  local address=$1
+  local message=$2
+  local data='{"jsonrpc":"2.0","method":"wallet_signMessage","params":["'$address'","'$message'"],"id":null}'
+  get_result "$data"
+}
+
+function redeem_htlc() {
+  local raw_tx=$1
+  local index=$2
+  local contract=$3
+  local secret=$4
+  local data='{"jsonrpc":"2.0","method":"wallet_redeemHTLC","params":["'$raw_tx'",'$index',"'$contract'","'$secret'"],"id":null}'
+  get_result "$data"
+}
+
+function refund_htlc() {
+  local raw_tx=$1
+  local index=$2
+  local contract=$3
+  local data='{"jsonrpc":"2.0","method":"wallet_refundHTLC","params":["'$raw_tx'",'$index',"'$contract'"],"id":null}'
+  get_result "$data"
+}
+
 function get_acctinfo() {
    local data='{"jsonrpc":"2.0","method":"getAcctInfo","params":[],"id":null}'
    get_result "$data"
@@ -489,6 +780,34 @@ function get_addresses(){
   get_result "$data"
 }
 
+function verify_message(){
+  local address=$1
+  local signature=$2
+  local message=$3
+  local data='{"jsonrpc":"2.0","method":"verifyMessage","params":["'$address'","'$signature'","'$message'"],"id":null}'
+  get_result "$data"
+}
+
+function get_descriptor_info(){
+  local descriptor=$1
+  local data='{"jsonrpc":"2.0","method":"getDescriptorInfo","params":["'$descriptor'"],"id":null}'
+  get_result "$data"
+}
+
+function derive_addresses(){
+  local descriptor=$1
+  local begin=$2
+  local end=$3
+  if [ "$begin" == "" ]; then
+    local data='{"jsonrpc":"2.0","method":"deriveAddresses","params":["'$descriptor'"],"id":null}'
+  elif [ "$end" == "" ]; then
+    local data='{"jsonrpc":"2.0","method":"deriveAddresses","params":["'$descriptor'",'$begin'],"id":null}'
+  else
+    local data='{"jsonrpc":"2.0","method":"deriveAddresses","params":["'$descriptor'",'$begin','$end'],"id":null}'
+  fi
+  get_result "$data"
+}
+
 function get_rpc_info(){
   local data='{"jsonrpc":"2.0","method":"getRpcInfo","params":[],"id":null}'
   get_result "$data"
@@ -605,6 +924,21 @@ function get_tokeninfo(){
   get_result "$data"
 }
 
+function get_blockstats(){
+  local hash_or_order=$1
+  local fields=$2
+  if [ "$fields" == "" ]; then
+    fields="[]"
+  fi
+  local data='{"jsonrpc":"2.0","method":"getBlockStats","params":["'$hash_or_order'",'$fields'],"id":null}'
+  get_result "$data"
+}
+
+function get_txoutsetinfo(){
+  local data='{"jsonrpc":"2.0","method":"getTxOutSetInfo","params":[],"id":null}'
+  get_result "$data"
+}
+
 function submit_block() {
   local input=$1
   local data='{"jsonrpc":"2.0","method":"submitBlock","params":["'$input'"],"id":1}'
@@ -727,7 +1061,15 @@ function usage(){
   echo "  getbalance <address> <coinID>"
   echo "  getbalanceinfo <address> <coinID>"
   echo "  addbalance <address>"
+  echo "  addxpub <name> <xpub> <path,default=0/*> <gaplimit,default=20>"
+  echo "  getxpubbalance <name>"
+  echo "  addresshistory <address> <from order,default=0> <count,default=100>"
+  echo "  balanceat <address> <order>"
+  echo "  lockedschedule <address> <coinID,default=0>"
   echo "  getaddresses <private key>"
+  echo "  verifymessage <address> <signature> <message>"
+  echo "  getdescriptorinfo <descriptor>"
+  echo "  deriveaddresses <descriptor#checksum> <begin> <end>"
   echo "  modules"
   echo "  daginfo"
   echo "block  :"
@@ -748,6 +1090,8 @@ function usage(){
   echo "  fees <hash>"
   echo "  estimatefee <numblocks>"
   echo "  tokeninfo"
+  echo "  txoutsetinfo"
+  echo "  blockstats <hash|order> <fields,default=[]>"
   echo "tx     :"
   echo "  tx <id>"
   echo "  evmtxhash <id>"
@@ -757,14 +1101,43 @@ function usage(){
   echo "  createRawTx"
   echo "  createRawTxV2"
   echo "  createTokenRawTx"
+  echo "  fundRawTx <rawTx> <{\"addresses\":[\"address\"],\"changeAddress\":\"\",\"feeRate\":0,\"confTarget\":6}>"
   echo "  createExportRawTx <txid> <vout> <PKAdress> <amount>"
   echo "  createExportRawTxV2 <inputs> <outputs> <lockTime>"
   echo "  createImportRawTx <PKAdress> <amount>"
   echo "  txSign <rawTx>"
   echo "  sendRawTx <signedRawTx>"
+  echo "  traceTxInput <rawTx> <inputIndex> <prevPkScript> <amount,default=0>"
+  echo "  createhtlc <recipient> <refund> <secretHash> <lockTime> <hashType,default=sha256>"
+  echo "  createpsbt <rawTx>"
+  echo "  updatepsbt <psbt> <[\"redeemScript\"],default=[]>"
+  echo "  combinepsbt <[\"psbt\",\"psbt\"]>"
+  echo "  finalizepsbt <psbt> <extract,default=true>"
+  echo "  decodepsbt <psbt>"
   echo "  getrawtxs <address>"
   echo "utxo   :"
   echo "  getutxo <tx_id> <index> <include_mempool,default=true>"
+  echo "  getspendingtx <tx_id> <index>"
+  echo "  indexinfo"
+  echo "  crosschaintransfer <tx_id|0xevm_tx_hash>"
+  echo "  crosschaintransfers <address|0xevm_address> <count,default=100> <skip,default=0> <reverse,default=false>"
+  echo "  tokenholders <coin_id> <count,default=100> <skip,default=0>"
+  echo "  tokenhistory <coin_id> <count,default=100> <skip,default=0> <reverse,default=false>"
+  echo "  tokenbalances <address>"
+  echo "wallet :"
+  echo "  createwallet <passphrase> <seed hex,default=random>"
+  echo "  unlock <passphrase> <timeout seconds,default=60>"
+  echo "  lock"
+  echo "  walletinfo"
+  echo "  getnewaddress <account,default=default>"
+  echo "  listunspent <coinID,default=all>"
+  echo "  sendtoaddress <address> <amount> <coinID,default=0>"
+  echo "  sendmany <{\"address\":{\"coinid\":0,\"amount\":1}}>"
+  echo "  signrawtxwithwallet <rawTx>"
+  echo "  signpsbt <psbt> <sighash,default=ALL>"
+  echo "  signmessage <address> <message>"
+  echo "  redeemhtlc <rawTx> <inputIndex> <contract> <secret>"
+  echo "  refundhtlc <rawTx> <inputIndex> <contract>"
   echo "miner  :"
   echo "  template"
   echo "  generate <num>"
@@ -1078,6 +1451,18 @@ elif [ "$1" == "getaddresses" ]; then
   shift
   get_addresses $@
 
+elif [ "$1" == "verifymessage" ]; then
+  shift
+  verify_message "$@"
+
+elif [ "$1" == "getdescriptorinfo" ]; then
+  shift
+  get_descriptor_info $@
+
+elif [ "$1" == "deriveaddresses" ]; then
+  shift
+  derive_addresses $@
+
 elif [ "$1" == "subsidy" ]; then
   shift
   get_subsidy $@
@@ -1124,6 +1509,60 @@ elif [ "$1" == "getbalanceinfo" ]; then
 elif [ "$1" == "addbalance" ]; then
   shift
   add_balance $@
+elif [ "$1" == "addxpub" ]; then
+  shift
+  add_xpub $@
+elif [ "$1" == "getxpubbalance" ]; then
+  shift
+  get_xpub_balance $@
+elif [ "$1" == "addresshistory" ]; then
+  shift
+  get_address_history $@
+elif [ "$1" == "balanceat" ]; then
+  shift
+  get_balance_at $@
+elif [ "$1" == "lockedschedule" ]; then
+  shift
+  get_locked_balance_schedule $@
+elif [ "$1" == "createwallet" ]; then
+  shift
+  create_wallet $@
+elif [ "$1" == "unlock" ]; then
+  shift
+  wallet_unlock $@
+elif [ "$1" == "lock" ]; then
+  shift
+  wallet_lock $@
+elif [ "$1" == "walletinfo" ]; then
+  shift
+  get_wallet_info $@
+elif [ "$1" == "getnewaddress" ]; then
+  shift
+  get_new_address $@
+elif [ "$1" == "listunspent" ]; then
+  shift
+  list_unspent $@
+elif [ "$1" == "sendtoaddress" ]; then
+  shift
+  send_to_address $@
+elif [ "$1" == "sendmany" ]; then
+  shift
+  send_many $@
+elif [ "$1" == "signrawtxwithwallet" ]; then
+  shift
+  sign_raw_tx_with_wallet $@
+elif [ "$1" == "signpsbt" ]; then
+  shift
+  sign_psbt $@
+elif [ "$1" == "signmessage" ]; then
+  shift
+  sign_message "$@"
+elif [ "$1" == "redeemhtlc" ]; then
+  shift
+  redeem_htlc $@
+elif [ "$1" == "refundhtlc" ]; then
+  shift
+  refund_htlc $@
 elif [ "$1" == "rpcmax" ]; then
   shift
   set_rpc_maxclients $@
@@ -1149,6 +1588,12 @@ elif [ "$1" == "modules" ]; then
 elif [ "$1" == "tokeninfo" ]; then
   shift
   get_tokeninfo | jq .
+elif [ "$1" == "blockstats" ]; then
+  shift
+  get_blockstats $@ | jq .
+elif [ "$1" == "txoutsetinfo" ]; then
+  shift
+  get_txoutsetinfo | jq .
 elif [ "$1" == "coinbase" ]; then
   shift
   get_coinbase $@
@@ -1240,11 +1685,56 @@ elif [ "$1" == "vmsinfo" ]; then
 elif [ "$1" == "txSign" ]; then
   shift
   tx_sign $@
+elif [ "$1" == "fundRawTx" ]; then
+  shift
+  fund_raw_tx $@
+elif [ "$1" == "traceTxInput" ]; then
+  shift
+  trace_tx_input $@
+elif [ "$1" == "createhtlc" ]; then
+  shift
+  create_htlc $@
+elif [ "$1" == "createpsbt" ]; then
+  shift
+  create_psbt $@
+elif [ "$1" == "updatepsbt" ]; then
+  shift
+  update_psbt $@
+elif [ "$1" == "combinepsbt" ]; then
+  shift
+  combine_psbt $@
+elif [ "$1" == "finalizepsbt" ]; then
+  shift
+  finalize_psbt $@
+elif [ "$1" == "decodepsbt" ]; then
+  shift
+  decode_psbt $@
 
 ## UTXO
 elif [ "$1" == "getutxo" ]; then
   shift
   get_utxo $@
+elif [ "$1" == "getspendingtx" ]; then
+  shift
+  get_spending_tx $@
+elif [ "$1" == "indexinfo" ]; then
+  shift
+  get_index_info | jq .
+elif [ "$1" == "crosschaintransfer" ]; then
+  shift
+  get_cross_chain_transfer $@
+elif [ "$1" == "crosschaintransfers" ]; then
+  shift
+  list_cross_chain_transfers $@
+elif [ "$1" == "tokenholders" ]; then
+  shift
+  get_token_holders $@
+elif [ "$1" == "tokenhistory" ]; then
+  shift
+  get_token_history $@
+elif [ "$1" == "tokenbalances" ]; then
+  shift
+  get_token_balances $@
 
 ## Accounts
 elif [ "$1" == "newaccount" ]; then
@@ -9,6 +9,7 @@ import (
 	"github.com/Qitmeer/qng/core/blockchain"
 	"github.com/Qitmeer/qng/core/blockchain/utxo"
 	"github.com/Qitmeer/qng/core/dbnamespace"
+	"github.com/Qitmeer/qng/core/json"
 	"github.com/Qitmeer/qng/core/types"
 	"github.com/Qitmeer/qng/database"
 	"github.com/Qitmeer/qng/engine/txscript"
@@ -16,6 +17,7 @@ import (
 	"github.com/Qitmeer/qng/params"
 	"github.com/Qitmeer/qng/rpc/api"
 	"github.com/Qitmeer/qng/rpc/client/cmds"
+	"math"
 )
 
 // account manager communicate with various backends for signing transactions.
@@ -27,6 +29,16 @@ type AccountManager struct {
 	info     *AcctInfo
 	utxoops  []*UTXOOP
 	watchers map[string]*AcctBalanceWatcher
+
+	historyops []*blockHistoryOp
+
+	// rebuildMarked is set when the account database failed to follow the
+	// chain, it isn't updated anymore until it is rebuilt.
+	rebuildMarked bool
+
+	// LockMaturedNotify, when not nil, is called for every watched locked
+	// output which matures with the connected blocks.
+	LockMaturedNotify func(lm *cmds.SubscribedLockMatured)
 }
 
 func (a *AccountManager) Start() error {
@@ -66,6 +78,7 @@ func (a *AccountManager) initDB(first bool) error {
 	curDAGID := uint32(a.chain.BlockDAG().GetBlockTotal())
 	rebuilddb := false
 	rebuildidx := false
+	migrate := false
 	err = a.db.Update(func(dbTx database.Tx) error {
 		info, err := DBGetACCTInfo(dbTx)
 		if err != nil {
@@ -82,7 +95,10 @@ func (a *AccountManager) initDB(first bool) error {
 		} else {
 			a.info = info
 			log.Info(fmt.Sprintf("Load account manager info:%s", a.info.String()))
-			if !a.info.IsCurrentVersion() {
+			if a.info.version == preTokenAcctInfoVersion {
+				migrate = true
+				return nil
+			} else if !a.info.IsCurrentVersion() {
 				log.Warn(fmt.Sprintf("The account database version is not current(%d != %d). It will be rebuilt", a.info.version, CurrentAcctInfoVersion))
 				rebuilddb = true
 				return nil
@@ -102,10 +118,18 @@ func (a *AccountManager) initDB(first bool) error {
 	if err != nil {
 		return err
 	}
-	if rebuilddb {
+	if migrate {
+		err = a.migrateTokens()
+		if err != nil {
+			return err
+		}
+		return a.initDB(false)
+	} else if rebuilddb {
 		info := NewAcctInfo()
 		if a.info != nil {
 			info.addrs = a.info.addrs
+			info.xpubs = a.info.xpubs
+			info.derived = a.info.derived
 		}
 		a.info = info
 		a.cleanDB()
@@ -119,6 +143,7 @@ func (a *AccountManager) initDB(first bool) error {
 		if err != nil {
 			return err
 		}
+		return a.watchXpubs()
 	}
 	return nil
 }
@@ -143,10 +168,12 @@ func (a *AccountManager) cleanDB() {
 				if err != nil {
 					return err
 				}
-				if meta.Bucket(BalanceBucketName) != nil {
-					err := meta.DeleteBucket(BalanceBucketName)
-					if err != nil {
-						return err
+				for _, bucketName := range [][]byte{BalanceBucketName, HistoryBucketName, HistoryBlockBucketName} {
+					if meta.Bucket(bucketName) != nil {
+						err := meta.DeleteBucket(bucketName)
+						if err != nil {
+							return err
+						}
 					}
 				}
 			}
@@ -169,6 +196,26 @@ func (a *AccountManager) rebuild(addrs []string) error {
 	} else {
 		log.Trace("Try to rebuild account index")
 	}
+	ops, entrys, err := a.fetchUtxos(addrs)
+	if err != nil {
+		return err
+	}
+	for i := 0; i < len(ops); i++ {
+		err = a.apply(true, ops[i], entrys[i])
+		if err != nil {
+			return err
+		}
+		err = a.seedHistory(ops[i], entrys[i])
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// fetchUtxos returns the unspent outputs of the addresses, or of all the
+// watched addresses when none is given.
+func (a *AccountManager) fetchUtxos(addrs []string) ([]*types.TxOutPoint, []*utxo.UtxoEntry, error) {
 	ops := []*types.TxOutPoint{}
 	entrys := []*utxo.UtxoEntry{}
 	err := a.chain.DB().View(func(dbTx database.Tx) error {
@@ -189,38 +236,60 @@ func (a *AccountManager) rebuild(addrs []string) error {
 			if entry.IsSpent() {
 				continue
 			}
+			addr, _ := historyAddr(entry.PkScript())
+			if len(addr) <= 0 {
+				continue
+			}
 			if len(addrs) > 0 {
-				addr, _, err := a.checkUtxoEntry(entry, addrs)
-				if err != nil {
-					return err
+				has := false
+				for _, ad := range addrs {
+					if ad == addr {
+						has = true
+						break
+					}
 				}
-				if len(addr) <= 0 {
+				if !has {
 					continue
 				}
+			} else if !a.info.Has(addr) {
+				continue
 			}
 			ops = append(ops, op)
 			entrys = append(entrys, entry)
 		}
 		return nil
 	})
+	if err != nil {
+		return nil, nil, err
+	}
+	return ops, entrys, nil
+}
+
+// migrateTokens migrates the account database of the version before the
+// token outputs were tracked by adding the token outputs of the watched
+// addresses.  Its history already has the token events.
+func (a *AccountManager) migrateTokens() error {
+	log.Info(fmt.Sprintf("Migrate the account database from version %d to %d", a.info.version, CurrentAcctInfoVersion))
+	ops, entrys, err := a.fetchUtxos(nil)
 	if err != nil {
 		return err
 	}
-	if len(ops) > 0 {
-		for i := 0; i < len(ops); i++ {
-			err = a.apply(true, ops[i], entrys[i])
-			if err != nil {
-				return err
-			}
+	for i := 0; i < len(ops); i++ {
+		if entrys[i].Amount().Id == types.MEERA {
+			continue
+		}
+		err = a.apply(true, ops[i], entrys[i])
+		if err != nil {
+			return err
 		}
 	}
-	return nil
+	a.info.version = CurrentAcctInfoVersion
+	return a.db.Update(func(dbTx database.Tx) error {
+		return DBPutACCTInfo(dbTx, a.info)
+	})
 }
 
-func (a *AccountManager) checkUtxoEntry(entry *utxo.UtxoEntry, tracks []string) (string, txscript.ScriptClass, error) {
-	if entry.Amount().Id != types.MEERA {
-		return "", txscript.NonStandardTy, nil
-	}
+func (a *AccountManager) checkUtxoEntry(entry *utxo.UtxoEntry, isTracked func(addr string) bool) (string, txscript.ScriptClass, error) {
 	scriptClass, addrs, _, err := txscript.ExtractPkScriptAddrs(entry.PkScript(), params.ActiveNetParams.Params)
 	if err != nil {
 		return "", txscript.NonStandardTy, err
@@ -229,19 +298,7 @@ func (a *AccountManager) checkUtxoEntry(entry *utxo.UtxoEntry, tracks []string)
 		return "", txscript.NonStandardTy, nil
 	}
 	addrStr := addrs[0].String()
-
-	isHas := func(addr string) bool {
-		if len(tracks) <= 0 {
-			return false
-		}
-		for _, ad := range tracks {
-			if ad == addr {
-				return true
-			}
-		}
-		return false
-	}
-	if !isHas(addrStr) {
+	if !isTracked(addrStr) {
 		return "", txscript.NonStandardTy, nil
 	}
 	if scriptClass != txscript.PubKeyHashTy &&
@@ -249,17 +306,24 @@ func (a *AccountManager) checkUtxoEntry(entry *utxo.UtxoEntry, tracks []string)
 		scriptClass != txscript.CLTVPubKeyHashTy {
 		return "", txscript.NonStandardTy, nil
 	}
+	// The tokens are only tracked on the standard pay-to-pubkey(-hash) outputs.
+	if entry.Amount().Id != types.MEERA && scriptClass == txscript.CLTVPubKeyHashTy {
+		return "", txscript.NonStandardTy, nil
+	}
 	return addrStr, scriptClass, nil
 }
 
 func (a *AccountManager) apply(add bool, op *types.TxOutPoint, entry *utxo.UtxoEntry) error {
-	addrStr, scriptClass, err := a.checkUtxoEntry(entry, a.info.addrs)
+	addrStr, scriptClass, err := a.checkUtxoEntry(entry, a.info.Has)
 	if err != nil {
 		return err
 	}
 	if len(addrStr) <= 0 {
 		return nil
 	}
+	if entry.Amount().Id != types.MEERA {
+		return a.applyToken(add, addrStr, op, entry)
+	}
 	if add {
 		if entry.Amount().Value == 0 && !entry.IsCoinBase() {
 			return nil
@@ -267,6 +331,9 @@ func (a *AccountManager) apply(add bool, op *types.TxOutPoint, entry *utxo.UtxoE
 		if entry.IsCoinBase() && op.OutIndex != blockchain.CoinbaseOutput_subsidy {
 			return nil
 		}
+		// The new addresses after the moved gap limit are watched once the
+		// outputs are applied, see watchXpubs.
+		a.info.markUsed(addrStr)
 		var balance *AcctBalance
 		err = a.db.View(func(dbTx database.Tx) error {
 			balance, err = DBGetACCTBalance(dbTx, addrStr)
@@ -376,10 +443,22 @@ func (a *AccountManager) apply(add bool, op *types.TxOutPoint, entry *utxo.UtxoE
 			}
 			log.Trace(fmt.Sprintf("Del balance: %s (%s:%d)", addrStr, op.Hash.String(), op.OutIndex))
 			if balance.IsEmpty() {
-				er = a.cleanBalanceDB(dbTx, addrStr)
+				// The token outputs of the address are kept.
+				er = DBDelACCTBalance(dbTx, addrStr)
+				if er != nil {
+					return er
+				}
+				er = DBDelACCTUTXO(dbTx, addrStr, op)
 				if er != nil {
 					return er
 				}
+				if a.info.total > 0 {
+					a.info.total--
+					er = DBPutACCTInfo(dbTx, a.info)
+					if er != nil {
+						return er
+					}
+				}
 			} else {
 				er = DBPutACCTBalance(dbTx, addrStr, balance)
 				if er != nil {
@@ -475,25 +554,29 @@ func (a *AccountManager) initWatchers(dbTx database.Tx) error {
 			if err != nil {
 				return err
 			}
+			// The outputs which are already mature at startup are not
+			// notified.
+			w.matured = nil
 		}
 	}
 	return nil
 }
 
-func (a *AccountManager) Apply(add bool, op *types.TxOutPoint, entry interface{}) error {
-	if !a.cfg.AcctMode {
+func (a *AccountManager) Apply(add bool, op *types.TxOutPoint, entry *utxo.UtxoEntry) error {
+	if !a.cfg.AcctMode || a.rebuildMarked {
 		return nil
 	}
-	a.utxoops = append(a.utxoops, &UTXOOP{add: add, op: op, entry: entry.(*utxo.UtxoEntry)})
+	a.utxoops = append(a.utxoops, &UTXOOP{add: add, op: op, entry: entry})
 	return nil
 }
 
 func (a *AccountManager) Commit() error {
-	if !a.cfg.AcctMode {
+	if !a.cfg.AcctMode || a.rebuildMarked {
 		return nil
 	}
 	defer func() {
 		a.utxoops = []*UTXOOP{}
+		a.historyops = []*blockHistoryOp{}
 	}()
 
 	curDAGID := uint32(a.chain.BlockDAG().GetBlockTotal())
@@ -511,6 +594,14 @@ func (a *AccountManager) Commit() error {
 			return err
 		}
 	}
+	err = a.commitHistory()
+	if err != nil {
+		return err
+	}
+	err = a.watchXpubs()
+	if err != nil {
+		return err
+	}
 
 	if len(a.watchers) > 0 {
 		for _, w := range a.watchers {
@@ -519,10 +610,32 @@ func (a *AccountManager) Commit() error {
 				return err
 			}
 		}
+		a.notifyMatured()
 	}
 	return nil
 }
 
+// MarkRebuild marks the account database for a rebuild after it failed to
+// follow the chain.  It isn't updated anymore and its DAG id no longer
+// matches the chain, so it is rebuilt the next time the account manager is
+// started.
+func (a *AccountManager) MarkRebuild(err error) {
+	if !a.cfg.AcctMode || a.rebuildMarked || a.db == nil {
+		return
+	}
+	log.Error(fmt.Sprintf("The account database will be rebuilt on the next start:%s", err.Error()))
+	a.rebuildMarked = true
+	a.utxoops = []*UTXOOP{}
+	a.historyops = []*blockHistoryOp{}
+	a.info.updateDAGID = math.MaxUint32
+	err = a.db.Update(func(dbTx database.Tx) error {
+		return DBPutACCTInfo(dbTx, a.info)
+	})
+	if err != nil {
+		log.Error(err.Error())
+	}
+}
+
 func (a *AccountManager) GetBalance(addr string) (uint64, error) {
 	if !a.cfg.AcctMode {
 		return 0, fmt.Errorf("Please enable --acctmode")
@@ -552,13 +665,16 @@ func (a *AccountManager) GetBalance(addr string) (uint64, error) {
 	return result, nil
 }
 
-func (a *AccountManager) GetUTXOs(addr string) ([]UTXOResult, error) {
-	utxos := []UTXOResult{}
+func (a *AccountManager) GetUTXOs(addr string) ([]json.UTXOResult, error) {
+	utxos := []json.UTXOResult{}
 	err := a.db.Update(func(dbTx database.Tx) error {
 		us := DBGetACCTUTXOs(dbTx, addr)
 		if len(us) > 0 {
 			for k, v := range us {
-				ur := UTXOResult{Type: v.TypeStr(), Amount: v.balance, Status: "valid"}
+				if v.IsToken() {
+					continue
+				}
+				ur := json.UTXOResult{Type: v.TypeStr(), Amount: v.balance, Status: "valid"}
 				wb, exist := a.watchers[addr]
 				if exist {
 					wu := wb.GetByOPS(k)
@@ -617,6 +733,112 @@ func (a *AccountManager) AddAddress(addr string) error {
 	return a.rebuild([]string{addr})
 }
 
+// AddXpub watches the addresses derived from the extended public key by the
+// path template, up to the gap limit after the last used address.
+func (a *AccountManager) AddXpub(name string, xpub string, path string, gapLimit uint32) error {
+	if !a.cfg.AcctMode {
+		return fmt.Errorf("Please enable --acctmode")
+	}
+	xa, err := NewXpubAcct(name, xpub, path, gapLimit)
+	if err != nil {
+		return err
+	}
+	addrs, err := a.info.AddXpub(xa)
+	if err != nil {
+		return err
+	}
+	log.Info(fmt.Sprintf("Add xpub account:%s", xa.String()))
+	err = a.db.Update(func(dbTx database.Tx) error {
+		return DBPutACCTInfo(dbTx, a.info)
+	})
+	if err != nil {
+		return err
+	}
+	err = a.rebuild(addrs)
+	if err != nil {
+		return err
+	}
+	return a.watchXpubs()
+}
+
+// watchXpubs derives the new addresses of the xpub accounts whose gap limit
+// moved and applies the outputs which they already received, until no gap
+// limit moves.
+func (a *AccountManager) watchXpubs() error {
+	changed := false
+	for {
+		addrs, err := a.info.extendXpubs()
+		if err != nil {
+			return err
+		}
+		if len(addrs) <= 0 {
+			break
+		}
+		changed = true
+		err = a.rebuild(addrs)
+		if err != nil {
+			return err
+		}
+	}
+	if !changed {
+		return nil
+	}
+	return a.db.Update(func(dbTx database.Tx) error {
+		return DBPutACCTInfo(dbTx, a.info)
+	})
+}
+
+// GetXpubBalance returns the balances of the xpub account and of its derived
+// addresses.
+func (a *AccountManager) GetXpubBalance(name string) (*json.XpubBalanceResult, error) {
+	if !a.cfg.AcctMode {
+		return nil, fmt.Errorf("Please enable --acctmode")
+	}
+	xa := a.info.GetXpub(name)
+	if xa == nil {
+		return nil, fmt.Errorf("No xpub account:%s", name)
+	}
+	result := &json.XpubBalanceResult{
+		Name:     xa.name,
+		Xpub:     xa.xpub,
+		Path:     xa.path,
+		GapLimit: xa.gapLimit,
+		Used:     xa.used,
+		Addrs:    []json.XpubAddrBalanceResult{},
+	}
+	err := a.db.View(func(dbTx database.Tx) error {
+		for i, addr := range xa.addrs {
+			ar := json.XpubAddrBalanceResult{
+				Index:   uint32(i),
+				Path:    xa.IndexPath(uint32(i)),
+				Address: addr,
+			}
+			balance, err := DBGetACCTBalance(dbTx, addr)
+			if err != nil {
+				return err
+			}
+			if balance != nil {
+				ar.Balance = balance.normal
+				ar.Locked = balance.locked
+				ar.UTXOs = balance.norUTXONum + balance.locUTXONum
+				wb, exist := a.watchers[addr]
+				if exist {
+					ar.Balance = wb.GetBalance()
+					ar.Locked -= wb.unlocked
+				}
+			}
+			result.Balance += ar.Balance
+			result.Locked += ar.Locked
+			result.Addrs = append(result.Addrs, ar)
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return result, nil
+}
+
 func (a *AccountManager) cleanBalanceDB(dbTx database.Tx, addr string) error {
 	er := DBDelACCTBalance(dbTx, addr)
 	if er != nil {
@@ -653,6 +875,8 @@ func New(chain *blockchain.BlockChain, cfg *config.Config) (*AccountManager, err
 		info:     NewAcctInfo(),
 		utxoops:  []*UTXOOP{},
 		watchers: map[string]*AcctBalanceWatcher{},
+
+		historyops: []*blockHistoryOp{},
 	}
 	return &a, nil
 }
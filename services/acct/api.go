@@ -2,6 +2,7 @@ package acct
 
 import (
 	"fmt"
+	"github.com/Qitmeer/qng/core/address"
 	"github.com/Qitmeer/qng/core/json"
 	"github.com/Qitmeer/qng/core/types"
 )
@@ -18,6 +19,7 @@ func NewPublicAccountManagerAPI(a *AccountManager) *PublicAccountManagerAPI {
 }
 
 func (api *PublicAccountManagerAPI) GetBalance(addr string, coinID types.CoinID) (interface{}, error) {
+	addr = address.NormalizeAddress(addr)
 	if coinID == types.MEERA {
 		return api.a.GetBalance(addr)
 	} else if coinID == types.MEERB {
@@ -36,11 +38,15 @@ func (api *PublicAccountManagerAPI) GetAcctInfo() (interface{}, error) {
 	if api.a.info.GetAddrTotal() > 0 {
 		ai.Addrs = api.a.info.addrs
 	}
+	for _, xa := range api.a.info.xpubs {
+		ai.Xpubs = append(ai.Xpubs, xa.name)
+	}
 	return ai, nil
 }
 
 func (api *PublicAccountManagerAPI) GetBalanceInfo(addr string, coinID types.CoinID) (interface{}, error) {
-	result := BalanceInfoResult{CoinId: coinID.Name()}
+	addr = address.NormalizeAddress(addr)
+	result := json.BalanceInfoResult{CoinId: coinID.Name()}
 	if coinID == types.MEERA {
 		bal, err := api.a.GetBalance(addr)
 		if err != nil {
@@ -64,5 +70,58 @@ func (api *PublicAccountManagerAPI) GetBalanceInfo(addr string, coinID types.Coi
 }
 
 func (api *PublicAccountManagerAPI) AddBalance(addr string) (interface{}, error) {
-	return nil, api.a.AddAddress(addr)
+	return nil, api.a.AddAddress(address.NormalizeAddress(addr))
+}
+
+// AddXpub watches the addresses derived from the extended public key, the
+// path template is relative to the key and its last component is the index
+// of the addresses.
+func (api *PublicAccountManagerAPI) AddXpub(name string, xpub string, path *string, gapLimit *uint32) (interface{}, error) {
+	p := DefaultXpubPath
+	if path != nil && len(*path) > 0 {
+		p = *path
+	}
+	gl := uint32(DefaultGapLimit)
+	if gapLimit != nil {
+		gl = *gapLimit
+	}
+	return nil, api.a.AddXpub(name, xpub, p, gl)
+}
+
+func (api *PublicAccountManagerAPI) GetXpubBalance(name string) (interface{}, error) {
+	return api.a.GetXpubBalance(name)
+}
+
+// GetAddressHistory returns the credits and debits of the watched address
+// from the order.
+func (api *PublicAccountManagerAPI) GetAddressHistory(addr string, fromOrder *uint32, count *uint32) (interface{}, error) {
+	addr = address.NormalizeAddress(addr)
+	from := uint32(0)
+	if fromOrder != nil {
+		from = *fromOrder
+	}
+	c := uint32(100)
+	if count != nil {
+		c = *count
+	}
+	return api.a.GetAddressHistory(addr, from, c)
+}
+
+func (api *PublicAccountManagerAPI) GetBalanceAt(addr string, order uint32) (interface{}, error) {
+	return api.a.GetBalanceAt(address.NormalizeAddress(addr), order)
+}
+
+// GetLockedBalanceSchedule returns the locked outputs of the address with
+// their unlock conditions and expected unlock times.
+func (api *PublicAccountManagerAPI) GetLockedBalanceSchedule(addr string, coinID types.CoinID) (interface{}, error) {
+	addr = address.NormalizeAddress(addr)
+	if coinID != types.MEERA {
+		return nil, fmt.Errorf("Not support %v", coinID)
+	}
+	result, err := api.a.GetLockedBalanceSchedule(addr)
+	if err != nil {
+		return nil, err
+	}
+	result.CoinId = coinID.Name()
+	return result, nil
 }
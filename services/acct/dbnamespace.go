@@ -20,4 +20,12 @@ var (
 	// InfoBucketName is the name of the db bucket used to house to
 	// account info
 	InfoBucketName = []byte("acctinfo")
+
+	// HistoryBucketName is the name of the db bucket used to house to
+	// the credits and debits of the addresses
+	HistoryBucketName = []byte("accthistory")
+
+	// HistoryBlockBucketName is the name of the db bucket used to house to
+	// Block -> the keys of its credits and debits
+	HistoryBlockBucketName = []byte("accthistoryblock")
 )
@@ -0,0 +1,408 @@
+package acct
+
+import (
+	"bytes"
+	"encoding/binary"
+	"fmt"
+	"github.com/Qitmeer/qng/common/hash"
+	"github.com/Qitmeer/qng/core/blockchain/utxo"
+	"github.com/Qitmeer/qng/core/json"
+	s "github.com/Qitmeer/qng/core/serialization"
+	"github.com/Qitmeer/qng/core/types"
+	"github.com/Qitmeer/qng/database"
+	"github.com/Qitmeer/qng/engine/txscript"
+	"github.com/Qitmeer/qng/params"
+	"io"
+)
+
+const (
+	HistoryCredit = 0
+	HistoryDebit  = 1
+
+	// addrEventValueSize is the size of the value of an event:
+	// coin id (2) + amount (8) + locked (1)
+	addrEventValueSize = 11
+)
+
+// The order of the events in their keys, so they are sorted by order.
+var eventKeyOrder = binary.BigEndian
+
+// AddrEvent is a credit or a debit of a watched address.  The credits are the
+// outputs received by the address and the debits are the inputs which spend
+// them, the index is the index of the output or of the input in the
+// transaction.
+type AddrEvent struct {
+	addr   string
+	order  uint32
+	txid   hash.Hash
+	typ    byte
+	index  uint32
+	amount types.Amount
+	locked bool
+}
+
+// Key returns the key of the event in the history bucket:
+//
+//	address | 0x00 | order (4) | txid (32) | type (1) | index (4)
+func (ae *AddrEvent) Key() []byte {
+	prefix := addrHistoryPrefix(ae.addr)
+	key := make([]byte, len(prefix)+4+hash.HashSize+1+4)
+	copy(key, prefix)
+	offset := len(prefix)
+	eventKeyOrder.PutUint32(key[offset:], ae.order)
+	offset += 4
+	copy(key[offset:], ae.txid[:])
+	offset += hash.HashSize
+	key[offset] = ae.typ
+	eventKeyOrder.PutUint32(key[offset+1:], ae.index)
+	return key
+}
+
+func (ae *AddrEvent) Value() []byte {
+	value := make([]byte, addrEventValueSize)
+	ByteOrder.PutUint16(value, uint16(ae.amount.Id))
+	ByteOrder.PutUint64(value[2:], uint64(ae.amount.Value))
+	if ae.locked {
+		value[10] = 1
+	}
+	return value
+}
+
+func (ae *AddrEvent) String() string {
+	typ := "credit"
+	if ae.typ == HistoryDebit {
+		typ = "debit"
+	}
+	return fmt.Sprintf("%s %s order=%d tx=%s:%d amount=%s", ae.addr, typ, ae.order, ae.txid, ae.index, ae.amount.String())
+}
+
+func decodeAddrEvent(addr string, key []byte, value []byte) (*AddrEvent, error) {
+	prefix := addrHistoryPrefix(addr)
+	if len(key) != len(prefix)+4+hash.HashSize+1+4 || len(value) != addrEventValueSize {
+		return nil, fmt.Errorf("Corrupt account history event:%x", key)
+	}
+	key = key[len(prefix):]
+	ae := &AddrEvent{
+		addr:  addr,
+		order: eventKeyOrder.Uint32(key),
+		typ:   key[4+hash.HashSize],
+		index: eventKeyOrder.Uint32(key[4+hash.HashSize+1:]),
+		amount: types.Amount{
+			Id:    types.CoinID(ByteOrder.Uint16(value)),
+			Value: int64(ByteOrder.Uint64(value[2:])),
+		},
+		locked: value[10] == 1,
+	}
+	copy(ae.txid[:], key[4:4+hash.HashSize])
+	return ae, nil
+}
+
+func addrHistoryPrefix(addr string) []byte {
+	return append([]byte(addr), 0x00)
+}
+
+// historyAddr returns the address which receives the output of the script
+// and whether the output is locked by the script.
+func historyAddr(pkScript []byte) (string, bool) {
+	scriptClass, addrs, _, err := txscript.ExtractPkScriptAddrs(pkScript, params.ActiveNetParams.Params)
+	if err != nil || len(addrs) <= 0 {
+		return "", false
+	}
+	return addrs[0].String(), scriptClass == txscript.CLTVPubKeyHashTy
+}
+
+// blockHistoryOp is a connected or disconnected block whose events are
+// applied to the history on Commit.
+type blockHistoryOp struct {
+	connect   bool
+	blockHash hash.Hash
+	events    []*AddrEvent
+}
+
+// ConnectBlock collects the credits and debits of the block, it must be
+// called before the view is committed since the outputs which are created
+// and spent by the block are pruned from it.
+func (a *AccountManager) ConnectBlock(block *types.SerializedBlock, order uint, view *utxo.UtxoViewpoint, stxos []utxo.SpentTxOut) error {
+	if !a.cfg.AcctMode || a.rebuildMarked || a.info.IsEmpty() {
+		return nil
+	}
+	op := &blockHistoryOp{connect: true, blockHash: *block.Hash(), events: []*AddrEvent{}}
+	for _, tx := range block.Transactions() {
+		if tx.IsDuplicate {
+			continue
+		}
+		for outIdx, txOut := range tx.Tx.TxOut {
+			entry := view.LookupEntry(types.TxOutPoint{Hash: *tx.Hash(), OutIndex: uint32(outIdx)})
+			if entry == nil || !entry.BlockHash().IsEqual(block.Hash()) {
+				continue
+			}
+			addr, locked := historyAddr(txOut.PkScript)
+			if len(addr) <= 0 {
+				continue
+			}
+			op.events = append(op.events, &AddrEvent{
+				addr:   addr,
+				order:  uint32(order),
+				txid:   *tx.Hash(),
+				typ:    HistoryCredit,
+				index:  uint32(outIdx),
+				amount: entry.Amount(),
+				locked: locked || entry.IsCoinBase(),
+			})
+		}
+	}
+	txs := block.Transactions()
+	for _, stxo := range stxos {
+		if int(stxo.TxIndex) >= len(txs) {
+			continue
+		}
+		addr, _ := historyAddr(stxo.PkScript)
+		if len(addr) <= 0 {
+			continue
+		}
+		op.events = append(op.events, &AddrEvent{
+			addr:   addr,
+			order:  uint32(order),
+			txid:   *txs[stxo.TxIndex].Hash(),
+			typ:    HistoryDebit,
+			index:  stxo.TxInIndex,
+			amount: stxo.Amount,
+		})
+	}
+	if len(op.events) > 0 {
+		a.historyops = append(a.historyops, op)
+	}
+	return nil
+}
+
+// DisconnectBlock reverts the events of the block on Commit.
+func (a *AccountManager) DisconnectBlock(block *types.SerializedBlock) error {
+	if !a.cfg.AcctMode || a.rebuildMarked || a.info.IsEmpty() {
+		return nil
+	}
+	a.historyops = append(a.historyops, &blockHistoryOp{connect: false, blockHash: *block.Hash()})
+	return nil
+}
+
+// commitHistory applies the events of the connected and disconnected blocks
+// in order, only the events of the watched addresses are kept.
+func (a *AccountManager) commitHistory() error {
+	if len(a.historyops) <= 0 {
+		return nil
+	}
+	return a.db.Update(func(dbTx database.Tx) error {
+		for _, op := range a.historyops {
+			if !op.connect {
+				err := DBDelACCTHistoryBlock(dbTx, &op.blockHash)
+				if err != nil {
+					return err
+				}
+				continue
+			}
+			for _, ae := range op.events {
+				if !a.info.Has(ae.addr) {
+					continue
+				}
+				err := DBPutACCTHistory(dbTx, &op.blockHash, ae)
+				if err != nil {
+					return err
+				}
+			}
+		}
+		return nil
+	})
+}
+
+// seedHistory adds the credit of an output which was received before its
+// address was watched, so the history and the balance timeline of the
+// address start with its unspent outputs.
+func (a *AccountManager) seedHistory(op *types.TxOutPoint, entry *utxo.UtxoEntry) error {
+	addr, locked := historyAddr(entry.PkScript())
+	if len(addr) <= 0 || !a.info.Has(addr) {
+		return nil
+	}
+	block := a.chain.BlockDAG().GetBlock(entry.BlockHash())
+	if block == nil {
+		return nil
+	}
+	ae := &AddrEvent{
+		addr:   addr,
+		order:  uint32(block.GetOrder()),
+		txid:   op.Hash,
+		typ:    HistoryCredit,
+		index:  op.OutIndex,
+		amount: entry.Amount(),
+		locked: locked || entry.IsCoinBase(),
+	}
+	return a.db.Update(func(dbTx database.Tx) error {
+		return DBPutACCTHistory(dbTx, entry.BlockHash(), ae)
+	})
+}
+
+// GetAddressHistory returns the events of the watched address from the order.
+func (a *AccountManager) GetAddressHistory(addr string, fromOrder uint32, count uint32) ([]json.AddrEventResult, error) {
+	err := a.checkHistoryAddr(addr)
+	if err != nil {
+		return nil, err
+	}
+	result := []json.AddrEventResult{}
+	err = a.db.View(func(dbTx database.Tx) error {
+		return dbForEachACCTHistory(dbTx, addr, fromOrder, func(ae *AddrEvent) (bool, error) {
+			if uint32(len(result)) >= count {
+				return false, nil
+			}
+			er := json.AddrEventResult{
+				Order:  ae.order,
+				TxId:   ae.txid.String(),
+				Type:   "credit",
+				Index:  ae.index,
+				CoinId: ae.amount.Id.Name(),
+				Amount: ae.amount.Value,
+				Locked: ae.locked,
+			}
+			if ae.typ == HistoryDebit {
+				er.Type = "debit"
+			}
+			result = append(result, er)
+			return true, nil
+		})
+	})
+	if err != nil {
+		return nil, err
+	}
+	return result, nil
+}
+
+// GetBalanceAt returns the balances of the watched address after the block
+// of the order, by coin.
+func (a *AccountManager) GetBalanceAt(addr string, order uint32) (*json.BalanceAtResult, error) {
+	err := a.checkHistoryAddr(addr)
+	if err != nil {
+		return nil, err
+	}
+	result := &json.BalanceAtResult{
+		Address:  addr,
+		Order:    order,
+		Balances: map[string]int64{},
+	}
+	err = a.db.View(func(dbTx database.Tx) error {
+		return dbForEachACCTHistory(dbTx, addr, 0, func(ae *AddrEvent) (bool, error) {
+			if ae.order > order {
+				return false, nil
+			}
+			if ae.typ == HistoryDebit {
+				result.Balances[ae.amount.Id.Name()] -= ae.amount.Value
+			} else {
+				result.Balances[ae.amount.Id.Name()] += ae.amount.Value
+			}
+			return true, nil
+		})
+	})
+	if err != nil {
+		return nil, err
+	}
+	return result, nil
+}
+
+func (a *AccountManager) checkHistoryAddr(addr string) error {
+	if !a.cfg.AcctMode {
+		return fmt.Errorf("Please enable --acctmode")
+	}
+	if !a.info.Has(addr) {
+		return fmt.Errorf("Not watched:%s (RPC:addBalance)", addr)
+	}
+	return nil
+}
+
+// history
+func DBPutACCTHistory(dbTx database.Tx, blockHash *hash.Hash, ae *AddrEvent) error {
+	meta := dbTx.Metadata()
+	bucket, err := meta.CreateBucketIfNotExists(HistoryBucketName)
+	if err != nil {
+		return err
+	}
+	key := ae.Key()
+	if bucket.Get(key) != nil {
+		return nil
+	}
+	err = bucket.Put(key, ae.Value())
+	if err != nil {
+		return err
+	}
+	log.Trace(fmt.Sprintf("Add history: %s", ae.String()))
+
+	// The keys of the events of the block, to revert them when it is
+	// disconnected.
+	blockBucket, err := meta.CreateBucketIfNotExists(HistoryBlockBucketName)
+	if err != nil {
+		return err
+	}
+	var buff bytes.Buffer
+	buff.Write(blockBucket.Get(blockHash[:]))
+	err = s.WriteElements(&buff, uint32(len(key)))
+	if err != nil {
+		return err
+	}
+	buff.Write(key)
+	return blockBucket.Put(blockHash[:], buff.Bytes())
+}
+
+func DBDelACCTHistoryBlock(dbTx database.Tx, blockHash *hash.Hash) error {
+	meta := dbTx.Metadata()
+	blockBucket := meta.Bucket(HistoryBlockBucketName)
+	if blockBucket == nil {
+		return nil
+	}
+	keys := blockBucket.Get(blockHash[:])
+	if keys == nil {
+		return nil
+	}
+	bucket := meta.Bucket(HistoryBucketName)
+	r := bytes.NewReader(keys)
+	for r.Len() > 0 {
+		keyLen := uint32(0)
+		err := s.ReadElements(r, &keyLen)
+		if err != nil {
+			return err
+		}
+		key := make([]byte, keyLen)
+		_, err = io.ReadFull(r, key)
+		if err != nil {
+			return err
+		}
+		if bucket != nil {
+			err = bucket.Delete(key)
+			if err != nil {
+				return err
+			}
+		}
+	}
+	log.Trace(fmt.Sprintf("Del history of block: %s", blockHash))
+	return blockBucket.Delete(blockHash[:])
+}
+
+func dbForEachACCTHistory(dbTx database.Tx, addr string, fromOrder uint32, fn func(ae *AddrEvent) (bool, error)) error {
+	bucket := dbTx.Metadata().Bucket(HistoryBucketName)
+	if bucket == nil {
+		return nil
+	}
+	prefix := addrHistoryPrefix(addr)
+	seek := make([]byte, len(prefix)+4)
+	copy(seek, prefix)
+	eventKeyOrder.PutUint32(seek[len(prefix):], fromOrder)
+	cursor := bucket.Cursor()
+	for ok := cursor.Seek(seek); ok && bytes.HasPrefix(cursor.Key(), prefix); ok = cursor.Next() {
+		ae, err := decodeAddrEvent(addr, cursor.Key(), cursor.Value())
+		if err != nil {
+			return err
+		}
+		next, err := fn(ae)
+		if err != nil {
+			return err
+		}
+		if !next {
+			return nil
+		}
+	}
+	return nil
+}
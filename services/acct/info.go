@@ -7,7 +7,12 @@ import (
 )
 
 const (
-	CurrentAcctInfoVersion = 2
+	CurrentAcctInfoVersion = 5
+
+	// The version before the token outputs were tracked.  Its outputs and
+	// balances of MEER are the same, so it is migrated by adding the token
+	// outputs instead of being rebuilt.
+	preTokenAcctInfoVersion = 4
 )
 
 type AcctInfo struct {
@@ -15,6 +20,15 @@ type AcctInfo struct {
 	updateDAGID uint32
 	total       uint32
 	addrs       []string
+	xpubs       []*XpubAcct
+
+	// The xpub account and index of the derived addresses.
+	derived map[string]derivedAddr
+}
+
+type derivedAddr struct {
+	xa    *XpubAcct
+	index uint32
 }
 
 func (ai *AcctInfo) Encode(w io.Writer) error {
@@ -47,6 +61,16 @@ func (ai *AcctInfo) Encode(w io.Writer) error {
 			}
 		}
 	}
+	err = s.WriteElements(w, uint32(len(ai.xpubs)))
+	if err != nil {
+		return err
+	}
+	for _, xa := range ai.xpubs {
+		err = xa.Encode(w)
+		if err != nil {
+			return err
+		}
+	}
 	return nil
 }
 
@@ -84,11 +108,31 @@ func (ai *AcctInfo) Decode(r io.Reader) error {
 			ai.addrs = append(ai.addrs, string(addrby))
 		}
 	}
+	// The xpub accounts since version 3
+	if ai.version < 3 {
+		return nil
+	}
+	xpubTotal := uint32(0)
+	err = s.ReadElements(r, &xpubTotal)
+	if err != nil {
+		return err
+	}
+	for i := 0; i < int(xpubTotal); i++ {
+		xa := &XpubAcct{}
+		err = xa.Decode(r)
+		if err != nil {
+			return err
+		}
+		_, err = ai.AddXpub(xa)
+		if err != nil {
+			return err
+		}
+	}
 	return nil
 }
 
 func (ai *AcctInfo) String() string {
-	return fmt.Sprintf("version=%d dagid=%d addrtotal=%d/%d", ai.version, ai.updateDAGID, ai.total, ai.GetAddrTotal())
+	return fmt.Sprintf("version=%d dagid=%d addrtotal=%d/%d xpubs=%d", ai.version, ai.updateDAGID, ai.total, ai.GetAddrTotal(), len(ai.xpubs))
 }
 
 func (ai *AcctInfo) IsCurrentVersion() bool {
@@ -100,10 +144,13 @@ func (ai *AcctInfo) GetAddrTotal() int {
 }
 
 func (ai *AcctInfo) IsEmpty() bool {
-	return ai.GetAddrTotal() <= 0
+	return ai.GetAddrTotal() <= 0 && len(ai.xpubs) <= 0
 }
 
 func (ai *AcctInfo) Has(addr string) bool {
+	if _, ok := ai.derived[addr]; ok {
+		return true
+	}
 	if ai.GetAddrTotal() <= 0 {
 		return false
 	}
@@ -119,11 +166,80 @@ func (ai *AcctInfo) Add(addr string) {
 	ai.addrs = append(ai.addrs, addr)
 }
 
+// AddXpub adds the xpub account and returns its addresses up to the gap
+// limit.
+func (ai *AcctInfo) AddXpub(xa *XpubAcct) ([]string, error) {
+	if ai.GetXpub(xa.name) != nil {
+		return nil, fmt.Errorf("Already exists:%s", xa.name)
+	}
+	addrs, err := xa.extend()
+	if err != nil {
+		return nil, err
+	}
+	for i, addr := range addrs {
+		if ai.Has(addr) {
+			return nil, fmt.Errorf("Already exists:%s (%s)", addr, xa.IndexPath(uint32(i)))
+		}
+	}
+	for i, addr := range addrs {
+		ai.derived[addr] = derivedAddr{xa: xa, index: uint32(i)}
+	}
+	ai.xpubs = append(ai.xpubs, xa)
+	return addrs, nil
+}
+
+func (ai *AcctInfo) GetXpub(name string) *XpubAcct {
+	for _, xa := range ai.xpubs {
+		if xa.name == name {
+			return xa
+		}
+	}
+	return nil
+}
+
+func (ai *AcctInfo) extendXpub(xa *XpubAcct) ([]string, error) {
+	start := uint32(len(xa.addrs))
+	added, err := xa.extend()
+	if err != nil {
+		return nil, err
+	}
+	for i, addr := range added {
+		ai.derived[addr] = derivedAddr{xa: xa, index: start + uint32(i)}
+	}
+	return added, nil
+}
+
+// extendXpubs derives the addresses of the xpub accounts up to their gap
+// limits and returns the new addresses.
+func (ai *AcctInfo) extendXpubs() ([]string, error) {
+	result := []string{}
+	for _, xa := range ai.xpubs {
+		added, err := ai.extendXpub(xa)
+		if err != nil {
+			return nil, err
+		}
+		result = append(result, added...)
+	}
+	return result, nil
+}
+
+// markUsed marks the address as used if it is derived from a xpub account,
+// it returns whether the gap limit of the account moved.
+func (ai *AcctInfo) markUsed(addr string) bool {
+	da, ok := ai.derived[addr]
+	if !ok {
+		return false
+	}
+	return da.xa.markUsed(da.index)
+}
+
 func NewAcctInfo() *AcctInfo {
 	ai := AcctInfo{
 		version: CurrentAcctInfoVersion,
 		addrs:   []string{},
 		total:   0,
+		xpubs:   []*XpubAcct{},
+		derived: map[string]derivedAddr{},
 	}
 
 	return &ai
@@ -0,0 +1,236 @@
+package acct
+
+import (
+	"encoding/hex"
+	"fmt"
+	"github.com/Qitmeer/qng/consensus/forks"
+	"github.com/Qitmeer/qng/core/address"
+	"github.com/Qitmeer/qng/core/json"
+	"github.com/Qitmeer/qng/core/protocol"
+	"github.com/Qitmeer/qng/database"
+	"github.com/Qitmeer/qng/engine/txscript"
+	"github.com/Qitmeer/qng/params"
+	"github.com/Qitmeer/qng/rpc/client/cmds"
+	"sort"
+	"time"
+)
+
+const (
+	// scheduleIntervalBlocks is the number of the last main chain blocks
+	// whose average interval estimates the unlock times.
+	scheduleIntervalBlocks = 100
+
+	LockConditionMaturity = "maturity"
+	LockConditionHeight   = "height"
+	LockConditionTime     = "time"
+)
+
+// mainBlockInterval returns the average interval of the last main chain
+// blocks, or the target time per block when it is unknown.
+func (a *AccountManager) mainBlockInterval() time.Duration {
+	bd := a.chain.BlockDAG()
+	target := params.ActiveNetParams.TargetTimePerBlock
+	tip := bd.GetMainChainTip()
+	if tip == nil {
+		return target
+	}
+	ib := tip
+	for i := 0; i < scheduleIntervalBlocks; i++ {
+		parent := bd.GetBlockById(ib.GetMainParent())
+		if parent == nil {
+			break
+		}
+		ib = parent
+	}
+	if tip.GetHeight() <= ib.GetHeight() {
+		return target
+	}
+	dt := bd.GetBlockData(tip).GetTimestamp() - bd.GetBlockData(ib).GetTimestamp()
+	if dt <= 0 {
+		return target
+	}
+	return time.Duration(dt) * time.Second / time.Duration(tip.GetHeight()-ib.GetHeight())
+}
+
+// scheduleTip is the state of the main chain which the unlock times of the
+// locked outputs are estimated from.
+type scheduleTip struct {
+	now      int64
+	height   int64
+	layer    int64
+	time     int64
+	interval time.Duration
+}
+
+// expected returns the expected time after the main chain blocks.
+func (st *scheduleTip) expected(blocks int64) int64 {
+	if blocks <= 0 {
+		return st.now
+	}
+	return st.now + int64(time.Duration(blocks)*st.interval/time.Second)
+}
+
+// schedule sets the unlock condition of the locked output of the watcher, it
+// returns false when the output is not watched.
+func (st *scheduleTip) schedule(lu *json.LockedUTXOResult, w AcctUTXOIWatcher) bool {
+	switch uw := w.(type) {
+	case *CoinbaseWatcher:
+		// The coinbase is mature once its block is blue and the main
+		// chain is the coinbase maturity layers above it.
+		unlockLayer := int64(uw.target.GetLayer()) + int64(params.ActiveNetParams.CoinbaseMaturity)
+		lu.Condition = LockConditionMaturity
+		lu.UnlockLayer = uint64(unlockLayer)
+		lu.Remaining = unlockLayer - st.layer
+		lu.UnlockTime = st.expected(lu.Remaining)
+	case *CLTVWatcher:
+		unlockHeight := int64(-1)
+		if uw.lockTime < txscript.LockTimeThreshold {
+			unlockHeight = uw.lockTime
+		}
+		// The locked outputs of the genesis are unlocked by the
+		// MeerEVM fork on the main net.
+		if uw.isForkGenUTXO && params.ActiveNetParams.Net == protocol.MainNet &&
+			(unlockHeight < 0 || unlockHeight > forks.MeerEVMUTXOUnlockMainHeight) {
+			unlockHeight = forks.MeerEVMUTXOUnlockMainHeight
+		}
+		lu.LockTime = uw.lockTime
+		if unlockHeight >= 0 {
+			lu.Condition = LockConditionHeight
+			lu.UnlockHeight = uint64(unlockHeight)
+			lu.Remaining = unlockHeight - st.height
+			lu.UnlockTime = st.expected(lu.Remaining)
+		} else {
+			// The time lock is verified against the time of the main
+			// tip, so it unlocks with the first main block after it.
+			lu.Condition = LockConditionTime
+			lu.UnlockTime = uw.lockTime
+			if st.time < uw.lockTime {
+				secs := int64(st.interval / time.Second)
+				if secs <= 0 {
+					secs = 1
+				}
+				lu.Remaining = (uw.lockTime - st.time + secs - 1) / secs
+			}
+		}
+	default:
+		// The output is not watched, its block is unknown or invalid.
+		return false
+	}
+	return true
+}
+
+// GetLockedBalanceSchedule returns the locked outputs of the address with
+// their unlock conditions, sorted by their expected unlock times.  The unlock
+// times of the conditions on the main chain are estimated by the average
+// interval of the last main chain blocks.
+func (a *AccountManager) GetLockedBalanceSchedule(addr string) (*json.LockedBalanceScheduleResult, error) {
+	if !a.cfg.AcctMode {
+		return nil, fmt.Errorf("Please enable --acctmode")
+	}
+	if !address.IsForCurNetwork(addr) {
+		return nil, fmt.Errorf("network error:%s", addr)
+	}
+	bd := a.chain.BlockDAG()
+	mainTip := bd.GetMainChainTip()
+	if mainTip == nil {
+		return nil, fmt.Errorf("No main tip")
+	}
+	st := &scheduleTip{
+		now:      time.Now().Unix(),
+		height:   int64(mainTip.GetHeight()),
+		layer:    int64(mainTip.GetLayer()),
+		time:     bd.GetBlockData(mainTip).GetTimestamp(),
+		interval: a.mainBlockInterval(),
+	}
+
+	result := &json.LockedBalanceScheduleResult{
+		Address:       addr,
+		MainHeight:    uint64(st.height),
+		MainLayer:     uint64(st.layer),
+		BlockInterval: st.interval.Seconds(),
+		UTXOs:         []json.LockedUTXOResult{},
+	}
+	var us map[string]*AcctUTXO
+	err := a.db.View(func(dbTx database.Tx) error {
+		us = DBGetACCTUTXOs(dbTx, addr)
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	wb := a.watchers[addr]
+	for k, au := range us {
+		if !au.IsCoinbase() && !au.IsCLTV() {
+			continue
+		}
+		opk, err := hex.DecodeString(k)
+		if err != nil {
+			return nil, err
+		}
+		op, err := parseOutpoint(opk)
+		if err != nil {
+			return nil, err
+		}
+		lu := json.LockedUTXOResult{
+			TxId:   op.Hash.String(),
+			Index:  op.OutIndex,
+			Type:   au.TypeStr(),
+			Amount: au.balance,
+		}
+		var w AcctUTXOIWatcher
+		if wb != nil {
+			w = wb.GetByOPS(k)
+		}
+		if w != nil && w.IsUnlocked() {
+			continue
+		}
+		if !st.schedule(&lu, w) {
+			continue
+		}
+		result.Locked += lu.Amount
+		result.UTXOs = append(result.UTXOs, lu)
+	}
+	sort.Slice(result.UTXOs, func(i, j int) bool {
+		if result.UTXOs[i].UnlockTime == result.UTXOs[j].UnlockTime {
+			return result.UTXOs[i].TxId < result.UTXOs[j].TxId
+		}
+		return result.UTXOs[i].UnlockTime < result.UTXOs[j].UnlockTime
+	})
+	return result, nil
+}
+
+// notifyMatured notifies the locked outputs of the watchers which matured
+// since their last update.
+func (a *AccountManager) notifyMatured() {
+	mainTip := a.chain.BlockDAG().GetMainChainTip()
+	for _, wb := range a.watchers {
+		matured := wb.matured
+		wb.matured = nil
+		if a.LockMaturedNotify == nil || mainTip == nil {
+			continue
+		}
+		for _, k := range matured {
+			w := wb.GetByOPS(k)
+			if w == nil {
+				continue
+			}
+			opk, err := hex.DecodeString(k)
+			if err != nil {
+				continue
+			}
+			op, err := parseOutpoint(opk)
+			if err != nil {
+				continue
+			}
+			a.LockMaturedNotify(&cmds.SubscribedLockMatured{
+				Address: wb.address,
+				TxID:    op.Hash.String(),
+				Index:   op.OutIndex,
+				Type:    w.GetName(),
+				Amount:  w.GetBalance(),
+				Order:   uint64(mainTip.GetOrder()),
+				Height:  uint64(mainTip.GetHeight()),
+			})
+		}
+	}
+}
@@ -0,0 +1,120 @@
+package acct
+
+import (
+	"testing"
+	"time"
+
+	"github.com/Qitmeer/qng/consensus/forks"
+	"github.com/Qitmeer/qng/core/json"
+	"github.com/Qitmeer/qng/meerdag"
+	"github.com/Qitmeer/qng/params"
+)
+
+// testLayerBlock is the block of a coinbase, only its layer is known.
+type testLayerBlock struct {
+	meerdag.IBlock
+	layer uint
+}
+
+func (b *testLayerBlock) GetLayer() uint {
+	return b.layer
+}
+
+// testLockWatcher is a watcher which unlocks on its next update.
+type testLockWatcher struct {
+	unlocked bool
+	unlock   bool
+}
+
+func (w *testLockWatcher) Update(am *AccountManager) error {
+	if w.unlock {
+		w.unlocked = true
+	}
+	return nil
+}
+
+func (w *testLockWatcher) GetBalance() uint64 {
+	if w.unlocked {
+		return 10
+	}
+	return 0
+}
+
+func (w *testLockWatcher) IsUnlocked() bool {
+	return w.unlocked
+}
+
+func (w *testLockWatcher) GetName() string {
+	return "test"
+}
+
+func TestLockedUTXOSchedule(t *testing.T) {
+	st := &scheduleTip{
+		now:      1000,
+		height:   100,
+		layer:    50,
+		time:     900,
+		interval: 30 * time.Second,
+	}
+	maturity := int64(params.ActiveNetParams.CoinbaseMaturity)
+
+	tests := []struct {
+		name      string
+		w         AcctUTXOIWatcher
+		condition string
+		remaining int64
+		unlockAt  int64
+	}{
+		{"coinbase", &CoinbaseWatcher{target: &testLayerBlock{layer: 40}},
+			LockConditionMaturity, maturity - 10, 1000 + (maturity-10)*30},
+		{"height", &CLTVWatcher{lockTime: 110}, LockConditionHeight, 10, 1300},
+		{"past height", &CLTVWatcher{lockTime: 90}, LockConditionHeight, -10, 1000},
+		// The time lock unlocks with the first main block after it.
+		{"time", &CLTVWatcher{lockTime: 600000000}, LockConditionTime,
+			(600000000 - 900 + 29) / 30, 600000000},
+		{"genesis", &CLTVWatcher{lockTime: 600000000, isForkGenUTXO: true}, LockConditionHeight,
+			forks.MeerEVMUTXOUnlockMainHeight - 100, 1000 + (forks.MeerEVMUTXOUnlockMainHeight-100)*30},
+	}
+	for _, test := range tests {
+		lu := &json.LockedUTXOResult{}
+		if !st.schedule(lu, test.w) {
+			t.Errorf("%s: the output isn't scheduled", test.name)
+			continue
+		}
+		if lu.Condition != test.condition || lu.Remaining != test.remaining || lu.UnlockTime != test.unlockAt {
+			t.Errorf("%s: got the condition %s remaining %d at %d, want %s remaining %d at %d", test.name,
+				lu.Condition, lu.Remaining, lu.UnlockTime, test.condition, test.remaining, test.unlockAt)
+		}
+	}
+
+	// The outputs which aren't watched aren't scheduled.
+	if st.schedule(&json.LockedUTXOResult{}, nil) || st.schedule(&json.LockedUTXOResult{}, &testLockWatcher{}) {
+		t.Fatalf("an output which isn't watched is scheduled")
+	}
+}
+
+func TestBalanceWatcherMatured(t *testing.T) {
+	aw := NewAcctBalanceWatcher("addr", &AcctBalance{})
+	aw.watchers["locked"] = &testLockWatcher{}
+	aw.watchers["unlocking"] = &testLockWatcher{unlock: true}
+	aw.watchers["unlocked"] = &testLockWatcher{unlocked: true}
+
+	if err := aw.Update(nil); err != nil {
+		t.Fatal(err)
+	}
+	if len(aw.matured) != 1 || aw.matured[0] != "unlocking" {
+		t.Fatalf("got the matured outputs %v, want the one unlocked by the update", aw.matured)
+	}
+	if aw.GetBalance() != 20 || aw.unlocUTXONum != 2 {
+		t.Fatalf("got the balance %d of %d outputs", aw.GetBalance(), aw.unlocUTXONum)
+	}
+
+	// An output is matured once.
+	aw.matured = nil
+	if err := aw.Update(nil); err != nil {
+		t.Fatal(err)
+	}
+	if len(aw.matured) != 0 {
+		t.Fatalf("got the matured outputs %v again", aw.matured)
+	}
+}
@@ -0,0 +1,99 @@
+package acct
+
+import (
+	"encoding/hex"
+	"fmt"
+	"github.com/Qitmeer/qng/core/address"
+	"github.com/Qitmeer/qng/core/blockchain/utxo"
+	"github.com/Qitmeer/qng/core/types"
+	"github.com/Qitmeer/qng/database"
+)
+
+// UnspentUTXO is a spendable output of a watched address.
+type UnspentUTXO struct {
+	OutPoint types.TxOutPoint
+	Amount   types.Amount
+	Type     string
+}
+
+// applyToken adds or deletes the token output of the address, the token
+// outputs are not counted in the balance of the address.
+func (a *AccountManager) applyToken(add bool, addrStr string, op *types.TxOutPoint, entry *utxo.UtxoEntry) error {
+	if add {
+		if entry.Amount().Value <= 0 {
+			return nil
+		}
+		a.info.markUsed(addrStr)
+		au := NewAcctUTXO()
+		au.SetToken(entry.Amount().Id)
+		au.SetBalance(uint64(entry.Amount().Value))
+		log.Trace(fmt.Sprintf("Add token: %s (%s)", addrStr, au.String()))
+		return a.db.Update(func(tx database.Tx) error {
+			return DBPutACCTUTXO(tx, addrStr, op, au)
+		})
+	}
+	log.Trace(fmt.Sprintf("Del token: %s (%s:%d)", addrStr, op.Hash.String(), op.OutIndex))
+	return a.db.Update(func(tx database.Tx) error {
+		return DBDelACCTUTXO(tx, addrStr, op)
+	})
+}
+
+// HasAddress returns whether the address is watched by the account manager.
+func (a *AccountManager) HasAddress(addr string) bool {
+	addr = address.NormalizeAddress(addr)
+	if !a.cfg.AcctMode {
+		return false
+	}
+	return a.info.Has(addr)
+}
+
+// ListUnspent returns the spendable MEER and token outputs of the watched
+// address.  The immature coinbase outputs and the CLTV outputs, which need
+// the lock time of the spending transaction, are not returned.
+func (a *AccountManager) ListUnspent(addr string) ([]*UnspentUTXO, error) {
+	addr = address.NormalizeAddress(addr)
+	if !a.cfg.AcctMode {
+		return nil, fmt.Errorf("Please enable --acctmode")
+	}
+	if !address.IsForCurNetwork(addr) {
+		return nil, fmt.Errorf("network error:%s", addr)
+	}
+	var us map[string]*AcctUTXO
+	err := a.db.View(func(dbTx database.Tx) error {
+		us = DBGetACCTUTXOs(dbTx, addr)
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	wb := a.watchers[addr]
+	result := []*UnspentUTXO{}
+	for k, au := range us {
+		if au.IsCLTV() {
+			continue
+		}
+		if au.IsCoinbase() {
+			if wb == nil {
+				continue
+			}
+			w := wb.GetByOPS(k)
+			if w == nil || !w.IsUnlocked() {
+				continue
+			}
+		}
+		opk, err := hex.DecodeString(k)
+		if err != nil {
+			return nil, err
+		}
+		op, err := parseOutpoint(opk)
+		if err != nil {
+			return nil, err
+		}
+		result = append(result, &UnspentUTXO{
+			OutPoint: *op,
+			Amount:   types.Amount{Id: au.Coin(), Value: int64(au.balance)},
+			Type:     au.TypeStr(),
+		})
+	}
+	return result, nil
+}
@@ -3,6 +3,7 @@ package acct
 import (
 	"fmt"
 	s "github.com/Qitmeer/qng/core/serialization"
+	"github.com/Qitmeer/qng/core/types"
 	"io"
 )
 
@@ -18,6 +19,8 @@ const (
 type AcctUTXO struct {
 	typ     byte
 	balance uint64
+	// coin is only encoded for the token outputs, the others are MEER.
+	coin types.CoinID
 }
 
 func (au *AcctUTXO) Encode(w io.Writer) error {
@@ -29,6 +32,9 @@ func (au *AcctUTXO) Encode(w io.Writer) error {
 	if err != nil {
 		return err
 	}
+	if au.IsToken() {
+		return s.WriteElements(w, uint16(au.coin))
+	}
 	return nil
 }
 
@@ -42,10 +48,21 @@ func (au *AcctUTXO) Decode(r io.Reader) error {
 	if err != nil {
 		return err
 	}
+	if au.IsToken() {
+		coin := uint16(0)
+		err = s.ReadElements(r, &coin)
+		if err != nil {
+			return err
+		}
+		au.coin = types.CoinID(coin)
+	}
 	return nil
 }
 
 func (au *AcctUTXO) String() string {
+	if au.IsToken() {
+		return fmt.Sprintf("type=%s coin=%s balance=%d", au.TypeStr(), au.coin.Name(), au.balance)
+	}
 	return fmt.Sprintf("type=%s balance=%d", au.TypeStr(), au.balance)
 }
 
@@ -79,13 +96,34 @@ func (au *AcctUTXO) IsCLTV() bool {
 	return au.typ == CLTVUTXOType
 }
 
+func (au *AcctUTXO) SetToken(coin types.CoinID) {
+	au.typ = TokenUTXOType
+	au.coin = coin
+}
+
+func (au *AcctUTXO) IsToken() bool {
+	return au.typ == TokenUTXOType
+}
+
+func (au *AcctUTXO) Coin() types.CoinID {
+	if au.IsToken() {
+		return au.coin
+	}
+	return types.MEERA
+}
+
+func (au *AcctUTXO) Balance() uint64 {
+	return au.balance
+}
+
 func (au *AcctUTXO) SetBalance(balance uint64) {
 	au.balance = balance
 }
 
 func NewAcctUTXO() *AcctUTXO {
 	au := AcctUTXO{
-		typ: NormalUTXOType,
+		typ:  NormalUTXOType,
+		coin: types.MEERA,
 	}
 
 	return &au
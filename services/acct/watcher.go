@@ -17,6 +17,9 @@ type AcctBalanceWatcher struct {
 	unlocUTXONum uint32
 
 	watchers map[string]AcctUTXOIWatcher
+
+	// The keys of the watchers which are unlocked by the last update.
+	matured []string
 }
 
 func (aw *AcctBalanceWatcher) Add(op []byte, au AcctUTXOIWatcher) {
@@ -56,11 +59,15 @@ func (aw *AcctBalanceWatcher) GetBalance() uint64 {
 func (aw *AcctBalanceWatcher) Update(am *AccountManager) error {
 	aw.unlocked = 0
 	aw.unlocUTXONum = 0
-	for _, w := range aw.watchers {
+	for k, w := range aw.watchers {
+		wasUnlocked := w.IsUnlocked()
 		err := w.Update(am)
 		if err != nil {
 			return err
 		}
+		if !wasUnlocked && w.IsUnlocked() {
+			aw.matured = append(aw.matured, k)
+		}
 		aw.unlocked += w.GetBalance()
 		if w.IsUnlocked() {
 			aw.unlocUTXONum++
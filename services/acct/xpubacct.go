@@ -0,0 +1,215 @@
+package acct
+
+import (
+	"fmt"
+	"github.com/Qitmeer/qng/common/hash"
+	"github.com/Qitmeer/qng/core/address"
+	s "github.com/Qitmeer/qng/core/serialization"
+	"github.com/Qitmeer/qng/crypto/bip32"
+	"github.com/Qitmeer/qng/crypto/ecc"
+	"github.com/Qitmeer/qng/params"
+	"github.com/Qitmeer/qng/wallet"
+	"io"
+	"strings"
+)
+
+const (
+	// DefaultXpubPath is the derivation path template of the xpub accounts
+	// (the external chain of BIP44 account keys).
+	DefaultXpubPath = "0/*"
+
+	// DefaultGapLimit is the number of unused addresses which are watched
+	// after the last used address of a xpub account.
+	DefaultGapLimit = 20
+
+	// MaxGapLimit is the largest gap limit of a xpub account.
+	MaxGapLimit = 1000
+)
+
+// XpubAcct is an account of the addresses derived from an extended public
+// key.  Only the addresses up to the gap limit after the last used one are
+// derived and watched, an address is used once it received an output.
+type XpubAcct struct {
+	name     string
+	xpub     string
+	path     string
+	gapLimit uint32
+	// used is the number of addresses up to the last used one.
+	used uint32
+
+	// The derived addresses, the address of index i is addrs[i].
+	key   *bip32.Key
+	addrs []string
+}
+
+func (xa *XpubAcct) Encode(w io.Writer) error {
+	for _, str := range []string{xa.name, xa.xpub, xa.path} {
+		err := s.WriteElements(w, uint32(len(str)))
+		if err != nil {
+			return err
+		}
+		_, err = w.Write([]byte(str))
+		if err != nil {
+			return err
+		}
+	}
+	err := s.WriteElements(w, xa.gapLimit)
+	if err != nil {
+		return err
+	}
+	return s.WriteElements(w, xa.used)
+}
+
+func (xa *XpubAcct) Decode(r io.Reader) error {
+	strs := make([]string, 3)
+	for i := range strs {
+		strLen := uint32(0)
+		err := s.ReadElements(r, &strLen)
+		if err != nil {
+			return err
+		}
+		str := make([]byte, strLen)
+		_, err = io.ReadFull(r, str)
+		if err != nil {
+			return err
+		}
+		strs[i] = string(str)
+	}
+	xa.name, xa.xpub, xa.path = strs[0], strs[1], strs[2]
+	err := s.ReadElements(r, &xa.gapLimit)
+	if err != nil {
+		return err
+	}
+	err = s.ReadElements(r, &xa.used)
+	if err != nil {
+		return err
+	}
+	return xa.init()
+}
+
+func (xa *XpubAcct) String() string {
+	return fmt.Sprintf("name=%s path=%s gaplimit=%d used=%d derived=%d",
+		xa.name, xa.path, xa.gapLimit, xa.used, len(xa.addrs))
+}
+
+// init derives the key of the path template, the addresses are derived from
+// it by their index.
+func (xa *XpubAcct) init() error {
+	key, err := parseXpub(xa.xpub)
+	if err != nil {
+		return err
+	}
+	prefix, err := parseXpubPath(xa.path)
+	if err != nil {
+		return err
+	}
+	for _, idx := range prefix {
+		key, err = key.NewChildKey(idx)
+		if err != nil {
+			return err
+		}
+	}
+	xa.key = key
+	xa.addrs = []string{}
+	return nil
+}
+
+// extend derives the addresses up to the gap limit after the last used one
+// and returns the new addresses.
+func (xa *XpubAcct) extend() ([]string, error) {
+	added := []string{}
+	for uint32(len(xa.addrs)) < xa.used+xa.gapLimit {
+		child, err := xa.key.NewChildKey(uint32(len(xa.addrs)))
+		if err != nil {
+			return nil, err
+		}
+		addr, err := address.NewPubKeyHashAddress(hash.Hash160(child.Key), params.ActiveNetParams.Params, ecc.ECDSA_Secp256k1)
+		if err != nil {
+			return nil, err
+		}
+		xa.addrs = append(xa.addrs, addr.String())
+		added = append(added, addr.String())
+	}
+	return added, nil
+}
+
+// markUsed marks the address of the index as used, it returns whether the
+// gap limit moved.
+func (xa *XpubAcct) markUsed(index uint32) bool {
+	if index < xa.used {
+		return false
+	}
+	xa.used = index + 1
+	return true
+}
+
+// IndexPath returns the derivation path of the address of the index
+// relative to the xpub.
+func (xa *XpubAcct) IndexPath(index uint32) string {
+	return strings.Replace(xa.path, "*", fmt.Sprintf("%d", index), 1)
+}
+
+func NewXpubAcct(name string, xpub string, path string, gapLimit uint32) (*XpubAcct, error) {
+	if len(name) <= 0 {
+		return nil, fmt.Errorf("The account name is empty")
+	}
+	if gapLimit == 0 || gapLimit > MaxGapLimit {
+		return nil, fmt.Errorf("The gap limit must be between 1 and %d", MaxGapLimit)
+	}
+	xa := &XpubAcct{
+		name:     name,
+		xpub:     xpub,
+		path:     path,
+		gapLimit: gapLimit,
+	}
+	err := xa.init()
+	if err != nil {
+		return nil, err
+	}
+	return xa, nil
+}
+
+// parseXpub decodes an extended public key of the current network, the
+// standard (xpub) version is also accepted.
+func parseXpub(xpub string) (*bip32.Key, error) {
+	netVersion := bip32.Bip32Version{
+		PrivKeyVersion: params.ActiveNetParams.HDPrivateKeyID[:],
+		PubKeyVersion:  params.ActiveNetParams.HDPublicKeyID[:],
+	}
+	key, err := bip32.B58Deserialize(xpub, netVersion)
+	if err != nil {
+		key, err = bip32.B58Deserialize(xpub, bip32.DefaultBip32Version)
+		if err != nil {
+			return nil, fmt.Errorf("Invalid extended public key: %v", err)
+		}
+	}
+	if key.IsPrivate {
+		return nil, fmt.Errorf("Only the extended public keys can be watched")
+	}
+	return key, nil
+}
+
+// parseXpubPath returns the components of the path template before its
+// index.  The template is relative to the xpub, like "0/*", its last
+// component must be the "*" of the address index and it can not have
+// hardened components.
+func parseXpubPath(path string) (wallet.DerivationPath, error) {
+	path = strings.TrimPrefix(strings.TrimSpace(path), "m/")
+	if path != "*" && !strings.HasSuffix(path, "/*") {
+		return nil, fmt.Errorf("Invalid path template (%s), the last component must be *", path)
+	}
+	prefix := strings.TrimSuffix(strings.TrimSuffix(path, "*"), "/")
+	if len(prefix) <= 0 {
+		return wallet.DerivationPath{}, nil
+	}
+	result, err := wallet.ParseDerivationPath("m/" + prefix)
+	if err != nil {
+		return nil, fmt.Errorf("Invalid path template (%s): %v", path, err)
+	}
+	for _, idx := range result {
+		if idx >= bip32.FirstHardenedChild {
+			return nil, fmt.Errorf("Invalid path template (%s), the xpub can not derive hardened keys", path)
+		}
+	}
+	return result, nil
+}
@@ -0,0 +1,194 @@
+package acct
+
+import (
+	"bytes"
+	"testing"
+
+	"github.com/Qitmeer/qng/common/hash"
+	"github.com/Qitmeer/qng/core/address"
+	"github.com/Qitmeer/qng/crypto/bip32"
+	"github.com/Qitmeer/qng/crypto/ecc"
+	"github.com/Qitmeer/qng/params"
+)
+
+func testXpubMaster(t *testing.T) *bip32.Key {
+	master, err := bip32.NewMasterKey(bytes.Repeat([]byte{0x01}, 32))
+	if err != nil {
+		t.Fatal(err)
+	}
+	return master
+}
+
+// testXpubAddr returns the address of the key derived from the master key by
+// the path.
+func testXpubAddr(t *testing.T, master *bip32.Key, path ...uint32) string {
+	key := master
+	for _, idx := range path {
+		var err error
+		key, err = key.NewChildKey(idx)
+		if err != nil {
+			t.Fatal(err)
+		}
+	}
+	addr, err := address.NewPubKeyHashAddress(hash.Hash160(key.PublicKey().Key), params.ActiveNetParams.Params, ecc.ECDSA_Secp256k1)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return addr.String()
+}
+
+func TestParseXpubPath(t *testing.T) {
+	tests := []struct {
+		path   string
+		prefix []uint32
+		valid  bool
+	}{
+		{"*", []uint32{}, true},
+		{"0/*", []uint32{0}, true},
+		{"m/1/2/*", []uint32{1, 2}, true},
+		{"0", nil, false},
+		{"0/*/1", nil, false},
+		{"0'/*", nil, false},
+		{"x/*", nil, false},
+	}
+	for _, test := range tests {
+		prefix, err := parseXpubPath(test.path)
+		if (err == nil) != test.valid {
+			t.Errorf("%s: got the error %v, want valid %v", test.path, err, test.valid)
+			continue
+		}
+		if !test.valid {
+			continue
+		}
+		if len(prefix) != len(test.prefix) {
+			t.Errorf("%s: got the prefix %v, want %v", test.path, prefix, test.prefix)
+			continue
+		}
+		for i := range prefix {
+			if prefix[i] != test.prefix[i] {
+				t.Errorf("%s: got the prefix %v, want %v", test.path, prefix, test.prefix)
+			}
+		}
+	}
+}
+
+func TestXpubAcct(t *testing.T) {
+	master := testXpubMaster(t)
+	xpub := master.PublicKey().B58Serialize()
+
+	if _, err := NewXpubAcct("a", master.B58Serialize(), DefaultXpubPath, 3); err == nil {
+		t.Fatalf("an extended private key is watched")
+	}
+	for _, gapLimit := range []uint32{0, MaxGapLimit + 1} {
+		if _, err := NewXpubAcct("a", xpub, DefaultXpubPath, gapLimit); err == nil {
+			t.Fatalf("the gap limit %d is accepted", gapLimit)
+		}
+	}
+	if _, err := NewXpubAcct("", xpub, DefaultXpubPath, 3); err == nil {
+		t.Fatalf("an account without a name is accepted")
+	}
+
+	xa, err := NewXpubAcct("a", xpub, DefaultXpubPath, 3)
+	if err != nil {
+		t.Fatal(err)
+	}
+	addrs, err := xa.extend()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(addrs) != 3 {
+		t.Fatalf("got %d addresses, want the gap limit", len(addrs))
+	}
+	for i, addr := range addrs {
+		if want := testXpubAddr(t, master, 0, uint32(i)); addr != want {
+			t.Fatalf("got the address %s of the index %d, want %s", addr, i, want)
+		}
+	}
+	if path := xa.IndexPath(4); path != "0/4" {
+		t.Fatalf("got the path %s", path)
+	}
+
+	// Using an address moves the gap limit after it.
+	if !xa.markUsed(1) {
+		t.Fatalf("the gap limit didn't move")
+	}
+	if xa.markUsed(0) || xa.markUsed(1) {
+		t.Fatalf("the gap limit moved for an earlier address")
+	}
+	addrs, err = xa.extend()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(addrs) != 2 || addrs[1] != testXpubAddr(t, master, 0, 4) {
+		t.Fatalf("got the new addresses %v", addrs)
+	}
+
+	var buf bytes.Buffer
+	if err := xa.Encode(&buf); err != nil {
+		t.Fatal(err)
+	}
+	decoded := &XpubAcct{}
+	if err := decoded.Decode(&buf); err != nil {
+		t.Fatal(err)
+	}
+	if decoded.name != xa.name || decoded.xpub != xa.xpub || decoded.path != xa.path ||
+		decoded.gapLimit != xa.gapLimit || decoded.used != xa.used {
+		t.Fatalf("got the account %s, want %s", decoded, xa)
+	}
+}
+
+func TestAcctInfoXpub(t *testing.T) {
+	master := testXpubMaster(t)
+	xpub := master.PublicKey().B58Serialize()
+	info := NewAcctInfo()
+	xa, err := NewXpubAcct("a", xpub, DefaultXpubPath, 2)
+	if err != nil {
+		t.Fatal(err)
+	}
+	addrs, err := info.AddXpub(xa)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(addrs) != 2 || !info.Has(addrs[1]) || info.IsEmpty() {
+		t.Fatalf("the addresses %v aren't watched", addrs)
+	}
+
+	// The accounts are unique by name and by address.
+	same, _ := NewXpubAcct("a", xpub, "1/*", 2)
+	if _, err := info.AddXpub(same); err == nil {
+		t.Fatalf("an account with the same name is added")
+	}
+	overlap, _ := NewXpubAcct("b", xpub, "0/*", 2)
+	if _, err := info.AddXpub(overlap); err == nil {
+		t.Fatalf("an account with the same addresses is added")
+	}
+
+	if info.markUsed(testXpubAddr(t, master, 1, 0)) {
+		t.Fatalf("an address which isn't derived is marked used")
+	}
+	if !info.markUsed(addrs[1]) {
+		t.Fatalf("the gap limit didn't move")
+	}
+	added, err := info.extendXpubs()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(added) != 2 || !info.Has(added[0]) || info.derived[added[1]].index != 3 {
+		t.Fatalf("got the new addresses %v", added)
+	}
+
+	var buf bytes.Buffer
+	if err := info.Encode(&buf); err != nil {
+		t.Fatal(err)
+	}
+	decoded := NewAcctInfo()
+	if err := decoded.Decode(&buf); err != nil {
+		t.Fatal(err)
+	}
+	// The addresses up to the gap limit after the used one are derived
+	// again.
+	got := decoded.GetXpub("a")
+	if got == nil || got.used != 2 || len(got.addrs) != 4 || !decoded.Has(added[1]) {
+		t.Fatalf("got the decoded account %v", got)
+	}
+}
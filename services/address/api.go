@@ -3,6 +3,7 @@
 package address
 
 import (
+	"encoding/base64"
 	"encoding/hex"
 	"fmt"
 	"github.com/Qitmeer/qng/common/encode/base58"
@@ -11,6 +12,7 @@ import (
 	"github.com/Qitmeer/qng/core/blockchain"
 	qjson "github.com/Qitmeer/qng/core/json"
 	"github.com/Qitmeer/qng/crypto/ecc"
+	"github.com/Qitmeer/qng/engine/txscript"
 	"github.com/Qitmeer/qng/meerevm/common"
 	"github.com/Qitmeer/qng/params"
 	"github.com/Qitmeer/qng/rpc"
@@ -58,11 +60,7 @@ func (c *AddressApi) APIs() []api.API {
 	}
 }
 
-func (api *PublicAddressAPI) CheckAddress(address string, network string) (interface{}, error) {
-	_, ver, err := base58.QitmeerCheckDecode(address)
-	if err != nil {
-		return false, rpc.RpcInvalidError("Invalid address :" + err.Error())
-	}
+func (api *PublicAddressAPI) CheckAddress(addr string, network string) (interface{}, error) {
 	var p *params.Params
 	switch network {
 	case "privnet":
@@ -76,13 +74,115 @@ func (api *PublicAddressAPI) CheckAddress(address string, network string) (inter
 	default:
 		return false, rpc.RpcInvalidError("Invalid network : privnet | testnet | mainnet | mixnet")
 	}
+	if address.IsBech32Address(addr) {
+		a, err := address.DecodeAddress(addr)
+		if err != nil {
+			return false, rpc.RpcInvalidError("Invalid address :" + err.Error())
+		}
+		if !address.IsForNetwork(a, p) {
+			actual := ""
+			for _, net := range []*params.Params{&params.PrivNetParams, &params.TestNetParams,
+				&params.MainNetParams, &params.MixNetParams} {
+				if address.IsForNetwork(a, net) {
+					actual = net.Bech32HRP
+				}
+			}
+			return false, rpc.RpcRuleError("address prefix error , need %s , actual: %s,network not match,please check it",
+				p.Bech32HRP, actual)
+		}
+		pkh, ok := a.(*address.PubKeyHashAddress)
+		if !ok || pkh.EcType() != ecc.ECDSA_Secp256k1 {
+			return false, rpc.RpcInvalidError("Invalid address :%s is not a secp256k1 pubkey hash address", addr)
+		}
+		return true, nil
+	}
+	_, ver, err := base58.QitmeerCheckDecode(addr)
+	if err != nil {
+		return false, rpc.RpcInvalidError("Invalid address :" + err.Error())
+	}
 	if p.PubKeyHashAddrID != ver {
 		return false, rpc.RpcRuleError("address prefix error , need %s , actual: %s,network not match,please check it",
-			p.NetworkAddressPrefix, address[0:1])
+			p.NetworkAddressPrefix, addr[0:1])
 	}
 	return true, nil
 }
 
+// VerifyMessage returns whether the message is signed by the key of the
+// address, the signature is in base64 like qx msg-sign.
+func (api *PublicAddressAPI) VerifyMessage(addr string, signature string, message string) (interface{}, error) {
+	a, err := address.DecodeAddress(addr)
+	if err != nil {
+		return nil, rpc.RpcInvalidError("Invalid address : %v", err)
+	}
+	if !address.IsForNetwork(a, api.addressApi.params) {
+		return nil, rpc.RpcInvalidError("Invalid address : %s is not for %s", addr, api.addressApi.params.Name)
+	}
+	if _, ok := a.(*address.ScriptHashAddress); ok {
+		return nil, rpc.RpcInvalidError("Address does not refer to key : %s", addr)
+	}
+	sig, err := base64.StdEncoding.DecodeString(signature)
+	if err != nil {
+		return nil, rpc.RpcInvalidError("Malformed base64 encoding : %v", err)
+	}
+	ok, err := address.VerifyMessage(a, sig, message)
+	if err != nil {
+		switch err {
+		case address.ErrMalformedMessageSignature, address.ErrMessageAddressType:
+			return nil, rpc.RpcInvalidError("%v : %s", err, addr)
+		}
+		// The well-formed signature which doesn't recover isn't signed
+		// by the address.
+		return false, nil
+	}
+	return ok, nil
+}
+
+// GetDescriptorInfo returns the descriptor with its checksum and whether it
+// is ranged.
+func (api *PublicAddressAPI) GetDescriptorInfo(descriptor string) (interface{}, error) {
+	d, err := txscript.ParseDescriptor(descriptor, false, api.addressApi.params)
+	if err != nil {
+		return nil, rpc.RpcInvalidError("Invalid descriptor : %v", err)
+	}
+	return qjson.DescriptorInfoResult{
+		Descriptor:     d.String(),
+		Checksum:       d.Checksum(),
+		IsRange:        d.IsRange(),
+		HasPrivateKeys: d.HasPrivateKeys(),
+	}, nil
+}
+
+// DeriveAddresses returns the addresses of the descriptor with its checksum,
+// from the begin to the end of the range for a ranged descriptor.
+func (api *PublicAddressAPI) DeriveAddresses(descriptor string, begin *uint32, end *uint32) (interface{}, error) {
+	d, err := txscript.ParseDescriptor(descriptor, true, api.addressApi.params)
+	if err != nil {
+		return nil, rpc.RpcInvalidError("Invalid descriptor : %v", err)
+	}
+	if d.IsRange() && begin == nil {
+		return nil, rpc.RpcInvalidError("Range must be specified for a ranged descriptor")
+	}
+	if !d.IsRange() && begin != nil {
+		return nil, rpc.RpcInvalidError("Range should not be specified for an un-ranged descriptor")
+	}
+	var b, e uint32
+	if begin != nil {
+		b, e = *begin, *begin
+		if end != nil {
+			e = *end
+		}
+	}
+	addrs, err := d.Addresses(b, e)
+	if err != nil {
+		return nil, rpc.RpcInvalidError("%v", err)
+	}
+	result := make([]string, 0, len(addrs))
+	for _, addr := range addrs {
+		result = append(result, addr.String())
+	}
+	return result, nil
+}
+
 // private
 type PrivateAddressAPI struct {
 	addressApi *AddressApi
@@ -0,0 +1,80 @@
+// Copyright (c) 2017-2018 The qitmeer developers
+
+package address
+
+import (
+	"encoding/base64"
+	"encoding/hex"
+	"strings"
+	"testing"
+
+	"github.com/Qitmeer/qng/common/hash"
+	"github.com/Qitmeer/qng/core/address"
+	"github.com/Qitmeer/qng/crypto/ecc"
+	"github.com/Qitmeer/qng/params"
+)
+
+func TestCheckAddress(t *testing.T) {
+	pkHash, _ := hex.DecodeString("8dc268a8e2876b941b95f3bd3b71050f003c5383")
+	testnetPKH, _ := address.NewPubKeyHashAddress(pkHash, &params.TestNetParams, ecc.ECDSA_Secp256k1)
+	testnetBech32, _ := address.EncodeBech32(testnetPKH)
+	mainnetPKH, _ := address.NewPubKeyHashAddress(pkHash, &params.MainNetParams, ecc.ECDSA_Secp256k1)
+	mainnetBech32, _ := address.EncodeBech32(mainnetPKH)
+	testnetP2SH, _ := address.NewScriptHashAddressFromHash(pkHash, &params.TestNetParams)
+	testnetP2SHBech32, _ := address.EncodeBech32(testnetP2SH)
+
+	tests := []struct {
+		name    string
+		addr    string
+		network string
+		err     string
+	}{
+		{"base58", testnetPKH.String(), "testnet", ""},
+		{"bech32", testnetBech32, "testnet", ""},
+		{"bech32 uppercase", strings.ToUpper(testnetBech32), "testnet", ""},
+		{"base58 network", mainnetPKH.String(), "testnet", "address prefix error , need T , actual: M"},
+		{"bech32 network", mainnetBech32, "testnet", "address prefix error , need tmeer , actual: meer,"},
+		{"bech32 p2sh", testnetP2SHBech32, "testnet", "Invalid Parameter"},
+		{"bech32 checksum", testnetBech32[:len(testnetBech32)-1] + "x", "testnet", "Invalid Parameter"},
+		{"unknown network", testnetBech32, "regnet", "Invalid network"},
+	}
+
+	api := &PublicAddressAPI{addressApi: &AddressApi{params: &params.TestNetParams}}
+	for _, test := range tests {
+		ok, err := api.CheckAddress(test.addr, test.network)
+		if test.err == "" {
+			if err != nil || ok != true {
+				t.Errorf("%s: got %v %v, want true", test.name, ok, err)
+			}
+			continue
+		}
+		if err == nil || !strings.Contains(err.Error(), test.err) {
+			t.Errorf("%s: got %v, want %q", test.name, err, test.err)
+		}
+	}
+}
+
+func TestVerifyMessage(t *testing.T) {
+	key, pub := ecc.Secp256k1.PrivKeyFromBytes(hash.HashB([]byte("message")))
+	pkh, _ := address.NewPubKeyHashAddress(hash.Hash160(pub.SerializeCompressed()), &params.TestNetParams, ecc.ECDSA_Secp256k1)
+	sig, err := address.SignMessage(key, true, "hello")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	api := &PublicAddressAPI{addressApi: &AddressApi{params: &params.TestNetParams}}
+	ok, err := api.VerifyMessage(pkh.String(), base64.StdEncoding.EncodeToString(sig), "hello")
+	if err != nil || ok != true {
+		t.Fatalf("got %v %v, want true", ok, err)
+	}
+	ok, err = api.VerifyMessage(pkh.String(), base64.StdEncoding.EncodeToString(sig), "bye")
+	if err != nil || ok != false {
+		t.Fatalf("got %v %v, want false", ok, err)
+	}
+	for _, signature := range []string{"!", base64.StdEncoding.EncodeToString(sig[1:])} {
+		_, err = api.VerifyMessage(pkh.String(), signature, "hello")
+		if err == nil || !strings.Contains(err.Error(), "Invalid Parameter") {
+			t.Errorf("%s: got %v, want an invalid parameter", signature, err)
+		}
+	}
+}
@@ -195,6 +195,16 @@ var (
 			Usage:       "Modules is a list of API modules(See GetNodeInfo) to expose via the HTTP RPC interface. If the module list is empty, all RPC API endpoints designated public will be exposed.",
 			Destination: &Modules,
 		},
+		&cli.BoolFlag{
+			Name:        "rest",
+			Usage:       "Enable the read-only REST interface (/rest/...) on the RPC listeners",
+			Destination: &cfg.REST,
+		},
+		&cli.BoolFlag{
+			Name:        "graphql",
+			Usage:       "Enable the read-only GraphQL endpoint (/graphql) on the RPC listeners",
+			Destination: &cfg.GraphQL,
+		},
 		&cli.BoolFlag{
 			Name:        "nocheckpoints",
 			Usage:       "Disable built-in checkpoints.  Don't do this unless you know what you're doing.",
@@ -220,6 +230,36 @@ var (
 			Usage:       "Maintain a full vm block index which makes the GetTxIDByMeerEVMTxHash RPC available",
 			Destination: &cfg.VMBlockIndex,
 		},
+		&cli.BoolFlag{
+			Name:        "spentindex",
+			Usage:       "Maintain a full spent outpoint index which makes the getSpendingTx RPC available",
+			Destination: &cfg.SpentIndex,
+		},
+		&cli.BoolFlag{
+			Name:        "dropspentindex",
+			Usage:       "Deletes the spent outpoint index from the database on start up and then exits.",
+			Destination: &cfg.DropSpentIndex,
+		},
+		&cli.BoolFlag{
+			Name:        "tokenindex",
+			Usage:       "Maintain a token activity and holder index which makes the getTokenHolders, getTokenHistory and getTokenBalances RPCs available",
+			Destination: &cfg.TokenIndex,
+		},
+		&cli.BoolFlag{
+			Name:        "droptokenindex",
+			Usage:       "Deletes the token index from the database on start up and then exits.",
+			Destination: &cfg.DropTokenIndex,
+		},
+		&cli.BoolFlag{
+			Name:        "crosschainindex",
+			Usage:       "Maintain a cross chain transfer index which makes the getCrossChainTransfer and listCrossChainTransfers RPCs available",
+			Destination: &cfg.CrossChainIndex,
+		},
+		&cli.BoolFlag{
+			Name:        "dropcrosschainindex",
+			Usage:       "Deletes the cross chain transfer index from the database on start up and then exits.",
+			Destination: &cfg.DropCrossChainIndex,
+		},
 		&cli.BoolFlag{
 			Name:        "light",
 			Usage:       "start as a qitmeer light node",
@@ -448,6 +488,11 @@ var (
 			Usage:       "Enable publish raw transaction in <address>",
 			Destination: &cfg.Zmqpubrawtx,
 		},
+		&cli.StringFlag{
+			Name:        "zmqpubreorg",
+			Usage:       "Enable publish reorganization diff in <address>",
+			Destination: &cfg.Zmqpubreorg,
+		},
 		&cli.BoolFlag{
 			Name:        "invalidtxindex",
 			Usage:       "invalid transaction index.",
@@ -818,6 +863,72 @@ func LoadConfig(ctx *cli.Context, parsefile bool) (*config.Config, error) {
 		return nil, err
 	}
 
+	// --spentindex and --dropspentindex do not mix.
+	if cfg.SpentIndex && cfg.DropSpentIndex {
+		err := fmt.Errorf("%s: the --spentindex and --dropspentindex "+
+			"options may not be activated at the same time",
+			funcName)
+		fmt.Fprintln(os.Stderr, err)
+		fmt.Fprintln(os.Stderr, usageMessage)
+		return nil, err
+	}
+
+	// --spentindex and --droptxindex do not mix.
+	if cfg.SpentIndex && cfg.DropTxIndex {
+		err := fmt.Errorf("%s: the --spentindex and --droptxindex "+
+			"options may not be activated at the same time "+
+			"because the spent index relies on the transaction "+
+			"index",
+			funcName)
+		fmt.Fprintln(os.Stderr, err)
+		fmt.Fprintln(os.Stderr, usageMessage)
+		return nil, err
+	}
+
+	// --tokenindex and --droptokenindex do not mix.
+	if cfg.TokenIndex && cfg.DropTokenIndex {
+		err := fmt.Errorf("%s: the --tokenindex and --droptokenindex "+
+			"options may not be activated at the same time",
+			funcName)
+		fmt.Fprintln(os.Stderr, err)
+		fmt.Fprintln(os.Stderr, usageMessage)
+		return nil, err
+	}
+
+	// --tokenindex and --droptxindex do not mix.
+	if cfg.TokenIndex && cfg.DropTxIndex {
+		err := fmt.Errorf("%s: the --tokenindex and --droptxindex "+
+			"options may not be activated at the same time "+
+			"because the token index relies on the transaction "+
+			"index",
+			funcName)
+		fmt.Fprintln(os.Stderr, err)
+		fmt.Fprintln(os.Stderr, usageMessage)
+		return nil, err
+	}
+
+	// --crosschainindex and --dropcrosschainindex do not mix.
+	if cfg.CrossChainIndex && cfg.DropCrossChainIndex {
+		err := fmt.Errorf("%s: the --crosschainindex and --dropcrosschainindex "+
+			"options may not be activated at the same time",
+			funcName)
+		fmt.Fprintln(os.Stderr, err)
+		fmt.Fprintln(os.Stderr, usageMessage)
+		return nil, err
+	}
+
+	// --crosschainindex and --droptxindex do not mix.
+	if cfg.CrossChainIndex && cfg.DropTxIndex {
+		err := fmt.Errorf("%s: the --crosschainindex and --droptxindex "+
+			"options may not be activated at the same time "+
+			"because the cross chain index relies on the transaction "+
+			"index",
+			funcName)
+		fmt.Fprintln(os.Stderr, err)
+		fmt.Fprintln(os.Stderr, usageMessage)
+		return nil, err
+	}
+
 	// Check mining addresses are valid and saved parsed versions.
 	for _, strAddr := range cfg.MiningAddrs {
 		addr, err := address.DecodeAddress(strAddr)
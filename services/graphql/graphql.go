@@ -0,0 +1,829 @@
+// Copyright (c) 2017-2018 The qitmeer developers
+
+package graphql
+
+import (
+	"bytes"
+	"context"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"github.com/Qitmeer/qng/common/hash"
+	"github.com/Qitmeer/qng/consensus/model"
+	"github.com/Qitmeer/qng/core/address"
+	"github.com/Qitmeer/qng/core/blockchain"
+	"github.com/Qitmeer/qng/core/types"
+	"github.com/Qitmeer/qng/database"
+	"github.com/Qitmeer/qng/engine/txscript"
+	"github.com/Qitmeer/qng/meerdag"
+	"github.com/Qitmeer/qng/services/index"
+	"github.com/Qitmeer/qng/services/mempool"
+	"strconv"
+	"sync/atomic"
+)
+
+const (
+	// maxBlocksRange is the maximum number of blocks returned by a single
+	// blocks query.
+	maxBlocksRange = 1000
+
+	// defaultAddressTxCount is the default number of transactions returned
+	// by the transactions field of an address.
+	defaultAddressTxCount = 100
+
+	// maxAddressTxCount is the maximum number of transactions returned by
+	// the transactions field of an address.
+	maxAddressTxCount = 1000
+
+	// maxQueryItems is the maximum number of list items resolved by a
+	// single query, over all of its list fields.
+	maxQueryItems = 10000
+)
+
+var (
+	errBlockInvariant = errors.New("block objects must be instantiated with a DAG block")
+	errNoAddrIndex    = errors.New("Address index must be enabled (--addrindex)")
+	errQueryTooLarge  = fmt.Errorf("query resolves more than %d list items", maxQueryItems)
+)
+
+// queryBudget is the number of list items a query can still resolve.
+type queryBudget struct {
+	left int64
+}
+
+type queryBudgetKey struct{}
+
+// withQueryBudget returns the context of a query which resolves at most
+// maxQueryItems list items.
+func withQueryBudget(ctx context.Context) context.Context {
+	return context.WithValue(ctx, queryBudgetKey{}, &queryBudget{left: maxQueryItems})
+}
+
+// chargeQuery takes the list items from the budget of the query, the list
+// fields are resolved in parallel.
+func chargeQuery(ctx context.Context, items int) error {
+	budget, ok := ctx.Value(queryBudgetKey{}).(*queryBudget)
+	if !ok {
+		return nil
+	}
+	if atomic.AddInt64(&budget.left, -int64(items)) < 0 {
+		return errQueryTooLarge
+	}
+	return nil
+}
+
+// Long is a 64 bit integer scalar.
+type Long int64
+
+// ImplementsGraphQLType returns true if Long implements the provided GraphQL type.
+func (b Long) ImplementsGraphQLType(name string) bool { return name == "Long" }
+
+// UnmarshalGraphQL unmarshals the provided GraphQL query data.
+func (b *Long) UnmarshalGraphQL(input interface{}) error {
+	var err error
+	switch input := input.(type) {
+	case string:
+		var value int64
+		value, err = strconv.ParseInt(input, 10, 64)
+		*b = Long(value)
+	case int32:
+		*b = Long(input)
+	case int64:
+		*b = Long(input)
+	case float64:
+		*b = Long(input)
+	default:
+		err = fmt.Errorf("unexpected type %T for Long", input)
+	}
+	return err
+}
+
+// Resolver is the root resolver of the schema.
+type Resolver struct {
+	consensus model.Consensus
+	txPool    *mempool.TxPool
+}
+
+func (r *Resolver) chain() *blockchain.BlockChain {
+	return r.consensus.BlockChain().(*blockchain.BlockChain)
+}
+
+func (r *Resolver) indexManager() *index.Manager {
+	return r.consensus.IndexManager().(*index.Manager)
+}
+
+func (r *Resolver) newBlock(ib meerdag.IBlock) *Block {
+	if ib == nil {
+		return nil
+	}
+	return &Block{r: r, ib: ib}
+}
+
+func (r *Resolver) blocksOfSet(ctx context.Context, set *meerdag.IdSet) ([]*Block, error) {
+	ret := []*Block{}
+	if set == nil || set.IsEmpty() {
+		return ret, nil
+	}
+	if err := chargeQuery(ctx, set.Size()); err != nil {
+		return nil, err
+	}
+	bd := r.chain().BlockDAG()
+	for _, id := range set.SortList(false) {
+		if b := r.newBlock(bd.GetBlockById(id)); b != nil {
+			ret = append(ret, b)
+		}
+	}
+	return ret, nil
+}
+
+// fetchTx looks up a transaction in the mempool and then in the transaction
+// index. It returns nil when the transaction is unknown.
+func (r *Resolver) fetchTx(txid *hash.Hash) (*Transaction, error) {
+	tx, _ := r.txPool.FetchTransaction(txid)
+	if tx != nil {
+		return &Transaction{r: r, tx: tx, inMempool: true}, nil
+	}
+	txIndex := r.indexManager().TxIndex()
+	if txIndex == nil {
+		return nil, fmt.Errorf("the transaction index must be enabled")
+	}
+	blockRegion, err := txIndex.TxBlockRegion(*txid)
+	if err != nil {
+		return nil, err
+	}
+	if blockRegion == nil {
+		return nil, nil
+	}
+	txBytes, err := r.indexManager().GetTxBytes(blockRegion)
+	if err != nil {
+		return nil, err
+	}
+	return newTransactionFromBytes(r, txBytes, blockRegion.Hash)
+}
+
+func newTransactionFromBytes(r *Resolver, txBytes []byte, blockHash *hash.Hash) (*Transaction, error) {
+	var msgTx types.Transaction
+	err := msgTx.Deserialize(bytes.NewReader(txBytes))
+	if err != nil {
+		return nil, err
+	}
+	return &Transaction{r: r, tx: types.NewTx(&msgTx), blockHash: blockHash}, nil
+}
+
+// Block resolves a block by hash or order.
+func (r *Resolver) Block(ctx context.Context, args struct {
+	Hash  *string
+	Order *Long
+}) (*Block, error) {
+	bd := r.chain().BlockDAG()
+	if args.Hash != nil {
+		h, err := hash.NewHashFromStr(*args.Hash)
+		if err != nil {
+			return nil, err
+		}
+		return r.newBlock(bd.GetBlock(h)), nil
+	}
+	order := uint(r.chain().BestSnapshot().GraphState.GetMainOrder())
+	if args.Order != nil {
+		if *args.Order < 0 {
+			return nil, fmt.Errorf("invalid order %d", *args.Order)
+		}
+		order = uint(*args.Order)
+	}
+	return r.newBlock(bd.GetBlockByOrder(order)), nil
+}
+
+// Blocks resolves the blocks of an order range.
+func (r *Resolver) Blocks(ctx context.Context, args struct {
+	From Long
+	To   *Long
+}) ([]*Block, error) {
+	mainOrder := Long(r.chain().BestSnapshot().GraphState.GetMainOrder())
+	to := mainOrder
+	if args.To != nil && *args.To < to {
+		to = *args.To
+	}
+	if args.From < 0 || args.From > to {
+		return []*Block{}, nil
+	}
+	if to-args.From >= maxBlocksRange {
+		return nil, fmt.Errorf("blocks range is limited to %d", maxBlocksRange)
+	}
+	if err := chargeQuery(ctx, int(to-args.From+1)); err != nil {
+		return nil, err
+	}
+	bd := r.chain().BlockDAG()
+	ret := make([]*Block, 0, to-args.From+1)
+	for order := args.From; order <= to; order++ {
+		if b := r.newBlock(bd.GetBlockByOrder(uint(order))); b != nil {
+			ret = append(ret, b)
+		}
+	}
+	return ret, nil
+}
+
+// Tips resolves the tips of the DAG.
+func (r *Resolver) Tips(ctx context.Context) ([]*Block, error) {
+	tips := r.chain().BlockDAG().GetTipsList()
+	if err := chargeQuery(ctx, len(tips)); err != nil {
+		return nil, err
+	}
+	ret := []*Block{}
+	for _, ib := range tips {
+		ret = append(ret, r.newBlock(ib))
+	}
+	return ret, nil
+}
+
+// Transaction resolves a transaction by id.
+func (r *Resolver) Transaction(ctx context.Context, args struct{ Hash string }) (*Transaction, error) {
+	h, err := hash.NewHashFromStr(args.Hash)
+	if err != nil {
+		return nil, err
+	}
+	return r.fetchTx(h)
+}
+
+// Address resolves an address.
+func (r *Resolver) Address(ctx context.Context, args struct{ Address string }) (*Address, error) {
+	addr, err := address.DecodeAddress(args.Address)
+	if err != nil {
+		return nil, fmt.Errorf("Invalid address or key: %s", err)
+	}
+	return &Address{r: r, addr: addr}, nil
+}
+
+// Tokens resolves the token types.
+func (r *Resolver) Tokens(ctx context.Context) []*Token {
+	ret := []*Token{}
+	state := r.chain().GetCurTokenState()
+	if state == nil {
+		return ret
+	}
+	for _, v := range state.Types {
+		t := &Token{
+			coinId:  int32(v.Id),
+			name:    v.Name,
+			owners:  hex.EncodeToString(v.Owners),
+			upLimit: Long(v.UpLimit),
+			enable:  v.Enable,
+		}
+		if b, ok := state.Balances[v.Id]; ok {
+			t.balance = Long(b.Balance)
+			t.lockedMeer = Long(b.LockedMeer)
+		}
+		ret = append(ret, t)
+	}
+	return ret
+}
+
+// Mempool resolves the transaction pool.
+func (r *Resolver) Mempool(ctx context.Context) *Mempool {
+	return &Mempool{r: r}
+}
+
+// Dag resolves the state of the MeerDAG.
+func (r *Resolver) Dag(ctx context.Context) *DAG {
+	return &DAG{r: r}
+}
+
+// Block is the resolver of a DAG block.
+type Block struct {
+	r     *Resolver
+	ib    meerdag.IBlock
+	block *types.SerializedBlock
+}
+
+// resolve loads the block data from the database if it has not been loaded yet.
+func (b *Block) resolve() (*types.SerializedBlock, error) {
+	if b.ib == nil {
+		return nil, errBlockInvariant
+	}
+	if b.block != nil {
+		return b.block, nil
+	}
+	blk, err := b.r.chain().FetchBlockByHash(b.ib.GetHash())
+	if err != nil {
+		return nil, err
+	}
+	b.block = blk
+	return blk, nil
+}
+
+func (b *Block) Hash() string {
+	return b.ib.GetHash().String()
+}
+
+func (b *Block) Order() *Long {
+	if !b.ib.IsOrdered() {
+		return nil
+	}
+	order := Long(b.ib.GetOrder())
+	return &order
+}
+
+func (b *Block) Height() Long {
+	return Long(b.ib.GetHeight())
+}
+
+func (b *Block) Layer() Long {
+	return Long(b.ib.GetLayer())
+}
+
+func (b *Block) IsBlue() bool {
+	return b.r.chain().BlockDAG().IsBlue(b.ib.GetID())
+}
+
+func (b *Block) IsOrdered() bool {
+	return b.ib.IsOrdered()
+}
+
+func (b *Block) IsValid() bool {
+	return !b.ib.GetStatus().KnownInvalid()
+}
+
+func (b *Block) IsOnMainChain() bool {
+	return b.r.chain().BlockDAG().IsOnMainChain(b.ib.GetID())
+}
+
+func (b *Block) Confirmations() Long {
+	return Long(b.r.chain().BlockDAG().GetConfirmations(b.ib.GetID()))
+}
+
+func (b *Block) Weight() Long {
+	return Long(b.ib.GetWeight())
+}
+
+func (b *Block) Parents(ctx context.Context) ([]*Block, error) {
+	return b.r.blocksOfSet(ctx, b.r.chain().BlockDAG().GetParents(b.ib))
+}
+
+func (b *Block) Children(ctx context.Context) ([]*Block, error) {
+	return b.r.blocksOfSet(ctx, b.r.chain().BlockDAG().GetChildren(b.ib))
+}
+
+func (b *Block) MainParent() *Block {
+	if !b.ib.HasParents() {
+		return nil
+	}
+	return b.r.newBlock(b.r.chain().BlockDAG().GetBlockById(b.ib.GetMainParent()))
+}
+
+func (b *Block) Version(ctx context.Context) (int32, error) {
+	blk, err := b.resolve()
+	if err != nil {
+		return 0, err
+	}
+	return int32(blk.Block().Header.Version), nil
+}
+
+func (b *Block) Timestamp(ctx context.Context) (Long, error) {
+	blk, err := b.resolve()
+	if err != nil {
+		return 0, err
+	}
+	return Long(blk.Block().Header.Timestamp.Unix()), nil
+}
+
+func (b *Block) Difficulty(ctx context.Context) (Long, error) {
+	blk, err := b.resolve()
+	if err != nil {
+		return 0, err
+	}
+	return Long(blk.Block().Header.Difficulty), nil
+}
+
+func (b *Block) ParentRoot(ctx context.Context) (string, error) {
+	blk, err := b.resolve()
+	if err != nil {
+		return "", err
+	}
+	return blk.Block().Header.ParentRoot.String(), nil
+}
+
+func (b *Block) TxRoot(ctx context.Context) (string, error) {
+	blk, err := b.resolve()
+	if err != nil {
+		return "", err
+	}
+	return blk.Block().Header.TxRoot.String(), nil
+}
+
+func (b *Block) StateRoot(ctx context.Context) (string, error) {
+	blk, err := b.resolve()
+	if err != nil {
+		return "", err
+	}
+	return blk.Block().Header.StateRoot.String(), nil
+}
+
+func (b *Block) Fees(ctx context.Context) []*Amount {
+	ret := []*Amount{}
+	for coinId, v := range b.r.chain().GetFees(b.ib.GetHash()) {
+		ret = append(ret, &Amount{coinId: int32(coinId), value: Long(v)})
+	}
+	return ret
+}
+
+func (b *Block) TransactionCount(ctx context.Context) (int32, error) {
+	blk, err := b.resolve()
+	if err != nil {
+		return 0, err
+	}
+	return int32(len(blk.Transactions())), nil
+}
+
+func (b *Block) Transactions(ctx context.Context) ([]*Transaction, error) {
+	blk, err := b.resolve()
+	if err != nil {
+		return nil, err
+	}
+	if err := chargeQuery(ctx, len(blk.Transactions())); err != nil {
+		return nil, err
+	}
+	ret := make([]*Transaction, 0, len(blk.Transactions()))
+	for _, tx := range blk.Transactions() {
+		ret = append(ret, &Transaction{r: b.r, tx: tx, blockHash: b.ib.GetHash()})
+	}
+	return ret, nil
+}
+
+func (b *Block) TransactionAt(ctx context.Context, args struct{ Index int32 }) (*Transaction, error) {
+	blk, err := b.resolve()
+	if err != nil {
+		return nil, err
+	}
+	txs := blk.Transactions()
+	if args.Index < 0 || int(args.Index) >= len(txs) {
+		return nil, nil
+	}
+	return &Transaction{r: b.r, tx: txs[args.Index], blockHash: b.ib.GetHash()}, nil
+}
+
+// Amount is the resolver of a coin value.
+type Amount struct {
+	coinId int32
+	value  Long
+}
+
+func (a *Amount) CoinId() int32 { return a.coinId }
+
+func (a *Amount) Value() Long { return a.value }
+
+// Transaction is the resolver of a transaction.
+type Transaction struct {
+	r         *Resolver
+	tx        *types.Tx
+	blockHash *hash.Hash
+	inMempool bool
+}
+
+func (t *Transaction) Hash() string {
+	return t.tx.Hash().String()
+}
+
+func (t *Transaction) FullHash() string {
+	return t.tx.Tx.TxHashFull().String()
+}
+
+func (t *Transaction) Version() int32 {
+	return int32(t.tx.Tx.Version)
+}
+
+func (t *Transaction) LockTime() Long {
+	return Long(t.tx.Tx.LockTime)
+}
+
+func (t *Transaction) Expire() Long {
+	return Long(t.tx.Tx.Expire)
+}
+
+func (t *Transaction) Timestamp() Long {
+	return Long(t.tx.Tx.Timestamp.Unix())
+}
+
+func (t *Transaction) Type() string {
+	return types.DetermineTxType(t.tx.Tx).String()
+}
+
+func (t *Transaction) Size() int32 {
+	return int32(t.tx.Tx.SerializeSize())
+}
+
+func (t *Transaction) IsCoinbase() bool {
+	return t.tx.Tx.IsCoinBase()
+}
+
+func (t *Transaction) InMempool() bool {
+	return t.inMempool
+}
+
+func (t *Transaction) Block(ctx context.Context) *Block {
+	if t.blockHash == nil {
+		return nil
+	}
+	return t.r.newBlock(t.r.chain().BlockDAG().GetBlock(t.blockHash))
+}
+
+func (t *Transaction) Confirmations(ctx context.Context) Long {
+	if t.blockHash == nil {
+		return 0
+	}
+	bd := t.r.chain().BlockDAG()
+	ib := bd.GetBlock(t.blockHash)
+	if ib == nil {
+		return 0
+	}
+	return Long(bd.GetConfirmations(ib.GetID()))
+}
+
+func (t *Transaction) Inputs(ctx context.Context) ([]*Input, error) {
+	if err := chargeQuery(ctx, len(t.tx.Tx.TxIn)); err != nil {
+		return nil, err
+	}
+	ret := make([]*Input, 0, len(t.tx.Tx.TxIn))
+	for i, in := range t.tx.Tx.TxIn {
+		ret = append(ret, &Input{tx: t, index: i, in: in})
+	}
+	return ret, nil
+}
+
+func (t *Transaction) Outputs(ctx context.Context) ([]*Output, error) {
+	if err := chargeQuery(ctx, len(t.tx.Tx.TxOut)); err != nil {
+		return nil, err
+	}
+	ret := make([]*Output, 0, len(t.tx.Tx.TxOut))
+	for i, out := range t.tx.Tx.TxOut {
+		ret = append(ret, &Output{tx: t, index: i, out: out})
+	}
+	return ret, nil
+}
+
+// Input is the resolver of a transaction input.
+type Input struct {
+	tx    *Transaction
+	index int
+	in    *types.TxInput
+}
+
+func (i *Input) Index() int32 {
+	return int32(i.index)
+}
+
+func (i *Input) PreviousTxid() string {
+	return i.in.PreviousOut.Hash.String()
+}
+
+func (i *Input) PreviousVout() Long {
+	return Long(i.in.PreviousOut.OutIndex)
+}
+
+func (i *Input) Sequence() Long {
+	return Long(i.in.Sequence)
+}
+
+func (i *Input) SignScript() string {
+	return hex.EncodeToString(i.in.SignScript)
+}
+
+func (i *Input) PreviousTransaction(ctx context.Context) (*Transaction, error) {
+	if i.tx.tx.Tx.IsCoinBase() || i.in.PreviousOut.Hash.IsEqual(&hash.ZeroHash) {
+		return nil, nil
+	}
+	return i.tx.r.fetchTx(&i.in.PreviousOut.Hash)
+}
+
+func (i *Input) PreviousOutput(ctx context.Context) (*Output, error) {
+	prev, err := i.PreviousTransaction(ctx)
+	if err != nil || prev == nil {
+		return nil, err
+	}
+	vout := int(i.in.PreviousOut.OutIndex)
+	if vout >= len(prev.tx.Tx.TxOut) {
+		return nil, nil
+	}
+	return &Output{tx: prev, index: vout, out: prev.tx.Tx.TxOut[vout]}, nil
+}
+
+// Output is the resolver of a transaction output.
+type Output struct {
+	tx    *Transaction
+	index int
+	out   *types.TxOutput
+}
+
+func (o *Output) Index() int32 {
+	return int32(o.index)
+}
+
+func (o *Output) CoinId() int32 {
+	return int32(o.out.Amount.Id)
+}
+
+func (o *Output) Amount() Long {
+	return Long(o.out.Amount.Value)
+}
+
+func (o *Output) PkScript() string {
+	return hex.EncodeToString(o.out.PkScript)
+}
+
+func (o *Output) ScriptType() string {
+	class, _, _, _ := txscript.ExtractPkScriptAddrs(o.out.PkScript, o.tx.r.consensus.Params())
+	return class.String()
+}
+
+func (o *Output) ReqSigs() int32 {
+	_, _, reqSigs, _ := txscript.ExtractPkScriptAddrs(o.out.PkScript, o.tx.r.consensus.Params())
+	return int32(reqSigs)
+}
+
+func (o *Output) Addresses() []string {
+	_, addrs, _, _ := txscript.ExtractPkScriptAddrs(o.out.PkScript, o.tx.r.consensus.Params())
+	ret := make([]string, 0, len(addrs))
+	for _, addr := range addrs {
+		ret = append(ret, addr.String())
+	}
+	return ret
+}
+
+func (o *Output) Unspent(ctx context.Context) (bool, error) {
+	if o.tx.inMempool {
+		return false, nil
+	}
+	entry, err := o.tx.r.chain().FetchUtxoEntry(types.TxOutPoint{Hash: *o.tx.tx.Hash(), OutIndex: uint32(o.index)})
+	if err != nil {
+		return false, err
+	}
+	return entry != nil && !entry.IsSpent(), nil
+}
+
+func (o *Output) Transaction() *Transaction {
+	return o.tx
+}
+
+// Address is the resolver of an address.
+type Address struct {
+	r    *Resolver
+	addr types.Address
+}
+
+func (a *Address) Address() string {
+	return a.addr.String()
+}
+
+func (a *Address) Transactions(ctx context.Context, args struct {
+	Count   *int32
+	Skip    *int32
+	Reverse *bool
+}) ([]*Transaction, error) {
+	addrIndex := a.r.indexManager().AddrIndex()
+	if addrIndex == nil {
+		return nil, errNoAddrIndex
+	}
+	if err := a.r.indexManager().CheckSynced(addrIndex.Name()); err != nil {
+		return nil, err
+	}
+	count := uint32(defaultAddressTxCount)
+	if args.Count != nil && *args.Count >= 0 {
+		count = uint32(*args.Count)
+	}
+	if count > maxAddressTxCount {
+		return nil, fmt.Errorf("transactions count is limited to %d", maxAddressTxCount)
+	}
+	if err := chargeQuery(ctx, int(count)); err != nil {
+		return nil, err
+	}
+	skip := uint32(0)
+	if args.Skip != nil && *args.Skip >= 0 {
+		skip = uint32(*args.Skip)
+	}
+	reverse := args.Reverse != nil && *args.Reverse
+
+	ret := []*Transaction{}
+	err := a.r.consensus.DatabaseContext().View(func(dbTx database.Tx) error {
+		regions, _, err := addrIndex.TxRegionsForAddress(dbTx, a.addr, skip, count, reverse)
+		if err != nil {
+			return err
+		}
+		serializedTxns, err := dbTx.FetchBlockRegions(regions)
+		if err != nil {
+			return err
+		}
+		for i, serializedTx := range serializedTxns {
+			tx, err := newTransactionFromBytes(a.r, serializedTx, regions[i].Hash)
+			if err != nil {
+				return err
+			}
+			ret = append(ret, tx)
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return ret, nil
+}
+
+func (a *Address) UnconfirmedTransactions(ctx context.Context) ([]*Transaction, error) {
+	addrIndex := a.r.indexManager().AddrIndex()
+	if addrIndex == nil {
+		return nil, errNoAddrIndex
+	}
+	txs := addrIndex.UnconfirmedTxnsForAddress(a.addr)
+	if err := chargeQuery(ctx, len(txs)); err != nil {
+		return nil, err
+	}
+	ret := []*Transaction{}
+	for _, tx := range txs {
+		ret = append(ret, &Transaction{r: a.r, tx: tx, inMempool: true})
+	}
+	return ret, nil
+}
+
+// Token is the resolver of a token type.
+type Token struct {
+	coinId     int32
+	name       string
+	owners     string
+	upLimit    Long
+	enable     bool
+	balance    Long
+	lockedMeer Long
+}
+
+func (t *Token) CoinId() int32 { return t.coinId }
+
+func (t *Token) Name() string { return t.name }
+
+func (t *Token) Owners() string { return t.owners }
+
+func (t *Token) UpLimit() Long { return t.upLimit }
+
+func (t *Token) Enable() bool { return t.enable }
+
+func (t *Token) Balance() Long { return t.balance }
+
+func (t *Token) LockedMeer() Long { return t.lockedMeer }
+
+// Mempool is the resolver of the transaction pool.
+type Mempool struct {
+	r *Resolver
+}
+
+func (m *Mempool) Count() int32 {
+	return int32(m.r.txPool.Count())
+}
+
+func (m *Mempool) Bytes() Long {
+	var size Long
+	for _, desc := range m.r.txPool.TxDescs() {
+		size += Long(desc.Tx.Tx.SerializeSize())
+	}
+	return size
+}
+
+func (m *Mempool) Transactions(ctx context.Context) ([]*Transaction, error) {
+	descs := m.r.txPool.TxDescs()
+	if err := chargeQuery(ctx, len(descs)); err != nil {
+		return nil, err
+	}
+	ret := make([]*Transaction, 0, len(descs))
+	for _, desc := range descs {
+		ret = append(ret, &Transaction{r: m.r, tx: desc.Tx, inMempool: true})
+	}
+	return ret, nil
+}
+
+// DAG is the resolver of the MeerDAG state.
+type DAG struct {
+	r *Resolver
+}
+
+func (d *DAG) Name() string {
+	return d.r.chain().BlockDAG().GetName()
+}
+
+func (d *DAG) Total() Long {
+	return Long(d.r.chain().BestSnapshot().GraphState.GetTotal())
+}
+
+func (d *DAG) Layer() Long {
+	return Long(d.r.chain().BestSnapshot().GraphState.GetLayer())
+}
+
+func (d *DAG) MainOrder() Long {
+	return Long(d.r.chain().BestSnapshot().GraphState.GetMainOrder())
+}
+
+func (d *DAG) MainHeight() Long {
+	return Long(d.r.chain().BestSnapshot().GraphState.GetMainHeight())
+}
+
+func (d *DAG) MainChainTip() *Block {
+	return d.r.newBlock(d.r.chain().BlockDAG().GetMainChainTip())
+}
+
+func (d *DAG) Tips(ctx context.Context) ([]*Block, error) {
+	return d.r.Tips(ctx)
+}
@@ -0,0 +1,73 @@
+// Copyright (c) 2017-2018 The qitmeer developers
+
+package graphql
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/Qitmeer/qng/common/hash"
+	"github.com/Qitmeer/qng/core/types"
+)
+
+func TestQueryDepth(t *testing.T) {
+	h, err := New(nil, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	// The parents of the parents of... walk the DAG, the query is rejected
+	// before any resolver runs.
+	query := "{ block { " + strings.Repeat("parents { ", maxQueryDepth) + "hash" +
+		strings.Repeat(" }", maxQueryDepth) + " } }"
+	req := httptest.NewRequest(http.MethodPost, PathPrefix,
+		strings.NewReader(`{"query":"`+query+`"}`))
+	rec := httptest.NewRecorder()
+	h.ServeHTTP(rec, req)
+	if rec.Code != http.StatusBadRequest {
+		t.Fatalf("got status %d, want %d", rec.Code, http.StatusBadRequest)
+	}
+	if !strings.Contains(rec.Body.String(), "exceeds max depth") {
+		t.Fatalf("unexpected response %s", rec.Body.String())
+	}
+}
+
+func TestTransactionResolver(t *testing.T) {
+	tx := types.NewTransaction()
+	for i := 0; i < 2; i++ {
+		tx.AddTxIn(types.NewTxInput(types.NewOutPoint(&hash.Hash{byte(i + 1)}, uint32(i)), nil))
+	}
+	for i := 0; i < 3; i++ {
+		tx.AddTxOut(types.NewTxOutput(types.Amount{Id: types.MEERA, Value: int64(i + 1)}, nil))
+	}
+	tr := &Transaction{tx: types.NewTx(tx), inMempool: true}
+	if tr.Hash() != tx.TxHash().String() {
+		t.Fatalf("got hash %s, want %s", tr.Hash(), tx.TxHash().String())
+	}
+
+	ctx := withQueryBudget(context.Background())
+	if err := chargeQuery(ctx, maxQueryItems-5); err != nil {
+		t.Fatal(err)
+	}
+	inputs, err := tr.Inputs(ctx)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(inputs) != 2 || inputs[1].Index() != 1 {
+		t.Fatalf("unexpected inputs %v", inputs)
+	}
+	outputs, err := tr.Outputs(ctx)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(outputs) != 3 || outputs[2].Amount() != 3 {
+		t.Fatalf("unexpected outputs %v", outputs)
+	}
+
+	// The budget of the query is used up.
+	if _, err := tr.Inputs(ctx); err != errQueryTooLarge {
+		t.Fatalf("got %v, want %v", err, errQueryTooLarge)
+	}
+}
@@ -0,0 +1,170 @@
+// Copyright (c) 2017-2018 The qitmeer developers
+
+package graphql
+
+// schema is the read-only GraphQL schema over the UTXO chain and the MeerDAG.
+const schema string = `
+    # Long is a 64 bit integer, it can be given as a decimal string or a number.
+    scalar Long
+
+    schema {
+        query: Query
+    }
+
+    # Block is a block of the MeerDAG.
+    type Block {
+        # Hash is the hash of the block.
+        hash: String!
+        # Order is the position of the block in the DAG total order, null if
+        # the block is not ordered yet.
+        order: Long
+        # Height is the height of the block in its main chain.
+        height: Long!
+        # Layer is the layer of the block in the DAG.
+        layer: Long!
+        # IsBlue reports whether the block is in the blue set of the DAG.
+        isBlue: Boolean!
+        # IsOrdered reports whether the block has an order yet.
+        isOrdered: Boolean!
+        # IsValid reports whether the transactions of the block are valid.
+        isValid: Boolean!
+        # IsOnMainChain reports whether the block is on the DAG main chain.
+        isOnMainChain: Boolean!
+        confirmations: Long!
+        weight: Long!
+        # Parents are the blocks referenced by this block.
+        parents: [Block!]!
+        # Children are the known blocks referencing this block.
+        children: [Block!]!
+        # MainParent is the parent of the block on the main chain.
+        mainParent: Block
+        version: Int!
+        timestamp: Long!
+        difficulty: Long!
+        parentRoot: String!
+        txRoot: String!
+        stateRoot: String!
+        # Fees are the transaction fees collected by the block, per coin.
+        fees: [Amount!]!
+        transactionCount: Int!
+        transactions: [Transaction!]!
+        transactionAt(index: Int!): Transaction
+    }
+
+    # Amount is a value of a coin.
+    type Amount {
+        coinId: Int!
+        value: Long!
+    }
+
+    # Transaction is a transaction of the UTXO chain.
+    type Transaction {
+        # Hash is the transaction id.
+        hash: String!
+        # FullHash is the hash of the transaction including the signatures.
+        fullHash: String!
+        version: Int!
+        lockTime: Long!
+        expire: Long!
+        timestamp: Long!
+        # Type is the transaction type, e.g. TxTypeRegular.
+        type: String!
+        size: Int!
+        isCoinbase: Boolean!
+        # InMempool reports whether the transaction is still in the mempool.
+        inMempool: Boolean!
+        # Block is the block containing the transaction, null when the
+        # transaction is in the mempool.
+        block: Block
+        confirmations: Long!
+        inputs: [Input!]!
+        outputs: [Output!]!
+    }
+
+    # Input is a transaction input.
+    type Input {
+        index: Int!
+        previousTxid: String!
+        previousVout: Long!
+        sequence: Long!
+        signScript: String!
+        # PreviousTransaction is the transaction of the spent output.
+        previousTransaction: Transaction
+        # PreviousOutput is the spent output.
+        previousOutput: Output
+    }
+
+    # Output is a transaction output.
+    type Output {
+        index: Int!
+        coinId: Int!
+        amount: Long!
+        pkScript: String!
+        scriptType: String!
+        reqSigs: Int!
+        addresses: [String!]!
+        # Unspent reports whether the output is in the UTXO set.
+        unspent: Boolean!
+        transaction: Transaction!
+    }
+
+    # Address is an address which was used on the chain.
+    type Address {
+        address: String!
+        # Transactions are the transactions of the address, 100 by default
+        # and at most 1000, the address index must be enabled (--addrindex).
+        transactions(count: Int, skip: Int, reverse: Boolean): [Transaction!]!
+        # UnconfirmedTransactions are the mempool transactions of the
+        # address, the address index must be enabled (--addrindex).
+        unconfirmedTransactions: [Transaction!]!
+    }
+
+    # Token is a token type of the chain.
+    type Token {
+        coinId: Int!
+        name: String!
+        owners: String!
+        upLimit: Long!
+        enable: Boolean!
+        balance: Long!
+        lockedMeer: Long!
+    }
+
+    # Mempool is the state of the transaction pool.
+    type Mempool {
+        count: Int!
+        bytes: Long!
+        transactions: [Transaction!]!
+    }
+
+    # DAG is the state of the MeerDAG.
+    type DAG {
+        name: String!
+        total: Long!
+        layer: Long!
+        mainOrder: Long!
+        mainHeight: Long!
+        mainChainTip: Block!
+        tips: [Block!]!
+    }
+
+    type Query {
+        # Block fetches a block by hash or by order, the latest ordered block
+        # is returned when neither is given.
+        block(hash: String, order: Long): Block
+        # Blocks returns the blocks in the order range [from, to].
+        blocks(from: Long!, to: Long): [Block!]!
+        # Tips returns the tips of the DAG.
+        tips: [Block!]!
+        # Transaction fetches a transaction by id.
+        transaction(hash: String!): Transaction
+        # Address returns an address.
+        address(address: String!): Address!
+        # Tokens returns all of the token types.
+        tokens: [Token!]!
+        # Mempool returns the transaction pool.
+        mempool: Mempool!
+        # Dag returns the state of the MeerDAG.
+        dag: DAG!
+    }
+`
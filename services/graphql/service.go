@@ -0,0 +1,69 @@
+// Copyright (c) 2017-2018 The qitmeer developers
+
+package graphql
+
+import (
+	"encoding/json"
+	"github.com/Qitmeer/qng/consensus/model"
+	"github.com/Qitmeer/qng/services/mempool"
+	"github.com/graph-gophers/graphql-go"
+	"net/http"
+)
+
+const (
+	// PathPrefix is the http path of the GraphQL endpoint.
+	PathPrefix = "/graphql"
+
+	// maxQueryDepth is the maximum depth of the fields of a query, the
+	// block and transaction fields refer to each other.
+	maxQueryDepth = 10
+
+	// maxQueryParallelism is the maximum number of fields resolved in
+	// parallel by a query.
+	maxQueryParallelism = 10
+)
+
+// Handler answers the GraphQL queries.
+type Handler struct {
+	Schema *graphql.Schema
+}
+
+func (h Handler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	var params struct {
+		Query         string                 `json:"query"`
+		OperationName string                 `json:"operationName"`
+		Variables     map[string]interface{} `json:"variables"`
+	}
+	if r.Method == http.MethodGet {
+		params.Query = r.URL.Query().Get("query")
+		params.OperationName = r.URL.Query().Get("operationName")
+	} else if err := json.NewDecoder(r.Body).Decode(&params); err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	response := h.Schema.Exec(withQueryBudget(r.Context()), params.Query, params.OperationName, params.Variables)
+	responseJSON, err := json.Marshal(response)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+	w.Header().Set("Content-Type", "application/json")
+	if len(response.Errors) > 0 {
+		w.WriteHeader(http.StatusBadRequest)
+	}
+	w.Write(responseJSON)
+}
+
+// New returns the http handler that will answer the read-only GraphQL queries
+// over the UTXO chain and the MeerDAG.
+func New(consensus model.Consensus, txPool *mempool.TxPool) (*Handler, error) {
+	q := Resolver{consensus: consensus, txPool: txPool}
+
+	s, err := graphql.ParseSchema(schema, &q, graphql.MaxDepth(maxQueryDepth),
+		graphql.MaxParallelism(maxQueryParallelism))
+	if err != nil {
+		return nil, err
+	}
+	return &Handler{Schema: s}, nil
+}
@@ -0,0 +1,170 @@
+// Copyright (c) 2017-2018 The qitmeer developers
+
+package index
+
+import (
+	"fmt"
+	"github.com/Qitmeer/qng/core/address"
+	"github.com/Qitmeer/qng/core/json"
+	"github.com/Qitmeer/qng/core/types"
+	"github.com/Qitmeer/qng/rpc/api"
+	"github.com/Qitmeer/qng/rpc/client/cmds"
+	"sort"
+)
+
+func (m *Manager) API() api.API {
+	return api.API{
+		NameSpace: cmds.DefaultServiceNameSpace,
+		Service:   NewPublicIndexAPI(m),
+		Public:    true,
+	}
+}
+
+type PublicIndexAPI struct {
+	im *Manager
+}
+
+func NewPublicIndexAPI(im *Manager) *PublicIndexAPI {
+	return &PublicIndexAPI{im}
+}
+
+// GetIndexInfo returns the sync state of the enabled indexes
+//
+// Result:
+// [{
+// "name": "value",             (string)          The name of the index
+// "order": n,                  (numeric)         The order of the tip of the index, -1 when nothing is indexed yet
+// "synced": true|false,        (boolean)         Whether or not the index has caught up with the main chain
+// },...]
+func (api *PublicIndexAPI) GetIndexInfo() (interface{}, error) {
+	infos, err := api.im.Info()
+	if err != nil {
+		return nil, err
+	}
+	result := make([]json.IndexInfoResult, 0, len(infos))
+	for _, info := range infos {
+		result = append(result, json.IndexInfoResult{
+			Name:   info.Name,
+			Order:  info.Order,
+			Synced: info.Synced,
+		})
+	}
+	return result, nil
+}
+
+// tokenIndex returns the token index when it is enabled and synced.
+func (api *PublicIndexAPI) tokenIndex() (*TokenIndex, error) {
+	tokenIndex := api.im.TokenIndex()
+	if tokenIndex == nil {
+		return nil, fmt.Errorf("Token index must be enabled (--tokenindex)")
+	}
+	if err := api.im.CheckSynced(tokenIndex.Name()); err != nil {
+		return nil, err
+	}
+	return tokenIndex, nil
+}
+
+// GetTokenHolders returns the addresses holding a token
+// 1. coinId         (numeric, required)               The coin id of the token
+// 2. count          (numeric, optional, default=100)  The maximum number of holders to return
+// 3. skip           (numeric, optional, default=0)    The number of leading holders to skip
+//
+// The holders are sorted by the balance in descending order.
+func (api *PublicIndexAPI) GetTokenHolders(coinId uint16, count *uint, skip *uint) (interface{}, error) {
+	tokenIndex, err := api.tokenIndex()
+	if err != nil {
+		return nil, err
+	}
+	numRequested := uint32(100)
+	if count != nil {
+		numRequested = uint32(*count)
+	}
+	var numToSkip uint32
+	if skip != nil {
+		numToSkip = uint32(*skip)
+	}
+	holders, numHolders, held, err := tokenIndex.TokenHolders(types.CoinID(coinId), numToSkip, numRequested)
+	if err != nil {
+		return nil, err
+	}
+	result := &json.TokenHoldersResult{
+		CoinId:   coinId,
+		CoinName: types.CoinID(coinId).Name(),
+		Holders:  numHolders,
+		Held:     held,
+		List:     make([]json.TokenHolderResult, 0, len(holders)),
+	}
+	for _, holder := range holders {
+		result.List = append(result.List, json.TokenHolderResult{
+			Address: holder.Address,
+			Balance: holder.Balance,
+		})
+	}
+	return result, nil
+}
+
+// GetTokenHistory returns the mint, unmint, transfer and type update events
+// of a token
+// 1. coinId         (numeric, required)               The coin id of the token
+// 2. count          (numeric, optional, default=100)  The maximum number of events to return
+// 3. skip           (numeric, optional, default=0)    The number of leading events to skip
+// 4. reverse        (boolean, optional, default=false) Return the latest events first
+func (api *PublicIndexAPI) GetTokenHistory(coinId uint16, count *uint, skip *uint, reverse *bool) (interface{}, error) {
+	tokenIndex, err := api.tokenIndex()
+	if err != nil {
+		return nil, err
+	}
+	numRequested := uint32(100)
+	if count != nil {
+		numRequested = uint32(*count)
+	}
+	var numToSkip uint32
+	if skip != nil {
+		numToSkip = uint32(*skip)
+	}
+	events, err := tokenIndex.TokenHistory(types.CoinID(coinId), numToSkip, numRequested,
+		reverse != nil && *reverse)
+	if err != nil {
+		return nil, err
+	}
+	result := make([]json.TokenEventResult, 0, len(events))
+	for _, event := range events {
+		result = append(result, json.TokenEventResult{
+			Type:    event.Type.String(),
+			TxId:    event.TxHash.String(),
+			Order:   event.BlockOrder,
+			TxIndex: event.TxIndex,
+			Amount:  event.Amount,
+		})
+	}
+	return result, nil
+}
+
+// GetTokenBalances returns the balances of the tokens held by an address
+// 1. address        (string, required)                The address
+func (api *PublicIndexAPI) GetTokenBalances(addr string) (interface{}, error) {
+	tokenIndex, err := api.tokenIndex()
+	if err != nil {
+		return nil, err
+	}
+	a, err := address.DecodeAddress(addr)
+	if err != nil {
+		return nil, fmt.Errorf("Invalid address or key: " + err.Error())
+	}
+	balances, err := tokenIndex.TokenBalances(a)
+	if err != nil {
+		return nil, err
+	}
+	result := make([]json.TokenBalanceResult, 0, len(balances))
+	for coinId, balance := range balances {
+		result = append(result, json.TokenBalanceResult{
+			CoinId:   uint16(coinId),
+			CoinName: coinId.Name(),
+			Balance:  balance,
+		})
+	}
+	sort.Slice(result, func(i, j int) bool {
+		return result[i].CoinId < result[j].CoinId
+	})
+	return result, nil
+}
@@ -3,26 +3,35 @@ package index
 import "github.com/Qitmeer/qng/config"
 
 type Config struct {
-	TxIndex        bool
-	AddrIndex      bool
-	VMBlockIndex   bool
-	InvalidTxIndex bool
+	TxIndex         bool
+	AddrIndex       bool
+	VMBlockIndex    bool
+	InvalidTxIndex  bool
+	SpentIndex      bool
+	TokenIndex      bool
+	CrossChainIndex bool
 }
 
 func DefaultConfig() *Config {
 	return &Config{
-		TxIndex:        true,
-		AddrIndex:      false,
-		VMBlockIndex:   false,
-		InvalidTxIndex: false,
+		TxIndex:         true,
+		AddrIndex:       false,
+		VMBlockIndex:    false,
+		InvalidTxIndex:  false,
+		SpentIndex:      false,
+		TokenIndex:      false,
+		CrossChainIndex: false,
 	}
 }
 
 func ToConfig(cfg *config.Config) *Config {
 	return &Config{
-		TxIndex:        true,
-		AddrIndex:      cfg.AddrIndex,
-		VMBlockIndex:   cfg.VMBlockIndex,
-		InvalidTxIndex: cfg.InvalidTxIndex,
+		TxIndex:         true,
+		AddrIndex:       cfg.AddrIndex,
+		VMBlockIndex:    cfg.VMBlockIndex,
+		InvalidTxIndex:  cfg.InvalidTxIndex,
+		SpentIndex:      cfg.SpentIndex,
+		TokenIndex:      cfg.TokenIndex,
+		CrossChainIndex: cfg.CrossChainIndex,
 	}
 }
@@ -0,0 +1,663 @@
+// Copyright (c) 2017-2018 The qitmeer developers
+
+package index
+
+import (
+	"bytes"
+	"encoding/hex"
+	"fmt"
+	"github.com/Qitmeer/qng/common/hash"
+	"github.com/Qitmeer/qng/consensus/model"
+	"github.com/Qitmeer/qng/consensus/vm"
+	"github.com/Qitmeer/qng/core/address"
+	"github.com/Qitmeer/qng/core/types"
+	"github.com/Qitmeer/qng/database"
+	"github.com/Qitmeer/qng/engine/txscript"
+	qcommon "github.com/Qitmeer/qng/meerevm/common"
+	"github.com/Qitmeer/qng/params"
+	"strings"
+)
+
+const (
+	// crossChainIndexName is the human-readable name for the index.
+	crossChainIndexName = "cross chain index"
+
+	// crossChainAddrKeyTypeEVM is the type of the address keys of the
+	// MeerEVM accounts, it follows the address key types of the address
+	// index.
+	crossChainAddrKeyTypeEVM = 0xff
+
+	// evmAddressSize is the size of a MeerEVM account address.
+	evmAddressSize = 20
+
+	// crossChainTransferSize is the size of a serialized transfer without
+	// its address.
+	crossChainTransferSize = 2 + 1 + 2 + 8 + hash.HashSize + 4 + hash.HashSize + evmAddressSize
+
+	// crossChainReplacedSize is the size of a replaced entry of a block
+	// record without its previous transfer.
+	crossChainReplacedSize = hash.HashSize + hash.HashSize + 2
+)
+
+var (
+	// crossChainIndexKey is the key of the cross chain index and the db
+	// bucket used to house it.
+	crossChainIndexKey = []byte("crosschainidx")
+
+	// crossChainTransfersBucketName is the name of the bucket of the
+	// transfers.
+	crossChainTransfersBucketName = []byte("transfers")
+
+	// crossChainAddrsBucketName is the name of the bucket of the transfers
+	// keyed by address.
+	crossChainAddrsBucketName = []byte("addrs")
+
+	// crossChainEVMTxsBucketName is the name of the bucket of the transfers
+	// keyed by the hash of their MeerEVM transaction.
+	crossChainEVMTxsBucketName = []byte("evmtxs")
+
+	// crossChainBlocksBucketName is the name of the bucket of the entries
+	// replaced by the blocks.
+	crossChainBlocksBucketName = []byte("blocks")
+)
+
+// -----------------------------------------------------------------------------
+// The cross chain index links the export and import transactions, which move
+// value between the UTXO ledger and MeerEVM, with their MeerEVM side.  It
+// consists of the following buckets nested in the index bucket:
+//
+//   transfers: <txhash> = <type><valid><coin id><amount><block hash>
+//                         <block order><evm txhash><evm address><address>
+//   addrs:     <addr key><block order><txhash> = <txhash>
+//   evmtxs:    <evm txhash> = <txhash>
+//   blocks:    <block hash> = <txhash><prev evm mapping><size><prev transfer>...
+//
+// The MeerEVM transaction of a transfer is the one carried by the extra data
+// of the header of the MeerEVM block, see NewCrossChainTx.  Its hash only
+// commits to the account and the amount, so the evmtxs bucket maps it to the
+// latest transfer with them.
+//
+// The transfers of the blocks which are invalid when they are connected are
+// indexed as well, so a transfer invalidated by a reorder of the DAG is still
+// found.  A transfer of a valid block is never replaced by one of an invalid
+// block.  The address keys are the ones of the address index, the MeerEVM
+// accounts use the crossChainAddrKeyTypeEVM type, and the block orders are
+// big endian so the transfers are iterated in the main order.
+//
+// The record of a block keeps the entries its transfers replaced, so they are
+// restored when the block is disconnected: the previous transfer of the same
+// transaction with a uint16 size, which is zero when there is none, and the
+// previous transaction of the evmtxs entry, which is the zero hash when there
+// is none.  The blocks are disconnected in the reverse order of connecting, so
+// the entries are restored in the reverse order of the transfers.  Only the
+// blocks which index a transfer have a record.
+// -----------------------------------------------------------------------------
+
+// CrossChainTransfer is an export or an import transaction.
+type CrossChainTransfer struct {
+	TxHash hash.Hash
+	// Type is TxTypeCrossChainExport or TxTypeCrossChainImport.
+	Type   types.TxType
+	Amount types.Amount
+	// Address is the address of the UTXO ledger, it is the receiver of an
+	// import and the owner of the public key receiving an export.
+	Address string
+	// EVMAddress is the account of MeerEVM, it is the receiver of an export
+	// and the sender of an import.
+	EVMAddress [evmAddressSize]byte
+	EVMTxHash  hash.Hash
+
+	// The following fields are only set for the indexed transfers.
+	Valid      bool
+	BlockHash  hash.Hash
+	BlockOrder uint32
+}
+
+// NewCrossChainTransfer returns the transfer of an export or an import
+// transaction.
+func NewCrossChainTransfer(tx *types.Transaction) (*CrossChainTransfer, error) {
+	var pubkey string
+	transfer := &CrossChainTransfer{
+		TxHash: tx.TxHash(),
+		Amount: tx.TxOut[0].Amount,
+	}
+	switch {
+	case types.IsCrossChainExportTx(tx):
+		etx, err := vm.NewExportTx(tx)
+		if err != nil {
+			return nil, err
+		}
+		transfer.Type = types.TxTypeCrossChainExport
+		pubkey = etx.To
+	case types.IsCrossChainImportTx(tx):
+		itx, err := vm.NewImportTx(tx)
+		if err != nil {
+			return nil, err
+		}
+		transfer.Type = types.TxTypeCrossChainImport
+		pubkey = itx.From
+	default:
+		return nil, fmt.Errorf("%s is not a cross chain transfer", transfer.TxHash)
+	}
+	etx, err := qcommon.NewCrossChainTx(transfer.Type, pubkey, uint64(transfer.Amount.Value))
+	if err != nil {
+		return nil, err
+	}
+	copy(transfer.EVMTxHash[:], qcommon.FromEVMHash(etx.Hash())[:])
+	copy(transfer.EVMAddress[:], etx.To().Bytes())
+
+	_, addrs, _, err := txscript.ExtractPkScriptAddrs(tx.TxOut[0].PkScript, params.ActiveNetParams.Params)
+	if err == nil && len(addrs) > 0 {
+		if pka, ok := addrs[0].(*address.SecpPubKeyAddress); ok {
+			transfer.Address = pka.PKHAddress().String()
+		} else {
+			transfer.Address = addrs[0].String()
+		}
+	}
+	return transfer, nil
+}
+
+// EVMAddressKey returns the address key of a MeerEVM account.
+func EVMAddressKey(evmAddress [evmAddressSize]byte) [addrKeySize]byte {
+	var key [addrKeySize]byte
+	key[0] = crossChainAddrKeyTypeEVM
+	copy(key[1:], evmAddress[:])
+	return key
+}
+
+func serializeCrossChainTransfer(transfer *CrossChainTransfer) []byte {
+	serialized := make([]byte, crossChainTransferSize+len(transfer.Address))
+	offset := 0
+	byteOrder.PutUint16(serialized[offset:], uint16(transfer.Type))
+	offset += 2
+	if transfer.Valid {
+		serialized[offset] = 1
+	}
+	offset++
+	byteOrder.PutUint16(serialized[offset:], uint16(transfer.Amount.Id))
+	offset += 2
+	byteOrder.PutUint64(serialized[offset:], uint64(transfer.Amount.Value))
+	offset += 8
+	copy(serialized[offset:], transfer.BlockHash[:])
+	offset += hash.HashSize
+	byteOrder.PutUint32(serialized[offset:], transfer.BlockOrder)
+	offset += 4
+	copy(serialized[offset:], transfer.EVMTxHash[:])
+	offset += hash.HashSize
+	copy(serialized[offset:], transfer.EVMAddress[:])
+	offset += evmAddressSize
+	copy(serialized[offset:], transfer.Address)
+	return serialized
+}
+
+func deserializeCrossChainTransfer(txHash []byte, serialized []byte) (*CrossChainTransfer, error) {
+	if len(serialized) < crossChainTransferSize {
+		return nil, database.Error{
+			ErrorCode: database.ErrCorruption,
+			Description: fmt.Sprintf("corrupt cross chain transfer entry: "+
+				"unexpected length %d", len(serialized)),
+		}
+	}
+	transfer := &CrossChainTransfer{}
+	copy(transfer.TxHash[:], txHash)
+	offset := 0
+	transfer.Type = types.TxType(byteOrder.Uint16(serialized[offset:]))
+	offset += 2
+	transfer.Valid = serialized[offset] != 0
+	offset++
+	transfer.Amount.Id = types.CoinID(byteOrder.Uint16(serialized[offset:]))
+	offset += 2
+	transfer.Amount.Value = int64(byteOrder.Uint64(serialized[offset:]))
+	offset += 8
+	copy(transfer.BlockHash[:], serialized[offset:offset+hash.HashSize])
+	offset += hash.HashSize
+	transfer.BlockOrder = byteOrder.Uint32(serialized[offset:])
+	offset += 4
+	copy(transfer.EVMTxHash[:], serialized[offset:offset+hash.HashSize])
+	offset += hash.HashSize
+	copy(transfer.EVMAddress[:], serialized[offset:offset+evmAddressSize])
+	offset += evmAddressSize
+	transfer.Address = string(serialized[offset:])
+	return transfer, nil
+}
+
+// crossChainAddrKeys returns the address keys of the transfer.
+func crossChainAddrKeys(transfer *CrossChainTransfer) [][addrKeySize]byte {
+	keys := [][addrKeySize]byte{EVMAddressKey(transfer.EVMAddress)}
+	if len(transfer.Address) == 0 {
+		return keys
+	}
+	addr, err := address.DecodeAddress(transfer.Address)
+	if err != nil {
+		return keys
+	}
+	addrKey, err := addrToKey(addr, params.ActiveNetParams.Params)
+	if err != nil {
+		return keys
+	}
+	return append(keys, addrKey)
+}
+
+func crossChainAddrEntryKey(addrKey [addrKeySize]byte, order uint32, txHash *hash.Hash) []byte {
+	key := make([]byte, addrKeySize+4+hash.HashSize)
+	copy(key, addrKey[:])
+	eventKeyOrder.PutUint32(key[addrKeySize:], order)
+	copy(key[addrKeySize+4:], txHash[:])
+	return key
+}
+
+// putAddrEntries adds the address entries of the indexed transfer.
+func putAddrEntries(addrs database.Bucket, transfer *CrossChainTransfer) error {
+	for _, addrKey := range crossChainAddrKeys(transfer) {
+		err := addrs.Put(crossChainAddrEntryKey(addrKey, transfer.BlockOrder, &transfer.TxHash), transfer.TxHash[:])
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// removeAddrEntries removes the address entries of the indexed transfer.
+func removeAddrEntries(addrs database.Bucket, transfer *CrossChainTransfer) error {
+	for _, addrKey := range crossChainAddrKeys(transfer) {
+		err := addrs.Delete(crossChainAddrEntryKey(addrKey, transfer.BlockOrder, &transfer.TxHash))
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// crossChainReplaced is an entry of the record of a block, which keeps the
+// entries replaced by one of its transfers.
+type crossChainReplaced struct {
+	txHash hash.Hash
+	// prevEVMTx is the transaction of the evmtxs entry before the transfer,
+	// it is the zero hash when there was none.
+	prevEVMTx hash.Hash
+	// prevTransfer is the serialized transfer of the transaction before the
+	// transfer, it is empty when there was none.
+	prevTransfer []byte
+}
+
+func serializeCrossChainBlockRecord(record []*crossChainReplaced) []byte {
+	size := 0
+	for _, r := range record {
+		size += crossChainReplacedSize + len(r.prevTransfer)
+	}
+	serialized := make([]byte, 0, size)
+	for _, r := range record {
+		serialized = append(serialized, r.txHash[:]...)
+		serialized = append(serialized, r.prevEVMTx[:]...)
+		var prevSize [2]byte
+		byteOrder.PutUint16(prevSize[:], uint16(len(r.prevTransfer)))
+		serialized = append(serialized, prevSize[:]...)
+		serialized = append(serialized, r.prevTransfer...)
+	}
+	return serialized
+}
+
+func deserializeCrossChainBlockRecord(serialized []byte) ([]*crossChainReplaced, error) {
+	corrupt := database.Error{
+		ErrorCode:   database.ErrCorruption,
+		Description: "corrupt cross chain block record",
+	}
+	var record []*crossChainReplaced
+	for offset := 0; offset < len(serialized); {
+		if len(serialized[offset:]) < crossChainReplacedSize {
+			return nil, corrupt
+		}
+		r := &crossChainReplaced{}
+		copy(r.txHash[:], serialized[offset:])
+		offset += hash.HashSize
+		copy(r.prevEVMTx[:], serialized[offset:])
+		offset += hash.HashSize
+		prevSize := int(byteOrder.Uint16(serialized[offset:]))
+		offset += 2
+		if len(serialized[offset:]) < prevSize {
+			return nil, corrupt
+		}
+		if prevSize > 0 {
+			r.prevTransfer = append([]byte(nil), serialized[offset:offset+prevSize]...)
+		}
+		offset += prevSize
+		record = append(record, r)
+	}
+	return record, nil
+}
+
+// CrossChainIndex implements an index of the export and import transactions.
+type CrossChainIndex struct {
+	db database.DB
+}
+
+// Ensure the CrossChainIndex type implements the Indexer interface.
+var _ Indexer = (*CrossChainIndex)(nil)
+
+// Init is only provided to satisfy the Indexer interface as there is nothing to
+// initialize for this index.
+//
+// This is part of the Indexer interface.
+func (idx *CrossChainIndex) Init(chain model.BlockChain) error {
+	// Nothing to do.
+	return nil
+}
+
+// Key returns the database key to use for the index as a byte slice.
+//
+// This is part of the Indexer interface.
+func (idx *CrossChainIndex) Key() []byte {
+	return crossChainIndexKey
+}
+
+// Name returns the human-readable name of the index.
+//
+// This is part of the Indexer interface.
+func (idx *CrossChainIndex) Name() string {
+	return crossChainIndexName
+}
+
+// Create is invoked when the indexer manager determines the index needs
+// to be created for the first time.  It creates the bucket for the cross
+// chain index and its nested buckets.
+//
+// This is part of the Indexer interface.
+func (idx *CrossChainIndex) Create(dbTx database.Tx) error {
+	index, err := dbTx.Metadata().CreateBucket(crossChainIndexKey)
+	if err != nil {
+		return err
+	}
+	for _, name := range [][]byte{crossChainTransfersBucketName,
+		crossChainAddrsBucketName, crossChainEVMTxsBucketName,
+		crossChainBlocksBucketName} {
+		if _, err := index.CreateBucket(name); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// blockTransfers calls fn for every export and import transaction of the
+// block.
+func blockTransfers(block *types.SerializedBlock, fn func(tx *types.Tx) error) error {
+	for _, tx := range block.Transactions() {
+		if tx.IsDuplicate {
+			continue
+		}
+		if !types.IsCrossChainExportTx(tx.Tx) && !types.IsCrossChainImportTx(tx.Tx) {
+			continue
+		}
+		if err := fn(tx); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// ConnectBlock is invoked by the index manager when a new block has been
+// connected to the main chain.  This indexer adds the export and import
+// transactions in the block and records the entries they replace.
+//
+// This is part of the Indexer interface.
+func (idx *CrossChainIndex) ConnectBlock(dbTx database.Tx, block *types.SerializedBlock, stxos [][]byte, blk model.Block) error {
+	blockHash := block.Hash()
+	order, err := dbFetchOrderByHash(dbTx, blockHash)
+	if err != nil {
+		return err
+	}
+	valid := !blk.GetStatus().KnownInvalid()
+	index := dbTx.Metadata().Bucket(crossChainIndexKey)
+	transfers := index.Bucket(crossChainTransfersBucketName)
+	addrs := index.Bucket(crossChainAddrsBucketName)
+	evmTxs := index.Bucket(crossChainEVMTxsBucketName)
+	var record []*crossChainReplaced
+	err = blockTransfers(block, func(tx *types.Tx) error {
+		transfer, err := NewCrossChainTransfer(tx.Tx)
+		if err != nil {
+			// The block was already validated, so the transaction
+			// can only be malformed in an invalid block.
+			log.Trace(fmt.Sprintf("Skip cross chain transfer %s: %v", tx.Hash(), err))
+			return nil
+		}
+		replaced := &crossChainReplaced{txHash: *tx.Hash()}
+		if serialized := transfers.Get(tx.Hash()[:]); serialized != nil {
+			old, err := deserializeCrossChainTransfer(tx.Hash()[:], serialized)
+			if err != nil {
+				return err
+			}
+			if old.Valid && !valid {
+				return nil
+			}
+			if err := removeAddrEntries(addrs, old); err != nil {
+				return err
+			}
+			replaced.prevTransfer = append([]byte(nil), serialized...)
+		}
+		if prevEVMTx := evmTxs.Get(transfer.EVMTxHash[:]); prevEVMTx != nil {
+			copy(replaced.prevEVMTx[:], prevEVMTx)
+		}
+		record = append(record, replaced)
+
+		transfer.Valid = valid
+		transfer.BlockHash = *blockHash
+		transfer.BlockOrder = order
+		err = transfers.Put(tx.Hash()[:], serializeCrossChainTransfer(transfer))
+		if err != nil {
+			return err
+		}
+		if err := putAddrEntries(addrs, transfer); err != nil {
+			return err
+		}
+		return evmTxs.Put(transfer.EVMTxHash[:], tx.Hash()[:])
+	})
+	if err != nil || len(record) == 0 {
+		return err
+	}
+	return index.Bucket(crossChainBlocksBucketName).Put(blockHash[:], serializeCrossChainBlockRecord(record))
+}
+
+// DisconnectBlock is invoked by the index manager when a block has been
+// disconnected from the main chain.  This indexer removes the export and
+// import transactions which were added by the block and restores the entries
+// they replaced.
+//
+// This is part of the Indexer interface.
+func (idx *CrossChainIndex) DisconnectBlock(dbTx database.Tx, block *types.SerializedBlock, stxos [][]byte) error {
+	blockHash := block.Hash()
+	index := dbTx.Metadata().Bucket(crossChainIndexKey)
+	transfers := index.Bucket(crossChainTransfersBucketName)
+	addrs := index.Bucket(crossChainAddrsBucketName)
+	evmTxs := index.Bucket(crossChainEVMTxsBucketName)
+	blocks := index.Bucket(crossChainBlocksBucketName)
+	serialized := blocks.Get(blockHash[:])
+	if serialized == nil {
+		// The block didn't index any transfer.
+		return nil
+	}
+	record, err := deserializeCrossChainBlockRecord(serialized)
+	if err != nil {
+		return err
+	}
+	for i := len(record) - 1; i >= 0; i-- {
+		r := record[i]
+		serialized := transfers.Get(r.txHash[:])
+		if serialized == nil {
+			continue
+		}
+		transfer, err := deserializeCrossChainTransfer(r.txHash[:], serialized)
+		if err != nil {
+			return err
+		}
+		if !transfer.BlockHash.IsEqual(blockHash) {
+			continue
+		}
+		if err := removeAddrEntries(addrs, transfer); err != nil {
+			return err
+		}
+		if bytes.Equal(evmTxs.Get(transfer.EVMTxHash[:]), r.txHash[:]) {
+			if r.prevEVMTx.IsEqual(&hash.ZeroHash) {
+				err = evmTxs.Delete(transfer.EVMTxHash[:])
+			} else {
+				err = evmTxs.Put(transfer.EVMTxHash[:], r.prevEVMTx[:])
+			}
+			if err != nil {
+				return err
+			}
+		}
+		if len(r.prevTransfer) == 0 {
+			if err := transfers.Delete(r.txHash[:]); err != nil {
+				return err
+			}
+			continue
+		}
+		prev, err := deserializeCrossChainTransfer(r.txHash[:], r.prevTransfer)
+		if err != nil {
+			return err
+		}
+		if err := transfers.Put(r.txHash[:], r.prevTransfer); err != nil {
+			return err
+		}
+		if err := putAddrEntries(addrs, prev); err != nil {
+			return err
+		}
+	}
+	return blocks.Delete(blockHash[:])
+}
+
+// Transfer returns the indexed transfer of the transaction, or nil when it is
+// unknown.
+//
+// This function is safe for concurrent access.
+func (idx *CrossChainIndex) Transfer(txHash *hash.Hash) (*CrossChainTransfer, error) {
+	var transfer *CrossChainTransfer
+	err := idx.db.View(func(dbTx database.Tx) error {
+		transfers := dbTx.Metadata().Bucket(crossChainIndexKey).Bucket(crossChainTransfersBucketName)
+		serialized := transfers.Get(txHash[:])
+		if serialized == nil {
+			return nil
+		}
+		var err error
+		transfer, err = deserializeCrossChainTransfer(txHash[:], serialized)
+		return err
+	})
+	return transfer, err
+}
+
+// TransferByEVMTxHash returns the indexed transfer of the MeerEVM
+// transaction, or nil when it is unknown.
+//
+// This function is safe for concurrent access.
+func (idx *CrossChainIndex) TransferByEVMTxHash(evmTxHash *hash.Hash) (*CrossChainTransfer, error) {
+	var txHash *hash.Hash
+	err := idx.db.View(func(dbTx database.Tx) error {
+		evmTxs := dbTx.Metadata().Bucket(crossChainIndexKey).Bucket(crossChainEVMTxsBucketName)
+		serialized := evmTxs.Get(evmTxHash[:])
+		if serialized == nil {
+			return nil
+		}
+		var err error
+		txHash, err = hash.NewHash(serialized)
+		return err
+	})
+	if err != nil || txHash == nil {
+		return nil, err
+	}
+	return idx.Transfer(txHash)
+}
+
+// TransfersForAddress returns the indexed transfers of the address key in the
+// main order, or in the reverse order when reverse is set.
+//
+// This function is safe for concurrent access.
+func (idx *CrossChainIndex) TransfersForAddress(addrKey [addrKeySize]byte, skip, count uint32, reverse bool) ([]*CrossChainTransfer, error) {
+	result := []*CrossChainTransfer{}
+	err := idx.db.View(func(dbTx database.Tx) error {
+		index := dbTx.Metadata().Bucket(crossChainIndexKey)
+		transfers := index.Bucket(crossChainTransfersBucketName)
+		cursor := index.Bucket(crossChainAddrsBucketName).Cursor()
+		var ok bool
+		if reverse {
+			// Position the cursor on the last transfer of the address.
+			next := addrKey
+			carry := true
+			for i := addrKeySize - 1; i >= 0 && carry; i-- {
+				next[i]++
+				carry = next[i] == 0
+			}
+			if carry || !cursor.Seek(next[:]) {
+				ok = cursor.Last()
+			} else {
+				ok = cursor.Prev()
+			}
+		} else {
+			ok = cursor.Seek(addrKey[:])
+		}
+		for ; ok && uint32(len(result)) < count; ok = advanceCursor(cursor, reverse) {
+			if !bytes.HasPrefix(cursor.Key(), addrKey[:]) {
+				break
+			}
+			if skip > 0 {
+				skip--
+				continue
+			}
+			txHash := cursor.Value()
+			serialized := transfers.Get(txHash)
+			if serialized == nil {
+				continue
+			}
+			transfer, err := deserializeCrossChainTransfer(txHash, serialized)
+			if err != nil {
+				return err
+			}
+			result = append(result, transfer)
+		}
+		return nil
+	})
+	return result, err
+}
+
+// AddressKey returns the address key of an address of the UTXO ledger or of
+// a 0x prefixed MeerEVM account.
+func (idx *CrossChainIndex) AddressKey(addr string) ([addrKeySize]byte, error) {
+	if strings.HasPrefix(addr, "0x") {
+		var evmAddress [evmAddressSize]byte
+		b, err := hex.DecodeString(addr[2:])
+		if err != nil || len(b) != evmAddressSize {
+			return [addrKeySize]byte{}, fmt.Errorf("Invalid MeerEVM address: %s", addr)
+		}
+		copy(evmAddress[:], b)
+		return EVMAddressKey(evmAddress), nil
+	}
+	a, err := address.DecodeAddress(addr)
+	if err != nil {
+		return [addrKeySize]byte{}, err
+	}
+	return addrToKey(a, params.ActiveNetParams.Params)
+}
+
+// InvolvesAddressKey returns whether the address key is one of the addresses
+// of the transfer.
+func (transfer *CrossChainTransfer) InvolvesAddressKey(addrKey [addrKeySize]byte) bool {
+	for _, key := range crossChainAddrKeys(transfer) {
+		if key == addrKey {
+			return true
+		}
+	}
+	return false
+}
+
+// NewCrossChainIndex returns a new instance of an indexer that is used to
+// track the export and import transactions.
+//
+// It implements the Indexer interface which plugs into the IndexManager that
+// in turn is used by the blockchain package.  This allows the index to be
+// seamlessly maintained along with the chain.
+func NewCrossChainIndex(db database.DB) *CrossChainIndex {
+	return &CrossChainIndex{db: db}
+}
+
+// DropCrossChainIndex drops the cross chain index from the provided database
+// if it exists.
+func DropCrossChainIndex(db database.DB, interrupt <-chan struct{}) error {
+	return dropIndex(db, crossChainIndexKey, crossChainIndexName, interrupt)
+}
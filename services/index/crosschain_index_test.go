@@ -0,0 +1,198 @@
+// Copyright (c) 2017-2018 The qitmeer developers
+
+package index
+
+import (
+	"testing"
+
+	"github.com/Qitmeer/qng/common/hash"
+	"github.com/Qitmeer/qng/consensus/model"
+	"github.com/Qitmeer/qng/core/address"
+	"github.com/Qitmeer/qng/core/types"
+	"github.com/Qitmeer/qng/crypto/ecc"
+	"github.com/Qitmeer/qng/database"
+	"github.com/Qitmeer/qng/engine/txscript"
+	"github.com/Qitmeer/qng/params"
+)
+
+// testExportTx returns an export transaction spending the outpoint to the
+// public key of the seed.
+func testExportTx(t *testing.T, seed string, outpoint types.TxOutPoint, value int64) *types.Transaction {
+	_, pub := ecc.Secp256k1.PrivKeyFromBytes(hash.HashB([]byte(seed)))
+	addr, err := address.NewSecpPubKeyAddress(pub.SerializeCompressed(), params.ActiveNetParams.Params)
+	if err != nil {
+		t.Fatal(err)
+	}
+	pkScript, err := txscript.PayToAddrScript(addr)
+	if err != nil {
+		t.Fatal(err)
+	}
+	tx := testSpendTx([]types.TxOutPoint{outpoint}, types.NewTxOutput(types.Amount{Id: types.MEERB, Value: value}, pkScript))
+	if !types.IsCrossChainExportTx(tx) {
+		t.Fatalf("%s isn't an export transaction", tx.TxHash())
+	}
+	return tx
+}
+
+func TestCrossChainIndex(t *testing.T) {
+	idx := NewCrossChainIndex(nil)
+	db := newTestIndexDB(t, idx)
+	idx.db = db
+	valid := &testIndexBlock{}
+	invalid := &testIndexBlock{status: model.StatusInvalid}
+
+	connect := func(block *types.SerializedBlock, blk model.Block) {
+		t.Helper()
+		err := db.Update(func(dbTx database.Tx) error {
+			return idx.ConnectBlock(dbTx, block, nil, blk)
+		})
+		if err != nil {
+			t.Fatal(err)
+		}
+	}
+	disconnect := func(block *types.SerializedBlock) {
+		t.Helper()
+		err := db.Update(func(dbTx database.Tx) error {
+			return idx.DisconnectBlock(dbTx, block, nil)
+		})
+		if err != nil {
+			t.Fatal(err)
+		}
+	}
+	// checkTransfer checks the transfer of the transaction, which is
+	// indexed by the block when it isn't nil, and the transaction of its
+	// MeerEVM transaction.
+	checkTransfer := func(tx *types.Transaction, block *types.SerializedBlock, isValid bool, evmTx *types.Transaction) {
+		t.Helper()
+		txHash := tx.TxHash()
+		transfer, err := idx.Transfer(&txHash)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if block == nil {
+			if transfer != nil {
+				t.Fatalf("%s is indexed by %s", txHash, transfer.BlockHash)
+			}
+			return
+		}
+		if transfer == nil {
+			t.Fatalf("%s isn't indexed", txHash)
+		}
+		if transfer.BlockHash != *block.Hash() || transfer.Valid != isValid {
+			t.Fatalf("%s: got the block %s valid %v, want %s valid %v", txHash,
+				transfer.BlockHash, transfer.Valid, block.Hash(), isValid)
+		}
+		transfers, err := idx.TransfersForAddress(EVMAddressKey(transfer.EVMAddress), 0, 10, false)
+		if err != nil {
+			t.Fatal(err)
+		}
+		found := 0
+		for _, tr := range transfers {
+			if tr.TxHash == txHash {
+				found++
+			}
+		}
+		if found != 1 {
+			t.Fatalf("%s: got %d address entries, want 1", txHash, found)
+		}
+		byEVM, err := idx.TransferByEVMTxHash(&transfer.EVMTxHash)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if evmTx == nil {
+			if byEVM != nil {
+				t.Fatalf("%s: the MeerEVM transaction is mapped to %s", txHash, byEVM.TxHash)
+			}
+			return
+		}
+		if byEVM == nil || byEVM.TxHash != evmTx.TxHash() {
+			t.Fatalf("%s: the MeerEVM transaction isn't mapped to %s", txHash, evmTx.TxHash())
+		}
+	}
+	countAddrEntries := func() int {
+		t.Helper()
+		n := 0
+		err := db.View(func(dbTx database.Tx) error {
+			addrs := dbTx.Metadata().Bucket(crossChainIndexKey).Bucket(crossChainAddrsBucketName)
+			return addrs.ForEach(func(k, v []byte) error {
+				n++
+				return nil
+			})
+		})
+		if err != nil {
+			t.Fatal(err)
+		}
+		return n
+	}
+
+	// The two exports have the same MeerEVM transaction.
+	export := testExportTx(t, "a", testOutPoint("a", 0), 1e8)
+	same := testExportTx(t, "a", testOutPoint("b", 0), 1e8)
+
+	block1 := newTestIndexBlock(t, db, 1, export)
+	connect(block1, invalid)
+	checkTransfer(export, block1, false, export)
+
+	// The transfer of the invalid block is replaced by the one of a valid
+	// block.
+	block2 := newTestIndexBlock(t, db, 2, export)
+	connect(block2, valid)
+	checkTransfer(export, block2, true, export)
+
+	// A valid transfer isn't replaced by the one of an invalid block.
+	block3 := newTestIndexBlock(t, db, 3, export)
+	connect(block3, invalid)
+	checkTransfer(export, block2, true, export)
+
+	block4 := newTestIndexBlock(t, db, 4, same)
+	connect(block4, valid)
+	checkTransfer(same, block4, true, same)
+
+	disconnect(block4)
+	checkTransfer(same, nil, false, nil)
+	checkTransfer(export, block2, true, export)
+
+	disconnect(block3)
+	checkTransfer(export, block2, true, export)
+
+	// The transfer of the first block is restored.
+	disconnect(block2)
+	checkTransfer(export, block1, false, export)
+
+	disconnect(block1)
+	checkTransfer(export, nil, false, nil)
+	if n := countAddrEntries(); n != 0 {
+		t.Fatalf("got %d address entries, want none", n)
+	}
+	err := db.View(func(dbTx database.Tx) error {
+		blocks := dbTx.Metadata().Bucket(crossChainIndexKey).Bucket(crossChainBlocksBucketName)
+		return blocks.ForEach(func(k, v []byte) error {
+			t.Fatalf("the block record %x is left", k)
+			return nil
+		})
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestCrossChainBlockRecord(t *testing.T) {
+	transfer := serializeCrossChainTransfer(&CrossChainTransfer{Address: "addr", BlockOrder: 3})
+	record := []*crossChainReplaced{
+		{txHash: hash.HashH([]byte("a")), prevEVMTx: hash.HashH([]byte("b")), prevTransfer: transfer},
+		{txHash: hash.HashH([]byte("c"))},
+	}
+	serialized := serializeCrossChainBlockRecord(record)
+	got, err := deserializeCrossChainBlockRecord(serialized)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(got) != 2 || got[0].txHash != record[0].txHash || got[0].prevEVMTx != record[0].prevEVMTx ||
+		string(got[0].prevTransfer) != string(transfer) || got[1].txHash != record[1].txHash ||
+		!got[1].prevEVMTx.IsEqual(&hash.ZeroHash) || len(got[1].prevTransfer) != 0 {
+		t.Fatalf("got the record %+v, want %+v", got, record)
+	}
+	if _, err := deserializeCrossChainBlockRecord(serialized[:len(serialized)-1]); err == nil {
+		t.Fatalf("a truncated record is deserialized")
+	}
+}
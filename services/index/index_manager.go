@@ -13,6 +13,7 @@ import (
 	"github.com/Qitmeer/qng/core/types"
 	"github.com/Qitmeer/qng/database"
 	"github.com/Qitmeer/qng/services/common/progresslog"
+	"sync"
 )
 
 // Manager defines an index manager that manages multiple optional indexes and
@@ -25,6 +26,13 @@ type Manager struct {
 	enabledIndexes []Indexer
 	vmblockIndex   *VMBlockIndex
 	invalidtxIndex *InvalidTxIndex
+
+	// syncing holds the names of the indexes which are catching up with
+	// the main chain in the background, vmblockNext is the next order of
+	// the vm block index while it is syncing.
+	syncMtx     sync.RWMutex
+	syncing     map[string]bool
+	vmblockNext uint
 }
 
 // Ensure the Manager type implements the blockchain.IndexManager interface.
@@ -48,6 +56,18 @@ func NewManager(cfg *Config, consensus model.Consensus) *Manager {
 		addrIndex := NewAddrIndex(consensus.DatabaseContext())
 		indexers = append(indexers, addrIndex)
 	}
+	if cfg.SpentIndex {
+		spentIndex := NewSpentIndex(consensus.DatabaseContext())
+		indexers = append(indexers, spentIndex)
+	}
+	if cfg.TokenIndex {
+		tokenIndex := NewTokenIndex(consensus.DatabaseContext())
+		indexers = append(indexers, tokenIndex)
+	}
+	if cfg.CrossChainIndex {
+		crossChainIndex := NewCrossChainIndex(consensus.DatabaseContext())
+		indexers = append(indexers, crossChainIndex)
+	}
 	for _, indexer := range indexers {
 		log.Info(fmt.Sprintf("%s is enabled", indexer.Name()))
 	}
@@ -56,6 +76,7 @@ func NewManager(cfg *Config, consensus model.Consensus) *Manager {
 		db:             consensus.DatabaseContext(),
 		enabledIndexes: indexers,
 		consensus:      consensus,
+		syncing:        map[string]bool{},
 	}
 	if cfg.VMBlockIndex {
 		im.vmblockIndex = NewVMBlockIndex(consensus)
@@ -73,15 +94,33 @@ func NewManager(cfg *Config, consensus model.Consensus) *Manager {
 // time new blocks are being downloaded would lead to an overall longer time to
 // catch up due to the I/O contention.
 //
+// Only the transaction index is caught up here, the other indexes which are
+// behind are caught up in the background once the initialization is done.
+//
 // This is part of the blockchain.IndexManager interface.
 func (m *Manager) Init() error {
+	if err := m.init(); err != nil {
+		return err
+	}
+	if m.syncingCount() > 0 {
+		log.Info(fmt.Sprintf("Catching up %d indexes in the background", m.syncingCount()))
+		go m.syncIndexes()
+	}
+	return nil
+}
+
+func (m *Manager) init() error {
 	interrupt := m.consensus.Interrupt()
 	chain := m.consensus.BlockChain()
 	if m.vmblockIndex != nil {
-		err := m.vmblockIndex.Init()
+		next, err := m.vmblockIndex.Init()
 		if err != nil {
 			return err
 		}
+		if next <= chain.GetMainOrder() {
+			m.vmblockNext = next
+			m.setSyncing(vmblockIndexName, true)
+		}
 	}
 	if m.invalidtxIndex != nil {
 		err := m.invalidtxIndex.Init()
@@ -199,15 +238,18 @@ func (m *Manager) Init() error {
 			}
 			orderShow := int64(order)
 			if order == math.MaxUint32 {
-				lowestOrder = -1
 				indexerOrders[i] = -1
 				orderShow = -1
 			} else {
-				if int64(order) < lowestOrder {
-					lowestOrder = int64(order)
-				}
 				indexerOrders[i] = int64(order)
 			}
+			// Only the transaction index is required by the chain, the
+			// others catch up in the background.
+			if indexerOrders[i] < int64(bestOrder) && indexer.Name() != txIndexName {
+				m.setSyncing(indexer.Name(), true)
+			} else if indexerOrders[i] < lowestOrder {
+				lowestOrder = indexerOrders[i]
+			}
 			log.Debug(fmt.Sprintf("Current %s tip", indexer.Name()),
 				"order", orderShow, "hash", h)
 		}
@@ -255,7 +297,7 @@ func (m *Manager) Init() error {
 		for i, indexer := range m.enabledIndexes {
 			// Skip indexes that don't need to be updated with this
 			// block.
-			if indexerOrders[i] >= order {
+			if indexerOrders[i] >= order || m.isSyncing(indexer.Name()) {
 				continue
 			}
 
@@ -383,6 +425,10 @@ func (m *Manager) ConnectBlock(block *types.SerializedBlock, stxos [][]byte, blk
 	// being connected so they can update accordingly.
 	err := m.db.Update(func(dbTx database.Tx) error {
 		for _, index := range m.enabledIndexes {
+			// The syncing indexes are caught up in the background.
+			if m.isSyncing(index.Name()) {
+				continue
+			}
 			err := dbIndexConnectBlock(dbTx, index, block, stxos, blk)
 			if err != nil {
 				return err
@@ -394,6 +440,10 @@ func (m *Manager) ConnectBlock(block *types.SerializedBlock, stxos [][]byte, blk
 		return err
 	}
 	if m.vmblockIndex != nil {
+		// The syncing vm block index is caught up in the background.
+		if !m.vmblockConnects() {
+			return nil
+		}
 		return m.vmblockIndex.ConnectBlock(block.Hash(), vmbid)
 	}
 	if blk.GetStatus().KnownInvalid() {
@@ -415,6 +465,17 @@ func (m *Manager) DisconnectBlock(block *types.SerializedBlock, stxos [][]byte,
 	// being disconnected so they can update accordingly.
 	err := m.db.Update(func(dbTx database.Tx) error {
 		for _, index := range m.enabledIndexes {
+			// A syncing index only needs to be rolled back when the
+			// block is its tip.
+			if m.isSyncing(index.Name()) {
+				tipHash, _, err := dbFetchIndexerTip(dbTx, index.Key())
+				if err != nil {
+					return err
+				}
+				if !tipHash.IsEqual(block.Hash()) {
+					continue
+				}
+			}
 			err := m.dbIndexDisconnectBlock(dbTx, index, block, stxos)
 			if err != nil {
 				return err
@@ -426,6 +487,9 @@ func (m *Manager) DisconnectBlock(block *types.SerializedBlock, stxos [][]byte,
 		return err
 	}
 	if m.vmblockIndex != nil {
+		if !m.vmblockDisconnects(block.Order()) {
+			return nil
+		}
 		return m.vmblockIndex.DisconnectBlock(block.Hash(), vmbid)
 	}
 	if m.invalidtxIndex != nil {
@@ -436,6 +500,11 @@ func (m *Manager) DisconnectBlock(block *types.SerializedBlock, stxos [][]byte,
 
 func (m *Manager) UpdateMainTip(bh *hash.Hash, order uint64) error {
 	if m.vmblockIndex != nil {
+		// The tip of a syncing vm block index is updated once it has
+		// caught up.
+		if m.isSyncing(vmblockIndexName) {
+			return nil
+		}
 		return m.vmblockIndex.UpdateMainTip(bh, order)
 	}
 	if m.invalidtxIndex != nil {
@@ -580,6 +649,18 @@ func (m *Manager) Drop() error {
 	if err != nil {
 		return err
 	}
+	err = DropSpentIndex(m.db, make(chan struct{}))
+	if err != nil {
+		return err
+	}
+	err = DropTokenIndex(m.db, make(chan struct{}))
+	if err != nil {
+		return err
+	}
+	err = DropCrossChainIndex(m.db, make(chan struct{}))
+	if err != nil {
+		return err
+	}
 	return DropTxIndex(m.db, make(chan struct{}))
 }
 
@@ -599,6 +680,30 @@ func (m *Manager) AddrIndex() *AddrIndex {
 	return nil
 }
 
+func (m *Manager) SpentIndex() *SpentIndex {
+	indexer := m.GetIndex(spentIndexName)
+	if indexer != nil {
+		return indexer.(*SpentIndex)
+	}
+	return nil
+}
+
+func (m *Manager) TokenIndex() *TokenIndex {
+	indexer := m.GetIndex(tokenIndexName)
+	if indexer != nil {
+		return indexer.(*TokenIndex)
+	}
+	return nil
+}
+
+func (m *Manager) CrossChainIndex() *CrossChainIndex {
+	indexer := m.GetIndex(crossChainIndexName)
+	if indexer != nil {
+		return indexer.(*CrossChainIndex)
+	}
+	return nil
+}
+
 func (m *Manager) VMBlockIndex() *VMBlockIndex {
 	return m.vmblockIndex
 }
@@ -0,0 +1,341 @@
+// Copyright (c) 2017-2018 The qitmeer developers
+
+package index
+
+import (
+	"fmt"
+	"github.com/Qitmeer/qng/common/hash"
+	"github.com/Qitmeer/qng/common/math"
+	"github.com/Qitmeer/qng/common/system"
+	"github.com/Qitmeer/qng/consensus/model"
+	"github.com/Qitmeer/qng/core/blockchain/utxo"
+	"github.com/Qitmeer/qng/core/types"
+	"github.com/Qitmeer/qng/database"
+	"github.com/Qitmeer/qng/rpc/client/cmds"
+	"github.com/Qitmeer/qng/services/common/progresslog"
+)
+
+// -----------------------------------------------------------------------------
+// The optional indexes which are behind the main chain when the node starts
+// are caught up in the background while the node is running, only the
+// transaction index is caught up at startup since the chain itself depends on
+// it.  The tip of every index is persisted with each indexed block, so an
+// interrupted catch up resumes from it on the next start.
+//
+// While an index is syncing, the blocks connected by the chain are not passed
+// to it, the background catch up indexes them in order instead.  The blocks
+// disconnected by the chain are still passed to it when they are its tip so a
+// reorganization below its tip rolls it back.  The vm block index has no tip
+// of its own while it is syncing, so the order of the next block it catches up
+// plays that role.  Every catch up step holds the
+// chain lock, so the index always sees a consistent main chain.
+// -----------------------------------------------------------------------------
+
+// ErrIndexSyncing is returned for the requests which depend on an index that
+// is still catching up with the main chain.
+type ErrIndexSyncing struct {
+	Name  string
+	Order int64
+	Main  uint
+}
+
+// ErrorCode returns the code of the RPC error.
+func (e *ErrIndexSyncing) ErrorCode() int {
+	return int(cmds.ErrRPCIndexSyncing.Code)
+}
+
+func (e *ErrIndexSyncing) Error() string {
+	return fmt.Sprintf("%s is syncing (order %d of %d), try again later",
+		e.Name, e.Order, e.Main)
+}
+
+// IsIndexSyncingErr returns whether the error is an ErrIndexSyncing.
+func IsIndexSyncingErr(err error) bool {
+	_, ok := err.(*ErrIndexSyncing)
+	return ok
+}
+
+// IndexInfo describes the sync state of an index.
+type IndexInfo struct {
+	Name string
+	// Order is the order of the tip of the index, -1 when nothing is
+	// indexed yet.
+	Order  int64
+	Synced bool
+}
+
+func (m *Manager) isSyncing(name string) bool {
+	m.syncMtx.RLock()
+	defer m.syncMtx.RUnlock()
+	return m.syncing[name]
+}
+
+func (m *Manager) setSyncing(name string, syncing bool) {
+	m.syncMtx.Lock()
+	defer m.syncMtx.Unlock()
+	if syncing {
+		m.syncing[name] = true
+	} else {
+		delete(m.syncing, name)
+	}
+}
+
+func (m *Manager) syncingCount() int {
+	m.syncMtx.RLock()
+	defer m.syncMtx.RUnlock()
+	return len(m.syncing)
+}
+
+// vmblockConnects returns whether a block connected by the chain is passed to
+// the vm block index, which isn't the case while it is syncing.
+func (m *Manager) vmblockConnects() bool {
+	return !m.isSyncing(vmblockIndexName)
+}
+
+// vmblockDisconnects returns whether a block of the order disconnected by the
+// chain is passed to the vm block index.  A syncing vm block index only has
+// the blocks below its next order, so it is only rolled back, with its next
+// order, when the block is one of them.
+func (m *Manager) vmblockDisconnects(order uint64) bool {
+	if !m.isSyncing(vmblockIndexName) {
+		return true
+	}
+	m.syncMtx.Lock()
+	defer m.syncMtx.Unlock()
+	if order >= uint64(m.vmblockNext) {
+		return false
+	}
+	m.vmblockNext = uint(order)
+	return true
+}
+
+// indexTipOrder returns the order of the tip of the index, -1 when nothing
+// is indexed yet.
+func (m *Manager) indexTipOrder(indexer Indexer) (int64, error) {
+	var order uint32
+	err := m.db.View(func(dbTx database.Tx) error {
+		var err error
+		_, order, err = dbFetchIndexerTip(dbTx, indexer.Key())
+		return err
+	})
+	if err != nil {
+		return 0, err
+	}
+	if order == math.MaxUint32 {
+		return -1, nil
+	}
+	return int64(order), nil
+}
+
+// CheckSynced returns an ErrIndexSyncing when the index with the name is
+// still catching up with the main chain.
+func (m *Manager) CheckSynced(name string) error {
+	if !m.isSyncing(name) {
+		return nil
+	}
+	e := &ErrIndexSyncing{
+		Name:  name,
+		Order: -1,
+		Main:  m.consensus.BlockChain().GetMainOrder(),
+	}
+	if name == vmblockIndexName {
+		m.syncMtx.RLock()
+		e.Order = int64(m.vmblockNext) - 1
+		m.syncMtx.RUnlock()
+	} else if indexer := m.GetIndex(name); indexer != nil {
+		order, err := m.indexTipOrder(indexer)
+		if err != nil {
+			return err
+		}
+		e.Order = order
+	}
+	return e
+}
+
+// Info returns the sync state of the enabled indexes.
+func (m *Manager) Info() ([]IndexInfo, error) {
+	mainOrder := int64(m.consensus.BlockChain().GetMainOrder())
+	infos := []IndexInfo{}
+	for _, indexer := range m.enabledIndexes {
+		order, err := m.indexTipOrder(indexer)
+		if err != nil {
+			return nil, err
+		}
+		infos = append(infos, IndexInfo{
+			Name:   indexer.Name(),
+			Order:  order,
+			Synced: !m.isSyncing(indexer.Name()),
+		})
+	}
+	if m.vmblockIndex != nil {
+		info := IndexInfo{
+			Name:   vmblockIndexName,
+			Order:  mainOrder,
+			Synced: true,
+		}
+		if m.isSyncing(vmblockIndexName) {
+			m.syncMtx.RLock()
+			info.Order = int64(m.vmblockNext) - 1
+			m.syncMtx.RUnlock()
+			info.Synced = false
+		}
+		infos = append(infos, info)
+	}
+	if m.invalidtxIndex != nil {
+		infos = append(infos, IndexInfo{
+			Name:   m.invalidtxIndex.Name(),
+			Order:  mainOrder,
+			Synced: true,
+		})
+	}
+	return infos, nil
+}
+
+// syncIndexes catches up the syncing indexes with the main chain in the
+// background, one block at a time.
+func (m *Manager) syncIndexes() {
+	interrupt := m.consensus.Interrupt()
+	progressLogger := progresslog.NewBlockProgressLogger("Indexed in background", log)
+	for m.syncingCount() > 0 {
+		if system.InterruptRequested(interrupt) {
+			log.Info("Background index catch up is interrupted, it will resume on the next start")
+			return
+		}
+		block, err := m.syncNextBlock()
+		if err != nil {
+			log.Error(fmt.Sprintf("Background index catch up failed: %v", err))
+			return
+		}
+		if block != nil {
+			progressLogger.LogBlockHeight(block)
+		}
+	}
+	log.Info("Indexes caught up in the background")
+}
+
+// syncNextBlock indexes the lowest block of the main chain which is missing
+// from a syncing index and marks the indexes which reached the main tip as
+// synced.  It returns the indexed block, if any.
+func (m *Manager) syncNextBlock() (*types.SerializedBlock, error) {
+	chain := m.consensus.BlockChain()
+	chain.ChainLock()
+	defer chain.ChainUnlock()
+
+	mainOrder := int64(chain.GetMainOrder())
+	nextOrder := int64(math.MaxInt64)
+
+	// The next order of every syncing index.
+	nexts := make([]int64, len(m.enabledIndexes))
+	for i, indexer := range m.enabledIndexes {
+		if !m.isSyncing(indexer.Name()) {
+			continue
+		}
+		var tipHash *hash.Hash
+		var tipOrder uint32
+		err := m.db.View(func(dbTx database.Tx) error {
+			var err error
+			tipHash, tipOrder, err = dbFetchIndexerTip(dbTx, indexer.Key())
+			return err
+		})
+		if err != nil {
+			return nil, err
+		}
+		next := int64(0)
+		if tipOrder != math.MaxUint32 {
+			mainHash := chain.GetBlockHashByOrder(uint(tipOrder))
+			if mainHash == nil || !mainHash.IsEqual(tipHash) {
+				return nil, fmt.Errorf("%s tip (%s, %d) is not in the main chain",
+					indexer.Name(), tipHash, tipOrder)
+			}
+			next = int64(tipOrder) + 1
+		}
+		if next > mainOrder {
+			m.setSyncing(indexer.Name(), false)
+			log.Info(fmt.Sprintf("%s caught up to order %d", indexer.Name(), mainOrder))
+			continue
+		}
+		nexts[i] = next
+		if next < nextOrder {
+			nextOrder = next
+		}
+	}
+	vmblockNext := int64(-1)
+	if m.isSyncing(vmblockIndexName) {
+		m.syncMtx.RLock()
+		vmblockNext = int64(m.vmblockNext)
+		m.syncMtx.RUnlock()
+		if vmblockNext > mainOrder {
+			mainHash := chain.GetBlockHashByOrder(uint(mainOrder))
+			err := m.vmblockIndex.UpdateMainTip(mainHash, uint64(mainOrder))
+			if err != nil {
+				return nil, err
+			}
+			m.setSyncing(vmblockIndexName, false)
+			log.Info(fmt.Sprintf("%s caught up to order %d", vmblockIndexName, mainOrder))
+			vmblockNext = -1
+		} else if vmblockNext < nextOrder {
+			nextOrder = vmblockNext
+		}
+	}
+	if nextOrder == math.MaxInt64 {
+		return nil, nil
+	}
+
+	if vmblockNext == nextOrder {
+		err := m.vmblockIndex.catchUpBlock(uint(nextOrder))
+		if err != nil {
+			return nil, err
+		}
+		m.syncMtx.Lock()
+		m.vmblockNext++
+		m.syncMtx.Unlock()
+	}
+
+	var block *types.SerializedBlock
+	var blk model.Block
+	var spentTxos [][]byte
+	for i, indexer := range m.enabledIndexes {
+		if !m.isSyncing(indexer.Name()) || nexts[i] != nextOrder {
+			continue
+		}
+		if block == nil {
+			var err error
+			block, blk, err = chain.FetchBlockByOrder(uint64(nextOrder))
+			if err != nil {
+				return nil, err
+			}
+			chain.CalculateDAGDuplicateTxs(block)
+		}
+		if spentTxos == nil && indexNeedsInputs(indexer) {
+			var err error
+			spentTxos, err = m.fetchSpendJournalPKS(block)
+			if err != nil {
+				return nil, err
+			}
+		}
+		err := m.db.Update(func(dbTx database.Tx) error {
+			return dbIndexConnectBlock(dbTx, indexer, block, spentTxos, blk)
+		})
+		if err != nil {
+			return nil, err
+		}
+	}
+	return block, nil
+}
+
+// fetchSpendJournalPKS returns the scripts of the outputs spent by the block.
+// It reads the spend journal directly since the caller holds the chain lock.
+func (m *Manager) fetchSpendJournalPKS(block *types.SerializedBlock) ([][]byte, error) {
+	pks := [][]byte{}
+	err := m.db.View(func(dbTx database.Tx) error {
+		stxos, err := utxo.DBFetchSpendJournalEntry(dbTx, block)
+		if err != nil {
+			return err
+		}
+		for _, stxo := range stxos {
+			pks = append(pks, stxo.PkScript)
+		}
+		return nil
+	})
+	return pks, err
+}
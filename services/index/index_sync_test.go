@@ -0,0 +1,71 @@
+// Copyright (c) 2017-2018 The qitmeer developers
+
+package index
+
+import (
+	"testing"
+)
+
+func TestVMBlockIndexSyncing(t *testing.T) {
+	m := &Manager{syncing: map[string]bool{}}
+
+	// A synced index is passed every block.
+	if !m.vmblockConnects() || !m.vmblockDisconnects(10) {
+		t.Fatalf("a synced vm block index isn't passed the blocks")
+	}
+
+	m.vmblockNext = 5
+	m.setSyncing(vmblockIndexName, true)
+	if m.vmblockConnects() {
+		t.Fatalf("a syncing vm block index is passed the connected blocks")
+	}
+
+	// The blocks which aren't caught up yet aren't rolled back.
+	for _, order := range []uint64{7, 5} {
+		if m.vmblockDisconnects(order) {
+			t.Fatalf("a syncing vm block index is passed the block %d above its next order", order)
+		}
+	}
+	if m.vmblockNext != 5 {
+		t.Fatalf("got the next order %d, want 5", m.vmblockNext)
+	}
+
+	// The caught up blocks are rolled back with the next order.
+	for _, order := range []uint64{4, 3} {
+		if !m.vmblockDisconnects(order) {
+			t.Fatalf("a syncing vm block index isn't passed the caught up block %d", order)
+		}
+		if m.vmblockNext != uint(order) {
+			t.Fatalf("got the next order %d, want %d", m.vmblockNext, order)
+		}
+	}
+
+	m.setSyncing(vmblockIndexName, false)
+	if !m.vmblockConnects() {
+		t.Fatalf("a caught up vm block index isn't passed the connected blocks")
+	}
+}
+
+func TestIndexSyncingErr(t *testing.T) {
+	err := error(&ErrIndexSyncing{Name: txIndexName, Order: 3, Main: 10})
+	if !IsIndexSyncingErr(err) {
+		t.Fatalf("%v isn't an index syncing error", err)
+	}
+	if err.Error() != txIndexName+" is syncing (order 3 of 10), try again later" {
+		t.Fatalf("got %q", err.Error())
+	}
+
+	m := &Manager{syncing: map[string]bool{}}
+	if err := m.CheckSynced(txIndexName); err != nil {
+		t.Fatalf("got %v for a synced index", err)
+	}
+	m.setSyncing(txIndexName, true)
+	m.setSyncing(addrIndexName, true)
+	if m.syncingCount() != 2 {
+		t.Fatalf("got %d syncing indexes, want 2", m.syncingCount())
+	}
+	m.setSyncing(txIndexName, false)
+	if m.isSyncing(txIndexName) || !m.isSyncing(addrIndexName) || m.syncingCount() != 1 {
+		t.Fatalf("got the syncing indexes %v", m.syncing)
+	}
+}
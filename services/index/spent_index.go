@@ -0,0 +1,291 @@
+// Copyright (c) 2017-2018 The qitmeer developers
+
+package index
+
+import (
+	"fmt"
+	"github.com/Qitmeer/qng/common/hash"
+	"github.com/Qitmeer/qng/consensus/model"
+	"github.com/Qitmeer/qng/core/types"
+	"github.com/Qitmeer/qng/database"
+)
+
+const (
+	// spentIndexName is the human-readable name for the index.
+	spentIndexName = "spent index"
+
+	// spentKeySize is the size of a serialized outpoint key.
+	spentKeySize = hash.HashSize + 4
+
+	// spentEntrySize is the size of a serialized spent index entry.
+	spentEntrySize = hash.HashSize + 4 + hash.HashSize + 4
+)
+
+var (
+	// spentIndexKey is the key of the spent index and the db bucket used
+	// to house it.
+	spentIndexKey = []byte("spentbyoutpointidx")
+)
+
+// -----------------------------------------------------------------------------
+// The spent index consists of an entry for every outpoint spent by the
+// transactions of the main chain, which maps the outpoint to the transaction
+// input spending it.  The spent outpoints are exactly the ones recorded in the
+// spend journal of the block, so the coinbase, the cross chain transactions
+// and the token transactions other than the mint are not indexed.
+//
+// The serialized format for the keys and values in the spent index bucket is:
+//
+//   <txhash><index> = <spending txhash><input index><block hash><block order>
+//
+//   Field           Type              Size
+//   txhash          hash.Hash    32 bytes
+//   index           uint32            4 bytes
+//   spending txhash hash.Hash    32 bytes
+//   input index     uint32            4 bytes
+//   block hash      hash.Hash    32 bytes
+//   block order     uint32            4 bytes
+//   -----
+//   Total: 108 bytes
+// -----------------------------------------------------------------------------
+
+// SpentEntry describes the transaction input spending an outpoint.
+type SpentEntry struct {
+	TxHash     hash.Hash
+	InputIndex uint32
+	BlockHash  hash.Hash
+	BlockOrder uint32
+}
+
+// spentKey serializes the outpoint for use as a key of the spent index.
+func spentKey(outpoint *types.TxOutPoint) []byte {
+	key := make([]byte, spentKeySize)
+	copy(key, outpoint.Hash[:])
+	byteOrder.PutUint32(key[hash.HashSize:], outpoint.OutIndex)
+	return key
+}
+
+// serializeSpentEntry serializes the spent entry according to the format
+// described above.
+func serializeSpentEntry(entry *SpentEntry) []byte {
+	serialized := make([]byte, spentEntrySize)
+	offset := 0
+	copy(serialized[offset:], entry.TxHash[:])
+	offset += hash.HashSize
+	byteOrder.PutUint32(serialized[offset:], entry.InputIndex)
+	offset += 4
+	copy(serialized[offset:], entry.BlockHash[:])
+	offset += hash.HashSize
+	byteOrder.PutUint32(serialized[offset:], entry.BlockOrder)
+	return serialized
+}
+
+// deserializeSpentEntry decodes the spent entry from the passed serialized
+// bytes.
+func deserializeSpentEntry(serialized []byte) (*SpentEntry, error) {
+	if len(serialized) != spentEntrySize {
+		return nil, database.Error{
+			ErrorCode: database.ErrCorruption,
+			Description: fmt.Sprintf("corrupt spent index entry: "+
+				"unexpected length %d", len(serialized)),
+		}
+	}
+	var entry SpentEntry
+	offset := 0
+	copy(entry.TxHash[:], serialized[offset:offset+hash.HashSize])
+	offset += hash.HashSize
+	entry.InputIndex = byteOrder.Uint32(serialized[offset:])
+	offset += 4
+	copy(entry.BlockHash[:], serialized[offset:offset+hash.HashSize])
+	offset += hash.HashSize
+	entry.BlockOrder = byteOrder.Uint32(serialized[offset:])
+	return &entry, nil
+}
+
+// spentOutpoints calls fn for every outpoint spent by the block together with
+// the spending transaction and the index of its input.  The spend journal of
+// the block has an entry for every spent outpoint and one for every cross
+// chain import transaction, in the order of the transactions, so an error is
+// returned when the stxos don't line up with the block.
+func spentOutpoints(block *types.SerializedBlock, stxos [][]byte, fn func(tx *types.Tx, txInIndex int, outpoint *types.TxOutPoint) error) error {
+	stxoIndex := 0
+	for _, tx := range block.Transactions() {
+		if tx.IsDuplicate || tx.Tx.IsCoinBase() {
+			continue
+		}
+		if types.IsTokenTx(tx.Tx) && !types.IsTokenMintTx(tx.Tx) {
+			continue
+		}
+		if types.IsCrossChainImportTx(tx.Tx) {
+			stxoIndex++
+			continue
+		}
+		if types.IsCrossChainVMTx(tx.Tx) {
+			continue
+		}
+		for txInIndex, txIn := range tx.Tx.TxIn {
+			if txInIndex == 0 && types.IsTokenMintTx(tx.Tx) {
+				continue
+			}
+			if stxoIndex >= len(stxos) {
+				return fmt.Errorf("%s: the spend journal of block %s has %d entries, "+
+					"too few for its transactions", spentIndexName, block.Hash(), len(stxos))
+			}
+			err := fn(tx, txInIndex, &txIn.PreviousOut)
+			if err != nil {
+				return err
+			}
+			stxoIndex++
+		}
+	}
+	if stxoIndex != len(stxos) {
+		return fmt.Errorf("%s: the spend journal of block %s has %d entries, "+
+			"want %d", spentIndexName, block.Hash(), len(stxos), stxoIndex)
+	}
+	return nil
+}
+
+// SpentIndex implements an index which maps every spent outpoint to the
+// transaction input spending it.
+type SpentIndex struct {
+	db database.DB
+}
+
+// Ensure the SpentIndex type implements the Indexer interface.
+var _ Indexer = (*SpentIndex)(nil)
+
+// Ensure the SpentIndex type implements the NeedsInputser interface.
+var _ NeedsInputser = (*SpentIndex)(nil)
+
+// NeedsInputs signals that the index requires the spend journal of the block
+// in order to check the spent outpoints against it.
+//
+// This implements the NeedsInputser interface.
+func (idx *SpentIndex) NeedsInputs() bool {
+	return true
+}
+
+// Init is only provided to satisfy the Indexer interface as there is nothing to
+// initialize for this index.
+//
+// This is part of the Indexer interface.
+func (idx *SpentIndex) Init(chain model.BlockChain) error {
+	// Nothing to do.
+	return nil
+}
+
+// Key returns the database key to use for the index as a byte slice.
+//
+// This is part of the Indexer interface.
+func (idx *SpentIndex) Key() []byte {
+	return spentIndexKey
+}
+
+// Name returns the human-readable name of the index.
+//
+// This is part of the Indexer interface.
+func (idx *SpentIndex) Name() string {
+	return spentIndexName
+}
+
+// Create is invoked when the indexer manager determines the index needs
+// to be created for the first time.  It creates the bucket for the spent
+// index.
+//
+// This is part of the Indexer interface.
+func (idx *SpentIndex) Create(dbTx database.Tx) error {
+	_, err := dbTx.Metadata().CreateBucket(spentIndexKey)
+	return err
+}
+
+// ConnectBlock is invoked by the index manager when a new block has been
+// connected to the main chain.  This indexer adds a mapping for each outpoint
+// spent by the transactions in the block, which must match the stxos of the
+// spend journal of the block.
+//
+// This is part of the Indexer interface.
+func (idx *SpentIndex) ConnectBlock(dbTx database.Tx, block *types.SerializedBlock, stxos [][]byte, blk model.Block) error {
+	// The transactions of an invalid block don't spend anything.
+	if blk.GetStatus().KnownInvalid() {
+		return nil
+	}
+	blockHash := block.Hash()
+	order, err := dbFetchOrderByHash(dbTx, blockHash)
+	if err != nil {
+		return err
+	}
+	bucket := dbTx.Metadata().Bucket(spentIndexKey)
+	return spentOutpoints(block, stxos, func(tx *types.Tx, txInIndex int, outpoint *types.TxOutPoint) error {
+		entry := &SpentEntry{
+			TxHash:     *tx.Hash(),
+			InputIndex: uint32(txInIndex),
+			BlockHash:  *blockHash,
+			BlockOrder: order,
+		}
+		return bucket.Put(spentKey(outpoint), serializeSpentEntry(entry))
+	})
+}
+
+// DisconnectBlock is invoked by the index manager when a block has been
+// disconnected from the main chain.  This indexer removes the outpoints that
+// were spent by the transactions in the block.
+//
+// This is part of the Indexer interface.
+func (idx *SpentIndex) DisconnectBlock(dbTx database.Tx, block *types.SerializedBlock, stxos [][]byte) error {
+	// The spend journal of an invalid block is empty as it spent nothing.
+	if len(stxos) == 0 {
+		return nil
+	}
+	blockHash := block.Hash()
+	bucket := dbTx.Metadata().Bucket(spentIndexKey)
+	return spentOutpoints(block, stxos, func(tx *types.Tx, txInIndex int, outpoint *types.TxOutPoint) error {
+		key := spentKey(outpoint)
+		serialized := bucket.Get(key)
+		if serialized == nil {
+			return nil
+		}
+		entry, err := deserializeSpentEntry(serialized)
+		if err != nil {
+			return err
+		}
+		// Only remove the entries which were added by this block.
+		if !entry.BlockHash.IsEqual(blockHash) {
+			return nil
+		}
+		return bucket.Delete(key)
+	})
+}
+
+// SpendingTx returns the transaction input which spent the outpoint in the
+// main chain, or nil when the outpoint is unspent or unknown.
+//
+// This function is safe for concurrent access.
+func (idx *SpentIndex) SpendingTx(outpoint *types.TxOutPoint) (*SpentEntry, error) {
+	var entry *SpentEntry
+	err := idx.db.View(func(dbTx database.Tx) error {
+		serialized := dbTx.Metadata().Bucket(spentIndexKey).Get(spentKey(outpoint))
+		if serialized == nil {
+			return nil
+		}
+		var err error
+		entry, err = deserializeSpentEntry(serialized)
+		return err
+	})
+	return entry, err
+}
+
+// NewSpentIndex returns a new instance of an indexer that is used to map
+// every spent outpoint to the transaction input spending it.
+//
+// It implements the Indexer interface which plugs into the IndexManager that
+// in turn is used by the blockchain package.  This allows the index to be
+// seamlessly maintained along with the chain.
+func NewSpentIndex(db database.DB) *SpentIndex {
+	return &SpentIndex{db: db}
+}
+
+// DropSpentIndex drops the spent index from the provided database if it
+// exists.
+func DropSpentIndex(db database.DB, interrupt <-chan struct{}) error {
+	return dropIndex(db, spentIndexKey, spentIndexName, interrupt)
+}
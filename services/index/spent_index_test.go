@@ -0,0 +1,193 @@
+// Copyright (c) 2017-2018 The qitmeer developers
+
+package index
+
+import (
+	"math"
+	"testing"
+	"time"
+
+	"github.com/Qitmeer/qng/common/hash"
+	"github.com/Qitmeer/qng/consensus/model"
+	"github.com/Qitmeer/qng/core/types"
+	"github.com/Qitmeer/qng/core/types/pow"
+	"github.com/Qitmeer/qng/database"
+	_ "github.com/Qitmeer/qng/database/ffldb"
+	"github.com/Qitmeer/qng/params"
+)
+
+// testIndexBlock implements the model.Block passed to the indexers.
+type testIndexBlock struct {
+	status model.BlockStatus
+}
+
+func (b *testIndexBlock) GetID() uint {
+	return 0
+}
+
+func (b *testIndexBlock) GetStatus() model.BlockStatus {
+	return b.status
+}
+
+// newTestIndexDB returns a database with the block order buckets and the
+// bucket of the indexer.
+func newTestIndexDB(t *testing.T, indexer Indexer) database.DB {
+	db, err := database.Create("ffldb", t.TempDir(), params.PrivNetParam.Net)
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { db.Close() })
+	err = db.Update(func(dbTx database.Tx) error {
+		meta := dbTx.Metadata()
+		if _, err := meta.CreateBucket(orderByHashIndexBucketName); err != nil {
+			return err
+		}
+		if _, err := meta.CreateBucket(hashByOrderIndexBucketName); err != nil {
+			return err
+		}
+		return indexer.Create(dbTx)
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	return db
+}
+
+// newTestIndexBlock returns a block with a coinbase and the transactions,
+// whose order is put to the database.
+func newTestIndexBlock(t *testing.T, db database.DB, order uint32, txs ...*types.Transaction) *types.SerializedBlock {
+	coinbase := types.NewTransaction()
+	coinbase.AddTxIn(types.NewTxInput(types.NewOutPoint(&hash.ZeroHash, math.MaxUint32), []byte{byte(order)}))
+	coinbase.AddTxOut(types.NewTxOutput(types.Amount{Id: types.MEERA, Value: 1e8}, []byte{}))
+	block := &types.Block{
+		Header: types.BlockHeader{
+			Pow:        pow.GetInstance(pow.MEERXKECCAKV1, 0, []byte{}),
+			Timestamp:  time.Unix(int64(order), 0),
+			Difficulty: order,
+		},
+		Transactions: append([]*types.Transaction{coinbase}, txs...),
+	}
+	sb := types.NewBlock(block)
+	err := db.Update(func(dbTx database.Tx) error {
+		return dbPutBlockOrderIndexEntry(dbTx, sb.Hash(), order)
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	return sb
+}
+
+// testSpendTx returns a transaction spending the outpoints to the outputs, or
+// to a MEER output without any.
+func testSpendTx(outpoints []types.TxOutPoint, txOuts ...*types.TxOutput) *types.Transaction {
+	tx := types.NewTransaction()
+	for i := range outpoints {
+		tx.AddTxIn(types.NewTxInput(&outpoints[i], []byte{}))
+	}
+	if len(txOuts) == 0 {
+		txOuts = []*types.TxOutput{types.NewTxOutput(types.Amount{Id: types.MEERA, Value: 1e7}, []byte{})}
+	}
+	for _, txOut := range txOuts {
+		tx.AddTxOut(txOut)
+	}
+	return tx
+}
+
+func testOutPoint(seed string, index uint32) types.TxOutPoint {
+	return types.TxOutPoint{Hash: hash.HashH([]byte(seed)), OutIndex: index}
+}
+
+func testStxos(n int) [][]byte {
+	stxos := make([][]byte, n)
+	for i := range stxos {
+		stxos[i] = []byte{byte(i)}
+	}
+	return stxos
+}
+
+func TestSpentIndex(t *testing.T) {
+	idx := NewSpentIndex(nil)
+	db := newTestIndexDB(t, idx)
+	idx.db = db
+	valid := &testIndexBlock{}
+
+	a, b, c := testOutPoint("a", 0), testOutPoint("a", 1), testOutPoint("b", 0)
+	block := newTestIndexBlock(t, db, 1, testSpendTx([]types.TxOutPoint{a, b}), testSpendTx([]types.TxOutPoint{c}))
+	connect := func(block *types.SerializedBlock, stxos [][]byte, blk model.Block) error {
+		return db.Update(func(dbTx database.Tx) error {
+			return idx.ConnectBlock(dbTx, block, stxos, blk)
+		})
+	}
+	disconnect := func(block *types.SerializedBlock, stxos [][]byte) error {
+		return db.Update(func(dbTx database.Tx) error {
+			return idx.DisconnectBlock(dbTx, block, stxos)
+		})
+	}
+	checkSpent := func(outpoint types.TxOutPoint, block *types.SerializedBlock, txIndex int, inputIndex uint32) {
+		t.Helper()
+		entry, err := idx.SpendingTx(&outpoint)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if block == nil {
+			if entry != nil {
+				t.Fatalf("%v is spent by %v", outpoint, entry.TxHash)
+			}
+			return
+		}
+		if entry == nil {
+			t.Fatalf("%v isn't spent", outpoint)
+		}
+		tx := block.Transactions()[txIndex]
+		if entry.TxHash != *tx.Hash() || entry.InputIndex != inputIndex ||
+			entry.BlockHash != *block.Hash() {
+			t.Fatalf("%v: got the spent entry %+v", outpoint, entry)
+		}
+	}
+
+	// The spend journal must have an entry for every spent outpoint.
+	for _, n := range []int{0, 2, 4} {
+		if err := connect(block, testStxos(n), valid); err == nil {
+			t.Fatalf("the block is connected with %d stxos", n)
+		}
+	}
+	checkSpent(a, nil, 0, 0)
+
+	// The transactions of an invalid block don't spend anything.
+	if err := connect(block, nil, &testIndexBlock{status: model.StatusInvalid}); err != nil {
+		t.Fatal(err)
+	}
+	checkSpent(a, nil, 0, 0)
+
+	if err := connect(block, testStxos(3), valid); err != nil {
+		t.Fatal(err)
+	}
+	checkSpent(a, block, 1, 0)
+	checkSpent(b, block, 1, 1)
+	checkSpent(c, block, 2, 0)
+	checkSpent(testOutPoint("b", 1), nil, 0, 0)
+
+	// An empty spend journal is the one of an invalid block.
+	if err := disconnect(block, nil); err != nil {
+		t.Fatal(err)
+	}
+	checkSpent(a, block, 1, 0)
+
+	// The outpoint is spent again by another block, which isn't undone
+	// by disconnecting the first one.
+	other := newTestIndexBlock(t, db, 2, testSpendTx([]types.TxOutPoint{c}))
+	if err := connect(other, testStxos(1), valid); err != nil {
+		t.Fatal(err)
+	}
+	if err := disconnect(block, testStxos(3)); err != nil {
+		t.Fatal(err)
+	}
+	checkSpent(a, nil, 0, 0)
+	checkSpent(b, nil, 0, 0)
+	checkSpent(c, other, 1, 0)
+
+	if err := disconnect(other, testStxos(1)); err != nil {
+		t.Fatal(err)
+	}
+	checkSpent(c, nil, 0, 0)
+}
@@ -0,0 +1,845 @@
+// Copyright (c) 2017-2018 The qitmeer developers
+
+package index
+
+import (
+	"bytes"
+	"encoding/binary"
+	"fmt"
+	"github.com/Qitmeer/qng/common/hash"
+	"github.com/Qitmeer/qng/consensus/model"
+	"github.com/Qitmeer/qng/core/types"
+	"github.com/Qitmeer/qng/database"
+	"github.com/Qitmeer/qng/engine/txscript"
+	"github.com/Qitmeer/qng/params"
+	"sort"
+)
+
+const (
+	// tokenIndexName is the human-readable name for the index.
+	tokenIndexName = "token index"
+
+	// tokenOutKeySize is the size of a serialized outpoint key.
+	tokenOutKeySize = hash.HashSize + 4
+
+	// tokenEventKeySize is the size of a serialized event key.
+	tokenEventKeySize = 2 + 4 + 4
+
+	// tokenEventSize is the size of a serialized event.
+	tokenEventSize = 1 + hash.HashSize + 8
+)
+
+var (
+	// tokenIndexKey is the key of the token index and the db bucket used
+	// to house it.
+	tokenIndexKey = []byte("tokenidx")
+
+	// tokenOutsBucketName is the name of the bucket of the token outputs.
+	tokenOutsBucketName = []byte("outs")
+
+	// tokenHoldersBucketName is the name of the bucket of the balances
+	// keyed by coin id and address.
+	tokenHoldersBucketName = []byte("holders")
+
+	// tokenAddrBalancesBucketName is the name of the bucket of the balances
+	// keyed by address and coin id.
+	tokenAddrBalancesBucketName = []byte("addrbalances")
+
+	// tokenEventsBucketName is the name of the bucket of the events.
+	tokenEventsBucketName = []byte("events")
+
+	// tokenBlocksBucketName is the name of the bucket of the events added
+	// by every indexed block.
+	tokenBlocksBucketName = []byte("blocks")
+
+	// eventKeyOrder is the byte order of the event keys, they are big
+	// endian so the events of a coin are iterated in the main order.
+	eventKeyOrder = binary.BigEndian
+)
+
+// TokenEventType is the type of the activity of a token.
+type TokenEventType byte
+
+const (
+	TokenEventNew TokenEventType = iota
+	TokenEventRenew
+	TokenEventValidate
+	TokenEventInvalidate
+	TokenEventMint
+	TokenEventUnmint
+	TokenEventTransfer
+	TokenEventFees
+)
+
+var tokenEventTypeStrings = map[TokenEventType]string{
+	TokenEventNew:        "new",
+	TokenEventRenew:      "renew",
+	TokenEventValidate:   "validate",
+	TokenEventInvalidate: "invalidate",
+	TokenEventMint:       "mint",
+	TokenEventUnmint:     "unmint",
+	TokenEventTransfer:   "transfer",
+	TokenEventFees:       "fees",
+}
+
+func (t TokenEventType) String() string {
+	if s, ok := tokenEventTypeStrings[t]; ok {
+		return s
+	}
+	return fmt.Sprintf("unknown(%d)", byte(t))
+}
+
+// -----------------------------------------------------------------------------
+// The token index tracks the activity and the holders of the tokens, which
+// are the coins with an id above QitmeerReservedID.  It consists of the
+// following buckets nested in the index bucket:
+//
+//   outs:         <txhash><index> = <coin id><value><addr key><address>
+//   holders:      <coin id><addr key> = <balance><address>
+//   addrbalances: <addr key><coin id> = <balance>
+//   events:       <coin id><block order><tx index> = <type><txhash><amount>
+//   blocks:       <block hash> = <spent count><spent out>...<event key>...
+//
+// The outs bucket only keeps the unspent token outputs.  The outputs spent by
+// a block are removed from it and kept in the record of the block instead,
+// each one as <txhash><index><size><out> with a uint16 size, so they are
+// restored when the block is disconnected.  The spent count is a uint32 and
+// is written even when it is zero, so the record of an indexed block is never
+// empty and its presence marks the block as indexed.
+//
+// The spent outputs are the ones of the spend journal of the block, except
+// for the unmint transactions which destroy their token inputs without being
+// recorded in it.  The value of a coinbase output of a token includes the
+// token fees of its block, the same as the spend journal of the chain.  The
+// outputs which don't pay to a single supported address have no holder, they
+// are still counted by the events.
+//
+// The coin ids, block orders and tx indexes of the event keys are big endian
+// so the events of a coin are iterated in the main order, the other numbers
+// use the byte order of the package.  The event amount is the minted amount,
+// the destroyed amount of an unmint, the transferred amount (the outputs of
+// the coin) of a transfer and the collected fees of a coinbase.
+// -----------------------------------------------------------------------------
+
+// TokenEvent is an activity of a token.
+type TokenEvent struct {
+	CoinId     types.CoinID
+	Type       TokenEventType
+	TxHash     hash.Hash
+	BlockOrder uint32
+	TxIndex    uint32
+	Amount     int64
+}
+
+// TokenHolder is the balance of a token held by an address.
+type TokenHolder struct {
+	Address string
+	Balance int64
+}
+
+// tokenOut is a token output of the outs bucket.
+type tokenOut struct {
+	coinId  types.CoinID
+	value   int64
+	addrKey [addrKeySize]byte
+	address string
+}
+
+func serializeTokenOut(out *tokenOut) []byte {
+	serialized := make([]byte, 2+8+addrKeySize+len(out.address))
+	byteOrder.PutUint16(serialized[0:], uint16(out.coinId))
+	byteOrder.PutUint64(serialized[2:], uint64(out.value))
+	copy(serialized[10:], out.addrKey[:])
+	copy(serialized[10+addrKeySize:], out.address)
+	return serialized
+}
+
+func deserializeTokenOut(serialized []byte) (*tokenOut, error) {
+	if len(serialized) < 2+8+addrKeySize {
+		return nil, database.Error{
+			ErrorCode: database.ErrCorruption,
+			Description: fmt.Sprintf("corrupt token output entry: "+
+				"unexpected length %d", len(serialized)),
+		}
+	}
+	out := &tokenOut{
+		coinId:  types.CoinID(byteOrder.Uint16(serialized[0:])),
+		value:   int64(byteOrder.Uint64(serialized[2:])),
+		address: string(serialized[10+addrKeySize:]),
+	}
+	copy(out.addrKey[:], serialized[10:10+addrKeySize])
+	return out, nil
+}
+
+// tokenBlockRecord is the record of an indexed block.
+type tokenBlockRecord struct {
+	spentKeys [][]byte
+	spentOuts [][]byte
+	eventKeys [][]byte
+}
+
+// addSpent adds the serialized token output spent by the block.
+func (r *tokenBlockRecord) addSpent(key []byte, serialized []byte) {
+	r.spentKeys = append(r.spentKeys, key)
+	r.spentOuts = append(r.spentOuts, append([]byte(nil), serialized...))
+}
+
+func serializeTokenBlockRecord(r *tokenBlockRecord) []byte {
+	size := 4 + len(r.eventKeys)*tokenEventKeySize
+	for _, out := range r.spentOuts {
+		size += tokenOutKeySize + 2 + len(out)
+	}
+	serialized := make([]byte, 4, size)
+	byteOrder.PutUint32(serialized, uint32(len(r.spentOuts)))
+	for i, out := range r.spentOuts {
+		serialized = append(serialized, r.spentKeys[i]...)
+		var outSize [2]byte
+		byteOrder.PutUint16(outSize[:], uint16(len(out)))
+		serialized = append(serialized, outSize[:]...)
+		serialized = append(serialized, out...)
+	}
+	for _, key := range r.eventKeys {
+		serialized = append(serialized, key...)
+	}
+	return serialized
+}
+
+func deserializeTokenBlockRecord(serialized []byte) (*tokenBlockRecord, error) {
+	corrupt := database.Error{
+		ErrorCode:   database.ErrCorruption,
+		Description: "corrupt token block record",
+	}
+	if len(serialized) < 4 {
+		return nil, corrupt
+	}
+	r := &tokenBlockRecord{}
+	numSpent := byteOrder.Uint32(serialized)
+	offset := 4
+	for i := uint32(0); i < numSpent; i++ {
+		if len(serialized[offset:]) < tokenOutKeySize+2 {
+			return nil, corrupt
+		}
+		key := serialized[offset : offset+tokenOutKeySize]
+		offset += tokenOutKeySize
+		outSize := int(byteOrder.Uint16(serialized[offset:]))
+		offset += 2
+		if len(serialized[offset:]) < outSize {
+			return nil, corrupt
+		}
+		r.spentKeys = append(r.spentKeys, key)
+		r.spentOuts = append(r.spentOuts, serialized[offset:offset+outSize])
+		offset += outSize
+	}
+	if len(serialized[offset:])%tokenEventKeySize != 0 {
+		return nil, corrupt
+	}
+	for ; offset < len(serialized); offset += tokenEventKeySize {
+		r.eventKeys = append(r.eventKeys, serialized[offset:offset+tokenEventKeySize])
+	}
+	return r, nil
+}
+
+func tokenOutKey(outpoint *types.TxOutPoint) []byte {
+	key := make([]byte, tokenOutKeySize)
+	copy(key, outpoint.Hash[:])
+	byteOrder.PutUint32(key[hash.HashSize:], outpoint.OutIndex)
+	return key
+}
+
+func tokenCoinPrefix(coinId types.CoinID) []byte {
+	prefix := make([]byte, 2)
+	eventKeyOrder.PutUint16(prefix, uint16(coinId))
+	return prefix
+}
+
+func tokenEventKey(coinId types.CoinID, order uint32, txIndex uint32) []byte {
+	key := make([]byte, tokenEventKeySize)
+	eventKeyOrder.PutUint16(key[0:], uint16(coinId))
+	eventKeyOrder.PutUint32(key[2:], order)
+	eventKeyOrder.PutUint32(key[6:], txIndex)
+	return key
+}
+
+func serializeTokenEvent(event *TokenEvent) []byte {
+	serialized := make([]byte, tokenEventSize)
+	serialized[0] = byte(event.Type)
+	copy(serialized[1:], event.TxHash[:])
+	byteOrder.PutUint64(serialized[1+hash.HashSize:], uint64(event.Amount))
+	return serialized
+}
+
+func deserializeTokenEvent(key []byte, serialized []byte) (*TokenEvent, error) {
+	if len(key) != tokenEventKeySize || len(serialized) != tokenEventSize {
+		return nil, database.Error{
+			ErrorCode:   database.ErrCorruption,
+			Description: "corrupt token event entry",
+		}
+	}
+	event := &TokenEvent{
+		CoinId:     types.CoinID(eventKeyOrder.Uint16(key[0:])),
+		BlockOrder: eventKeyOrder.Uint32(key[2:]),
+		TxIndex:    eventKeyOrder.Uint32(key[6:]),
+		Type:       TokenEventType(serialized[0]),
+		Amount:     int64(byteOrder.Uint64(serialized[1+hash.HashSize:])),
+	}
+	copy(event.TxHash[:], serialized[1:1+hash.HashSize])
+	return event, nil
+}
+
+// isTokenCoin returns whether the coin is a token.
+func isTokenCoin(coinId types.CoinID) bool {
+	return coinId > types.QitmeerReservedID
+}
+
+// tokenBuckets are the buckets of the token index.
+type tokenBuckets struct {
+	outs         database.Bucket
+	holders      database.Bucket
+	addrBalances database.Bucket
+	events       database.Bucket
+	blocks       database.Bucket
+}
+
+func fetchTokenBuckets(dbTx database.Tx) *tokenBuckets {
+	index := dbTx.Metadata().Bucket(tokenIndexKey)
+	return &tokenBuckets{
+		outs:         index.Bucket(tokenOutsBucketName),
+		holders:      index.Bucket(tokenHoldersBucketName),
+		addrBalances: index.Bucket(tokenAddrBalancesBucketName),
+		events:       index.Bucket(tokenEventsBucketName),
+		blocks:       index.Bucket(tokenBlocksBucketName),
+	}
+}
+
+// updateBalance adds the delta to the balance of the token held by the
+// address of the output, the balances which drop to zero are removed.
+func (b *tokenBuckets) updateBalance(out *tokenOut, delta int64) error {
+	if len(out.address) == 0 {
+		return nil
+	}
+	holderKey := make([]byte, 0, 2+addrKeySize)
+	holderKey = append(holderKey, tokenCoinPrefix(out.coinId)...)
+	holderKey = append(holderKey, out.addrKey[:]...)
+	addrKey := make([]byte, 0, addrKeySize+2)
+	addrKey = append(addrKey, out.addrKey[:]...)
+	addrKey = append(addrKey, tokenCoinPrefix(out.coinId)...)
+	balance := int64(0)
+	if serialized := b.holders.Get(holderKey); len(serialized) >= 8 {
+		balance = int64(byteOrder.Uint64(serialized))
+	}
+	balance += delta
+	if balance == 0 {
+		if err := b.holders.Delete(holderKey); err != nil {
+			return err
+		}
+		return b.addrBalances.Delete(addrKey)
+	}
+	serialized := make([]byte, 8+len(out.address))
+	byteOrder.PutUint64(serialized, uint64(balance))
+	copy(serialized[8:], out.address)
+	if err := b.holders.Put(holderKey, serialized); err != nil {
+		return err
+	}
+	return b.addrBalances.Put(addrKey, serialized[:8])
+}
+
+// TokenIndex implements an index of the activity and the holders of the
+// tokens.
+type TokenIndex struct {
+	db          database.DB
+	chainParams *params.Params
+}
+
+// Ensure the TokenIndex type implements the Indexer interface.
+var _ Indexer = (*TokenIndex)(nil)
+
+// Ensure the TokenIndex type implements the NeedsInputser interface.
+var _ NeedsInputser = (*TokenIndex)(nil)
+
+// NeedsInputs signals that the index requires the spend journal of the block
+// in order to know the outputs spent by it.
+//
+// This implements the NeedsInputser interface.
+func (idx *TokenIndex) NeedsInputs() bool {
+	return true
+}
+
+// Init is only provided to satisfy the Indexer interface as there is nothing to
+// initialize for this index.
+//
+// This is part of the Indexer interface.
+func (idx *TokenIndex) Init(chain model.BlockChain) error {
+	// Nothing to do.
+	return nil
+}
+
+// Key returns the database key to use for the index as a byte slice.
+//
+// This is part of the Indexer interface.
+func (idx *TokenIndex) Key() []byte {
+	return tokenIndexKey
+}
+
+// Name returns the human-readable name of the index.
+//
+// This is part of the Indexer interface.
+func (idx *TokenIndex) Name() string {
+	return tokenIndexName
+}
+
+// Create is invoked when the indexer manager determines the index needs
+// to be created for the first time.  It creates the bucket for the token
+// index and its nested buckets.
+//
+// This is part of the Indexer interface.
+func (idx *TokenIndex) Create(dbTx database.Tx) error {
+	index, err := dbTx.Metadata().CreateBucket(tokenIndexKey)
+	if err != nil {
+		return err
+	}
+	for _, name := range [][]byte{tokenOutsBucketName, tokenHoldersBucketName,
+		tokenAddrBalancesBucketName, tokenEventsBucketName, tokenBlocksBucketName} {
+		if _, err := index.CreateBucket(name); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// newTokenOut returns the token output for the passed output, the holder is
+// left empty unless it pays to a single supported address.
+func (idx *TokenIndex) newTokenOut(txOut *types.TxOutput, value int64) *tokenOut {
+	out := &tokenOut{coinId: txOut.Amount.Id, value: value}
+	_, addrs, _, err := txscript.ExtractPkScriptAddrs(txOut.PkScript, idx.chainParams)
+	if err != nil || len(addrs) != 1 {
+		return out
+	}
+	addrKey, err := addrToKey(addrs[0], idx.chainParams)
+	if err != nil {
+		return out
+	}
+	out.addrKey = addrKey
+	out.address = addrs[0].String()
+	return out
+}
+
+// tokenAmounts are the amounts of the tokens spent or created by a
+// transaction.
+type tokenAmounts map[types.CoinID]int64
+
+// connectTx spends the token outputs of the transaction inputs and adds its
+// token outputs.  The spent outputs are removed and added to the record of
+// the block.  The fees are added to the values of the coinbase outputs.
+func (idx *TokenIndex) connectTx(b *tokenBuckets, tx *types.Tx, spent map[types.TxOutPoint]struct{}, fees tokenAmounts, record *tokenBlockRecord) (tokenAmounts, tokenAmounts, error) {
+	in, out := tokenAmounts{}, tokenAmounts{}
+	msgTx := tx.Tx
+	if !msgTx.IsCoinBase() {
+		for _, txIn := range msgTx.TxIn {
+			if _, ok := spent[txIn.PreviousOut]; !ok && !types.IsTokenUnmintTx(msgTx) {
+				continue
+			}
+			key := tokenOutKey(&txIn.PreviousOut)
+			serialized := b.outs.Get(key)
+			if serialized == nil {
+				continue
+			}
+			entry, err := deserializeTokenOut(serialized)
+			if err != nil {
+				return nil, nil, err
+			}
+			record.addSpent(key, serialized)
+			if err := b.outs.Delete(key); err != nil {
+				return nil, nil, err
+			}
+			if err := b.updateBalance(entry, -entry.value); err != nil {
+				return nil, nil, err
+			}
+			in[entry.coinId] += entry.value
+		}
+	}
+	for txOutIdx, txOut := range msgTx.TxOut {
+		if !isTokenCoin(txOut.Amount.Id) {
+			continue
+		}
+		value := txOut.Amount.Value
+		if msgTx.IsCoinBase() {
+			value += fees[txOut.Amount.Id]
+		}
+		entry := idx.newTokenOut(txOut, value)
+		key := tokenOutKey(types.NewOutPoint(tx.Hash(), uint32(txOutIdx)))
+		if err := b.outs.Put(key, serializeTokenOut(entry)); err != nil {
+			return nil, nil, err
+		}
+		if err := b.updateBalance(entry, value); err != nil {
+			return nil, nil, err
+		}
+		out[txOut.Amount.Id] += value
+	}
+	return in, out, nil
+}
+
+// disconnectTx removes the token outputs of the transaction which are still
+// unspent.
+func (idx *TokenIndex) disconnectTx(b *tokenBuckets, tx *types.Tx) error {
+	for txOutIdx := range tx.Tx.TxOut {
+		key := tokenOutKey(types.NewOutPoint(tx.Hash(), uint32(txOutIdx)))
+		serialized := b.outs.Get(key)
+		if serialized == nil {
+			continue
+		}
+		entry, err := deserializeTokenOut(serialized)
+		if err != nil {
+			return err
+		}
+		if err := b.updateBalance(entry, -entry.value); err != nil {
+			return err
+		}
+		if err := b.outs.Delete(key); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// isTokenTypeUpdateTx returns whether the transaction updates a token type,
+// these transactions don't spend or create any coin.
+func isTokenTypeUpdateTx(tx *types.Transaction) bool {
+	return types.IsTokenNewTx(tx) || types.IsTokenRenewTx(tx) ||
+		types.IsTokenValidateTx(tx) || types.IsTokenInvalidateTx(tx)
+}
+
+// tokenTypeEvent returns the event of a token type update transaction.
+func tokenTypeEvent(tx *types.Transaction) (*TokenEvent, bool) {
+	script, err := txscript.ParsePkScript(tx.TxOut[0].PkScript)
+	if err != nil {
+		return nil, false
+	}
+	tnScript, ok := script.(*txscript.TokenScript)
+	if !ok {
+		return nil, false
+	}
+	event := &TokenEvent{CoinId: tnScript.GetCoinId()}
+	switch {
+	case types.IsTokenNewTx(tx):
+		event.Type = TokenEventNew
+	case types.IsTokenRenewTx(tx):
+		event.Type = TokenEventRenew
+	case types.IsTokenValidateTx(tx):
+		event.Type = TokenEventValidate
+	default:
+		event.Type = TokenEventInvalidate
+	}
+	return event, true
+}
+
+// tokenTxEvents returns the events of a transaction which spends and creates
+// the passed token amounts.
+func tokenTxEvents(tx *types.Transaction, in, out tokenAmounts) []*TokenEvent {
+	coins := map[types.CoinID]struct{}{}
+	for coinId := range in {
+		coins[coinId] = struct{}{}
+	}
+	for coinId := range out {
+		coins[coinId] = struct{}{}
+	}
+	events := make([]*TokenEvent, 0, len(coins))
+	for coinId := range coins {
+		event := &TokenEvent{CoinId: coinId}
+		switch {
+		case tx.IsCoinBase():
+			event.Type = TokenEventFees
+			event.Amount = out[coinId]
+		case types.IsTokenMintTx(tx):
+			event.Type = TokenEventMint
+			event.Amount = out[coinId] - in[coinId]
+		case types.IsTokenUnmintTx(tx):
+			event.Type = TokenEventUnmint
+			event.Amount = in[coinId] - out[coinId]
+		default:
+			event.Type = TokenEventTransfer
+			event.Amount = out[coinId]
+		}
+		events = append(events, event)
+	}
+	sort.Slice(events, func(i, j int) bool {
+		return events[i].CoinId < events[j].CoinId
+	})
+	return events
+}
+
+// ConnectBlock is invoked by the index manager when a new block has been
+// connected to the main chain.  This indexer updates the token outputs and
+// balances and adds the events of the transactions in the block.
+//
+// This is part of the Indexer interface.
+func (idx *TokenIndex) ConnectBlock(dbTx database.Tx, block *types.SerializedBlock, stxos [][]byte, blk model.Block) error {
+	// The transactions of an invalid block don't spend or create anything.
+	if blk.GetStatus().KnownInvalid() {
+		return nil
+	}
+	blockHash := block.Hash()
+	order, err := dbFetchOrderByHash(dbTx, blockHash)
+	if err != nil {
+		return err
+	}
+	spent := map[types.TxOutPoint]struct{}{}
+	err = spentOutpoints(block, stxos, func(tx *types.Tx, txInIndex int, outpoint *types.TxOutPoint) error {
+		spent[*outpoint] = struct{}{}
+		return nil
+	})
+	if err != nil {
+		return err
+	}
+	b := fetchTokenBuckets(dbTx)
+	record := &tokenBlockRecord{}
+	addEvents := func(txIdx int, tx *types.Tx, events []*TokenEvent) error {
+		for _, event := range events {
+			event.TxHash = *tx.Hash()
+			key := tokenEventKey(event.CoinId, order, uint32(txIdx))
+			if err := b.events.Put(key, serializeTokenEvent(event)); err != nil {
+				return err
+			}
+			record.eventKeys = append(record.eventKeys, key)
+		}
+		return nil
+	}
+
+	// The coinbase is connected last since its outputs collect the fees of
+	// the other transactions, the fees are computed the same as
+	// CalculateFees of the chain.
+	fees := tokenAmounts{}
+	txs := block.Transactions()
+	for txIdx := 1; txIdx < len(txs); txIdx++ {
+		tx := txs[txIdx]
+		if tx.IsDuplicate || tx.Tx.IsCoinBase() {
+			continue
+		}
+		if isTokenTypeUpdateTx(tx.Tx) {
+			if event, ok := tokenTypeEvent(tx.Tx); ok {
+				if err := addEvents(txIdx, tx, []*TokenEvent{event}); err != nil {
+					return err
+				}
+			}
+			continue
+		}
+		in, out, err := idx.connectTx(b, tx, spent, nil, record)
+		if err != nil {
+			return err
+		}
+		for coinId, value := range in {
+			fees[coinId] += value
+		}
+		for coinId, value := range out {
+			fees[coinId] -= value
+		}
+		if err := addEvents(txIdx, tx, tokenTxEvents(tx.Tx, in, out)); err != nil {
+			return err
+		}
+	}
+	for coinId, fee := range fees {
+		if fee < 0 {
+			fees[coinId] = 0
+		}
+	}
+	if len(txs) > 0 && !txs[0].IsDuplicate {
+		_, out, err := idx.connectTx(b, txs[0], spent, fees, record)
+		if err != nil {
+			return err
+		}
+		if err := addEvents(0, txs[0], tokenTxEvents(txs[0].Tx, nil, out)); err != nil {
+			return err
+		}
+	}
+
+	// The record of the block is added even without any spent output or
+	// event, it marks the block as indexed for DisconnectBlock.
+	return b.blocks.Put(blockHash[:], serializeTokenBlockRecord(record))
+}
+
+// DisconnectBlock is invoked by the index manager when a block has been
+// disconnected from the main chain.  This indexer restores the token outputs
+// and balances and removes the events of the transactions in the block.
+//
+// This is part of the Indexer interface.
+func (idx *TokenIndex) DisconnectBlock(dbTx database.Tx, block *types.SerializedBlock, stxos [][]byte) error {
+	blockHash := block.Hash()
+	b := fetchTokenBuckets(dbTx)
+	serialized := b.blocks.Get(blockHash[:])
+	if serialized == nil {
+		// The block was invalid when it was connected.
+		return nil
+	}
+	record, err := deserializeTokenBlockRecord(append([]byte(nil), serialized...))
+	if err != nil {
+		return err
+	}
+
+	// The spent outputs are restored before the outputs of the block are
+	// removed, since the outputs spent in the same block are restored too.
+	for i, key := range record.spentKeys {
+		entry, err := deserializeTokenOut(record.spentOuts[i])
+		if err != nil {
+			return err
+		}
+		if err := b.outs.Put(key, record.spentOuts[i]); err != nil {
+			return err
+		}
+		if err := b.updateBalance(entry, entry.value); err != nil {
+			return err
+		}
+	}
+	for txIdx, tx := range block.Transactions() {
+		if tx.IsDuplicate || (txIdx > 0 && tx.Tx.IsCoinBase()) || isTokenTypeUpdateTx(tx.Tx) {
+			continue
+		}
+		if err := idx.disconnectTx(b, tx); err != nil {
+			return err
+		}
+	}
+	for _, key := range record.eventKeys {
+		if err := b.events.Delete(key); err != nil {
+			return err
+		}
+	}
+	return b.blocks.Delete(blockHash[:])
+}
+
+// TokenHolders returns the addresses holding the token sorted by the balance
+// in descending order, along with the total count of the holders and the sum
+// of their balances.
+//
+// This function is safe for concurrent access.
+func (idx *TokenIndex) TokenHolders(coinId types.CoinID, skip, count uint32) ([]TokenHolder, uint32, int64, error) {
+	holders := []TokenHolder{}
+	var total int64
+	err := idx.db.View(func(dbTx database.Tx) error {
+		prefix := tokenCoinPrefix(coinId)
+		cursor := fetchTokenBuckets(dbTx).holders.Cursor()
+		for ok := cursor.Seek(prefix); ok; ok = cursor.Next() {
+			if !bytes.HasPrefix(cursor.Key(), prefix) {
+				break
+			}
+			serialized := cursor.Value()
+			if len(serialized) < 8 {
+				return database.Error{
+					ErrorCode:   database.ErrCorruption,
+					Description: "corrupt token holder entry",
+				}
+			}
+			holder := TokenHolder{
+				Address: string(serialized[8:]),
+				Balance: int64(byteOrder.Uint64(serialized)),
+			}
+			total += holder.Balance
+			holders = append(holders, holder)
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, 0, 0, err
+	}
+	sort.SliceStable(holders, func(i, j int) bool {
+		return holders[i].Balance > holders[j].Balance
+	})
+	numHolders := uint32(len(holders))
+	if skip >= numHolders {
+		return []TokenHolder{}, numHolders, total, nil
+	}
+	holders = holders[skip:]
+	if uint32(len(holders)) > count {
+		holders = holders[:count]
+	}
+	return holders, numHolders, total, nil
+}
+
+// TokenHistory returns the events of the token in the main order, or in the
+// reverse order when reverse is set.
+//
+// This function is safe for concurrent access.
+func (idx *TokenIndex) TokenHistory(coinId types.CoinID, skip, count uint32, reverse bool) ([]*TokenEvent, error) {
+	events := []*TokenEvent{}
+	err := idx.db.View(func(dbTx database.Tx) error {
+		prefix := tokenCoinPrefix(coinId)
+		cursor := fetchTokenBuckets(dbTx).events.Cursor()
+		var ok bool
+		if reverse {
+			// Position the cursor on the last event of the coin.
+			next := tokenCoinPrefix(coinId + 1)
+			if coinId == types.CoinID(0xffff) || !cursor.Seek(next) {
+				ok = cursor.Last()
+			} else {
+				ok = cursor.Prev()
+			}
+		} else {
+			ok = cursor.Seek(prefix)
+		}
+		for ; ok && uint32(len(events)) < count; ok = advanceCursor(cursor, reverse) {
+			key := cursor.Key()
+			if !bytes.HasPrefix(key, prefix) {
+				break
+			}
+			if skip > 0 {
+				skip--
+				continue
+			}
+			event, err := deserializeTokenEvent(key, cursor.Value())
+			if err != nil {
+				return err
+			}
+			events = append(events, event)
+		}
+		return nil
+	})
+	return events, err
+}
+
+func advanceCursor(cursor database.Cursor, reverse bool) bool {
+	if reverse {
+		return cursor.Prev()
+	}
+	return cursor.Next()
+}
+
+// TokenBalances returns the balances of the tokens held by the address keyed
+// by the coin id.
+//
+// This function is safe for concurrent access.
+func (idx *TokenIndex) TokenBalances(addr types.Address) (map[types.CoinID]int64, error) {
+	addrKey, err := addrToKey(addr, idx.chainParams)
+	if err != nil {
+		return nil, err
+	}
+	balances := map[types.CoinID]int64{}
+	err = idx.db.View(func(dbTx database.Tx) error {
+		cursor := fetchTokenBuckets(dbTx).addrBalances.Cursor()
+		for ok := cursor.Seek(addrKey[:]); ok; ok = cursor.Next() {
+			key := cursor.Key()
+			if len(key) != addrKeySize+2 || !bytes.HasPrefix(key, addrKey[:]) {
+				break
+			}
+			coinId := types.CoinID(eventKeyOrder.Uint16(key[addrKeySize:]))
+			balances[coinId] = int64(byteOrder.Uint64(cursor.Value()))
+		}
+		return nil
+	})
+	return balances, err
+}
+
+// NewTokenIndex returns a new instance of an indexer that is used to track the
+// activity and the holders of the tokens.
+//
+// It implements the Indexer interface which plugs into the IndexManager that
+// in turn is used by the blockchain package.  This allows the index to be
+// seamlessly maintained along with the chain.
+func NewTokenIndex(db database.DB) *TokenIndex {
+	return &TokenIndex{
+		db:          db,
+		chainParams: params.ActiveNetParams.Params,
+	}
+}
+
+// DropTokenIndex drops the token index from the provided database if it
+// exists.
+func DropTokenIndex(db database.DB, interrupt <-chan struct{}) error {
+	return dropIndex(db, tokenIndexKey, tokenIndexName, interrupt)
+}
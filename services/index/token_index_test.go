@@ -0,0 +1,190 @@
+// Copyright (c) 2017-2018 The qitmeer developers
+
+package index
+
+import (
+	"testing"
+
+	"github.com/Qitmeer/qng/common/hash"
+	"github.com/Qitmeer/qng/core/address"
+	"github.com/Qitmeer/qng/core/types"
+	"github.com/Qitmeer/qng/crypto/ecc"
+	"github.com/Qitmeer/qng/database"
+	"github.com/Qitmeer/qng/engine/txscript"
+	"github.com/Qitmeer/qng/params"
+)
+
+const testTokenCoin = types.QitmeerReservedID + 1
+
+func testTokenAddr(t *testing.T, seed string) (types.Address, []byte) {
+	addr, err := address.NewPubKeyHashAddress(hash.Hash160([]byte(seed)), params.ActiveNetParams.Params, ecc.ECDSA_Secp256k1)
+	if err != nil {
+		t.Fatal(err)
+	}
+	pkScript, err := txscript.PayToAddrScript(addr)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return addr, pkScript
+}
+
+func testTokenOut(pkScript []byte, value int64) *types.TxOutput {
+	return types.NewTxOutput(types.Amount{Id: testTokenCoin, Value: value}, pkScript)
+}
+
+func TestTokenIndex(t *testing.T) {
+	idx := NewTokenIndex(nil)
+	db := newTestIndexDB(t, idx)
+	idx.db = db
+	valid := &testIndexBlock{}
+	addrA, scriptA := testTokenAddr(t, "a")
+	addrB, scriptB := testTokenAddr(t, "b")
+
+	connect := func(block *types.SerializedBlock, stxos [][]byte) error {
+		return db.Update(func(dbTx database.Tx) error {
+			return idx.ConnectBlock(dbTx, block, stxos, valid)
+		})
+	}
+	disconnect := func(block *types.SerializedBlock) {
+		t.Helper()
+		err := db.Update(func(dbTx database.Tx) error {
+			return idx.DisconnectBlock(dbTx, block, nil)
+		})
+		if err != nil {
+			t.Fatal(err)
+		}
+	}
+	checkBalances := func(a, b int64) {
+		t.Helper()
+		for _, test := range []struct {
+			addr    types.Address
+			balance int64
+		}{{addrA, a}, {addrB, b}} {
+			balances, err := idx.TokenBalances(test.addr)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if balances[testTokenCoin] != test.balance {
+				t.Fatalf("%s: got the balance %d, want %d", test.addr, balances[testTokenCoin], test.balance)
+			}
+		}
+		_, numHolders, total, err := idx.TokenHolders(testTokenCoin, 0, 10)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if total != a+b {
+			t.Fatalf("got the holder total %d, want %d", total, a+b)
+		}
+		if a == 0 && b == 0 && numHolders != 0 {
+			t.Fatalf("got %d holders, want none", numHolders)
+		}
+	}
+	countOuts := func() int {
+		t.Helper()
+		n := 0
+		err := db.View(func(dbTx database.Tx) error {
+			return fetchTokenBuckets(dbTx).outs.ForEach(func(k, v []byte) error {
+				n++
+				return nil
+			})
+		})
+		if err != nil {
+			t.Fatal(err)
+		}
+		return n
+	}
+	checkHistory := func(want int) {
+		t.Helper()
+		events, err := idx.TokenHistory(testTokenCoin, 0, 10, false)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if len(events) != want {
+			t.Fatalf("got %d events, want %d", len(events), want)
+		}
+	}
+
+	issue := testSpendTx([]types.TxOutPoint{testOutPoint("meer", 0)},
+		testTokenOut(scriptA, 100), testTokenOut(scriptB, 50))
+	block1 := newTestIndexBlock(t, db, 1, issue)
+	if err := connect(block1, testStxos(1)); err != nil {
+		t.Fatal(err)
+	}
+	checkBalances(100, 50)
+
+	// The second transaction spends an output of the first one in the same
+	// block.
+	pay := testSpendTx([]types.TxOutPoint{{Hash: issue.TxHash(), OutIndex: 0}},
+		testTokenOut(scriptB, 60), testTokenOut(scriptA, 40))
+	payBack := testSpendTx([]types.TxOutPoint{{Hash: pay.TxHash(), OutIndex: 1}},
+		testTokenOut(scriptB, 40))
+	block2 := newTestIndexBlock(t, db, 2, pay, payBack)
+
+	// The spend journal must have an entry for every spent outpoint.
+	if err := connect(block2, testStxos(1)); err == nil {
+		t.Fatalf("the block is connected with a short spend journal")
+	}
+	if err := connect(block2, testStxos(2)); err != nil {
+		t.Fatal(err)
+	}
+	checkBalances(0, 150)
+	checkHistory(3)
+
+	// The spent outputs are pruned.
+	if n := countOuts(); n != 3 {
+		t.Fatalf("got %d token outputs, want 3", n)
+	}
+
+	disconnect(block2)
+	checkBalances(100, 50)
+	checkHistory(1)
+	if n := countOuts(); n != 2 {
+		t.Fatalf("got %d token outputs, want 2", n)
+	}
+
+	// A block which isn't indexed is ignored.
+	disconnect(block2)
+	checkBalances(100, 50)
+
+	disconnect(block1)
+	checkBalances(0, 0)
+	checkHistory(0)
+	if n := countOuts(); n != 0 {
+		t.Fatalf("got %d token outputs, want none", n)
+	}
+	err := db.View(func(dbTx database.Tx) error {
+		return fetchTokenBuckets(dbTx).blocks.ForEach(func(k, v []byte) error {
+			t.Fatalf("the block record %x is left", k)
+			return nil
+		})
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestTokenBlockRecord(t *testing.T) {
+	out := serializeTokenOut(&tokenOut{coinId: testTokenCoin, value: 10, address: "addr"})
+	r := &tokenBlockRecord{}
+	r.addSpent(tokenOutKey(&types.TxOutPoint{Hash: hash.HashH([]byte("a"))}), out)
+	r.eventKeys = append(r.eventKeys, tokenEventKey(testTokenCoin, 3, 1))
+	serialized := serializeTokenBlockRecord(r)
+
+	got, err := deserializeTokenBlockRecord(serialized)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(got.spentOuts) != 1 || string(got.spentOuts[0]) != string(out) ||
+		string(got.spentKeys[0]) != string(r.spentKeys[0]) ||
+		len(got.eventKeys) != 1 || string(got.eventKeys[0]) != string(r.eventKeys[0]) {
+		t.Fatalf("got the record %+v, want %+v", got, r)
+	}
+
+	// The record of a block without spent outputs or events isn't empty.
+	if empty := serializeTokenBlockRecord(&tokenBlockRecord{}); len(empty) == 0 {
+		t.Fatalf("the empty record is empty")
+	}
+	if _, err := deserializeTokenBlockRecord(serialized[:len(serialized)-1]); err == nil {
+		t.Fatalf("a truncated record is deserialized")
+	}
+}
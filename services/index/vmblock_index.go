@@ -8,8 +8,6 @@ import (
 	"github.com/Qitmeer/qng/consensus/store/vm_block_index"
 	"github.com/Qitmeer/qng/core/blockchain"
 	"github.com/Qitmeer/qng/database"
-	l "github.com/Qitmeer/qng/log"
-	"github.com/schollz/progressbar/v3"
 )
 
 const (
@@ -24,78 +22,57 @@ func (idx *VMBlockIndex) Name() string {
 	return vmblockIndexName
 }
 
-func (idx *VMBlockIndex) Init() error {
+// Init checks the tip of the index against the main chain and returns the
+// order from which the index has to catch up, which is past the main order
+// when the index is already synced.
+func (idx *VMBlockIndex) Init() (uint, error) {
 	vmbiStore := idx.consensus.VMBlockIndexStore()
 	if vmbiStore == nil {
-		return fmt.Errorf("No vm block index store")
+		return 0, fmt.Errorf("No vm block index store")
 	}
 	bc := idx.consensus.BlockChain()
 	mainOrder := bc.GetMainOrder()
 	mainHash := bc.GetBlockHashByOrder(mainOrder)
 	if mainHash == nil {
-		return fmt.Errorf("No block in order:%d", mainOrder)
+		return 0, fmt.Errorf("No block in order:%d", mainOrder)
 	}
 	if vmbiStore.IsEmpty() {
-		return idx.caughtUpFrom(0)
-	} else {
-		tipOrder, tipHash, err := vmbiStore.Tip(model.NewStagingArea())
-		if err != nil {
-			return err
-		}
-		if tipOrder != uint64(mainOrder) || !mainHash.IsEqual(tipHash) {
-			if tipOrder < uint64(mainOrder) {
-				// It shows that the data is encounter
-				bh := bc.GetBlockHashByOrder(uint(tipOrder))
-				if bh != nil && bh.IsEqual(tipHash) {
-					return idx.caughtUpFrom(uint(tipOrder + 1))
-				}
+		return 0, nil
+	}
+	tipOrder, tipHash, err := vmbiStore.Tip(model.NewStagingArea())
+	if err != nil {
+		return 0, err
+	}
+	if tipOrder != uint64(mainOrder) || !mainHash.IsEqual(tipHash) {
+		if tipOrder < uint64(mainOrder) {
+			// It shows that the data is encounter
+			bh := bc.GetBlockHashByOrder(uint(tipOrder))
+			if bh != nil && bh.IsEqual(tipHash) {
+				return uint(tipOrder + 1), nil
 			}
-			return fmt.Errorf("vm block index(%s:%d) is out of synchronization(%s:%d) and can only be deleted and rebuilt:index --dropvmblock",
-				tipHash, tipOrder, mainHash, mainOrder)
 		}
-		log.Info(fmt.Sprintf("Current vmblock index tip:%s,%d", tipHash.String(), tipOrder))
+		return 0, fmt.Errorf("vm block index(%s:%d) is out of synchronization(%s:%d) and can only be deleted and rebuilt:index --dropvmblock",
+			tipHash, tipOrder, mainHash, mainOrder)
 	}
-	return nil
+	log.Info(fmt.Sprintf("Current vmblock index tip:%s,%d", tipHash.String(), tipOrder))
+	return mainOrder + 1, nil
 }
 
-func (idx *VMBlockIndex) caughtUpFrom(startOrder uint) error {
-	vmbiStore := idx.consensus.VMBlockIndexStore()
-	if vmbiStore == nil {
-		return fmt.Errorf("No vm block index store")
+// catchUpBlock indexes the block of the main chain at the order.
+func (idx *VMBlockIndex) catchUpBlock(order uint) error {
+	if order == 0 {
+		return nil
 	}
 	bc := idx.consensus.BlockChain()
-	mainOrder := bc.GetMainOrder()
-	mainHash := bc.GetBlockHashByOrder(mainOrder)
-	if startOrder > mainOrder {
-		return nil
+	bh := bc.GetBlockHashByOrder(order)
+	if bh == nil {
+		return fmt.Errorf("No block in order:%d", order)
 	}
-	if mainOrder > 0 {
-		log.Info(fmt.Sprintf("Start caught up vm block index from (order:%d) to tip(hash:%s,order:%d)", startOrder, mainHash, mainOrder))
-		logLvl := l.Glogger().GetVerbosity()
-		bar := progressbar.Default(int64(mainOrder-startOrder), fmt.Sprintf("%s:", idx.Name()))
-		l.Glogger().Verbosity(l.LvlCrit)
-		for i := uint(startOrder); i <= mainOrder; i++ {
-			bar.Add(1)
-			if i == 0 {
-				continue
-			}
-			bh := bc.GetBlockHashByOrder(i)
-			if bh == nil {
-				return fmt.Errorf("No block in order:%d", i)
-			}
-			bid := bc.(*blockchain.BlockChain).VMService().GetBlockID(bh)
-			if bid == 0 {
-				continue
-			}
-			err := idx.ConnectBlock(bh, bid)
-			if err != nil {
-				return err
-			}
-		}
-		l.Glogger().Verbosity(logLvl)
+	bid := bc.(*blockchain.BlockChain).VMService().GetBlockID(bh)
+	if bid == 0 {
+		return nil
 	}
-	log.Info(fmt.Sprintf("Current vmblock index tip:%s,%d", mainHash.String(), mainOrder))
-	return idx.UpdateMainTip(mainHash, uint64(mainOrder))
+	return idx.ConnectBlock(bh, bid)
 }
 
 func (idx *VMBlockIndex) ConnectBlock(bh *hash.Hash, vmbid uint64) error {
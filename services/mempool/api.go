@@ -2,6 +2,7 @@ package mempool
 
 import (
 	"fmt"
+	"github.com/Qitmeer/qng/core/json"
 	"github.com/Qitmeer/qng/rpc/api"
 	"github.com/Qitmeer/qng/rpc/client/cmds"
 	"sort"
@@ -41,6 +42,22 @@ func (api *PublicMempoolAPI) GetMempoolCount() (interface{}, error) {
 	return fmt.Sprintf("%d", api.txPool.Count()), nil
 }
 
+// GetMempoolInfo returns the size, total serialized bytes and total fees of the
+// transactions in the main pool.
+func (api *PublicMempoolAPI) GetMempoolInfo() (interface{}, error) {
+	descs := api.txPool.TxDescs()
+	ret := json.MempoolInfoResult{
+		Size:        api.txPool.Count(),
+		Orphans:     api.txPool.OrphanCount(),
+		LastUpdated: api.txPool.LastUpdated().Unix(),
+	}
+	for _, desc := range descs {
+		ret.Bytes += int64(desc.Tx.Transaction().SerializeSize())
+		ret.Fees += desc.Fee
+	}
+	return ret, nil
+}
+
 func (api *PublicMempoolAPI) SaveMempool() (interface{}, error) {
 	num, err := api.txPool.Perisit()
 	if err != nil {
@@ -186,6 +186,22 @@ func checkPkScriptStandard(pkScript []byte,
 			return txRuleError(message.RejectNonstandard, str)
 		}
 
+	case txscript.HTLCTy:
+		pushes, err := txscript.ExtractHTLCDataPushes(pkScript)
+		if err != nil {
+			str := fmt.Sprintf("hash time-locked contract script "+
+				"parse failure: %v", err)
+			return txRuleError(message.RejectNonstandard, str)
+		}
+
+		// The refund branch of a standard hash time-locked contract
+		// must be locked.
+		if pushes.LockTime < 1 {
+			str := fmt.Sprintf("hash time-locked contract with lock "+
+				"time %d", pushes.LockTime)
+			return txRuleError(message.RejectNonstandard, str)
+		}
+
 	case txscript.NonStandardTy:
 		return txRuleError(message.RejectNonstandard,
 			"non-standard script form")
@@ -194,6 +210,12 @@ func checkPkScriptStandard(pkScript []byte,
 	return nil
 }
 
+// IsDust returns whether the memory pool rejects the output as dust by the
+// minimum relay fee.
+func IsDust(txOut *types.TxOutput, minRelayTxFee types.Amount) bool {
+	return isDust(txOut, minRelayTxFee)
+}
+
 // isDust returns whether or not the passed transaction output amount is
 // considered dust or not based on the passed minimum transaction relay fee.
 // Dust is defined in terms of the minimum transaction relay fee.  In
@@ -88,6 +88,10 @@ type Config struct {
 
 	Events *event.Feed
 
+	// RemoveTxNotify, when not nil, is called for every transaction which
+	// is removed from the pool. It is called with the pool lock held.
+	RemoveTxNotify func(tx *types.Tx)
+
 	// FeeEstimatator provides a feeEstimator. If it is not nil, the mempool
 	// records all new transactions it observes into the feeEstimator.
 	FeeEstimator *FeeEstimator
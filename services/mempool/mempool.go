@@ -130,6 +130,9 @@ func (mp *TxPool) removeTransaction(theTx *types.Tx, removeRedeemers bool) {
 		delete(mp.pool, *txHash)
 		atomic.StoreInt64(&mp.lastUpdated, roughtime.Now().Unix())
 		log.Trace(fmt.Sprintf("TxPool:remove tx %s", txHash))
+		if mp.cfg.RemoveTxNotify != nil {
+			mp.cfg.RemoveTxNotify(theTx)
+		}
 	}
 }
 
@@ -361,6 +364,11 @@ func (mp *TxPool) maybeAcceptTransaction(tx *types.Tx, isNew, rateLimit, allowHi
 	if err != nil {
 		return nil, nil, err
 	}
+	// The token opcodes are checked against the token state of the tip.
+	tokenState := mp.cfg.BC.GetCurTokenState()
+	if tokenState == nil {
+		return nil, nil, fmt.Errorf("Token state error\n")
+	}
 	// Don't allow transactions with fees too low to get into a mined block.
 	serializedSize := int64(msgTx.SerializeSize())
 
@@ -393,7 +401,7 @@ func (mp *TxPool) maybeAcceptTransaction(tx *types.Tx, isNew, rateLimit, allowHi
 			utxoView.AddTokenTxOut(tx.Tx.TxIn[0].PreviousOut, nil)
 		}
 
-		err = blockchain.ValidateTransactionScripts(tx, utxoView, flags,
+		err = blockchain.ValidateTransactionScripts(tx, utxoView, tokenState, flags,
 			mp.cfg.SigCache, int64(nextBlockHeight))
 		if err != nil {
 			if cerr, ok := err.(blockchain.RuleError); ok {
@@ -426,7 +434,7 @@ func (mp *TxPool) maybeAcceptTransaction(tx *types.Tx, isNew, rateLimit, allowHi
 		if err != nil {
 			return nil, nil, err
 		}
-		err = blockchain.ValidateTransactionScripts(types.NewTx(vtsTx), utxoView, flags,
+		err = blockchain.ValidateTransactionScripts(types.NewTx(vtsTx), utxoView, tokenState, flags,
 			mp.cfg.SigCache, int64(nextBlockHeight))
 		if err != nil {
 			if cerr, ok := err.(blockchain.RuleError); ok {
@@ -669,7 +677,7 @@ func (mp *TxPool) maybeAcceptTransaction(tx *types.Tx, isNew, rateLimit, allowHi
 	// Verify crypto signatures for each input and reject the transaction if
 	// any don't verify.
 
-	err = blockchain.ValidateTransactionScripts(tx, utxoView, flags,
+	err = blockchain.ValidateTransactionScripts(tx, utxoView, tokenState, flags,
 		mp.cfg.SigCache, int64(nextBlockHeight))
 	if err != nil {
 		if cerr, ok := err.(blockchain.RuleError); ok {
@@ -1117,6 +1125,19 @@ func (mp *TxPool) HaveTransaction(hash *hash.Hash) bool {
 	return haveTx
 }
 
+// CheckSpend checks whether the passed outpoint is already spent by a
+// transaction in the main pool. If that's the case the spending transaction
+// will be returned, if not nil will be returned.
+//
+// This function is safe for concurrent access.
+func (mp *TxPool) CheckSpend(op types.TxOutPoint) *types.Tx {
+	mp.mtx.RLock()
+	txR := mp.outpoints[op]
+	mp.mtx.RUnlock()
+
+	return txR
+}
+
 // isTransactionInPool returns whether or not the passed transaction already
 // exists in the main pool.
 //
@@ -1256,6 +1277,16 @@ func (mp *TxPool) Count() int {
 	return count
 }
 
+// OrphanCount returns the number of transactions in the orphan pool.
+//
+// This function is safe for concurrent access.
+func (mp *TxPool) OrphanCount() int {
+	mp.mtx.RLock()
+	defer mp.mtx.RUnlock()
+
+	return len(mp.orphans)
+}
+
 func (mp *TxPool) GetConfig() *Config {
 	return &mp.cfg
 }
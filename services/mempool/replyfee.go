@@ -33,3 +33,9 @@ func calcMinRequiredTxRelayFee(serializedSize int64, minRelayTxFee types.Amount)
 
 	return minFee
 }
+
+// CalcMinRequiredTxRelayFee returns the minimum fee of the serialized size
+// which the memory pool accepts by the minimum relay fee rate.
+func CalcMinRequiredTxRelayFee(serializedSize int64, minRelayTxFee types.Amount) int64 {
+	return calcMinRequiredTxRelayFee(serializedSize, minRelayTxFee)
+}
@@ -107,6 +107,10 @@ func NewBlockTemplate(policy *Policy, params *params.Params,
 	if err != nil {
 		return nil, err
 	}
+	tokenState := bc.GetCurTokenState()
+	if tokenState == nil {
+		return nil, fmt.Errorf("Token state error\n")
+	}
 
 	// Add a random coinbase nonce to ensure that tx prefix hash
 	// so that our merkle root is unique for lookups needed for
@@ -423,7 +427,7 @@ mempool:
 			logSkippedDeps(tx, deps)
 			continue
 		}
-		err = blockchain.ValidateTransactionScripts(tx, blockUtxos,
+		err = blockchain.ValidateTransactionScripts(tx, blockUtxos, tokenState,
 			scriptFlags, sigCache, int64(nextBlockHeight))
 		if err != nil {
 			log.Trace(fmt.Sprintf("Skipping tx %s due to error in "+
@@ -9,6 +9,7 @@ import (
 	"github.com/Qitmeer/qng/node/service"
 	"github.com/Qitmeer/qng/p2p"
 	"github.com/Qitmeer/qng/rpc"
+	"github.com/Qitmeer/qng/rpc/client/cmds"
 	"github.com/Qitmeer/qng/services/notifymgr/notify"
 	"github.com/Qitmeer/qng/services/zmq"
 	"github.com/libp2p/go-libp2p/core/peer"
@@ -87,6 +88,22 @@ func (ntmgr *NotifyMgr) TransactionConfirmed(tx *types.Tx) {
 	ntmgr.Server.Rebroadcast().RemoveInventory(tx.Hash())
 }
 
+// TransactionRemoved notifies the websocket clients of a transaction that was
+// removed from the mempool.
+func (ntmgr *NotifyMgr) TransactionRemoved(tx *types.Tx) {
+	if ntmgr.RpcServer != nil && ntmgr.RpcServer.IsStarted() {
+		ntmgr.RpcServer.NotifyRemovedTransaction(tx)
+	}
+}
+
+// LockMatured notifies the websocket clients of a locked output watched by
+// the account manager which matured.
+func (ntmgr *NotifyMgr) LockMatured(lm *cmds.SubscribedLockMatured) {
+	if ntmgr.RpcServer != nil && ntmgr.RpcServer.IsStarted() {
+		ntmgr.RpcServer.NotifyLockMatured(lm)
+	}
+}
+
 func (ntmgr *NotifyMgr) Start() error {
 	if err := ntmgr.Service.Start(); err != nil {
 		return err
@@ -216,6 +233,17 @@ func (ntmgr *NotifyMgr) handleNotifyMsg(notification *blockchain.Notification) {
 			break
 		}
 		ntmgr.zmqNotify.BlockDisconnected(block)
+
+	// The block order was reorganized.
+	case blockchain.Reorganization:
+		rnd, ok := notification.Data.(*blockchain.ReorganizationNotifyData)
+		if !ok {
+			log.Warn("Chain reorganization notification is not ReorganizationNotifyData.")
+			break
+		}
+		if ntmgr.zmqNotify.IsEnable() {
+			ntmgr.zmqNotify.Reorganization(rnd.Diff())
+		}
 	}
 }
 
@@ -24,6 +24,7 @@ import (
 	"github.com/Qitmeer/qng/rpc"
 	"github.com/Qitmeer/qng/rpc/api"
 	"github.com/Qitmeer/qng/rpc/client/cmds"
+	"github.com/Qitmeer/qng/services/index"
 	"github.com/Qitmeer/qng/services/mempool"
 	"strconv"
 	"strings"
@@ -43,6 +44,7 @@ func (tm *TxManager) APIs() []api.API {
 			Public:    false,
 		},
 		tm.txMemPool.API(),
+		tm.indexManager.API(),
 	}
 }
 
@@ -493,12 +495,220 @@ func (api *PublicTxAPI) GetUtxo(txHash hash.Hash, vout uint32, includeMempool *b
 	return txOutReply, nil
 }
 
+// Returns the transaction input which spent a transaction output
+// 1. txid           (string, required)                The hash of the transaction
+// 2. vout           (numeric, required)               The index of the output
+//
+//Result:
+//{
+// "txid": "value",             (string)          The hash of the spending transaction
+// "vin": n,                    (numeric)         The index of the spending input
+// "blockhash": "value",        (string)          The block containing the spending transaction, empty for the mempool
+// "order": n,                  (numeric)         The order of the block
+// "inmempool": true|false,     (boolean)         Whether or not the spending transaction is in the mempool
+//}
+// The result is null when the output is not spent.
+func (api *PublicTxAPI) GetSpendingTx(txHash hash.Hash, vout uint32) (interface{}, error) {
+	spentIndex := api.txManager.indexManager.SpentIndex()
+	if spentIndex == nil {
+		return nil, fmt.Errorf("Spent index must be enabled (--spentindex)")
+	}
+	if err := api.txManager.indexManager.CheckSynced(spentIndex.Name()); err != nil {
+		return nil, err
+	}
+	outpoint := types.TxOutPoint{Hash: txHash, OutIndex: vout}
+	if spender := api.txManager.txMemPool.CheckSpend(outpoint); spender != nil {
+		for i, txIn := range spender.Tx.TxIn {
+			if txIn.PreviousOut == outpoint {
+				return &json.GetSpendingTxResult{
+					Txid:      spender.Hash().String(),
+					Vin:       uint32(i),
+					InMempool: true,
+				}, nil
+			}
+		}
+	}
+	entry, err := spentIndex.SpendingTx(&outpoint)
+	if err != nil {
+		return nil, rpc.RpcInternalError(err.Error(), "Failed to fetch the spent index entry")
+	}
+	if entry == nil {
+		return nil, nil
+	}
+	return &json.GetSpendingTxResult{
+		Txid:      entry.TxHash.String(),
+		Vin:       entry.InputIndex,
+		BlockHash: entry.BlockHash.String(),
+		Order:     uint64(entry.BlockOrder),
+	}, nil
+}
+
+// crossChainTransferResult returns the result of a cross chain transfer, the
+// transfer is pending when it is not indexed.
+func (api *PublicTxAPI) crossChainTransferResult(transfer *index.CrossChainTransfer, indexed bool) *json.CrossChainTransferResult {
+	result := &json.CrossChainTransferResult{
+		Txid:       transfer.TxHash.String(),
+		Type:       "export",
+		Status:     "pending",
+		Amount:     transfer.Amount.Value,
+		CoinId:     uint16(transfer.Amount.Id),
+		Address:    transfer.Address,
+		EVMAddress: "0x" + hex.EncodeToString(transfer.EVMAddress[:]),
+		EVMTxHash:  "0x" + transfer.EVMTxHash.String(),
+	}
+	if transfer.Type == types.TxTypeCrossChainImport {
+		result.Type = "import"
+	}
+	if !indexed {
+		return result
+	}
+	result.BlockHash = transfer.BlockHash.String()
+	result.Order = uint64(transfer.BlockOrder)
+	// The block may be invalidated by a reorder of the DAG after the
+	// transfer was indexed.
+	result.Status = "invalidated"
+	bd := api.txManager.GetChain().BlockDAG()
+	ib := bd.GetBlock(&transfer.BlockHash)
+	if ib != nil && !ib.GetStatus().KnownInvalid() {
+		result.Status = "confirmed"
+		result.Confirmations = uint64(bd.GetConfirmations(ib.GetID()))
+	}
+	return result
+}
+
+// crossChainIndex returns the cross chain index when it is enabled and synced.
+func (api *PublicTxAPI) crossChainIndex() (*index.CrossChainIndex, error) {
+	crossChainIndex := api.txManager.indexManager.CrossChainIndex()
+	if crossChainIndex == nil {
+		return nil, fmt.Errorf("Cross chain index must be enabled (--crosschainindex)")
+	}
+	if err := api.txManager.indexManager.CheckSynced(crossChainIndex.Name()); err != nil {
+		return nil, err
+	}
+	return crossChainIndex, nil
+}
+
+// Returns the lifecycle of an export or import transaction
+// 1. id             (string, required)                The hash of the transaction, or the 0x prefixed hash of its MeerEVM transaction
+//
+//Result:
+//{
+// "txid": "value",             (string)          The hash of the transaction
+// "type": "value",             (string)          export or import
+// "status": "value",           (string)          pending, confirmed or invalidated by a reorder of the DAG
+// "amount": n,                 (numeric)         The transferred amount
+// "coinid": n,                 (numeric)         The coin id of the amount
+// "address": "value",          (string)          The address of the UTXO ledger
+// "evmaddress": "value",       (string)          The MeerEVM account
+// "evmtxhash": "value",        (string)          The hash of the MeerEVM transaction applying the transfer
+// "blockhash": "value",        (string)          The block containing the transaction, empty when pending
+// "order": n,                  (numeric)         The order of the block
+// "confirmations": n,          (numeric)         The confirmations of the block
+//}
+// The result is null when the transfer is unknown.
+func (api *PublicTxAPI) GetCrossChainTransfer(id string) (interface{}, error) {
+	crossChainIndex, err := api.crossChainIndex()
+	if err != nil {
+		return nil, err
+	}
+	byEVMTxHash := strings.HasPrefix(id, "0x")
+	if byEVMTxHash {
+		id = id[2:]
+	}
+	h, err := hash.NewHashFromStr(id)
+	if err != nil {
+		return nil, rpc.RpcDecodeHexError(id)
+	}
+	var transfer *index.CrossChainTransfer
+	if byEVMTxHash {
+		transfer, err = crossChainIndex.TransferByEVMTxHash(h)
+	} else {
+		transfer, err = crossChainIndex.Transfer(h)
+	}
+	if err != nil {
+		return nil, rpc.RpcInternalError(err.Error(), "Failed to fetch the cross chain index entry")
+	}
+	if transfer != nil {
+		return api.crossChainTransferResult(transfer, true), nil
+	}
+	for _, tx := range api.crossChainMempoolTransfers() {
+		if (!byEVMTxHash && tx.TxHash.IsEqual(h)) ||
+			(byEVMTxHash && tx.EVMTxHash.IsEqual(h)) {
+			return api.crossChainTransferResult(tx, false), nil
+		}
+	}
+	return nil, nil
+}
+
+// crossChainMempoolTransfers returns the export and import transactions of
+// the mempool.
+func (api *PublicTxAPI) crossChainMempoolTransfers() []*index.CrossChainTransfer {
+	transfers := []*index.CrossChainTransfer{}
+	for _, desc := range api.txManager.txMemPool.TxDescs() {
+		tx := desc.Tx.Tx
+		if !types.IsCrossChainExportTx(tx) && !types.IsCrossChainImportTx(tx) {
+			continue
+		}
+		transfer, err := index.NewCrossChainTransfer(tx)
+		if err != nil {
+			continue
+		}
+		transfers = append(transfers, transfer)
+	}
+	return transfers
+}
+
+// Returns the export and import transactions of an address
+// 1. address        (string, required)                The address of the UTXO ledger, or a 0x prefixed MeerEVM account
+// 2. count          (numeric, optional, default=100)  The maximum number of transfers to return
+// 3. skip           (numeric, optional, default=0)    The number of leading transfers to skip
+// 4. reverse        (boolean, optional, default=false) Return the latest transfers first
+//
+// The pending transfers of the mempool are returned first, they are not
+// affected by count and skip.
+func (api *PublicTxAPI) ListCrossChainTransfers(addr string, count *uint, skip *uint, reverse *bool) (interface{}, error) {
+	crossChainIndex, err := api.crossChainIndex()
+	if err != nil {
+		return nil, err
+	}
+	addrKey, err := crossChainIndex.AddressKey(addr)
+	if err != nil {
+		return nil, fmt.Errorf("Invalid address or key: " + err.Error())
+	}
+	numRequested := uint32(100)
+	if count != nil {
+		numRequested = uint32(*count)
+	}
+	var numToSkip uint32
+	if skip != nil {
+		numToSkip = uint32(*skip)
+	}
+	results := []*json.CrossChainTransferResult{}
+	for _, transfer := range api.crossChainMempoolTransfers() {
+		if transfer.InvolvesAddressKey(addrKey) {
+			results = append(results, api.crossChainTransferResult(transfer, false))
+		}
+	}
+	transfers, err := crossChainIndex.TransfersForAddress(addrKey, numToSkip, numRequested,
+		reverse != nil && *reverse)
+	if err != nil {
+		return nil, rpc.RpcInternalError(err.Error(), "Failed to fetch the cross chain index entries")
+	}
+	for _, transfer := range transfers {
+		results = append(results, api.crossChainTransferResult(transfer, true))
+	}
+	return results, nil
+}
+
 // handleSearchRawTransactions implements the searchrawtransactions command.
 func (api *PublicTxAPI) GetRawTransactions(addre string, vinext *bool, count *uint, skip *uint, revers *bool, verbose *bool, filterAddrs *[]string) (interface{}, error) {
 	addrIndex := api.txManager.indexManager.AddrIndex()
 	if addrIndex == nil {
 		return nil, fmt.Errorf("Address index must be enabled (--addrindex)")
 	}
+	if err := api.txManager.indexManager.CheckSynced(addrIndex.Name()); err != nil {
+		return nil, err
+	}
 	vinExtra := false
 	if vinext != nil {
 		vinExtra = *vinext
@@ -1005,6 +1215,11 @@ func (api *PublicTxAPI) GetTxIDByMeerEVMTxHash(etxh hash.Hash) (interface{}, err
 	if vmbiStore == nil {
 		return nil, fmt.Errorf("You must be enable by --vmblockindex")
 	}
+	if vmbi := api.txManager.indexManager.VMBlockIndex(); vmbi != nil {
+		if err := api.txManager.indexManager.CheckSynced(vmbi.Name()); err != nil {
+			return nil, err
+		}
+	}
 	bh, err := vmbiStore.Get(model.NewStagingArea(), bid)
 	if err != nil {
 		return nil, err
@@ -1032,6 +1247,10 @@ func NewPrivateTxAPI(tm *TxManager) *PrivateTxAPI {
 	return &ptapi
 }
 
+// TxSign signs the raw transaction by the raw private key.
+//
+// Deprecated: the private key is sent over RPC, use the wallet module
+// (RPC:signRawTransactionWithWallet) instead.
 func (api *PrivateTxAPI) TxSign(privkeyStr string, rawTxStr string, tokenPrivkeyStr *string) (interface{}, error) {
 	privkeyByte, err := hex.DecodeString(privkeyStr)
 	if err != nil {
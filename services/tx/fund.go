@@ -0,0 +1,364 @@
+package tx
+
+import (
+	"bytes"
+	"encoding/hex"
+	"fmt"
+	"github.com/Qitmeer/qng/common/marshal"
+	"github.com/Qitmeer/qng/core/address"
+	"github.com/Qitmeer/qng/core/json"
+	"github.com/Qitmeer/qng/core/types"
+	"github.com/Qitmeer/qng/database"
+	"github.com/Qitmeer/qng/engine/txscript"
+	"github.com/Qitmeer/qng/params"
+	"github.com/Qitmeer/qng/rpc"
+	"github.com/Qitmeer/qng/services/acct"
+	"github.com/Qitmeer/qng/services/mempool"
+	"math"
+	"sort"
+)
+
+const (
+	// DefaultConfTarget is the confirmation target in blocks of the fee
+	// estimation of fundRawTransaction.
+	DefaultConfTarget = 6
+
+	// estimatedSignScriptSize is the size of the sign script of a
+	// pay-to-pubkey-hash input with a compressed public key, which the
+	// funded transaction is estimated by before it is signed.
+	estimatedSignScriptSize = 108
+)
+
+// fundingUTXO is an output which fundRawTransaction can select.
+type fundingUTXO struct {
+	op     types.TxOutPoint
+	amount types.Amount
+}
+
+// listUnspent returns the spendable outputs of the address, from the account
+// manager when it watches the address or else from the address index.  The
+// outputs spent by the mempool are not returned.
+func (api *PublicTxAPI) listUnspent(addr types.Address) ([]*fundingUTXO, error) {
+	result := []*fundingUTXO{}
+	am, ok := api.txManager.GetChain().Acct.(*acct.AccountManager)
+	if ok && am.HasAddress(addr.String()) {
+		us, err := am.ListUnspent(addr.String())
+		if err != nil {
+			return nil, err
+		}
+		for _, u := range us {
+			if api.txManager.txMemPool.CheckSpend(u.OutPoint) != nil {
+				continue
+			}
+			result = append(result, &fundingUTXO{op: u.OutPoint, amount: u.Amount})
+		}
+		return result, nil
+	}
+
+	addrIndex := api.txManager.indexManager.AddrIndex()
+	if addrIndex == nil {
+		return nil, fmt.Errorf("The address %s is not watched by the account manager (--acctmode), or address index must be enabled (--addrindex)", addr.String())
+	}
+	if err := api.txManager.indexManager.CheckSynced(addrIndex.Name()); err != nil {
+		return nil, err
+	}
+	var serializedTxns [][]byte
+	err := api.txManager.db.View(func(dbTx database.Tx) error {
+		regions, _, err := addrIndex.TxRegionsForAddress(dbTx, addr, 0, math.MaxUint32, false)
+		if err != nil {
+			return err
+		}
+		serializedTxns, err = dbTx.FetchBlockRegions(regions)
+		return err
+	})
+	if err != nil {
+		return nil, err
+	}
+	param := params.ActiveNetParams.Params
+	seen := map[types.TxOutPoint]struct{}{}
+	for _, serializedTx := range serializedTxns {
+		var tx types.Transaction
+		err = tx.Deserialize(bytes.NewReader(serializedTx))
+		if err != nil {
+			return nil, err
+		}
+		// The maturity of the coinbase outputs is only tracked by the
+		// account manager.
+		if tx.IsCoinBase() {
+			continue
+		}
+		txHash := tx.TxHash()
+		for i, txOut := range tx.TxOut {
+			class, addrs, _, err := txscript.ExtractPkScriptAddrs(txOut.PkScript, param)
+			if err != nil || len(addrs) != 1 {
+				continue
+			}
+			if class != txscript.PubKeyHashTy && class != txscript.PubKeyTy {
+				continue
+			}
+			if addrs[0].String() != addr.String() {
+				continue
+			}
+			op := types.TxOutPoint{Hash: txHash, OutIndex: uint32(i)}
+			if _, ok := seen[op]; ok {
+				continue
+			}
+			seen[op] = struct{}{}
+			entry, err := api.txManager.GetChain().FetchUtxoEntry(op)
+			if err != nil {
+				return nil, err
+			}
+			if entry == nil || entry.IsSpent() {
+				continue
+			}
+			if api.txManager.txMemPool.CheckSpend(op) != nil {
+				continue
+			}
+			result = append(result, &fundingUTXO{op: op, amount: entry.Amount()})
+		}
+	}
+	return result, nil
+}
+
+// selectFundingUTXOs selects the outputs of the coin, largest first, until
+// the amount is covered.
+func selectFundingUTXOs(us []*fundingUTXO, coin types.CoinID, amount int64) ([]*fundingUTXO, int64, error) {
+	selected := []*fundingUTXO{}
+	total := int64(0)
+	for _, u := range us {
+		if total >= amount {
+			break
+		}
+		if u.amount.Id != coin {
+			continue
+		}
+		selected = append(selected, u)
+		total += u.amount.Value
+	}
+	if total < amount {
+		return nil, 0, fmt.Errorf("Insufficient funds of %s: %d < %d", coin.Name(), total, amount)
+	}
+	return selected, total, nil
+}
+
+// fundingFeeRate returns the fee rate in atoms per kilobyte, by the option or
+// by the fee estimation, which is never lower than the minimum relay fee.
+func (api *PublicTxAPI) fundingFeeRate(opts *json.FundRawTransactionOptions) (int64, error) {
+	minRate := api.txManager.consensus.Config().MinTxFee
+	if opts.FeeRate > 0 {
+		if opts.FeeRate < minRate {
+			return 0, rpc.RpcInvalidError("The fee rate %d is lower than the minimum relay fee rate %d", opts.FeeRate, minRate)
+		}
+		return opts.FeeRate, nil
+	}
+	if api.txManager.feeEstimator == nil {
+		return minRate, nil
+	}
+	target := uint32(DefaultConfTarget)
+	if opts.ConfTarget > 0 {
+		target = opts.ConfTarget
+	}
+	feeRate, err := api.txManager.feeEstimator.EstimateFee(target)
+	if err != nil {
+		log.Debug("Use the minimum relay fee rate to fund", "reason", err)
+		return minRate, nil
+	}
+	rate := int64(float64(feeRate) * types.AtomsPerCoin)
+	if rate < minRate {
+		rate = minRate
+	}
+	return rate, nil
+}
+
+// FundRawTransaction adds the inputs which pay for the outputs of the raw
+// transaction and the fee, and the change outputs.  The inputs of every coin
+// are selected from the outputs of the addresses, the token inputs balance
+// their outputs and the fee is paid in MEER.
+func (api *PublicTxAPI) FundRawTransaction(hexTx string, options json.FundRawTransactionOptions) (interface{}, error) {
+	if len(hexTx)%2 != 0 {
+		hexTx = "0" + hexTx
+	}
+	serializedTx, err := hex.DecodeString(hexTx)
+	if err != nil {
+		return nil, rpc.RpcDecodeHexError(hexTx)
+	}
+	var mtx types.Transaction
+	err = mtx.Deserialize(bytes.NewReader(serializedTx))
+	if err != nil {
+		return nil, rpc.RpcDeserializationError("Could not decode Tx: %v", err)
+	}
+	if len(options.Addresses) <= 0 {
+		return nil, rpc.RpcInvalidError("No addresses to fund the transaction")
+	}
+	param := params.ActiveNetParams.Params
+	addrs := make([]types.Address, len(options.Addresses))
+	for i, a := range options.Addresses {
+		addr, err := address.DecodeAddress(a)
+		if err != nil {
+			return nil, rpc.RpcAddressKeyError("Could not decode address: %v", err)
+		}
+		if !address.IsForNetwork(addr, param) {
+			return nil, rpc.RpcAddressKeyError("Wrong network: %v", addr)
+		}
+		addrs[i] = addr
+	}
+	changeAddr := addrs[0]
+	if len(options.ChangeAddress) > 0 {
+		changeAddr, err = address.DecodeAddress(options.ChangeAddress)
+		if err != nil {
+			return nil, rpc.RpcAddressKeyError("Could not decode address: %v", err)
+		}
+		if !address.IsForNetwork(changeAddr, param) {
+			return nil, rpc.RpcAddressKeyError("Wrong network: %v", changeAddr)
+		}
+	}
+	changeScript, err := txscript.PayToAddrScript(changeAddr)
+	if err != nil {
+		return nil, err
+	}
+	feeRate, err := api.fundingFeeRate(&options)
+	if err != nil {
+		return nil, err
+	}
+	minRelayFee := types.Amount{Id: types.MEERA, Value: api.txManager.consensus.Config().MinTxFee}
+
+	prevOuts := make([]*types.TxOutput, len(mtx.TxIn))
+	exclude := map[types.TxOutPoint]struct{}{}
+	for i, txIn := range mtx.TxIn {
+		prevOut, err := api.fetchPrevOut(txIn.PreviousOut)
+		if err != nil {
+			return nil, err
+		}
+		if prevOut == nil {
+			return nil, rpc.RpcInvalidError("The previous output of the input %d is unknown", i)
+		}
+		prevOuts[i] = prevOut
+		exclude[txIn.PreviousOut] = struct{}{}
+	}
+
+	us := []*fundingUTXO{}
+	for _, addr := range addrs {
+		aus, err := api.listUnspent(addr)
+		if err != nil {
+			return nil, err
+		}
+		for _, u := range aus {
+			if _, ok := exclude[u.op]; ok {
+				continue
+			}
+			exclude[u.op] = struct{}{}
+			us = append(us, u)
+		}
+	}
+	funded, fee, changePos, err := fundTransaction(&mtx, prevOuts, us, changeScript, feeRate, minRelayFee)
+	if err != nil {
+		return nil, err
+	}
+	mtxHex, err := marshal.MessageToHex(funded)
+	if err != nil {
+		return nil, err
+	}
+	return json.FundRawTransactionResult{Hex: mtxHex, Fee: fee, ChangePos: changePos}, nil
+}
+
+// fundTransaction returns a copy of the transaction with the inputs selected
+// from the outputs and the change outputs, its fee and the position of the
+// MEER change output, or -1 when the change is dust and paid as the fee.  The
+// previous outputs are those of the inputs of the transaction.
+func fundTransaction(mtx *types.Transaction, prevOuts []*types.TxOutput, us []*fundingUTXO, changeScript []byte,
+	feeRate int64, minRelayFee types.Amount) (*types.Transaction, int64, int, error) {
+	// The amounts of the coins which the new inputs must pay.
+	amounts := map[types.CoinID]int64{}
+	for i, txOut := range mtx.TxOut {
+		if txOut.Amount.Id == types.MEERB {
+			return nil, 0, 0, rpc.RpcInvalidError("Not support %v", txOut.Amount.Id)
+		}
+		// The data outputs are skipped like the mempool does.
+		if txscript.GetScriptClass(txscript.DefaultScriptVersion, txOut.PkScript) != txscript.NullDataTy &&
+			mempool.IsDust(txOut, minRelayFee) {
+			return nil, 0, 0, rpc.RpcInvalidError("The output %d is dust: %d", i, txOut.Amount.Value)
+		}
+		amounts[txOut.Amount.Id] += txOut.Amount.Value
+	}
+	for _, prevOut := range prevOuts {
+		amounts[prevOut.Amount.Id] -= prevOut.Amount.Value
+	}
+	us = append([]*fundingUTXO{}, us...)
+	sort.SliceStable(us, func(i, j int) bool {
+		return us[i].amount.Value > us[j].amount.Value
+	})
+
+	// The token inputs and outputs are balanced exactly.
+	coins := []types.CoinID{}
+	for coin := range amounts {
+		if coin != types.MEERA {
+			coins = append(coins, coin)
+		}
+	}
+	sort.Slice(coins, func(i, j int) bool { return coins[i] < coins[j] })
+	tokenIns := []*fundingUTXO{}
+	tokenChanges := []*types.TxOutput{}
+	for _, coin := range coins {
+		selected, total, err := selectFundingUTXOs(us, coin, amounts[coin])
+		if err != nil {
+			return nil, 0, 0, err
+		}
+		tokenIns = append(tokenIns, selected...)
+		if total > amounts[coin] {
+			tokenChanges = append(tokenChanges, types.NewTxOutput(types.Amount{Id: coin, Value: total - amounts[coin]}, changeScript))
+		}
+	}
+
+	fee := int64(0)
+	for {
+		meerIns, total, err := selectFundingUTXOs(us, types.MEERA, amounts[types.MEERA]+fee)
+		if err != nil {
+			return nil, 0, 0, err
+		}
+		funded := copyTransaction(mtx)
+		for _, u := range append(append([]*fundingUTXO{}, tokenIns...), meerIns...) {
+			op := u.op
+			funded.AddTxIn(types.NewTxInput(&op, []byte{}))
+		}
+		for _, txOut := range tokenChanges {
+			funded.AddTxOut(txOut)
+		}
+		changePos := -1
+		change := total - amounts[types.MEERA] - fee
+		if change > 0 {
+			txOut := types.NewTxOutput(types.Amount{Id: types.MEERA, Value: change}, changeScript)
+			if !mempool.IsDust(txOut, minRelayFee) {
+				changePos = len(funded.TxOut)
+				funded.AddTxOut(txOut)
+			}
+		}
+		size := int64(funded.SerializeSize())
+		for _, txIn := range funded.TxIn {
+			if len(txIn.SignScript) <= 0 {
+				size += estimatedSignScriptSize
+			}
+		}
+		required := size * feeRate / 1000
+		minFee := mempool.CalcMinRequiredTxRelayFee(size, minRelayFee)
+		if required < minFee {
+			required = minFee
+		}
+		if fee >= required {
+			if changePos < 0 {
+				// The dust change is paid as the fee.
+				fee += change
+			}
+			return funded, fee, changePos, nil
+		}
+		fee = required
+	}
+}
+
+// copyTransaction returns a copy of the transaction whose inputs and outputs
+// can be added without changing it.
+func copyTransaction(mtx *types.Transaction) *types.Transaction {
+	funded := *mtx
+	funded.TxIn = append([]*types.TxInput{}, mtx.TxIn...)
+	funded.TxOut = append([]*types.TxOutput{}, mtx.TxOut...)
+	return &funded
+}
@@ -0,0 +1,189 @@
+package tx
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/Qitmeer/qng/common/hash"
+	"github.com/Qitmeer/qng/core/address"
+	"github.com/Qitmeer/qng/core/types"
+	"github.com/Qitmeer/qng/crypto/ecc"
+	"github.com/Qitmeer/qng/engine/txscript"
+	"github.com/Qitmeer/qng/params"
+)
+
+const testFundTokenID types.CoinID = 100
+
+var testMinRelayFee = types.Amount{Id: types.MEERA, Value: 10000}
+
+func testPayScript(t *testing.T, seed string) []byte {
+	addr, err := address.NewPubKeyHashAddress(hash.Hash160([]byte(seed)), &params.TestNetParams, ecc.ECDSA_Secp256k1)
+	if err != nil {
+		t.Fatal(err)
+	}
+	pkScript, err := txscript.PayToAddrScript(addr)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return pkScript
+}
+
+func testFundingUTXO(index uint32, coin types.CoinID, value int64) *fundingUTXO {
+	return &fundingUTXO{
+		op:     types.TxOutPoint{Hash: hash.HashH([]byte("funding")), OutIndex: index},
+		amount: types.Amount{Id: coin, Value: value},
+	}
+}
+
+// checkFunded checks that the inputs of the funded transaction pay its
+// outputs and the fee.
+func checkFunded(t *testing.T, funded *types.Transaction, prevOuts []*types.TxOutput, us []*fundingUTXO, fee int64) {
+	ins := map[types.CoinID]int64{}
+	for _, prevOut := range prevOuts {
+		ins[prevOut.Amount.Id] += prevOut.Amount.Value
+	}
+	for _, txIn := range funded.TxIn[len(prevOuts):] {
+		found := false
+		for _, u := range us {
+			if u.op == txIn.PreviousOut {
+				ins[u.amount.Id] += u.amount.Value
+				found = true
+			}
+		}
+		if !found {
+			t.Fatalf("the input %v isn't a funding output", txIn.PreviousOut)
+		}
+	}
+	outs := map[types.CoinID]int64{types.MEERA: fee}
+	for _, txOut := range funded.TxOut {
+		outs[txOut.Amount.Id] += txOut.Amount.Value
+	}
+	for coin, value := range ins {
+		if outs[coin] != value {
+			t.Fatalf("%v: the inputs pay %d, the outputs and the fee %d", coin, value, outs[coin])
+		}
+	}
+}
+
+func TestFundTransaction(t *testing.T) {
+	payScript := testPayScript(t, "pay")
+	changeScript := testPayScript(t, "change")
+	nullData, err := txscript.NewScriptBuilder().AddOp(txscript.OP_RETURN).AddData([]byte("data")).Script()
+	if err != nil {
+		t.Fatal(err)
+	}
+	us := []*fundingUTXO{
+		testFundingUTXO(0, types.MEERA, 1e8),
+		testFundingUTXO(1, types.MEERA, 5e7),
+		testFundingUTXO(2, testFundTokenID, 300),
+		testFundingUTXO(3, testFundTokenID, 200),
+	}
+
+	tests := []struct {
+		name      string
+		outs      []*types.TxOutput
+		changePos int
+	}{
+		{"meer", []*types.TxOutput{
+			types.NewTxOutput(types.Amount{Id: types.MEERA, Value: 1e8}, payScript),
+		}, 1},
+		{"null data", []*types.TxOutput{
+			types.NewTxOutput(types.Amount{Id: types.MEERA, Value: 1e7}, payScript),
+			types.NewTxOutput(types.Amount{Id: types.MEERA, Value: 0}, nullData),
+		}, 2},
+		{"token", []*types.TxOutput{
+			types.NewTxOutput(types.Amount{Id: testFundTokenID, Value: 400}, payScript),
+		}, 2},
+	}
+	for _, test := range tests {
+		mtx := types.NewTransaction()
+		for _, txOut := range test.outs {
+			mtx.AddTxOut(txOut)
+		}
+		funded, fee, changePos, err := fundTransaction(mtx, nil, us, changeScript, 20000, testMinRelayFee)
+		if err != nil {
+			t.Errorf("%s: %v", test.name, err)
+			continue
+		}
+		if changePos != test.changePos {
+			t.Errorf("%s: got the change position %d, want %d", test.name, changePos, test.changePos)
+		}
+		if len(mtx.TxIn) != 0 || len(mtx.TxOut) != len(test.outs) {
+			t.Errorf("%s: the transaction is changed", test.name)
+		}
+		if fee <= 0 {
+			t.Errorf("%s: got the fee %d", test.name, fee)
+		}
+		checkFunded(t, funded, nil, us, fee)
+	}
+}
+
+func TestFundTransactionDustChange(t *testing.T) {
+	payScript := testPayScript(t, "pay")
+	us := []*fundingUTXO{testFundingUTXO(0, types.MEERA, 1e8)}
+	mtx := types.NewTransaction()
+	mtx.AddTxOut(types.NewTxOutput(types.Amount{Id: types.MEERA, Value: 5e7}, payScript))
+	_, fee, _, err := fundTransaction(mtx, nil, us, payScript, 20000, testMinRelayFee)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// The change left by the fee with a change output is dust.
+	mtx = types.NewTransaction()
+	mtx.AddTxOut(types.NewTxOutput(types.Amount{Id: types.MEERA, Value: 1e8 - fee - 100}, payScript))
+	funded, dustFee, changePos, err := fundTransaction(mtx, nil, us, payScript, 20000, testMinRelayFee)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if changePos != -1 || len(funded.TxOut) != 1 {
+		t.Fatalf("got the change position %d, want no change", changePos)
+	}
+	if dustFee <= fee-100 || dustFee != 1e8-funded.TxOut[0].Amount.Value {
+		t.Fatalf("got the fee %d, want the dust change paid as the fee", dustFee)
+	}
+}
+
+func TestFundTransactionPrevOuts(t *testing.T) {
+	// The existing inputs pay a part of the outputs.
+	mtx := types.NewTransaction()
+	mtx.AddTxIn(types.NewTxInput(&types.TxOutPoint{Hash: hash.HashH([]byte("prev"))}, []byte{}))
+	mtx.AddTxOut(types.NewTxOutput(types.Amount{Id: types.MEERA, Value: 1e8}, testPayScript(t, "pay")))
+	prevOuts := []*types.TxOutput{types.NewTxOutput(types.Amount{Id: types.MEERA, Value: 9e7}, testPayScript(t, "prev"))}
+	us := []*fundingUTXO{testFundingUTXO(0, types.MEERA, 2e7)}
+
+	funded, fee, _, err := fundTransaction(mtx, prevOuts, us, testPayScript(t, "change"), 20000, testMinRelayFee)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(funded.TxIn) != 2 || funded.TxIn[0].PreviousOut != mtx.TxIn[0].PreviousOut {
+		t.Fatalf("got %d inputs, want the existing input and a funding one", len(funded.TxIn))
+	}
+	checkFunded(t, funded, prevOuts, us, fee)
+}
+
+func TestFundTransactionErrors(t *testing.T) {
+	payScript := testPayScript(t, "pay")
+	us := []*fundingUTXO{
+		testFundingUTXO(0, types.MEERA, 1e8),
+		testFundingUTXO(1, testFundTokenID, 100),
+	}
+
+	tests := []struct {
+		name string
+		out  *types.TxOutput
+		err  string
+	}{
+		{"dust", types.NewTxOutput(types.Amount{Id: types.MEERA, Value: 100}, payScript), "The output 0 is dust"},
+		{"meerb", types.NewTxOutput(types.Amount{Id: types.MEERB, Value: 1e8}, payScript), "Not support"},
+		{"meer funds", types.NewTxOutput(types.Amount{Id: types.MEERA, Value: 1e8}, payScript), "Insufficient funds"},
+		{"token funds", types.NewTxOutput(types.Amount{Id: testFundTokenID, Value: 101}, payScript), "Insufficient funds"},
+	}
+	for _, test := range tests {
+		mtx := types.NewTransaction()
+		mtx.AddTxOut(test.out)
+		_, _, _, err := fundTransaction(mtx, nil, us, payScript, 20000, testMinRelayFee)
+		if err == nil || !strings.Contains(err.Error(), test.err) {
+			t.Errorf("%s: got %v, want %q", test.name, err, test.err)
+		}
+	}
+}
@@ -0,0 +1,56 @@
+package tx
+
+import (
+	"encoding/hex"
+	"github.com/Qitmeer/qng/common/marshal"
+	"github.com/Qitmeer/qng/core/address"
+	"github.com/Qitmeer/qng/engine/txscript"
+	"github.com/Qitmeer/qng/params"
+	"github.com/Qitmeer/qng/rpc"
+)
+
+// decodeHTLCAddress returns the pubkey hash of the pay-to-pubkey-hash address
+// of a hash time-locked contract.
+func decodeHTLCAddress(addrStr string) ([]byte, error) {
+	addr, err := address.DecodeAddress(addrStr)
+	if err != nil {
+		return nil, rpc.RpcAddressKeyError("Could not decode address: %v", err)
+	}
+	if !address.IsForNetwork(addr, params.ActiveNetParams.Params) {
+		return nil, rpc.RpcAddressKeyError("Wrong network: %v", addr)
+	}
+	if _, ok := addr.(*address.PubKeyHashAddress); !ok {
+		return nil, rpc.RpcAddressKeyError("Not a pay-to-pubkey-hash address: %v", addr)
+	}
+	return addr.Hash160()[:], nil
+}
+
+// CreateHTLC returns the hash time-locked contract which pays to the recipient
+// with the secret of the secret hash, or refunds after the lock time.  The
+// hash type of the secret is sha256 by default, or blake256.
+func (api *PublicTxAPI) CreateHTLC(recipient string, refund string, secretHash string, lockTime int64, hashType *string) (interface{}, error) {
+	recipientHash, err := decodeHTLCAddress(recipient)
+	if err != nil {
+		return nil, err
+	}
+	refundHash, err := decodeHTLCAddress(refund)
+	if err != nil {
+		return nil, err
+	}
+	hashBytes, err := hex.DecodeString(secretHash)
+	if err != nil {
+		return nil, rpc.RpcDecodeHexError(secretHash)
+	}
+	hashOp := byte(txscript.OP_SHA256)
+	if hashType != nil && len(*hashType) > 0 {
+		hashOp, err = txscript.ParseHTLCHashOp(*hashType)
+		if err != nil {
+			return nil, rpc.RpcInvalidError("%v", err)
+		}
+	}
+	contract, err := txscript.PayToHTLCScript(hashOp, hashBytes, recipientHash, refundHash, lockTime)
+	if err != nil {
+		return nil, rpc.RpcInvalidError("%v", err)
+	}
+	return marshal.MarshalJsonHTLC(contract, params.ActiveNetParams.Params)
+}
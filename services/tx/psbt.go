@@ -0,0 +1,172 @@
+package tx
+
+import (
+	"bytes"
+	"encoding/hex"
+	"github.com/Qitmeer/qng/common/marshal"
+	"github.com/Qitmeer/qng/core/json"
+	"github.com/Qitmeer/qng/core/psbt"
+	"github.com/Qitmeer/qng/core/types"
+	"github.com/Qitmeer/qng/params"
+	"github.com/Qitmeer/qng/rpc"
+)
+
+func decodePsbt(psbtStr string) (*psbt.Packet, error) {
+	p, err := psbt.NewFromBase64(psbtStr)
+	if err != nil {
+		return nil, rpc.RpcDeserializationError("Could not decode psbt: %v", err)
+	}
+	return p, nil
+}
+
+// fetchPrevOut returns the output from the transactions of the mempool or
+// the UTXO set.
+func (api *PublicTxAPI) fetchPrevOut(op types.TxOutPoint) (*types.TxOutput, error) {
+	tx, _ := api.txManager.txMemPool.FetchTransaction(&op.Hash)
+	if tx != nil {
+		if op.OutIndex >= uint32(len(tx.Tx.TxOut)) {
+			return nil, nil
+		}
+		return tx.Tx.TxOut[op.OutIndex], nil
+	}
+	entry, err := api.txManager.GetChain().FetchUtxoEntry(op)
+	if err != nil {
+		return nil, err
+	}
+	if entry == nil || entry.IsSpent() {
+		return nil, nil
+	}
+	return types.NewTxOutput(entry.Amount(), entry.PkScript()), nil
+}
+
+// CreatePsbt returns the partially signed transaction of the unsigned raw
+// transaction.
+func (api *PublicTxAPI) CreatePsbt(rawTx string) (interface{}, error) {
+	if len(rawTx)%2 != 0 {
+		rawTx = "0" + rawTx
+	}
+	serializedTx, err := hex.DecodeString(rawTx)
+	if err != nil {
+		return nil, rpc.RpcDecodeHexError(rawTx)
+	}
+	var mtx types.Transaction
+	err = mtx.Deserialize(bytes.NewReader(serializedTx))
+	if err != nil {
+		return nil, rpc.RpcDeserializationError("Could not decode Tx: %v", err)
+	}
+	p, err := psbt.New(&mtx)
+	if err != nil {
+		return nil, rpc.RpcInvalidError("%v", err)
+	}
+	return p.B64Encode()
+}
+
+// UpdatePsbt fills the unknown previous outputs of the partially signed
+// transaction from the mempool and the UTXO set, and adds the hex redeem
+// scripts of the pay-to-script-hash inputs which match them.
+func (api *PublicTxAPI) UpdatePsbt(psbtStr string, redeemScripts *[]string) (interface{}, error) {
+	p, err := decodePsbt(psbtStr)
+	if err != nil {
+		return nil, err
+	}
+	for i, txIn := range p.UnsignedTx.TxIn {
+		if p.Inputs[i].PrevOut != nil {
+			continue
+		}
+		prevOut, err := api.fetchPrevOut(txIn.PreviousOut)
+		if err != nil {
+			return nil, err
+		}
+		if prevOut == nil {
+			return nil, rpc.RpcNoTxInfoError(&txIn.PreviousOut.Hash)
+		}
+		err = p.AddInPrevOut(i, prevOut)
+		if err != nil {
+			return nil, err
+		}
+	}
+	if redeemScripts != nil {
+		for _, rs := range *redeemScripts {
+			script, err := hex.DecodeString(rs)
+			if err != nil {
+				return nil, rpc.RpcDecodeHexError(rs)
+			}
+			for i := range p.Inputs {
+				if len(p.Inputs[i].RedeemScript) > 0 {
+					continue
+				}
+				// Only the input whose script hash matches takes it.
+				p.AddInRedeemScript(i, script)
+			}
+		}
+	}
+	return p.B64Encode()
+}
+
+// CombinePsbt merges the partially signed transactions of the same
+// transaction.
+func (api *PublicTxAPI) CombinePsbt(psbts []string) (interface{}, error) {
+	packets := make([]*psbt.Packet, len(psbts))
+	for i, psbtStr := range psbts {
+		p, err := decodePsbt(psbtStr)
+		if err != nil {
+			return nil, err
+		}
+		packets[i] = p
+	}
+	p, err := psbt.Combine(packets...)
+	if err != nil {
+		return nil, rpc.RpcInvalidError("%v", err)
+	}
+	return p.B64Encode()
+}
+
+// FinalizePsbt finalizes the inputs which have enough signatures. When all
+// the inputs are finalized and extract is not false, it returns the signed
+// raw transaction instead.
+func (api *PublicTxAPI) FinalizePsbt(psbtStr string, extract *bool) (interface{}, error) {
+	p, err := decodePsbt(psbtStr)
+	if err != nil {
+		return nil, err
+	}
+	complete := p.MaybeFinalizeAll()
+	if complete && (extract == nil || *extract) {
+		tx, err := p.Extract()
+		if err != nil {
+			return nil, err
+		}
+		mtxHex, err := marshal.MessageToHex(tx)
+		if err != nil {
+			return nil, err
+		}
+		return json.FinalizePsbtResult{Hex: mtxHex, Complete: complete}, nil
+	}
+	result, err := p.B64Encode()
+	if err != nil {
+		return nil, err
+	}
+	return json.FinalizePsbtResult{Psbt: result, Complete: complete}, nil
+}
+
+// DecodePsbt returns the json of the partially signed transaction.  Its fee
+// is only known when the previous outputs are proven by their transactions
+// or match the mempool and the UTXO set.
+func (api *PublicTxAPI) DecodePsbt(psbtStr string) (interface{}, error) {
+	p, err := decodePsbt(psbtStr)
+	if err != nil {
+		return nil, err
+	}
+	for i, txIn := range p.UnsignedTx.TxIn {
+		if p.Inputs[i].PrevOut == nil || p.Inputs[i].IsPrevOutVerified() {
+			continue
+		}
+		prevOut, err := api.fetchPrevOut(txIn.PreviousOut)
+		if err != nil {
+			return nil, err
+		}
+		if prevOut != nil {
+			p.VerifyInPrevOut(i, prevOut)
+		}
+	}
+	return marshal.MarshalJsonPsbt(p, params.ActiveNetParams.Params), nil
+}
@@ -0,0 +1,71 @@
+package tx
+
+import (
+	"bytes"
+	"encoding/hex"
+	"github.com/Qitmeer/qng/common/marshal"
+	"github.com/Qitmeer/qng/core/blockchain"
+	"github.com/Qitmeer/qng/core/blockchain/utxo"
+	"github.com/Qitmeer/qng/core/types"
+	"github.com/Qitmeer/qng/engine/txscript"
+	"github.com/Qitmeer/qng/rpc"
+	"github.com/Qitmeer/qng/services/common"
+)
+
+// TraceTxInput executes the scripts of the input of the raw transaction with
+// the previous public key script by the standard verify flags, and returns
+// every executed opcode with the stacks and the branches before and after
+// it.  The amount of the previous output isn't committed by the signatures,
+// it is only returned with the trace.
+func (api *PublicTxAPI) TraceTxInput(hexTx string, inputIndex int, prevPkScript string, amount int64) (interface{}, error) {
+	if len(hexTx)%2 != 0 {
+		hexTx = "0" + hexTx
+	}
+	serializedTx, err := hex.DecodeString(hexTx)
+	if err != nil {
+		return nil, rpc.RpcDecodeHexError(hexTx)
+	}
+	var mtx types.Transaction
+	err = mtx.Deserialize(bytes.NewReader(serializedTx))
+	if err != nil {
+		return nil, rpc.RpcDeserializationError("Could not decode Tx: %v", err)
+	}
+	pkScript, err := hex.DecodeString(prevPkScript)
+	if err != nil {
+		return nil, rpc.RpcDecodeHexError(prevPkScript)
+	}
+	flags, err := common.StandardScriptVerifyFlags()
+	if err != nil {
+		return nil, err
+	}
+	vm, err := txscript.NewEngine(pkScript, &mtx, inputIndex, flags, txscript.DefaultScriptVersion, nil)
+	if err != nil {
+		return nil, rpc.RpcInvalidError("%v", err)
+	}
+
+	// The token opcodes check the transaction against the token state and
+	// the previous outputs like the mempool.  The traced output keeps the
+	// coin of its entry in the chain or the mempool, and it is only taken
+	// as the given amount of MEER when it is in neither of them.
+	bc := api.txManager.GetChain()
+	tokenState := bc.GetCurTokenState()
+	if tokenState != nil {
+		utxoView, err := bc.FetchUtxoView(types.NewTx(&mtx))
+		if err != nil {
+			return nil, err
+		}
+		prevOut := mtx.TxIn[inputIndex].PreviousOut
+		if utxoView.LookupEntry(prevOut) == nil {
+			prevAmount := types.Amount{Id: types.MEERA, Value: amount}
+			prevTx, err := api.txManager.txMemPool.FetchTransaction(&prevOut.Hash)
+			if err == nil && prevOut.OutIndex < uint32(len(prevTx.Tx.TxOut)) {
+				prevAmount.Id = prevTx.Tx.TxOut[prevOut.OutIndex].Amount.Id
+			}
+			utxoView.AddEntry(prevOut, utxo.NewUtxoEntry(prevAmount, pkScript, nil, false))
+		}
+		vm.SetTokenView(blockchain.NewTokenScriptView(tokenState, utxoView))
+	}
+	vm.EnableTrace()
+	err = vm.Execute()
+	return marshal.MarshalJsonTrace(&mtx, inputIndex, amount, pkScript, vm.Trace(), err), nil
+}
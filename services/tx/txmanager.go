@@ -233,6 +233,9 @@ func NewTxManager(consensus model.Consensus, ntmgr vmconsensus.Notify) (*TxManag
 		NoMempoolBar:     cfg.NoMempoolBar,
 		Events:           consensus.Events(),
 	}
+	if ntmgr != nil {
+		txC.RemoveTxNotify = ntmgr.TransactionRemoved
+	}
 	txMemPool := mempool.New(&txC)
 	invalidTx := make(map[hash.Hash]*meerdag.HashSet)
 	tm:= &TxManager{
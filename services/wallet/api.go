@@ -0,0 +1,240 @@
+package wallet
+
+import (
+	"bytes"
+	"encoding/base64"
+	"encoding/hex"
+	"fmt"
+	"github.com/Qitmeer/qng/common/marshal"
+	"github.com/Qitmeer/qng/core/json"
+	"github.com/Qitmeer/qng/core/psbt"
+	"github.com/Qitmeer/qng/core/types"
+	"github.com/Qitmeer/qng/engine/txscript"
+	"github.com/Qitmeer/qng/rpc"
+	"time"
+)
+
+// PrivateWalletAPI provides the wallet API, it is only exposed by the
+// "wallet" module.
+type PrivateWalletAPI struct {
+	w *WalletManager
+}
+
+func NewPrivateWalletAPI(w *WalletManager) *PrivateWalletAPI {
+	return &PrivateWalletAPI{w}
+}
+
+// CreateWallet creates the wallet encrypted by the passphrase, the hex seed
+// restores an existing wallet.
+func (api *PrivateWalletAPI) CreateWallet(passphrase string, seed *string) (interface{}, error) {
+	var data []byte
+	if seed != nil && len(*seed) > 0 {
+		var err error
+		data, err = decodeSeed(*seed)
+		if err != nil {
+			return nil, err
+		}
+	}
+	return nil, api.w.Create(passphrase, data)
+}
+
+// Unlock unlocks the wallet for the timeout in seconds, which must not be
+// zero.
+func (api *PrivateWalletAPI) Unlock(passphrase string, timeout uint32) (interface{}, error) {
+	return nil, api.w.Unlock(passphrase, time.Duration(timeout)*time.Second)
+}
+
+func (api *PrivateWalletAPI) Lock() (interface{}, error) {
+	api.w.Lock()
+	return nil, nil
+}
+
+func (api *PrivateWalletAPI) GetWalletInfo() (interface{}, error) {
+	w := api.w
+	w.lock.Lock()
+	defer w.lock.Unlock()
+	if w.ks == nil {
+		return nil, fmt.Errorf("No wallet, please create it first (RPC:createWallet)")
+	}
+	result := json.WalletInfoResult{
+		Version:       w.ks.Version,
+		Unlocked:      w.master != nil,
+		UnlockedUntil: w.unlockedUntil,
+		Accounts:      []json.WalletAccountResult{},
+	}
+	for _, aj := range w.ks.Accounts {
+		result.Accounts = append(result.Accounts, json.WalletAccountResult{
+			Name:     aj.Name,
+			Index:    aj.Index,
+			Xpub:     aj.Xpub,
+			External: aj.External,
+			Internal: aj.Internal,
+		})
+	}
+	return result, nil
+}
+
+func (api *PrivateWalletAPI) GetNewAddress(account *string) (interface{}, error) {
+	name := ""
+	if account != nil {
+		name = *account
+	}
+	return api.w.GetNewAddress(name)
+}
+
+func (api *PrivateWalletAPI) ListUnspent(coinID *uint16, addrs *[]string) (interface{}, error) {
+	var coin *types.CoinID
+	if coinID != nil {
+		c := types.CoinID(*coinID)
+		coin = &c
+	}
+	var as []string
+	if addrs != nil {
+		as = *addrs
+	}
+	return api.w.ListUnspent(coin, as)
+}
+
+func (api *PrivateWalletAPI) SendToAddress(addr string, amount int64, coinID *uint16) (interface{}, error) {
+	coin := types.MEERA
+	if coinID != nil {
+		coin = types.CoinID(*coinID)
+	}
+	txHash, err := api.w.Send([]*TxOutput{{Address: addr, Amount: types.Amount{Id: coin, Value: amount}}})
+	if err != nil {
+		return nil, err
+	}
+	return txHash.String(), nil
+}
+
+func (api *PrivateWalletAPI) SendMany(amounts json.AdreesAmount) (interface{}, error) {
+	outputs := []*TxOutput{}
+	for addr, amount := range amounts {
+		outputs = append(outputs, &TxOutput{Address: addr, Amount: types.Amount{Id: types.CoinID(amount.CoinId), Value: amount.Amount}})
+	}
+	txHash, err := api.w.Send(outputs)
+	if err != nil {
+		return nil, err
+	}
+	return txHash.String(), nil
+}
+
+// SignRawTransactionWithWallet signs the inputs of the raw transaction which
+// spend the outputs of the wallet.
+func (api *PrivateWalletAPI) SignRawTransactionWithWallet(rawTx string) (interface{}, error) {
+	if len(rawTx)%2 != 0 {
+		rawTx = "0" + rawTx
+	}
+	serializedTx, err := hex.DecodeString(rawTx)
+	if err != nil {
+		return nil, rpc.RpcDecodeHexError(rawTx)
+	}
+	var mtx types.Transaction
+	err = mtx.Deserialize(bytes.NewReader(serializedTx))
+	if err != nil {
+		return nil, rpc.RpcDeserializationError("Could not decode Tx: %v", err)
+	}
+	complete, err := api.w.SignRawTransaction(&mtx)
+	if err != nil {
+		return nil, err
+	}
+	mtxHex, err := marshal.MessageToHex(&mtx)
+	if err != nil {
+		return nil, err
+	}
+	return json.SignRawTransactionResult{Hex: mtxHex, Complete: complete}, nil
+}
+
+// SignPsbt signs the inputs of the partially signed transaction which the
+// wallet keys own, by the sighash type like "ALL" when it is given.
+func (api *PrivateWalletAPI) SignPsbt(psbtStr string, sighash *string) (interface{}, error) {
+	p, err := psbt.NewFromBase64(psbtStr)
+	if err != nil {
+		return nil, rpc.RpcDeserializationError("Could not decode psbt: %v", err)
+	}
+	hashType := txscript.SigHashType(0)
+	if sighash != nil && len(*sighash) > 0 {
+		hashType, err = psbt.ParseSighashType(*sighash)
+		if err != nil {
+			return nil, rpc.RpcInvalidError("%v", err)
+		}
+	}
+	complete, err := api.w.SignPsbt(p, hashType)
+	if err != nil {
+		return nil, err
+	}
+	result, err := p.B64Encode()
+	if err != nil {
+		return nil, err
+	}
+	return json.PsbtResult{Psbt: result, Complete: complete}, nil
+}
+
+// SignMessage signs the message by the key of the wallet address, and returns
+// the signature in base64 like qx msg-sign.
+func (api *PrivateWalletAPI) SignMessage(addr string, message string) (interface{}, error) {
+	sig, err := api.w.SignMessage(addr, message)
+	if err != nil {
+		return nil, err
+	}
+	return base64.StdEncoding.EncodeToString(sig), nil
+}
+
+func decodeRawTx(rawTx string) (*types.Transaction, error) {
+	if len(rawTx)%2 != 0 {
+		rawTx = "0" + rawTx
+	}
+	serializedTx, err := hex.DecodeString(rawTx)
+	if err != nil {
+		return nil, rpc.RpcDecodeHexError(rawTx)
+	}
+	var mtx types.Transaction
+	err = mtx.Deserialize(bytes.NewReader(serializedTx))
+	if err != nil {
+		return nil, rpc.RpcDeserializationError("Could not decode Tx: %v", err)
+	}
+	return &mtx, nil
+}
+
+// RedeemHTLC signs the input of the raw transaction which spends the hash
+// time-locked contract to the wallet address of the recipient with the
+// secret, and returns the signed raw transaction.
+func (api *PrivateWalletAPI) RedeemHTLC(rawTx string, inputIndex int, contract string, secret string) (interface{}, error) {
+	mtx, err := decodeRawTx(rawTx)
+	if err != nil {
+		return nil, err
+	}
+	contractBytes, err := hex.DecodeString(contract)
+	if err != nil {
+		return nil, rpc.RpcDecodeHexError(contract)
+	}
+	secretBytes, err := hex.DecodeString(secret)
+	if err != nil {
+		return nil, rpc.RpcDecodeHexError(secret)
+	}
+	err = api.w.SignHTLC(mtx, inputIndex, contractBytes, secretBytes)
+	if err != nil {
+		return nil, err
+	}
+	return marshal.MessageToHex(mtx)
+}
+
+// RefundHTLC signs the input of the raw transaction which refunds the hash
+// time-locked contract to the wallet address of the refund, and returns the
+// signed raw transaction.  The raw transaction must have reached the lock
+// time of the contract, and the sequence of the input must not be final.
+func (api *PrivateWalletAPI) RefundHTLC(rawTx string, inputIndex int, contract string) (interface{}, error) {
+	mtx, err := decodeRawTx(rawTx)
+	if err != nil {
+		return nil, err
+	}
+	contractBytes, err := hex.DecodeString(contract)
+	if err != nil {
+		return nil, rpc.RpcDecodeHexError(contract)
+	}
+	err = api.w.SignHTLC(mtx, inputIndex, contractBytes, nil)
+	if err != nil {
+		return nil, err
+	}
+	return marshal.MessageToHex(mtx)
+}
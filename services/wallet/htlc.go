@@ -0,0 +1,69 @@
+package wallet
+
+import (
+	"bytes"
+	"fmt"
+	"github.com/Qitmeer/qng/common/hash"
+	"github.com/Qitmeer/qng/core/address"
+	"github.com/Qitmeer/qng/core/types"
+	"github.com/Qitmeer/qng/crypto/ecc"
+	"github.com/Qitmeer/qng/engine/txscript"
+	"github.com/Qitmeer/qng/params"
+)
+
+// SignHTLC signs the input of the transaction which spends the hash
+// time-locked contract, directly or by its pay-to-script-hash output.  With
+// the secret it redeems the contract by the key of the recipient, otherwise
+// it refunds the contract by the key of the refund, both of which must be
+// wallet addresses.
+func (w *WalletManager) SignHTLC(mtx *types.Transaction, idx int, contract []byte, secret []byte) error {
+	w.lock.Lock()
+	defer w.lock.Unlock()
+	err := w.isUnlocked()
+	if err != nil {
+		return err
+	}
+	if idx < 0 || idx >= len(mtx.TxIn) {
+		return fmt.Errorf("The input index %d is out of range", idx)
+	}
+	pushes, err := txscript.ExtractHTLCDataPushes(contract)
+	if err != nil {
+		return err
+	}
+	prevOut, err := w.fetchPrevOut(mtx.TxIn[idx].PreviousOut)
+	if err != nil {
+		return err
+	}
+	if prevOut == nil {
+		return fmt.Errorf("The previous output of the input %d is unknown", idx)
+	}
+	p2sh := false
+	if !bytes.Equal(prevOut.PkScript, contract) {
+		if !txscript.IsPayToScriptHash(prevOut.PkScript) {
+			return fmt.Errorf("The input %d doesn't spend the contract", idx)
+		}
+		scriptHash, err := txscript.GetScriptHashFromP2SHScript(prevOut.PkScript)
+		if err != nil || !bytes.Equal(scriptHash, hash.Hash160(contract)) {
+			return fmt.Errorf("The input %d doesn't spend the contract", idx)
+		}
+		p2sh = true
+	}
+	pkh := pushes.RefundHash160
+	if secret != nil {
+		pkh = pushes.RecipientHash160
+	}
+	addr, err := address.NewPubKeyHashAddress(pkh[:], params.ActiveNetParams.Params, ecc.ECDSA_Secp256k1)
+	if err != nil {
+		return err
+	}
+	key, err := w.privateKey(addr.String())
+	if err != nil {
+		return err
+	}
+	sigScript, err := txscript.SignHTLC(mtx, idx, contract, txscript.SigHashAll, key, secret, p2sh)
+	if err != nil {
+		return err
+	}
+	mtx.TxIn[idx].SignScript = sigScript
+	return nil
+}
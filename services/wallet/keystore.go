@@ -0,0 +1,135 @@
+package wallet
+
+import (
+	"encoding/json"
+	"fmt"
+	"github.com/Qitmeer/qng/common/hash"
+	"github.com/Qitmeer/qng/core/address"
+	"github.com/Qitmeer/qng/crypto/bip32"
+	"github.com/Qitmeer/qng/crypto/ecc"
+	"github.com/Qitmeer/qng/params"
+	"github.com/ethereum/go-ethereum/accounts/keystore"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+)
+
+const (
+	walletDirName  = "wallet"
+	walletFileName = "wallet.json"
+
+	CurrentWalletVersion = 1
+
+	// The BIP44 branches of the account addresses.
+	ExternalBranch = 0
+	InternalBranch = 1
+
+	DefaultAccountName = "default"
+)
+
+// accountJSON is an account of the keystore file, its addresses are derived
+// from the account extended public key so they are known while the wallet is
+// locked.
+type accountJSON struct {
+	Name     string `json:"name"`
+	Index    uint32 `json:"index"`
+	Xpub     string `json:"xpub"`
+	External uint32 `json:"external"`
+	Internal uint32 `json:"internal"`
+}
+
+// keystoreJSON is the keystore file, the HD seed is encrypted by the
+// passphrase with scrypt and AES.
+type keystoreJSON struct {
+	Version  int                 `json:"version"`
+	Crypto   keystore.CryptoJSON `json:"crypto"`
+	Accounts []*accountJSON      `json:"accounts"`
+}
+
+// addrPath is the derivation path of a wallet address relative to the
+// BIP44 purpose and coin type.
+type addrPath struct {
+	account uint32
+	branch  uint32
+	index   uint32
+}
+
+func (ap *addrPath) String() string {
+	return fmt.Sprintf("m/44'/%d'/%d'/%d/%d", params.ActiveNetParams.SLIP0044CoinType, ap.account, ap.branch, ap.index)
+}
+
+func getKeystorePath(dataDir string) string {
+	return filepath.Join(dataDir, walletDirName, walletFileName)
+}
+
+func loadKeystore(path string) (*keystoreJSON, error) {
+	data, err := ioutil.ReadFile(path)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return nil, nil
+		}
+		return nil, err
+	}
+	ks := &keystoreJSON{}
+	err = json.Unmarshal(data, ks)
+	if err != nil {
+		return nil, err
+	}
+	if ks.Version != CurrentWalletVersion {
+		return nil, fmt.Errorf("The wallet version is not supported:%d", ks.Version)
+	}
+	return ks, nil
+}
+
+func saveKeystore(path string, ks *keystoreJSON) error {
+	data, err := json.MarshalIndent(ks, "", "  ")
+	if err != nil {
+		return err
+	}
+	err = os.MkdirAll(filepath.Dir(path), 0700)
+	if err != nil {
+		return err
+	}
+	// Write to a temporary file first so the keystore is never truncated.
+	tmp := path + ".tmp"
+	err = ioutil.WriteFile(tmp, data, 0600)
+	if err != nil {
+		return err
+	}
+	return os.Rename(tmp, path)
+}
+
+// netBip32Version is the version of the extended keys of the current network.
+func netBip32Version() bip32.Bip32Version {
+	return bip32.Bip32Version{
+		PrivKeyVersion: params.ActiveNetParams.HDPrivateKeyID[:],
+		PubKeyVersion:  params.ActiveNetParams.HDPublicKeyID[:],
+	}
+}
+
+// accountKey derives the key of the BIP44 account m/44'/coin'/account' from
+// the master key.
+func accountKey(master *bip32.Key, account uint32) (*bip32.Key, error) {
+	key := master
+	for _, idx := range []uint32{44, params.ActiveNetParams.SLIP0044CoinType, account} {
+		var err error
+		key, err = key.NewChildKey(bip32.FirstHardenedChild + idx)
+		if err != nil {
+			return nil, err
+		}
+	}
+	return key, nil
+}
+
+// pubKeyHashAddress returns the pay-to-pubkey-hash address of the key.
+func pubKeyHashAddress(key *bip32.Key) (string, error) {
+	pubKey := key.Key
+	if key.IsPrivate {
+		pubKey = key.PublicKey().Key
+	}
+	addr, err := address.NewPubKeyHashAddress(hash.Hash160(pubKey), params.ActiveNetParams.Params, ecc.ECDSA_Secp256k1)
+	if err != nil {
+		return "", err
+	}
+	return addr.String(), nil
+}
@@ -0,0 +1,145 @@
+package wallet
+
+import (
+	"bytes"
+	"encoding/hex"
+	"testing"
+	"time"
+
+	"github.com/Qitmeer/qng/common/hash"
+	"github.com/Qitmeer/qng/config"
+	"github.com/Qitmeer/qng/core/address"
+	"github.com/Qitmeer/qng/crypto/bip32"
+	"github.com/Qitmeer/qng/crypto/ecc"
+	"github.com/Qitmeer/qng/params"
+)
+
+// The BIP32 test vector 1 seed.
+const testSeed = "000102030405060708090a0b0c0d0e0f"
+
+// testAddresses derives the first addresses of the default account branches.
+func testAddresses(t *testing.T, w *WalletManager, external uint32, internal uint32) {
+	w.lock.Lock()
+	defer w.lock.Unlock()
+	w.ks.Accounts[0].External = external
+	w.ks.Accounts[0].Internal = internal
+	if err := w.load(w.ks); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestKeystoreRoundTrip(t *testing.T) {
+	seed, _ := hex.DecodeString(testSeed)
+	w := newTestWallet(t, seed)
+	if err := w.Create(testPassphrase, nil); err == nil {
+		t.Fatalf("the wallet is created twice")
+	}
+
+	// The wallet loaded from the keystore file decrypts the same seed.
+	ks, err := loadKeystore(w.ksPath)
+	if err != nil {
+		t.Fatal(err)
+	}
+	loaded, err := New(&config.Config{DataDir: w.cfg.DataDir}, nil, nil, nil, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := loaded.load(ks); err != nil {
+		t.Fatal(err)
+	}
+	if err := loaded.Unlock(testPassphrase, time.Hour); err != nil {
+		t.Fatal(err)
+	}
+	defer loaded.Lock()
+	master, err := bip32.NewMasterKey2(seed, netBip32Version())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !bytes.Equal(loaded.master.Key, master.Key) || !bytes.Equal(loaded.master.ChainCode, master.ChainCode) {
+		t.Fatalf("the decrypted master key is different")
+	}
+	if loaded.ks.Accounts[0].Xpub != w.ks.Accounts[0].Xpub {
+		t.Fatalf("got the account key %s, want %s", loaded.ks.Accounts[0].Xpub, w.ks.Accounts[0].Xpub)
+	}
+}
+
+func TestWrongPassphrase(t *testing.T) {
+	w := newTestWallet(t, nil)
+	if err := w.Unlock("wrong", time.Hour); err == nil {
+		t.Fatalf("the wallet is unlocked by a wrong passphrase")
+	}
+	if w.testUnlocked() {
+		t.Fatalf("the wallet is unlocked by a wrong passphrase")
+	}
+	if _, err := w.SignMessage("", "hello"); err == nil {
+		t.Fatalf("a locked wallet signs")
+	}
+
+	// The wallet is still unlocked by the right passphrase.
+	if err := w.Unlock(testPassphrase, time.Hour); err != nil {
+		t.Fatal(err)
+	}
+	w.Lock()
+}
+
+func TestEmptyPassphrase(t *testing.T) {
+	w, err := New(&config.Config{DataDir: t.TempDir()}, nil, nil, nil, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := w.Create("", nil); err == nil {
+		t.Fatalf("the wallet is created without a passphrase")
+	}
+}
+
+func TestDerivation(t *testing.T) {
+	seed, _ := hex.DecodeString(testSeed)
+	w := newTestWallet(t, seed)
+	testAddresses(t, w, 2, 1)
+	if err := w.Unlock(testPassphrase, time.Hour); err != nil {
+		t.Fatal(err)
+	}
+	defer w.Lock()
+
+	// The keys and the addresses of m/44'/813'/0'/branch/index of the seed
+	// on the main network.
+	vectors := []struct {
+		path   string
+		pubKey string
+		addr   string
+	}{
+		{"m/44'/813'/0'/0/0", "03b530bce7ed7f52fb4c4413f07cf198aa728efe6fe4b5694f56f39ad39e012884", "MmNSuA9pAHQW7fFLffPjc8RsxyjNA5Tponn"},
+		{"m/44'/813'/0'/0/1", "020e3a5a7cc0cc492bf769036c70882b24efcbf2a7cd3df09de0389227c7048786", "MmXiKLJuG514yaTAKauBgEzWLF6WDXAU1T4"},
+		{"m/44'/813'/0'/1/0", "03034d7a40a9900bf1a9f0a629df406f3ec68724f790d36f7701a25e6890aa2565", "Mme8B7oN5xVyJge1VjTk47GZmFbtKF38qi4"},
+	}
+	if len(w.addrs) != len(vectors) {
+		t.Fatalf("got %d addresses, want %d", len(w.addrs), len(vectors))
+	}
+	for _, v := range vectors {
+		ap, ok := w.addrs[v.addr]
+		if !ok {
+			t.Errorf("%s: the address %s isn't derived", v.path, v.addr)
+			continue
+		}
+		if ap.String() != v.path {
+			t.Errorf("%s: got the path %s", v.addr, ap.String())
+		}
+		// The key derived from the master key is the key of the address
+		// derived from the account public key.
+		key, err := w.privateKey(v.addr)
+		if err != nil {
+			t.Fatal(err)
+		}
+		_, pub := ecc.Secp256k1.PrivKeyFromBytes(key.Serialize())
+		if hex.EncodeToString(pub.SerializeCompressed()) != v.pubKey {
+			t.Errorf("%s: got the key %x", v.path, pub.SerializeCompressed())
+		}
+		pkh, err := address.NewPubKeyHashAddress(hash.Hash160(pub.SerializeCompressed()), params.ActiveNetParams.Params, ecc.ECDSA_Secp256k1)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if pkh.String() != v.addr {
+			t.Errorf("%s: got the key of %s", v.addr, pkh)
+		}
+	}
+}
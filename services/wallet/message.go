@@ -0,0 +1,22 @@
+package wallet
+
+import (
+	"github.com/Qitmeer/qng/core/address"
+)
+
+// SignMessage signs the message by the key of the wallet address, the
+// compact signature is verified by verifyMessage and qx msg-verify.
+func (w *WalletManager) SignMessage(addr string, msg string) ([]byte, error) {
+	w.lock.Lock()
+	defer w.lock.Unlock()
+	err := w.isUnlocked()
+	if err != nil {
+		return nil, err
+	}
+	key, err := w.privateKey(addr)
+	if err != nil {
+		return nil, err
+	}
+	// The wallet addresses are the hashes of the compressed public keys.
+	return address.SignMessage(key, true, msg)
+}
@@ -0,0 +1,44 @@
+package wallet
+
+import (
+	"testing"
+	"time"
+
+	"github.com/Qitmeer/qng/core/address"
+	"github.com/Qitmeer/qng/crypto/ecc"
+	"github.com/Qitmeer/qng/params"
+)
+
+func TestSignMessage(t *testing.T) {
+	w := newTestWallet(t, nil)
+	testAddresses(t, w, 1, 0)
+	var addr string
+	for a := range w.addrs {
+		addr = a
+	}
+	if err := w.Unlock(testPassphrase, time.Hour); err != nil {
+		t.Fatal(err)
+	}
+	defer w.Lock()
+
+	sig, err := w.SignMessage(addr, "hello")
+	if err != nil {
+		t.Fatal(err)
+	}
+	a, err := address.DecodeAddress(addr)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for msg, want := range map[string]bool{"hello": true, "bye": false} {
+		ok, err := address.VerifyMessage(a, sig, msg)
+		if err != nil || ok != want {
+			t.Errorf("%s: got %v %v, want %v", msg, ok, err, want)
+		}
+	}
+
+	// Only the wallet addresses are signed by.
+	other, _ := address.NewPubKeyHashAddress(make([]byte, 20), params.ActiveNetParams.Params, ecc.ECDSA_Secp256k1)
+	if _, err := w.SignMessage(other.String(), "hello"); err == nil {
+		t.Fatalf("signed by an address which isn't in the wallet")
+	}
+}
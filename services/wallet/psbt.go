@@ -0,0 +1,102 @@
+package wallet
+
+import (
+	"encoding/binary"
+	"fmt"
+	"github.com/Qitmeer/qng/common/hash"
+	"github.com/Qitmeer/qng/core/psbt"
+	"github.com/Qitmeer/qng/crypto/bip32"
+	"github.com/Qitmeer/qng/crypto/ecc"
+	"github.com/Qitmeer/qng/engine/txscript"
+	"github.com/Qitmeer/qng/params"
+)
+
+// SignPsbt signs the inputs of the partially signed transaction by the keys
+// of the wallet addresses, including the keys of the multisig scripts, and
+// adds the BIP32 derivation hints of the keys.  The previous outputs which
+// aren't proven by their transactions are filled from or checked against the
+// UTXO set and the mempool.  The inputs which have enough signatures are
+// finalized, it returns whether all the inputs are.
+func (w *WalletManager) SignPsbt(p *psbt.Packet, hashType txscript.SigHashType) (bool, error) {
+	w.lock.Lock()
+	defer w.lock.Unlock()
+	err := w.isUnlocked()
+	if err != nil {
+		return false, err
+	}
+	fingerprint := binary.LittleEndian.Uint32(hash.Hash160(w.master.PublicKey().Key)[:4])
+	param := params.ActiveNetParams.Params
+	for i, txIn := range p.UnsignedTx.TxIn {
+		pi := &p.Inputs[i]
+		if pi.IsFinalized() {
+			continue
+		}
+		if !pi.IsPrevOutVerified() {
+			prevOut, err := w.fetchPrevOut(txIn.PreviousOut)
+			if err != nil {
+				return false, err
+			}
+			if prevOut == nil {
+				continue
+			}
+			if pi.PrevOut == nil {
+				err = p.AddInPrevOut(i, prevOut)
+				if err != nil {
+					return false, err
+				}
+			}
+			err = p.VerifyInPrevOut(i, prevOut)
+			if err != nil {
+				return false, fmt.Errorf("input %d: %v", i, err)
+			}
+		}
+		if hashType != 0 && pi.SighashType == 0 {
+			pi.SighashType = hashType
+		}
+		subScript := pi.PrevOut.PkScript
+		if txscript.IsPayToScriptHash(subScript) {
+			if len(pi.RedeemScript) <= 0 {
+				continue
+			}
+			subScript = pi.RedeemScript
+		}
+		_, addrs, _, err := txscript.ExtractPkScriptAddrs(subScript, param)
+		if err != nil {
+			continue
+		}
+		for _, addr := range addrs {
+			pkh := pkhString(addr)
+			ap, ok := w.addrs[pkh]
+			if !ok {
+				continue
+			}
+			key, err := w.privateKey(pkh)
+			if err != nil {
+				return false, err
+			}
+			ok, err = p.Sign(i, key)
+			if err != nil {
+				return false, err
+			}
+			if !ok {
+				continue
+			}
+			_, pub := ecc.Secp256k1.PrivKeyFromBytes(key.Serialize())
+			err = p.AddInBip32Derivation(i, &psbt.Bip32Derivation{
+				PubKey:               pub.SerializeCompressed(),
+				MasterKeyFingerprint: fingerprint,
+				Path: []uint32{
+					bip32.FirstHardenedChild + 44,
+					bip32.FirstHardenedChild + params.ActiveNetParams.SLIP0044CoinType,
+					bip32.FirstHardenedChild + ap.account,
+					ap.branch,
+					ap.index,
+				},
+			})
+			if err != nil {
+				return false, err
+			}
+		}
+	}
+	return p.MaybeFinalizeAll(), nil
+}
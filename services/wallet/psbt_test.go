@@ -0,0 +1,73 @@
+package wallet
+
+import (
+	"testing"
+	"time"
+
+	"github.com/Qitmeer/qng/common/hash"
+	"github.com/Qitmeer/qng/core/address"
+	"github.com/Qitmeer/qng/core/psbt"
+	"github.com/Qitmeer/qng/core/types"
+	"github.com/Qitmeer/qng/engine/txscript"
+)
+
+func TestSignPsbt(t *testing.T) {
+	w := newTestWallet(t, nil)
+	testAddresses(t, w, 1, 0)
+	var addr string
+	for a := range w.addrs {
+		addr = a
+	}
+	a, err := address.DecodeAddress(addr)
+	if err != nil {
+		t.Fatal(err)
+	}
+	pkScript, err := txscript.PayToAddrScript(a)
+	if err != nil {
+		t.Fatal(err)
+	}
+	prevTx := types.NewTransaction()
+	prevTx.AddTxIn(types.NewTxInput(types.NewOutPoint(&hash.Hash{0x01}, 0), nil))
+	prevTx.AddTxOut(types.NewTxOutput(types.Amount{Id: types.MEERA, Value: 1e8}, pkScript))
+	prevHash := prevTx.TxHash()
+	tx := types.NewTransaction()
+	tx.AddTxIn(types.NewTxInput(types.NewOutPoint(&prevHash, 0), nil))
+	tx.AddTxOut(types.NewTxOutput(types.Amount{Id: types.MEERA, Value: 9e7}, pkScript))
+	p, err := psbt.New(tx)
+	if err != nil {
+		t.Fatal(err)
+	}
+	err = p.AddInPrevTx(0, prevTx)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if _, err := w.SignPsbt(p, txscript.SigHashAll); err == nil {
+		t.Fatalf("a locked wallet signs")
+	}
+	if err := w.Unlock(testPassphrase, time.Hour); err != nil {
+		t.Fatal(err)
+	}
+	defer w.Lock()
+	complete, err := w.SignPsbt(p, txscript.SigHashAll)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !complete {
+		t.Fatalf("the packet isn't complete")
+	}
+
+	signed, err := p.Extract()
+	if err != nil {
+		t.Fatal(err)
+	}
+	flags := txscript.ScriptBip16 | txscript.ScriptVerifyDERSignatures |
+		txscript.ScriptVerifyStrictEncoding | txscript.ScriptVerifyMinimalData
+	vm, err := txscript.NewEngine(pkScript, signed, 0, flags, 0, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := vm.Execute(); err != nil {
+		t.Fatal(err)
+	}
+}
@@ -0,0 +1,398 @@
+package wallet
+
+import (
+	"fmt"
+	"github.com/Qitmeer/qng/common/hash"
+	"github.com/Qitmeer/qng/core/address"
+	"github.com/Qitmeer/qng/core/json"
+	"github.com/Qitmeer/qng/core/message"
+	"github.com/Qitmeer/qng/core/types"
+	"github.com/Qitmeer/qng/crypto/ecc"
+	"github.com/Qitmeer/qng/engine/txscript"
+	"github.com/Qitmeer/qng/params"
+	"github.com/Qitmeer/qng/rpc"
+	"github.com/Qitmeer/qng/services/acct"
+	"github.com/Qitmeer/qng/services/mempool"
+	"sort"
+)
+
+const (
+	// p2pkhInputSize is the serialized size of a pay-to-pubkey-hash input
+	// with a compressed public key, it is used by the dust threshold like
+	// the mempool.
+	p2pkhInputSize = 165
+)
+
+// walletUTXO is a spendable output of a wallet address.
+type walletUTXO struct {
+	*acct.UnspentUTXO
+	addr string
+}
+
+// TxOutput is an output of the transactions sent by the wallet.
+type TxOutput struct {
+	Address string
+	Amount  types.Amount
+}
+
+// listUnspent returns the spendable outputs of the wallet addresses which
+// are not spent by the transactions of the mempool.
+func (w *WalletManager) listUnspent(addrs []string) ([]*walletUTXO, error) {
+	if !w.cfg.AcctMode {
+		return nil, fmt.Errorf("Please enable --acctmode")
+	}
+	if len(addrs) <= 0 {
+		for addr := range w.addrs {
+			addrs = append(addrs, addr)
+		}
+	}
+	result := []*walletUTXO{}
+	for _, addr := range addrs {
+		addr = address.NormalizeAddress(addr)
+		if _, ok := w.addrs[addr]; !ok {
+			return nil, fmt.Errorf("Not the wallet address:%s", addr)
+		}
+		us, err := w.acct.ListUnspent(addr)
+		if err != nil {
+			return nil, err
+		}
+		for _, u := range us {
+			if w.txpool.CheckSpend(u.OutPoint) != nil {
+				continue
+			}
+			result = append(result, &walletUTXO{UnspentUTXO: u, addr: addr})
+		}
+	}
+	sort.Slice(result, func(i, j int) bool {
+		if result[i].Amount.Value == result[j].Amount.Value {
+			return result[i].OutPoint.Hash.String() < result[j].OutPoint.Hash.String()
+		}
+		return result[i].Amount.Value > result[j].Amount.Value
+	})
+	return result, nil
+}
+
+// ListUnspent returns the spendable outputs of the wallet, the coin and the
+// addresses are optional filters.
+func (w *WalletManager) ListUnspent(coinID *types.CoinID, addrs []string) ([]json.WalletUnspentResult, error) {
+	w.lock.Lock()
+	defer w.lock.Unlock()
+	if w.ks == nil {
+		return nil, fmt.Errorf("No wallet, please create it first (RPC:createWallet)")
+	}
+	us, err := w.listUnspent(addrs)
+	if err != nil {
+		return nil, err
+	}
+	result := []json.WalletUnspentResult{}
+	for _, u := range us {
+		if coinID != nil && u.Amount.Id != *coinID {
+			continue
+		}
+		result = append(result, json.WalletUnspentResult{
+			TxId:    u.OutPoint.Hash.String(),
+			Vout:    u.OutPoint.OutIndex,
+			Address: u.addr,
+			CoinId:  uint16(u.Amount.Id),
+			Coin:    u.Amount.Id.Name(),
+			Amount:  u.Amount.Value,
+			Type:    u.Type,
+		})
+	}
+	return result, nil
+}
+
+// minRelayFee returns the minimum relay fee of the serialized size like the
+// mempool, the fees are always paid in MEER.
+func (w *WalletManager) minRelayFee(size int) int64 {
+	fee := int64(size) * w.cfg.MinTxFee / 1000
+	if fee == 0 && w.cfg.MinTxFee > 0 {
+		fee = w.cfg.MinTxFee
+	}
+	return fee
+}
+
+// isDust returns whether the MEER output costs more than a third of its
+// value to be spent, like the mempool.
+func (w *WalletManager) isDust(txOut *types.TxOutput) bool {
+	if txOut.Amount.Id != types.MEERA {
+		return false
+	}
+	totalSize := txOut.SerializeSize() + p2pkhInputSize
+	return txOut.Amount.Value*1000/(3*int64(totalSize)) < w.cfg.MinTxFee
+}
+
+// selectUTXOs selects the outputs of the coin, largest first, until the
+// amount is covered.
+func selectUTXOs(us []*walletUTXO, coin types.CoinID, amount int64) ([]*walletUTXO, int64, error) {
+	selected := []*walletUTXO{}
+	total := int64(0)
+	for _, u := range us {
+		if total >= amount && len(selected) > 0 {
+			break
+		}
+		if u.Amount.Id != coin {
+			continue
+		}
+		selected = append(selected, u)
+		total += u.Amount.Value
+	}
+	if total < amount || len(selected) <= 0 {
+		return nil, 0, fmt.Errorf("Insufficient funds of %s: %d < %d", coin.Name(), total, amount)
+	}
+	return selected, total, nil
+}
+
+// Send builds, signs and submits the transaction paying to the outputs.  The
+// inputs are selected from the wallet outputs of every coin, the change goes
+// to a new internal address of the default account and the minimum relay fee
+// is paid in MEER.
+func (w *WalletManager) Send(outputs []*TxOutput) (*hash.Hash, error) {
+	w.lock.Lock()
+	defer w.lock.Unlock()
+	err := w.isUnlocked()
+	if err != nil {
+		return nil, err
+	}
+	if len(outputs) <= 0 {
+		return nil, fmt.Errorf("No outputs")
+	}
+	amounts := map[types.CoinID]int64{}
+	txOuts := []*types.TxOutput{}
+	for _, output := range outputs {
+		if output.Amount.Value <= 0 || output.Amount.Value > types.MaxAmount {
+			return nil, rpc.RpcInvalidError("Invalid amount: 0 >= %v > %v", output.Amount.Value, types.MaxAmount)
+		}
+		err = types.CheckCoinID(output.Amount.Id)
+		if err != nil {
+			return nil, rpc.RpcInvalidError(err.Error())
+		}
+		if output.Amount.Id == types.MEERB {
+			return nil, rpc.RpcInvalidError("Not support %v", output.Amount.Id)
+		}
+		addr, err := address.DecodeAddress(output.Address)
+		if err != nil {
+			return nil, rpc.RpcAddressKeyError("Could not decode address: %v", err)
+		}
+		if !address.IsForNetwork(addr, params.ActiveNetParams.Params) {
+			return nil, rpc.RpcAddressKeyError("Wrong network: %v", addr)
+		}
+		pkScript, err := txscript.PayToAddrScript(addr)
+		if err != nil {
+			return nil, err
+		}
+		txOut := types.NewTxOutput(output.Amount, pkScript)
+		if w.isDust(txOut) {
+			return nil, rpc.RpcInvalidError("The output to %s is dust: %d", output.Address, output.Amount.Value)
+		}
+		txOuts = append(txOuts, txOut)
+		amounts[output.Amount.Id] += output.Amount.Value
+	}
+
+	us, err := w.listUnspent(nil)
+	if err != nil {
+		return nil, err
+	}
+	aj, err := w.getAccount(DefaultAccountName)
+	if err != nil {
+		return nil, err
+	}
+	changeAddr := ""
+	changeScript := func() ([]byte, error) {
+		if len(changeAddr) <= 0 {
+			changeAddr, err = w.newAddress(aj, InternalBranch)
+			if err != nil {
+				return nil, err
+			}
+		}
+		addr, err := address.DecodeAddress(changeAddr)
+		if err != nil {
+			return nil, err
+		}
+		return txscript.PayToAddrScript(addr)
+	}
+
+	// The token inputs and outputs are balanced exactly.
+	coins := []types.CoinID{}
+	for coin := range amounts {
+		if coin != types.MEERA {
+			coins = append(coins, coin)
+		}
+	}
+	sort.Slice(coins, func(i, j int) bool { return coins[i] < coins[j] })
+	tokenIns := []*walletUTXO{}
+	for _, coin := range coins {
+		selected, total, err := selectUTXOs(us, coin, amounts[coin])
+		if err != nil {
+			return nil, err
+		}
+		tokenIns = append(tokenIns, selected...)
+		if total > amounts[coin] {
+			pkScript, err := changeScript()
+			if err != nil {
+				return nil, err
+			}
+			txOuts = append(txOuts, types.NewTxOutput(types.Amount{Id: coin, Value: total - amounts[coin]}, pkScript))
+		}
+	}
+
+	fee := int64(0)
+	for {
+		meerIns, total, err := selectUTXOs(us, types.MEERA, amounts[types.MEERA]+fee)
+		if err != nil {
+			return nil, err
+		}
+		mtx := types.NewTransaction()
+		for _, u := range append(append([]*walletUTXO{}, tokenIns...), meerIns...) {
+			op := u.OutPoint
+			mtx.AddTxIn(types.NewTxInput(&op, []byte{}))
+		}
+		for _, txOut := range txOuts {
+			mtx.AddTxOut(txOut)
+		}
+		change := total - amounts[types.MEERA] - fee
+		if change > 0 {
+			pkScript, err := changeScript()
+			if err != nil {
+				return nil, err
+			}
+			txOut := types.NewTxOutput(types.Amount{Id: types.MEERA, Value: change}, pkScript)
+			if !w.isDust(txOut) {
+				mtx.AddTxOut(txOut)
+			}
+		}
+		complete, err := w.signTx(mtx)
+		if err != nil {
+			return nil, err
+		}
+		if !complete {
+			return nil, fmt.Errorf("Failed to sign the transaction")
+		}
+		required := w.minRelayFee(mtx.SerializeSize())
+		if fee >= required {
+			return w.submit(mtx)
+		}
+		fee = required
+	}
+}
+
+// signTx signs the inputs which spend the outputs of the wallet addresses, it
+// returns whether all the inputs are signed.
+func (w *WalletManager) signTx(mtx *types.Transaction) (bool, error) {
+	param := params.ActiveNetParams.Params
+	var kdb txscript.KeyClosure = func(addr types.Address) (ecc.PrivateKey, bool, error) {
+		privKey, err := w.privateKey(pkhString(addr))
+		if err != nil {
+			return nil, false, err
+		}
+		return privKey, true, nil // compressed is true
+	}
+	complete := true
+	for i, txIn := range mtx.TxIn {
+		pkScript, err := w.fetchPkScript(txIn.PreviousOut)
+		if err != nil {
+			return false, err
+		}
+		if pkScript == nil || !w.isMine(pkScript) {
+			complete = false
+			continue
+		}
+		sigScript, err := txscript.SignTxOutput(param, mtx, i, pkScript, txscript.SigHashAll, kdb, nil, txIn.SignScript, ecc.ECDSA_Secp256k1)
+		if err != nil {
+			return false, err
+		}
+		txIn.SignScript = sigScript
+	}
+	return complete, nil
+}
+
+// fetchPkScript returns the script of the output from the UTXO set or the
+// transactions of the mempool.
+func (w *WalletManager) fetchPkScript(op types.TxOutPoint) ([]byte, error) {
+	prevOut, err := w.fetchPrevOut(op)
+	if err != nil || prevOut == nil {
+		return nil, err
+	}
+	return prevOut.PkScript, nil
+}
+
+// fetchPrevOut returns the output from the UTXO set or the transactions of
+// the mempool.
+func (w *WalletManager) fetchPrevOut(op types.TxOutPoint) (*types.TxOutput, error) {
+	entry, err := w.chain.FetchUtxoEntry(op)
+	if err != nil {
+		return nil, err
+	}
+	if entry != nil && !entry.IsSpent() {
+		return types.NewTxOutput(entry.Amount(), entry.PkScript()), nil
+	}
+	tx, err := w.txpool.FetchTransaction(&op.Hash)
+	if err != nil || tx == nil {
+		return nil, nil
+	}
+	if op.OutIndex >= uint32(len(tx.Tx.TxOut)) {
+		return nil, nil
+	}
+	return tx.Tx.TxOut[op.OutIndex], nil
+}
+
+// isMine returns whether the script pays to a wallet address by
+// pay-to-pubkey(-hash).
+func (w *WalletManager) isMine(pkScript []byte) bool {
+	param := params.ActiveNetParams.Params
+	class, addrs, _, err := txscript.ExtractPkScriptAddrs(pkScript, param)
+	if err != nil || len(addrs) != 1 {
+		return false
+	}
+	if class != txscript.PubKeyHashTy && class != txscript.PubKeyTy {
+		return false
+	}
+	_, ok := w.addrs[pkhString(addrs[0])]
+	return ok
+}
+
+// pkhString returns the pay-to-pubkey-hash address of the pay-to-pubkey
+// address, which is how the wallet knows its keys.
+func pkhString(addr types.Address) string {
+	if pka, ok := addr.(*address.SecpPubKeyAddress); ok {
+		return pka.PKHAddress().String()
+	}
+	return addr.String()
+}
+
+// SignRawTransaction signs the inputs of the transaction which spend the
+// outputs of the wallet addresses.
+func (w *WalletManager) SignRawTransaction(mtx *types.Transaction) (bool, error) {
+	w.lock.Lock()
+	defer w.lock.Unlock()
+	err := w.isUnlocked()
+	if err != nil {
+		return false, err
+	}
+	return w.signTx(mtx)
+}
+
+// submit processes the transaction by the mempool and announces it.
+func (w *WalletManager) submit(mtx *types.Transaction) (*hash.Hash, error) {
+	tx := types.NewTx(mtx)
+	acceptedTxs, err := w.txpool.ProcessTransaction(tx, false, false, false)
+	if err != nil {
+		if rerr, ok := err.(mempool.RuleError); ok {
+			err = fmt.Errorf("Rejected transaction %v: %v", tx.Hash(), err)
+			log.Error("Failed to process transaction", "mempool.RuleError", err)
+			txRuleErr, ok := rerr.Err.(mempool.TxRuleError)
+			if ok && txRuleErr.RejectCode == message.RejectDuplicate {
+				return nil, rpc.RpcDuplicateTxError("%v", err)
+			}
+			return nil, rpc.RpcRuleError("%v", err)
+		}
+		log.Error("Failed to process transaction", "err", err)
+		return nil, fmt.Errorf("failed to process transaction %v: %v", tx.Hash(), err)
+	}
+	if w.ntmgr != nil {
+		w.ntmgr.AnnounceNewTransactions(acceptedTxs, nil)
+		w.ntmgr.AddRebroadcastInventory(acceptedTxs)
+	}
+	log.Info(fmt.Sprintf("Send transaction:%s", tx.Hash()))
+	return tx.Hash(), nil
+}
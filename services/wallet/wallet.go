@@ -0,0 +1,390 @@
+package wallet
+
+import (
+	"crypto/rand"
+	"encoding/hex"
+	"fmt"
+	"github.com/Qitmeer/qng/config"
+	"github.com/Qitmeer/qng/core/address"
+	"github.com/Qitmeer/qng/core/blockchain"
+	"github.com/Qitmeer/qng/crypto/bip32"
+	"github.com/Qitmeer/qng/crypto/ecc"
+	"github.com/Qitmeer/qng/node/service"
+	"github.com/Qitmeer/qng/rpc/api"
+	"github.com/Qitmeer/qng/rpc/client/cmds"
+	"github.com/Qitmeer/qng/services/acct"
+	"github.com/Qitmeer/qng/services/mempool"
+	vmconsensus "github.com/Qitmeer/qng/vm/consensus"
+	"github.com/ethereum/go-ethereum/accounts/keystore"
+	"sync"
+	"time"
+)
+
+const (
+	// The seed lengths of BIP32.
+	MinSeedBytes       = 16
+	MaxSeedBytes       = 64
+	RecommendedSeedLen = 32
+)
+
+// The scrypt parameters of the seed encryption.
+var (
+	scryptN = keystore.StandardScryptN
+	scryptP = keystore.StandardScryptP
+)
+
+// WalletManager is the node side wallet.  The HD seed is kept encrypted in
+// the keystore file and is only decrypted in memory while the wallet is
+// unlocked.  The outputs of the wallet addresses are tracked by the account
+// manager.
+type WalletManager struct {
+	service.Service
+	cfg    *config.Config
+	chain  *blockchain.BlockChain
+	acct   *acct.AccountManager
+	txpool *mempool.TxPool
+	ntmgr  vmconsensus.Notify
+
+	lock   sync.Mutex
+	ksPath string
+	ks     *keystoreJSON
+	// The account public keys by their index.
+	accounts map[uint32]*bip32.Key
+	addrs    map[string]*addrPath
+
+	// The master key while the wallet is unlocked.  Every unlock starts a
+	// new generation, the lock timer only locks the generation it was
+	// started by.
+	master        *bip32.Key
+	lockTimer     *time.Timer
+	unlockGen     uint64
+	unlockedUntil int64
+}
+
+func (w *WalletManager) Start() error {
+	if err := w.Service.Start(); err != nil {
+		return err
+	}
+	w.lock.Lock()
+	defer w.lock.Unlock()
+	ks, err := loadKeystore(w.ksPath)
+	if err != nil {
+		log.Error(fmt.Sprintf("Failed to load the wallet(%s):%s", w.ksPath, err.Error()))
+		return nil
+	}
+	if ks == nil {
+		return nil
+	}
+	err = w.load(ks)
+	if err != nil {
+		log.Error(fmt.Sprintf("Failed to load the wallet(%s):%s", w.ksPath, err.Error()))
+		return nil
+	}
+	log.Info(fmt.Sprintf("Load wallet:accounts=%d addresses=%d", len(w.accounts), len(w.addrs)))
+	// The account database may have been rebuilt without the wallet addresses.
+	if w.cfg.AcctMode {
+		for addr := range w.addrs {
+			if w.acct.HasAddress(addr) {
+				continue
+			}
+			err = w.acct.AddAddress(addr)
+			if err != nil {
+				log.Error(err.Error())
+			}
+		}
+	}
+	return nil
+}
+
+func (w *WalletManager) Stop() error {
+	if err := w.Service.Stop(); err != nil {
+		return err
+	}
+	w.Lock()
+	return nil
+}
+
+// load derives the addresses of the keystore accounts.
+func (w *WalletManager) load(ks *keystoreJSON) error {
+	accounts := map[uint32]*bip32.Key{}
+	addrs := map[string]*addrPath{}
+	for _, aj := range ks.Accounts {
+		key, err := bip32.B58Deserialize(aj.Xpub, netBip32Version())
+		if err != nil {
+			return err
+		}
+		accounts[aj.Index] = key
+		for _, bc := range []struct {
+			branch uint32
+			count  uint32
+		}{{ExternalBranch, aj.External}, {InternalBranch, aj.Internal}} {
+			bk, err := key.NewChildKey(bc.branch)
+			if err != nil {
+				return err
+			}
+			for i := uint32(0); i < bc.count; i++ {
+				child, err := bk.NewChildKey(i)
+				if err != nil {
+					return err
+				}
+				addr, err := pubKeyHashAddress(child)
+				if err != nil {
+					return err
+				}
+				addrs[addr] = &addrPath{account: aj.Index, branch: bc.branch, index: i}
+			}
+		}
+	}
+	w.ks = ks
+	w.accounts = accounts
+	w.addrs = addrs
+	return nil
+}
+
+// Create creates the wallet with the default account, the seed is generated
+// when it is not given.
+func (w *WalletManager) Create(passphrase string, seed []byte) error {
+	w.lock.Lock()
+	defer w.lock.Unlock()
+	if w.ks != nil {
+		return fmt.Errorf("The wallet already exists:%s", w.ksPath)
+	}
+	if len(passphrase) <= 0 {
+		return fmt.Errorf("The passphrase is empty")
+	}
+	var err error
+	if len(seed) <= 0 {
+		seed = make([]byte, RecommendedSeedLen)
+		_, err = rand.Read(seed)
+		if err != nil {
+			return err
+		}
+	}
+	master, err := bip32.NewMasterKey2(seed, netBip32Version())
+	if err != nil {
+		return err
+	}
+	ak, err := accountKey(master, 0)
+	if err != nil {
+		return err
+	}
+	crypto, err := keystore.EncryptDataV3(seed, []byte(passphrase), scryptN, scryptP)
+	if err != nil {
+		return err
+	}
+	ks := &keystoreJSON{
+		Version: CurrentWalletVersion,
+		Crypto:  crypto,
+		Accounts: []*accountJSON{
+			{Name: DefaultAccountName, Index: 0, Xpub: ak.PublicKey().B58Serialize()},
+		},
+	}
+	err = saveKeystore(w.ksPath, ks)
+	if err != nil {
+		return err
+	}
+	log.Info(fmt.Sprintf("Create wallet:%s", w.ksPath))
+	return w.load(ks)
+}
+
+// Unlock decrypts the seed of the wallet, the wallet is locked again after
+// the timeout.
+func (w *WalletManager) Unlock(passphrase string, timeout time.Duration) error {
+	if timeout <= 0 {
+		return fmt.Errorf("The unlock timeout must be positive")
+	}
+	w.lock.Lock()
+	defer w.lock.Unlock()
+	if w.ks == nil {
+		return fmt.Errorf("No wallet, please create it first (RPC:createWallet)")
+	}
+	seed, err := keystore.DecryptDataV3(w.ks.Crypto, passphrase)
+	if err != nil {
+		return err
+	}
+	master, err := bip32.NewMasterKey2(seed, netBip32Version())
+	for i := range seed {
+		seed[i] = 0
+	}
+	if err != nil {
+		return err
+	}
+	w.master = master
+	if w.lockTimer != nil {
+		w.lockTimer.Stop()
+	}
+	w.unlockGen++
+	gen := w.unlockGen
+	w.unlockedUntil = time.Now().Add(timeout).Unix()
+	w.lockTimer = time.AfterFunc(timeout, func() {
+		w.lock.Lock()
+		defer w.lock.Unlock()
+		// The timer of an earlier unlock may fire while it is stopped.
+		if gen != w.unlockGen {
+			return
+		}
+		w.clearKeys()
+	})
+	log.Info("Wallet unlocked")
+	return nil
+}
+
+// Lock removes the decrypted keys from memory.
+func (w *WalletManager) Lock() {
+	w.lock.Lock()
+	defer w.lock.Unlock()
+	w.clearKeys()
+}
+
+// clearKeys locks the wallet, the caller must hold the wallet lock.
+func (w *WalletManager) clearKeys() {
+	if w.lockTimer != nil {
+		w.lockTimer.Stop()
+		w.lockTimer = nil
+	}
+	w.unlockGen++
+	w.unlockedUntil = 0
+	if w.master == nil {
+		return
+	}
+	w.master = nil
+	log.Info("Wallet locked")
+}
+
+func (w *WalletManager) isUnlocked() error {
+	if w.ks == nil {
+		return fmt.Errorf("No wallet, please create it first (RPC:createWallet)")
+	}
+	if w.master == nil {
+		return fmt.Errorf("The wallet is locked, please unlock it first (RPC:unlock)")
+	}
+	return nil
+}
+
+// getAccount returns the keystore account by its name, the default account
+// is returned for the empty name.
+func (w *WalletManager) getAccount(name string) (*accountJSON, error) {
+	if w.ks == nil {
+		return nil, fmt.Errorf("No wallet, please create it first (RPC:createWallet)")
+	}
+	if len(name) <= 0 {
+		name = DefaultAccountName
+	}
+	for _, aj := range w.ks.Accounts {
+		if aj.Name == name {
+			return aj, nil
+		}
+	}
+	return nil, fmt.Errorf("No account:%s", name)
+}
+
+// newAddress derives the next address of the account branch, and watches it
+// by the account manager.
+func (w *WalletManager) newAddress(aj *accountJSON, branch uint32) (string, error) {
+	if !w.cfg.AcctMode {
+		return "", fmt.Errorf("Please enable --acctmode")
+	}
+	key, ok := w.accounts[aj.Index]
+	if !ok {
+		return "", fmt.Errorf("No account key:%d", aj.Index)
+	}
+	index := aj.External
+	if branch == InternalBranch {
+		index = aj.Internal
+	}
+	bk, err := key.NewChildKey(branch)
+	if err != nil {
+		return "", err
+	}
+	child, err := bk.NewChildKey(index)
+	if err != nil {
+		return "", err
+	}
+	addr, err := pubKeyHashAddress(child)
+	if err != nil {
+		return "", err
+	}
+	if branch == InternalBranch {
+		aj.Internal++
+	} else {
+		aj.External++
+	}
+	err = saveKeystore(w.ksPath, w.ks)
+	if err != nil {
+		return "", err
+	}
+	w.addrs[addr] = &addrPath{account: aj.Index, branch: branch, index: index}
+	if !w.acct.HasAddress(addr) {
+		err = w.acct.AddAddress(addr)
+		if err != nil {
+			return "", err
+		}
+	}
+	return addr, nil
+}
+
+// GetNewAddress returns a new receiving address of the account.
+func (w *WalletManager) GetNewAddress(account string) (string, error) {
+	w.lock.Lock()
+	defer w.lock.Unlock()
+	aj, err := w.getAccount(account)
+	if err != nil {
+		return "", err
+	}
+	return w.newAddress(aj, ExternalBranch)
+}
+
+// privateKey derives the private key of the wallet address.
+func (w *WalletManager) privateKey(addr string) (ecc.PrivateKey, error) {
+	ap, ok := w.addrs[address.NormalizeAddress(addr)]
+	if !ok {
+		return nil, fmt.Errorf("Not the wallet address:%s", addr)
+	}
+	key, err := accountKey(w.master, ap.account)
+	if err != nil {
+		return nil, err
+	}
+	for _, idx := range []uint32{ap.branch, ap.index} {
+		key, err = key.NewChildKey(idx)
+		if err != nil {
+			return nil, err
+		}
+	}
+	privKey, _ := ecc.Secp256k1.PrivKeyFromBytes(key.Key)
+	return privKey, nil
+}
+
+func (w *WalletManager) APIs() []api.API {
+	return []api.API{
+		{
+			NameSpace: cmds.WalletNameSpace,
+			Service:   NewPrivateWalletAPI(w),
+			Public:    false,
+		},
+	}
+}
+
+func New(cfg *config.Config, chain *blockchain.BlockChain, acctmgr *acct.AccountManager, txpool *mempool.TxPool, ntmgr vmconsensus.Notify) (*WalletManager, error) {
+	w := WalletManager{
+		cfg:      cfg,
+		chain:    chain,
+		acct:     acctmgr,
+		txpool:   txpool,
+		ntmgr:    ntmgr,
+		ksPath:   getKeystorePath(cfg.DataDir),
+		accounts: map[uint32]*bip32.Key{},
+		addrs:    map[string]*addrPath{},
+	}
+	return &w, nil
+}
+
+func decodeSeed(seed string) ([]byte, error) {
+	data, err := hex.DecodeString(seed)
+	if err != nil {
+		return nil, err
+	}
+	if len(data) < MinSeedBytes || len(data) > MaxSeedBytes {
+		return nil, fmt.Errorf("The seed length must be between %d and %d bytes", MinSeedBytes, MaxSeedBytes)
+	}
+	return data, nil
+}
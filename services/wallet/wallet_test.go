@@ -0,0 +1,70 @@
+package wallet
+
+import (
+	"testing"
+	"time"
+
+	"github.com/Qitmeer/qng/config"
+	"github.com/ethereum/go-ethereum/accounts/keystore"
+)
+
+const testPassphrase = "passphrase"
+
+func newTestWallet(t *testing.T, seed []byte) *WalletManager {
+	scryptN, scryptP = keystore.LightScryptN, keystore.LightScryptP
+	w, err := New(&config.Config{DataDir: t.TempDir()}, nil, nil, nil, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	err = w.Create(testPassphrase, seed)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return w
+}
+
+func (w *WalletManager) testUnlocked() bool {
+	w.lock.Lock()
+	defer w.lock.Unlock()
+	return w.master != nil
+}
+
+func TestUnlockTimeout(t *testing.T) {
+	w := newTestWallet(t, nil)
+	if err := w.Unlock(testPassphrase, 0); err == nil {
+		t.Fatalf("the wallet is unlocked without a timeout")
+	}
+	if w.testUnlocked() {
+		t.Fatalf("the wallet is unlocked by a failed unlock")
+	}
+
+	if err := w.Unlock(testPassphrase, 50*time.Millisecond); err != nil {
+		t.Fatal(err)
+	}
+	if !w.testUnlocked() {
+		t.Fatalf("the wallet isn't unlocked")
+	}
+	time.Sleep(200 * time.Millisecond)
+	if w.testUnlocked() {
+		t.Fatalf("the wallet isn't locked after the timeout")
+	}
+}
+
+func TestUnlockAgain(t *testing.T) {
+	w := newTestWallet(t, nil)
+	if err := w.Unlock(testPassphrase, 50*time.Millisecond); err != nil {
+		t.Fatal(err)
+	}
+	if err := w.Unlock(testPassphrase, time.Hour); err != nil {
+		t.Fatal(err)
+	}
+	// The timer of the first unlock doesn't lock the second one.
+	time.Sleep(200 * time.Millisecond)
+	if !w.testUnlocked() {
+		t.Fatalf("the wallet is locked by the timer of an earlier unlock")
+	}
+	w.Lock()
+	if w.testUnlocked() {
+		t.Fatalf("the wallet isn't locked")
+	}
+}
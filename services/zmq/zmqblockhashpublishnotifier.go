@@ -5,6 +5,7 @@ package zmq
 import (
 	"fmt"
 	"github.com/Qitmeer/qng/config"
+	"github.com/Qitmeer/qng/core/json"
 	"github.com/Qitmeer/qng/core/types"
 )
 
@@ -32,6 +33,10 @@ func (zp *ZMQBlockHashPublishNotifier) NotifyTransaction(txs []*types.Tx) error
 	return nil
 }
 
+func (zp *ZMQBlockHashPublishNotifier) NotifyReorganization(diff *json.ReorganizationDiff) error {
+	return nil
+}
+
 func (zp *ZMQBlockHashPublishNotifier) Shutdown() {
 	zp.shutdown()
 }
@@ -5,6 +5,7 @@ package zmq
 import (
 	"fmt"
 	"github.com/Qitmeer/qng/config"
+	"github.com/Qitmeer/qng/core/json"
 	"github.com/Qitmeer/qng/core/types"
 )
 
@@ -35,6 +36,10 @@ func (zp *ZMQBlockRawPublishNotifier) NotifyTransaction(txs []*types.Tx) error {
 	return nil
 }
 
+func (zp *ZMQBlockRawPublishNotifier) NotifyReorganization(diff *json.ReorganizationDiff) error {
+	return nil
+}
+
 func (zp *ZMQBlockRawPublishNotifier) Shutdown() {
 	zp.shutdown()
 }
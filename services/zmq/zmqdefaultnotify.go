@@ -4,6 +4,7 @@ package zmq
 
 import (
 	"github.com/Qitmeer/qng/config"
+	"github.com/Qitmeer/qng/core/json"
 	"github.com/Qitmeer/qng/core/types"
 )
 
@@ -36,6 +37,11 @@ func (zn *ZMQNotification) BlockDisconnected(block *types.SerializedBlock) {
 
 }
 
+// reorganization
+func (zn *ZMQNotification) Reorganization(diff *json.ReorganizationDiff) {
+
+}
+
 // Shutdown
 func (zn *ZMQNotification) Shutdown() {
 
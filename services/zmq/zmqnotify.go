@@ -5,6 +5,7 @@ package zmq
 import (
 	"fmt"
 	"github.com/Qitmeer/qng/config"
+	"github.com/Qitmeer/qng/core/json"
 	"github.com/Qitmeer/qng/core/types"
 )
 
@@ -21,7 +22,7 @@ func (zn *ZMQNotification) Init(cfg *config.Config) {
 	zn.cfg = cfg
 
 	zn.publishNotifiers = []IZMQPublishNotifier{}
-	notiTypeArr := []string{BlockHash, BlockRaw, TxHash, TxRaw}
+	notiTypeArr := []string{BlockHash, BlockRaw, TxHash, TxRaw, Reorg}
 	for _, notiType := range notiTypeArr {
 		publishNotifier := NewZMQPublishNotifier(cfg, notiType)
 		if publishNotifier != nil {
@@ -78,6 +79,20 @@ func (zn *ZMQNotification) BlockDisconnected(block *types.SerializedBlock) {
 	}
 }
 
+// reorganization
+func (zn *ZMQNotification) Reorganization(diff *json.ReorganizationDiff) {
+	log.Debug(fmt.Sprintf("Reorganization:%s", diff.Hash))
+	for i := 0; i < len(zn.publishNotifiers); {
+		err := zn.publishNotifiers[i].NotifyReorganization(diff)
+		if err != nil {
+			zn.publishNotifiers[i].Shutdown()
+			zn.publishNotifiers = append(zn.publishNotifiers[:i], zn.publishNotifiers[i+1:]...)
+		} else {
+			i++
+		}
+	}
+}
+
 // Shutdown
 func (zn *ZMQNotification) Shutdown() {
 	log.Info("ZMQ: Shutdown...")
@@ -2,6 +2,7 @@ package zmq
 
 import (
 	"github.com/Qitmeer/qng/config"
+	"github.com/Qitmeer/qng/core/json"
 	"github.com/Qitmeer/qng/core/types"
 	_ "log"
 )
@@ -23,6 +24,9 @@ type IZMQNotification interface {
 	// block connected
 	BlockDisconnected(block *types.SerializedBlock)
 
+	// reorganization
+	Reorganization(diff *json.ReorganizationDiff)
+
 	// Shutdown
 	Shutdown()
 }
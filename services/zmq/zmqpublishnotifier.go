@@ -5,6 +5,7 @@ package zmq
 import (
 	"fmt"
 	"github.com/Qitmeer/qng/config"
+	"github.com/Qitmeer/qng/core/json"
 	"github.com/Qitmeer/qng/core/types"
 	"github.com/zeromq/goczmq"
 )
@@ -14,17 +15,20 @@ const (
 	BlockRaw  = "BlockRaw"
 	TxHash    = "TxHash"
 	TxRaw     = "TxRaw"
+	Reorg     = "Reorg"
 
 	defaultBlockHashEndpoint = "tcp://*:8230"
 	defaultBlockRawEndpoint  = "tcp://*:8231"
 	defaultTxHashEndpoint    = "tcp://*:8232"
 	defaultTxRawEndpoint     = "tcp://*:8233"
+	defaultReorgEndpoint     = "tcp://*:8234"
 )
 
 type IZMQPublishNotifier interface {
 	Init(cfg *config.Config) error
 	NotifyBlock(block *types.SerializedBlock) error
 	NotifyTransaction(transaction []*types.Tx) error
+	NotifyReorganization(diff *json.ReorganizationDiff) error
 	Shutdown()
 }
 
@@ -83,6 +87,8 @@ func NewZMQPublishNotifier(cfg *config.Config, notifierType string) IZMQPublishN
 		zmq = &ZMQTxHashPublishNotifier{&ZMQPublishNotifier{name: notifierType}}
 	case TxRaw:
 		zmq = &ZMQTxRawPublishNotifier{&ZMQPublishNotifier{name: notifierType}}
+	case Reorg:
+		zmq = &ZMQReorgPublishNotifier{&ZMQPublishNotifier{name: notifierType}}
 	}
 	if zmq == nil {
 		return nil
@@ -0,0 +1,48 @@
+//go:build zmq
+// +build zmq
+
+package zmq
+
+import (
+	"encoding/json"
+	"fmt"
+	"github.com/Qitmeer/qng/config"
+	j "github.com/Qitmeer/qng/core/json"
+	"github.com/Qitmeer/qng/core/types"
+)
+
+// The ZeroMQ public notifier of the reorganization diff, it is published
+// as JSON.
+type ZMQReorgPublishNotifier struct {
+	*ZMQPublishNotifier
+}
+
+func (zp *ZMQReorgPublishNotifier) Init(cfg *config.Config) error {
+	if len(cfg.Zmqpubreorg) <= 0 {
+		return fmt.Errorf("No config")
+	}
+	if cfg.Zmqpubreorg == "default" || cfg.Zmqpubreorg == "*" {
+		cfg.Zmqpubreorg = defaultReorgEndpoint
+	}
+	return zp.initialization(cfg.Zmqpubreorg)
+}
+
+func (zp *ZMQReorgPublishNotifier) NotifyBlock(block *types.SerializedBlock) error {
+	return nil
+}
+
+func (zp *ZMQReorgPublishNotifier) NotifyTransaction(txs []*types.Tx) error {
+	return nil
+}
+
+func (zp *ZMQReorgPublishNotifier) NotifyReorganization(diff *j.ReorganizationDiff) error {
+	data, err := json.Marshal(diff)
+	if err != nil {
+		return err
+	}
+	return zp.sendMessage(data, false)
+}
+
+func (zp *ZMQReorgPublishNotifier) Shutdown() {
+	zp.shutdown()
+}
@@ -5,6 +5,7 @@ package zmq
 import (
 	"fmt"
 	"github.com/Qitmeer/qng/config"
+	"github.com/Qitmeer/qng/core/json"
 	"github.com/Qitmeer/qng/core/types"
 )
 
@@ -40,6 +41,10 @@ func (zp *ZMQTxHashPublishNotifier) NotifyTransaction(txs []*types.Tx) error {
 	return nil
 }
 
+func (zp *ZMQTxHashPublishNotifier) NotifyReorganization(diff *json.ReorganizationDiff) error {
+	return nil
+}
+
 func (zp *ZMQTxHashPublishNotifier) Shutdown() {
 	zp.shutdown()
 }
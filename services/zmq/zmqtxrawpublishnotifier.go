@@ -5,6 +5,7 @@ package zmq
 import (
 	"fmt"
 	"github.com/Qitmeer/qng/config"
+	"github.com/Qitmeer/qng/core/json"
 	"github.com/Qitmeer/qng/core/types"
 )
 
@@ -44,6 +45,10 @@ func (zp *ZMQTxRawPublishNotifier) NotifyTransaction(txs []*types.Tx) error {
 	return nil
 }
 
+func (zp *ZMQTxRawPublishNotifier) NotifyReorganization(diff *json.ReorganizationDiff) error {
+	return nil
+}
+
 func (zp *ZMQTxRawPublishNotifier) Shutdown() {
 	zp.shutdown()
 }
@@ -16,5 +16,6 @@ type Notify interface {
 	RelayInventory(data interface{}, filters []peer.ID)
 	BroadcastMessage(data interface{})
 	TransactionConfirmed(tx *types.Tx)
+	TransactionRemoved(tx *types.Tx)
 	AddRebroadcastInventory(newTxs []*types.TxDesc)
 }